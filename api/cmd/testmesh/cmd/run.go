@@ -85,6 +85,8 @@ func runFlow(cmd *cobra.Command, args []string) error {
 
 	// Create executor (without database, websocket, mock manager, and contract repo for local execution)
 	executor := runner.NewExecutor(nil, nil, log, nil, nil)
+	// Schema files are read relative to the working directory
+	executor.SetSchemaDir(".")
 
 	// Execute flow
 	startTime := time.Now()
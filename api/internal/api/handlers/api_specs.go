@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// APISpecHandler handles API spec requests
+type APISpecHandler struct {
+	repo   *repository.APISpecRepository
+	logger *zap.Logger
+}
+
+// NewAPISpecHandler creates a new API spec handler
+func NewAPISpecHandler(repo *repository.APISpecRepository, logger *zap.Logger) *APISpecHandler {
+	return &APISpecHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// SaveAPISpecRequest represents a request to attach an API spec to a workspace
+type SaveAPISpecRequest struct {
+	Name     string `json:"name" binding:"required"`
+	SpecType string `json:"spec_type"`
+	Version  string `json:"version"`
+	Content  string `json:"content" binding:"required"`
+}
+
+// List handles GET /api/v1/workspaces/:workspace_id/specs
+func (h *APISpecHandler) List(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	specs, err := h.repo.List(workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to list API specs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API specs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"specs": specs,
+		"total": len(specs),
+	})
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/specs/:id
+func (h *APISpecHandler) Get(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+		return
+	}
+
+	spec, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API spec not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// Save handles POST /api/v1/workspaces/:workspace_id/specs
+// Specs are keyed by name, so saving an existing name replaces its content.
+func (h *APISpecHandler) Save(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	var req SaveAPISpecRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := parseSpecDocument(req.Content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec content: " + err.Error()})
+		return
+	}
+
+	spec := &models.APISpec{
+		Name:     req.Name,
+		SpecType: req.SpecType,
+		Version:  req.Version,
+		Content:  req.Content,
+	}
+	if err := h.repo.Upsert(spec, workspaceID); err != nil {
+		h.logger.Error("Failed to save API spec", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API spec"})
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// Delete handles DELETE /api/v1/workspaces/:workspace_id/specs/:id
+func (h *APISpecHandler) Delete(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+		return
+	}
+
+	if err := h.repo.Delete(id, workspaceID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API spec not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API spec deleted"})
+}
+
+// workspaceSpecResolver resolves schema assertion refs against the API specs of one workspace
+type workspaceSpecResolver struct {
+	repo        *repository.APISpecRepository
+	workspaceID uuid.UUID
+}
+
+// ResolveDocument implements runner.SchemaDocumentResolver
+func (r *workspaceSpecResolver) ResolveDocument(name string) (interface{}, error) {
+	spec, err := r.repo.GetByName(name, r.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpecDocument(spec.Content)
+}
+
+// parseSpecDocument decodes a JSON or YAML spec into plain JSON values
+func parseSpecDocument(content string) (interface{}, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	return assertions.NormalizeJSON(doc), nil
+}
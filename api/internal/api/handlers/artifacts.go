@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ArtifactHandler handles execution artifact requests
+type ArtifactHandler struct {
+	repo     *repository.ArtifactRepository
+	execRepo *repository.ExecutionRepository
+	manager  *artifacts.Manager
+	logger   *zap.Logger
+}
+
+// NewArtifactHandler creates a new artifact handler
+func NewArtifactHandler(
+	repo *repository.ArtifactRepository,
+	execRepo *repository.ExecutionRepository,
+	manager *artifacts.Manager,
+	logger *zap.Logger,
+) *ArtifactHandler {
+	return &ArtifactHandler{
+		repo:     repo,
+		execRepo: execRepo,
+		manager:  manager,
+		logger:   logger,
+	}
+}
+
+// ListByExecution handles GET /api/v1/workspaces/:workspace_id/executions/:id/artifacts
+func (h *ArtifactHandler) ListByExecution(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+
+	execution, err := h.execRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID != uuid.Nil && execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	list, err := h.repo.ListByExecution(execution.ID)
+	if err != nil {
+		h.logger.Error("Failed to list artifacts", zap.String("execution_id", id.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list artifacts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"artifacts": list,
+		"total":     len(list),
+	})
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/artifacts/:id
+func (h *ArtifactHandler) Get(c *gin.Context) {
+	artifact, ok := h.loadArtifact(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, artifact)
+}
+
+// Download handles GET /api/v1/workspaces/:workspace_id/artifacts/:id/download.
+// Only images are shown inline; everything else is sent as an attachment so
+// that stored HTML cannot run in the context of the API.
+func (h *ArtifactHandler) Download(c *gin.Context) {
+	artifact, ok := h.loadArtifact(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.manager.Open(c.Request.Context(), artifact)
+	if err != nil {
+		if errors.Is(err, artifacts.ErrNotFound) {
+			c.JSON(http.StatusGone, gin.H{"error": "artifact content no longer exists"})
+			return
+		}
+		h.logger.Error("Failed to open artifact", zap.String("id", artifact.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open artifact"})
+		return
+	}
+	defer content.Close()
+
+	contentType := artifact.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if mediaType, _, _ := mime.ParseMediaType(contentType); strings.HasPrefix(mediaType, "image/") && mediaType != "image/svg+xml" {
+		disposition = "inline"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Length", fmt.Sprint(artifact.Size))
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": artifact.Name}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", `"`+artifact.Checksum+`"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		h.logger.Warn("Failed to send artifact", zap.String("id", artifact.ID.String()), zap.Error(err))
+	}
+}
+
+// loadArtifact loads the artifact in the path, answering 404 for artifacts of
+// other workspaces
+func (h *ArtifactHandler) loadArtifact(c *gin.Context) (*models.Artifact, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid artifact ID"})
+		return nil, false
+	}
+
+	artifact, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+		return nil, false
+	}
+
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		return artifact, true
+	}
+	if artifact.WorkspaceID != nil {
+		if *artifact.WorkspaceID != workspaceID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+			return nil, false
+		}
+		return artifact, true
+	}
+
+	// Artifacts of runs without a workspace belong to the workspace of the flow
+	execution, err := h.execRepo.GetByID(artifact.ExecutionID)
+	if err != nil || (execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+		return nil, false
+	}
+	return artifact, true
+}
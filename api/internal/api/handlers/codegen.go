@@ -3,19 +3,30 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
 	"github.com/georgi-georgiev/testmesh/internal/codegen"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 // CodegenHandler handles code generation requests
 type CodegenHandler struct {
 	generator *codegen.Generator
+	flowRepo  *repository.FlowRepository
+	envRepo   *repository.EnvironmentRepository
+	logger    *zap.Logger
 }
 
 // NewCodegenHandler creates a new codegen handler
-func NewCodegenHandler() *CodegenHandler {
+func NewCodegenHandler(flowRepo *repository.FlowRepository, envRepo *repository.EnvironmentRepository, logger *zap.Logger) *CodegenHandler {
 	return &CodegenHandler{
 		generator: codegen.NewGenerator(),
+		flowRepo:  flowRepo,
+		envRepo:   envRepo,
+		logger:    logger,
 	}
 }
 
@@ -86,6 +97,90 @@ func (h *CodegenHandler) GetLanguages(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"languages": languages})
 }
 
+// GenerateFlowRequest represents a request to generate the test file of a flow
+type GenerateFlowRequest struct {
+	Target      string `json:"target" binding:"required"`
+	FlowID      string `json:"flow_id"`
+	YAML        string `json:"yaml"`
+	Environment string `json:"environment"`
+}
+
+// GenerateFlow handles POST /api/v1/workspaces/:workspace_id/codegen/flow
+func (h *CodegenHandler) GenerateFlow(c *gin.Context) {
+	var req GenerateFlowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	var definition models.FlowDefinition
+	switch {
+	case req.FlowID != "":
+		id, err := uuid.Parse(req.FlowID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flow ID: " + req.FlowID})
+			return
+		}
+		flow, err := h.flowRepo.GetByID(id, workspaceID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "flow not found: " + req.FlowID})
+			return
+		}
+		definition = flow.Definition
+	case req.YAML != "":
+		var err error
+		definition, err = parseFlowYAML(req.YAML)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid YAML: " + err.Error()})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "flow_id or yaml is required"})
+		return
+	}
+
+	var variables []codegen.Variable
+	if req.Environment != "" {
+		env, err := h.loadEnvironment(req.Environment, workspaceID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "environment not found: " + req.Environment})
+			return
+		}
+		for _, v := range env.Variables {
+			if v.Enabled {
+				variables = append(variables, codegen.Variable{Name: v.Key, Value: v.Value, Secret: v.IsSecret})
+			}
+		}
+	}
+
+	code, err := h.generator.GenerateFlow(req.Target, &definition, variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Debug("Generated flow test",
+		zap.String("target", code.Target),
+		zap.String("flow", definition.Name),
+		zap.Int("warnings", len(code.Warnings)))
+	c.JSON(http.StatusOK, code)
+}
+
+// GetTargets handles GET /api/v1/codegen/targets
+func (h *CodegenHandler) GetTargets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"targets": h.generator.SupportedTargets()})
+}
+
+// loadEnvironment finds an environment by ID or name
+func (h *CodegenHandler) loadEnvironment(environmentRef string, workspaceID uuid.UUID) (*models.Environment, error) {
+	if envID, err := uuid.Parse(environmentRef); err == nil {
+		return h.envRepo.GetByID(envID, workspaceID)
+	}
+	return h.envRepo.GetByName(environmentRef, workspaceID)
+}
+
 func getExtension(language string) string {
 	extensions := map[string]string{
 		"curl":       ".sh",
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/reporting"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CompareHandler handles run comparison requests
+type CompareHandler struct {
+	execRepo     *repository.ExecutionRepository
+	scheduleRepo *repository.ScheduleRepository
+	comparer     *reporting.Comparer
+	logger       *zap.Logger
+}
+
+// NewCompareHandler creates a new compare handler
+func NewCompareHandler(execRepo *repository.ExecutionRepository, scheduleRepo *repository.ScheduleRepository, comparer *reporting.Comparer, logger *zap.Logger) *CompareHandler {
+	return &CompareHandler{
+		execRepo:     execRepo,
+		scheduleRepo: scheduleRepo,
+		comparer:     comparer,
+		logger:       logger,
+	}
+}
+
+// CompareExecutions handles GET /api/v1/workspaces/:workspace_id/executions/:id/compare
+// Compares the execution with ?base=<execution_id>, by default the previous
+// finished execution of the same flow.
+func (h *CompareHandler) CompareExecutions(c *gin.Context) {
+	head, ok := h.loadExecution(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var base *models.Execution
+	if baseID := c.Query("base"); baseID != "" {
+		if base, ok = h.loadExecution(c, baseID); !ok {
+			return
+		}
+	} else {
+		previous, err := h.execRepo.GetPrevious(head)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no previous execution of the flow to compare with"})
+			return
+		}
+		base = previous
+	}
+
+	opts, ok := compareOptions(c)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.comparer.CompareExecutions(base, head, opts)
+	if err != nil {
+		h.logger.Error("Failed to compare executions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compare executions"})
+		return
+	}
+	h.respond(c, comparison, fmt.Sprintf("compare-%s", head.ID))
+}
+
+// CompareScheduleRuns handles GET /api/v1/schedules/:id/runs/:run_id/compare
+// Compares the run with ?base=<run_id>, by default the previous completed run of the schedule.
+func (h *CompareHandler) CompareScheduleRuns(c *gin.Context) {
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+	schedule, err := h.scheduleRepo.Get(scheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	head, ok := h.loadRun(c, scheduleID, c.Param("run_id"))
+	if !ok {
+		return
+	}
+
+	var base *models.ScheduleRun
+	if baseID := c.Query("base"); baseID != "" {
+		if base, ok = h.loadRun(c, scheduleID, baseID); !ok {
+			return
+		}
+	} else {
+		previous, err := h.scheduleRepo.GetPreviousRun(head)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No previous completed run to compare with"})
+			return
+		}
+		base = previous
+	}
+
+	opts, ok := compareOptions(c)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.comparer.CompareScheduleRuns(schedule.Name, base, head, opts)
+	if err != nil {
+		h.logger.Error("Failed to compare schedule runs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.respond(c, comparison, fmt.Sprintf("compare-run-%s", head.ID))
+}
+
+// respond writes the comparison as JSON, or rendered with ?format=html|markdown
+func (h *CompareHandler) respond(c *gin.Context, comparison *reporting.Comparison, filename string) {
+	format := c.DefaultQuery("format", "json")
+	var contentType, ext string
+	switch format {
+	case "json":
+		c.JSON(http.StatusOK, comparison)
+		return
+	case "html":
+		contentType, ext = "text/html; charset=utf-8", "html"
+	case "markdown", "md":
+		contentType, ext = "text/markdown; charset=utf-8", "md"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, html or markdown"})
+		return
+	}
+
+	content, err := reporting.RenderComparison(comparison, format)
+	if err != nil {
+		h.logger.Error("Failed to render comparison", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render comparison"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s.%s", filename, ext))
+	c.Data(http.StatusOK, contentType, []byte(content))
+}
+
+// loadExecution loads an execution of the request's workspace
+func (h *CompareHandler) loadExecution(c *gin.Context, rawID string) (*models.Execution, bool) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return nil, false
+	}
+
+	execution, err := h.execRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return nil, false
+	}
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID != uuid.Nil && execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return nil, false
+	}
+	return execution, true
+}
+
+// loadRun loads a run of a schedule
+func (h *CompareHandler) loadRun(c *gin.Context, scheduleID uuid.UUID, rawID string) (*models.ScheduleRun, bool) {
+	runID, err := uuid.Parse(rawID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return nil, false
+	}
+
+	run, err := h.scheduleRepo.GetRun(runID)
+	if err != nil || run.ScheduleID != scheduleID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule run not found"})
+		return nil, false
+	}
+	return run, true
+}
+
+// compareOptions reads ?threshold=<stddevs> and ?history_days=<days>
+func compareOptions(c *gin.Context) (reporting.CompareOptions, bool) {
+	var opts reporting.CompareOptions
+	if raw := c.Query("threshold"); raw != "" {
+		threshold, err := strconv.ParseFloat(raw, 64)
+		if err != nil || threshold <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a positive number of standard deviations"})
+			return opts, false
+		}
+		opts.LatencyThreshold = threshold
+	}
+	if raw := c.Query("history_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days <= 0 || days > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "history_days must be between 1 and 90"})
+			return opts, false
+		}
+		opts.HistoryDays = days
+	}
+	return opts, true
+}
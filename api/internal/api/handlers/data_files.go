@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/runner"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DataFileHandler handles requests for the data files of a workspace
+type DataFileHandler struct {
+	repo   *repository.DataFileRepository
+	logger *zap.Logger
+}
+
+// NewDataFileHandler creates a new data file handler
+func NewDataFileHandler(repo *repository.DataFileRepository, logger *zap.Logger) *DataFileHandler {
+	return &DataFileHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateDataFileRequest represents a request to create a data file
+type CreateDataFileRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Format      string `json:"format"` // "csv" or "json"; defaults to the extension of the name
+	Content     string `json:"content" binding:"required"`
+}
+
+// UpdateDataFileRequest represents a request to update a data file
+type UpdateDataFileRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Format      *string `json:"format"`
+	Content     *string `json:"content"`
+}
+
+// List handles GET /api/v1/workspaces/:workspace_id/data-files
+func (h *DataFileHandler) List(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	files, err := h.repo.List(workspaceID, c.Query("search"))
+	if err != nil {
+		h.logger.Error("Failed to list data files", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list data files"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data_files": files,
+		"total":      len(files),
+	})
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/data-files/:id
+func (h *DataFileHandler) Get(c *gin.Context) {
+	file, ok := h.getFile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, file)
+}
+
+// Preview handles GET /api/v1/workspaces/:workspace_id/data-files/:id/preview
+func (h *DataFileHandler) Preview(c *gin.Context) {
+	file, ok := h.getFile(c)
+	if !ok {
+		return
+	}
+
+	source := &runner.DataSource{Type: file.Format, Content: file.Content}
+	rows, columns, err := runner.GetDataPreview(source, 10)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"columns":    columns,
+		"preview":    rows,
+		"total_rows": file.RowCount,
+	})
+}
+
+// Create handles POST /api/v1/workspaces/:workspace_id/data-files
+func (h *DataFileHandler) Create(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	var req CreateDataFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.repo.GetByName(req.Name, workspaceID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a data file with this name already exists"})
+		return
+	}
+
+	file := &models.DataFile{
+		Name:        req.Name,
+		Description: req.Description,
+		Format:      req.Format,
+		Content:     req.Content,
+	}
+	if err := prepareDataFile(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Create(file, workspaceID); err != nil {
+		h.logger.Error("Failed to create data file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create data file"})
+		return
+	}
+
+	h.logger.Info("Created data file",
+		zap.String("id", file.ID.String()),
+		zap.String("name", file.Name),
+		zap.Int("rows", file.RowCount),
+		zap.String("workspace_id", workspaceID.String()))
+
+	c.JSON(http.StatusCreated, file)
+}
+
+// Update handles PUT /api/v1/workspaces/:workspace_id/data-files/:id
+func (h *DataFileHandler) Update(c *gin.Context) {
+	file, ok := h.getFile(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateDataFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Apply updates
+	if req.Name != nil && *req.Name != file.Name {
+		if existing, err := h.repo.GetByName(*req.Name, file.WorkspaceID); err == nil && existing.ID != file.ID {
+			c.JSON(http.StatusConflict, gin.H{"error": "a data file with this name already exists"})
+			return
+		}
+		file.Name = *req.Name
+	}
+	if req.Description != nil {
+		file.Description = *req.Description
+	}
+	if req.Format != nil {
+		file.Format = *req.Format
+	}
+	if req.Content != nil {
+		file.Content = *req.Content
+	}
+	if err := prepareDataFile(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Update(file, file.WorkspaceID); err != nil {
+		h.logger.Error("Failed to update data file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update data file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, file)
+}
+
+// Delete handles DELETE /api/v1/workspaces/:workspace_id/data-files/:id
+func (h *DataFileHandler) Delete(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data file ID"})
+		return
+	}
+
+	if err := h.repo.Delete(id, workspaceID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data file not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Data file deleted"})
+}
+
+// getFile loads the data file of the request, writing the error response
+// when it cannot
+func (h *DataFileHandler) getFile(c *gin.Context) (*models.DataFile, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data file ID"})
+		return nil, false
+	}
+
+	file, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data file not found"})
+		return nil, false
+	}
+	return file, true
+}
+
+// prepareDataFile resolves the format of a data file from its name when
+// missing, and counts its rows, rejecting content that does not parse
+func prepareDataFile(file *models.DataFile) error {
+	if file.Format == "" {
+		file.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Name)), ".")
+	}
+	if file.Format != "csv" && file.Format != "json" {
+		return fmt.Errorf("format must be csv or json")
+	}
+
+	rows, err := runner.ParseDataFile(file.Format, file.Content)
+	if err != nil {
+		return err
+	}
+	file.RowCount = len(rows)
+	return nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
 	"github.com/gin-gonic/gin"
@@ -135,6 +136,19 @@ func (h *DebugHandler) AddBreakpoint(c *gin.Context) {
 		return
 	}
 
+	bp := newBreakpoint(req)
+	if err := h.controller.AddBreakpoint(executionID, bp); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"breakpoint": bp.ToJSON(),
+	})
+}
+
+// newBreakpoint creates the breakpoint of a request
+func newBreakpoint(req AddBreakpointRequest) *debugger.Breakpoint {
 	var bp *debugger.Breakpoint
 	switch req.Type {
 	case "conditional":
@@ -150,15 +164,7 @@ func (h *DebugHandler) AddBreakpoint(c *gin.Context) {
 	if req.LogPoint != "" {
 		bp.SetLogPoint(req.LogPoint)
 	}
-
-	if err := h.controller.AddBreakpoint(executionID, bp); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"breakpoint": bp.ToJSON(),
-	})
+	return bp
 }
 
 // RemoveBreakpoint removes a breakpoint from a debug session
@@ -316,14 +322,96 @@ func (h *DebugHandler) GetState(c *gin.Context) {
 		return
 	}
 
+	reason, config, lastError := session.GetStopped()
 	c.JSON(http.StatusOK, gin.H{
 		"state":        string(session.GetState()),
 		"current_step": session.GetCurrentStep(),
 		"variables":    session.GetVariables(),
-		"step_outputs": session.StepOutputs,
+		"step_outputs": session.GetStepOutputs(),
+		"stack":        session.GetStack(),
+		"stop_reason":  reason,
+		"step_config":  config,
+		"last_error":   lastError,
+	})
+}
+
+// EvaluateRequest is the request body for evaluating an expression
+type EvaluateRequest struct {
+	Expression string `json:"expression" binding:"required"`
+}
+
+// Evaluate evaluates an expression against the variables and step outputs
+// POST /api/v1/debug/sessions/:id/evaluate
+func (h *DebugHandler) Evaluate(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution id"})
+		return
+	}
+
+	var req EvaluateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Accept interpolation syntax as copied from flows
+	expression := strings.TrimSpace(req.Expression)
+	if strings.HasPrefix(expression, "${") && strings.HasSuffix(expression, "}") {
+		expression = strings.TrimSpace(expression[2 : len(expression)-1])
+	}
+
+	if _, ok := h.controller.GetSession(executionID); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	result, err := h.controller.Evaluate(executionID, expression)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result": result,
 	})
 }
 
+// SetVariableRequest is the request body for changing a variable
+type SetVariableRequest struct {
+	StepID string      `json:"step_id,omitempty"` // Change an output of this step instead of a variable
+	Name   string      `json:"name" binding:"required"`
+	Value  interface{} `json:"value"`
+}
+
+// SetVariable changes a variable or step output of a paused execution
+// PUT /api/v1/debug/sessions/:id/variables
+func (h *DebugHandler) SetVariable(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution id"})
+		return
+	}
+
+	var req SetVariableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := h.controller.GetSession(executionID); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	if err := h.controller.SetVariable(executionID, req.StepID, req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "variable set"})
+}
+
 // GetHistory returns the step execution history
 // GET /api/v1/debug/sessions/:id/history
 func (h *DebugHandler) GetHistory(c *gin.Context) {
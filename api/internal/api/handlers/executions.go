@@ -1,18 +1,30 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
+	"github.com/georgi-georgiev/testmesh/internal/quarantine"
+	"github.com/georgi-georgiev/testmesh/internal/reporting"
 	"github.com/georgi-georgiev/testmesh/internal/runner"
+	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
 	"github.com/georgi-georgiev/testmesh/internal/runner/mocks"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // ExecutionHandler handles execution-related requests
@@ -21,30 +33,90 @@ type ExecutionHandler struct {
 	flowRepo     *repository.FlowRepository
 	envRepo      *repository.EnvironmentRepository
 	contractRepo *repository.ContractRepository
+	specRepo     *repository.APISpecRepository
+	snapshotRepo *repository.SnapshotRepository
 	mockManager  *mocks.Manager
 	logger       *zap.Logger
 	wsHub        runner.WSHub
+	quarantine   *quarantine.Manager
+	reports      *reporting.Generator
+	metrics      *metrics.Metrics
+	artifacts    *artifacts.Manager
+	debug        *debugger.Controller
+	contextRepo  *repository.ContextSnapshotRepository
+	schemaDir    string
 }
 
 // NewExecutionHandler creates a new execution handler
-func NewExecutionHandler(execRepo *repository.ExecutionRepository, flowRepo *repository.FlowRepository, envRepo *repository.EnvironmentRepository, contractRepo *repository.ContractRepository, mockManager *mocks.Manager, logger *zap.Logger, wsHub runner.WSHub) *ExecutionHandler {
+func NewExecutionHandler(execRepo *repository.ExecutionRepository, flowRepo *repository.FlowRepository, envRepo *repository.EnvironmentRepository, contractRepo *repository.ContractRepository, specRepo *repository.APISpecRepository, snapshotRepo *repository.SnapshotRepository, mockManager *mocks.Manager, logger *zap.Logger, wsHub runner.WSHub) *ExecutionHandler {
 	return &ExecutionHandler{
 		execRepo:     execRepo,
 		flowRepo:     flowRepo,
 		envRepo:      envRepo,
 		contractRepo: contractRepo,
+		specRepo:     specRepo,
+		snapshotRepo: snapshotRepo,
 		mockManager:  mockManager,
 		logger:       logger,
 		wsHub:        wsHub,
 	}
 }
 
+// SetQuarantine sets the manager that reruns failed executions and reviews quarantined failures
+func (h *ExecutionHandler) SetQuarantine(manager *quarantine.Manager) {
+	h.quarantine = manager
+}
+
+// SetReportGenerator sets the generator that exports executions to result formats
+func (h *ExecutionHandler) SetReportGenerator(generator *reporting.Generator) {
+	h.reports = generator
+}
+
+// SetMetrics sets the collectors that record execution and step outcomes
+func (h *ExecutionHandler) SetMetrics(m *metrics.Metrics) {
+	h.metrics = m
+}
+
+// SetArtifacts sets the manager that stores step artifacts outside of the database
+func (h *ExecutionHandler) SetArtifacts(manager *artifacts.Manager) {
+	h.artifacts = manager
+}
+
+// SetDebugController sets the controller of executions started in debug mode
+func (h *ExecutionHandler) SetDebugController(controller *debugger.Controller) {
+	h.debug = controller
+}
+
+// SetSchemaDir sets the directory schema assertion files are read from
+func (h *ExecutionHandler) SetSchemaDir(dir string) {
+	h.schemaDir = dir
+}
+
+// SetContextSnapshots sets the repository that records the context of
+// executions before each step, so that they can be restarted from a step
+func (h *ExecutionHandler) SetContextSnapshots(repo *repository.ContextSnapshotRepository) {
+	h.contextRepo = repo
+}
+
+// DebugOptions starts an execution in a debug session, so that it pauses on
+// breakpoints from its first step on
+type DebugOptions struct {
+	Breakpoints []AddBreakpointRequest `json:"breakpoints"`
+	StopOnEntry bool                   `json:"stop_on_entry"` // Pause before the first step
+}
+
 // Create handles POST /api/v1/executions
+// Runs a stored flow, or with yaml instead of flow_id an ad-hoc flow that is
+// kept with the execution but not listed with the workspace flows.
 func (h *ExecutionHandler) Create(c *gin.Context) {
 	var req struct {
-		FlowID      string            `json:"flow_id" binding:"required"`
-		Environment string            `json:"environment"`
-		Variables   map[string]string `json:"variables"`
+		FlowID          string            `json:"flow_id"`
+		YAML            string            `json:"yaml"` // Definition of an ad-hoc flow
+		Environment     string            `json:"environment"`
+		Variables       map[string]string `json:"variables"`
+		UpdateSnapshots bool              `json:"update_snapshots"` // Replace differing golden records instead of failing
+		Debug           *DebugOptions     `json:"debug,omitempty"`
+		RecordContext   bool              `json:"record_context"` // Record the context before each step for restarts
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -52,58 +124,416 @@ func (h *ExecutionHandler) Create(c *gin.Context) {
 		return
 	}
 
-	flowID, err := uuid.Parse(req.FlowID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flow ID"})
+	if (req.FlowID == "") == (req.YAML == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either flow_id or yaml is required"})
+		return
+	}
+	if req.Debug != nil && h.debug == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "debugging is not available"})
 		return
 	}
 
 	// Get workspace ID from context
 	workspaceID := middleware.GetWorkspaceID(c)
 
-	// Get flow
-	flow, err := h.flowRepo.GetByID(flowID, workspaceID)
+	var flow *models.Flow
+	if req.YAML != "" {
+		if workspaceID == uuid.Nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+			return
+		}
+		definition, err := parseFlowYAML(req.YAML)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid YAML: " + err.Error()})
+			return
+		}
+
+		// Executions of the same YAML share an ad-hoc flow, so that runs
+		// of an unchanged file do not add a flow each
+		sum := sha256.Sum256([]byte(req.YAML))
+		contentHash := hex.EncodeToString(sum[:])
+		flow, err = h.flowRepo.GetAdHocByHash(contentHash, workspaceID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Error("Failed to look up ad-hoc flow", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create flow"})
+			return
+		}
+		if err != nil {
+			flow = &models.Flow{
+				Name:        definition.Name,
+				Description: definition.Description,
+				Suite:       definition.Suite,
+				Tags:        definition.Tags,
+				Definition:  definition,
+				Environment: "default",
+				AdHoc:       true,
+				ContentHash: contentHash,
+			}
+			if err := h.flowRepo.Create(flow, workspaceID); err != nil {
+				h.logger.Error("Failed to create ad-hoc flow", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create flow"})
+				return
+			}
+		}
+	} else {
+		flowID, err := uuid.Parse(req.FlowID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flow ID"})
+			return
+		}
+		if flow, err = h.flowRepo.GetByID(flowID, workspaceID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+			return
+		}
+	}
+
+	// Create execution record. Debugged executions record their context, so
+	// that debuggers can step back.
+	execution := &models.Execution{
+		FlowID:        flow.ID,
+		Status:        models.ExecutionStatusPending,
+		Environment:   req.Environment,
+		RecordContext: h.contextRepo != nil && (req.RecordContext || req.Debug != nil),
+	}
+
+	if err := h.execRepo.Create(execution); err != nil {
+		h.logger.Error("Failed to create execution", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create execution"})
+		return
+	}
+
+	// Start execution in background
+	if err := h.startInBackground(execution, flow, req.Variables, req.Environment, workspaceID, req.UpdateSnapshots, req.Debug, nil); err != nil {
+		h.logger.Error("Failed to start debug session", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start debug session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, execution)
+}
+
+// startInBackground starts an execution without waiting for it. With debug
+// options, the debug session starts before the execution, so that no step
+// runs before its breakpoints are set.
+func (h *ExecutionHandler) startInBackground(execution *models.Execution, flow *models.Flow, variables map[string]string, environmentRef string, workspaceID uuid.UUID, updateSnapshots bool, debug *DebugOptions, start *runner.StartPoint) error {
+	if debug == nil {
+		go h.executeFlow(execution, flow, variables, environmentRef, workspaceID, updateSnapshots, start)
+		return nil
+	}
+
+	if err := h.startDebugSession(execution, debug); err != nil {
+		return err
+	}
+	go func() {
+		defer h.debug.EndSession(execution.ID)
+		h.executeFlow(execution, flow, variables, environmentRef, workspaceID, updateSnapshots, start)
+	}()
+	return nil
+}
+
+// startDebugSession starts the debug session of an execution with its initial
+// breakpoints
+func (h *ExecutionHandler) startDebugSession(execution *models.Execution, options *DebugOptions) error {
+	if _, err := h.debug.StartSession(execution.ID, execution.FlowID); err != nil {
+		return err
+	}
+	for _, req := range options.Breakpoints {
+		if err := h.debug.AddBreakpoint(execution.ID, newBreakpoint(req)); err != nil {
+			h.debug.EndSession(execution.ID)
+			return err
+		}
+	}
+	if options.StopOnEntry {
+		if err := h.debug.StopOnEntry(execution.ID); err != nil {
+			h.debug.EndSession(execution.ID)
+			return err
+		}
+	}
+	return nil
+}
+
+// ListContext handles GET /api/v1/workspaces/:workspace_id/executions/:id/context
+// Returns the context recorded before each step, in the order the steps started.
+func (h *ExecutionHandler) ListContext(c *gin.Context) {
+	execution, ok := h.loadExecution(c)
+	if !ok {
+		return
+	}
+	if h.contextRepo == nil {
+		c.JSON(http.StatusOK, gin.H{"snapshots": []models.ContextSnapshot{}, "total": 0})
+		return
+	}
+
+	snapshots, err := h.contextRepo.ListByExecution(execution.ID)
+	if err != nil {
+		h.logger.Error("Failed to list context snapshots", zap.String("execution_id", execution.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list context snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots": snapshots,
+		"total":     len(snapshots),
+	})
+}
+
+// RestartRequest restarts an execution from a top-level step
+type RestartRequest struct {
+	StepID      string                            `json:"step_id" binding:"required"`
+	Variables   map[string]string                 `json:"variables"`    // Replace recorded variables
+	StepOutputs map[string]map[string]interface{} `json:"step_outputs"` // Replace recorded outputs, merged per step
+	Debug       *DebugOptions                     `json:"debug,omitempty"`
+}
+
+// Restart handles POST /api/v1/workspaces/:workspace_id/executions/:id/restart
+// Runs the flow again from a step, with the context recorded before that step
+// in the original execution. Earlier steps are skipped; teardown still runs.
+func (h *ExecutionHandler) Restart(c *gin.Context) {
+	original, ok := h.loadExecution(c)
+	if !ok {
+		return
+	}
+
+	var req RestartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Debug != nil && h.debug == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "debugging is not available"})
+		return
+	}
+	if h.contextRepo == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "context recording is not available"})
+		return
+	}
+
+	snapshot, err := h.contextRepo.GetByStep(original.ID, req.StepID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("no context recorded before step %s; restarts need an execution run with record_context", req.StepID)})
+		return
+	}
+
+	flow, err := h.flowRepo.GetByIDUnscoped(original.FlowID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
 		return
 	}
+	if stepIDAt(&flow.Definition, snapshot.Phase, snapshot.StepIndex) != snapshot.StepID {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("the flow has changed since the execution; step %s is no longer at its recorded position", snapshot.StepID)})
+		return
+	}
 
-	// Create execution record
-	execution := &models.Execution{
-		FlowID:      flowID,
-		Status:      models.ExecutionStatusPending,
-		Environment: req.Environment,
+	// Redacted variables would run as ********, so they must be given again
+	var missing []string
+	for _, name := range runner.RedactedVariables(snapshot.Variables) {
+		if _, ok := req.Variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("variables %s were redacted when recorded; set them in variables to restart", strings.Join(missing, ", "))})
+		return
+	}
+
+	start := &runner.StartPoint{
+		Phase:       snapshot.Phase,
+		StepIndex:   snapshot.StepIndex,
+		Variables:   make(map[string]string, len(snapshot.Variables)+len(req.Variables)),
+		StepOutputs: make(map[string]map[string]interface{}, len(snapshot.StepOutputs)),
+	}
+	for k, v := range snapshot.Variables {
+		start.Variables[k] = v
+	}
+	for k, v := range req.Variables {
+		start.Variables[k] = v
+	}
+	for stepID, outputs := range snapshot.StepOutputs {
+		start.StepOutputs[stepID] = outputs
+	}
+	for stepID, changes := range req.StepOutputs {
+		outputs := make(map[string]interface{}, len(start.StepOutputs[stepID])+len(changes))
+		for k, v := range start.StepOutputs[stepID] {
+			outputs[k] = v
+		}
+		for k, v := range changes {
+			outputs[k] = v
+		}
+		start.StepOutputs[stepID] = outputs
 	}
 
+	execution := &models.Execution{
+		FlowID:        original.FlowID,
+		Status:        models.ExecutionStatusPending,
+		Environment:   original.Environment,
+		RecordContext: true,
+		RestartOf:     &original.ID,
+		RestartFrom:   snapshot.StepID,
+	}
 	if err := h.execRepo.Create(execution); err != nil {
 		h.logger.Error("Failed to create execution", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create execution"})
 		return
 	}
 
-	// Start execution in background
-	go h.executeFlow(execution, flow, req.Variables, req.Environment, workspaceID)
+	if err := h.startInBackground(execution, flow, nil, original.Environment, flow.WorkspaceID, false, req.Debug, start); err != nil {
+		h.logger.Error("Failed to start debug session", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start debug session"})
+		return
+	}
 
 	c.JSON(http.StatusCreated, execution)
 }
 
-// executeFlow runs the flow execution
-func (h *ExecutionHandler) executeFlow(execution *models.Execution, flow *models.Flow, variables map[string]string, environmentRef string, workspaceID uuid.UUID) {
+// loadExecution loads the execution in the path, answering 404 for executions
+// of other workspaces
+func (h *ExecutionHandler) loadExecution(c *gin.Context) (*models.Execution, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return nil, false
+	}
+
+	execution, err := h.execRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return nil, false
+	}
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID != uuid.Nil && execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return nil, false
+	}
+	return execution, true
+}
+
+// stepIDAt returns the ID of the top-level step at a position of a phase, as
+// the executor names it
+func stepIDAt(definition *models.FlowDefinition, phase string, index int) string {
+	var steps []models.Step
+	switch phase {
+	case "setup":
+		steps = definition.Setup
+	case "main":
+		steps = definition.Steps
+	case "teardown":
+		steps = definition.Teardown
+	}
+	if index < 0 || index >= len(steps) {
+		return ""
+	}
+	if steps[index].ID != "" {
+		return steps[index].ID
+	}
+	return fmt.Sprintf("%s_%d", phase, index)
+}
+
+// executeFlow runs the flow execution, rerunning it on failure as often as the
+// workspace quarantine policy allows, and returns the last attempt. Restarted
+// executions begin at their start point, and so do their reruns.
+func (h *ExecutionHandler) executeFlow(execution *models.Execution, flow *models.Flow, variables map[string]string, environmentRef string, workspaceID uuid.UUID, updateSnapshots bool, start *runner.StartPoint) *models.Execution {
+	retries := 0
+	if h.quarantine != nil {
+		retries = h.quarantine.Policy(workspaceID).RetryOnFailure
+	}
+
+	first := execution
+	if first.Attempt == 0 {
+		first.Attempt = 1
+	}
+
+	for {
+		h.runAttempt(execution, flow, variables, environmentRef, workspaceID, updateSnapshots, start)
+
+		// Create the rerun before saving the failure, so that nothing polling
+		// the execution sees the run as finished
+		var retry *models.Execution
+		if execution.Status == models.ExecutionStatusFailed && execution.Attempt <= retries {
+			retry = &models.Execution{
+				FlowID:        execution.FlowID,
+				Status:        models.ExecutionStatusPending,
+				Environment:   execution.Environment,
+				ScheduleRunID: execution.ScheduleRunID,
+				Attempt:       execution.Attempt + 1,
+				RetryOf:       &first.ID,
+				RecordContext: execution.RecordContext,
+				RestartOf:     execution.RestartOf,
+				RestartFrom:   execution.RestartFrom,
+			}
+			if err := h.execRepo.Create(retry); err != nil {
+				h.logger.Error("Failed to create execution rerun", zap.Error(err))
+				retry = nil
+			}
+		}
+
+		if h.quarantine != nil {
+			h.quarantine.Review(execution)
+		}
+		h.metrics.ExecutionFinished(flow.Name, flow.Suite, execution.Environment, executionOutcome(execution),
+			time.Duration(execution.DurationMs)*time.Millisecond, execution.Attempt)
+		h.execRepo.Update(execution)
+		h.broadcastResult(execution)
+
+		if retry == nil {
+			break
+		}
+		h.logger.Info("Rerunning failed execution",
+			zap.String("execution_id", first.ID.String()),
+			zap.Int("attempt", retry.Attempt))
+		execution = retry
+	}
+
+	if h.quarantine != nil {
+		h.quarantine.RecordRun(workspaceID, first)
+	}
+	return execution
+}
+
+// runAttempt runs one attempt of a flow execution and sets its outcome
+func (h *ExecutionHandler) runAttempt(execution *models.Execution, flow *models.Flow, variables map[string]string, environmentRef string, workspaceID uuid.UUID, updateSnapshots bool, start *runner.StartPoint) {
 	// Update status to running
 	execution.Status = models.ExecutionStatusRunning
 	now := time.Now()
 	execution.StartedAt = &now
 	h.execRepo.Update(execution)
+	h.metrics.ExecutionStarted()
 
 	// Merge environment variables into the execution context
-	mergedVars := h.mergeEnvironmentVariables(environmentRef, workspaceID, variables)
+	env := h.loadEnvironment(environmentRef, workspaceID)
+	mergedVars := mergeEnvironmentVariables(env, variables)
 
 	// Ensure all mock servers for this execution are stopped when done
 	defer h.mockManager.StopServersByExecution(execution.ID)
 
 	// Execute flow using the runner
 	executor := runner.NewExecutor(h.execRepo, h.contractRepo, h.logger, h.wsHub, h.mockManager)
-	err := executor.Execute(execution, &flow.Definition, mergedVars)
+	executor.SetSchemaResolver(&workspaceSpecResolver{repo: h.specRepo, workspaceID: workspaceID})
+	executor.SetFlowResolver(runner.NewWorkspaceFlowResolver(h.flowRepo, workspaceID))
+	executor.SetSchemaDir(h.schemaDir)
+	executor.SetSnapshotRepository(h.snapshotRepo)
+	executor.SetUpdateSnapshots(updateSnapshots)
+	executor.SetMetrics(h.metrics)
+	if h.artifacts != nil {
+		executor.SetArtifacts(h.artifacts, workspaceID)
+	}
+	if h.debug != nil {
+		if _, ok := h.debug.GetSession(execution.ID); ok {
+			executor.SetDebugController(h.debug)
+		}
+	}
+	if h.contextRepo != nil && execution.RecordContext {
+		// Secrets are not recorded; restarts take them from the environment again
+		var secrets []string
+		if env != nil {
+			for _, v := range env.Variables {
+				if v.IsSecret {
+					secrets = append(secrets, v.Key)
+				}
+			}
+		}
+		executor.SetContextRecorder(h.contextRepo, secrets)
+	}
+	err := executor.ExecuteFrom(execution, &flow.Definition, mergedVars, start)
 
 	// Update execution status
 	finishedAt := time.Now()
@@ -113,29 +543,108 @@ func (h *ExecutionHandler) executeFlow(execution *models.Execution, flow *models
 	if err != nil {
 		execution.Status = models.ExecutionStatusFailed
 		execution.Error = err.Error()
-
-		// Broadcast execution failed
-		if h.wsHub != nil {
-			h.wsHub.BroadcastExecutionFailed(execution.ID, map[string]interface{}{
-				"error":       execution.Error,
-				"duration_ms": execution.DurationMs,
-			})
-		}
 	} else {
 		execution.Status = models.ExecutionStatusCompleted
+	}
+}
+
+// executionOutcome returns the status label of a finished attempt
+func executionOutcome(execution *models.Execution) string {
+	switch {
+	case execution.Status == models.ExecutionStatusCompleted:
+		return "passed"
+	case execution.Quarantined:
+		return "quarantined"
+	default:
+		return string(execution.Status)
+	}
+}
+
+// broadcastResult broadcasts the outcome of a finished execution attempt
+func (h *ExecutionHandler) broadcastResult(execution *models.Execution) {
+	if h.wsHub == nil {
+		return
+	}
+
+	if execution.Status == models.ExecutionStatusFailed {
+		// Broadcast execution failed
+		h.wsHub.BroadcastExecutionFailed(execution.ID, map[string]interface{}{
+			"error":       execution.Error,
+			"duration_ms": execution.DurationMs,
+			"attempt":     execution.Attempt,
+			"quarantined": execution.Quarantined,
+		})
+		return
+	}
 
-		// Broadcast execution completed
-		if h.wsHub != nil {
-			h.wsHub.BroadcastExecutionCompleted(execution.ID, map[string]interface{}{
-				"passed_steps": execution.PassedSteps,
-				"failed_steps": execution.FailedSteps,
-				"total_steps":  execution.TotalSteps,
-				"duration_ms":  execution.DurationMs,
-			})
+	// Broadcast execution completed
+	h.wsHub.BroadcastExecutionCompleted(execution.ID, map[string]interface{}{
+		"passed_steps": execution.PassedSteps,
+		"failed_steps": execution.FailedSteps,
+		"total_steps":  execution.TotalSteps,
+		"duration_ms":  execution.DurationMs,
+		"attempt":      execution.Attempt,
+	})
+}
+
+// RunScheduled executes a flow as part of a schedule run and waits for it to
+// finish. Schedule environment values become runtime variables; an
+// "environment" entry selects the environment to merge.
+func (h *ExecutionHandler) RunScheduled(ctx context.Context, flowID uuid.UUID, runID uuid.UUID, env map[string]interface{}) (uuid.UUID, string, error) {
+	flow, err := h.flowRepo.GetByIDUnscoped(flowID)
+	if err != nil {
+		return uuid.Nil, "failure", fmt.Errorf("flow not found: %w", err)
+	}
+
+	environmentRef := ""
+	variables := make(map[string]string, len(env))
+	for k, v := range env {
+		if k == "environment" {
+			environmentRef = fmt.Sprint(v)
+			continue
 		}
+		variables[k] = fmt.Sprint(v)
 	}
 
-	h.execRepo.Update(execution)
+	execution := &models.Execution{
+		FlowID:        flow.ID,
+		Status:        models.ExecutionStatusPending,
+		Environment:   environmentRef,
+		ScheduleRunID: &runID,
+	}
+	if err := h.execRepo.Create(execution); err != nil {
+		return uuid.Nil, "failure", err
+	}
+
+	last := h.executeFlow(execution, flow, variables, environmentRef, flow.WorkspaceID, false, nil)
+
+	switch {
+	case last.Status == models.ExecutionStatusCompleted:
+		return last.ID, "success", nil
+	case last.Quarantined:
+		return last.ID, "quarantined", nil
+	}
+	return last.ID, "failure", errors.New(last.Error)
+}
+
+// StartFlow starts a flow outside of an API request, e.g. from a git webhook,
+// and returns the execution ID without waiting for it to finish
+func (h *ExecutionHandler) StartFlow(flowID uuid.UUID, variables map[string]string) (uuid.UUID, error) {
+	flow, err := h.flowRepo.GetByIDUnscoped(flowID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("flow not found: %w", err)
+	}
+
+	execution := &models.Execution{
+		FlowID: flow.ID,
+		Status: models.ExecutionStatusPending,
+	}
+	if err := h.execRepo.Create(execution); err != nil {
+		return uuid.Nil, err
+	}
+
+	go h.executeFlow(execution, flow, variables, "", flow.WorkspaceID, false, nil)
+	return execution.ID, nil
 }
 
 // List handles GET /api/v1/executions
@@ -192,6 +701,41 @@ func (h *ExecutionHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, execution)
 }
 
+// Export handles GET /api/v1/workspaces/:workspace_id/executions/:id/export
+// Renders the execution in ?format=allure|ctrf|tap or the format of a reporter plugin.
+func (h *ExecutionHandler) Export(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+
+	execution, err := h.execRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID != uuid.Nil && execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	format := c.Query("format")
+	if _, ok := h.reports.Exporters().Get(format); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported format: " + format, "formats": h.reports.Exporters().Formats()})
+		return
+	}
+
+	content, exporter, err := h.reports.ExportExecution(c.Request.Context(), execution, format)
+	if err != nil {
+		h.logger.Error("Failed to export execution", zap.String("id", id.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	writeExport(c, content, exporter, fmt.Sprintf("execution-%s", execution.ID))
+}
+
 // Cancel handles POST /api/v1/executions/:id/cancel
 func (h *ExecutionHandler) Cancel(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -280,46 +824,55 @@ func (h *ExecutionHandler) GetStep(c *gin.Context) {
 	c.JSON(http.StatusOK, step)
 }
 
-// mergeEnvironmentVariables fetches environment variables and merges them with runtime variables.
+// loadEnvironment fetches the environment of an execution by ID or name,
+// falling back to the default environment of the workspace. It returns nil
+// when no environment is selected or none is found.
+func (h *ExecutionHandler) loadEnvironment(environmentRef string, workspaceID uuid.UUID) *models.Environment {
+	if environmentRef == "" {
+		return nil
+	}
+
+	var env *models.Environment
+	var err error
+
+	// Try parsing as UUID first
+	if envID, parseErr := uuid.Parse(environmentRef); parseErr == nil {
+		env, err = h.envRepo.GetByID(envID, workspaceID)
+	} else {
+		// Fall back to name lookup
+		env, err = h.envRepo.GetByName(environmentRef, workspaceID)
+	}
+
+	if err != nil {
+		h.logger.Warn("Failed to fetch environment by ref, trying default",
+			zap.String("environment", environmentRef),
+			zap.Error(err))
+		// Fall back to default environment for the workspace
+		env, err = h.envRepo.GetDefault(workspaceID)
+		if err != nil {
+			h.logger.Warn("Failed to fetch default environment", zap.Error(err))
+			return nil
+		}
+	}
+	h.logger.Debug("Loaded environment variables",
+		zap.String("environment", env.Name),
+		zap.Int("variable_count", len(env.Variables)))
+	return env
+}
+
+// mergeEnvironmentVariables merges environment variables with runtime variables.
 // Priority order (later overrides earlier):
 //   1. Environment variables (from selected environment)
 //   2. Runtime variables (passed at execution time)
-func (h *ExecutionHandler) mergeEnvironmentVariables(environmentRef string, workspaceID uuid.UUID, runtimeVars map[string]string) map[string]string {
+func mergeEnvironmentVariables(env *models.Environment, runtimeVars map[string]string) map[string]string {
 	merged := make(map[string]string)
 
-	// Fetch environment if specified
-	if environmentRef != "" {
-		var env *models.Environment
-		var err error
-
-		// Try parsing as UUID first
-		if envID, parseErr := uuid.Parse(environmentRef); parseErr == nil {
-			env, err = h.envRepo.GetByID(envID, workspaceID)
-		} else {
-			// Fall back to name lookup
-			env, err = h.envRepo.GetByName(environmentRef, workspaceID)
-		}
-
-		if err != nil {
-			h.logger.Warn("Failed to fetch environment by ref, trying default",
-				zap.String("environment", environmentRef),
-				zap.Error(err))
-			// Fall back to default environment for the workspace
-			env, err = h.envRepo.GetDefault(workspaceID)
-			if err != nil {
-				h.logger.Warn("Failed to fetch default environment", zap.Error(err))
-			}
-		}
-		if env != nil {
-			// Add enabled environment variables
-			for _, v := range env.Variables {
-				if v.Enabled {
-					merged[v.Key] = v.Value
-				}
+	if env != nil {
+		// Add enabled environment variables
+		for _, v := range env.Variables {
+			if v.Enabled {
+				merged[v.Key] = v.Value
 			}
-			h.logger.Debug("Loaded environment variables",
-				zap.String("environment", env.Name),
-				zap.Int("variable_count", len(env.Variables)))
 		}
 	}
 
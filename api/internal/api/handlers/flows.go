@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/gitsync"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
@@ -15,8 +16,9 @@ import (
 
 // FlowHandler handles flow-related requests
 type FlowHandler struct {
-	repo   *repository.FlowRepository
-	logger *zap.Logger
+	repo    *repository.FlowRepository
+	gitSync *gitsync.Syncer
+	logger  *zap.Logger
 }
 
 // NewFlowHandler creates a new flow handler
@@ -176,6 +178,10 @@ func (h *FlowHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if h.gitSync != nil {
+		h.gitSync.FlowSaved(flow, middleware.GetUserID(c))
+	}
+
 	c.JSON(http.StatusOK, flow)
 }
 
@@ -193,15 +199,30 @@ func (h *FlowHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	flow, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+		return
+	}
+
 	if err := h.repo.Delete(id, workspaceID); err != nil {
 		h.logger.Error("Failed to delete flow", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete flow"})
 		return
 	}
 
+	if h.gitSync != nil {
+		h.gitSync.FlowDeleted(flow)
+	}
+
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// SetGitSync sets the syncer that commits edits of flows in git-synced collections
+func (h *FlowHandler) SetGitSync(syncer *gitsync.Syncer) {
+	h.gitSync = syncer
+}
+
 // parseFlowYAML parses YAML supporting both wrapped (flow:) and unwrapped formats
 func parseFlowYAML(yamlContent string) (models.FlowDefinition, error) {
 	// First try wrapped format: flow: { name: ..., steps: ... }
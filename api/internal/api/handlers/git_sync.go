@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/gitsync"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GitSyncHandler handles git sync binding requests
+type GitSyncHandler struct {
+	repo            *repository.GitSyncRepository
+	collectionRepo  *repository.CollectionRepository
+	integrationRepo *repository.IntegrationRepository
+	syncer          *gitsync.Syncer
+	logger          *zap.Logger
+}
+
+// NewGitSyncHandler creates a new git sync handler
+func NewGitSyncHandler(
+	repo *repository.GitSyncRepository,
+	collectionRepo *repository.CollectionRepository,
+	integrationRepo *repository.IntegrationRepository,
+	syncer *gitsync.Syncer,
+	logger *zap.Logger,
+) *GitSyncHandler {
+	return &GitSyncHandler{
+		repo:            repo,
+		collectionRepo:  collectionRepo,
+		integrationRepo: integrationRepo,
+		syncer:          syncer,
+		logger:          logger,
+	}
+}
+
+// GitSyncBindingRequest represents a request to create or update a git sync binding
+type GitSyncBindingRequest struct {
+	CollectionID      uuid.UUID  `json:"collection_id" binding:"required"`
+	IntegrationID     *uuid.UUID `json:"integration_id"`
+	RepositoryURL     string     `json:"repository_url" binding:"required"`
+	Repository        string     `json:"repository"`
+	Branch            string     `json:"branch"`
+	Path              string     `json:"path"`
+	PushBranch        string     `json:"push_branch"`
+	CreatePullRequest bool       `json:"create_pull_request"`
+	SyncInterval      string     `json:"sync_interval"`
+	Enabled           *bool      `json:"enabled"`
+}
+
+// ResolveConflictRequest represents a request to resolve a conflicting flow file
+type ResolveConflictRequest struct {
+	FlowID uuid.UUID    `json:"flow_id" binding:"required"`
+	Keep   gitsync.Keep `json:"keep" binding:"required"`
+}
+
+// List handles GET /api/v1/workspaces/:workspace_id/git-sync
+func (h *GitSyncHandler) List(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	bindings, err := h.repo.List(workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to list git sync bindings", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list git sync bindings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bindings": bindings, "total": len(bindings)})
+}
+
+// Create handles POST /api/v1/workspaces/:workspace_id/git-sync
+func (h *GitSyncHandler) Create(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	var req GitSyncBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	binding := &models.GitSyncBinding{WorkspaceID: workspaceID, Enabled: true}
+	if err := h.applyRequest(binding, &req, workspaceID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if existing, err := h.repo.GetByCollection(binding.CollectionID); err == nil && existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "collection is already synced with a repository"})
+		return
+	}
+
+	if err := h.repo.Create(binding); err != nil {
+		h.logger.Error("Failed to create git sync binding", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create git sync binding"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, binding)
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/git-sync/:id
+func (h *GitSyncHandler) Get(c *gin.Context) {
+	binding, ok := h.loadBinding(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, binding)
+}
+
+// Update handles PUT /api/v1/workspaces/:workspace_id/git-sync/:id
+func (h *GitSyncHandler) Update(c *gin.Context) {
+	binding, ok := h.loadBinding(c)
+	if !ok {
+		return
+	}
+
+	var req GitSyncBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CollectionID != binding.CollectionID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the collection of a binding cannot be changed"})
+		return
+	}
+
+	if err := h.applyRequest(binding, &req, binding.WorkspaceID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Update(binding); err != nil {
+		h.logger.Error("Failed to update git sync binding", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update git sync binding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, binding)
+}
+
+// Delete handles DELETE /api/v1/workspaces/:workspace_id/git-sync/:id
+// Flows pulled from git stay in the collection.
+func (h *GitSyncHandler) Delete(c *gin.Context) {
+	binding, ok := h.loadBinding(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(binding.ID, binding.WorkspaceID); err != nil {
+		h.logger.Error("Failed to delete git sync binding", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete git sync binding"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Sync handles POST /api/v1/workspaces/:workspace_id/git-sync/:id/sync
+func (h *GitSyncHandler) Sync(c *gin.Context) {
+	binding, ok := h.loadBinding(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.syncer.Sync(ctx, binding.ID)
+	h.respondSync(c, result, err)
+}
+
+// Resolve handles POST /api/v1/workspaces/:workspace_id/git-sync/:id/resolve
+func (h *GitSyncHandler) Resolve(c *gin.Context) {
+	binding, ok := h.loadBinding(c)
+	if !ok {
+		return
+	}
+
+	var req ResolveConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Keep != gitsync.KeepGit && req.Keep != gitsync.KeepTestMesh {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "keep must be \"git\" or \"testmesh\""})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.syncer.Resolve(ctx, binding.ID, req.FlowID, req.Keep)
+	h.respondSync(c, result, err)
+}
+
+func (h *GitSyncHandler) respondSync(c *gin.Context, result *gitsync.Result, err error) {
+	if errors.Is(err, gitsync.ErrSyncInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Warn("Git sync failed", zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// loadBinding loads the binding named by the :id parameter in the request's workspace
+func (h *GitSyncHandler) loadBinding(c *gin.Context) (*models.GitSyncBinding, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid binding ID"})
+		return nil, false
+	}
+
+	binding, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "git sync binding not found"})
+		return nil, false
+	}
+	return binding, true
+}
+
+// applyRequest validates a request and copies it onto a binding
+func (h *GitSyncHandler) applyRequest(binding *models.GitSyncBinding, req *GitSyncBindingRequest, workspaceID uuid.UUID) error {
+	if _, err := h.collectionRepo.GetByID(req.CollectionID, workspaceID); err != nil {
+		return fmt.Errorf("collection not found")
+	}
+	if req.IntegrationID != nil {
+		integration, err := h.integrationRepo.Get(*req.IntegrationID)
+		if err != nil || integration.Type != models.IntegrationTypeGit {
+			return fmt.Errorf("git integration not found")
+		}
+	}
+	if req.SyncInterval != "" {
+		interval, err := time.ParseDuration(req.SyncInterval)
+		if err != nil || interval < time.Minute {
+			return fmt.Errorf("sync_interval must be a duration of at least 1m")
+		}
+	}
+	if strings.HasPrefix(req.RepositoryURL, "-") {
+		return fmt.Errorf("invalid repository_url")
+	}
+	if req.Branch != "" {
+		if err := gitsync.ValidateBranch(context.Background(), req.Branch); err != nil {
+			return err
+		}
+	}
+	if req.PushBranch != "" {
+		if err := gitsync.ValidateBranch(context.Background(), req.PushBranch); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(req.Path, "..") {
+		return fmt.Errorf("path must not leave the repository")
+	}
+	if req.CreatePullRequest && (req.PushBranch == "" || req.IntegrationID == nil) {
+		return fmt.Errorf("create_pull_request requires push_branch and integration_id")
+	}
+
+	binding.CollectionID = req.CollectionID
+	binding.IntegrationID = req.IntegrationID
+	binding.RepositoryURL = req.RepositoryURL
+	binding.Repository = req.Repository
+	binding.Branch = req.Branch
+	if binding.Branch == "" {
+		binding.Branch = "main"
+	}
+	binding.Path = strings.Trim(req.Path, "/")
+	binding.PushBranch = req.PushBranch
+	if binding.PushBranch == binding.Branch {
+		binding.PushBranch = ""
+	}
+	binding.CreatePullRequest = req.CreatePullRequest
+	binding.SyncInterval = req.SyncInterval
+	if req.Enabled != nil {
+		binding.Enabled = *req.Enabled
+	}
+	return nil
+}
@@ -1,37 +1,85 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
 	"github.com/georgi-georgiev/testmesh/internal/exporter"
 	"github.com/georgi-georgiev/testmesh/internal/importer"
+	"github.com/georgi-georgiev/testmesh/internal/runner/actions"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // ImportExportHandler handles import/export requests
 type ImportExportHandler struct {
-	flowRepo *repository.FlowRepository
-	logger   *zap.Logger
+	flowRepo       *repository.FlowRepository
+	collectionRepo *repository.CollectionRepository
+	envRepo        *repository.EnvironmentRepository
+	specRepo       *repository.APISpecRepository
+	logger         *zap.Logger
 }
 
 // NewImportExportHandler creates a new import/export handler
-func NewImportExportHandler(flowRepo *repository.FlowRepository, logger *zap.Logger) *ImportExportHandler {
+func NewImportExportHandler(flowRepo *repository.FlowRepository, collectionRepo *repository.CollectionRepository, envRepo *repository.EnvironmentRepository, specRepo *repository.APISpecRepository, logger *zap.Logger) *ImportExportHandler {
 	return &ImportExportHandler{
-		flowRepo: flowRepo,
-		logger:   logger,
+		flowRepo:       flowRepo,
+		collectionRepo: collectionRepo,
+		envRepo:        envRepo,
+		specRepo:       specRepo,
+		logger:         logger,
 	}
 }
 
 // ImportRequest represents an import request
 type ImportRequest struct {
-	Type    string `json:"type" binding:"required"` // "har", "curl", "postman"
-	Content string `json:"content" binding:"required"`
-	Preview bool   `json:"preview"` // If true, just preview without saving
+	Type    string            `json:"type" binding:"required"` // "har", "curl", "postman", "insomnia", "bruno", "openapi", "graphql"
+	Content string            `json:"content"`                 // Required, except for bruno
+	Files   map[string]string `json:"files"`                   // Bruno: the files of the collection by path
+	Preview bool              `json:"preview"`                 // If true, just preview without saving
+	GroupBy string            `json:"group_by"`                // OpenAPI: "operation" (default) or "tag"; GraphQL: "operation" or "type"
+}
+
+// ImportCollectionRequest represents a request to import a collection of an
+// API client: Postman, Insomnia or Bruno
+type ImportCollectionRequest struct {
+	Format          string            `json:"format" binding:"required"` // "postman", "insomnia" or "bruno"
+	Content         string            `json:"content"`                   // Postman and Insomnia exports
+	Files           map[string]string `json:"files"`                     // Bruno: the files of the collection by path
+	CollectionID    *string           `json:"collection_id"`             // Target collection (defaults to one named after the imported collection)
+	EnvironmentName string            `json:"environment_name"`          // Target environment (defaults to the collection name)
+	Preview         bool              `json:"preview"`
+}
+
+// ImportOpenAPIRequest represents a request to import an OpenAPI/Swagger document
+type ImportOpenAPIRequest struct {
+	Content         string  `json:"content" binding:"required"`
+	GroupBy         string  `json:"group_by"`         // "operation" (default) or "tag"
+	CollectionID    *string `json:"collection_id"`    // Target collection (defaults to one named after the API)
+	EnvironmentName string  `json:"environment_name"` // Target environment (defaults to the API title)
+	SpecName        string  `json:"spec_name"`        // Name the spec is attached under (defaults to the API title)
+	Preview         bool    `json:"preview"`
+}
+
+// ImportGraphQLRequest represents a request to import a GraphQL schema.
+// Either content (an introspection result) or url (an endpoint to introspect) is required.
+type ImportGraphQLRequest struct {
+	Content         string            `json:"content"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers"`   // Sent with the introspection query
+	Title           string            `json:"title"`     // Collection, suite and environment name (defaults to "GraphQL API")
+	GroupBy         string            `json:"group_by"`  // "operation" (default) or "type"
+	MaxDepth        int               `json:"max_depth"` // Selection set depth (default 2)
+	CollectionID    *string           `json:"collection_id"`
+	EnvironmentName string            `json:"environment_name"`
+	Preview         bool              `json:"preview"`
 }
 
 // ImportFlowsRequest represents a request to import parsed flows
@@ -45,9 +93,10 @@ type ImportFlowsRequest struct {
 // ExportRequest represents an export request
 type ExportRequest struct {
 	FlowIDs      []string `json:"flow_ids" binding:"required"`
-	Format       string   `json:"format" binding:"required"` // "postman", "openapi", "har", "testmesh"
+	Format       string   `json:"format" binding:"required"` // "postman", "insomnia", "bruno", "k6", "openapi", "har", "testmesh"
 	IncludeTests bool     `json:"include_tests"`
 	IncludeEnv   bool     `json:"include_env"`
+	Environment  string   `json:"environment"` // ID or name of the environment exported with include_env (defaults to the default environment)
 }
 
 // Parse handles POST /api/v1/import/parse
@@ -59,6 +108,11 @@ func (h *ImportExportHandler) Parse(c *gin.Context) {
 		return
 	}
 
+	if req.Content == "" && req.Type != "bruno" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content is required"})
+		return
+	}
+
 	var result *importer.ImportResult
 	var err error
 
@@ -67,8 +121,31 @@ func (h *ImportExportHandler) Parse(c *gin.Context) {
 		result, err = importer.ParseHAR(req.Content)
 	case "curl":
 		result, err = importer.ParseCURL(req.Content)
-	case "postman":
-		result, err = importer.ParsePostman(req.Content)
+	case "postman", "insomnia", "bruno":
+		var collectionResult *importer.CollectionImportResult
+		collectionResult, err = parseCollection(req.Type, req.Content, req.Files)
+		if err == nil {
+			c.JSON(http.StatusOK, collectionResult)
+			return
+		}
+	case "graphql":
+		var graphQLResult *importer.GraphQLImportResult
+		graphQLResult, err = importer.ParseGraphQLIntrospection(req.Content, importer.GraphQLOptions{
+			GroupBy: importer.GraphQLGroupBy(req.GroupBy),
+		})
+		if err == nil {
+			c.JSON(http.StatusOK, graphQLResult)
+			return
+		}
+	case "openapi", "swagger":
+		var openAPIResult *importer.OpenAPIImportResult
+		openAPIResult, err = importer.ParseOpenAPI(req.Content, importer.OpenAPIOptions{
+			GroupBy: importer.OpenAPIGroupBy(req.GroupBy),
+		})
+		if err == nil {
+			c.JSON(http.StatusOK, openAPIResult)
+			return
+		}
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported import type: " + req.Type})
 		return
@@ -106,7 +183,7 @@ func (h *ImportExportHandler) Import(c *gin.Context) {
 	}
 
 	created := []string{}
-	errors := []string{}
+	importErrs := []string{}
 
 	for _, flowDef := range req.Flows {
 		// Merge tags
@@ -133,7 +210,7 @@ func (h *ImportExportHandler) Import(c *gin.Context) {
 		}
 
 		if err := h.flowRepo.Create(flow, workspaceID); err != nil {
-			errors = append(errors, flowDef.Name+": "+err.Error())
+			importErrs = append(importErrs, flowDef.Name+": "+err.Error())
 			continue
 		}
 
@@ -142,15 +219,480 @@ func (h *ImportExportHandler) Import(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"created": created,
-		"errors":  errors,
+		"errors":  importErrs,
 		"stats": gin.H{
 			"total":     len(req.Flows),
 			"succeeded": len(created),
-			"failed":    len(errors),
+			"failed":    len(importErrs),
 		},
 	})
 }
 
+// ImportOpenAPI handles POST /api/v1/workspaces/:workspace_id/import/openapi
+// Creates or updates flows, the collection (with auth) and the environment derived from the spec.
+// Re-importing the same spec updates existing flows by name instead of duplicating them.
+func (h *ImportExportHandler) ImportOpenAPI(c *gin.Context) {
+	var req ImportOpenAPIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Response schema assertions reference the spec as attached to the workspace
+	result, err := importer.ParseOpenAPI(req.Content, importer.OpenAPIOptions{
+		GroupBy:    importer.OpenAPIGroupBy(req.GroupBy),
+		SchemaRef:  req.SpecName,
+		AttachSpec: true,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	specName := req.SpecName
+	if specName == "" {
+		specName = result.Title
+	}
+
+	if req.Preview {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	spec := &models.APISpec{
+		Name:     specName,
+		SpecType: result.SpecType,
+		Version:  result.Version,
+		Content:  req.Content,
+	}
+	if err := h.specRepo.Upsert(spec, workspaceID); err != nil {
+		h.logger.Error("Failed to attach API spec", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to attach API spec: " + err.Error()})
+		return
+	}
+
+	collection, err := h.upsertImportCollection(req.CollectionID, result.Title,
+		fmt.Sprintf("Imported from OpenAPI (version %s)", result.Version), result.Auth, workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to prepare import collection", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	envName := req.EnvironmentName
+	if envName == "" {
+		envName = result.Title
+	}
+	env, err := h.upsertImportEnvironment(envName, "Imported from OpenAPI", result.Variables, workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to prepare import environment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, updated, importErrs := h.saveImportedFlows(result.Flows, collection.ID, workspaceID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection_id":  collection.ID,
+		"environment_id": env.ID,
+		"spec_id":        spec.ID,
+		"created":        created,
+		"updated":        updated,
+		"errors":         importErrs,
+		"warnings":       result.Warnings,
+		"stats": gin.H{
+			"total":      len(result.Flows),
+			"created":    len(created),
+			"updated":    len(updated),
+			"failed":     len(importErrs),
+			"operations": result.Stats.TotalRequests,
+		},
+	})
+}
+
+// ImportGraphQL handles POST /api/v1/workspaces/:workspace_id/import/graphql
+// Introspects the endpoint (or uses the supplied introspection result) and creates or
+// updates one graphql flow per root field, plus the collection and environment.
+func (h *ImportExportHandler) ImportGraphQL(c *gin.Context) {
+	var req ImportGraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	content := req.Content
+	if content == "" {
+		if req.URL == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content or url is required"})
+			return
+		}
+
+		config := map[string]interface{}{
+			"url":           req.URL,
+			"introspection": true,
+		}
+		if len(req.Headers) > 0 {
+			config["headers"] = req.Headers
+		}
+		output, err := actions.NewGraphQLHandler(h.logger).Execute(c.Request.Context(), config)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "introspection failed: " + err.Error()})
+			return
+		}
+		body, err := json.Marshal(output["body"])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		content = string(body)
+	}
+
+	result, err := importer.ParseGraphQLIntrospection(content, importer.GraphQLOptions{
+		GroupBy:  importer.GraphQLGroupBy(req.GroupBy),
+		Title:    req.Title,
+		Endpoint: req.URL,
+		MaxDepth: req.MaxDepth,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Preview {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	collection, err := h.upsertImportCollection(req.CollectionID, result.Title, "Imported from GraphQL introspection", nil, workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to prepare import collection", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	envName := req.EnvironmentName
+	if envName == "" {
+		envName = result.Title
+	}
+	env, err := h.upsertImportEnvironment(envName, "Imported from GraphQL", result.Variables, workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to prepare import environment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, updated, importErrs := h.saveImportedFlows(result.Flows, collection.ID, workspaceID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection_id":  collection.ID,
+		"environment_id": env.ID,
+		"created":        created,
+		"updated":        updated,
+		"errors":         importErrs,
+		"warnings":       result.Warnings,
+		"stats": gin.H{
+			"total":      len(result.Flows),
+			"created":    len(created),
+			"updated":    len(updated),
+			"failed":     len(importErrs),
+			"operations": result.Stats.TotalRequests,
+		},
+	})
+}
+
+// ImportCollection handles POST /api/v1/workspaces/:workspace_id/import/collection
+// Imports a Postman, Insomnia or Bruno collection: folders become collections,
+// folders of requests flows, and the variables of the collection an environment.
+// Re-importing the same collection updates existing flows by name.
+func (h *ImportExportHandler) ImportCollection(c *gin.Context) {
+	var req ImportCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := parseCollection(req.Format, req.Content, req.Files)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Preview {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	workspaceID := middleware.GetWorkspaceID(c)
+	source := "Imported from " + collectionFormatNames[req.Format]
+
+	root := result.Collection
+	collection, err := h.upsertImportCollection(req.CollectionID, root.Name, source, root.Auth, workspaceID)
+	if err != nil {
+		h.logger.Error("Failed to prepare import collection", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(root.Variables) > 0 {
+		collection.Variables.Global = mergeVariables(collection.Variables.Global, root.Variables)
+		if err := h.collectionRepo.Update(collection, workspaceID); err != nil {
+			h.logger.Error("Failed to update import collection", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var envID *uuid.UUID
+	if len(result.Variables) > 0 {
+		envName := req.EnvironmentName
+		if envName == "" {
+			envName = root.Name
+		}
+		env, err := h.upsertImportEnvironment(envName, source, result.Variables, workspaceID)
+		if err != nil {
+			h.logger.Error("Failed to prepare import environment", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		envID = &env.ID
+	}
+
+	created, updated, importErrs := h.saveImportedFolder(root, collection, result.Flows, workspaceID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection_id":  collection.ID,
+		"environment_id": envID,
+		"created":        created,
+		"updated":        updated,
+		"errors":         importErrs,
+		"warnings":       result.Warnings,
+		"stats": gin.H{
+			"total":    len(result.Flows),
+			"created":  len(created),
+			"updated":  len(updated),
+			"failed":   len(importErrs),
+			"requests": result.Stats.TotalRequests,
+		},
+	})
+}
+
+// collectionFormatNames are the names of the API clients of collection formats
+var collectionFormatNames = map[string]string{
+	"postman":  "Postman",
+	"insomnia": "Insomnia",
+	"bruno":    "Bruno",
+}
+
+// parseCollection parses a collection of an API client
+func parseCollection(format, content string, files map[string]string) (*importer.CollectionImportResult, error) {
+	switch format {
+	case "postman":
+		return importer.ParsePostman(content)
+	case "insomnia":
+		return importer.ParseInsomnia(content)
+	case "bruno":
+		if len(files) == 0 {
+			return nil, fmt.Errorf("files are required for bruno collections")
+		}
+		return importer.ParseBruno(files)
+	}
+	return nil, fmt.Errorf("unsupported collection format: %s", format)
+}
+
+// saveImportedFolder saves the flows of an imported folder into its collection,
+// and its folders as child collections
+func (h *ImportExportHandler) saveImportedFolder(folder importer.ImportedFolder, collection *models.Collection, flows []models.FlowDefinition, workspaceID uuid.UUID) ([]string, []string, []string) {
+	folderFlows := make([]models.FlowDefinition, 0, len(folder.Flows))
+	for _, i := range folder.Flows {
+		folderFlows = append(folderFlows, flows[i])
+	}
+	created, updated, importErrs := h.saveImportedFlows(folderFlows, collection.ID, workspaceID)
+
+	children, err := h.collectionRepo.ListChildren(collection.ID, workspaceID)
+	if err != nil {
+		return created, updated, append(importErrs, folder.Name+": "+err.Error())
+	}
+	for i, child := range folder.Folders {
+		var target *models.Collection
+		for j := range children {
+			if children[j].Name == child.Name {
+				target = &children[j]
+				break
+			}
+		}
+
+		if target == nil {
+			target = &models.Collection{
+				Name:        child.Name,
+				Description: child.Description,
+				ParentID:    &collection.ID,
+				SortOrder:   i,
+				Auth:        models.CollectionAuth{Type: "none", Inherit: true},
+			}
+			if child.Auth != nil {
+				target.Auth = *child.Auth
+			}
+			target.Variables.Global = child.Variables
+			if err := h.collectionRepo.Create(target, workspaceID); err != nil {
+				importErrs = append(importErrs, child.Name+": "+err.Error())
+				continue
+			}
+		} else if child.Auth != nil || len(child.Variables) > 0 {
+			if child.Auth != nil {
+				target.Auth = *child.Auth
+			}
+			target.Variables.Global = mergeVariables(target.Variables.Global, child.Variables)
+			if err := h.collectionRepo.Update(target, workspaceID); err != nil {
+				importErrs = append(importErrs, child.Name+": "+err.Error())
+				continue
+			}
+		}
+
+		c, u, e := h.saveImportedFolder(child, target, flows, workspaceID)
+		created = append(created, c...)
+		updated = append(updated, u...)
+		importErrs = append(importErrs, e...)
+	}
+	return created, updated, importErrs
+}
+
+// mergeVariables adds imported variables to the variables of a collection
+func mergeVariables(existing, add map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(add))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[k] = v
+	}
+	return merged
+}
+
+// saveImportedFlows creates flows in the collection, updating flows that already
+// exist with the same name instead of duplicating them
+func (h *ImportExportHandler) saveImportedFlows(flows []models.FlowDefinition, collectionID uuid.UUID, workspaceID uuid.UUID) ([]string, []string, []string) {
+	created := []string{}
+	updated := []string{}
+	failures := []string{}
+
+	for i, flowDef := range flows {
+		existing, err := h.flowRepo.GetByName(flowDef.Name, workspaceID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			failures = append(failures, flowDef.Name+": "+err.Error())
+			continue
+		}
+		if err == nil {
+			existing.Description = flowDef.Description
+			existing.Suite = flowDef.Suite
+			existing.Tags = mergeTags(existing.Tags, flowDef.Tags)
+			existing.Definition = flowDef
+			existing.CollectionID = &collectionID
+			if err := h.flowRepo.Update(existing, workspaceID); err != nil {
+				failures = append(failures, flowDef.Name+": "+err.Error())
+				continue
+			}
+			updated = append(updated, existing.ID.String())
+			continue
+		}
+
+		flow := &models.Flow{
+			Name:         flowDef.Name,
+			Description:  flowDef.Description,
+			Suite:        flowDef.Suite,
+			Tags:         flowDef.Tags,
+			Definition:   flowDef,
+			CollectionID: &collectionID,
+			SortOrder:    i,
+		}
+		if err := h.flowRepo.Create(flow, workspaceID); err != nil {
+			failures = append(failures, flowDef.Name+": "+err.Error())
+			continue
+		}
+		created = append(created, flow.ID.String())
+	}
+
+	return created, updated, failures
+}
+
+// upsertImportCollection resolves the target collection (by ID, or by name) and applies the imported auth to it
+func (h *ImportExportHandler) upsertImportCollection(collectionIDStr *string, name, description string, auth *models.CollectionAuth, workspaceID uuid.UUID) (*models.Collection, error) {
+	var collection *models.Collection
+
+	if collectionIDStr != nil && *collectionIDStr != "" {
+		id, err := uuid.Parse(*collectionIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection ID")
+		}
+		collection, err = h.collectionRepo.GetByID(id, workspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("collection not found")
+		}
+	} else if existing, err := h.collectionRepo.GetByName(name, workspaceID); err == nil {
+		collection = existing
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if collection == nil {
+		collection = &models.Collection{
+			Name:        name,
+			Description: description,
+			Auth:        models.CollectionAuth{Type: "none"},
+		}
+		if auth != nil {
+			collection.Auth = *auth
+		}
+		if err := h.collectionRepo.Create(collection, workspaceID); err != nil {
+			return nil, err
+		}
+		return collection, nil
+	}
+
+	if auth != nil {
+		collection.Auth = *auth
+		if err := h.collectionRepo.Update(collection, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// upsertImportEnvironment creates the environment or adds missing variables,
+// keeping values the user already set (e.g. secrets) untouched
+func (h *ImportExportHandler) upsertImportEnvironment(name, description string, vars []models.EnvironmentVariable, workspaceID uuid.UUID) (*models.Environment, error) {
+	env, err := h.envRepo.GetByName(name, workspaceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		env = &models.Environment{
+			Name:        name,
+			Description: description,
+			Variables:   vars,
+		}
+		if err := h.envRepo.Create(env, workspaceID); err != nil {
+			return nil, err
+		}
+		return env, nil
+	}
+
+	existing := make(map[string]bool, len(env.Variables))
+	for _, v := range env.Variables {
+		existing[v.Key] = true
+	}
+	for _, v := range vars {
+		if !existing[v.Key] {
+			env.Variables = append(env.Variables, v)
+		}
+	}
+	if err := h.envRepo.Update(env, workspaceID); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
 // Export handles POST /api/v1/export
 // Exports flows to the specified format
 func (h *ImportExportHandler) Export(c *gin.Context) {
@@ -185,10 +727,14 @@ func (h *ImportExportHandler) Export(c *gin.Context) {
 	}
 
 	// Export
-	options := exporter.ExportOptions{
+	options, err := h.exportOptions(exporter.ExportOptions{
 		Format:       exporter.ExportFormat(req.Format),
 		IncludeTests: req.IncludeTests,
 		IncludeEnv:   req.IncludeEnv,
+	}, req.Environment, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
 	}
 
 	result, err := exporter.ExportFlows(flows, options)
@@ -240,8 +786,13 @@ func (h *ImportExportHandler) ExportDownload(c *gin.Context) {
 	}
 
 	// Export
-	options := exporter.ExportOptions{
-		Format: exporter.ExportFormat(format),
+	options, err := h.exportOptions(exporter.ExportOptions{
+		Format:     exporter.ExportFormat(format),
+		IncludeEnv: c.Query("include_env") == "true",
+	}, c.Query("environment"), workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
 	}
 
 	result, err := exporter.ExportFlows(flows, options)
@@ -251,10 +802,64 @@ func (h *ImportExportHandler) ExportDownload(c *gin.Context) {
 	}
 
 	c.Header("Content-Disposition", "attachment; filename="+result.Filename)
+	if len(result.Files) > 0 {
+		archive, err := result.Archive()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
 	c.Header("Content-Type", result.MimeType)
 	c.String(http.StatusOK, result.Content)
 }
 
+// exportOptions adds the collections of the workspace, for the folders of
+// collection formats, and the environment exported with include_env
+func (h *ImportExportHandler) exportOptions(options exporter.ExportOptions, environmentRef string, workspaceID uuid.UUID) (exporter.ExportOptions, error) {
+	collections, err := h.collectionRepo.ListAll(workspaceID)
+	if err != nil {
+		h.logger.Warn("Failed to load collections for export", zap.Error(err))
+	}
+	options.Collections = collections
+
+	if !options.IncludeEnv {
+		return options, nil
+	}
+	var env *models.Environment
+	switch {
+	case environmentRef == "":
+		env, err = h.envRepo.GetDefault(workspaceID)
+		if err != nil {
+			// Without a default environment, only the collection variables are exported
+			return options, nil
+		}
+	default:
+		if id, parseErr := uuid.Parse(environmentRef); parseErr == nil {
+			env, err = h.envRepo.GetByID(id, workspaceID)
+		} else {
+			env, err = h.envRepo.GetByName(environmentRef, workspaceID)
+		}
+		if err != nil {
+			return options, fmt.Errorf("environment not found: %s", environmentRef)
+		}
+	}
+	options.Environment = env
+	return options, nil
+}
+
+// mergeTags appends tags that are not already present
+func mergeTags(existing []string, add []string) []string {
+	tags := append([]string{}, existing...)
+	for _, t := range add {
+		if !contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
 // Helper function
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
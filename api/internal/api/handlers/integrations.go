@@ -455,10 +455,13 @@ func validateIntegration(integrationType models.IntegrationType, provider models
 		}
 	case models.IntegrationTypeGit:
 		switch provider {
-		case models.IntegrationProviderGitHub:
+		case models.IntegrationProviderGitHub,
+			models.IntegrationProviderGitLab,
+			models.IntegrationProviderBitbucket,
+			models.IntegrationProviderGeneric:
 			return nil
 		default:
-			return fmt.Errorf("invalid Git provider: %s (only github supported)", provider)
+			return fmt.Errorf("invalid Git provider: %s (expected github, gitlab, bitbucket or generic)", provider)
 		}
 	default:
 		return fmt.Errorf("invalid integration type: %s", integrationType)
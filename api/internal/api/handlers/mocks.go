@@ -125,6 +125,11 @@ func (h *MockHandler) CreateEndpoint(c *gin.Context) {
 
 	endpoint.MockServerID = serverID
 
+	if err := mocks.ValidateResponseConfig(&endpoint.ResponseConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.repo.CreateEndpoint(&endpoint); err != nil {
 		h.logger.Error("Failed to create endpoint", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create endpoint"})
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/monitoring"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MonitorHandler handles synthetic monitor requests
+type MonitorHandler struct {
+	repo          *repository.MonitorRepository
+	flowRepo      *repository.FlowRepository
+	workspaceRepo *repository.WorkspaceRepository
+	manager       *monitoring.Manager
+	logger        *zap.Logger
+}
+
+// NewMonitorHandler creates a new monitor handler
+func NewMonitorHandler(
+	repo *repository.MonitorRepository,
+	flowRepo *repository.FlowRepository,
+	workspaceRepo *repository.WorkspaceRepository,
+	manager *monitoring.Manager,
+	logger *zap.Logger,
+) *MonitorHandler {
+	return &MonitorHandler{
+		repo:          repo,
+		flowRepo:      flowRepo,
+		workspaceRepo: workspaceRepo,
+		manager:       manager,
+		logger:        logger,
+	}
+}
+
+// MonitorRequest represents a request to create or update a monitor
+type MonitorRequest struct {
+	Name             string                 `json:"name" binding:"required"`
+	Description      string                 `json:"description"`
+	FlowID           uuid.UUID              `json:"flow_id" binding:"required"`
+	Interval         string                 `json:"interval" binding:"required"`
+	Environment      map[string]interface{} `json:"environment"`
+	SLOs             []models.SLO           `json:"slos"`
+	PendingFor       string                 `json:"pending_for"`
+	RepeatInterval   string                 `json:"repeat_interval"`
+	NotifyWebhookURL string                 `json:"notify_webhook_url"`
+	Public           bool                   `json:"public"`
+}
+
+// monitorResponse is a monitor with the current evaluation of its SLOs
+type monitorResponse struct {
+	*models.Monitor
+	SLOStatus []models.SLOStatus `json:"slo_status"`
+}
+
+// List handles GET /api/v1/workspaces/:workspace_id/monitors
+func (h *MonitorHandler) List(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	monitors, err := h.repo.List(workspaceID, false)
+	if err != nil {
+		h.logger.Error("Failed to list monitors", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list monitors"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"monitors": monitors,
+		"total":    len(monitors),
+	})
+}
+
+// Create handles POST /api/v1/workspaces/:workspace_id/monitors
+func (h *MonitorHandler) Create(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	var req MonitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.flowRepo.GetByID(req.FlowID, workspaceID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+		return
+	}
+
+	monitor := &models.Monitor{WorkspaceID: workspaceID}
+	req.apply(monitor)
+
+	if err := h.manager.Create(monitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, monitor)
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/monitors/:id
+func (h *MonitorHandler) Get(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, monitorResponse{
+		Monitor:   monitor,
+		SLOStatus: h.manager.Evaluate(monitor, time.Now()),
+	})
+}
+
+// Update handles PUT /api/v1/workspaces/:workspace_id/monitors/:id
+func (h *MonitorHandler) Update(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	var req MonitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.FlowID != monitor.FlowID {
+		if _, err := h.flowRepo.GetByID(req.FlowID, monitor.WorkspaceID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+			return
+		}
+		monitor.Flow = nil
+	}
+	req.apply(monitor)
+
+	if err := h.manager.Update(monitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, monitor)
+}
+
+// Delete handles DELETE /api/v1/workspaces/:workspace_id/monitors/:id
+func (h *MonitorHandler) Delete(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Delete(monitor); err != nil {
+		h.logger.Error("Failed to delete monitor", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete monitor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "monitor deleted"})
+}
+
+// Pause handles POST /api/v1/workspaces/:workspace_id/monitors/:id/pause
+func (h *MonitorHandler) Pause(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Pause(monitor); err != nil {
+		h.logger.Error("Failed to pause monitor", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause monitor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, monitor)
+}
+
+// Resume handles POST /api/v1/workspaces/:workspace_id/monitors/:id/resume
+func (h *MonitorHandler) Resume(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Resume(monitor); err != nil {
+		h.logger.Error("Failed to resume monitor", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resume monitor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, monitor)
+}
+
+// Check handles POST /api/v1/workspaces/:workspace_id/monitors/:id/check
+// Runs a check now; its result is recorded when the run finishes.
+func (h *MonitorHandler) Check(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.manager.Check(monitor)
+	if err != nil {
+		h.logger.Error("Failed to run monitor check", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// Checks handles GET /api/v1/workspaces/:workspace_id/monitors/:id/checks
+func (h *MonitorHandler) Checks(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	checks, err := h.repo.ListChecks(monitor.ID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list monitor checks", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list checks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"checks": checks,
+		"total":  len(checks),
+	})
+}
+
+// Alerts handles GET /api/v1/workspaces/:workspace_id/monitors/:id/alerts
+// Lists the alerts that fired, with the open alert if it is still pending.
+func (h *MonitorHandler) Alerts(c *gin.Context) {
+	monitor, ok := h.loadMonitor(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	alerts, err := h.repo.ListAlerts(monitor.ID, time.Time{}, limit)
+	if err != nil {
+		h.logger.Error("Failed to list monitor alerts", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list alerts"})
+		return
+	}
+	if open, err := h.repo.GetOpenAlert(monitor.ID); err == nil && open != nil && open.FiredAt == nil {
+		alerts = append([]models.MonitorAlert{*open}, alerts...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts":      alerts,
+		"total":       len(alerts),
+		"alert_state": monitor.AlertState,
+	})
+}
+
+// StatusPage handles GET /api/v1/status/:slug
+// Public status of the monitors a workspace marked as public; requires no authentication.
+func (h *MonitorHandler) StatusPage(c *gin.Context) {
+	workspace, err := h.workspaceRepo.GetBySlug(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "status page not found"})
+		return
+	}
+
+	page, err := h.manager.StatusPage(workspace)
+	if err != nil {
+		h.logger.Error("Failed to build status page", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build status page"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=30")
+	c.JSON(http.StatusOK, page)
+}
+
+// loadMonitor loads the monitor named by the :id parameter in the request's workspace
+func (h *MonitorHandler) loadMonitor(c *gin.Context) (*models.Monitor, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid monitor ID"})
+		return nil, false
+	}
+
+	monitor, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "monitor not found"})
+		return nil, false
+	}
+	return monitor, true
+}
+
+// apply copies the request's settings to a monitor
+func (req *MonitorRequest) apply(monitor *models.Monitor) {
+	monitor.Name = req.Name
+	monitor.Description = req.Description
+	monitor.FlowID = req.FlowID
+	monitor.Interval = req.Interval
+	monitor.Environment = req.Environment
+	monitor.SLOs = req.SLOs
+	monitor.PendingFor = req.PendingFor
+	monitor.RepeatInterval = req.RepeatInterval
+	monitor.NotifyWebhookURL = req.NotifyWebhookURL
+	monitor.Public = req.Public
+}
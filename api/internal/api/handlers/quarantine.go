@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/quarantine"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// QuarantineHandler handles flaky flow quarantine requests
+type QuarantineHandler struct {
+	repo          *repository.QuarantineRepository
+	flowRepo      *repository.FlowRepository
+	workspaceRepo *repository.WorkspaceRepository
+	manager       *quarantine.Manager
+	logger        *zap.Logger
+}
+
+// NewQuarantineHandler creates a new quarantine handler
+func NewQuarantineHandler(
+	repo *repository.QuarantineRepository,
+	flowRepo *repository.FlowRepository,
+	workspaceRepo *repository.WorkspaceRepository,
+	manager *quarantine.Manager,
+	logger *zap.Logger,
+) *QuarantineHandler {
+	return &QuarantineHandler{
+		repo:          repo,
+		flowRepo:      flowRepo,
+		workspaceRepo: workspaceRepo,
+		manager:       manager,
+		logger:        logger,
+	}
+}
+
+// QuarantineRequest represents a request to quarantine a flow or one of its steps
+type QuarantineRequest struct {
+	FlowID uuid.UUID `json:"flow_id" binding:"required"`
+	StepID string    `json:"step_id"`
+	Reason string    `json:"reason"`
+}
+
+// ReleaseQuarantineRequest represents a request to release a quarantine
+type ReleaseQuarantineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// List handles GET /api/v1/workspaces/:workspace_id/quarantine
+// Lists active quarantines unless ?status=released or ?status=all is given.
+func (h *QuarantineHandler) List(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	var status models.QuarantineStatus
+	switch c.DefaultQuery("status", "active") {
+	case "active":
+		status = models.QuarantineStatusActive
+	case "released":
+		status = models.QuarantineStatusReleased
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, released or all"})
+		return
+	}
+
+	var flowID *uuid.UUID
+	if raw := c.Query("flow_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flow ID"})
+			return
+		}
+		flowID = &id
+	}
+
+	quarantines, err := h.repo.List(workspaceID, status, flowID)
+	if err != nil {
+		h.logger.Error("Failed to list quarantines", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list quarantines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quarantines": quarantines,
+		"total":       len(quarantines),
+		"policy":      h.manager.Policy(workspaceID),
+	})
+}
+
+// Create handles POST /api/v1/workspaces/:workspace_id/quarantine
+func (h *QuarantineHandler) Create(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return
+	}
+
+	var req QuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flow, err := h.flowRepo.GetByID(req.FlowID, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+		return
+	}
+
+	q, err := h.manager.Quarantine(flow, req.StepID, req.Reason, h.actor(c, workspaceID))
+	if errors.Is(err, quarantine.ErrAlreadyQuarantined) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to quarantine flow", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to quarantine flow"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, q)
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/quarantine/:id
+func (h *QuarantineHandler) Get(c *gin.Context) {
+	q, ok := h.loadQuarantine(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// Release handles DELETE /api/v1/workspaces/:workspace_id/quarantine/:id
+func (h *QuarantineHandler) Release(c *gin.Context) {
+	q, ok := h.loadQuarantine(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req ReleaseQuarantineRequest
+	_ = c.ShouldBindJSON(&req)
+	if req.Reason == "" {
+		req.Reason = "released manually"
+	}
+
+	if q.Status == models.QuarantineStatusReleased {
+		c.JSON(http.StatusOK, q)
+		return
+	}
+
+	if err := h.manager.Release(q, req.Reason, h.actor(c, q.WorkspaceID)); err != nil {
+		h.logger.Error("Failed to release quarantine", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to release quarantine"})
+		return
+	}
+
+	c.JSON(http.StatusOK, q)
+}
+
+// loadQuarantine loads the quarantine named by the :id parameter in the request's workspace
+func (h *QuarantineHandler) loadQuarantine(c *gin.Context) (*models.Quarantine, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace context required"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine ID"})
+		return nil, false
+	}
+
+	q, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quarantine not found"})
+		return nil, false
+	}
+	return q, true
+}
+
+// actor identifies the requesting member for activity events
+func (h *QuarantineHandler) actor(c *gin.Context, workspaceID uuid.UUID) quarantine.Actor {
+	actor := quarantine.Actor{ID: middleware.GetUserID(c)}
+	if member, err := h.workspaceRepo.GetMember(workspaceID, actor.ID); err == nil {
+		actor.Name = member.Name
+		if actor.Name == "" {
+			actor.Name = member.Email
+		}
+	}
+	return actor
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -41,7 +43,7 @@ func NewReportingHandler(
 func (h *ReportingHandler) GenerateReport(c *gin.Context) {
 	var req struct {
 		Name      string               `json:"name" binding:"required"`
-		Format    string               `json:"format" binding:"required,oneof=html json junit"`
+		Format    string               `json:"format" binding:"required"`
 		StartDate string               `json:"start_date" binding:"required"`
 		EndDate   string               `json:"end_date" binding:"required"`
 		Filters   models.ReportFilters `json:"filters"`
@@ -52,6 +54,11 @@ func (h *ReportingHandler) GenerateReport(c *gin.Context) {
 		return
 	}
 
+	if !h.generator.SupportsFormat(models.ReportFormat(req.Format)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported report format: " + req.Format})
+		return
+	}
+
 	startDate, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format, use YYYY-MM-DD"})
@@ -95,6 +102,19 @@ func (h *ReportingHandler) GenerateReport(c *gin.Context) {
 	c.JSON(http.StatusAccepted, report)
 }
 
+// ListFormats handles GET /api/v1/reports/formats
+// Lists the report formats, including those of loaded reporter plugins.
+func (h *ReportingHandler) ListFormats(c *gin.Context) {
+	formats := []string{
+		string(models.ReportFormatHTML),
+		string(models.ReportFormatJSON),
+		string(models.ReportFormatJUnit),
+	}
+	formats = append(formats, h.generator.Exporters().Formats()...)
+
+	c.JSON(http.StatusOK, gin.H{"formats": formats})
+}
+
 // ListReports handles GET /api/v1/reports
 func (h *ReportingHandler) ListReports(c *gin.Context) {
 	format := models.ReportFormat(c.Query("format"))
@@ -158,6 +178,8 @@ func (h *ReportingHandler) DownloadReport(c *gin.Context) {
 		filename += ".json"
 	case models.ReportFormatJUnit:
 		filename += ".xml"
+	default:
+		filename += filepath.Ext(report.FilePath)
 	}
 
 	c.Header("Content-Disposition", "attachment; filename="+filename)
@@ -507,6 +529,12 @@ func (h *ReportingHandler) GetStepPerformance(c *gin.Context) {
 	})
 }
 
+// writeExport sends an exported run as a download named after the exporter's extension
+func writeExport(c *gin.Context, content []byte, exporter reporting.Exporter, filename string) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", filename, exporter.Extension()))
+	c.Data(http.StatusOK, exporter.ContentType(), content)
+}
+
 func parseIntQuery(c *gin.Context, key string, defaultVal int) int {
 	if val := c.Query(key); val != "" {
 		if parsed, err := strconv.Atoi(val); err == nil {
@@ -43,10 +43,11 @@ type RunCollectionRequest struct {
 	Environment     string                 `json:"environment"`
 }
 
-// ParseDataRequest represents a request to parse a data file
+// ParseDataRequest represents a request to preview the rows of a data source
 type ParseDataRequest struct {
-	Type    string `json:"type" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	runner.DataSource
+	Environment string            `json:"environment"` // Environment whose variables SQL sources can use
+	Variables   map[string]string `json:"variables"`
 }
 
 // Run handles POST /api/v1/runner/run
@@ -96,14 +97,15 @@ func (h *RunnerHandler) Run(c *gin.Context) {
 		Variables:       mergedVars,
 		VariableMapping: req.VariableMapping,
 		Environment:     req.Environment,
+		WorkspaceID:     workspaceID,
 	}
 
-	// Run collection
+	// Run collection; it only fails when the data source cannot be loaded
 	ctx := c.Request.Context()
 	result, err := h.collectionRunner.Run(ctx, config, flows, nil)
 	if err != nil {
 		h.logger.Error("Failed to run collection", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -117,34 +119,32 @@ func (h *RunnerHandler) ParseData(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	source := &runner.DataSource{
-		Type:    req.Type,
-		Content: req.Content,
+	if req.Type == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
+		return
 	}
 
-	// Validate and get preview
-	rows, columns, err := runner.GetDataPreview(source, 10)
+	workspaceID := middleware.GetWorkspaceID(c)
+	vars := h.mergeEnvironmentVariables(req.Environment, workspaceID, req.Variables)
+
+	// Load the rows the run would iterate over
+	data, err := h.collectionRunner.LoadRows(c.Request.Context(), &req.DataSource, workspaceID, vars)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Count total rows
-	var totalRows int
-	switch req.Type {
-	case "csv":
-		allRows, _ := runner.ParseCSV(req.Content)
-		totalRows = len(allRows)
-	case "json":
-		allRows, _ := runner.ParseJSON(req.Content)
-		totalRows = len(allRows)
+	preview := data.Rows
+	if len(preview) > 10 {
+		preview = preview[:10]
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"columns":    columns,
-		"preview":    rows,
-		"total_rows": totalRows,
+		"columns":       data.Columns,
+		"preview":       preview,
+		"total_rows":    len(data.Rows),
+		"filtered_rows": data.Filtered,
+		"data_seed":     data.Seed,
 	})
 }
 
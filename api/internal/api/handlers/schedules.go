@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/reporting"
 	"github.com/georgi-georgiev/testmesh/internal/scheduler"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
@@ -16,6 +18,7 @@ import (
 type ScheduleHandler struct {
 	repo      *repository.ScheduleRepository
 	scheduler *scheduler.Scheduler
+	reports   *reporting.Generator
 	logger    *zap.Logger
 }
 
@@ -28,19 +31,34 @@ func NewScheduleHandler(repo *repository.ScheduleRepository, sched *scheduler.Sc
 	}
 }
 
+// SetReportGenerator sets the generator that exports runs to result formats
+func (h *ScheduleHandler) SetReportGenerator(generator *reporting.Generator) {
+	h.reports = generator
+}
+
 // CreateScheduleRequest represents a request to create a schedule
 type CreateScheduleRequest struct {
 	Name            string                 `json:"name" binding:"required"`
 	Description     string                 `json:"description"`
-	FlowID          string                 `json:"flow_id" binding:"required"`
 	CronExpr        string                 `json:"cron_expr" binding:"required"`
 	Timezone        string                 `json:"timezone"`
+	TargetType      string                 `json:"target_type"` // flow (default), flows, collection, suite or tags
+	WorkspaceID     string                 `json:"workspace_id"`
+	FlowID          string                 `json:"flow_id"`
+	CollectionID    string                 `json:"collection_id"`
+	Suite           string                 `json:"suite"`
+	TagQuery        string                 `json:"tag_query"`
+	FlowIDs         []string               `json:"flow_ids"`
+	Concurrency     int                    `json:"concurrency"`
+	FailFast        bool                   `json:"fail_fast"`
 	Environment     map[string]interface{} `json:"environment"`
 	NotifyOnFailure bool                   `json:"notify_on_failure"`
 	NotifyOnSuccess bool                   `json:"notify_on_success"`
 	NotifyEmails    []string               `json:"notify_emails"`
 	MaxRetries      int                    `json:"max_retries"`
 	RetryDelay      string                 `json:"retry_delay"`
+	MisfirePolicy   string                 `json:"misfire_policy"` // skip, run_once (default) or run_all
+	Jitter          string                 `json:"jitter"`
 	AllowOverlap    bool                   `json:"allow_overlap"`
 	Tags            []string               `json:"tags"`
 }
@@ -59,13 +77,6 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Parse flow ID
-	flowID, err := uuid.Parse(req.FlowID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flow ID"})
-		return
-	}
-
 	// Set default timezone
 	timezone := req.Timezone
 	if timezone == "" {
@@ -76,19 +87,40 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 	schedule := &models.Schedule{
 		Name:            req.Name,
 		Description:     req.Description,
-		FlowID:          flowID,
 		CronExpr:        req.CronExpr,
 		Timezone:        timezone,
 		Status:          models.ScheduleStatusActive,
+		TargetType:      models.ScheduleTargetFlow,
+		Concurrency:     req.Concurrency,
+		FailFast:        req.FailFast,
 		Environment:     req.Environment,
 		NotifyOnFailure: req.NotifyOnFailure,
 		NotifyOnSuccess: req.NotifyOnSuccess,
 		NotifyEmails:    req.NotifyEmails,
 		MaxRetries:      req.MaxRetries,
 		RetryDelay:      req.RetryDelay,
+		MisfirePolicy:   models.MisfireRunOnce,
+		Jitter:          req.Jitter,
 		AllowOverlap:    req.AllowOverlap,
 		Tags:            req.Tags,
 	}
+	if req.MisfirePolicy != "" {
+		schedule.MisfirePolicy = models.MisfirePolicy(req.MisfirePolicy)
+	}
+
+	if err := applyScheduleTarget(schedule, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := scheduler.ValidateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.scheduler.CheckTargetWorkspace(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if err := h.scheduler.AddSchedule(schedule); err != nil {
 		h.logger.Error("Failed to create schedule", zap.Error(err))
@@ -185,15 +217,13 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 		}
 	}
 
-	// Parse flow ID if changed
-	if req.FlowID != "" {
-		flowID, err := uuid.Parse(req.FlowID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flow ID"})
-			return
-		}
-		schedule.FlowID = flowID
+	// Apply target changes
+	if err := applyScheduleTarget(schedule, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	schedule.Concurrency = req.Concurrency
+	schedule.FailFast = req.FailFast
 
 	// Update fields
 	schedule.Name = req.Name
@@ -208,9 +238,22 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 	schedule.NotifyEmails = req.NotifyEmails
 	schedule.MaxRetries = req.MaxRetries
 	schedule.RetryDelay = req.RetryDelay
+	if req.MisfirePolicy != "" {
+		schedule.MisfirePolicy = models.MisfirePolicy(req.MisfirePolicy)
+	}
+	schedule.Jitter = req.Jitter
 	schedule.AllowOverlap = req.AllowOverlap
 	schedule.Tags = req.Tags
 
+	if err := scheduler.ValidateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.scheduler.CheckTargetWorkspace(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.scheduler.UpdateSchedule(schedule); err != nil {
 		h.logger.Error("Failed to update schedule", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -220,6 +263,56 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, schedule)
 }
 
+// applyScheduleTarget copies the target fields set in a request onto a schedule.
+// Changing the target type clears the fields of the previous target.
+func applyScheduleTarget(schedule *models.Schedule, req *CreateScheduleRequest) error {
+	if req.TargetType != "" && models.ScheduleTargetType(req.TargetType) != schedule.TargetType {
+		schedule.TargetType = models.ScheduleTargetType(req.TargetType)
+		schedule.FlowID = nil
+		schedule.Flow = nil
+		schedule.CollectionID = nil
+		schedule.Suite = ""
+		schedule.TagQuery = ""
+		schedule.FlowIDs = nil
+	}
+
+	parseID := func(raw, name string) (*uuid.UUID, error) {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s", name)
+		}
+		return &id, nil
+	}
+
+	var err error
+	if req.WorkspaceID != "" {
+		if schedule.WorkspaceID, err = parseID(req.WorkspaceID, "workspace ID"); err != nil {
+			return err
+		}
+	}
+	if req.FlowID != "" {
+		if schedule.FlowID, err = parseID(req.FlowID, "flow ID"); err != nil {
+			return err
+		}
+		schedule.Flow = nil
+	}
+	if req.CollectionID != "" {
+		if schedule.CollectionID, err = parseID(req.CollectionID, "collection ID"); err != nil {
+			return err
+		}
+	}
+	if req.Suite != "" {
+		schedule.Suite = req.Suite
+	}
+	if req.TagQuery != "" {
+		schedule.TagQuery = req.TagQuery
+	}
+	if req.FlowIDs != nil {
+		schedule.FlowIDs = req.FlowIDs
+	}
+	return nil
+}
+
 // Delete handles DELETE /api/v1/schedules/:id
 func (h *ScheduleHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -320,6 +413,119 @@ func (h *ScheduleHandler) GetRuns(c *gin.Context) {
 	})
 }
 
+// GetRun handles GET /api/v1/schedules/:id/runs/:run_id
+// Returns the run with its per-flow results and executions.
+func (h *ScheduleHandler) GetRun(c *gin.Context) {
+	run, ok := h.loadRun(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetRunJUnit handles GET /api/v1/schedules/:id/runs/:run_id/junit
+func (h *ScheduleHandler) GetRunJUnit(c *gin.Context) {
+	run, ok := h.loadRun(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.repo.Get(run.ScheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	data, err := reporting.ScheduleRunJUnit(schedule.Name, run)
+	if err != nil {
+		h.logger.Error("Failed to render schedule run JUnit", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=schedule-run-%s.xml", run.ID))
+	c.Data(http.StatusOK, "application/xml", data)
+}
+
+// GetRunExport handles GET /api/v1/schedules/:id/runs/:run_id/export
+// Renders the run in ?format=allure|ctrf|tap or the format of a reporter plugin,
+// with one test case per flow.
+func (h *ScheduleHandler) GetRunExport(c *gin.Context) {
+	run, ok := h.loadRun(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.repo.Get(run.ScheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	format := c.Query("format")
+	if _, ok := h.reports.Exporters().Get(format); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format: " + format, "formats": h.reports.Exporters().Formats()})
+		return
+	}
+
+	content, exporter, err := h.reports.ExportScheduleRun(c.Request.Context(), schedule.Name, run, format)
+	if err != nil {
+		h.logger.Error("Failed to export schedule run", zap.String("run_id", run.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	writeExport(c, content, exporter, fmt.Sprintf("schedule-run-%s", run.ID))
+}
+
+func (h *ScheduleHandler) loadRun(c *gin.Context) (*models.ScheduleRun, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return nil, false
+	}
+
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return nil, false
+	}
+
+	run, err := h.repo.GetRunWithExecutions(id, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule run not found"})
+		return nil, false
+	}
+	return run, true
+}
+
+// GetTargets handles GET /api/v1/schedules/:id/targets
+// Lists the flows the schedule would run right now.
+func (h *ScheduleHandler) GetTargets(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	schedule, err := h.repo.Get(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	flows, err := h.scheduler.ResolveTargets(schedule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flows": flows,
+		"total": len(flows),
+	})
+}
+
 // GetStats handles GET /api/v1/schedules/:id/stats
 func (h *ScheduleHandler) GetStats(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -428,3 +634,16 @@ func (h *ScheduleHandler) GetTimezones(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"timezones": timezones})
 }
+
+// GetSchedulerStatus handles GET /api/v1/schedules/scheduler
+// Reports which replica holds the scheduler lease.
+func (h *ScheduleHandler) GetSchedulerStatus(c *gin.Context) {
+	status, err := h.scheduler.Status()
+	if err != nil {
+		h.logger.Error("Failed to get scheduler status", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
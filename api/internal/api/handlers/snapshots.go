@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/runner/snapshots"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SnapshotHandler handles snapshot review requests
+type SnapshotHandler struct {
+	repo     *repository.SnapshotRepository
+	flowRepo *repository.FlowRepository
+	logger   *zap.Logger
+}
+
+// NewSnapshotHandler creates a new snapshot handler
+func NewSnapshotHandler(repo *repository.SnapshotRepository, flowRepo *repository.FlowRepository, logger *zap.Logger) *SnapshotHandler {
+	return &SnapshotHandler{
+		repo:     repo,
+		flowRepo: flowRepo,
+		logger:   logger,
+	}
+}
+
+// ListByFlow handles GET /api/v1/workspaces/:workspace_id/flows/:id/snapshots
+func (h *SnapshotHandler) ListByFlow(c *gin.Context) {
+	flow, ok := h.loadFlow(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.repo.ListByFlow(flow.ID, models.SnapshotStatus(c.Query("status")))
+	if err != nil {
+		h.logger.Error("Failed to list snapshots", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots": list,
+		"total":     len(list),
+	})
+}
+
+// AcceptAll handles POST /api/v1/workspaces/:workspace_id/flows/:id/snapshots/accept
+// Accepts every pending snapshot of the flow.
+func (h *SnapshotHandler) AcceptAll(c *gin.Context) {
+	flow, ok := h.loadFlow(c)
+	if !ok {
+		return
+	}
+
+	pending, err := h.repo.ListByFlow(flow.ID, models.SnapshotStatusPending)
+	if err != nil {
+		h.logger.Error("Failed to list pending snapshots", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list snapshots"})
+		return
+	}
+
+	accepted := []string{}
+	for i := range pending {
+		if err := h.accept(&pending[i]); err != nil {
+			h.logger.Error("Failed to accept snapshot", zap.Error(err), zap.String("snapshot", pending[i].Name))
+			continue
+		}
+		accepted = append(accepted, pending[i].Name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accepted": accepted,
+		"total":    len(accepted),
+	})
+}
+
+// Get handles GET /api/v1/workspaces/:workspace_id/snapshots/:id
+// Returns the snapshot together with its pending changes and a severity summary.
+func (h *SnapshotHandler) Get(c *gin.Context) {
+	snapshot, ok := h.loadSnapshot(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshot": snapshot,
+		"changes":  snapshot.PendingChanges,
+		"summary":  snapshots.Summary(snapshot.PendingChanges),
+		"diff":     renderChanges(snapshot.PendingChanges),
+	})
+}
+
+// Accept handles POST /api/v1/workspaces/:workspace_id/snapshots/:id/accept
+func (h *SnapshotHandler) Accept(c *gin.Context) {
+	snapshot, ok := h.loadSnapshot(c)
+	if !ok {
+		return
+	}
+
+	if snapshot.Status != models.SnapshotStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "snapshot has no pending changes"})
+		return
+	}
+
+	if err := h.accept(snapshot); err != nil {
+		h.logger.Error("Failed to accept snapshot", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// Reject handles POST /api/v1/workspaces/:workspace_id/snapshots/:id/reject
+// Discards the pending candidate and keeps the golden record.
+func (h *SnapshotHandler) Reject(c *gin.Context) {
+	snapshot, ok := h.loadSnapshot(c)
+	if !ok {
+		return
+	}
+
+	snapshot.Status = models.SnapshotStatusAccepted
+	snapshot.Pending = nil
+	snapshot.PendingChanges = nil
+	snapshot.PendingExecutionID = nil
+	if err := h.repo.Update(snapshot); err != nil {
+		h.logger.Error("Failed to reject snapshot", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// Delete handles DELETE /api/v1/workspaces/:workspace_id/snapshots/:id
+// The next run of the step records a fresh golden record.
+func (h *SnapshotHandler) Delete(c *gin.Context) {
+	snapshot, ok := h.loadSnapshot(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(snapshot.ID); err != nil {
+		h.logger.Error("Failed to delete snapshot", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "snapshot deleted"})
+}
+
+// accept promotes the pending candidate to the golden record
+func (h *SnapshotHandler) accept(snapshot *models.Snapshot) error {
+	now := time.Now()
+	snapshot.Golden = snapshot.Pending
+	snapshot.Status = models.SnapshotStatusAccepted
+	snapshot.Pending = nil
+	snapshot.PendingChanges = nil
+	snapshot.PendingExecutionID = nil
+	snapshot.AcceptedAt = &now
+	return h.repo.Update(snapshot)
+}
+
+func (h *SnapshotHandler) loadFlow(c *gin.Context) (*models.Flow, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	flowID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flow ID"})
+		return nil, false
+	}
+
+	flow, err := h.flowRepo.GetByID(flowID, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+		return nil, false
+	}
+	return flow, true
+}
+
+func (h *SnapshotHandler) loadSnapshot(c *gin.Context) (*models.Snapshot, bool) {
+	workspaceID := middleware.GetWorkspaceID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid snapshot ID"})
+		return nil, false
+	}
+
+	snapshot, err := h.repo.GetByID(id, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
+		return nil, false
+	}
+	return snapshot, true
+}
+
+// renderChanges returns the textual diff, or an empty string when there are no changes
+func renderChanges(changes models.SnapshotChanges) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	return snapshots.Render(changes)
+}
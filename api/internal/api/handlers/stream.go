@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/events"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// streamHeartbeat is how often idle streams send a comment and check whether
+// their execution or run has finished
+const streamHeartbeat = 15 * time.Second
+
+// StreamHandler streams execution events and logs as Server-Sent Events
+type StreamHandler struct {
+	bus          events.Bus
+	execRepo     *repository.ExecutionRepository
+	scheduleRepo *repository.ScheduleRepository
+	logger       *zap.Logger
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(bus events.Bus, execRepo *repository.ExecutionRepository, scheduleRepo *repository.ScheduleRepository, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		bus:          bus,
+		execRepo:     execRepo,
+		scheduleRepo: scheduleRepo,
+		logger:       logger,
+	}
+}
+
+// LogEntry is a structured log line of an execution
+type LogEntry struct {
+	Time        time.Time `json:"time"`
+	Level       string    `json:"level"` // "info" or "error"
+	ExecutionID uuid.UUID `json:"execution_id"`
+	StepID      string    `json:"step_id,omitempty"`
+	Message     string    `json:"message"`
+}
+
+// streamCursor is the position of the last event a client received. Event
+// IDs are <execution_id>:<seq>:<unix nanoseconds>.
+type streamCursor struct {
+	executionID uuid.UUID
+	seq         uint64
+	time        time.Time
+}
+
+// StreamExecution handles GET /api/v1/workspaces/:workspace_id/executions/:id/stream.
+// It replays the events of the execution so far, streams new ones, and ends
+// with an "end" event once the execution has finished. A failed execution
+// that is rerun continues with the events of its latest attempt.
+func (h *StreamHandler) StreamExecution(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+	execution, err := h.execRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+	workspaceID := middleware.GetWorkspaceID(c)
+	if workspaceID != uuid.Nil && execution.Flow != nil && execution.Flow.WorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	// Clients reconnect with the last event of the attempt they were following
+	current := id
+	filter := events.Filter{ExecutionID: id, Replay: true}
+	if cursor := lastEventID(c); cursor != nil && h.isAttempt(id, cursor.executionID) {
+		current = cursor.executionID
+		filter = events.Filter{ExecutionID: current, Replay: true, Since: cursor.seq}
+	}
+
+	// When an attempt fails, its rerun already exists and the stream moves on to it
+	follow := func(event *events.Event) *events.Subscription {
+		if event.Type != "execution.failed" {
+			return nil
+		}
+		retry, err := h.execRepo.GetLatestRetry(id)
+		if err != nil || retry.ID == current {
+			return nil
+		}
+		current = retry.ID
+		return h.bus.Subscribe(events.Filter{ExecutionID: current, Replay: true})
+	}
+
+	h.stream(c, h.bus.Subscribe(filter), nil, follow, func() (bool, gin.H) {
+		execution, err := h.execRepo.GetByID(id)
+		if err != nil {
+			return false, nil
+		}
+		// A failed run may have been rerun; its latest attempt decides
+		if execution.Status == models.ExecutionStatusFailed {
+			if retry, err := h.execRepo.GetLatestRetry(id); err == nil {
+				execution = retry
+			}
+		}
+		if !executionFinished(execution.Status) {
+			return false, nil
+		}
+		return true, gin.H{"status": execution.Status, "execution": execution}
+	})
+}
+
+// isAttempt reports whether an execution is the execution with an ID or one
+// of its reruns
+func (h *StreamHandler) isAttempt(id, executionID uuid.UUID) bool {
+	if executionID == id {
+		return true
+	}
+	execution, err := h.execRepo.GetByID(executionID)
+	return err == nil && execution.RetryOf != nil && *execution.RetryOf == id
+}
+
+// StreamRun handles GET /api/v1/schedules/:id/runs/:run_id/stream. It streams
+// the events of every execution of a schedule run, including reruns, and
+// ends with an "end" event once the run has finished.
+func (h *StreamHandler) StreamRun(c *gin.Context) {
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run ID"})
+		return
+	}
+	run, err := h.scheduleRepo.GetRun(runID)
+	if err != nil || run.ScheduleID != scheduleID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	schedule, err := h.scheduleRepo.Get(scheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	// The run's executions so far are listed before subscribing, so the start
+	// events of later ones, which name the run, are replayed or streamed
+	executions, err := h.execRepo.ListByScheduleRun(runID)
+	if err != nil {
+		h.logger.Error("Failed to list run executions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list run executions"})
+		return
+	}
+	members := make(map[uuid.UUID]bool, len(executions))
+	for _, execution := range executions {
+		members[execution.ID] = true
+	}
+	filter := events.Filter{Replay: true}
+	if schedule.WorkspaceID != nil {
+		filter.WorkspaceID = *schedule.WorkspaceID
+	}
+
+	cursor := lastEventID(c)
+	include := func(event *events.Event) bool {
+		if !members[event.ExecutionID] {
+			if eventRun, _ := event.Data["schedule_run_id"].(string); event.Type != "execution.started" || eventRun != run.ID.String() {
+				return false
+			}
+			members[event.ExecutionID] = true
+		}
+		if cursor == nil {
+			return true
+		}
+		// Skip what the client received before reconnecting
+		if event.ExecutionID == cursor.executionID {
+			return event.Seq > cursor.seq
+		}
+		return event.Timestamp.After(cursor.time)
+	}
+
+	h.stream(c, h.bus.Subscribe(filter), include, nil, func() (bool, gin.H) {
+		run, err := h.scheduleRepo.GetRun(runID)
+		if err != nil {
+			return false, nil
+		}
+		switch run.Status {
+		case "completed", "failed", "skipped":
+			return true, gin.H{"status": run.Status, "run": run}
+		}
+		return false, nil
+	})
+}
+
+// stream writes the events of a subscription until finished reports the end,
+// the subscription closes or the client goes away, then closes the
+// subscription. Events passing include are sent with a "log" event each. When
+// follow returns a subscription for an event sent, the stream continues with
+// that subscription instead.
+func (h *StreamHandler) stream(c *gin.Context, sub *events.Subscription, include func(*events.Event) bool, follow func(*events.Event) *events.Subscription, finished func() (bool, gin.H)) {
+	defer func() { sub.Close() }()
+
+	// The server's write timeout would cut the stream
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	write := func(event *events.Event) {
+		id := fmt.Sprintf("%s:%d:%d", event.ExecutionID, event.Seq, event.Timestamp.UnixNano())
+		writeSSE(c, id, event.Type, event)
+		if entry := logEntry(event); entry != nil {
+			writeSSE(c, "", "log", entry)
+		}
+	}
+	end := func(summary gin.H) {
+		writeSSE(c, "", "end", summary)
+		c.Writer.Flush()
+	}
+
+	// The first check is soon, for executions that finished before the
+	// stream opened and whose events are no longer buffered
+	check := time.NewTimer(time.Second)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Fell behind; the client reconnects with Last-Event-ID
+				return
+			}
+			if include != nil && !include(event) {
+				continue
+			}
+			write(event)
+			c.Writer.Flush()
+			if follow != nil {
+				if next := follow(event); next != nil {
+					sub.Close()
+					sub = next
+				}
+			}
+			if event.Type == "execution.completed" || event.Type == "execution.failed" {
+				// The final status is saved around the time the event is sent
+				check.Reset(250 * time.Millisecond)
+			}
+
+		case <-check.C:
+			if done, summary := finished(); done {
+				// Send what arrived before the end was noticed
+			drain:
+				for {
+					select {
+					case event, ok := <-sub.Events:
+						if !ok {
+							break drain
+						}
+						if include == nil || include(event) {
+							write(event)
+						}
+					default:
+						break drain
+					}
+				}
+				end(summary)
+				return
+			}
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+			check.Reset(streamHeartbeat)
+		}
+	}
+}
+
+// writeSSE writes one Server-Sent Event with a JSON payload
+func writeSSE(c *gin.Context, id, name string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", name, data)
+}
+
+// lastEventID reads the Last-Event-ID header, or the last_event_id query
+// parameter of clients that cannot set headers
+func lastEventID(c *gin.Context) *streamCursor {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return nil
+	}
+	executionID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return nil
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &streamCursor{executionID: executionID, seq: seq, time: time.Unix(0, nanos)}
+}
+
+// logEntry describes an event as a log line, or returns nil for events that
+// are not logged
+func logEntry(event *events.Event) *LogEntry {
+	entry := &LogEntry{Time: event.Timestamp, Level: "info", ExecutionID: event.ExecutionID}
+	str := func(key string) string {
+		value, _ := event.Data[key].(string)
+		return value
+	}
+	step := func() string {
+		entry.StepID = str("step_id")
+		if name := str("step_name"); name != "" {
+			return fmt.Sprintf("%s (%s)", name, entry.StepID)
+		}
+		return entry.StepID
+	}
+
+	switch event.Type {
+	case "execution.started":
+		entry.Message = fmt.Sprintf("Execution of %s started with %v steps", str("flow_name"), event.Data["total_steps"])
+	case "execution.completed":
+		entry.Message = fmt.Sprintf("Execution completed: %v passed, %v failed in %vms", event.Data["passed_steps"], event.Data["failed_steps"], event.Data["duration_ms"])
+	case "execution.failed":
+		entry.Level = "error"
+		entry.Message = "Execution failed: " + str("error")
+	case "step.started":
+		entry.Message = fmt.Sprintf("Step %s started (%s, %s)", step(), str("action"), str("phase"))
+	case "step.completed":
+		entry.Message = fmt.Sprintf("Step %s %s in %vms", step(), str("status"), event.Data["duration_ms"])
+	case "step.failed":
+		entry.Level = "error"
+		entry.Message = fmt.Sprintf("Step %s failed after %vms: %s", step(), event.Data["duration_ms"], str("error_message"))
+	case "debug.paused":
+		entry.Message = "Paused by debugger"
+	case "debug.resumed":
+		entry.Message = "Resumed by debugger"
+	default:
+		return nil
+	}
+	return entry
+}
+
+// executionFinished reports whether an execution has reached a final status
+func executionFinished(status models.ExecutionStatus) bool {
+	switch status {
+	case models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/plugins"
+	"github.com/georgi-georgiev/testmesh/internal/runner/parser"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ValidationHandler validates flow files and serves the flow JSON Schema
+type ValidationHandler struct {
+	registry *plugins.Registry
+	logger   *zap.Logger
+}
+
+// NewValidationHandler creates a new validation handler
+func NewValidationHandler(registry *plugins.Registry, logger *zap.Logger) *ValidationHandler {
+	return &ValidationHandler{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// ValidateFlowRequest is a flow file to validate
+type ValidateFlowRequest struct {
+	YAML string `json:"yaml" binding:"required"`
+}
+
+// ValidateFlowResponse lists the problems found in a flow file
+type ValidateFlowResponse struct {
+	Valid       bool                `json:"valid"` // No errors; warnings are allowed
+	Diagnostics []parser.Diagnostic `json:"diagnostics"`
+}
+
+// Validate handles POST /api/v1/workspaces/:workspace_id/flows/validate
+func (h *ValidationHandler) Validate(c *gin.Context) {
+	var req ValidateFlowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	diagnostics := parser.Validate([]byte(req.YAML), h.catalog())
+	if diagnostics == nil {
+		diagnostics = []parser.Diagnostic{}
+	}
+
+	c.JSON(http.StatusOK, ValidateFlowResponse{
+		Valid:       !parser.HasErrors(diagnostics),
+		Diagnostics: diagnostics,
+	})
+}
+
+// FlowSchema handles GET /api/v1/schemas/flow.json. Editors with YAML
+// language support use it for completion of flow files.
+func (h *ValidationHandler) FlowSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, parser.FlowSchema(h.catalog()))
+}
+
+// ListActions handles GET /api/v1/schemas/actions
+func (h *ValidationHandler) ListActions(c *gin.Context) {
+	catalog := h.catalog()
+	actions := catalog.Actions()
+
+	c.JSON(http.StatusOK, gin.H{
+		"actions":    actions,
+		"namespaces": catalog.Namespaces(),
+		"total":      len(actions),
+	})
+}
+
+// catalog lists the built-in actions and the actions of loaded plugins
+func (h *ValidationHandler) catalog() *parser.Catalog {
+	catalog := parser.NewCatalog()
+	if h.registry == nil {
+		return catalog
+	}
+
+	defs, undescribed := h.registry.ActionDefs()
+	for _, def := range defs {
+		plugin, _, _ := strings.Cut(def.ID, ".")
+		catalog.AddAction(parser.ActionSpec{
+			Action:      def.ID,
+			Description: def.Description,
+			Plugin:      plugin,
+			Schema:      def.Schema,
+		})
+	}
+	for _, name := range undescribed {
+		catalog.AddNamespace(name)
+	}
+	return catalog
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// parseGitHubEvent normalizes a GitHub push or pull_request event.
+// It returns nil for event types that do not trigger runs.
+func parseGitHubEvent(eventType string, body []byte) (*webhookEvent, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("Invalid JSON payload")
+	}
+
+	switch eventType {
+	case "push":
+		var pushEvent GitHubPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return nil, fmt.Errorf("Invalid push event format")
+		}
+		return &webhookEvent{
+			EventType:  "push",
+			Repository: pushEvent.Repository.FullName,
+			Branch:     extractBranchFromRef(pushEvent.Ref),
+			CommitSHA:  pushEvent.HeadCommit.ID,
+		}, nil
+
+	case "pull_request":
+		var prEvent GitHubPullRequestEvent
+		if err := json.Unmarshal(body, &prEvent); err != nil {
+			return nil, fmt.Errorf("Invalid pull_request event format")
+		}
+		return &webhookEvent{
+			EventType:  "pull_request",
+			Repository: prEvent.Repository.FullName,
+			Branch:     prEvent.PullRequest.Head.Ref,
+			CommitSHA:  prEvent.PullRequest.Head.SHA,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// GitLab webhook event structures
+type GitLabPushEvent struct {
+	Ref         string `json:"ref"`
+	CheckoutSHA string `json:"checkout_sha"` // Empty when the branch was deleted
+	Project     struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+type GitLabMergeRequestEvent struct {
+	ObjectAttributes struct {
+		Action       string `json:"action"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// parseGitLabEvent normalizes a GitLab "Push Hook" or "Merge Request Hook" event.
+// Merge request events only trigger when the request is opened, reopened or updated.
+func parseGitLabEvent(eventType string, body []byte) (*webhookEvent, error) {
+	switch eventType {
+	case "Push Hook":
+		var pushEvent GitLabPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return nil, fmt.Errorf("Invalid push event format")
+		}
+		if pushEvent.CheckoutSHA == "" {
+			return nil, nil
+		}
+		return &webhookEvent{
+			EventType:  "push",
+			Repository: pushEvent.Project.PathWithNamespace,
+			Branch:     extractBranchFromRef(pushEvent.Ref),
+			CommitSHA:  pushEvent.CheckoutSHA,
+		}, nil
+
+	case "Merge Request Hook":
+		var mrEvent GitLabMergeRequestEvent
+		if err := json.Unmarshal(body, &mrEvent); err != nil {
+			return nil, fmt.Errorf("Invalid merge request event format")
+		}
+		switch mrEvent.ObjectAttributes.Action {
+		case "open", "reopen", "update":
+		default:
+			return nil, nil
+		}
+		return &webhookEvent{
+			EventType:  "pull_request",
+			Repository: mrEvent.Project.PathWithNamespace,
+			Branch:     mrEvent.ObjectAttributes.SourceBranch,
+			CommitSHA:  mrEvent.ObjectAttributes.LastCommit.ID,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// Bitbucket webhook event structures
+type BitbucketRepository struct {
+	FullName string `json:"full_name"`
+}
+
+type BitbucketPushEvent struct {
+	Push struct {
+		Changes []struct {
+			New *struct {
+				Type   string `json:"type"`
+				Name   string `json:"name"`
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			} `json:"new"` // Null when the branch was deleted
+		} `json:"changes"`
+	} `json:"push"`
+	Repository BitbucketRepository `json:"repository"`
+}
+
+type BitbucketPullRequestEvent struct {
+	PullRequest struct {
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+			Commit struct {
+				Hash string `json:"hash"`
+			} `json:"commit"`
+		} `json:"source"`
+	} `json:"pullrequest"`
+	Repository BitbucketRepository `json:"repository"`
+}
+
+// parseBitbucketEvent normalizes a Bitbucket "repo:push", "pullrequest:created"
+// or "pullrequest:updated" event. A push with several branch changes triggers
+// for the last updated branch.
+func parseBitbucketEvent(eventType string, body []byte) (*webhookEvent, error) {
+	switch eventType {
+	case "repo:push":
+		var pushEvent BitbucketPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return nil, fmt.Errorf("Invalid push event format")
+		}
+		for i := len(pushEvent.Push.Changes) - 1; i >= 0; i-- {
+			change := pushEvent.Push.Changes[i].New
+			if change == nil || change.Type != "branch" {
+				continue
+			}
+			return &webhookEvent{
+				EventType:  "push",
+				Repository: pushEvent.Repository.FullName,
+				Branch:     change.Name,
+				CommitSHA:  change.Target.Hash,
+			}, nil
+		}
+		return nil, nil
+
+	case "pullrequest:created", "pullrequest:updated":
+		var prEvent BitbucketPullRequestEvent
+		if err := json.Unmarshal(body, &prEvent); err != nil {
+			return nil, fmt.Errorf("Invalid pull request event format")
+		}
+		return &webhookEvent{
+			EventType:  "pull_request",
+			Repository: prEvent.Repository.FullName,
+			Branch:     prEvent.PullRequest.Source.Branch.Name,
+			CommitSHA:  prEvent.PullRequest.Source.Commit.Hash,
+		}, nil
+	}
+
+	return nil, nil
+}
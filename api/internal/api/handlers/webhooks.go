@@ -3,14 +3,18 @@ package handlers
 import (
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/gitstatus"
+	"github.com/georgi-georgiev/testmesh/internal/gitsync"
 	"github.com/georgi-georgiev/testmesh/internal/scheduler"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
@@ -24,15 +28,22 @@ type WebhookHandler struct {
 	ruleRepo        *repository.GitTriggerRuleRepository
 	deliveryRepo    *repository.WebhookDeliveryRepository
 	scheduler       *scheduler.Scheduler
+	tracker         *gitstatus.Tracker
+	startFlow       FlowStarter
+	gitSync         *gitsync.Syncer
 	logger          *zap.Logger
 }
 
+// FlowStarter starts a flow execution in the background and returns its ID
+type FlowStarter func(flowID uuid.UUID, variables map[string]string) (uuid.UUID, error)
+
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler(
 	integrationRepo *repository.IntegrationRepository,
 	ruleRepo *repository.GitTriggerRuleRepository,
 	deliveryRepo *repository.WebhookDeliveryRepository,
 	scheduler *scheduler.Scheduler,
+	tracker *gitstatus.Tracker,
 	logger *zap.Logger,
 ) *WebhookHandler {
 	return &WebhookHandler{
@@ -40,10 +51,21 @@ func NewWebhookHandler(
 		ruleRepo:        ruleRepo,
 		deliveryRepo:    deliveryRepo,
 		scheduler:       scheduler,
+		tracker:         tracker,
 		logger:          logger,
 	}
 }
 
+// SetFlowStarter sets the function used by rules in direct trigger mode
+func (h *WebhookHandler) SetFlowStarter(fn FlowStarter) {
+	h.startFlow = fn
+}
+
+// SetGitSync sets the syncer that pulls bound collections on push events
+func (h *WebhookHandler) SetGitSync(syncer *gitsync.Syncer) {
+	h.gitSync = syncer
+}
+
 // GitHub webhook event structures
 type GitHubPushEvent struct {
 	Ref        string `json:"ref"`
@@ -77,6 +99,14 @@ type GitHubPullRequestEvent struct {
 	} `json:"repository"`
 }
 
+// webhookEvent is a git event normalized across providers
+type webhookEvent struct {
+	EventType  string // "push" or "pull_request"
+	Repository string
+	Branch     string
+	CommitSHA  string
+}
+
 // HandleGitHub handles POST /api/v1/webhooks/github
 func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 	// Read raw body for signature verification
@@ -101,68 +131,204 @@ func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 		return
 	}
 
-	// Load GitHub integration to get webhook secret
-	integration, err := h.integrationRepo.GetByTypeAndProviderWithSecrets(
-		models.IntegrationTypeGit,
-		models.IntegrationProviderGitHub,
-	)
-	if err != nil {
-		h.logger.Error("GitHub integration not found", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "GitHub integration not configured"})
+	integration, ok := h.loadIntegration(c, models.IntegrationProviderGitHub)
+	if !ok {
 		return
 	}
 
 	// Verify signature
-	webhookSecret := integration.Secrets["webhook_secret"]
-	if !verifyGitHubSignature(body, webhookSecret, signature) {
-		h.logger.Warn("Invalid webhook signature")
-		// Log failed delivery
-		h.logDelivery(integration.ID, nil, eventType, "", "", "", body, signature, models.WebhookDeliveryStatusRejected, "Invalid signature", nil)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+	if !verifySHA256Signature(body, integration.Secrets["webhook_secret"], signature) {
+		h.rejectDelivery(c, integration, eventType, body, signature)
 		return
 	}
 
-	// Parse payload based on event type
-	var repository, branch, commitSHA string
-	var payload map[string]interface{}
+	event, err := parseGitHubEvent(eventType, body)
+	if err != nil {
+		h.logger.Error("Failed to parse webhook payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.processEvent(c, integration, eventType, event, body, signature)
+}
+
+// HandleGitLab handles POST /api/v1/webhooks/gitlab
+func (h *WebhookHandler) HandleGitLab(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to read webhook body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	eventType := c.GetHeader("X-Gitlab-Event")
+	if eventType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing X-Gitlab-Event header"})
+		return
+	}
+
+	// GitLab sends the configured secret token as is
+	token := c.GetHeader("X-Gitlab-Token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing X-Gitlab-Token header"})
+		return
+	}
+
+	integration, ok := h.loadIntegration(c, models.IntegrationProviderGitLab)
+	if !ok {
+		return
+	}
 
-	if err := json.Unmarshal(body, &payload); err != nil {
+	secret := integration.Secrets["webhook_secret"]
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		h.rejectDelivery(c, integration, eventType, body, "")
+		return
+	}
+
+	event, err := parseGitLabEvent(eventType, body)
+	if err != nil {
 		h.logger.Error("Failed to parse webhook payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON payload"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	switch eventType {
-	case "push":
-		var pushEvent GitHubPushEvent
-		if err := json.Unmarshal(body, &pushEvent); err != nil {
-			h.logger.Error("Failed to parse push event", zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid push event format"})
-			return
-		}
-		repository = pushEvent.Repository.FullName
-		branch = extractBranchFromRef(pushEvent.Ref)
-		commitSHA = pushEvent.HeadCommit.ID
-
-	case "pull_request":
-		var prEvent GitHubPullRequestEvent
-		if err := json.Unmarshal(body, &prEvent); err != nil {
-			h.logger.Error("Failed to parse pull_request event", zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pull_request event format"})
-			return
-		}
-		repository = prEvent.Repository.FullName
-		branch = prEvent.PullRequest.Head.Ref
-		commitSHA = prEvent.PullRequest.Head.SHA
+	h.processEvent(c, integration, eventType, event, body, "")
+}
 
-	default:
-		h.logger.Info("Ignoring unsupported event type", zap.String("event_type", eventType))
+// HandleBitbucket handles POST /api/v1/webhooks/bitbucket
+func (h *WebhookHandler) HandleBitbucket(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to read webhook body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	eventType := c.GetHeader("X-Event-Key")
+	if eventType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing X-Event-Key header"})
+		return
+	}
+
+	// Bitbucket signs with the same HMAC-SHA256 scheme as GitHub
+	signature := c.GetHeader("X-Hub-Signature")
+	if signature == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing X-Hub-Signature header"})
+		return
+	}
+
+	integration, ok := h.loadIntegration(c, models.IntegrationProviderBitbucket)
+	if !ok {
+		return
+	}
+
+	if !verifySHA256Signature(body, integration.Secrets["webhook_secret"], signature) {
+		h.rejectDelivery(c, integration, eventType, body, signature)
+		return
+	}
+
+	event, err := parseBitbucketEvent(eventType, body)
+	if err != nil {
+		h.logger.Error("Failed to parse webhook payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.processEvent(c, integration, eventType, event, body, signature)
+}
+
+// GenericWebhookEvent is the payload of a generic CI webhook
+type GenericWebhookEvent struct {
+	Event      string `json:"event"` // "push" (default) or "pull_request"
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	CommitSHA  string `json:"commit_sha"`
+}
+
+// HandleGeneric handles POST /api/v1/webhooks/generic
+// The body is signed with X-TestMesh-Signature: sha256=HMAC(secret, "<timestamp>.<body>"),
+// where the timestamp is sent in X-TestMesh-Timestamp as Unix seconds.
+func (h *WebhookHandler) HandleGeneric(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to read webhook body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	timestamp := c.GetHeader("X-TestMesh-Timestamp")
+	signature := c.GetHeader("X-TestMesh-Signature")
+	if timestamp == "" || signature == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing X-TestMesh-Timestamp or X-TestMesh-Signature header"})
+		return
+	}
+
+	integration, ok := h.loadIntegration(c, models.IntegrationProviderGeneric)
+	if !ok {
+		return
+	}
+
+	var payload GenericWebhookEvent
+	json.Unmarshal(body, &payload)
+	if payload.Event == "" {
+		payload.Event = "push"
+	}
+
+	if !verifyGenericSignature(body, integration.Secrets["webhook_secret"], timestamp, signature, time.Now()) {
+		h.rejectDelivery(c, integration, payload.Event, body, signature)
+		return
+	}
+
+	if payload.Repository == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repository is required"})
+		return
+	}
+
+	event := &webhookEvent{
+		EventType:  payload.Event,
+		Repository: payload.Repository,
+		Branch:     extractBranchFromRef(payload.Branch),
+		CommitSHA:  payload.CommitSHA,
+	}
+	h.processEvent(c, integration, payload.Event, event, body, signature)
+}
+
+// loadIntegration loads the git integration of a provider with its secrets
+func (h *WebhookHandler) loadIntegration(c *gin.Context, provider models.IntegrationProvider) (*models.SystemIntegration, bool) {
+	integration, err := h.integrationRepo.GetByTypeAndProviderWithSecrets(models.IntegrationTypeGit, provider)
+	if err != nil {
+		h.logger.Error("Git integration not found", zap.String("provider", string(provider)), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s integration not configured", provider)})
+		return nil, false
+	}
+	return integration, true
+}
+
+// rejectDelivery logs and rejects a delivery with an invalid signature or token
+func (h *WebhookHandler) rejectDelivery(c *gin.Context, integration *models.SystemIntegration, eventType string, body []byte, signature string) {
+	h.logger.Warn("Invalid webhook signature", zap.String("provider", string(integration.Provider)))
+	h.logDelivery(integration.ID, nil, eventType, "", "", "", body, signature, models.WebhookDeliveryStatusRejected, "Invalid signature", nil)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+}
+
+// processEvent triggers the rules matching a verified event and reports the
+// results back to the provider once the triggered runs finish
+func (h *WebhookHandler) processEvent(c *gin.Context, integration *models.SystemIntegration, rawEventType string, event *webhookEvent, body []byte, signature string) {
+	if event == nil {
+		h.logger.Info("Ignoring unsupported event type", zap.String("event_type", rawEventType))
 		c.JSON(http.StatusOK, gin.H{"message": "Event type not supported"})
 		return
 	}
 
+	repository, branch, commitSHA, eventType := event.Repository, event.Branch, event.CommitSHA, event.EventType
+
+	// Collections synced with the pushed branch pull the change
+	if eventType == "push" && h.gitSync != nil {
+		h.gitSync.RepositoryPushed(repository, branch)
+	}
+
 	// Find matching trigger rules
-	rules, err := h.ruleRepo.FindMatchingRules(repository, branch, eventType)
+	rules, err := h.ruleRepo.FindMatchingRules(integration.ID, repository, branch, eventType)
 	if err != nil {
 		h.logger.Error("Failed to find matching rules", zap.Error(err))
 		h.logDelivery(integration.ID, nil, eventType, repository, branch, commitSHA, body, signature, models.WebhookDeliveryStatusFailed, err.Error(), nil)
@@ -189,8 +355,9 @@ func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 
 	// Trigger executions for each matching rule
 	var triggeredRuns []uuid.UUID
+	var trackedRuns []gitstatus.Run
 	for _, rule := range rules {
-		runID, err := h.triggerRule(rule, commitSHA, payload)
+		run, err := h.triggerRule(rule, integration.Provider, event)
 		if err != nil {
 			h.logger.Error("Failed to trigger rule",
 				zap.String("rule_id", rule.ID.String()),
@@ -198,28 +365,53 @@ func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 			)
 			continue
 		}
-		if runID != uuid.Nil {
-			triggeredRuns = append(triggeredRuns, runID)
+		if run != nil {
+			triggeredRuns = append(triggeredRuns, run.ID)
+			trackedRuns = append(trackedRuns, *run)
 		}
 	}
 
 	// Log successful delivery
 	h.logDelivery(integration.ID, nil, eventType, repository, branch, commitSHA, body, signature, models.WebhookDeliveryStatusSuccess, "", triggeredRuns)
 
+	h.reportStatus(integration, event, trackedRuns)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":          "Webhook processed successfully",
-		"repository":       repository,
-		"branch":           branch,
-		"commit_sha":       commitSHA,
-		"event_type":       eventType,
-		"matched_rules":    len(rules),
-		"triggered_runs":   len(triggeredRuns),
+		"message":           "Webhook processed successfully",
+		"repository":        repository,
+		"branch":            branch,
+		"commit_sha":        commitSHA,
+		"event_type":        eventType,
+		"matched_rules":     len(rules),
+		"triggered_runs":    len(triggeredRuns),
 		"triggered_run_ids": triggeredRuns,
 	})
 }
 
+// reportStatus starts reporting the triggered runs as a commit status when the integration asks for it
+func (h *WebhookHandler) reportStatus(integration *models.SystemIntegration, event *webhookEvent, runs []gitstatus.Run) {
+	if h.tracker == nil || len(runs) == 0 {
+		return
+	}
+
+	reporter, err := gitstatus.NewReporter(integration, nil)
+	if err != nil {
+		h.logger.Warn("Commit status reporting is misconfigured",
+			zap.String("provider", string(integration.Provider)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	h.tracker.Track(reporter, gitstatus.Commit{
+		Repository: event.Repository,
+		SHA:        event.CommitSHA,
+		Branch:     event.Branch,
+	}, integration.Config.StatusContext, runs)
+}
+
 // triggerRule triggers a test execution based on a git trigger rule
-func (h *WebhookHandler) triggerRule(rule *models.GitTriggerRule, commitSHA string, payload map[string]interface{}) (uuid.UUID, error) {
+func (h *WebhookHandler) triggerRule(rule *models.GitTriggerRule, provider models.IntegrationProvider, event *webhookEvent) (*gitstatus.Run, error) {
 	h.logger.Info("Triggering rule",
 		zap.String("rule_id", rule.ID.String()),
 		zap.String("rule_name", rule.Name),
@@ -229,34 +421,46 @@ func (h *WebhookHandler) triggerRule(rule *models.GitTriggerRule, commitSHA stri
 	switch rule.TriggerMode {
 	case models.TriggerModeSchedule:
 		if rule.ScheduleID == nil {
-			return uuid.Nil, fmt.Errorf("schedule_id is nil for schedule trigger mode")
+			return nil, fmt.Errorf("schedule_id is nil for schedule trigger mode")
 		}
 		// Trigger schedule run
 		run, err := h.scheduler.TriggerSchedule(*rule.ScheduleID)
 		if err != nil {
-			return uuid.Nil, err
+			return nil, err
 		}
-		if run != nil {
-			return run.ID, nil
+		if run == nil {
+			return nil, nil
 		}
-		return uuid.Nil, nil
+		return &gitstatus.Run{Kind: gitstatus.RunSchedule, ID: run.ID, ScheduleID: *rule.ScheduleID, Name: rule.Name}, nil
 
 	case models.TriggerModeDirect:
 		if rule.FlowID == nil {
-			return uuid.Nil, fmt.Errorf("flow_id is nil for direct trigger mode")
+			return nil, fmt.Errorf("flow_id is nil for direct trigger mode")
+		}
+		if h.startFlow == nil {
+			return nil, fmt.Errorf("direct flow execution is not available")
+		}
+
+		// Expose the git event to the flow as variables
+		executionID, err := h.startFlow(*rule.FlowID, map[string]string{
+			"git_provider":   string(provider),
+			"git_event":      event.EventType,
+			"git_repository": event.Repository,
+			"git_branch":     event.Branch,
+			"git_commit_sha": event.CommitSHA,
+		})
+		if err != nil {
+			return nil, err
 		}
-		// Execute flow directly
-		// For now, return a placeholder - the actual execution logic will be implemented
-		// when wiring up the webhook handler in main.go
 		h.logger.Info("Direct flow execution triggered",
 			zap.String("flow_id", rule.FlowID.String()),
-			zap.String("commit_sha", commitSHA),
+			zap.String("execution_id", executionID.String()),
+			zap.String("commit_sha", event.CommitSHA),
 		)
-		// TODO: Implement direct flow execution
-		return uuid.Nil, nil
+		return &gitstatus.Run{Kind: gitstatus.RunExecution, ID: executionID, Name: rule.Name}, nil
 
 	default:
-		return uuid.Nil, fmt.Errorf("unknown trigger mode: %s", rule.TriggerMode)
+		return nil, fmt.Errorf("unknown trigger mode: %s", rule.TriggerMode)
 	}
 }
 
@@ -299,14 +503,34 @@ func (h *WebhookHandler) logDelivery(
 	}
 }
 
-// verifyGitHubSignature verifies the HMAC signature from GitHub
-func verifyGitHubSignature(payload []byte, secret string, signature string) bool {
+// verifySHA256Signature verifies an "sha256=<hex>" HMAC signature as sent by GitHub and Bitbucket
+func verifySHA256Signature(payload []byte, secret string, signature string) bool {
+	if secret == "" {
+		return false
+	}
 	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(payload)
 	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
 	return hmac.Equal([]byte(expected), []byte(signature))
 }
 
+// verifyGenericSignature verifies a generic webhook signature and rejects
+// timestamps more than five minutes from now to prevent replays
+func verifyGenericSignature(payload []byte, secret, timestamp, signature string, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > 5*time.Minute || skew < -5*time.Minute {
+		return false
+	}
+	expected := gitstatus.Signature(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
 // extractBranchFromRef extracts branch name from a Git ref (e.g., "refs/heads/main" -> "main")
 func extractBranchFromRef(ref string) string {
 	const prefix = "refs/heads/"
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/gitstatus"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySHA256Signature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{"valid", "s3cret", githubSignature("s3cret", body), true},
+		{"wrong secret", "s3cret", githubSignature("other", body), false},
+		{"tampered body", "s3cret", githubSignature("s3cret", []byte(`{}`)), false},
+		{"missing prefix", "s3cret", strings.TrimPrefix(githubSignature("s3cret", body), "sha256="), false},
+		{"no secret configured", "", githubSignature("", body), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifySHA256Signature(body, tt.secret, tt.signature); got != tt.want {
+				t.Errorf("verifySHA256Signature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyGenericSignature(t *testing.T) {
+	body := []byte(`{"repository":"acme/payments"}`)
+	now := time.Unix(1_700_000_000, 0)
+	stamp := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		want      bool
+	}{
+		{"valid", "shh", stamp(0), gitstatus.Signature("shh", stamp(0), body), true},
+		{"within skew", "shh", stamp(-4 * time.Minute), gitstatus.Signature("shh", stamp(-4*time.Minute), body), true},
+		{"replayed", "shh", stamp(-6 * time.Minute), gitstatus.Signature("shh", stamp(-6*time.Minute), body), false},
+		{"from the future", "shh", stamp(6 * time.Minute), gitstatus.Signature("shh", stamp(6*time.Minute), body), false},
+		{"timestamp not signed", "shh", stamp(0), gitstatus.Signature("shh", stamp(-time.Second), body), false},
+		{"invalid timestamp", "shh", "yesterday", gitstatus.Signature("shh", "yesterday", body), false},
+		{"wrong secret", "shh", stamp(0), gitstatus.Signature("other", stamp(0), body), false},
+		{"no secret configured", "", stamp(0), gitstatus.Signature("", stamp(0), body), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyGenericSignature(body, tt.secret, tt.timestamp, tt.signature, now); got != tt.want {
+				t.Errorf("verifyGenericSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWebhookRequiresCredentials checks that deliveries without an event type,
+// signature or token are rejected before any integration is loaded
+func TestWebhookRequiresCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewWebhookHandler(nil, nil, nil, nil, nil, zap.NewNop())
+
+	router := gin.New()
+	router.POST("/webhooks/github", handler.HandleGitHub)
+	router.POST("/webhooks/gitlab", handler.HandleGitLab)
+	router.POST("/webhooks/bitbucket", handler.HandleBitbucket)
+	router.POST("/webhooks/generic", handler.HandleGeneric)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{"github without event", "/webhooks/github", map[string]string{"X-Hub-Signature-256": "sha256=00"}, http.StatusBadRequest},
+		{"github without signature", "/webhooks/github", map[string]string{"X-GitHub-Event": "push"}, http.StatusUnauthorized},
+		{"gitlab without event", "/webhooks/gitlab", map[string]string{"X-Gitlab-Token": "t"}, http.StatusBadRequest},
+		{"gitlab without token", "/webhooks/gitlab", map[string]string{"X-Gitlab-Event": "Push Hook"}, http.StatusUnauthorized},
+		{"bitbucket without event", "/webhooks/bitbucket", map[string]string{"X-Hub-Signature": "sha256=00"}, http.StatusBadRequest},
+		{"bitbucket without signature", "/webhooks/bitbucket", map[string]string{"X-Event-Key": "repo:push"}, http.StatusUnauthorized},
+		{"generic without timestamp", "/webhooks/generic", map[string]string{"X-TestMesh-Signature": "sha256=00"}, http.StatusUnauthorized},
+		{"generic without signature", "/webhooks/generic", map[string]string{"X-TestMesh-Timestamp": "1700000000"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{}`))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseProviderEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string, []byte) (*webhookEvent, error)
+		event string
+		body  string
+		want  *webhookEvent
+	}{
+		{
+			name:  "github push",
+			parse: parseGitHubEvent,
+			event: "push",
+			body:  `{"ref":"refs/heads/main","repository":{"full_name":"acme/payments"},"head_commit":{"id":"abc"}}`,
+			want:  &webhookEvent{EventType: "push", Repository: "acme/payments", Branch: "main", CommitSHA: "abc"},
+		},
+		{
+			name:  "github ping is ignored",
+			parse: parseGitHubEvent,
+			event: "ping",
+			body:  `{}`,
+		},
+		{
+			name:  "gitlab merge request",
+			parse: parseGitLabEvent,
+			event: "Merge Request Hook",
+			body:  `{"object_attributes":{"action":"update","source_branch":"feature","last_commit":{"id":"def"}},"project":{"path_with_namespace":"group/project"}}`,
+			want:  &webhookEvent{EventType: "pull_request", Repository: "group/project", Branch: "feature", CommitSHA: "def"},
+		},
+		{
+			name:  "gitlab closed merge request is ignored",
+			parse: parseGitLabEvent,
+			event: "Merge Request Hook",
+			body:  `{"object_attributes":{"action":"close"}}`,
+		},
+		{
+			name:  "gitlab branch deletion is ignored",
+			parse: parseGitLabEvent,
+			event: "Push Hook",
+			body:  `{"ref":"refs/heads/old","checkout_sha":""}`,
+		},
+		{
+			name:  "bitbucket push uses the last branch change",
+			parse: parseBitbucketEvent,
+			event: "repo:push",
+			body:  `{"push":{"changes":[{"new":{"type":"branch","name":"a","target":{"hash":"1"}}},{"new":{"type":"tag","name":"v1","target":{"hash":"2"}}},{"new":null}]},"repository":{"full_name":"acme/web"}}`,
+			want:  &webhookEvent{EventType: "push", Repository: "acme/web", Branch: "a", CommitSHA: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.event, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want the event ignored", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
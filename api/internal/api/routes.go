@@ -11,16 +11,24 @@ import (
 	"github.com/georgi-georgiev/testmesh/internal/api/handlers"
 	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
 	"github.com/georgi-georgiev/testmesh/internal/api/websocket"
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
 	"github.com/georgi-georgiev/testmesh/internal/auth"
+	"github.com/georgi-georgiev/testmesh/internal/gitstatus"
+	"github.com/georgi-georgiev/testmesh/internal/gitsync"
 	"github.com/georgi-georgiev/testmesh/internal/loadtest"
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
+	"github.com/georgi-georgiev/testmesh/internal/monitoring"
 	"github.com/georgi-georgiev/testmesh/internal/plugins"
+	"github.com/georgi-georgiev/testmesh/internal/quarantine"
 	"github.com/georgi-georgiev/testmesh/internal/reporting"
 	"github.com/georgi-georgiev/testmesh/internal/runner"
 	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
 	"github.com/georgi-georgiev/testmesh/internal/runner/mocks"
 	"github.com/georgi-georgiev/testmesh/internal/scheduler"
 	"github.com/georgi-georgiev/testmesh/internal/security"
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -56,7 +64,7 @@ func (a *integrationRepoAdapter) GetAIIntegrations() ([]*ai.IntegrationData, err
 }
 
 // NewRouter creates and configures the API router
-func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int) *gin.Engine {
+func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, serverCfg config.ServerConfig, m *metrics.Metrics, artifactsCfg config.ArtifactsConfig, runnerCfg config.RunnerConfig) *gin.Engine {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
 
@@ -76,6 +84,9 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	reportingRepo := repository.NewReportingRepository(db)
 	collectionRepo := repository.NewCollectionRepository(db)
 	historyRepo := repository.NewHistoryRepository(db)
+	apiSpecRepo := repository.NewAPISpecRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	contextSnapshotRepo := repository.NewContextSnapshotRepository(db)
 
 	// Initialize encryption service for integrations
 	encryptionKey := os.Getenv("ENCRYPTION_KEY")
@@ -127,14 +138,15 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	oauth2Service := auth.NewOAuth2Service(logger)
 
 	// Initialize singleton mock manager (routes through main API server)
-	mockBaseURL := fmt.Sprintf("http://localhost:%d", port)
+	mockBaseURL := fmt.Sprintf("http://localhost:%d", serverCfg.Port)
 	mockManager := mocks.NewManager(mockRepo, logger, mockBaseURL)
 	mockManager.RestoreRunningServers() // re-register DB-persisted running servers on startup
+	mockManager.SetMetrics(m)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(db)
 	flowHandler := handlers.NewFlowHandler(flowRepo, logger)
-	executionHandler := handlers.NewExecutionHandler(executionRepo, flowRepo, envRepo, contractRepo, mockManager, logger, wsHub)
+	executionHandler := handlers.NewExecutionHandler(executionRepo, flowRepo, envRepo, contractRepo, apiSpecRepo, snapshotRepo, mockManager, logger, wsHub)
 	mockHandler := handlers.NewMockHandler(mockRepo, mockManager, logger)
 	contractHandler := handlers.NewContractHandler(contractRepo, logger)
 	reportingHandler := handlers.NewReportingHandler(reportingRepo, aggregator, generator, logger)
@@ -143,6 +155,17 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	oauth2Handler := handlers.NewOAuth2Handler(oauth2Service, logger)
 	historyHandler := handlers.NewHistoryHandler(historyRepo, logger)
 	wsHandler := websocket.NewHandler(wsHub, logger)
+	wsHandler.SetAllowedOrigins(serverCfg.AllowedOrigins)
+	wsHub.SetWorkspaceResolver(func(executionID uuid.UUID) (uuid.UUID, error) {
+		execution, err := executionRepo.GetByID(executionID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if execution.Flow == nil {
+			return uuid.Nil, nil
+		}
+		return execution.Flow.WorkspaceID, nil
+	})
 
 	// Initialize debug controller
 	debugController := debugger.NewController(logger)
@@ -151,8 +174,16 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	// Initialize collection runner (executor created per-run to support parallel executions)
 	executor := runner.NewExecutor(executionRepo, contractRepo, logger, wsHub, nil)
 	executor.SetDebugController(debugController)
+	executor.SetSchemaDir(runnerCfg.SchemaDir)
+	executionHandler.SetDebugController(debugController)
+	executionHandler.SetSchemaDir(runnerCfg.SchemaDir)
+	dataFileRepo := repository.NewDataFileRepository(db)
 	collectionRunner := runner.NewCollectionRunner(executor, logger)
+	collectionRunner.SetDataFiles(dataFileRepo)
+	collectionRunner.SetFlows(flowRepo)
+	collectionRunner.SetDataSourceHosts(runnerCfg.DataSourceHosts)
 	runnerHandler := handlers.NewRunnerHandler(collectionRunner, flowRepo, envRepo, logger)
+	dataFileHandler := handlers.NewDataFileHandler(dataFileRepo, logger)
 
 	// Initialize debug handler
 	debugHandler := handlers.NewDebugHandler(debugController, logger)
@@ -165,12 +196,17 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	bulkHandler := handlers.NewBulkHandler(flowRepo, collectionRepo, logger)
 
 	// Initialize import/export handler
-	importExportHandler := handlers.NewImportExportHandler(flowRepo, logger)
+	importExportHandler := handlers.NewImportExportHandler(flowRepo, collectionRepo, envRepo, apiSpecRepo, logger)
+	apiSpecHandler := handlers.NewAPISpecHandler(apiSpecRepo, logger)
+	snapshotHandler := handlers.NewSnapshotHandler(snapshotRepo, flowRepo, logger)
 
 	// Initialize load test handler
 	loadTester := loadtest.NewLoadTester(logger)
 	loadTestHandler := handlers.NewLoadTestHandler(loadTester, flowRepo, envRepo, logger)
 
+	// Initialize code generation handler
+	codegenHandler := handlers.NewCodegenHandler(flowRepo, envRepo, logger)
+
 	// Initialize plugin registry
 	pluginDir := filepath.Join(os.TempDir(), "testmesh", "plugins")
 	pluginRegistry := plugins.NewRegistry(pluginDir, logger)
@@ -183,16 +219,20 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	pluginRegistry.Discover()
 	pluginRegistry.LoadAll()
 	pluginHandler := handlers.NewPluginHandler(pluginRegistry, logger)
+	validationHandler := handlers.NewValidationHandler(pluginRegistry, logger)
+
+	// Make report formats of reporter plugins available to exports
+	generator.Exporters().SetReporterPlugins(pluginRegistry)
+	executionHandler.SetReportGenerator(generator)
+	executionHandler.SetMetrics(m)
 
 	// Initialize scheduler
 	scheduleRepo := repository.NewScheduleRepository(db)
-	sched := scheduler.NewScheduler(scheduleRepo, logger)
+	sched := scheduler.NewScheduler(scheduleRepo, flowRepo, collectionRepo, logger)
+	sched.SetExecutionFunc(executionHandler.RunScheduled)
+	sched.SetMetrics(m)
 	scheduleHandler := handlers.NewScheduleHandler(scheduleRepo, sched, logger)
-
-	// Start the scheduler
-	if err := sched.Start(); err != nil {
-		logger.Error("Failed to start scheduler", zap.Error(err))
-	}
+	scheduleHandler.SetReportGenerator(generator)
 
 	// Initialize collaboration handler
 	collaborationRepo := repository.NewCollaborationRepository(db)
@@ -204,11 +244,87 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 	// Initialize integration handlers
 	integrationHandler := handlers.NewIntegrationHandler(integrationRepo, aiProviders, logger)
 	gitTriggerRuleHandler := handlers.NewGitTriggerRuleHandler(gitTriggerRuleRepo, logger)
-	webhookHandler := handlers.NewWebhookHandler(integrationRepo, gitTriggerRuleRepo, webhookDeliveryRepo, sched, logger)
+	publicURL := os.Getenv("TESTMESH_PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:3000"
+	}
+	statusTracker := gitstatus.NewTracker(executionRepo, scheduleRepo, publicURL, logger)
+	webhookHandler := handlers.NewWebhookHandler(integrationRepo, gitTriggerRuleRepo, webhookDeliveryRepo, sched, statusTracker, logger)
+	webhookHandler.SetFlowStarter(executionHandler.StartFlow)
+
+	// Initialize git sync of collections
+	gitSyncRepo := repository.NewGitSyncRepository(db)
+	gitSyncer := gitsync.NewSyncer(gitSyncRepo, flowRepo, collaborationRepo, integrationRepo, filepath.Join(os.TempDir(), "testmesh", "git"), logger)
+	gitSyncer.Start()
+	gitSyncHandler := handlers.NewGitSyncHandler(gitSyncRepo, collectionRepo, integrationRepo, gitSyncer, logger)
+	flowHandler.SetGitSync(gitSyncer)
+	webhookHandler.SetGitSync(gitSyncer)
+
+	// Initialize flaky flow quarantine
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	quarantineManager := quarantine.NewManager(quarantineRepo, workspaceRepo, flowRepo, executionRepo, collaborationRepo, logger)
+	quarantineHandler := handlers.NewQuarantineHandler(quarantineRepo, flowRepo, workspaceRepo, quarantineManager, logger)
+	executionHandler.SetQuarantine(quarantineManager)
+	aggregator.SetFlakinessHandler(quarantineManager.ConsiderFlakiness)
+
+	// Initialize synthetic monitoring; the run observer must be set before the scheduler starts
+	monitorRepo := repository.NewMonitorRepository(db)
+	monitorManager := monitoring.NewManager(monitorRepo, scheduleRepo, sched, collaborationRepo, os.Getenv("TESTMESH_LOCATION"), logger)
+	monitorHandler := handlers.NewMonitorHandler(monitorRepo, flowRepo, workspaceRepo, monitorManager, logger)
+	sched.SetRunObserver(monitorManager.ObserveRun)
+
+	// Initialize execution artifact storage; scheduled runs offload their outputs,
+	// so the manager must be set before the scheduler starts
+	artifactStore, err := artifacts.NewStore(artifactsCfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize artifact store", zap.Error(err))
+	}
+	artifactRepo := repository.NewArtifactRepository(db)
+	artifactManager := artifacts.NewManager(artifactStore, artifactRepo, workspaceRepo, artifactsCfg, logger)
+	artifactManager.Start()
+	artifactHandler := handlers.NewArtifactHandler(artifactRepo, executionRepo, artifactManager, logger)
+	executionHandler.SetArtifacts(artifactManager)
+	executionHandler.SetContextSnapshots(contextSnapshotRepo)
+	artifactsURL := artifactsCfg.BaseURL
+	if artifactsURL == "" {
+		artifactsURL = fmt.Sprintf("http://localhost:%d", serverCfg.Port)
+	}
+	generator.SetArtifacts(artifactManager, artifactsURL)
+
+	// Start the scheduler
+	if err := sched.Start(); err != nil {
+		logger.Error("Failed to start scheduler", zap.Error(err))
+	}
+
+	// Initialize run comparison
+	comparer := reporting.NewComparer(executionRepo, reportingRepo, logger)
+	comparer.SetArtifacts(artifactManager)
+	collectionRunner.SetArtifacts(artifactManager)
+	compareHandler := handlers.NewCompareHandler(executionRepo, scheduleRepo, comparer, logger)
+
+	// Initialize Server-Sent Event streams
+	streamHandler := handlers.NewStreamHandler(wsHub.Bus(), executionRepo, scheduleRepo, logger)
 
 	// Health check
 	router.GET("/health", healthHandler.Check)
 
+	// Prometheus metrics
+	if m != nil {
+		m.RegisterGauge("websocket_clients", "Connected WebSocket clients.", func() float64 {
+			return float64(wsHub.GetTotalClientCount())
+		})
+		m.RegisterGauge("mock_servers_running", "Running mock servers.", func() float64 {
+			return float64(mockManager.ServerCount())
+		})
+		m.RegisterGauge("scheduler_leader", "1 if this replica holds the scheduler lease.", func() float64 {
+			if sched.IsLeader() {
+				return 1
+			}
+			return 0
+		})
+		router.GET(m.Path(), gin.WrapH(m.Handler()))
+	}
+
 	// Mock server wildcard route — serves all mock endpoints through the main API server
 	router.Any("/mocks/:server_id/*path", mockManager.GinHandler())
 
@@ -229,6 +345,42 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 				gitTriggerRules.DELETE("/:id", gitTriggerRuleHandler.Delete)
 			}
 
+			// Git sync bindings (workspace-scoped)
+			gitSync := ws.Group("/git-sync")
+			{
+				gitSync.GET("", gitSyncHandler.List)
+				gitSync.POST("", gitSyncHandler.Create)
+				gitSync.GET("/:id", gitSyncHandler.Get)
+				gitSync.PUT("/:id", gitSyncHandler.Update)
+				gitSync.DELETE("/:id", gitSyncHandler.Delete)
+				gitSync.POST("/:id/sync", gitSyncHandler.Sync)
+				gitSync.POST("/:id/resolve", gitSyncHandler.Resolve)
+			}
+
+			// Flaky flow quarantine (workspace-scoped)
+			quarantines := ws.Group("/quarantine")
+			{
+				quarantines.GET("", quarantineHandler.List)
+				quarantines.POST("", quarantineHandler.Create)
+				quarantines.GET("/:id", quarantineHandler.Get)
+				quarantines.DELETE("/:id", quarantineHandler.Release)
+			}
+
+			// Synthetic monitors (workspace-scoped)
+			monitors := ws.Group("/monitors")
+			{
+				monitors.GET("", monitorHandler.List)
+				monitors.POST("", monitorHandler.Create)
+				monitors.GET("/:id", monitorHandler.Get)
+				monitors.PUT("/:id", monitorHandler.Update)
+				monitors.DELETE("/:id", monitorHandler.Delete)
+				monitors.POST("/:id/pause", monitorHandler.Pause)
+				monitors.POST("/:id/resume", monitorHandler.Resume)
+				monitors.POST("/:id/check", monitorHandler.Check)
+				monitors.GET("/:id/checks", monitorHandler.Checks)
+				monitors.GET("/:id/alerts", monitorHandler.Alerts)
+			}
+
 			// Collection routes (workspace-scoped)
 			collections := ws.Group("/collections")
 			{
@@ -254,9 +406,12 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 			{
 				flows.POST("", flowHandler.Create)
 				flows.GET("", flowHandler.List)
+				flows.POST("/validate", validationHandler.Validate)
 				flows.GET("/:id", flowHandler.Get)
 				flows.PUT("/:id", flowHandler.Update)
 				flows.DELETE("/:id", flowHandler.Delete)
+				flows.GET("/:id/snapshots", snapshotHandler.ListByFlow)
+				flows.POST("/:id/snapshots/accept", snapshotHandler.AcceptAll)
 			}
 
 			// Environment routes (workspace-scoped)
@@ -274,16 +429,78 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 				environments.GET("/:id/export", envHandler.Export)
 			}
 
+			// Data file routes (workspace-scoped), rows of data-driven runs
+			dataFiles := ws.Group("/data-files")
+			{
+				dataFiles.GET("", dataFileHandler.List)
+				dataFiles.POST("", dataFileHandler.Create)
+				dataFiles.GET("/:id", dataFileHandler.Get)
+				dataFiles.PUT("/:id", dataFileHandler.Update)
+				dataFiles.DELETE("/:id", dataFileHandler.Delete)
+				dataFiles.GET("/:id/preview", dataFileHandler.Preview)
+			}
+
+			// Collection runner routes (workspace-scoped), which can read
+			// data files and executions of the workspace
+			wsRunner := ws.Group("/runner")
+			{
+				wsRunner.POST("/run", runnerHandler.Run)
+				wsRunner.POST("/parse-data", runnerHandler.ParseData)
+			}
+
 			// Execution routes (workspace-scoped)
+			// Event stream of the workspace's executions (WebSocket)
+			ws.GET("/events", wsHandler.HandleWorkspaceConnection)
+
 			executions := ws.Group("/executions")
 			{
 				executions.POST("", executionHandler.Create)
 				executions.GET("", executionHandler.List)
 				executions.GET("/:id", executionHandler.Get)
+				executions.GET("/:id/compare", compareHandler.CompareExecutions)
+				executions.GET("/:id/export", executionHandler.Export)
 				executions.POST("/:id/cancel", executionHandler.Cancel)
+				executions.GET("/:id/context", executionHandler.ListContext)
+				executions.POST("/:id/restart", executionHandler.Restart)
 				executions.GET("/:id/logs", executionHandler.GetLogs)
+				executions.GET("/:id/stream", streamHandler.StreamExecution)
+				executions.GET("/:id/events", wsHandler.HandleConnection)
 				executions.GET("/:id/steps", executionHandler.GetSteps)
 				executions.GET("/:id/steps/:step_id", executionHandler.GetStep)
+				executions.GET("/:id/artifacts", artifactHandler.ListByExecution)
+			}
+
+			// Execution artifact routes (workspace-scoped)
+			artifactRoutes := ws.Group("/artifacts")
+			{
+				artifactRoutes.GET("/:id", artifactHandler.Get)
+				artifactRoutes.GET("/:id/download", artifactHandler.Download)
+			}
+
+			// Deterministic OpenAPI/Swagger, GraphQL and API client collection import (workspace-scoped)
+			ws.POST("/import/openapi", importExportHandler.ImportOpenAPI)
+			ws.POST("/import/graphql", importExportHandler.ImportGraphQL)
+			ws.POST("/import/collection", importExportHandler.ImportCollection)
+
+			// Test file generation from flows (workspace-scoped)
+			ws.POST("/codegen/flow", codegenHandler.GenerateFlow)
+
+			// API specs referenced by schema assertions (workspace-scoped)
+			specs := ws.Group("/specs")
+			{
+				specs.GET("", apiSpecHandler.List)
+				specs.POST("", apiSpecHandler.Save)
+				specs.GET("/:id", apiSpecHandler.Get)
+				specs.DELETE("/:id", apiSpecHandler.Delete)
+			}
+
+			// Snapshot review routes (workspace-scoped)
+			snapshotsGroup := ws.Group("/snapshots")
+			{
+				snapshotsGroup.GET("/:id", snapshotHandler.Get)
+				snapshotsGroup.POST("/:id/accept", snapshotHandler.Accept)
+				snapshotsGroup.POST("/:id/reject", snapshotHandler.Reject)
+				snapshotsGroup.DELETE("/:id", snapshotHandler.Delete)
 			}
 		}
 
@@ -340,6 +557,7 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 		{
 			reports.POST("/generate", reportingHandler.GenerateReport)
 			reports.GET("", reportingHandler.ListReports)
+			reports.GET("/formats", reportingHandler.ListFormats)
 			reports.GET("/:id", reportingHandler.GetReport)
 			reports.GET("/:id/download", reportingHandler.DownloadReport)
 			reports.DELETE("/:id", reportingHandler.DeleteReport)
@@ -466,6 +684,22 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 			loadTests.GET("/:id/timeline", loadTestHandler.GetTimeline)
 		}
 
+		// Code generation routes
+		codegenRoutes := v1.Group("/codegen")
+		{
+			codegenRoutes.POST("/generate", codegenHandler.Generate)
+			codegenRoutes.POST("/generate/all", codegenHandler.GenerateAll)
+			codegenRoutes.GET("/languages", codegenHandler.GetLanguages)
+			codegenRoutes.GET("/targets", codegenHandler.GetTargets)
+		}
+
+		// Flow JSON Schema and action schemas (editor completion, validation)
+		schemas := v1.Group("/schemas")
+		{
+			schemas.GET("/flow.json", validationHandler.FlowSchema)
+			schemas.GET("/actions", validationHandler.ListActions)
+		}
+
 		// Plugin routes
 		pluginsRoutes := v1.Group("/plugins")
 		{
@@ -486,6 +720,7 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 			schedules.GET("", scheduleHandler.List)
 			schedules.GET("/presets", scheduleHandler.GetPresets)
 			schedules.GET("/timezones", scheduleHandler.GetTimezones)
+			schedules.GET("/scheduler", scheduleHandler.GetSchedulerStatus)
 			schedules.POST("/validate-cron", scheduleHandler.ValidateCron)
 			schedules.GET("/:id", scheduleHandler.Get)
 			schedules.PUT("/:id", scheduleHandler.Update)
@@ -494,6 +729,12 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 			schedules.POST("/:id/resume", scheduleHandler.Resume)
 			schedules.POST("/:id/trigger", scheduleHandler.Trigger)
 			schedules.GET("/:id/runs", scheduleHandler.GetRuns)
+			schedules.GET("/:id/runs/:run_id", scheduleHandler.GetRun)
+			schedules.GET("/:id/runs/:run_id/junit", scheduleHandler.GetRunJUnit)
+			schedules.GET("/:id/runs/:run_id/stream", streamHandler.StreamRun)
+			schedules.GET("/:id/runs/:run_id/export", scheduleHandler.GetRunExport)
+			schedules.GET("/:id/runs/:run_id/compare", compareHandler.CompareScheduleRuns)
+			schedules.GET("/:id/targets", scheduleHandler.GetTargets)
 			schedules.GET("/:id/stats", scheduleHandler.GetStats)
 		}
 
@@ -506,6 +747,8 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 			debug.DELETE("/sessions/:id", debugHandler.EndSession)
 			debug.GET("/sessions/:id/state", debugHandler.GetState)
 			debug.GET("/sessions/:id/history", debugHandler.GetHistory)
+			debug.POST("/sessions/:id/evaluate", debugHandler.Evaluate)
+			debug.PUT("/sessions/:id/variables", debugHandler.SetVariable)
 			debug.GET("/sessions/:id/breakpoints", debugHandler.ListBreakpoints)
 			debug.POST("/sessions/:id/breakpoints", debugHandler.AddBreakpoint)
 			debug.DELETE("/sessions/:id/breakpoints/:breakpoint_id", debugHandler.RemoveBreakpoint)
@@ -564,11 +807,14 @@ func NewRouter(db *gorm.DB, logger *zap.Logger, wsHub *websocket.Hub, port int)
 
 		// Public webhook endpoint (no auth - signature verified)
 		v1.POST("/webhooks/github", webhookHandler.HandleGitHub)
+		v1.POST("/webhooks/gitlab", webhookHandler.HandleGitLab)
+		v1.POST("/webhooks/bitbucket", webhookHandler.HandleBitbucket)
+		v1.POST("/webhooks/generic", webhookHandler.HandleGeneric)
 
-	}
+		// Public status page of a workspace's monitors (no auth)
+		v1.GET("/status/:slug", monitorHandler.StatusPage)
 
-	// WebSocket routes
-	router.GET("/ws/executions/:id", wsHandler.HandleConnection)
+	}
 
 	// 404 handler
 	router.NoRoute(func(c *gin.Context) {
@@ -1,9 +1,15 @@
 package websocket
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/api/middleware"
+	"github.com/georgi-georgiev/testmesh/internal/events"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -24,31 +30,54 @@ const (
 	maxMessageSize = 512
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow all origins in development
-		// In production, you should check the origin
-		return true
-	},
-}
-
 // Handler handles WebSocket connections
 type Handler struct {
-	hub    *Hub
-	logger *zap.Logger
+	hub            *Hub
+	logger         *zap.Logger
+	allowedOrigins map[string]bool
+	upgrader       websocket.Upgrader
 }
 
-// NewHandler creates a new WebSocket handler
+// NewHandler creates a new WebSocket handler. Browsers may only connect from
+// the API's own origin until other origins are allowed.
 func NewHandler(hub *Hub, logger *zap.Logger) *Handler {
-	return &Handler{
-		hub:    hub,
-		logger: logger,
+	h := &Handler{
+		hub:            hub,
+		logger:         logger,
+		allowedOrigins: make(map[string]bool),
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// SetAllowedOrigins sets the browser origins, such as the dashboard's, that
+// may open WebSockets besides the API's own
+func (h *Handler) SetAllowedOrigins(origins []string) {
+	h.allowedOrigins = make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		h.allowedOrigins[strings.TrimRight(origin, "/")] = true
 	}
 }
 
-// HandleConnection handles WebSocket connection requests
+// checkOrigin accepts clients that send no Origin, such as the CLI, the
+// API's own origin and the allowed origins
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || h.allowedOrigins[origin] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// HandleConnection handles GET /api/v1/workspaces/:workspace_id/executions/:id/events,
+// a WebSocket of the events of an execution of the workspace. Events of the
+// execution so far are replayed first; with ?since=<seq>, only those after
+// that sequence number.
 func (h *Handler) HandleConnection(c *gin.Context) {
 	// Get execution ID from URL parameter
 	executionIDStr := c.Param("id")
@@ -57,9 +86,75 @@ func (h *Handler) HandleConnection(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
 		return
 	}
+	if h.hub.WorkspaceOf(executionID) != middleware.GetWorkspaceID(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	since, err := parseSince(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.serve(c, events.Filter{ExecutionID: executionID, Replay: true, Since: since})
+}
+
+// HandleWorkspaceConnection handles GET /api/v1/workspaces/:workspace_id/events,
+// a WebSocket of the events of every execution in the workspace. With
+// ?execution_id=<id>, only the events of that execution are sent, and its
+// events so far are replayed first; ?since=<seq> skips those up to that
+// sequence number. ?replay=true replays the events of the recent executions
+// of the workspace as well. Sequence numbers are per execution, so clients of
+// the whole stream resume with a ?cursor=<execution_id>:<seq> per execution
+// they have seen, which replays the events after each cursor and every event
+// of other executions.
+func (h *Handler) HandleWorkspaceConnection(c *gin.Context) {
+	workspaceID := middleware.GetWorkspaceID(c)
+	filter := events.Filter{WorkspaceID: workspaceID, Replay: c.Query("replay") == "true"}
+
+	if id := c.Query("execution_id"); id != "" {
+		executionID, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+			return
+		}
+		if h.hub.WorkspaceOf(executionID) != workspaceID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		filter.ExecutionID = executionID
+		filter.Replay = true
+	}
+
+	since, err := parseSince(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if since > 0 && filter.ExecutionID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since needs execution_id; resume the workspace stream with a cursor per execution"})
+		return
+	}
+	filter.Since = since
+
+	cursors, err := parseCursors(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(cursors) > 0 {
+		filter.Cursors = cursors
+		filter.Replay = true
+	}
+
+	h.serve(c, filter)
+}
 
+// serve upgrades the connection and streams the events passing a filter
+func (h *Handler) serve(c *gin.Context, filter events.Filter) {
 	// Upgrade HTTP connection to WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		h.logger.Error("Failed to upgrade WebSocket connection", zap.Error(err))
 		return
@@ -67,25 +162,36 @@ func (h *Handler) HandleConnection(c *gin.Context) {
 
 	// Create new client
 	client := &Client{
-		ID:          uuid.New().String(),
-		ExecutionID: executionID,
-		Send:        make(chan *Event, 256),
-		hub:         h.hub,
-		unregister:  h.hub.unregister,
+		ID:     uuid.New().String(),
+		Filter: filter,
+		hub:    h.hub,
 	}
 
-	// Register client with hub
-	h.hub.register <- client
+	// Subscribe client to the event bus
+	h.hub.subscribe(client)
 
 	// Start goroutines for reading and writing
 	go client.writePump(conn, h.logger)
 	go client.readPump(conn, h.logger)
 }
 
-// readPump pumps messages from the WebSocket connection to the hub
+// parseSince reads the sequence number of ?since=, 0 when absent
+func parseSince(c *gin.Context) (uint64, error) {
+	value := c.Query("since")
+	if value == "" {
+		return 0, nil
+	}
+	since, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid since %q", value)
+	}
+	return since, nil
+}
+
+// readPump reads from the WebSocket connection until it closes
 func (c *Client) readPump(conn *websocket.Conn, logger *zap.Logger) {
 	defer func() {
-		c.unregister <- c
+		c.hub.unsubscribe(c)
 		conn.Close()
 	}()
 
@@ -118,10 +224,11 @@ func (c *Client) writePump(conn *websocket.Conn, logger *zap.Logger) {
 
 	for {
 		select {
-		case event, ok := <-c.Send:
+		case event, ok := <-c.sub.Events:
 			conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				// Hub closed the channel
+				// The subscription ended, or the client fell behind and should
+				// reconnect with ?since=<last seq>
 				conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
@@ -147,3 +254,26 @@ func (c *Client) writePump(conn *websocket.Conn, logger *zap.Logger) {
 		}
 	}
 }
+
+// parseCursors reads the ?cursor=<execution_id>:<seq> positions of a workspace
+// stream
+func parseCursors(c *gin.Context) (map[uuid.UUID]uint64, error) {
+	values := c.QueryArray("cursor")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	cursors := make(map[uuid.UUID]uint64, len(values))
+	for _, value := range values {
+		id, seq, ok := strings.Cut(value, ":")
+		executionID, err := uuid.Parse(id)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid cursor %q, expected <execution_id>:<seq>", value)
+		}
+		n, err := strconv.ParseUint(seq, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q, expected <execution_id>:<seq>", value)
+		}
+		cursors[executionID] = n
+	}
+	return cursors, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"sync"
 
+	"github.com/georgi-georgiev/testmesh/internal/events"
 	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -31,35 +32,32 @@ const (
 	EventDebugSessionEnded   EventType = "debug.session.ended"
 )
 
-// Event represents a WebSocket event
-type Event struct {
-	Type        EventType              `json:"type"`
-	ExecutionID uuid.UUID              `json:"execution_id"`
-	Data        map[string]interface{} `json:"data"`
-}
+// Event is an event as sent to WebSocket clients
+type Event = events.Event
+
+// WorkspaceResolver returns the workspace of an execution
+type WorkspaceResolver func(executionID uuid.UUID) (uuid.UUID, error)
 
 // Client represents a WebSocket client connection
 type Client struct {
-	ID           string
-	ExecutionID  uuid.UUID
-	Send         chan *Event
-	hub          *Hub
-	unregister   chan *Client
+	ID     string
+	Filter events.Filter
+	sub    *events.Subscription
+	hub    *Hub
 }
 
-// Hub manages WebSocket connections and broadcasts
+// Hub publishes execution and debug events to the event bus and tracks the
+// WebSocket clients subscribed to it. With a shared bus, clients receive the
+// events of executions running on any server.
 type Hub struct {
-	// Registered clients per execution ID
-	clients map[uuid.UUID]map[*Client]bool
-
-	// Register requests from clients
-	register chan *Client
+	bus      events.Bus
+	resolver WorkspaceResolver
 
-	// Unregister requests from clients
-	unregister chan *Client
+	// Registered clients
+	clients map[*Client]bool
 
-	// Broadcast events to clients
-	broadcast chan *Event
+	// Workspaces of running executions, resolved once per execution
+	workspaces map[uuid.UUID]uuid.UUID
 
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
@@ -68,164 +66,164 @@ type Hub struct {
 	logger *zap.Logger
 }
 
-// NewHub creates a new WebSocket hub
-func NewHub(logger *zap.Logger) *Hub {
+// NewHub creates a new WebSocket hub publishing to a bus
+func NewHub(bus events.Bus, logger *zap.Logger) *Hub {
 	return &Hub{
-		clients:    make(map[uuid.UUID]map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *Event, 256),
+		bus:        bus,
+		clients:    make(map[*Client]bool),
+		workspaces: make(map[uuid.UUID]uuid.UUID),
 		logger:     logger,
 	}
 }
 
-// Run starts the hub's main loop
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.registerClient(client)
-
-		case client := <-h.unregister:
-			h.unregisterClient(client)
-
-		case event := <-h.broadcast:
-			h.broadcastEvent(event)
-		}
-	}
+// Bus returns the event bus the hub publishes to
+func (h *Hub) Bus() events.Bus {
+	return h.bus
 }
 
-// registerClient registers a new client
-func (h *Hub) registerClient(client *Client) {
+// SetWorkspaceResolver sets how the workspace of an execution is found, so
+// that clients can subscribe to the events of a whole workspace
+func (h *Hub) SetWorkspaceResolver(resolver WorkspaceResolver) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.resolver = resolver
+}
 
-	if h.clients[client.ExecutionID] == nil {
-		h.clients[client.ExecutionID] = make(map[*Client]bool)
+// WorkspaceOf returns the workspace of an execution, or uuid.Nil when it is
+// unknown
+func (h *Hub) WorkspaceOf(executionID uuid.UUID) uuid.UUID {
+	h.mu.RLock()
+	workspaceID, ok := h.workspaces[executionID]
+	resolver := h.resolver
+	h.mu.RUnlock()
+	if ok || resolver == nil {
+		return workspaceID
+	}
+
+	workspaceID, err := resolver(executionID)
+	if err != nil {
+		h.logger.Debug("Failed to resolve workspace of execution",
+			zap.String("execution_id", executionID.String()),
+			zap.Error(err),
+		)
+		return uuid.Nil
 	}
+	return workspaceID
+}
 
-	h.clients[client.ExecutionID][client] = true
+// subscribe registers a client and subscribes it to the bus
+func (h *Hub) subscribe(client *Client) {
+	client.sub = h.bus.Subscribe(client.Filter)
+
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
 
 	h.logger.Info("WebSocket client registered",
 		zap.String("client_id", client.ID),
-		zap.String("execution_id", client.ExecutionID.String()),
+		zap.String("workspace_id", client.Filter.WorkspaceID.String()),
+		zap.String("execution_id", client.Filter.ExecutionID.String()),
 	)
 }
 
-// unregisterClient unregisters a client
-func (h *Hub) unregisterClient(client *Client) {
+// unsubscribe unregisters a client and ends its subscription
+func (h *Hub) unsubscribe(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	if clients, ok := h.clients[client.ExecutionID]; ok {
-		if _, exists := clients[client]; exists {
-			delete(clients, client)
-			close(client.Send)
+	_, exists := h.clients[client]
+	delete(h.clients, client)
+	h.mu.Unlock()
 
-			// Clean up empty execution maps
-			if len(clients) == 0 {
-				delete(h.clients, client.ExecutionID)
-			}
-
-			h.logger.Info("WebSocket client unregistered",
-				zap.String("client_id", client.ID),
-				zap.String("execution_id", client.ExecutionID.String()),
-			)
-		}
+	if !exists {
+		return
 	}
+	client.sub.Close()
+
+	h.logger.Info("WebSocket client unregistered",
+		zap.String("client_id", client.ID),
+		zap.String("execution_id", client.Filter.ExecutionID.String()),
+	)
 }
 
-// broadcastEvent sends an event to all clients watching the execution
-func (h *Hub) broadcastEvent(event *Event) {
-	h.mu.RLock()
-	clients := h.clients[event.ExecutionID]
-	h.mu.RUnlock()
+// publish sends an event of an execution to the bus
+func (h *Hub) publish(eventType EventType, executionID uuid.UUID, data map[string]interface{}) {
+	workspaceID := h.WorkspaceOf(executionID)
 
-	if len(clients) == 0 {
-		return
+	event := &Event{
+		Type:        string(eventType),
+		WorkspaceID: workspaceID,
+		ExecutionID: executionID,
+		Data:        data,
 	}
 
-	h.logger.Debug("Broadcasting event",
-		zap.String("type", string(event.Type)),
-		zap.String("execution_id", event.ExecutionID.String()),
-		zap.Int("clients", len(clients)),
-	)
-
-	for client := range clients {
-		select {
-		case client.Send <- event:
-			// Event sent successfully
-		default:
-			// Client's send channel is full, unregister it
-			h.unregisterClient(client)
-		}
+	h.mu.Lock()
+	if event.Type == string(EventExecutionCompleted) || event.Type == string(EventExecutionFailed) {
+		delete(h.workspaces, executionID)
+	} else {
+		h.workspaces[executionID] = workspaceID
+	}
+	h.mu.Unlock()
+
+	if err := h.bus.Publish(event); err != nil {
+		h.logger.Warn("Failed to publish event",
+			zap.String("type", event.Type),
+			zap.String("execution_id", executionID.String()),
+			zap.Error(err),
+		)
 	}
 }
 
 // BroadcastExecutionStarted broadcasts execution started event
 func (h *Hub) BroadcastExecutionStarted(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventExecutionStarted,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventExecutionStarted, executionID, data)
 }
 
 // BroadcastExecutionCompleted broadcasts execution completed event
 func (h *Hub) BroadcastExecutionCompleted(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventExecutionCompleted,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventExecutionCompleted, executionID, data)
 }
 
 // BroadcastExecutionFailed broadcasts execution failed event
 func (h *Hub) BroadcastExecutionFailed(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventExecutionFailed,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventExecutionFailed, executionID, data)
 }
 
 // BroadcastStepStarted broadcasts step started event
 func (h *Hub) BroadcastStepStarted(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventStepStarted,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventStepStarted, executionID, data)
 }
 
 // BroadcastStepCompleted broadcasts step completed event
 func (h *Hub) BroadcastStepCompleted(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventStepCompleted,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventStepCompleted, executionID, data)
 }
 
 // BroadcastStepFailed broadcasts step failed event
 func (h *Hub) BroadcastStepFailed(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventStepFailed,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventStepFailed, executionID, data)
 }
 
-// GetClientCount returns the number of connected clients for an execution
+// GetClientCount returns the number of clients of this server subscribed to
+// an execution, by its ID or its workspace
 func (h *Hub) GetClientCount(executionID uuid.UUID) int {
+	event := &Event{ExecutionID: executionID, WorkspaceID: h.WorkspaceOf(executionID)}
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if clients, ok := h.clients[executionID]; ok {
-		return len(clients)
+	count := 0
+	for client := range h.clients {
+		if client.Filter.Matches(event) {
+			count++
+		}
 	}
+	return count
+}
 
-	return 0
+// GetTotalClientCount returns the number of clients connected to this server
+func (h *Hub) GetTotalClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
 }
 
 // MarshalEvent marshals an event to JSON
@@ -261,27 +259,15 @@ func (h *Hub) BroadcastDebugEvent(executionID uuid.UUID, event *debugger.DebugEv
 	data["session_id"] = event.SessionID.String()
 	data["timestamp"] = event.Timestamp
 
-	h.broadcast <- &Event{
-		Type:        eventType,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(eventType, executionID, data)
 }
 
 // BroadcastDebugSessionStarted broadcasts when a debug session starts
 func (h *Hub) BroadcastDebugSessionStarted(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventDebugSessionStarted,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventDebugSessionStarted, executionID, data)
 }
 
 // BroadcastDebugSessionEnded broadcasts when a debug session ends
 func (h *Hub) BroadcastDebugSessionEnded(executionID uuid.UUID, data map[string]interface{}) {
-	h.broadcast <- &Event{
-		Type:        EventDebugSessionEnded,
-		ExecutionID: executionID,
-		Data:        data,
-	}
+	h.publish(EventDebugSessionEnded, executionID, data)
 }
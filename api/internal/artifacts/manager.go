@@ -0,0 +1,114 @@
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sync"
+
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Manager stores artifacts, offloads step outputs and removes expired artifacts
+type Manager struct {
+	store         Store
+	repo          *repository.ArtifactRepository
+	workspaceRepo *repository.WorkspaceRepository
+	cfg           config.ArtifactsConfig
+	logger        *zap.Logger
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewManager creates an artifact manager
+func NewManager(
+	store Store,
+	repo *repository.ArtifactRepository,
+	workspaceRepo *repository.WorkspaceRepository,
+	cfg config.ArtifactsConfig,
+	logger *zap.Logger,
+) *Manager {
+	return &Manager{
+		store:         store,
+		repo:          repo,
+		workspaceRepo: workspaceRepo,
+		cfg:           cfg,
+		logger:        logger,
+	}
+}
+
+// Save stores the content of an artifact and records it. The artifact's
+// execution, kind, name and content type must be set.
+func (m *Manager) Save(ctx context.Context, artifact *models.Artifact, data []byte) error {
+	if artifact.ID == uuid.Nil {
+		artifact.ID = uuid.New()
+	}
+	sum := sha256.Sum256(data)
+	artifact.Size = int64(len(data))
+	artifact.Checksum = hex.EncodeToString(sum[:])
+	artifact.Backend = m.store.Backend()
+	artifact.StorageKey = storageKey(artifact)
+
+	if err := m.store.Put(ctx, artifact.StorageKey, data, artifact.ContentType); err != nil {
+		return fmt.Errorf("failed to store artifact: %w", err)
+	}
+	if err := m.repo.Create(artifact); err != nil {
+		m.store.Delete(ctx, artifact.StorageKey)
+		return fmt.Errorf("failed to record artifact: %w", err)
+	}
+	return nil
+}
+
+// Open opens the content of an artifact
+func (m *Manager) Open(ctx context.Context, artifact *models.Artifact) (io.ReadCloser, error) {
+	if artifact.Backend != m.store.Backend() {
+		return nil, fmt.Errorf("artifact is stored in the %s backend, but the server uses %s", artifact.Backend, m.store.Backend())
+	}
+	return m.store.Open(ctx, artifact.StorageKey)
+}
+
+// Read reads the content of an artifact of at most limit bytes. Larger
+// artifacts return nil, so that reports link to them instead of embedding them.
+func (m *Manager) Read(ctx context.Context, artifact *models.Artifact, limit int64) ([]byte, error) {
+	if artifact.Size > limit {
+		return nil, nil
+	}
+	r, err := m.Open(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, limit))
+}
+
+// ListByExecutions lists the artifacts of several executions, grouped by execution ID
+func (m *Manager) ListByExecutions(executionIDs []uuid.UUID) (map[uuid.UUID][]models.Artifact, error) {
+	return m.repo.ListByExecutions(executionIDs)
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// storageKey returns the key of an artifact in the store:
+// <workspace>/<execution>/<artifact>/<name>
+func storageKey(artifact *models.Artifact) string {
+	workspace := "shared"
+	if artifact.WorkspaceID != nil {
+		workspace = artifact.WorkspaceID.String()
+	}
+	name := unsafeNameChars.ReplaceAllString(artifact.Name, "_")
+	if name == "" || name == "." || name == ".." {
+		name = "artifact"
+	}
+	return path.Join(workspace, artifact.ExecutionID.String(), artifact.ID.String(), name)
+}
@@ -0,0 +1,269 @@
+package artifacts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Output keys with special handling
+const (
+	// ScreenshotKey holds a base64 encoded screenshot, as returned by browser actions
+	ScreenshotKey = "screenshot"
+	// FilesKey holds files attached by actions and plugins, as a list of
+	// {"name": "trace.har", "content_type": "application/json", "content": "...", "encoding": "base64"}
+	FilesKey = "artifacts"
+)
+
+// Offload stores the screenshot, attached files and large values of a step's
+// output as artifacts, and returns the output to persist with references in
+// their place. The output itself is not modified, so later steps still see
+// the full values. Values that fail to store are kept inline.
+func (m *Manager) Offload(ctx context.Context, workspaceID *uuid.UUID, step *models.ExecutionStep, output models.OutputData) models.OutputData {
+	if len(output) == 0 {
+		return output
+	}
+
+	persisted := make(models.OutputData, len(output))
+	keys := make([]string, 0, len(output))
+	for key, value := range output {
+		persisted[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	newArtifact := func(kind models.ArtifactKind, name, contentType string) *models.Artifact {
+		stepID := step.ID
+		return &models.Artifact{
+			WorkspaceID:     workspaceID,
+			ExecutionID:     step.ExecutionID,
+			ExecutionStepID: &stepID,
+			StepID:          step.StepID,
+			Kind:            kind,
+			Name:            name,
+			ContentType:     contentType,
+		}
+	}
+
+	for _, key := range keys {
+		value := output[key]
+
+		switch key {
+		case ScreenshotKey:
+			encoded, ok := value.(string)
+			if !ok || encoded == "" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				continue
+			}
+			contentType := http.DetectContentType(data)
+			artifact := newArtifact(models.ArtifactKindScreenshot, "screenshot"+extension(contentType), contentType)
+			if m.save(ctx, artifact, data) {
+				persisted[key] = artifact.ArtifactRef()
+			}
+
+		case FilesKey:
+			var files []interface{}
+			switch list := value.(type) {
+			case []interface{}:
+				files = list
+			case []map[string]interface{}: // Returned directly by in-process actions
+				for _, file := range list {
+					files = append(files, file)
+				}
+			default:
+				continue
+			}
+			refs := make([]interface{}, len(files))
+			for i, file := range files {
+				refs[i] = file
+				name, contentType, data, err := decodeFile(file)
+				if err != nil {
+					m.logger.Warn("Invalid step artifact",
+						zap.String("step_id", step.StepID), zap.Int("index", i), zap.Error(err))
+					continue
+				}
+				artifact := newArtifact(models.ArtifactKindFile, name, contentType)
+				if m.save(ctx, artifact, data) {
+					refs[i] = artifact.ArtifactRef()
+				}
+			}
+			persisted[key] = refs
+
+		default:
+			if m.cfg.OffloadThreshold <= 0 {
+				continue
+			}
+			data, contentType := encodeValue(value)
+			if len(data) <= m.cfg.OffloadThreshold {
+				continue
+			}
+			artifact := newArtifact(models.ArtifactKindOutput, key+extension(contentType), contentType)
+			if m.save(ctx, artifact, data) {
+				persisted[key] = artifact.ArtifactRef()
+			}
+		}
+	}
+
+	return persisted
+}
+
+// save stores an artifact, logging failures
+func (m *Manager) save(ctx context.Context, artifact *models.Artifact, data []byte) bool {
+	if err := m.Save(ctx, artifact, data); err != nil {
+		m.logger.Warn("Failed to offload step artifact",
+			zap.String("execution_id", artifact.ExecutionID.String()),
+			zap.String("step_id", artifact.StepID),
+			zap.String("name", artifact.Name),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// decodeFile decodes a file attached under the artifacts key of an output
+func decodeFile(file interface{}) (name, contentType string, data []byte, err error) {
+	entry, ok := file.(map[string]interface{})
+	if !ok {
+		return "", "", nil, fmt.Errorf("expected an object")
+	}
+	name, _ = entry["name"].(string)
+	if name == "" {
+		return "", "", nil, fmt.Errorf("name is required")
+	}
+
+	switch content := entry["content"].(type) {
+	case string:
+		data = []byte(content)
+		if encoding, _ := entry["encoding"].(string); encoding == "base64" {
+			if data, err = base64.StdEncoding.DecodeString(content); err != nil {
+				return "", "", nil, fmt.Errorf("invalid base64 content: %w", err)
+			}
+		}
+	case nil:
+		return "", "", nil, fmt.Errorf("content is required")
+	default:
+		if data, err = json.MarshalIndent(content, "", "  "); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	contentType, _ = entry["content_type"].(string)
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return name, contentType, data, nil
+}
+
+// encodeValue encodes an output value for storage: strings as text, anything
+// else as JSON
+func encodeValue(value interface{}) ([]byte, string) {
+	if s, ok := value.(string); ok {
+		contentType := http.DetectContentType([]byte(s))
+		if trimmed := strings.TrimSpace(s); json.Valid([]byte(trimmed)) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+			contentType = "application/json"
+		}
+		return []byte(s), contentType
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, ""
+	}
+	return data, "application/json"
+}
+
+// extension returns the file extension of a content type
+func extension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return ".json"
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "text/html":
+		return ".html"
+	case "text/plain":
+		return ".txt"
+	case "text/xml", "application/xml":
+		return ".xml"
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// ResolveOutput returns a copy of a persisted step output with references to
+// offloaded values replaced by the stored values: JSON is decoded and other
+// content is returned as text. References to screenshots and attached files,
+// to artifacts larger than limit and to artifacts that cannot be read are left
+// in place; use IsRef to tell them apart.
+func (m *Manager) ResolveOutput(ctx context.Context, output models.OutputData, limit int64) models.OutputData {
+	if len(output) == 0 {
+		return output
+	}
+
+	resolved := make(models.OutputData, len(output))
+	for key, value := range output {
+		resolved[key] = value
+		id, ok := refID(value)
+		if !ok {
+			continue
+		}
+		artifact, err := m.repo.GetByID(id)
+		if err != nil || artifact.Kind != models.ArtifactKindOutput {
+			continue
+		}
+		data, err := m.Read(ctx, artifact, limit)
+		if err != nil || data == nil {
+			continue
+		}
+		mediaType, _, _ := mime.ParseMediaType(artifact.ContentType)
+		if mediaType == "application/json" {
+			var decoded interface{}
+			if err := json.Unmarshal(data, &decoded); err == nil {
+				resolved[key] = decoded
+				continue
+			}
+		}
+		resolved[key] = string(data)
+	}
+	return resolved
+}
+
+// IsRef reports whether an output value is a reference to an artifact
+func IsRef(value interface{}) bool {
+	_, ok := refID(value)
+	return ok
+}
+
+// refID returns the artifact ID of a reference
+func refID(value interface{}) (uuid.UUID, bool) {
+	ref, ok := value.(map[string]interface{})
+	if !ok {
+		return uuid.Nil, false
+	}
+	s, ok := ref[models.ArtifactRefKey].(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(s)
+	return id, err == nil
+}
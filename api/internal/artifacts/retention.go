@@ -0,0 +1,147 @@
+package artifacts
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// cleanupBatch is the number of artifacts removed per query
+const cleanupBatch = 500
+
+// RetentionDays returns how many days the artifacts of a workspace are kept.
+// Zero keeps them forever.
+func (m *Manager) RetentionDays(workspaceID uuid.UUID) int {
+	if workspace, err := m.workspaceRepo.GetByID(workspaceID); err == nil && workspace.Settings.Artifacts.RetentionDays > 0 {
+		return workspace.Settings.Artifacts.RetentionDays
+	}
+	return m.cfg.RetentionDays
+}
+
+// Start starts removing expired artifacts every cleanup interval
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running || m.cfg.CleanupInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	m.running = true
+
+	go m.loop(ctx)
+	m.logger.Info("Artifact retention started", zap.Duration("interval", m.cfg.CleanupInterval))
+}
+
+// Stop stops the retention loop and waits for it to exit
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	m.cancel()
+	<-m.done
+}
+
+func (m *Manager) loop(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if removed, err := m.Cleanup(ctx); err != nil {
+				m.logger.Error("Failed to remove expired artifacts", zap.Error(err))
+			} else if removed > 0 {
+				m.logger.Info("Removed expired artifacts", zap.Int("count", removed))
+			}
+		}
+	}
+}
+
+// Cleanup removes the artifacts older than the retention of their workspace
+// and the artifacts of deleted executions. It returns how many were removed.
+func (m *Manager) Cleanup(ctx context.Context) (int, error) {
+	removed := 0
+
+	workspaceIDs, err := m.repo.WorkspaceIDs()
+	if err != nil {
+		return 0, err
+	}
+	for i := range workspaceIDs {
+		days := m.RetentionDays(workspaceIDs[i])
+		if days <= 0 {
+			continue
+		}
+		n, err := m.removeAll(ctx, func() ([]models.Artifact, error) {
+			return m.repo.ListExpired(&workspaceIDs[i], time.Now().AddDate(0, 0, -days), cleanupBatch)
+		})
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	if m.cfg.RetentionDays > 0 {
+		n, err := m.removeAll(ctx, func() ([]models.Artifact, error) {
+			return m.repo.ListExpired(nil, time.Now().AddDate(0, 0, -m.cfg.RetentionDays), cleanupBatch)
+		})
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	n, err := m.removeAll(ctx, func() ([]models.Artifact, error) {
+		return m.repo.ListOrphaned(cleanupBatch)
+	})
+	return removed + n, err
+}
+
+// removeAll removes batches of artifacts until list returns none. Artifacts
+// whose content cannot be deleted are kept for the next cleanup.
+func (m *Manager) removeAll(ctx context.Context, list func() ([]models.Artifact, error)) (int, error) {
+	removed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		artifacts, err := list()
+		if err != nil || len(artifacts) == 0 {
+			return removed, err
+		}
+
+		ids := make([]uuid.UUID, 0, len(artifacts))
+		for _, artifact := range artifacts {
+			if artifact.Backend == m.store.Backend() {
+				if err := m.store.Delete(ctx, artifact.StorageKey); err != nil {
+					m.logger.Warn("Failed to delete artifact content",
+						zap.String("artifact_id", artifact.ID.String()), zap.Error(err))
+					continue
+				}
+			}
+			ids = append(ids, artifact.ID)
+		}
+		if len(ids) == 0 {
+			// Every deletion failed; stop rather than listing the same batch again
+			return removed, nil
+		}
+		if err := m.repo.Delete(ids); err != nil {
+			return removed, err
+		}
+		removed += len(ids)
+	}
+}
@@ -0,0 +1,44 @@
+// Package artifacts keeps the files produced by executions, such as large
+// response bodies, screenshots and files attached by actions and plugins,
+// outside of the database. Artifacts live in a filesystem directory or an
+// S3-compatible bucket and are recorded in executions.artifacts.
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+)
+
+// Storage backends
+const (
+	BackendFilesystem = "filesystem"
+	BackendS3         = "s3"
+)
+
+// ErrNotFound is returned when a stored artifact does not exist
+var ErrNotFound = errors.New("artifact not found")
+
+// Store keeps artifact contents by key
+type Store interface {
+	// Backend returns the name of the backend, recorded with every artifact
+	Backend() string
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore creates the store selected by the configuration
+func NewStore(cfg config.ArtifactsConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendFilesystem:
+		return NewFilesystemStore(cfg.Path)
+	case BackendS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown artifact backend %q (expected filesystem or s3)", cfg.Backend)
+	}
+}
@@ -0,0 +1,90 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+)
+
+// Tests of API clients, like Postman, Bruno and Insomnia, are JavaScript
+// with chai's expect
+
+// chaiMatchers are the assertions of ordering operators
+var chaiMatchers = map[string]string{
+	"<": "to.be.below", "<=": "to.be.at.most", ">": "to.be.above", ">=": "to.be.at.least",
+}
+
+// helperCallPattern finds calls of the helpers of translated assertions
+var helperCallPattern = regexp.MustCompile(`(?:^|[^.\w])(equal|has|len)\(`)
+
+// ChaiAssertion translates an assertion of a step to a chai statement over
+// root, an object like the result of an http_request step. expect is the
+// expect function of the client, like pm.expect. It returns the helpers the
+// statement calls, whose code JSHelpers returns.
+func ChaiAssertion(source, root, expect string) (string, []string, error) {
+	tree, err := parser.Parse(source)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkAssertion(tree.Node); err != nil {
+		return "", nil, err
+	}
+
+	statement := chaiExpect(tree.Node, root, expect)
+	var helpers []string
+	seen := make(map[string]bool)
+	for _, m := range helperCallPattern.FindAllStringSubmatch(statement, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			helpers = append(helpers, m[1])
+		}
+	}
+	return statement, helpers, nil
+}
+
+// chaiExpect translates an assertion to an expect statement, with the
+// assertion of its operator where chai has one
+func chaiExpect(node ast.Node, root, expect string) string {
+	negated := false
+	target := node
+	if u, ok := node.(*ast.UnaryNode); ok && u.Operator != "-" {
+		negated, target = true, u.Node
+	}
+
+	if b, ok := target.(*ast.BinaryNode); ok {
+		left, right := jsExpr(b.Left, root), jsExpr(b.Right, root)
+		to := "to"
+		if negated {
+			to = "to.not"
+		}
+		switch b.Operator {
+		case "==", "!=":
+			if b.Operator == "!=" {
+				if negated {
+					to = "to"
+				} else {
+					to = "to.not"
+				}
+			}
+			if _, ok := b.Right.(*ast.NilNode); ok {
+				return fmt.Sprintf("%s(%s ?? null).%s.be.null;", expect, left, to)
+			}
+			return fmt.Sprintf("%s(%s).%s.eql(%s);", expect, left, to, right)
+		case "<", "<=", ">", ">=":
+			if !negated {
+				return fmt.Sprintf("%s(%s).%s(%s);", expect, left, chaiMatchers[b.Operator], right)
+			}
+		case "contains":
+			return fmt.Sprintf("%s(%s).%s.include(%s);", expect, left, to, right)
+		case "in":
+			if _, ok := b.Right.(*ast.ArrayNode); ok {
+				return fmt.Sprintf("%s(%s).%s.deep.include(%s);", expect, right, to, left)
+			}
+		case "matches":
+			return fmt.Sprintf("%s(%s).%s.match(new RegExp(%s));", expect, left, to, right)
+		}
+	}
+	return fmt.Sprintf("%s(%s).to.be.true;", expect, jsExpr(node, root))
+}
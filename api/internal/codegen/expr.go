@@ -0,0 +1,444 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+)
+
+// Assertions are expr expressions over the fields of a step result, like
+// status, body and headers. The translators below cover the operators
+// assertions use; checkAssertion rejects the rest before translation.
+
+// binaryOperators are the binary operators assertions are translated with
+var binaryOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true,
+	"and": true, "&&": true, "or": true, "||": true,
+	"in": true, "contains": true, "startsWith": true, "endsWith": true, "matches": true,
+	"+": true, "-": true, "*": true, "/": true, "??": true,
+}
+
+// builtinFunctions are the expr built-in functions assertions are
+// translated with
+var builtinFunctions = map[string]bool{"len": true, "lower": true, "upper": true}
+
+var jsIdentPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// checkAssertion returns an error for expressions the translators do not
+// cover
+func checkAssertion(node ast.Node) error {
+	switch n := node.(type) {
+	case *ast.NilNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.StringNode:
+		return nil
+	case *ast.IdentifierNode:
+		if n.Value == "response" {
+			return fmt.Errorf("response is only supported as response.body")
+		}
+		return nil
+	case *ast.UnaryNode:
+		if n.Operator != "not" && n.Operator != "!" && n.Operator != "-" {
+			return fmt.Errorf("operator %s is not supported", n.Operator)
+		}
+		return checkAssertion(n.Node)
+	case *ast.BinaryNode:
+		if !binaryOperators[n.Operator] {
+			return fmt.Errorf("operator %s is not supported", n.Operator)
+		}
+		if err := checkAssertion(n.Left); err != nil {
+			return err
+		}
+		return checkAssertion(n.Right)
+	case *ast.MemberNode:
+		if n.Optional || n.Method {
+			return fmt.Errorf("optional chaining and methods are not supported")
+		}
+		if isResponseBody(n) {
+			return nil
+		}
+		if err := checkAssertion(n.Node); err != nil {
+			return err
+		}
+		return checkAssertion(n.Property)
+	case *ast.BuiltinNode:
+		if !builtinFunctions[n.Name] || len(n.Arguments) != 1 {
+			return fmt.Errorf("%s() is not supported", n.Name)
+		}
+		return checkAssertion(n.Arguments[0])
+	case *ast.ArrayNode:
+		for _, item := range n.Nodes {
+			if err := checkAssertion(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.ChainNode:
+		return fmt.Errorf("optional chaining is not supported")
+	case *ast.ConditionalNode:
+		return fmt.Errorf("conditionals are not supported")
+	case *ast.CallNode:
+		return fmt.Errorf("function calls are not supported")
+	}
+	return fmt.Errorf("the expression is not supported")
+}
+
+// uses reports whether an assertion of a suite uses an operator
+func (s *Suite) uses(operator string) bool {
+	found := false
+	for _, phase := range [][]SuiteStep{s.Setup, s.Steps, s.Teardown} {
+		for _, step := range phase {
+			for _, a := range step.Assertions {
+				if a.Node == nil {
+					continue
+				}
+				ast.Find(a.Node, func(node ast.Node) bool {
+					if n, ok := node.(*ast.BinaryNode); ok && n.Operator == operator {
+						found = true
+					}
+					return found
+				})
+			}
+		}
+	}
+	return found
+}
+
+// isResponseBody reports whether a node is response.body, the alias of body
+func isResponseBody(node ast.Node) bool {
+	m, ok := node.(*ast.MemberNode)
+	if !ok {
+		return false
+	}
+	ident, ok := m.Node.(*ast.IdentifierNode)
+	prop, isString := m.Property.(*ast.StringNode)
+	return ok && ident.Value == "response" && isString && prop.Value == "body"
+}
+
+// field returns the result field a node reads, for identifiers and
+// response.body
+func field(node ast.Node) (string, bool) {
+	if isResponseBody(node) {
+		return "body", true
+	}
+	if ident, ok := node.(*ast.IdentifierNode); ok {
+		return ident.Value, true
+	}
+	return "", false
+}
+
+// isComparison reports whether an operator compares two values
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return true
+	}
+	return false
+}
+
+// isLiteral reports whether a node is a string, number or boolean literal
+func isLiteral(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.StringNode:
+		return true
+	}
+	return false
+}
+
+// paren wraps the code of operators in parentheses
+func paren(node ast.Node, code string) string {
+	switch node.(type) {
+	case *ast.BinaryNode, *ast.UnaryNode:
+		return "(" + code + ")"
+	}
+	return code
+}
+
+func formatFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// jsonString quotes a string for Python and JavaScript
+func jsonString(s string) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// goExpr translates an assertion to a Go expression over the map root. The
+// values of results are untyped, so comparisons use helpers of the
+// generated file.
+func goExpr(node ast.Node, root string) string {
+	switch n := node.(type) {
+	case *ast.NilNode:
+		return "nil"
+	case *ast.IntegerNode:
+		return strconv.Itoa(n.Value)
+	case *ast.FloatNode:
+		return formatFloat(n.Value)
+	case *ast.BoolNode:
+		return strconv.FormatBool(n.Value)
+	case *ast.StringNode:
+		return strconv.Quote(n.Value)
+	case *ast.IdentifierNode:
+		return fmt.Sprintf("dig(%s, %s)", root, strconv.Quote(n.Value))
+	case *ast.MemberNode:
+		// Collapse a.b[0].c into one dig call
+		var path []string
+		var base ast.Node = n
+		for {
+			m, ok := base.(*ast.MemberNode)
+			if !ok || isResponseBody(m) {
+				break
+			}
+			path = append([]string{goExpr(m.Property, root)}, path...)
+			base = m.Node
+		}
+		if name, ok := field(base); ok {
+			return fmt.Sprintf("dig(%s)", strings.Join(append([]string{root, strconv.Quote(name)}, path...), ", "))
+		}
+		return fmt.Sprintf("dig(%s)", strings.Join(append([]string{goExpr(base, root)}, path...), ", "))
+	case *ast.UnaryNode:
+		if n.Operator == "-" {
+			return "-num(" + goExpr(n.Node, root) + ")"
+		}
+		return "!" + paren(n.Node, goCond(n.Node, root))
+	case *ast.BinaryNode:
+		left, right := goExpr(n.Left, root), goExpr(n.Right, root)
+		switch n.Operator {
+		case "==":
+			return fmt.Sprintf("eq(%s, %s)", left, right)
+		case "!=":
+			return fmt.Sprintf("!eq(%s, %s)", left, right)
+		case "<", ">", "<=", ">=":
+			return fmt.Sprintf("compare(%s, %s) %s 0", left, right, n.Operator)
+		case "and", "&&":
+			return paren(n.Left, goCond(n.Left, root)) + " && " + paren(n.Right, goCond(n.Right, root))
+		case "or", "||":
+			return paren(n.Left, goCond(n.Left, root)) + " || " + paren(n.Right, goCond(n.Right, root))
+		case "in":
+			return fmt.Sprintf("contains(%s, %s)", right, left)
+		case "contains":
+			return fmt.Sprintf("contains(%s, %s)", left, right)
+		case "startsWith":
+			return fmt.Sprintf("strings.HasPrefix(str(%s), str(%s))", left, right)
+		case "endsWith":
+			return fmt.Sprintf("strings.HasSuffix(str(%s), str(%s))", left, right)
+		case "matches":
+			return fmt.Sprintf("matches(%s, %s)", left, right)
+		case "+":
+			return fmt.Sprintf("add(%s, %s)", left, right)
+		case "??":
+			return fmt.Sprintf("coalesce(%s, %s)", left, right)
+		default:
+			return fmt.Sprintf("num(%s) %s num(%s)", left, n.Operator, right)
+		}
+	case *ast.BuiltinNode:
+		arg := goExpr(n.Arguments[0], root)
+		switch n.Name {
+		case "lower":
+			return "strings.ToLower(str(" + arg + "))"
+		case "upper":
+			return "strings.ToUpper(str(" + arg + "))"
+		}
+		return "length(" + arg + ")"
+	case *ast.ArrayNode:
+		items := make([]string, len(n.Nodes))
+		for i, item := range n.Nodes {
+			items[i] = goExpr(item, root)
+		}
+		return "[]any{" + strings.Join(items, ", ") + "}"
+	}
+	return "nil"
+}
+
+// goCond translates an assertion to a Go bool expression
+func goCond(node ast.Node, root string) string {
+	code := goExpr(node, root)
+	if goIsBool(node) {
+		return code
+	}
+	return "truthy(" + code + ")"
+}
+
+// goIsBool reports whether the Go translation of a node is of type bool
+func goIsBool(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.BoolNode:
+		return true
+	case *ast.UnaryNode:
+		return n.Operator != "-"
+	case *ast.BinaryNode:
+		switch n.Operator {
+		case "+", "-", "*", "/", "??":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// pyExpr translates an assertion to a Python expression over the dict root
+func pyExpr(node ast.Node, root string) string {
+	if name, ok := field(node); ok {
+		return fmt.Sprintf("%s[%s]", root, jsonString(name))
+	}
+	switch n := node.(type) {
+	case *ast.NilNode:
+		return "None"
+	case *ast.IntegerNode:
+		return strconv.Itoa(n.Value)
+	case *ast.FloatNode:
+		return formatFloat(n.Value)
+	case *ast.BoolNode:
+		if n.Value {
+			return "True"
+		}
+		return "False"
+	case *ast.StringNode:
+		return jsonString(n.Value)
+	case *ast.MemberNode:
+		return pyExpr(n.Node, root) + "[" + pyExpr(n.Property, root) + "]"
+	case *ast.UnaryNode:
+		if n.Operator == "-" {
+			return "-" + paren(n.Node, pyExpr(n.Node, root))
+		}
+		return "not " + paren(n.Node, pyExpr(n.Node, root))
+	case *ast.BinaryNode:
+		left, right := paren(n.Left, pyExpr(n.Left, root)), paren(n.Right, pyExpr(n.Right, root))
+		switch n.Operator {
+		case "and", "&&":
+			return left + " and " + right
+		case "or", "||":
+			return left + " or " + right
+		case "contains":
+			return right + " in " + left
+		case "startsWith":
+			return fmt.Sprintf("str(%s).startswith(%s)", left, right)
+		case "endsWith":
+			return fmt.Sprintf("str(%s).endswith(%s)", left, right)
+		case "matches":
+			return fmt.Sprintf("re.search(%s, %s) is not None", right, left)
+		case "??":
+			return fmt.Sprintf("%s if %s is not None else %s", left, left, right)
+		case "==", "!=":
+			if _, ok := n.Right.(*ast.NilNode); ok {
+				if n.Operator == "==" {
+					return left + " is None"
+				}
+				return left + " is not None"
+			}
+		}
+		return left + " " + n.Operator + " " + right
+	case *ast.BuiltinNode:
+		arg := pyExpr(n.Arguments[0], root)
+		switch n.Name {
+		case "lower":
+			return "str(" + arg + ").lower()"
+		case "upper":
+			return "str(" + arg + ").upper()"
+		}
+		return "len(" + arg + ")"
+	case *ast.ArrayNode:
+		items := make([]string, len(n.Nodes))
+		for i, item := range n.Nodes {
+			items[i] = pyExpr(item, root)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return "None"
+}
+
+// jsExpr translates an assertion to a JavaScript expression over the object
+// root
+func jsExpr(node ast.Node, root string) string {
+	if name, ok := field(node); ok {
+		return jsMember(root, name)
+	}
+	switch n := node.(type) {
+	case *ast.NilNode:
+		return "null"
+	case *ast.IntegerNode:
+		return strconv.Itoa(n.Value)
+	case *ast.FloatNode:
+		return strconv.FormatFloat(n.Value, 'g', -1, 64)
+	case *ast.BoolNode:
+		return strconv.FormatBool(n.Value)
+	case *ast.StringNode:
+		return jsonString(n.Value)
+	case *ast.MemberNode:
+		if prop, ok := n.Property.(*ast.StringNode); ok {
+			return jsMember(jsExpr(n.Node, root), prop.Value)
+		}
+		return jsExpr(n.Node, root) + "[" + jsExpr(n.Property, root) + "]"
+	case *ast.UnaryNode:
+		if n.Operator == "-" {
+			return "-" + paren(n.Node, jsExpr(n.Node, root))
+		}
+		return "!" + paren(n.Node, jsExpr(n.Node, root))
+	case *ast.BinaryNode:
+		left, right := paren(n.Left, jsExpr(n.Left, root)), paren(n.Right, jsExpr(n.Right, root))
+		switch n.Operator {
+		case "==", "!=":
+			_, leftNil := n.Left.(*ast.NilNode)
+			_, rightNil := n.Right.(*ast.NilNode)
+			switch {
+			case leftNil || rightNil:
+				// Missing fields are undefined
+				return left + " " + n.Operator + " " + right
+			case isLiteral(n.Left) || isLiteral(n.Right):
+				return left + " " + n.Operator + "= " + right
+			case n.Operator == "==":
+				return fmt.Sprintf("equal(%s, %s)", left, right)
+			}
+			return fmt.Sprintf("!equal(%s, %s)", left, right)
+		case "and":
+			return left + " && " + right
+		case "or":
+			return left + " || " + right
+		case "in":
+			return fmt.Sprintf("has(%s, %s)", right, left)
+		case "contains":
+			return fmt.Sprintf("has(%s, %s)", left, right)
+		case "startsWith":
+			return fmt.Sprintf("String(%s).startsWith(%s)", left, right)
+		case "endsWith":
+			return fmt.Sprintf("String(%s).endsWith(%s)", left, right)
+		case "matches":
+			return fmt.Sprintf("new RegExp(%s).test(%s)", right, left)
+		}
+		return left + " " + n.Operator + " " + right
+	case *ast.BuiltinNode:
+		arg := jsExpr(n.Arguments[0], root)
+		switch n.Name {
+		case "lower":
+			return "String(" + arg + ").toLowerCase()"
+		case "upper":
+			return "String(" + arg + ").toUpperCase()"
+		}
+		return "len(" + arg + ")"
+	case *ast.ArrayNode:
+		items := make([]string, len(n.Nodes))
+		for i, item := range n.Nodes {
+			items[i] = jsExpr(item, root)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return "null"
+}
+
+// jsMember accesses a property of an object
+func jsMember(object, name string) string {
+	if jsIdentPattern.MatchString(name) {
+		return object + "." + name
+	}
+	return object + "[" + jsonString(name) + "]"
+}
@@ -0,0 +1,595 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// FlowGenerator generates a test file of a test framework from a flow
+type FlowGenerator interface {
+	GenerateFlow(suite *Suite) (string, error)
+	Name() string
+	Filename(suite *Suite) string
+}
+
+// Variable is a variable of the environment a flow runs in. Generated tests
+// read variables from the process environment and fall back to the value.
+type Variable struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"` // The value is not written to the test file
+}
+
+// FlowCode is a generated test file
+type FlowCode struct {
+	Target   string   `json:"target"`
+	Filename string   `json:"filename"`
+	Code     string   `json:"code"`
+	Warnings []string `json:"warnings"` // Parts of the flow that were not translated
+}
+
+// Suite is a flow prepared for code generation: step outputs are resolved to
+// the steps that produce them, and assertions are parsed.
+type Suite struct {
+	Name        string
+	Description string
+	Variables   []Variable // Sorted by name
+	Setup       []SuiteStep
+	Steps       []SuiteStep
+	Teardown    []SuiteStep
+	Warnings    []string
+}
+
+// Step kinds
+const (
+	StepHTTP        = "http"
+	StepLog         = "log"
+	StepDelay       = "delay"
+	StepAssert      = "assert"
+	StepUnsupported = "unsupported"
+)
+
+// SuiteStep is a step of a suite
+type SuiteStep struct {
+	ID         string // The ID the runner gives the step
+	Name       string
+	Action     string
+	Kind       string
+	Referenced bool // Other steps use the outputs of the step
+
+	// http_request
+	Method  Template
+	URL     Template
+	Headers []Header
+	Body    interface{} // Maps, slices, scalars and templates; nil without a body
+	Timeout time.Duration
+	Outputs []Output
+
+	// log
+	Message Template
+	Level   string
+
+	// delay
+	Duration time.Duration
+
+	// assert
+	Data interface{}
+
+	Assertions []Assertion
+}
+
+// Header is a request header
+type Header struct {
+	Name  string
+	Value Template
+}
+
+// Output is a value a step extracts from its result. Path is split into the
+// keys and indexes below the result.
+type Output struct {
+	Name string
+	Path []interface{}
+}
+
+// Assertion is an expr assertion of a step. Node is nil when the assertion
+// cannot be translated; the generated test marks it as a TODO.
+type Assertion struct {
+	Source string
+	Node   ast.Node
+}
+
+// Template is a string with ${...} references
+type Template []Part
+
+// Part kinds
+const (
+	PartText     = "text"
+	PartVariable = "variable" // ${BASE_URL}
+	PartOutput   = "output"   // ${login.token}
+	PartBuiltin  = "builtin"  // ${UUID}
+)
+
+// Part is a literal text or a reference of a template
+type Part struct {
+	Kind string
+	Text string // Literal text, or the name of a variable or built-in
+	Step string // Step of an output
+	Path string // Output path below the step, like "body.token"
+}
+
+// Literal returns the text of a template without references
+func (t Template) Literal() (string, bool) {
+	var sb strings.Builder
+	for _, part := range t {
+		if part.Kind != PartText {
+			return "", false
+		}
+		sb.WriteString(part.Text)
+	}
+	return sb.String(), true
+}
+
+// Builtins are the built-in variables of the runner
+var Builtins = map[string]bool{
+	"RANDOM_ID": true, "UUID": true, "TIMESTAMP": true, "ISO_TIMESTAMP": true,
+	"DATE": true, "TIME": true, "DATETIME": true,
+	"YEAR": true, "MONTH": true, "DAY": true, "HOUR": true, "MINUTE": true, "SECOND": true,
+}
+
+var (
+	referencePattern = regexp.MustCompile(`\$\{([^}]+)\}|\{\{([^}]+)\}\}`)
+	outputPattern    = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)\.([\w.]+)$`)
+	variablePattern  = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+	identPattern     = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+// RegisterFlow registers a flow generator
+func (g *Generator) RegisterFlow(fg FlowGenerator) {
+	g.targets[strings.ToLower(fg.Name())] = fg
+}
+
+// SupportedTargets returns the test frameworks flows can be generated for
+func (g *Generator) SupportedTargets() []string {
+	targets := make([]string, 0, len(g.targets))
+	for name := range g.targets {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// GenerateFlow generates a test file of a flow for a target framework.
+// Variables are the variables of the environment the flow runs in; the
+// flow's env overrides them, like it does when the runner runs the flow.
+func (g *Generator) GenerateFlow(target string, def *models.FlowDefinition, variables []Variable) (*FlowCode, error) {
+	fg, ok := g.targets[strings.ToLower(target)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported target: %s", target)
+	}
+
+	suite := NewSuite(def, variables)
+	code, err := fg.GenerateFlow(suite)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := suite.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return &FlowCode{
+		Target:   fg.Name(),
+		Filename: fg.Filename(suite),
+		Code:     code,
+		Warnings: warnings,
+	}, nil
+}
+
+// NewSuite prepares a flow for code generation
+func NewSuite(def *models.FlowDefinition, variables []Variable) *Suite {
+	s := &Suite{Name: def.Name, Description: def.Description}
+
+	values := make(map[string]Variable)
+	for _, v := range variables {
+		values[v.Name] = v
+	}
+	for name, value := range def.Env {
+		values[name] = Variable{Name: name, Value: fmt.Sprintf("%v", value)}
+	}
+	for _, v := range values {
+		s.Variables = append(s.Variables, v)
+	}
+	sort.Slice(s.Variables, func(i, j int) bool { return s.Variables[i].Name < s.Variables[j].Name })
+
+	b := &suiteBuilder{suite: s, steps: make(map[string]bool), variables: values, referenced: make(map[string]bool)}
+	for _, phase := range []struct {
+		name  string
+		steps []models.Step
+	}{{"setup", def.Setup}, {"main", def.Steps}, {"teardown", def.Teardown}} {
+		for i, step := range phase.steps {
+			b.steps[stepID(step, phase.name, i)] = true
+		}
+	}
+
+	s.Setup = b.build(def.Setup, "setup")
+	s.Steps = b.build(def.Steps, "main")
+	s.Teardown = b.build(def.Teardown, "teardown")
+
+	for _, phase := range [][]SuiteStep{s.Setup, s.Steps, s.Teardown} {
+		for i := range phase {
+			phase[i].Referenced = b.referenced[phase[i].ID]
+		}
+	}
+	return s
+}
+
+// has reports whether a suite has a step of a kind
+func (s *Suite) has(kind string) bool {
+	for _, phase := range [][]SuiteStep{s.Setup, s.Steps, s.Teardown} {
+		for _, step := range phase {
+			if step.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stepID returns the ID of a step, or the ID the runner generates for it
+func stepID(step models.Step, phase string, index int) string {
+	if step.ID != "" {
+		return step.ID
+	}
+	return fmt.Sprintf("%s_%d", phase, index)
+}
+
+type suiteBuilder struct {
+	suite      *Suite
+	steps      map[string]bool // IDs of all steps
+	variables  map[string]Variable
+	referenced map[string]bool // Steps with referenced outputs
+	warned     map[string]bool
+}
+
+func (b *suiteBuilder) warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if b.warned == nil {
+		b.warned = make(map[string]bool)
+	}
+	if !b.warned[message] {
+		b.warned[message] = true
+		b.suite.Warnings = append(b.suite.Warnings, message)
+	}
+}
+
+func (b *suiteBuilder) build(steps []models.Step, phase string) []SuiteStep {
+	result := make([]SuiteStep, 0, len(steps))
+	for i, step := range steps {
+		result = append(result, b.step(step, stepID(step, phase, i)))
+	}
+	return result
+}
+
+func (b *suiteBuilder) step(step models.Step, id string) SuiteStep {
+	s := SuiteStep{ID: id, Name: step.Name, Action: step.Action}
+	config := step.Config
+
+	switch step.Action {
+	case "http_request":
+		s.Kind = StepHTTP
+		method, _ := config["method"].(string)
+		if method == "" {
+			method = "GET"
+		}
+		s.Method = b.template(id, strings.ToUpper(method))
+		url, _ := config["url"].(string)
+		s.URL = b.template(id, url)
+		if headers, ok := config["headers"].(map[string]interface{}); ok {
+			for _, name := range sortedKeys(headers) {
+				s.Headers = append(s.Headers, Header{Name: name, Value: b.template(id, fmt.Sprintf("%v", headers[name]))})
+			}
+		}
+		if body, ok := config["body"]; ok && body != nil {
+			s.Body = b.value(id, body)
+		}
+		for _, name := range sortedKeys(step.Output) {
+			s.Outputs = append(s.Outputs, Output{Name: name, Path: OutputPath(id, step.Output[name])})
+		}
+		for _, key := range []string{"query", "auth"} {
+			if _, ok := config[key]; ok {
+				b.warn("step %s: http_request ignores config.%s; it is not generated", id, key)
+			}
+		}
+
+	case "log":
+		s.Kind = StepLog
+		message, _ := config["message"].(string)
+		s.Message = b.template(id, message)
+		s.Level, _ = config["level"].(string)
+		if s.Level == "" {
+			s.Level = "info"
+		}
+
+	case "delay":
+		s.Kind = StepDelay
+		duration, _ := config["duration"].(string)
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			b.warn("step %s: invalid delay duration %q", id, duration)
+		}
+		s.Duration = d
+
+	case "assert":
+		s.Kind = StepAssert
+		s.Data = b.value(id, config["data"])
+		var list []string
+		switch v := config["assertions"].(type) {
+		case []string:
+			list = v
+		case []interface{}:
+			for _, a := range v {
+				if source, ok := a.(string); ok {
+					list = append(list, source)
+				}
+			}
+		}
+		s.Assertions = b.assertions(id, list)
+
+	default:
+		s.Kind = StepUnsupported
+		b.warn("step %s: action %s is not generated; the test marks it with a TODO", id, step.Action)
+		return s
+	}
+
+	if step.Timeout != "" {
+		d, err := time.ParseDuration(step.Timeout)
+		if err != nil {
+			b.warn("step %s: invalid timeout %q", id, step.Timeout)
+		}
+		s.Timeout = d
+	}
+	if step.Retry != nil {
+		b.warn("step %s: retries are not generated", id)
+	}
+	if len(step.Schema) > 0 || step.Snapshot != nil {
+		b.warn("step %s: schema and snapshot assertions are not generated", id)
+	}
+	if s.Kind != StepAssert {
+		s.Assertions = append(s.Assertions, b.assertions(id, step.Assert)...)
+	}
+	return s
+}
+
+// template splits a string into text and the references the runner resolves
+func (b *suiteBuilder) template(id, text string) Template {
+	var t Template
+	add := func(part Part) {
+		// Merge adjacent texts
+		if part.Kind == PartText && len(t) > 0 && t[len(t)-1].Kind == PartText {
+			t[len(t)-1].Text += part.Text
+			return
+		}
+		t = append(t, part)
+	}
+
+	last := 0
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > last {
+			add(Part{Kind: PartText, Text: text[last:m[0]]})
+		}
+		last = m[1]
+
+		var ref string
+		if m[2] >= 0 {
+			ref = strings.TrimSpace(text[m[2]:m[3]])
+		} else {
+			ref = strings.TrimSpace(text[m[4]:m[5]])
+		}
+		switch {
+		case Builtins[ref]:
+			add(Part{Kind: PartBuiltin, Text: ref})
+		case outputPattern.MatchString(ref) && b.steps[outputPattern.FindStringSubmatch(ref)[1]]:
+			match := outputPattern.FindStringSubmatch(ref)
+			b.referenced[match[1]] = true
+			add(Part{Kind: PartOutput, Step: match[1], Path: match[2]})
+		case variablePattern.MatchString(ref):
+			if _, ok := b.variables[ref]; !ok {
+				b.warn("variable %s is not defined by the flow or the environment; set it in the environment of the test", ref)
+			}
+			add(Part{Kind: PartVariable, Text: ref})
+		default:
+			b.warn("step %s: reference %s is not resolved and stays in the text", id, text[m[0]:m[1]])
+			add(Part{Kind: PartText, Text: text[m[0]:m[1]]})
+		}
+	}
+	if last < len(text) || len(t) == 0 {
+		add(Part{Kind: PartText, Text: text[last:]})
+	}
+	return t
+}
+
+// value converts the strings of a config value to templates
+func (b *suiteBuilder) value(id string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return b.template(id, v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = b.value(id, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = b.value(id, item)
+		}
+		return result
+	}
+	return v
+}
+
+func (b *suiteBuilder) assertions(id string, sources []string) []Assertion {
+	result := make([]Assertion, 0, len(sources))
+	for _, source := range sources {
+		a := Assertion{Source: source}
+		tree, err := parser.Parse(source)
+		if err != nil {
+			b.warn("step %s: assertion %q does not parse: %v", id, source, err)
+		} else if err := checkAssertion(tree.Node); err != nil {
+			b.warn("step %s: assertion %q is not translated: %v", id, source, err)
+		} else {
+			a.Node = tree.Node
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// OutputPath splits an output path into keys and indexes below the step
+// result. Like the runner, paths are relative to the response body, unless
+// they start with a field of the result, like status or headers. A leading
+// "$." and the step's own ID are dropped.
+func OutputPath(id, path string) []interface{} {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.TrimPrefix(path, id+".")
+
+	var keys []interface{}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		// Indexes, like items[0]
+		name, rest, _ := strings.Cut(key, "[")
+		if name != "" {
+			keys = append(keys, name)
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			var n int
+			if _, err := fmt.Sscanf(index, "%d", &n); err == nil {
+				keys = append(keys, n)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	if len(keys) == 0 {
+		return keys
+	}
+	if first, ok := keys[0].(string); !ok || !resultFields[first] {
+		keys = append([]interface{}{"body"}, keys...)
+	}
+	return keys
+}
+
+// resultFields are the fields of the result of an http_request step
+var resultFields = map[string]bool{
+	"status": true, "body": true, "headers": true, "duration_ms": true, "content_type": true,
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// identifier converts a step ID or flow name to a camel or snake case
+// identifier with a prefix, like stepCreateUser or step_create_user
+func identifier(prefix, name string, snake bool) string {
+	words := identPattern.FindAllString(name, -1)
+	if snake {
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return strings.Join(append([]string{prefix}, words...), "_")
+	}
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, w := range words {
+		sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return sb.String()
+}
+
+// slug converts a flow name to a file name, like place-order-flow
+func slug(name, separator string) string {
+	words := identPattern.FindAllString(strings.ToLower(name), -1)
+	if len(words) == 0 {
+		return "flow"
+	}
+	return strings.Join(words, separator)
+}
+
+// stepNames names the functions of the steps of a suite, keeping them unique
+func stepNames(s *Suite, snake bool) map[*SuiteStep]string {
+	names := make(map[*SuiteStep]string)
+	used := make(map[string]bool)
+	for _, phase := range []struct {
+		prefix string
+		steps  []SuiteStep
+	}{{"setup", s.Setup}, {"step", s.Steps}, {"teardown", s.Teardown}} {
+		for i := range phase.steps {
+			name := identifier(phase.prefix, phase.steps[i].ID, snake)
+			unique := name
+			for n := 2; used[unique]; n++ {
+				unique = fmt.Sprintf("%s%d", name, n)
+			}
+			used[unique] = true
+			names[&phase.steps[i]] = unique
+		}
+	}
+	return names
+}
+
+// label describes a step in comments and test names
+func (s *SuiteStep) label() string {
+	if s.Name != "" && s.Name != s.ID {
+		return s.ID + ": " + s.Name
+	}
+	return s.ID
+}
+
+// comment collapses text to a single line, so that a description or an
+// assertion with line breaks stays inside the comment it is written to
+func comment(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// writer writes indented lines of generated code
+type writer struct {
+	sb     strings.Builder
+	indent string // One level of indentation
+}
+
+func (w *writer) line(depth int, format string, args ...interface{}) {
+	if format == "" {
+		w.sb.WriteString("\n")
+		return
+	}
+	w.sb.WriteString(strings.Repeat(w.indent, depth))
+	if len(args) == 0 {
+		w.sb.WriteString(format)
+	} else {
+		w.sb.WriteString(fmt.Sprintf(format, args...))
+	}
+	w.sb.WriteString("\n")
+}
+
+// raw writes text as is
+func (w *writer) raw(text string) {
+	w.sb.WriteString(text)
+}
+
+func (w *writer) String() string {
+	return w.sb.String()
+}
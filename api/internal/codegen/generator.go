@@ -5,9 +5,11 @@ import (
 	"strings"
 )
 
-// Generator handles code generation from flow steps
+// Generator handles code generation from flow steps, and of test files from
+// whole flows
 type Generator struct {
 	languages map[string]LanguageGenerator
+	targets   map[string]FlowGenerator
 }
 
 // LanguageGenerator interface for language-specific generators
@@ -43,6 +45,7 @@ type AuthConfig struct {
 func NewGenerator() *Generator {
 	g := &Generator{
 		languages: make(map[string]LanguageGenerator),
+		targets:   make(map[string]FlowGenerator),
 	}
 
 	// Register built-in generators
@@ -55,6 +58,13 @@ func NewGenerator() *Generator {
 	g.Register(&JavaGenerator{})
 	g.Register(&CSharpGenerator{})
 
+	// Register built-in flow generators
+	g.RegisterFlow(&GoTestGenerator{})
+	g.RegisterFlow(&PytestGenerator{})
+	g.RegisterFlow(&JestGenerator{})
+	g.RegisterFlow(&JestGenerator{Vitest: true})
+	g.RegisterFlow(&K6Generator{})
+
 	return g
 }
 
@@ -0,0 +1,599 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr/ast"
+)
+
+// GoTestGenerator generates a Go test of the testing package with net/http
+type GoTestGenerator struct{}
+
+func (g *GoTestGenerator) Name() string { return "go" }
+
+func (g *GoTestGenerator) Filename(suite *Suite) string {
+	return slug(suite.Name, "_") + "_test.go"
+}
+
+func (g *GoTestGenerator) GenerateFlow(suite *Suite) (string, error) {
+	w := &writer{indent: "\t"}
+	names := stepNames(suite, false)
+
+	w.line(0, "// Tests of flow %q, generated by testmesh.", suite.Name)
+	w.line(0, "")
+	w.line(0, "package flows")
+	w.line(0, "")
+	w.raw(goImports)
+	w.line(0, "")
+
+	// Test function
+	test := identifier("Test", suite.Name, false)
+	if test == "Test" {
+		test = "TestFlow"
+	}
+	if suite.Description != "" {
+		w.line(0, "// %s runs flow %q: %s", test, suite.Name, comment(suite.Description))
+	} else {
+		w.line(0, "// %s runs flow %q", test, suite.Name)
+	}
+	w.line(0, "func %s(t *testing.T) {", test)
+	w.line(1, "outputs := map[string]map[string]any{}")
+	w.line(0, "")
+	for i := range suite.Setup {
+		w.line(1, "%s(t, outputs)", names[&suite.Setup[i]])
+	}
+	if len(suite.Teardown) > 0 {
+		w.line(1, "t.Cleanup(func() {")
+		for i := range suite.Teardown {
+			w.line(2, "%s(t, outputs)", names[&suite.Teardown[i]])
+		}
+		w.line(1, "})")
+	}
+	if len(suite.Setup) > 0 || len(suite.Teardown) > 0 {
+		w.line(0, "")
+	}
+	w.line(1, "// Steps depend on the outputs of the steps before them")
+	w.line(1, "for _, step := range []struct {")
+	w.line(2, "name string")
+	w.line(2, "run  func(*testing.T, map[string]map[string]any)")
+	w.line(1, "}{")
+	for i := range suite.Steps {
+		w.line(2, "{%s, %s},", strconv.Quote(suite.Steps[i].ID), names[&suite.Steps[i]])
+	}
+	w.line(1, "} {")
+	w.line(2, "if !t.Run(step.name, func(t *testing.T) { step.run(t, outputs) }) {")
+	w.line(3, "t.FailNow()")
+	w.line(2, "}")
+	w.line(1, "}")
+	w.line(0, "}")
+
+	for _, phase := range []struct {
+		name  string
+		steps []SuiteStep
+	}{{"setup", suite.Setup}, {"main", suite.Steps}, {"teardown", suite.Teardown}} {
+		for i := range phase.steps {
+			w.line(0, "")
+			g.step(w, &phase.steps[i], names[&phase.steps[i]], phase.name)
+		}
+	}
+
+	// Variables and helpers
+	w.line(0, "")
+	w.line(0, "// defaults are the values of the variables of the flow and its environment.")
+	w.line(0, "// Variables of the process environment override them.")
+	w.line(0, "var defaults = map[string]string{")
+	for _, v := range suite.Variables {
+		if v.Secret {
+			w.line(1, "%s: \"\", // Secret: set %s in the environment", strconv.Quote(v.Name), comment(v.Name))
+		} else {
+			w.line(1, "%s: %s,", strconv.Quote(v.Name), strconv.Quote(v.Value))
+		}
+	}
+	w.line(0, "}")
+	w.line(0, "")
+	w.raw(goHelpers)
+
+	code, err := format.Source([]byte(w.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return string(code), nil
+}
+
+func (g *GoTestGenerator) step(w *writer, s *SuiteStep, name, phase string) {
+	w.line(0, "// %s runs step %s", name, comment(s.label()))
+	w.line(0, "func %s(t *testing.T, outputs map[string]map[string]any) {", name)
+	if phase != "main" {
+		w.line(1, "t.Helper()")
+		w.line(0, "")
+	}
+
+	switch s.Kind {
+	case StepHTTP:
+		headers := "nil"
+		if len(s.Headers) > 0 {
+			var sb strings.Builder
+			sb.WriteString("map[string]string{\n")
+			for _, h := range s.Headers {
+				sb.WriteString(fmt.Sprintf("\t\t%s: %s,\n", strconv.Quote(h.Name), goTemplate(h.Value)))
+			}
+			sb.WriteString("\t}")
+			headers = sb.String()
+		}
+		body := "nil"
+		if s.Body != nil {
+			body = goValue(s.Body, 1)
+		}
+		w.line(1, "result := do(t, %s, %s, %s, %s, %s)", goTemplate(s.Method), goTemplate(s.URL), headers, body, goDuration(s.Timeout))
+		g.assertions(w, s.Assertions, "result")
+		if len(s.Outputs) > 0 {
+			w.line(0, "")
+			for _, o := range s.Outputs {
+				w.line(1, "result[%s] = %s", strconv.Quote(o.Name), goDig("result", o.Path))
+			}
+		}
+		if s.Referenced || len(s.Outputs) > 0 {
+			w.line(1, "outputs[%s] = result", strconv.Quote(s.ID))
+		}
+
+	case StepLog:
+		w.line(1, "message := %s", goTemplate(s.Message))
+		w.line(1, "t.Log(message)")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = map[string]any{\"message\": message, \"level\": %s, \"logged\": true}", strconv.Quote(s.ID), strconv.Quote(s.Level))
+		}
+
+	case StepDelay:
+		w.line(1, "time.Sleep(%s)", goDuration(s.Duration))
+		if s.Referenced {
+			w.line(1, "outputs[%s] = map[string]any{\"duration\": %s, \"duration_ms\": %d, \"completed\": true}",
+				strconv.Quote(s.ID), strconv.Quote(s.Duration.String()), s.Duration.Milliseconds())
+		}
+
+	case StepAssert:
+		w.line(1, "data := %s", goData(s.Data))
+		g.assertions(w, s.Assertions, "data")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = map[string]any{\"assertions_count\": %d, \"passed\": true}", strconv.Quote(s.ID), len(s.Assertions))
+		}
+
+	default:
+		w.line(1, "// TODO: %s steps are not generated", comment(s.Action))
+		if phase == "teardown" {
+			w.line(1, "t.Log(%s)", strconv.Quote(s.Action+" step "+s.ID+" is not generated"))
+		} else {
+			w.line(1, "t.Skip(%s)", strconv.Quote(s.Action+" step "+s.ID+" is not generated"))
+		}
+	}
+	w.line(0, "}")
+}
+
+func (g *GoTestGenerator) assertions(w *writer, assertions []Assertion, root string) {
+	for _, a := range assertions {
+		w.line(0, "")
+		if a.Node == nil {
+			w.line(1, "// TODO: translate assertion %s", comment(a.Source))
+			continue
+		}
+
+		// Report the actual value of comparisons
+		if n, ok := a.Node.(*ast.BinaryNode); ok && isComparison(n.Operator) {
+			left, right := goExpr(n.Left, root), goExpr(n.Right, root)
+			var failed string
+			switch n.Operator {
+			case "==":
+				failed = fmt.Sprintf("!eq(got, %s)", right)
+			case "!=":
+				failed = fmt.Sprintf("eq(got, %s)", right)
+			default:
+				failed = fmt.Sprintf("compare(got, %s) %s 0", right, negate[n.Operator])
+			}
+			w.line(1, "if got := %s; %s {", left, failed)
+			w.line(2, "t.Fatalf(%s, got)", strconv.Quote(strings.ReplaceAll(a.Source, "%", "%%")+": got %v"))
+			w.line(1, "}")
+			continue
+		}
+
+		cond := goCond(a.Node, root)
+		if u, ok := a.Node.(*ast.UnaryNode); ok && u.Operator != "-" {
+			cond = goCond(u.Node, root)
+		} else {
+			cond = "!" + paren(a.Node, cond)
+		}
+		w.line(1, "if %s {", cond)
+		w.line(2, "t.Fatal(%s)", strconv.Quote("assertion failed: "+a.Source))
+		w.line(1, "}")
+	}
+}
+
+// negate maps an ordering operator to its negation
+var negate = map[string]string{"<": ">=", ">": "<=", "<=": ">", ">=": "<"}
+
+// goTemplate converts a template to a Go string expression
+func goTemplate(t Template) string {
+	parts := make([]string, 0, len(t))
+	for _, part := range t {
+		switch part.Kind {
+		case PartVariable:
+			parts = append(parts, fmt.Sprintf("env(%s)", strconv.Quote(part.Text)))
+		case PartBuiltin:
+			parts = append(parts, fmt.Sprintf("builtin(%s)", strconv.Quote(part.Text)))
+		case PartOutput:
+			parts = append(parts, fmt.Sprintf("ref(outputs, %s, %s)", strconv.Quote(part.Step), strconv.Quote(part.Path)))
+		default:
+			parts = append(parts, strconv.Quote(part.Text))
+		}
+	}
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
+
+// goValue converts a config value to a Go literal
+func goValue(v interface{}, depth int) string {
+	indent := strings.Repeat("\t", depth)
+	switch v := v.(type) {
+	case Template:
+		return goTemplate(v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "map[string]any{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("map[string]any{\n")
+		for _, key := range sortedKeys(v) {
+			sb.WriteString(fmt.Sprintf("%s\t%s: %s,\n", indent, strconv.Quote(key), goValue(v[key], depth+1)))
+		}
+		sb.WriteString(indent + "}")
+		return sb.String()
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]any{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("[]any{\n")
+		for _, item := range v {
+			sb.WriteString(fmt.Sprintf("%s\t%s,\n", indent, goValue(item, depth+1)))
+		}
+		sb.WriteString(indent + "}")
+		return sb.String()
+	case float64:
+		return formatFloat(v)
+	case nil:
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// goData converts the data of an assert step to a Go map. Data that is a
+// single output, like ${create_order.body}, is the output itself.
+func goData(v interface{}) string {
+	if t, ok := v.(Template); ok {
+		if len(t) == 1 && t[0].Kind == PartOutput {
+			return fmt.Sprintf("toMap(output(outputs, %s, %s))", strconv.Quote(t[0].Step), strconv.Quote(t[0].Path))
+		}
+		return fmt.Sprintf("map[string]any{\"value\": %s}", goTemplate(t))
+	}
+	if _, ok := v.(map[string]interface{}); ok {
+		return goValue(v, 1)
+	}
+	return fmt.Sprintf("map[string]any{\"value\": %s}", goValue(v, 1))
+}
+
+// goDig reads a path of a result
+func goDig(root string, path []interface{}) string {
+	args := []string{root}
+	for _, key := range path {
+		if s, ok := key.(string); ok {
+			args = append(args, strconv.Quote(s))
+		} else {
+			args = append(args, fmt.Sprint(key))
+		}
+	}
+	return "dig(" + strings.Join(args, ", ") + ")"
+}
+
+// goDuration converts a duration to a Go expression
+func goDuration(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"}, {time.Millisecond, "time.Millisecond"}}
+	if d == 0 {
+		return "0"
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
+const goImports = `import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+`
+
+const goHelpers = `// env returns a variable of the environment, or its default
+func env(name string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return defaults[name]
+}
+
+// builtin returns a built-in variable of TestMesh, like UUID or TIMESTAMP
+func builtin(name string) string {
+	now := time.Now()
+	switch name {
+	case "UUID", "RANDOM_ID":
+		var b [16]byte
+		rand.Read(b[:])
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "TIMESTAMP":
+		return strconv.FormatInt(now.Unix(), 10)
+	case "ISO_TIMESTAMP":
+		return now.Format(time.RFC3339)
+	case "DATE":
+		return now.Format("2006-01-02")
+	case "TIME":
+		return now.Format("15:04:05")
+	case "DATETIME":
+		return now.Format("2006-01-02 15:04:05")
+	case "YEAR":
+		return now.Format("2006")
+	case "MONTH":
+		return now.Format("01")
+	case "DAY":
+		return now.Format("02")
+	case "HOUR":
+		return now.Format("15")
+	case "MINUTE":
+		return now.Format("04")
+	case "SECOND":
+		return now.Format("05")
+	}
+	return ""
+}
+
+// output returns an output of a step: a named output, or a path below a
+// field of its result, like body.token
+func output(outputs map[string]map[string]any, step, path string) (any, bool) {
+	if value, ok := outputs[step][path]; ok {
+		return value, true
+	}
+	keys := strings.Split(path, ".")
+	value, ok := outputs[step][keys[0]]
+	for _, key := range keys[1:] {
+		m, isMap := value.(map[string]any)
+		if !isMap {
+			return nil, false
+		}
+		value, ok = m[key]
+	}
+	return value, ok
+}
+
+// ref returns an output of a step as text, like ${login.token}
+func ref(outputs map[string]map[string]any, step, path string) string {
+	value, ok := output(outputs, step, path)
+	if !ok {
+		return "${" + step + "." + path + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+// do sends a request like the http_request action, and returns the result
+// of the action: status, body, headers, duration_ms and content_type
+func do(t *testing.T, method, url string, headers map[string]string, body any, timeout time.Duration) map[string]any {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	var parsed any
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		parsed = string(data)
+	}
+	header := make(map[string]any, len(resp.Header))
+	for name := range resp.Header {
+		header[name] = resp.Header.Get(name)
+	}
+	return map[string]any{
+		"status":       resp.StatusCode,
+		"body":         parsed,
+		"headers":      header,
+		"duration_ms":  time.Since(start).Milliseconds(),
+		"content_type": resp.Header.Get("Content-Type"),
+	}
+}
+
+// dig returns the value at a path of keys and indexes of nested maps and
+// slices, or nil
+func dig(value any, path ...any) any {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[fmt.Sprint(key)]
+		case []any:
+			i, ok := key.(int)
+			if !ok || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// toMap returns a map, or a map holding a value that is not a map
+func toMap(value any, _ bool) map[string]any {
+	if m, ok := value.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{"value": value}
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// num converts a value to a number for arithmetic
+func num(v any) float64 {
+	n, _ := number(v)
+	return n
+}
+
+// str converts a value to text
+func str(v any) string {
+	return fmt.Sprint(v)
+}
+
+// eq compares numbers by value, and other values deeply
+func eq(a, b any) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders numbers by value, and other values as text
+func compare(a, b any) int {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	return strings.Compare(str(a), str(b))
+}
+
+// truthy reports whether a value is true
+func truthy(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// contains reports whether a string contains a substring, a slice an
+// element, or a map a key
+func contains(container, item any) bool {
+	switch c := container.(type) {
+	case string:
+		return strings.Contains(c, str(item))
+	case []any:
+		for _, v := range c {
+			if eq(v, item) {
+				return true
+			}
+		}
+	case map[string]any:
+		_, ok := c[str(item)]
+		return ok
+	}
+	return false
+}
+
+// length returns the length of a string, slice or map
+func length(v any) int {
+	switch c := v.(type) {
+	case string:
+		return len([]rune(c))
+	case []any:
+		return len(c)
+	case map[string]any:
+		return len(c)
+	}
+	return 0
+}
+
+// matches reports whether a value matches a regular expression
+func matches(v, pattern any) bool {
+	ok, _ := regexp.MatchString(str(pattern), str(v))
+	return ok
+}
+
+// add adds numbers, or concatenates text
+func add(a, b any) any {
+	_, aString := a.(string)
+	_, bString := b.(string)
+	if aString || bString {
+		return str(a) + str(b)
+	}
+	return num(a) + num(b)
+}
+
+// coalesce returns a, or b when a is nil
+func coalesce(a, b any) any {
+	if a != nil {
+		return a
+	}
+	return b
+}
+`
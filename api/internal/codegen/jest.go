@@ -0,0 +1,253 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+)
+
+// JestGenerator generates a Jest or Vitest test file with fetch
+type JestGenerator struct {
+	Vitest bool
+}
+
+func (g *JestGenerator) Name() string {
+	if g.Vitest {
+		return "vitest"
+	}
+	return "jest"
+}
+
+func (g *JestGenerator) Filename(suite *Suite) string {
+	return slug(suite.Name, "-") + ".test.js"
+}
+
+func (g *JestGenerator) GenerateFlow(suite *Suite) (string, error) {
+	w := &writer{indent: "  "}
+	names := stepNames(suite, false)
+
+	w.line(0, "// Tests of flow %s, generated by testmesh.", jsonString(suite.Name))
+	if suite.Description != "" {
+		w.line(0, "// %s", comment(suite.Description))
+	}
+	if g.Vitest {
+		w.line(0, "import { afterAll, beforeAll, describe, expect, test } from \"vitest\";")
+	}
+	w.line(0, "")
+	jsDefaults(w, suite)
+	w.line(0, "")
+
+	// Steps depend on the outputs of the steps before them, so tests of the
+	// describe block run in order
+	w.line(0, "describe(%s, () => {", jsonString(suite.Name))
+	w.line(1, "const outputs = {};")
+	if len(suite.Setup) > 0 {
+		w.line(0, "")
+		w.line(1, "beforeAll(async () => {")
+		for i := range suite.Setup {
+			w.line(2, "await %s(outputs);", names[&suite.Setup[i]])
+		}
+		w.line(1, "});")
+	}
+	if len(suite.Teardown) > 0 {
+		w.line(0, "")
+		w.line(1, "afterAll(async () => {")
+		for i := range suite.Teardown {
+			w.line(2, "await %s(outputs);", names[&suite.Teardown[i]])
+		}
+		w.line(1, "});")
+	}
+	for i := range suite.Steps {
+		w.line(0, "")
+		w.line(1, "test(%s, async () => {", jsonString(suite.Steps[i].label()))
+		w.line(2, "await %s(outputs);", names[&suite.Steps[i]])
+		w.line(1, "});")
+	}
+	w.line(0, "});")
+
+	for _, phase := range [][]SuiteStep{suite.Setup, suite.Steps, suite.Teardown} {
+		for i := range phase {
+			w.line(0, "")
+			g.step(w, &phase[i], names[&phase[i]])
+		}
+	}
+
+	w.line(0, "")
+	w.raw(jestHelpers)
+	w.raw(jsHelpers)
+	return w.String(), nil
+}
+
+func (g *JestGenerator) step(w *writer, s *SuiteStep, name string) {
+	w.line(0, "// %s", comment(s.label()))
+	w.line(0, "async function %s(outputs) {", name)
+
+	switch s.Kind {
+	case StepHTTP:
+		options := jsRequestOptions(s, 2, fmt.Sprint(s.Timeout.Milliseconds()))
+		if len(options) == 0 {
+			w.line(1, "const result = await request(%s, %s);", jsTemplate(s.Method), jsTemplate(s.URL))
+		} else {
+			w.line(1, "const result = await request(%s, %s, {", jsTemplate(s.Method), jsTemplate(s.URL))
+			for _, option := range options {
+				w.line(2, "%s,", option)
+			}
+			w.line(1, "});")
+		}
+		g.assertions(w, s.Assertions, "result")
+		for _, o := range s.Outputs {
+			w.line(1, "result[%s] = dig(result, %s);", jsonString(o.Name), jsPath(o.Path))
+		}
+		if s.Referenced || len(s.Outputs) > 0 {
+			w.line(1, "outputs[%s] = result;", jsonString(s.ID))
+		}
+
+	case StepLog:
+		w.line(1, "const message = %s;", jsTemplate(s.Message))
+		w.line(1, "console.%s(message);", jsConsole(s.Level))
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { message, level: %s, logged: true };", jsonString(s.ID), jsonString(s.Level))
+		}
+
+	case StepDelay:
+		w.line(1, "await sleep(%d);", s.Duration.Milliseconds())
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { duration: %s, duration_ms: %d, completed: true };",
+				jsonString(s.ID), jsonString(s.Duration.String()), s.Duration.Milliseconds())
+		}
+
+	case StepAssert:
+		w.line(1, "const data = %s;", jsData(s.Data, 1))
+		g.assertions(w, s.Assertions, "data")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { assertions_count: %d, passed: true };", jsonString(s.ID), len(s.Assertions))
+		}
+
+	default:
+		w.line(1, "// TODO: %s steps are not generated", comment(s.Action))
+	}
+	w.line(0, "}")
+}
+
+func (g *JestGenerator) assertions(w *writer, assertions []Assertion, root string) {
+	for _, a := range assertions {
+		if a.Node == nil {
+			w.line(1, "// TODO: translate assertion %s", comment(a.Source))
+			continue
+		}
+		w.line(1, "%s;", jestExpect(a.Node, root))
+	}
+}
+
+// jestMatchers are the matchers of ordering operators
+var jestMatchers = map[string]string{
+	"<": "toBeLessThan", "<=": "toBeLessThanOrEqual", ">": "toBeGreaterThan", ">=": "toBeGreaterThanOrEqual",
+}
+
+// jestExpect translates an assertion to an expect call, with the matcher of
+// its operator where there is one
+func jestExpect(node ast.Node, root string) string {
+	negated := false
+	target := node
+	if u, ok := node.(*ast.UnaryNode); ok && u.Operator != "-" {
+		negated, target = true, u.Node
+	}
+
+	if b, ok := target.(*ast.BinaryNode); ok {
+		left, right := jsExpr(b.Left, root), jsExpr(b.Right, root)
+		not := ""
+		if negated {
+			not = ".not"
+		}
+		switch b.Operator {
+		case "==", "!=":
+			if b.Operator == "!=" {
+				if negated {
+					not = ""
+				} else {
+					not = ".not"
+				}
+			}
+			if _, ok := b.Right.(*ast.NilNode); ok {
+				return fmt.Sprintf("expect(%s ?? null)%s.toBeNull()", left, not)
+			}
+			return fmt.Sprintf("expect(%s)%s.toEqual(%s)", left, not, right)
+		case "<", "<=", ">", ">=":
+			if !negated {
+				return fmt.Sprintf("expect(%s).%s(%s)", left, jestMatchers[b.Operator], right)
+			}
+		case "contains":
+			return fmt.Sprintf("expect(%s)%s.toContain(%s)", left, not, right)
+		case "in":
+			if _, ok := b.Right.(*ast.ArrayNode); ok {
+				return fmt.Sprintf("expect(%s)%s.toContain(%s)", right, not, left)
+			}
+		case "matches":
+			return fmt.Sprintf("expect(%s)%s.toMatch(new RegExp(%s))", left, not, right)
+		}
+	}
+	return fmt.Sprintf("expect(%s).toBe(true)", jsExpr(node, root))
+}
+
+// jsConsole returns the console method of a log level
+func jsConsole(level string) string {
+	switch strings.ToLower(level) {
+	case "debug", "warn", "error":
+		return strings.ToLower(level)
+	}
+	return "log"
+}
+
+const jestHelpers = `// env returns a variable of the environment, or its default
+function env(name) {
+  return process.env[name] ?? defaults[name] ?? "";
+}
+
+// request sends a request like the http_request action, and returns the
+// result of the action: status, body, headers, duration_ms and content_type
+async function request(method, url, { headers = {}, body, timeout } = {}) {
+  const init = { method, headers: { ...headers } };
+  if (body !== undefined) {
+    init.body = JSON.stringify(body);
+    if (!Object.keys(init.headers).some((name) => name.toLowerCase() === "content-type")) {
+      init.headers["Content-Type"] = "application/json";
+    }
+  }
+  if (timeout) {
+    init.signal = AbortSignal.timeout(timeout);
+  }
+
+  const start = Date.now();
+  const response = await fetch(url, init);
+  const text = await response.text();
+  let parsed;
+  try {
+    parsed = JSON.parse(text);
+  } catch {
+    parsed = text;
+  }
+  // Header names are canonical, like Content-Type
+  const responseHeaders = {};
+  response.headers.forEach((value, name) => {
+    responseHeaders[name.replace(/(^|-)[a-z]/g, (c) => c.toUpperCase())] = value;
+  });
+  return {
+    status: response.status,
+    body: parsed,
+    headers: responseHeaders,
+    duration_ms: Date.now() - start,
+    content_type: response.headers.get("content-type") ?? "",
+  };
+}
+
+// sleep waits for a number of milliseconds
+function sleep(ms) {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+// uuid returns a random UUID
+function uuid() {
+  return crypto.randomUUID();
+}
+`
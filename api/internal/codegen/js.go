@@ -0,0 +1,285 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shared by the JavaScript targets, Jest, Vitest and k6
+
+// jsTemplate converts a template to a JavaScript string expression
+func jsTemplate(t Template) string {
+	if text, ok := t.Literal(); ok {
+		return jsonString(text)
+	}
+	parts := make([]string, 0, len(t))
+	for _, part := range t {
+		switch part.Kind {
+		case PartVariable:
+			parts = append(parts, fmt.Sprintf("env(%s)", jsonString(part.Text)))
+		case PartBuiltin:
+			parts = append(parts, fmt.Sprintf("builtin(%s)", jsonString(part.Text)))
+		case PartOutput:
+			parts = append(parts, fmt.Sprintf("ref(outputs, %s, %s)", jsonString(part.Step), jsonString(part.Path)))
+		default:
+			parts = append(parts, jsonString(part.Text))
+		}
+	}
+	return strings.Join(parts, " + ")
+}
+
+// jsValue converts a config value to a JavaScript literal
+func jsValue(v interface{}, depth int) string {
+	indent := strings.Repeat("  ", depth)
+	switch v := v.(type) {
+	case Template:
+		return jsTemplate(v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for _, key := range sortedKeys(v) {
+			sb.WriteString(fmt.Sprintf("%s  %s: %s,\n", indent, jsKey(key), jsValue(v[key], depth+1)))
+		}
+		sb.WriteString(indent + "}")
+		return sb.String()
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var sb strings.Builder
+		sb.WriteString("[\n")
+		for _, item := range v {
+			sb.WriteString(fmt.Sprintf("%s  %s,\n", indent, jsValue(item, depth+1)))
+		}
+		sb.WriteString(indent + "]")
+		return sb.String()
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// jsKey quotes object keys that are not identifiers
+func jsKey(key string) string {
+	if jsIdentPattern.MatchString(key) {
+		return key
+	}
+	return jsonString(key)
+}
+
+// jsData converts the data of an assert step to a JavaScript object
+func jsData(v interface{}, depth int) string {
+	if t, ok := v.(Template); ok {
+		if len(t) == 1 && t[0].Kind == PartOutput {
+			return fmt.Sprintf("toObject(output(outputs, %s, %s))", jsonString(t[0].Step), jsonString(t[0].Path))
+		}
+		return fmt.Sprintf("{ value: %s }", jsTemplate(t))
+	}
+	if _, ok := v.(map[string]interface{}); ok {
+		return jsValue(v, depth)
+	}
+	return fmt.Sprintf("{ value: %s }", jsValue(v, depth))
+}
+
+// jsPath converts a path to an array of keys and indexes
+func jsPath(path []interface{}) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		if s, ok := key.(string); ok {
+			keys[i] = jsonString(s)
+		} else {
+			keys[i] = fmt.Sprint(key)
+		}
+	}
+	return "[" + strings.Join(keys, ", ") + "]"
+}
+
+// jsRequestOptions lists the headers, body and timeout of a request
+func jsRequestOptions(s *SuiteStep, depth int, timeout string) []string {
+	indent := strings.Repeat("  ", depth)
+	var options []string
+	if len(s.Headers) > 0 {
+		var sb strings.Builder
+		sb.WriteString("headers: {\n")
+		for _, h := range s.Headers {
+			sb.WriteString(fmt.Sprintf("%s  %s: %s,\n", indent, jsKey(h.Name), jsTemplate(h.Value)))
+		}
+		sb.WriteString(indent + "}")
+		options = append(options, sb.String())
+	}
+	if s.Body != nil {
+		options = append(options, "body: "+jsValue(s.Body, depth))
+	}
+	if s.Timeout > 0 {
+		options = append(options, "timeout: "+timeout)
+	}
+	return options
+}
+
+// jsDefaults writes the variables of a suite
+func jsDefaults(w *writer, suite *Suite) {
+	w.line(0, "// Values of the variables of the flow and its environment. Variables of the")
+	w.line(0, "// process environment override them.")
+	w.line(0, "const defaults = {")
+	for _, v := range suite.Variables {
+		if v.Secret {
+			w.line(1, "%s: \"\", // Secret: set %s in the environment", jsKey(v.Name), v.Name)
+		} else {
+			w.line(1, "%s: %s,", jsKey(v.Name), jsonString(v.Value))
+		}
+	}
+	w.line(0, "};")
+}
+
+// jsHelperFuncs are the helpers of both runtimes, in the order they are
+// written; template literals are avoided, since the code is kept in raw
+// strings
+var jsHelperFuncs = []struct{ name, code string }{
+	{"builtin", `// builtin returns a built-in variable of TestMesh, like UUID or TIMESTAMP
+function builtin(name) {
+  const now = new Date();
+  const pad = (n) => String(n).padStart(2, "0");
+  const date = now.getFullYear() + "-" + pad(now.getMonth() + 1) + "-" + pad(now.getDate());
+  const time = pad(now.getHours()) + ":" + pad(now.getMinutes()) + ":" + pad(now.getSeconds());
+  switch (name) {
+    case "UUID":
+    case "RANDOM_ID":
+      return uuid();
+    case "TIMESTAMP":
+      return String(Math.floor(now.getTime() / 1000));
+    case "ISO_TIMESTAMP":
+      return now.toISOString().replace(/\.\d{3}Z$/, "Z");
+    case "DATE":
+      return date;
+    case "TIME":
+      return time;
+    case "DATETIME":
+      return date + " " + time;
+    case "YEAR":
+      return String(now.getFullYear());
+    case "MONTH":
+      return pad(now.getMonth() + 1);
+    case "DAY":
+      return pad(now.getDate());
+    case "HOUR":
+      return pad(now.getHours());
+    case "MINUTE":
+      return pad(now.getMinutes());
+    case "SECOND":
+      return pad(now.getSeconds());
+  }
+  return "";
+}
+`},
+	{"output", `// output returns an output of a step: a named output, or a path below a
+// field of its result, like body.token
+function output(outputs, step, path) {
+  const values = outputs[step] || {};
+  if (path in values) {
+    return values[path];
+  }
+  const [first, ...rest] = path.split(".");
+  let value = values[first];
+  for (const key of rest) {
+    if (value === null || typeof value !== "object" || Array.isArray(value)) {
+      return undefined;
+    }
+    value = value[key];
+  }
+  return value;
+}
+`},
+	{"ref", `// ref returns an output of a step as text, like ${login.token}
+function ref(outputs, step, path) {
+  const value = output(outputs, step, path);
+  return value === undefined ? "${" + step + "." + path + "}" : String(value);
+}
+`},
+	{"dig", `// dig returns the value at a path of keys and indexes of nested objects and
+// arrays, or undefined
+function dig(value, path) {
+  for (const key of path) {
+    if (value === null || typeof value !== "object") {
+      return undefined;
+    }
+    value = value[key];
+  }
+  return value;
+}
+`},
+	{"toObject", `// toObject returns an object, or an object holding a value that is not one
+function toObject(value) {
+  return value !== null && typeof value === "object" && !Array.isArray(value) ? value : { value };
+}
+`},
+	{"equal", `// equal compares values deeply
+function equal(a, b) {
+  return JSON.stringify(a) === JSON.stringify(b);
+}
+`},
+	{"has", `// has reports whether a string contains a substring, an array an element, or
+// an object a key
+function has(container, item) {
+  if (typeof container === "string") {
+    return container.includes(String(item));
+  }
+  if (Array.isArray(container)) {
+    return container.some((value) => equal(value, item));
+  }
+  if (container !== null && typeof container === "object") {
+    return Object.prototype.hasOwnProperty.call(container, String(item));
+  }
+  return false;
+}
+`},
+	{"len", `// len returns the length of a string, array or object
+function len(value) {
+  if (typeof value === "string" || Array.isArray(value)) {
+    return value.length;
+  }
+  if (value !== null && typeof value === "object") {
+    return Object.keys(value).length;
+  }
+  return 0;
+}
+`},
+}
+
+// jsHelperDeps are the helpers other helpers call
+var jsHelperDeps = map[string][]string{"ref": {"output"}, "has": {"equal"}}
+
+// jsHelpers are the helpers written into generated files
+var jsHelpers = "\n" + jsHelperCode(nil)
+
+// jsHelperCode returns the code of helpers and the helpers they call, or of
+// all helpers
+func jsHelperCode(names map[string]bool) string {
+	for name := range names {
+		for _, dep := range jsHelperDeps[name] {
+			names[dep] = true
+		}
+	}
+	var code []string
+	for _, helper := range jsHelperFuncs {
+		if names == nil || names[helper.name] {
+			code = append(code, helper.code)
+		}
+	}
+	return strings.Join(code, "\n")
+}
+
+// JSHelpers returns the code of JavaScript helpers that translated
+// assertions call, like equal and len
+func JSHelpers(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return jsHelperCode(set)
+}
@@ -0,0 +1,256 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// K6Generator generates a k6 load test script
+type K6Generator struct{}
+
+func (g *K6Generator) Name() string { return "k6" }
+
+func (g *K6Generator) Filename(suite *Suite) string {
+	return slug(suite.Name, "-") + ".k6.js"
+}
+
+func (g *K6Generator) GenerateFlow(suite *Suite) (string, error) {
+	w := &writer{indent: "  "}
+	names := stepNames(suite, false)
+
+	// k6 passes the data of setup to the iterations and to teardown, but
+	// nothing from the iterations to teardown
+	main := make(map[string]bool)
+	for _, s := range suite.Steps {
+		main[s.ID] = true
+	}
+	for _, s := range suite.Teardown {
+		for _, step := range referencedSteps(&s) {
+			if main[step] {
+				suite.Warnings = append(suite.Warnings, fmt.Sprintf("step %s: k6 does not pass outputs of step %s to teardown", s.ID, step))
+				main[step] = false
+			}
+		}
+	}
+
+	w.line(0, "// Load test of flow %s, generated by testmesh.", jsonString(suite.Name))
+	if suite.Description != "" {
+		w.line(0, "// %s", comment(suite.Description))
+	}
+	w.line(0, "import http from \"k6/http\";")
+	w.line(0, "import { check, fail, group, sleep } from \"k6\";")
+	w.line(0, "")
+	w.line(0, "export const options = {")
+	w.line(1, "thresholds: {")
+	w.line(2, "checks: [\"rate==1.0\"],")
+	w.line(1, "},")
+	w.line(0, "};")
+	w.line(0, "")
+	jsDefaults(w, suite)
+	w.line(0, "")
+
+	w.line(0, "// setup runs the setup steps of the flow once, before the iterations")
+	w.line(0, "export function setup() {")
+	w.line(1, "const outputs = {};")
+	for i := range suite.Setup {
+		g.call(w, names[&suite.Setup[i]], "fail("+jsonString("setup step "+suite.Setup[i].ID+" failed")+");")
+	}
+	w.line(1, "return outputs;")
+	w.line(0, "}")
+	w.line(0, "")
+
+	w.line(0, "// Each iteration runs the steps of the flow, until one fails")
+	w.line(0, "export default function (data) {")
+	w.line(1, "const outputs = { ...data };")
+	for i := range suite.Steps {
+		w.line(1, "if (!group(%s, () => %s(outputs))) {", jsonString(suite.Steps[i].label()), names[&suite.Steps[i]])
+		w.line(2, "return;")
+		w.line(1, "}")
+	}
+	w.line(0, "}")
+
+	if len(suite.Teardown) > 0 {
+		w.line(0, "")
+		w.line(0, "// teardown runs the teardown steps of the flow once, after the iterations")
+		w.line(0, "export function teardown(data) {")
+		w.line(1, "const outputs = { ...data };")
+		for i := range suite.Teardown {
+			g.call(w, names[&suite.Teardown[i]], "return;")
+		}
+		w.line(0, "}")
+	}
+
+	for _, phase := range [][]SuiteStep{suite.Setup, suite.Steps, suite.Teardown} {
+		for i := range phase {
+			w.line(0, "")
+			g.step(w, &phase[i], names[&phase[i]])
+		}
+	}
+
+	w.line(0, "")
+	w.raw(k6Helpers)
+	w.raw(jsHelpers)
+	return w.String(), nil
+}
+
+// call runs a step of setup or teardown, and stops them when it fails
+func (g *K6Generator) call(w *writer, name, failed string) {
+	w.line(1, "if (!%s(outputs)) {", name)
+	w.line(2, "%s", failed)
+	w.line(1, "}")
+}
+
+// step writes the function of a step, which returns whether its checks
+// passed
+func (g *K6Generator) step(w *writer, s *SuiteStep, name string) {
+	w.line(0, "// %s", comment(s.label()))
+	w.line(0, "function %s(outputs) {", name)
+
+	switch s.Kind {
+	case StepHTTP:
+		options := jsRequestOptions(s, 2, jsonString(s.Timeout.String()))
+		options = append(options, "name: "+jsonString(s.ID))
+		w.line(1, "const result = request(%s, %s, {", jsTemplate(s.Method), jsTemplate(s.URL))
+		for _, option := range options {
+			w.line(2, "%s,", option)
+		}
+		w.line(1, "});")
+		g.checks(w, s.Assertions, "result")
+		for _, o := range s.Outputs {
+			w.line(1, "result[%s] = dig(result, %s);", jsonString(o.Name), jsPath(o.Path))
+		}
+		if s.Referenced || len(s.Outputs) > 0 {
+			w.line(1, "outputs[%s] = result;", jsonString(s.ID))
+		}
+
+	case StepLog:
+		w.line(1, "const message = %s;", jsTemplate(s.Message))
+		w.line(1, "console.%s(message);", jsConsole(s.Level))
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { message, level: %s, logged: true };", jsonString(s.ID), jsonString(s.Level))
+		}
+
+	case StepDelay:
+		w.line(1, "sleep(%s);", strconv.FormatFloat(s.Duration.Seconds(), 'g', -1, 64))
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { duration: %s, duration_ms: %d, completed: true };",
+				jsonString(s.ID), jsonString(s.Duration.String()), s.Duration.Milliseconds())
+		}
+
+	case StepAssert:
+		w.line(1, "const data = %s;", jsData(s.Data, 1))
+		g.checks(w, s.Assertions, "data")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = { assertions_count: %d, passed: true };", jsonString(s.ID), len(s.Assertions))
+		}
+
+	default:
+		w.line(1, "// TODO: %s steps are not generated", comment(s.Action))
+	}
+	w.line(1, "return true;")
+	w.line(0, "}")
+}
+
+// checks writes the check of the assertions of a step, which ends the step
+// when it fails
+func (g *K6Generator) checks(w *writer, assertions []Assertion, root string) {
+	translated := 0
+	for _, a := range assertions {
+		if a.Node == nil {
+			w.line(1, "// TODO: translate assertion %s", comment(a.Source))
+		} else {
+			translated++
+		}
+	}
+	if translated == 0 {
+		return
+	}
+
+	w.line(1, "const passed = check(%s, {", root)
+	for _, a := range assertions {
+		if a.Node != nil {
+			w.line(2, "%s: (r) => %s,", jsonString(a.Source), jsExpr(a.Node, "r"))
+		}
+	}
+	w.line(1, "});")
+	w.line(1, "if (!passed) {")
+	w.line(2, "return false;")
+	w.line(1, "}")
+}
+
+// referencedSteps returns the steps whose outputs a step references
+func referencedSteps(s *SuiteStep) []string {
+	var steps []string
+	var visit func(v interface{})
+	visit = func(v interface{}) {
+		switch v := v.(type) {
+		case Template:
+			for _, part := range v {
+				if part.Kind == PartOutput {
+					steps = append(steps, part.Step)
+				}
+			}
+		case map[string]interface{}:
+			for _, item := range v {
+				visit(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				visit(item)
+			}
+		}
+	}
+	visit(s.URL)
+	visit(s.Body)
+	visit(s.Message)
+	visit(s.Data)
+	for _, h := range s.Headers {
+		visit(h.Value)
+	}
+	return steps
+}
+
+const k6Helpers = `// env returns a variable of the environment, or its default
+function env(name) {
+  return __ENV[name] ?? defaults[name] ?? "";
+}
+
+// request sends a request like the http_request action, and returns the
+// result of the action: status, body, headers, duration_ms and content_type
+function request(method, url, { headers = {}, body, timeout, name } = {}) {
+  const params = { headers: { ...headers }, tags: { name } };
+  if (timeout) {
+    params.timeout = timeout;
+  }
+  let payload = null;
+  if (body !== undefined) {
+    payload = JSON.stringify(body);
+    if (!Object.keys(params.headers).some((header) => header.toLowerCase() === "content-type")) {
+      params.headers["Content-Type"] = "application/json";
+    }
+  }
+
+  const response = http.request(method, url, payload, params);
+  let parsed;
+  try {
+    parsed = response.json();
+  } catch (e) {
+    parsed = response.body;
+  }
+  return {
+    status: response.status,
+    body: parsed,
+    headers: response.headers,
+    duration_ms: Math.round(response.timings.duration),
+    content_type: response.headers["Content-Type"] || "",
+  };
+}
+
+// uuid returns a random UUID
+function uuid() {
+  return "xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx".replace(/[xy]/g, (c) => {
+    const r = (Math.random() * 16) | 0;
+    return (c === "x" ? r : (r & 0x3) | 0x8).toString(16);
+  });
+}
+`
@@ -0,0 +1,362 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PytestGenerator generates a pytest module with requests
+type PytestGenerator struct{}
+
+func (g *PytestGenerator) Name() string { return "pytest" }
+
+func (g *PytestGenerator) Filename(suite *Suite) string {
+	return "test_" + slug(suite.Name, "_") + ".py"
+}
+
+func (g *PytestGenerator) GenerateFlow(suite *Suite) (string, error) {
+	w := &writer{indent: "    "}
+	names := stepNames(suite, true)
+
+	w.line(0, `"""Tests of flow %s, generated by testmesh.`, pyDocstring(suite.Name))
+	if suite.Description != "" {
+		w.line(0, "")
+		w.line(0, "%s", pyDocstring(suite.Description))
+	}
+	w.line(0, `"""`)
+	w.line(0, "")
+	w.line(0, "import os")
+	if suite.uses("matches") {
+		w.line(0, "import re")
+	}
+	if suite.has(StepDelay) {
+		w.line(0, "import time")
+	}
+	w.line(0, "import uuid")
+	w.line(0, "from datetime import datetime")
+	w.line(0, "")
+	fixture := len(suite.Setup) > 0 || len(suite.Teardown) > 0
+	if fixture {
+		w.line(0, "import pytest")
+	}
+	w.line(0, "import requests")
+	w.line(0, "")
+
+	// Variables and helpers
+	w.line(0, "# Values of the variables of the flow and its environment. Variables of the")
+	w.line(0, "# process environment override them.")
+	w.line(0, "DEFAULTS = {")
+	for _, v := range suite.Variables {
+		if v.Secret {
+			w.line(1, "%s: \"\",  # Secret: set %s in the environment", jsonString(v.Name), comment(v.Name))
+		} else {
+			w.line(1, "%s: %s,", jsonString(v.Name), jsonString(v.Value))
+		}
+	}
+	w.line(0, "}")
+	w.line(0, "")
+	w.raw(pyHelpers)
+
+	// Setup and teardown run in a fixture around the test
+	test := identifier("test", suite.Name, true)
+	w.line(0, "")
+	w.line(0, "")
+	if fixture {
+		w.line(0, "@pytest.fixture")
+		w.line(0, "def outputs():")
+		w.line(1, `"""Runs the setup steps of the flow before the test, and its teardown steps after it."""`)
+		w.line(1, "outputs = {}")
+		for i := range suite.Setup {
+			w.line(1, "%s(outputs)", names[&suite.Setup[i]])
+		}
+		w.line(1, "yield outputs")
+		for i := range suite.Teardown {
+			w.line(1, "%s(outputs)", names[&suite.Teardown[i]])
+		}
+		w.line(0, "")
+		w.line(0, "")
+		w.line(0, "def %s(outputs):", test)
+	} else {
+		w.line(0, "def %s():", test)
+		w.line(1, "outputs = {}")
+	}
+	if len(suite.Steps) == 0 {
+		w.line(1, "pass")
+	}
+	for i := range suite.Steps {
+		w.line(1, "%s(outputs)", names[&suite.Steps[i]])
+	}
+
+	for _, phase := range [][]SuiteStep{suite.Setup, suite.Steps, suite.Teardown} {
+		for i := range phase {
+			w.line(0, "")
+			w.line(0, "")
+			g.step(w, &phase[i], names[&phase[i]])
+		}
+	}
+	return w.String(), nil
+}
+
+func (g *PytestGenerator) step(w *writer, s *SuiteStep, name string) {
+	w.line(0, "def %s(outputs):", name)
+	w.line(1, `"""Step %s."""`, pyDocstring(s.label()))
+
+	switch s.Kind {
+	case StepHTTP:
+		args := []string{pyTemplate(s.Method), pyTemplate(s.URL)}
+		if len(s.Headers) > 0 {
+			var sb strings.Builder
+			sb.WriteString("headers={\n")
+			for _, h := range s.Headers {
+				sb.WriteString(fmt.Sprintf("            %s: %s,\n", jsonString(h.Name), pyTemplate(h.Value)))
+			}
+			sb.WriteString("        }")
+			args = append(args, sb.String())
+		}
+		if s.Body != nil {
+			args = append(args, "body="+pyValue(s.Body, 2))
+		}
+		if s.Timeout > 0 {
+			args = append(args, "timeout="+strconv.FormatFloat(s.Timeout.Seconds(), 'g', -1, 64))
+		}
+		w.line(1, "result = request(")
+		for _, arg := range args {
+			w.line(2, "%s,", arg)
+		}
+		w.line(1, ")")
+		g.assertions(w, s.Assertions, "result")
+		for _, o := range s.Outputs {
+			w.line(1, "result[%s] = dig(result, %s)", jsonString(o.Name), pyPath(o.Path))
+		}
+		if s.Referenced || len(s.Outputs) > 0 {
+			w.line(1, "outputs[%s] = result", jsonString(s.ID))
+		}
+
+	case StepLog:
+		w.line(1, "message = %s", pyTemplate(s.Message))
+		w.line(1, "print(message)")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = {\"message\": message, \"level\": %s, \"logged\": True}", jsonString(s.ID), jsonString(s.Level))
+		}
+
+	case StepDelay:
+		w.line(1, "time.sleep(%s)", strconv.FormatFloat(s.Duration.Seconds(), 'g', -1, 64))
+		if s.Referenced {
+			w.line(1, "outputs[%s] = {\"duration\": %s, \"duration_ms\": %d, \"completed\": True}",
+				jsonString(s.ID), jsonString(s.Duration.String()), s.Duration.Milliseconds())
+		}
+
+	case StepAssert:
+		w.line(1, "data = %s", pyData(s.Data))
+		g.assertions(w, s.Assertions, "data")
+		if s.Referenced {
+			w.line(1, "outputs[%s] = {\"assertions_count\": %d, \"passed\": True}", jsonString(s.ID), len(s.Assertions))
+		}
+
+	default:
+		w.line(1, "# TODO: %s steps are not generated", comment(s.Action))
+		w.line(1, "pass")
+	}
+}
+
+func (g *PytestGenerator) assertions(w *writer, assertions []Assertion, root string) {
+	for _, a := range assertions {
+		if a.Node == nil {
+			w.line(1, "# TODO: translate assertion %s", comment(a.Source))
+			continue
+		}
+		w.line(1, "assert %s, %s", pyExpr(a.Node, root), jsonString(a.Source))
+	}
+}
+
+// pyTemplate converts a template to a Python string expression
+func pyTemplate(t Template) string {
+	if text, ok := t.Literal(); ok {
+		return jsonString(text)
+	}
+	parts := make([]string, 0, len(t))
+	for _, part := range t {
+		switch part.Kind {
+		case PartVariable:
+			parts = append(parts, fmt.Sprintf("env(%s)", jsonString(part.Text)))
+		case PartBuiltin:
+			parts = append(parts, fmt.Sprintf("builtin(%s)", jsonString(part.Text)))
+		case PartOutput:
+			parts = append(parts, fmt.Sprintf("ref(outputs, %s, %s)", jsonString(part.Step), jsonString(part.Path)))
+		default:
+			parts = append(parts, jsonString(part.Text))
+		}
+	}
+	return strings.Join(parts, " + ")
+}
+
+// pyValue converts a config value to a Python literal
+func pyValue(v interface{}, depth int) string {
+	indent := strings.Repeat("    ", depth)
+	switch v := v.(type) {
+	case Template:
+		return pyTemplate(v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for _, key := range sortedKeys(v) {
+			sb.WriteString(fmt.Sprintf("%s    %s: %s,\n", indent, jsonString(key), pyValue(v[key], depth+1)))
+		}
+		sb.WriteString(indent + "}")
+		return sb.String()
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var sb strings.Builder
+		sb.WriteString("[\n")
+		for _, item := range v {
+			sb.WriteString(fmt.Sprintf("%s    %s,\n", indent, pyValue(item, depth+1)))
+		}
+		sb.WriteString(indent + "]")
+		return sb.String()
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return formatFloat(v)
+	case nil:
+		return "None"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// pyData converts the data of an assert step to a Python dict
+func pyData(v interface{}) string {
+	if t, ok := v.(Template); ok {
+		if len(t) == 1 && t[0].Kind == PartOutput {
+			return fmt.Sprintf("to_dict(output(outputs, %s, %s))", jsonString(t[0].Step), jsonString(t[0].Path))
+		}
+		return fmt.Sprintf("{\"value\": %s}", pyTemplate(t))
+	}
+	if _, ok := v.(map[string]interface{}); ok {
+		return pyValue(v, 1)
+	}
+	return fmt.Sprintf("{\"value\": %s}", pyValue(v, 1))
+}
+
+// pyPath converts a path to a list of keys and indexes
+func pyPath(path []interface{}) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		if s, ok := key.(string); ok {
+			keys[i] = jsonString(s)
+		} else {
+			keys[i] = fmt.Sprint(key)
+		}
+	}
+	return "[" + strings.Join(keys, ", ") + "]"
+}
+
+// pyDocstring escapes text for a docstring
+func pyDocstring(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return strings.ReplaceAll(text, `"""`, `\"\"\"`)
+}
+
+const pyHelpers = `
+
+def env(name):
+    """Returns a variable of the environment, or its default."""
+    return os.environ.get(name, DEFAULTS.get(name, ""))
+
+
+def builtin(name):
+    """Returns a built-in variable of TestMesh, like UUID or TIMESTAMP."""
+    now = datetime.now()
+    if name in ("UUID", "RANDOM_ID"):
+        return str(uuid.uuid4())
+    if name == "TIMESTAMP":
+        return str(int(now.timestamp()))
+    if name == "ISO_TIMESTAMP":
+        return now.astimezone().isoformat(timespec="seconds")
+    formats = {
+        "DATE": "%Y-%m-%d",
+        "TIME": "%H:%M:%S",
+        "DATETIME": "%Y-%m-%d %H:%M:%S",
+        "YEAR": "%Y",
+        "MONTH": "%m",
+        "DAY": "%d",
+        "HOUR": "%H",
+        "MINUTE": "%M",
+        "SECOND": "%S",
+    }
+    return now.strftime(formats[name])
+
+
+_MISSING = object()
+
+
+def output(outputs, step, path):
+    """Returns an output of a step: a named output, or a path below a field of
+    its result, like body.token."""
+    values = outputs.get(step, {})
+    if path in values:
+        return values[path]
+    keys = path.split(".")
+    value = values.get(keys[0], _MISSING)
+    for key in keys[1:]:
+        if not isinstance(value, dict):
+            return _MISSING
+        value = value.get(key, _MISSING)
+    return value
+
+
+def ref(outputs, step, path):
+    """Returns an output of a step as text, like ${login.token}."""
+    value = output(outputs, step, path)
+    if value is _MISSING:
+        return "${%s.%s}" % (step, path)
+    return str(value)
+
+
+def request(method, url, headers=None, body=_MISSING, timeout=None):
+    """Sends a request like the http_request action, and returns the result of
+    the action: status, body, headers, duration_ms and content_type."""
+    kwargs = {"headers": headers, "timeout": timeout}
+    if body is not _MISSING:
+        kwargs["json"] = body
+    response = requests.request(method, url, **kwargs)
+    try:
+        parsed = response.json()
+    except ValueError:
+        parsed = response.text
+    return {
+        "status": response.status_code,
+        "body": parsed,
+        "headers": response.headers,
+        "duration_ms": int(response.elapsed.total_seconds() * 1000),
+        "content_type": response.headers.get("Content-Type", ""),
+    }
+
+
+def dig(value, path):
+    """Returns the value at a path of keys and indexes of nested dicts and
+    lists, or None."""
+    for key in path:
+        if isinstance(value, dict):
+            value = value.get(key)
+        elif isinstance(value, list) and isinstance(key, int) and 0 <= key < len(value):
+            value = value[key]
+        else:
+            return None
+    return value
+
+
+def to_dict(value):
+    """Returns a dict, or a dict holding a value that is not a dict."""
+    if isinstance(value, dict):
+        return value
+    return {"value": None if value is _MISSING else value}
+`
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionBuffer is how many live events a subscriber may fall behind
+// before it is dropped
+const subscriptionBuffer = 256
+
+// Subscription receives the events passing its filter. Events is closed when
+// the subscription is closed, or when the subscriber falls too far behind;
+// it can then subscribe again with Since set to the last sequence it saw.
+type Subscription struct {
+	Events <-chan *Event
+
+	events chan *Event
+	filter Filter
+	broker *broker
+	once   sync.Once
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// replay holds the recent events of an execution for late subscribers, and
+// when the execution finished
+type replay struct {
+	events     []*Event
+	finishedAt time.Time
+}
+
+// broker fans events out to the subscribers of one API server and keeps
+// recent events of every execution for replay. Both buses deliver through a
+// broker: the memory bus directly, the Postgres bus for each notification.
+type broker struct {
+	replaySize int
+	retention  time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	replays     map[uuid.UUID]*replay
+	sequences   map[uuid.UUID]uint64 // Last sequence number published per execution
+	lastSweep   time.Time
+}
+
+func newBroker(replaySize int, retention time.Duration) *broker {
+	return &broker{
+		replaySize:  replaySize,
+		retention:   retention,
+		subscribers: make(map[*Subscription]struct{}),
+		replays:     make(map[uuid.UUID]*replay),
+		sequences:   make(map[uuid.UUID]uint64),
+	}
+}
+
+// number assigns the next sequence number of the event's execution. Only the
+// server running an execution publishes its events, so numbers are local.
+func (b *broker) number(event *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequences[event.ExecutionID]++
+	event.Seq = b.sequences[event.ExecutionID]
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+}
+
+// deliver buffers an event for replay and sends it to matching subscribers
+func (b *broker) deliver(event *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.sweep(now)
+
+	r := b.replays[event.ExecutionID]
+	if r == nil {
+		r = &replay{}
+		b.replays[event.ExecutionID] = r
+	}
+	if b.replaySize > 0 {
+		r.events = append(r.events, event)
+		if len(r.events) > b.replaySize {
+			r.events = r.events[len(r.events)-b.replaySize:]
+		}
+	}
+	if event.final() {
+		r.finishedAt = now
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// The subscriber fell behind; it resumes by subscribing again
+			b.drop(sub)
+		}
+	}
+}
+
+// subscribe registers a subscriber, replaying buffered events first. Both
+// happen under the lock, so no event is missed or delivered twice.
+func (b *broker) subscribe(filter Filter) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []*Event
+	if filter.Replay {
+		backlog = b.backlog(filter)
+	}
+
+	events := make(chan *Event, len(backlog)+subscriptionBuffer)
+	for _, event := range backlog {
+		events <- event
+	}
+	sub := &Subscription{Events: events, events: events, filter: filter, broker: b}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// backlog returns the buffered events passing a filter, merged across
+// executions in the order they were published. Must be called with the lock
+// held.
+func (b *broker) backlog(filter Filter) []*Event {
+	var queues [][]*Event
+	for id, r := range b.replays {
+		if filter.ExecutionID != uuid.Nil && id != filter.ExecutionID {
+			continue
+		}
+		var queue []*Event
+		for _, event := range r.events {
+			if event.Seq > filter.after(id) && filter.Matches(event) {
+				queue = append(queue, event)
+			}
+		}
+		if len(queue) > 0 {
+			queues = append(queues, queue)
+		}
+	}
+
+	var backlog []*Event
+	for len(queues) > 0 {
+		// Events of an execution stay in sequence; executions are merged by time
+		next := 0
+		for i := range queues {
+			if queues[i][0].Timestamp.Before(queues[next][0].Timestamp) {
+				next = i
+			}
+		}
+		backlog = append(backlog, queues[next][0])
+		if queues[next] = queues[next][1:]; len(queues[next]) == 0 {
+			queues = append(queues[:next], queues[next+1:]...)
+		}
+	}
+	return backlog
+}
+
+func (b *broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(sub)
+}
+
+// drop removes a subscriber. Must be called with the lock held.
+func (b *broker) drop(sub *Subscription) {
+	delete(b.subscribers, sub)
+	sub.once.Do(func() { close(sub.events) })
+}
+
+// sweep drops the events and sequence numbers of executions that finished
+// longer than the retention ago. Must be called with the lock held.
+func (b *broker) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+	b.lastSweep = now
+	for id, r := range b.replays {
+		if !r.finishedAt.IsZero() && now.Sub(r.finishedAt) > b.retention {
+			delete(b.replays, id)
+			delete(b.sequences, id)
+		}
+	}
+}
+
+// close ends every subscription
+func (b *broker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		b.drop(sub)
+	}
+}
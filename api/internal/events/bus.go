@@ -0,0 +1,90 @@
+// Package events carries execution and debug events from the runner to
+// WebSocket clients. The in-memory bus serves a single API server; the
+// Postgres bus uses LISTEN/NOTIFY so that clients connected to any replica
+// receive the events of executions running on every other replica.
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Bus backends
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// Event is an execution or debug event
+type Event struct {
+	Seq         uint64                 `json:"seq"` // Order of the event within its execution, from 1
+	Type        string                 `json:"type"`
+	WorkspaceID uuid.UUID              `json:"workspace_id"`
+	ExecutionID uuid.UUID              `json:"execution_id"`
+	Data        map[string]interface{} `json:"data"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// final reports whether an event ends its execution. The events of finished
+// executions are replayed for a while, then dropped.
+func (e *Event) final() bool {
+	return e.Type == "execution.completed" || e.Type == "execution.failed"
+}
+
+// Filter selects the events of a subscription
+type Filter struct {
+	WorkspaceID uuid.UUID // uuid.Nil for every workspace
+	ExecutionID uuid.UUID // uuid.Nil for every execution
+	Replay      bool      // Deliver the buffered events of matching executions first
+	Since       uint64    // With Replay, skip events up to this sequence number
+	// With Replay, skip the events of each execution up to its sequence
+	// number, in place of Since. Sequence numbers are per execution, so a
+	// stream of several executions resumes with one cursor per execution.
+	Cursors map[uuid.UUID]uint64
+}
+
+// after returns the sequence number replayed events of an execution must follow
+func (f Filter) after(executionID uuid.UUID) uint64 {
+	if seq, ok := f.Cursors[executionID]; ok {
+		return seq
+	}
+	return f.Since
+}
+
+// Matches reports whether an event passes the filter
+func (f Filter) Matches(event *Event) bool {
+	if f.WorkspaceID != uuid.Nil && event.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	if f.ExecutionID != uuid.Nil && event.ExecutionID != f.ExecutionID {
+		return false
+	}
+	return true
+}
+
+// Bus publishes events to the subscribers of every API server sharing it
+type Bus interface {
+	// Publish numbers an event within its execution and delivers it
+	Publish(event *Event) error
+	// Subscribe delivers the events passing a filter until the subscription
+	// is closed
+	Subscribe(filter Filter) *Subscription
+	Close() error
+}
+
+// NewBus creates the bus selected by the configuration
+func NewBus(cfg config.EventsConfig, db *gorm.DB, dbCfg config.DatabaseConfig, logger *zap.Logger) (Bus, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemoryBus(cfg), nil
+	case BackendPostgres:
+		return NewPostgresBus(cfg, db, dbCfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown event bus backend %q (expected memory or postgres)", cfg.Backend)
+	}
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+)
+
+// MemoryBus delivers events within a single API server
+type MemoryBus struct {
+	broker *broker
+}
+
+// NewMemoryBus creates an in-memory bus
+func NewMemoryBus(cfg config.EventsConfig) *MemoryBus {
+	return &MemoryBus{broker: newBroker(cfg.ReplaySize, cfg.Retention)}
+}
+
+// Publish delivers an event to the subscribers of this server
+func (b *MemoryBus) Publish(event *Event) error {
+	b.broker.number(event)
+	b.broker.deliver(event)
+	return nil
+}
+
+// Subscribe delivers the events passing a filter
+func (b *MemoryBus) Subscribe(filter Filter) *Subscription {
+	return b.broker.subscribe(filter)
+}
+
+// Close ends every subscription
+func (b *MemoryBus) Close() error {
+	b.broker.close()
+	return nil
+}
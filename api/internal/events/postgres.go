@@ -0,0 +1,208 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+	"github.com/georgi-georgiev/testmesh/internal/shared/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// notifyLimit is the largest payload sent with NOTIFY. Postgres rejects
+// payloads of 8000 bytes or more; larger events go through
+// executions.event_overflow.
+const notifyLimit = 7900
+
+// overflowTTL is how long overflowed payloads are kept for slow replicas
+const overflowTTL = 10 * time.Minute
+
+// notification is the payload of a NOTIFY: the event, or the ID of its
+// overflow row
+type notification struct {
+	Event    *Event     `json:"event,omitempty"`
+	Overflow *uuid.UUID `json:"overflow,omitempty"`
+}
+
+// PostgresBus delivers events to every API server listening on the same
+// channel of the database
+type PostgresBus struct {
+	db       *gorm.DB
+	channel  string
+	broker   *broker
+	listener *pq.Listener
+	queue    chan *Event
+	logger   *zap.Logger
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewPostgresBus listens on the configured channel and starts delivering
+// notifications
+func NewPostgresBus(cfg config.EventsConfig, db *gorm.DB, dbCfg config.DatabaseConfig, logger *zap.Logger) (*PostgresBus, error) {
+	if cfg.Channel == "" {
+		return nil, fmt.Errorf("events channel is required for the postgres bus")
+	}
+
+	b := &PostgresBus{
+		db:      db,
+		channel: cfg.Channel,
+		broker:  newBroker(cfg.ReplaySize, cfg.Retention),
+		queue:   make(chan *Event, 1024),
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+	b.listener = pq.NewListener(database.DSN(dbCfg), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Event bus connection error", zap.Error(err))
+		}
+	})
+	if err := b.listener.Listen(cfg.Channel); err != nil {
+		b.listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Channel, err)
+	}
+
+	b.wg.Add(2)
+	go b.send()
+	go b.listen()
+	return b, nil
+}
+
+// Publish numbers an event and queues it for NOTIFY. Events are sent in the
+// order they are published.
+func (b *PostgresBus) Publish(event *Event) error {
+	b.broker.number(event)
+	select {
+	case b.queue <- event:
+		return nil
+	case <-b.done:
+		return errors.New("event bus is closed")
+	}
+}
+
+// Subscribe delivers the events passing a filter, from every server
+func (b *PostgresBus) Subscribe(filter Filter) *Subscription {
+	return b.broker.subscribe(filter)
+}
+
+// Close stops listening and ends every subscription
+func (b *PostgresBus) Close() error {
+	b.once.Do(func() { close(b.done) })
+	b.wg.Wait()
+	b.broker.close()
+	return b.listener.Close()
+}
+
+// send notifies the channel of queued events. Events that cannot be sent
+// are still delivered to the subscribers of this server.
+func (b *PostgresBus) send() {
+	defer b.wg.Done()
+	for {
+		select {
+		case <-b.done:
+			return
+		case event := <-b.queue:
+			if err := b.notify(event); err != nil {
+				b.logger.Warn("Failed to publish event",
+					zap.String("type", event.Type),
+					zap.String("execution_id", event.ExecutionID.String()),
+					zap.Error(err),
+				)
+				b.broker.deliver(event)
+			}
+		}
+	}
+}
+
+func (b *PostgresBus) notify(event *Event) error {
+	payload, err := json.Marshal(notification{Event: event})
+	if err != nil {
+		return err
+	}
+	if len(payload) > notifyLimit {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		id := uuid.New()
+		if err := b.db.Exec("INSERT INTO executions.event_overflow (id, payload) VALUES (?, ?)", id, string(data)).Error; err != nil {
+			return fmt.Errorf("failed to store event payload: %w", err)
+		}
+		if payload, err = json.Marshal(notification{Overflow: &id}); err != nil {
+			return err
+		}
+	}
+	return b.db.Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+// listen delivers notifications, including those this server sent, and
+// removes expired overflow payloads
+func (b *PostgresBus) listen() {
+	defer b.wg.Done()
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+	cleanup := time.NewTicker(time.Minute)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+
+		case n := <-b.listener.Notify:
+			if n == nil {
+				// The connection was re-established; notifications sent while it
+				// was down are lost
+				b.logger.Warn("Event bus reconnected; events may have been missed")
+				continue
+			}
+			event, err := b.decode(n.Extra)
+			if err != nil {
+				b.logger.Warn("Failed to decode event", zap.Error(err))
+				continue
+			}
+			b.broker.deliver(event)
+
+		case <-ping.C:
+			go b.listener.Ping()
+
+		case <-cleanup.C:
+			if err := b.db.Exec("DELETE FROM executions.event_overflow WHERE created_at < ?", time.Now().Add(-overflowTTL)).Error; err != nil {
+				b.logger.Warn("Failed to remove expired event payloads", zap.Error(err))
+			}
+		}
+	}
+}
+
+// decode reads the event of a notification, loading overflowed payloads
+func (b *PostgresBus) decode(payload string) (*Event, error) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return nil, err
+	}
+	if n.Event != nil {
+		return n.Event, nil
+	}
+	if n.Overflow == nil {
+		return nil, errors.New("notification has no event")
+	}
+
+	var data string
+	if err := b.db.Raw("SELECT payload FROM executions.event_overflow WHERE id = ?", *n.Overflow).Row().Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to load event payload %s: %w", *n.Overflow, err)
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
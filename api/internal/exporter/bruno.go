@@ -0,0 +1,342 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Bruno export
+
+// A Bruno collection is a folder of .bru files: collection.bru for the root,
+// folder.bru for each folder and a file per request, named after its step
+
+// brunoPrelude builds the result of an http_request step from the response
+const brunoPrelude = `// result of the request, like the result of an http_request step
+const result = {
+  status: res.status,
+  body: res.body,
+  headers: res.headers,
+  duration_ms: res.responseTime,
+  content_type: res.headers["content-type"] || "",
+};`
+
+func exportToBruno(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
+	result := &ExportResult{
+		Format:   FormatBruno,
+		Filename: "testmesh-collection.bruno.zip",
+		MimeType: "application/zip",
+	}
+	warn := func(format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+	root := buildTree(flows, options.Collections, warn)
+	add := func(name, content string) {
+		result.Files = append(result.Files, ExportedFile{Path: name, Content: content})
+	}
+
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"version": "1",
+		"name":    root.Name,
+		"type":    "collection",
+		"ignore":  []string{"node_modules", ".git"},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bruno collection: %w", err)
+	}
+	add("bruno.json", string(manifest))
+
+	w := &bruWriter{}
+	w.auth(root.Auth)
+	w.vars("vars:pre-request", root.Variables)
+	w.text("docs", root.Description)
+	add("collection.bru", w.String())
+
+	// Variables of the environment go to an environment of the collection
+	if options.IncludeEnv && options.Environment != nil {
+		w := &bruWriter{}
+		env := map[string]interface{}{}
+		var secrets []string
+		for _, v := range exportVariables(&folder{}, options) {
+			if v.Secret {
+				secrets = append(secrets, v.Key)
+			} else {
+				env[v.Key] = v.Value
+			}
+		}
+		w.vars("vars", env)
+		w.list("vars:secret", secrets)
+		add("environments/"+fileName(options.Environment.Name)+".bru", w.String())
+	}
+
+	brunoFolder(root, "", warn, add)
+	return result, nil
+}
+
+// brunoFolder adds the files of the folders and flows of a folder
+func brunoFolder(f *folder, dir string, warn func(format string, args ...interface{}), add func(name, content string)) {
+	used := map[string]bool{"environments": dir == ""}
+	seq := 0
+	for _, child := range f.Folders {
+		seq++
+		childDir := path.Join(dir, uniqueName(fileName(child.Name), used))
+		w := &bruWriter{}
+		w.pairs("meta", [][2]string{{"name", child.Name}, {"seq", fmt.Sprint(seq)}})
+		w.auth(child.Auth)
+		w.vars("vars:pre-request", child.Variables)
+		w.text("docs", child.Description)
+		add(path.Join(childDir, "folder.bru"), w.String())
+		brunoFolder(child, childDir, warn, add)
+	}
+
+	for _, flow := range f.Flows {
+		seq++
+		flowDir := path.Join(dir, uniqueName(fileName(flow.Name), used))
+		w := &bruWriter{}
+		w.pairs("meta", [][2]string{{"name", flow.Name}, {"seq", fmt.Sprint(seq)}})
+		w.vars("vars:pre-request", flow.Definition.Env)
+		w.text("docs", flow.Description)
+		add(path.Join(flowDir, "folder.bru"), w.String())
+
+		flowWarn := func(format string, args ...interface{}) {
+			warn("flow %s: "+format, append([]interface{}{flow.Name}, args...)...)
+		}
+		files := map[string]bool{"folder": true}
+		for i, r := range flowRequests(flow, warn) {
+			name := uniqueName(fileName(r.ID), files)
+			add(path.Join(flowDir, name+".bru"), brunoRequest(r, i+1, flowWarn))
+		}
+	}
+}
+
+// brunoRequest writes the .bru file of a request
+func brunoRequest(r request, seq int, warn func(format string, args ...interface{})) string {
+	w := &bruWriter{}
+	w.pairs("meta", [][2]string{{"name", r.Name}, {"type", "http"}, {"seq", fmt.Sprint(seq)}})
+
+	bodyMode := "none"
+	if r.HasBody {
+		bodyMode = "json"
+	}
+	w.pairs(strings.ToLower(r.Method), [][2]string{
+		{"url", convertReferences(r.URL, mustacheReference)},
+		{"body", bodyMode},
+		{"auth", "inherit"},
+	})
+
+	var headers [][2]string
+	for _, h := range r.Headers {
+		headers = append(headers, [2]string{h.Name, convertReferences(h.Value, mustacheReference)})
+	}
+	w.pairs("headers", headers)
+	if r.HasBody {
+		w.text("body:json", jsonBody(convertValue(r.Body, mustacheReference)))
+	}
+
+	// Outputs are variables set after the response
+	var sets [][2]string
+	for _, set := range r.Sets {
+		sets = append(sets, [2]string{set.Name, brunoResponsePath(set.Path)})
+	}
+	w.pairs("vars:post-response", sets)
+
+	// Simple comparisons are native assertions, the others are tests
+	var asserts [][2]string
+	var tests []string
+	for _, source := range r.Assertions {
+		if key, value, ok := brunoAssert(source); ok {
+			asserts = append(asserts, [2]string{key, value})
+		} else {
+			tests = append(tests, source)
+		}
+	}
+	w.pairs("assert", asserts)
+	script := resultScript(request{ID: r.ID, Assertions: tests}, brunoPrelude, "test", "expect", "bru.setVar", warn)
+	w.text("tests", strings.Join(script, "\n"))
+	w.text("docs", r.Description)
+	return w.String()
+}
+
+// brunoResponsePath converts a path below the result of a step to an
+// expression of the response, like res.body.items[0].id
+func brunoResponsePath(keys []interface{}) string {
+	if len(keys) == 0 {
+		return "res"
+	}
+	var sb strings.Builder
+	switch keys[0] {
+	case "status":
+		sb.WriteString("res.status")
+	case "duration_ms":
+		sb.WriteString("res.responseTime")
+	case "content_type":
+		sb.WriteString(`res.headers["content-type"]`)
+	case "headers":
+		sb.WriteString("res.headers")
+		if len(keys) > 1 {
+			if name, ok := keys[1].(string); ok {
+				fmt.Fprintf(&sb, "[%s]", jsString(strings.ToLower(name)))
+				keys = keys[1:]
+			}
+		}
+	default:
+		sb.WriteString("res.body")
+	}
+	for _, key := range keys[1:] {
+		switch key := key.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", key)
+		default:
+			name := fmt.Sprint(key)
+			if brunoIdentPattern.MatchString(name) {
+				sb.WriteString("." + name)
+			} else {
+				fmt.Fprintf(&sb, "[%s]", jsString(name))
+			}
+		}
+	}
+	return sb.String()
+}
+
+var brunoIdentPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// brunoAssertPattern matches comparisons of a field of the result with a
+// literal, like status == 200
+var brunoAssertPattern = regexp.MustCompile(`^(status|duration_ms|body(?:\.[A-Za-z_]\w*|\[\d+\])*) (==|!=|>=|<=|>|<) (-?\d+(?:\.\d+)?|true|false|"[^"\\]*")$`)
+
+// brunoOperators are the operators of Bruno assertions
+var brunoOperators = map[string]string{
+	"==": "eq", "!=": "neq", ">": "gt", ">=": "gte", "<": "lt", "<=": "lte",
+}
+
+// brunoAssert converts an assertion to a Bruno assertion, like
+// res.status: eq 200. The importer converts it back to the same source.
+func brunoAssert(source string) (string, string, bool) {
+	m := brunoAssertPattern.FindStringSubmatch(source)
+	if m == nil {
+		return "", "", false
+	}
+	key := "res." + m[1]
+	if m[1] == "duration_ms" {
+		key = "res.responseTime"
+	}
+	return key, brunoOperators[m[2]] + " " + m[3], true
+}
+
+// bruWriter writes the blocks of a .bru file
+type bruWriter struct {
+	sb strings.Builder
+}
+
+func (w *bruWriter) start() {
+	if w.sb.Len() > 0 {
+		w.sb.WriteString("\n")
+	}
+}
+
+// pairs writes a block of names and values, unless it is empty
+func (w *bruWriter) pairs(name string, pairs [][2]string) {
+	if len(pairs) == 0 {
+		return
+	}
+	w.start()
+	w.sb.WriteString(name + " {\n")
+	for _, pair := range pairs {
+		value := strings.ReplaceAll(pair[1], "\n", " ")
+		w.sb.WriteString(fmt.Sprintf("  %s: %s\n", pair[0], value))
+	}
+	w.sb.WriteString("}\n")
+}
+
+// vars writes a block of variables
+func (w *bruWriter) vars(name string, vars map[string]interface{}) {
+	var pairs [][2]string
+	for _, key := range sortedKeys(vars) {
+		pairs = append(pairs, [2]string{key, convertReferences(fmt.Sprint(vars[key]), mustacheReference)})
+	}
+	w.pairs(name, pairs)
+}
+
+// list writes a block of names
+func (w *bruWriter) list(name string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	w.start()
+	w.sb.WriteString(name + " [\n")
+	for i, item := range items {
+		if i < len(items)-1 {
+			item += ","
+		}
+		w.sb.WriteString("  " + item + "\n")
+	}
+	w.sb.WriteString("]\n")
+}
+
+// text writes a block of text, like a body or a script
+func (w *bruWriter) text(name, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	w.start()
+	w.sb.WriteString(name + " {\n")
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			w.sb.WriteString("\n")
+		} else {
+			w.sb.WriteString("  " + line + "\n")
+		}
+	}
+	w.sb.WriteString("}\n")
+}
+
+// auth writes the auth of a collection or folder
+func (w *bruWriter) auth(auth *models.CollectionAuth) {
+	if auth == nil {
+		return
+	}
+	convert := func(s string) string { return convertReferences(s, mustacheReference) }
+
+	switch {
+	case auth.Type == "bearer" && auth.Bearer != nil:
+		w.pairs("auth", [][2]string{{"mode", "bearer"}})
+		w.pairs("auth:bearer", [][2]string{{"token", convert(auth.Bearer.Token)}})
+	case auth.Type == "basic" && auth.Basic != nil:
+		w.pairs("auth", [][2]string{{"mode", "basic"}})
+		w.pairs("auth:basic", [][2]string{
+			{"username", convert(auth.Basic.Username)},
+			{"password", convert(auth.Basic.Password)},
+		})
+	case auth.Type == "api_key" && auth.APIKey != nil:
+		placement := "header"
+		if auth.APIKey.In == "query" {
+			placement = "queryparams"
+		}
+		w.pairs("auth", [][2]string{{"mode", "apikey"}})
+		w.pairs("auth:apikey", [][2]string{
+			{"key", auth.APIKey.Key},
+			{"value", convert(auth.APIKey.Value)},
+			{"placement", placement},
+		})
+	case auth.Type == "oauth2" && auth.OAuth2 != nil:
+		o := auth.OAuth2
+		w.pairs("auth", [][2]string{{"mode", "oauth2"}})
+		w.pairs("auth:oauth2", [][2]string{
+			{"grant_type", o.GrantType},
+			{"callback_url", convert(o.RedirectURI)},
+			{"authorization_url", convert(o.AuthURL)},
+			{"access_token_url", convert(o.TokenURL)},
+			{"client_id", convert(o.ClientID)},
+			{"client_secret", convert(o.ClientSecret)},
+			{"scope", o.Scope},
+		})
+	}
+}
+
+func (w *bruWriter) String() string {
+	return w.sb.String()
+}
@@ -0,0 +1,486 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/codegen"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+)
+
+// Collection formats, Postman, Bruno and Insomnia, arrange the exported flows
+// in folders: a folder per collection, holding a folder per flow with a
+// request per http_request step. Their importers read a folder that holds
+// only requests as a flow.
+
+// defaultCollectionName names exports of flows of several collections
+const defaultCollectionName = "TestMesh Export"
+
+// folder is a folder of a collection format, for a collection or a flow
+type folder struct {
+	Name        string
+	Description string
+	Auth        *models.CollectionAuth
+	Variables   map[string]interface{}
+	Folders     []*folder
+	Flows       []*models.Flow
+	order       int
+}
+
+// buildTree arranges flows in the folders of their collections. When all
+// flows belong to one collection, that collection is the root. Flows without
+// HTTP requests are left out.
+func buildTree(flows []*models.Flow, collections []models.Collection, warn func(format string, args ...interface{})) *folder {
+	byID := make(map[uuid.UUID]*models.Collection, len(collections))
+	for i := range collections {
+		byID[collections[i].ID] = &collections[i]
+	}
+
+	root := &folder{Name: defaultCollectionName}
+	folders := make(map[uuid.UUID]*folder)
+	var get func(id uuid.UUID) *folder
+	get = func(id uuid.UUID) *folder {
+		if f, ok := folders[id]; ok {
+			return f
+		}
+		c := byID[id]
+		f := &folder{
+			Name:        c.Name,
+			Description: c.Description,
+			Variables:   c.Variables.Global,
+			order:       c.SortOrder,
+		}
+		if c.Auth.Type != "" && c.Auth.Type != "none" && !c.Auth.Inherit {
+			auth := c.Auth
+			f.Auth = &auth
+		}
+		folders[id] = f
+
+		parent := root
+		if c.ParentID != nil && byID[*c.ParentID] != nil {
+			parent = get(*c.ParentID)
+		}
+		parent.Folders = append(parent.Folders, f)
+		return f
+	}
+
+	for _, flow := range flows {
+		if !hasRequests(flow) {
+			warn("flow %s: no http_request steps, not exported", flow.Name)
+			continue
+		}
+		if flow.CollectionID != nil && byID[*flow.CollectionID] != nil {
+			f := get(*flow.CollectionID)
+			f.Flows = append(f.Flows, flow)
+		} else {
+			root.Flows = append(root.Flows, flow)
+		}
+	}
+
+	if len(root.Flows) == 0 && len(root.Folders) == 1 {
+		root = root.Folders[0]
+	}
+	root.sort()
+	return root
+}
+
+// sort orders folders and flows by their sort order, then by name
+func (f *folder) sort() {
+	sort.SliceStable(f.Folders, func(i, j int) bool {
+		if f.Folders[i].order != f.Folders[j].order {
+			return f.Folders[i].order < f.Folders[j].order
+		}
+		return f.Folders[i].Name < f.Folders[j].Name
+	})
+	sort.SliceStable(f.Flows, func(i, j int) bool {
+		return f.Flows[i].SortOrder < f.Flows[j].SortOrder
+	})
+	for _, child := range f.Folders {
+		child.sort()
+	}
+}
+
+// hasRequests reports whether a flow has steps that export as requests
+func hasRequests(flow *models.Flow) bool {
+	def := flow.Definition
+	for _, steps := range [][]models.Step{def.Setup, def.Steps, def.Teardown} {
+		for _, step := range steps {
+			if isRequestStep(step) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isRequestStep(step models.Step) bool {
+	return step.Action == "http_request" || step.Action == "http"
+}
+
+// variable is a variable of an exported collection
+type variable struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// exportVariables lists the variables of the root collection and, with
+// IncludeEnv, of the environment. Values of secrets are not exported.
+func exportVariables(root *folder, options ExportOptions) []variable {
+	var vars []variable
+	index := make(map[string]int)
+	add := func(v variable) {
+		if v.Secret {
+			v.Value = ""
+		}
+		if i, ok := index[v.Key]; ok {
+			vars[i] = v
+			return
+		}
+		index[v.Key] = len(vars)
+		vars = append(vars, v)
+	}
+
+	for _, key := range sortedKeys(root.Variables) {
+		add(variable{Key: key, Value: fmt.Sprint(root.Variables[key])})
+	}
+	if options.IncludeEnv && options.Environment != nil {
+		for _, v := range options.Environment.Variables {
+			if v.Enabled {
+				add(variable{Key: v.Key, Value: v.Value, Secret: v.IsSecret})
+			}
+		}
+	}
+	return vars
+}
+
+// request is an http_request step, in the terms of API clients
+type request struct {
+	ID          string
+	Name        string
+	Description string
+	Method      string
+	URL         string
+	Headers     []header
+	Body        interface{}
+	HasBody     bool
+	Assertions  []string
+	// Sets are the variables the request sets from its response: its named
+	// outputs and the values later steps reference, like ${login.token}
+	Sets []variableSet
+}
+
+type header struct {
+	Name  string
+	Value string
+}
+
+// variableSet is a variable a request sets from its response
+type variableSet struct {
+	Name string
+	Path []interface{} // Keys and indexes below the result of the step
+}
+
+// flowRequests converts the http_request steps of a flow to requests. Setup
+// and teardown steps become requests before and after the other steps.
+func flowRequests(flow *models.Flow, warn func(format string, args ...interface{})) []request {
+	def := flow.Definition
+	if len(def.Setup) > 0 || len(def.Teardown) > 0 {
+		warn("flow %s: setup and teardown steps are exported as requests in order", flow.Name)
+	}
+
+	var steps []models.Step
+	for _, phase := range []struct {
+		name  string
+		steps []models.Step
+	}{{"setup", def.Setup}, {"main", def.Steps}, {"teardown", def.Teardown}} {
+		for i, step := range phase.steps {
+			if step.ID == "" {
+				step.ID = fmt.Sprintf("%s_%d", phase.name, i)
+			}
+			steps = append(steps, step)
+		}
+	}
+
+	var requests []request
+	byID := make(map[string]int)
+	for _, step := range steps {
+		if !isRequestStep(step) {
+			warn("flow %s: step %s: %s steps are not exported", flow.Name, step.ID, step.Action)
+			continue
+		}
+		if step.Retry != nil || len(step.Schema) > 0 || step.Snapshot != nil {
+			warn("flow %s: step %s: retries, schema and snapshot assertions are not exported", flow.Name, step.ID)
+		}
+
+		r := request{
+			ID:          step.ID,
+			Name:        step.Name,
+			Description: step.Description,
+			Method:      "GET",
+			Assertions:  step.Assert,
+		}
+		if r.Name == "" {
+			r.Name = step.ID
+		}
+		if method, ok := step.Config["method"].(string); ok && method != "" {
+			r.Method = strings.ToUpper(method)
+		}
+		r.URL, _ = step.Config["url"].(string)
+		if headers, ok := step.Config["headers"].(map[string]interface{}); ok {
+			for _, name := range sortedKeys(headers) {
+				r.Headers = append(r.Headers, header{Name: name, Value: fmt.Sprint(headers[name])})
+			}
+		}
+		if body, ok := step.Config["body"]; ok {
+			r.Body, r.HasBody = body, true
+		}
+		for _, name := range sortedKeys(step.Output) {
+			r.Sets = append(r.Sets, variableSet{Name: step.ID + "." + name, Path: codegen.OutputPath(step.ID, step.Output[name])})
+		}
+
+		byID[step.ID] = len(requests)
+		requests = append(requests, r)
+	}
+
+	// Set the values later steps reference, unless a named output sets them
+	for i, step := range steps {
+		for _, ref := range stepReferences(step) {
+			id, path, _ := strings.Cut(ref, ".")
+			j, ok := byID[id]
+			if !ok || path == "" {
+				continue
+			}
+			keys, ok := referencePath(steps, id, path)
+			if !ok {
+				warn("flow %s: step %s: reference ${%s} is not exported", flow.Name, steps[i].ID, ref)
+				continue
+			}
+			if !requests[j].sets(ref) {
+				requests[j].Sets = append(requests[j].Sets, variableSet{Name: ref, Path: keys})
+			}
+		}
+	}
+	for i := range requests {
+		sort.Slice(requests[i].Sets, func(a, b int) bool { return requests[i].Sets[a].Name < requests[i].Sets[b].Name })
+	}
+	return requests
+}
+
+func (r *request) sets(name string) bool {
+	for _, set := range r.Sets {
+		if set.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resultFields are the fields of the result of an http_request step
+var resultFields = map[string]bool{
+	"status": true, "body": true, "headers": true, "duration_ms": true, "content_type": true,
+}
+
+// referencePath resolves a reference to an output of a step like the runner
+// does: a named output, then a field of the result
+func referencePath(steps []models.Step, id, path string) ([]interface{}, bool) {
+	for _, step := range steps {
+		if step.ID != id {
+			continue
+		}
+		if output, ok := step.Output[path]; ok {
+			return codegen.OutputPath(id, output), true
+		}
+		first, rest, _ := strings.Cut(path, ".")
+		if output, ok := step.Output[first]; ok {
+			keys := codegen.OutputPath(id, output)
+			if rest != "" {
+				keys = append(keys, codegen.OutputPath(id, rest)[1:]...)
+			}
+			return keys, true
+		}
+		if resultFields[first] {
+			return codegen.OutputPath(id, path), true
+		}
+	}
+	return nil, false
+}
+
+// referencePattern finds ${...} references in text
+var referencePattern = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// stepReferences lists the references of a step, like login.token
+func stepReferences(step models.Step) []string {
+	var refs []string
+	walkStrings(step.Config, func(s string) {
+		for _, m := range referencePattern.FindAllStringSubmatch(s, -1) {
+			refs = append(refs, strings.TrimSpace(m[1]))
+		}
+	})
+	return refs
+}
+
+// walkStrings calls fn with the strings of a config value
+func walkStrings(v interface{}, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
+
+// convertReferences rewrites the ${...} references of text
+func convertReferences(text string, convert func(ref string) string) string {
+	return referencePattern.ReplaceAllStringFunc(text, func(m string) string {
+		return convert(strings.TrimSpace(m[2 : len(m)-1]))
+	})
+}
+
+// convertValue rewrites the references of the strings of a config value
+func convertValue(v interface{}, convert func(ref string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return convertReferences(v, convert)
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = convertValue(item, convert)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = convertValue(item, convert)
+		}
+		return converted
+	}
+	return v
+}
+
+// clientBuiltins are the dynamic variables of API clients for the built-in
+// variables of TestMesh
+var clientBuiltins = map[string]string{
+	"UUID": "$guid", "RANDOM_ID": "$randomUUID", "TIMESTAMP": "$timestamp",
+}
+
+// mustacheReference converts a reference to the {{name}} syntax of Postman
+// and Bruno
+func mustacheReference(ref string) string {
+	if builtin, ok := clientBuiltins[ref]; ok {
+		return "{{" + builtin + "}}"
+	}
+	return "{{" + ref + "}}"
+}
+
+// jsonBody formats a request body as JSON
+func jsonBody(body interface{}) string {
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return fmt.Sprint(body)
+	}
+	return string(data)
+}
+
+// resultScript builds the test script of a request: the assertions as
+// tests and the variables it sets, over a result object built by prelude
+func resultScript(r request, prelude, test, expect, setVariable string, warn func(format string, args ...interface{})) []string {
+	if len(r.Assertions) == 0 && len(r.Sets) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(prelude, "\n")
+	var helpers []string
+	for _, source := range r.Assertions {
+		statement, used, err := codegen.ChaiAssertion(source, "result", expect)
+		lines = append(lines, "", fmt.Sprintf("%s(%s, function () {", test, jsString(source)))
+		if err != nil {
+			warn("step %s: assertion %q is exported as a test without a check: %v", r.ID, source, err)
+			lines = append(lines, "  // TODO: translate the assertion")
+		} else {
+			lines = append(lines, "  "+statement)
+			helpers = append(helpers, used...)
+		}
+		lines = append(lines, "});")
+	}
+	if len(r.Sets) > 0 {
+		lines = append(lines, "")
+		for _, set := range r.Sets {
+			path, _ := json.Marshal(set.Path)
+			lines = append(lines, fmt.Sprintf("%s(%s, dig(result, %s));", setVariable, jsString(set.Name), path))
+		}
+		helpers = append(helpers, "dig")
+	}
+	if code := codegen.JSHelpers(helpers); code != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(strings.TrimSuffix(code, "\n"), "\n")...)
+	}
+	return lines
+}
+
+// jsString quotes a JavaScript string
+func jsString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// pathString formats keys and indexes as a path, like body.items[0].id
+func pathString(path []interface{}) string {
+	var sb strings.Builder
+	for _, key := range path {
+		switch key := key.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", key)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			fmt.Fprint(&sb, key)
+		}
+	}
+	return sb.String()
+}
+
+// fileName turns a name into a file name, without the characters file
+// systems reject
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
+
+// uniqueName returns name, or name with a number when it is taken
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s %d", name, i)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
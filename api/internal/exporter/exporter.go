@@ -1,8 +1,11 @@
 package exporter
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -17,6 +20,9 @@ const (
 	FormatOpenAPI ExportFormat = "openapi"
 	FormatHAR     ExportFormat = "har"
 	FormatTestMesh ExportFormat = "testmesh"
+	FormatBruno    ExportFormat = "bruno"
+	FormatInsomnia ExportFormat = "insomnia"
+	FormatK6       ExportFormat = "k6"
 )
 
 // ExportOptions contains options for export
@@ -24,6 +30,12 @@ type ExportOptions struct {
 	Format       ExportFormat `json:"format"`
 	IncludeTests bool         `json:"include_tests"`
 	IncludeEnv   bool         `json:"include_env"`
+
+	// Collections are the collections of the flows, for the folders of
+	// collection formats
+	Collections []models.Collection `json:"-"`
+	// Environment is exported with IncludeEnv
+	Environment *models.Environment `json:"-"`
 }
 
 // ExportResult contains the export result
@@ -32,6 +44,35 @@ type ExportResult struct {
 	Content  string       `json:"content"`
 	Filename string       `json:"filename"`
 	MimeType string       `json:"mime_type"`
+	// Files are the files of exports of several files, like Bruno
+	// collections, instead of Content
+	Files    []ExportedFile `json:"files,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// ExportedFile is a file of an export of several files
+type ExportedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Archive zips the files of an export
+func (r *ExportResult) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range r.Files {
+		w, err := zw.Create(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to archive: %w", file.Path, err)
+		}
+		if _, err := io.WriteString(w, file.Content); err != nil {
+			return nil, fmt.Errorf("failed to add %s to archive: %w", file.Path, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 // ExportFlows exports flows to the specified format
@@ -45,128 +86,17 @@ func ExportFlows(flows []*models.Flow, options ExportOptions) (*ExportResult, er
 		return exportToHAR(flows, options)
 	case FormatTestMesh:
 		return exportToTestMesh(flows, options)
+	case FormatBruno:
+		return exportToBruno(flows, options)
+	case FormatInsomnia:
+		return exportToInsomnia(flows, options)
+	case FormatK6:
+		return exportToK6(flows, options)
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", options.Format)
 	}
 }
 
-// Postman export
-
-func exportToPostman(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
-	collection := map[string]interface{}{
-		"info": map[string]interface{}{
-			"name":        "TestMesh Export",
-			"description": fmt.Sprintf("Exported from TestMesh on %s", time.Now().Format(time.RFC3339)),
-			"schema":      "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
-		},
-		"item": []interface{}{},
-	}
-
-	items := []interface{}{}
-	for _, flow := range flows {
-		item := flowToPostmanItem(flow)
-		items = append(items, item)
-	}
-	collection["item"] = items
-
-	content, err := json.MarshalIndent(collection, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Postman collection: %w", err)
-	}
-
-	return &ExportResult{
-		Format:   FormatPostman,
-		Content:  string(content),
-		Filename: "testmesh-collection.postman_collection.json",
-		MimeType: "application/json",
-	}, nil
-}
-
-func flowToPostmanItem(flow *models.Flow) map[string]interface{} {
-	item := map[string]interface{}{
-		"name": flow.Name,
-	}
-
-	if flow.Description != "" {
-		item["description"] = flow.Description
-	}
-
-	// If single step, export as request; otherwise as folder
-	if len(flow.Definition.Steps) == 1 {
-		step := flow.Definition.Steps[0]
-		request := stepToPostmanRequest(step)
-		if request != nil {
-			item["request"] = request
-		}
-	} else {
-		// Multiple steps - create folder
-		subItems := []interface{}{}
-		for _, step := range flow.Definition.Steps {
-			subItem := map[string]interface{}{
-				"name": step.Name,
-			}
-			request := stepToPostmanRequest(step)
-			if request != nil {
-				subItem["request"] = request
-			}
-			subItems = append(subItems, subItem)
-		}
-		item["item"] = subItems
-	}
-
-	return item
-}
-
-func stepToPostmanRequest(step models.Step) map[string]interface{} {
-	if step.Action != "http" {
-		return nil
-	}
-
-	if step.Config == nil {
-		return nil
-	}
-
-	request := map[string]interface{}{
-		"method": step.Config["method"],
-		"url": map[string]interface{}{
-			"raw": step.Config["url"],
-		},
-	}
-
-	// Headers
-	if headers, ok := step.Config["headers"].(map[string]interface{}); ok {
-		headerList := []map[string]string{}
-		for k, v := range headers {
-			headerList = append(headerList, map[string]string{
-				"key":   k,
-				"value": fmt.Sprintf("%v", v),
-			})
-		}
-		request["header"] = headerList
-	}
-
-	// Body
-	if body, ok := step.Config["body"]; ok {
-		bodyStr := ""
-		switch b := body.(type) {
-		case string:
-			bodyStr = b
-		case map[string]interface{}, []interface{}:
-			if jsonBytes, err := json.Marshal(b); err == nil {
-				bodyStr = string(jsonBytes)
-			}
-		}
-		if bodyStr != "" {
-			request["body"] = map[string]interface{}{
-				"mode": "raw",
-				"raw":  bodyStr,
-			}
-		}
-	}
-
-	return request
-}
-
 // OpenAPI export
 
 func exportToOpenAPI(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
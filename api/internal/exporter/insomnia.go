@@ -0,0 +1,374 @@
+package exporter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/codegen"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Insomnia export
+
+// References to outputs of steps are response tags, which read the last
+// response of a request. The outputs themselves are set by after-response
+// scripts, for the importer. Assertions are unit tests, in a suite per flow.
+
+// insomniaPrelude builds the result of an http_request step from the
+// response of insomnia.send()
+const insomniaPrelude = `const response = await insomnia.send();
+// result of the request, like the result of an http_request step
+const headers = Object.fromEntries(response.headers.map((h) => [h.name, h.value]));
+const result = {
+  status: response.status,
+  body: (() => {
+    try {
+      return JSON.parse(response.data);
+    } catch (e) {
+      return response.data;
+    }
+  })(),
+  headers,
+  duration_ms: response.responseTime,
+  content_type: response.contentType || "",
+};`
+
+// insomniaResponsePrelude builds the result of an http_request step from
+// the response, for the outputs of after-response scripts
+const insomniaResponsePrelude = `// result of the request, like the result of an http_request step
+const result = {
+  status: insomnia.response.code,
+  body: (() => {
+    try {
+      return insomnia.response.json();
+    } catch (e) {
+      return insomnia.response.text();
+    }
+  })(),
+  headers: insomnia.response.headers.toObject(),
+  duration_ms: insomnia.response.responseTime,
+  content_type: insomnia.response.headers.get("Content-Type") || "",
+};`
+
+// insomniaBuiltins are the template tags of the built-in variables of
+// TestMesh
+var insomniaBuiltins = map[string]string{
+	"UUID": "{% uuid 'v4' %}", "RANDOM_ID": "{% uuid 'v4' %}", "TIMESTAMP": "{% now 'unix' %}",
+}
+
+// insomniaVariable converts a reference to a variable of the environment
+func insomniaVariable(ref string) string {
+	if builtin, ok := insomniaBuiltins[ref]; ok {
+		return builtin
+	}
+	return "{{ _." + ref + " }}"
+}
+
+type insomniaExport struct {
+	resources   []interface{}
+	ids         int
+	workspaceID string
+	warn        func(format string, args ...interface{})
+}
+
+func (e *insomniaExport) id(prefix string) string {
+	e.ids++
+	return fmt.Sprintf("%s_%d", prefix, e.ids)
+}
+
+func exportToInsomnia(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
+	result := &ExportResult{
+		Format:   FormatInsomnia,
+		Filename: "testmesh-collection.insomnia.json",
+		MimeType: "application/json",
+	}
+	e := &insomniaExport{
+		warn: func(format string, args ...interface{}) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+		},
+	}
+	root := buildTree(flows, options.Collections, e.warn)
+
+	workspaceID := e.id("wrk")
+	e.workspaceID = workspaceID
+	e.add(map[string]interface{}{
+		"_id":         workspaceID,
+		"_type":       "workspace",
+		"parentId":    nil,
+		"name":        root.Name,
+		"description": root.Description,
+		"scope":       "collection",
+	})
+
+	baseID := e.id("env")
+	e.add(map[string]interface{}{
+		"_id":      baseID,
+		"_type":    "environment",
+		"parentId": workspaceID,
+		"name":     "Base Environment",
+		"data":     insomniaData(root.Variables),
+	})
+	if options.IncludeEnv && options.Environment != nil {
+		data := map[string]interface{}{}
+		for _, v := range exportVariables(&folder{}, options) {
+			data[v.Key] = convertReferences(v.Value, insomniaVariable)
+		}
+		e.add(map[string]interface{}{
+			"_id":      e.id("env"),
+			"_type":    "environment",
+			"parentId": baseID,
+			"name":     options.Environment.Name,
+			"data":     data,
+		})
+	}
+
+	// Workspaces have no auth, so the folders below the root get its auth
+	e.folder(root, workspaceID, root.Auth)
+
+	content, err := json.MarshalIndent(map[string]interface{}{
+		"_type":           "export",
+		"__export_format": 4,
+		"__export_date":   time.Now().UTC().Format(time.RFC3339),
+		"__export_source": "testmesh",
+		"resources":       e.resources,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Insomnia export: %w", err)
+	}
+	result.Content = string(content)
+	return result, nil
+}
+
+func (e *insomniaExport) add(resource map[string]interface{}) {
+	e.resources = append(e.resources, resource)
+}
+
+// folder adds request groups for the folders and flows of a folder
+func (e *insomniaExport) folder(f *folder, parentID string, auth *models.CollectionAuth) {
+	for i, child := range f.Folders {
+		id := e.id("fld")
+		group := map[string]interface{}{
+			"_id":         id,
+			"_type":       "request_group",
+			"parentId":    parentID,
+			"name":        child.Name,
+			"description": child.Description,
+			"environment": insomniaData(child.Variables),
+			"metaSortKey": i,
+		}
+		childAuth := child.Auth
+		if childAuth == nil {
+			childAuth = auth
+		}
+		if childAuth != nil {
+			group["authentication"] = insomniaAuth(childAuth)
+		}
+		e.add(group)
+		e.folder(child, id, nil)
+	}
+
+	for i, flow := range f.Flows {
+		id := e.id("fld")
+		group := map[string]interface{}{
+			"_id":         id,
+			"_type":       "request_group",
+			"parentId":    parentID,
+			"name":        flow.Name,
+			"description": flow.Description,
+			"environment": insomniaData(flow.Definition.Env),
+			"metaSortKey": len(f.Folders) + i,
+		}
+		if auth != nil {
+			group["authentication"] = insomniaAuth(auth)
+		}
+		e.add(group)
+		e.flow(flow, id)
+	}
+}
+
+// flow adds the requests of a flow and a unit test suite of its assertions
+func (e *insomniaExport) flow(flow *models.Flow, groupID string) {
+	requests := flowRequests(flow, e.warn)
+
+	// Request IDs keep the IDs of steps, for the importer
+	ids := make(map[string]string, len(requests))
+	for _, r := range requests {
+		ids[r.ID] = e.id("req_" + r.ID)
+	}
+	refs := make(map[string]string)
+	for _, r := range requests {
+		for _, set := range r.Sets {
+			if tag, ok := insomniaResponseTag(ids[r.ID], set.Path); ok {
+				refs[set.Name] = tag
+			} else {
+				e.warn("flow %s: step %s: ${%s} is not exported, Insomnia response tags cannot read %s", flow.Name, r.ID, set.Name, set.Path[0])
+			}
+		}
+	}
+	convert := func(ref string) string {
+		if tag, ok := refs[ref]; ok {
+			return tag
+		}
+		return insomniaVariable(ref)
+	}
+
+	suiteID := ""
+	for i, r := range requests {
+		headers := []interface{}{}
+		for _, h := range r.Headers {
+			headers = append(headers, map[string]interface{}{
+				"name":  h.Name,
+				"value": convertReferences(h.Value, convert),
+			})
+		}
+		req := map[string]interface{}{
+			"_id":            ids[r.ID],
+			"_type":          "request",
+			"parentId":       groupID,
+			"name":           r.Name,
+			"description":    r.Description,
+			"method":         r.Method,
+			"url":            convertReferences(r.URL, convert),
+			"headers":        headers,
+			"body":           map[string]interface{}{},
+			"authentication": map[string]interface{}{},
+			"metaSortKey":    i,
+		}
+		if r.HasBody {
+			req["body"] = map[string]interface{}{
+				"mimeType": "application/json",
+				"text":     jsonBody(convertValue(r.Body, convert)),
+			}
+		}
+		// Assertions are unit tests, so the script only sets the outputs
+		script := resultScript(request{ID: r.ID, Sets: r.Sets}, insomniaResponsePrelude, "test", "expect", "insomnia.environment.set", e.warn)
+		if len(script) > 0 {
+			req["afterResponseScript"] = strings.Join(script, "\n")
+		}
+		e.add(req)
+
+		for _, source := range r.Assertions {
+			if suiteID == "" {
+				suiteID = e.id("uts")
+				e.add(map[string]interface{}{
+					"_id":      suiteID,
+					"_type":    "unit_test_suite",
+					"parentId": e.workspaceID,
+					"name":     flow.Name,
+				})
+			}
+			e.add(map[string]interface{}{
+				"_id":       e.id("ut"),
+				"_type":     "unit_test",
+				"parentId":  suiteID,
+				"requestId": ids[r.ID],
+				"name":      source,
+				"code":      e.unitTest(flow, r, source),
+			})
+		}
+	}
+}
+
+// unitTest writes the code of a unit test of an assertion
+func (e *insomniaExport) unitTest(flow *models.Flow, r request, source string) string {
+	statement, helpers, err := codegen.ChaiAssertion(source, "result", "expect")
+	if err != nil {
+		e.warn("flow %s: step %s: assertion %q is exported as a test without a check: %v", flow.Name, r.ID, source, err)
+		statement = "// TODO: translate the assertion"
+	}
+	code := insomniaPrelude + "\n\n" + statement + "\n"
+	if helpers := codegen.JSHelpers(helpers); helpers != "" {
+		code += "\n" + helpers
+	}
+	return code
+}
+
+// insomniaResponseTag converts a path below the result of a step to a
+// response tag. Tags read the body with JSONPath, or a header.
+func insomniaResponseTag(requestID string, path []interface{}) (string, bool) {
+	if len(path) == 0 {
+		return "", false
+	}
+	var field, filter string
+	switch path[0] {
+	case "body":
+		field = "body"
+		filter = "$"
+		if len(path) > 1 {
+			filter += "." + pathString(path[1:])
+			filter = strings.Replace(filter, "$.[", "$[", 1)
+		}
+	case "headers":
+		if len(path) != 2 {
+			return "", false
+		}
+		field = "header"
+		filter = fmt.Sprint(path[1])
+	case "content_type":
+		field = "header"
+		filter = "Content-Type"
+	default:
+		return "", false
+	}
+	encoded := "b64::" + base64.StdEncoding.EncodeToString([]byte(filter)) + "::46b"
+	return fmt.Sprintf("{%% response '%s', '%s', '%s', 'never', 60 %%}", field, requestID, encoded), true
+}
+
+func insomniaData(vars map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(vars))
+	for key, value := range vars {
+		if s, ok := value.(string); ok {
+			value = convertReferences(s, insomniaVariable)
+		}
+		data[key] = value
+	}
+	return data
+}
+
+// insomniaAuth converts collection auth to the authentication of a request
+// group
+func insomniaAuth(auth *models.CollectionAuth) map[string]interface{} {
+	convert := func(s string) string { return convertReferences(s, insomniaVariable) }
+
+	switch {
+	case auth.Type == "bearer" && auth.Bearer != nil:
+		return map[string]interface{}{
+			"type":   "bearer",
+			"token":  convert(auth.Bearer.Token),
+			"prefix": auth.Bearer.Prefix,
+		}
+	case auth.Type == "basic" && auth.Basic != nil:
+		return map[string]interface{}{
+			"type":     "basic",
+			"username": convert(auth.Basic.Username),
+			"password": convert(auth.Basic.Password),
+		}
+	case auth.Type == "api_key" && auth.APIKey != nil:
+		addTo := "header"
+		if auth.APIKey.In == "query" {
+			addTo = "queryParams"
+		}
+		return map[string]interface{}{
+			"type":  "apikey",
+			"key":   auth.APIKey.Key,
+			"value": convert(auth.APIKey.Value),
+			"addTo": addTo,
+		}
+	case auth.Type == "oauth2" && auth.OAuth2 != nil:
+		o := auth.OAuth2
+		return map[string]interface{}{
+			"type":             "oauth2",
+			"grantType":        o.GrantType,
+			"authorizationUrl": convert(o.AuthURL),
+			"accessTokenUrl":   convert(o.TokenURL),
+			"clientId":         convert(o.ClientID),
+			"clientSecret":     convert(o.ClientSecret),
+			"redirectUrl":      convert(o.RedirectURI),
+			"scope":            o.Scope,
+		}
+	}
+	return map[string]interface{}{}
+}
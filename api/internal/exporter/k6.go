@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/codegen"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// k6 export
+
+// exportToK6 generates a k6 script per flow. A single flow is exported as
+// its script, several as an archive of scripts.
+func exportToK6(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
+	var variables []codegen.Variable
+	if options.IncludeEnv && options.Environment != nil {
+		for _, v := range options.Environment.Variables {
+			if v.Enabled {
+				variables = append(variables, codegen.Variable{Name: v.Key, Value: v.Value, Secret: v.IsSecret})
+			}
+		}
+	}
+
+	result := &ExportResult{Format: FormatK6}
+	generator := codegen.NewGenerator()
+	used := make(map[string]bool)
+	for _, flow := range flows {
+		def := flow.Definition
+		if def.Name == "" {
+			def.Name = flow.Name
+		}
+		code, err := generator.GenerateFlow("k6", &def, variables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate k6 script of flow %s: %w", flow.Name, err)
+		}
+		for _, warning := range code.Warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("flow %s: %s", flow.Name, warning))
+		}
+		name := strings.TrimSuffix(code.Filename, ".k6.js")
+		result.Files = append(result.Files, ExportedFile{
+			Path:    uniqueName(name, used) + ".k6.js",
+			Content: code.Code,
+		})
+	}
+
+	if len(result.Files) == 1 {
+		result.Content = result.Files[0].Content
+		result.Filename = result.Files[0].Path
+		result.MimeType = "application/javascript"
+		result.Files = nil
+		return result, nil
+	}
+	result.Filename = "testmesh-k6-scripts.zip"
+	result.MimeType = "application/zip"
+	return result, nil
+}
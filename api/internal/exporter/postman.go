@@ -0,0 +1,233 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Postman export
+
+// postmanPrelude builds the result of an http_request step from the
+// response, for the assertions and outputs of test scripts
+const postmanPrelude = `// result of the request, like the result of an http_request step
+const result = {
+  status: pm.response.code,
+  body: (() => {
+    try {
+      return pm.response.json();
+    } catch (e) {
+      return pm.response.text();
+    }
+  })(),
+  headers: pm.response.headers.toObject(),
+  duration_ms: pm.response.responseTime,
+  content_type: pm.response.headers.get("Content-Type") || "",
+};`
+
+func exportToPostman(flows []*models.Flow, options ExportOptions) (*ExportResult, error) {
+	result := &ExportResult{
+		Format:   FormatPostman,
+		Filename: "testmesh-collection.postman_collection.json",
+		MimeType: "application/json",
+	}
+	warn := func(format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+	root := buildTree(flows, options.Collections, warn)
+
+	description := root.Description
+	if description == "" {
+		description = "Exported from TestMesh"
+	}
+	collection := map[string]interface{}{
+		"info": map[string]interface{}{
+			"name":        root.Name,
+			"description": description,
+			"schema":      "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item": postmanItems(root, warn),
+	}
+	if root.Auth != nil {
+		collection["auth"] = postmanAuth(root.Auth)
+	}
+
+	variables := []interface{}{}
+	for _, v := range exportVariables(root, options) {
+		kind := "string"
+		if v.Secret {
+			kind = "secret"
+		}
+		variables = append(variables, map[string]interface{}{
+			"key":   v.Key,
+			"value": convertReferences(v.Value, mustacheReference),
+			"type":  kind,
+		})
+	}
+	collection["variable"] = variables
+
+	content, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Postman collection: %w", err)
+	}
+	result.Content = string(content)
+	return result, nil
+}
+
+// postmanItems lists the folders of the collections and flows of a folder
+func postmanItems(f *folder, warn func(format string, args ...interface{})) []interface{} {
+	items := []interface{}{}
+	for _, child := range f.Folders {
+		item := map[string]interface{}{
+			"name": child.Name,
+			"item": postmanItems(child, warn),
+		}
+		if child.Description != "" {
+			item["description"] = child.Description
+		}
+		if child.Auth != nil {
+			item["auth"] = postmanAuth(child.Auth)
+		}
+		if len(child.Variables) > 0 {
+			item["variable"] = postmanVariables(child.Variables)
+		}
+		items = append(items, item)
+	}
+	for _, flow := range f.Flows {
+		items = append(items, flowToPostmanItem(flow, warn))
+	}
+	return items
+}
+
+// flowToPostmanItem exports a flow as a folder with a request per step
+func flowToPostmanItem(flow *models.Flow, warn func(format string, args ...interface{})) map[string]interface{} {
+	item := map[string]interface{}{
+		"name": flow.Name,
+	}
+	if flow.Description != "" {
+		item["description"] = flow.Description
+	}
+	if len(flow.Definition.Env) > 0 {
+		item["variable"] = postmanVariables(flow.Definition.Env)
+	}
+
+	requests := []interface{}{}
+	for _, r := range flowRequests(flow, warn) {
+		requests = append(requests, requestToPostmanItem(r, func(format string, args ...interface{}) {
+			warn("flow %s: "+format, append([]interface{}{flow.Name}, args...)...)
+		}))
+	}
+	item["item"] = requests
+	return item
+}
+
+func requestToPostmanItem(r request, warn func(format string, args ...interface{})) map[string]interface{} {
+	headers := []interface{}{}
+	for _, h := range r.Headers {
+		headers = append(headers, map[string]interface{}{
+			"key":   h.Name,
+			"value": convertReferences(h.Value, mustacheReference),
+		})
+	}
+
+	req := map[string]interface{}{
+		"method": r.Method,
+		"header": headers,
+		"url": map[string]interface{}{
+			"raw": convertReferences(r.URL, mustacheReference),
+		},
+	}
+	if r.Description != "" {
+		req["description"] = r.Description
+	}
+	if r.HasBody {
+		req["body"] = map[string]interface{}{
+			"mode": "raw",
+			"raw":  jsonBody(convertValue(r.Body, mustacheReference)),
+			"options": map[string]interface{}{
+				"raw": map[string]interface{}{"language": "json"},
+			},
+		}
+	}
+
+	item := map[string]interface{}{
+		"id":      r.ID,
+		"name":    r.Name,
+		"request": req,
+	}
+	script := resultScript(r, postmanPrelude, "pm.test", "pm.expect", "pm.collectionVariables.set", warn)
+	if len(script) > 0 {
+		item["event"] = []interface{}{
+			map[string]interface{}{
+				"listen": "test",
+				"script": map[string]interface{}{
+					"type": "text/javascript",
+					"exec": script,
+				},
+			},
+		}
+	}
+	return item
+}
+
+func postmanVariables(vars map[string]interface{}) []interface{} {
+	list := []interface{}{}
+	for _, key := range sortedKeys(vars) {
+		list = append(list, map[string]interface{}{
+			"key":   key,
+			"value": convertReferences(fmt.Sprint(vars[key]), mustacheReference),
+			"type":  "string",
+		})
+	}
+	return list
+}
+
+// postmanAuth converts collection auth to the auth of a Postman collection or
+// folder
+func postmanAuth(auth *models.CollectionAuth) map[string]interface{} {
+	pairs := func(kind string, values ...string) map[string]interface{} {
+		list := []interface{}{}
+		for i := 0; i+1 < len(values); i += 2 {
+			if values[i+1] == "" {
+				continue
+			}
+			list = append(list, map[string]interface{}{
+				"key":   values[i],
+				"value": convertReferences(values[i+1], mustacheReference),
+				"type":  "string",
+			})
+		}
+		return map[string]interface{}{"type": kind, kind: list}
+	}
+
+	switch {
+	case auth.Type == "bearer" && auth.Bearer != nil:
+		return pairs("bearer", "token", auth.Bearer.Token)
+	case auth.Type == "basic" && auth.Basic != nil:
+		return pairs("basic", "username", auth.Basic.Username, "password", auth.Basic.Password)
+	case auth.Type == "api_key" && auth.APIKey != nil:
+		in := auth.APIKey.In
+		if in == "" {
+			in = "header"
+		}
+		return pairs("apikey", "key", auth.APIKey.Key, "value", auth.APIKey.Value, "in", in)
+	case auth.Type == "oauth2" && auth.OAuth2 != nil:
+		o := auth.OAuth2
+		grantType := o.GrantType
+		if grantType == "password" {
+			grantType = "password_credentials"
+		}
+		return pairs("oauth2",
+			"grant_type", grantType,
+			"clientId", o.ClientID,
+			"clientSecret", o.ClientSecret,
+			"authUrl", o.AuthURL,
+			"accessTokenUrl", o.TokenURL,
+			"redirect_uri", o.RedirectURI,
+			"scope", o.Scope,
+			"accessToken", o.AccessToken,
+		)
+	}
+	return map[string]interface{}{"type": "noauth"}
+}
@@ -0,0 +1,244 @@
+package exporter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/georgi-georgiev/testmesh/internal/importer"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+)
+
+// roundTripFlow logs in and reads the profile with the token of the login
+func roundTripFlow() *models.Flow {
+	return &models.Flow{
+		Name: "Profile",
+		Definition: models.FlowDefinition{
+			Name: "Profile",
+			Steps: []models.Step{
+				{
+					ID:     "login",
+					Action: "http_request",
+					Name:   "Log in",
+					Config: map[string]interface{}{
+						"method":  "POST",
+						"url":     "${BASE_URL}/login",
+						"headers": map[string]interface{}{"Content-Type": "application/json"},
+						"body":    map[string]interface{}{"user": "${USER}", "password": "secret"},
+					},
+					Assert: []string{"status == 200"},
+					Output: map[string]string{"token": "$.body.token"},
+				},
+				{
+					ID:     "profile",
+					Action: "http_request",
+					Name:   "Read profile",
+					Config: map[string]interface{}{
+						"method":  "GET",
+						"url":     "${BASE_URL}/profile",
+						"headers": map[string]interface{}{"Authorization": "Bearer ${login.token}"},
+					},
+					Assert: []string{"status == 200", `body.name == "alice"`},
+					Output: map[string]string{"first_role": "$.body.roles[0]"},
+				},
+			},
+		},
+	}
+}
+
+// roundTripFormats are the collection formats with their importers
+var roundTripFormats = []struct {
+	format ExportFormat
+	parse  func(*ExportResult) (*importer.CollectionImportResult, error)
+}{
+	{FormatPostman, func(r *ExportResult) (*importer.CollectionImportResult, error) {
+		return importer.ParsePostman(r.Content)
+	}},
+	{FormatInsomnia, func(r *ExportResult) (*importer.CollectionImportResult, error) {
+		return importer.ParseInsomnia(r.Content)
+	}},
+	{FormatBruno, func(r *ExportResult) (*importer.CollectionImportResult, error) {
+		files := make(map[string]string, len(r.Files))
+		for _, file := range r.Files {
+			files[file.Path] = file.Content
+		}
+		return importer.ParseBruno(files)
+	}},
+}
+
+func TestCollectionRoundTrip(t *testing.T) {
+	for _, tt := range roundTripFormats {
+		t.Run(string(tt.format), func(t *testing.T) {
+			flow := roundTripFlow()
+			exported, err := ExportFlows([]*models.Flow{flow}, ExportOptions{Format: tt.format})
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			imported, err := tt.parse(exported)
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(imported.Flows) != 1 {
+				t.Fatalf("imported %d flows, want 1", len(imported.Flows))
+			}
+
+			want := flow.Definition.Steps
+			got := imported.Flows[0].Steps
+			if len(got) != len(want) {
+				t.Fatalf("imported %d steps, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].ID != want[i].ID || got[i].Action != want[i].Action || got[i].Name != want[i].Name {
+					t.Errorf("step %d = %s %s %q, want %s %s %q", i, got[i].ID, got[i].Action, got[i].Name, want[i].ID, want[i].Action, want[i].Name)
+				}
+				if !reflect.DeepEqual(got[i].Config, want[i].Config) {
+					t.Errorf("step %s config = %v, want %v", want[i].ID, got[i].Config, want[i].Config)
+				}
+				if !reflect.DeepEqual(got[i].Assert, want[i].Assert) {
+					t.Errorf("step %s asserts = %q, want %q", want[i].ID, got[i].Assert, want[i].Assert)
+				}
+				if !reflect.DeepEqual(got[i].Output, want[i].Output) {
+					t.Errorf("step %s outputs = %v, want %v", want[i].ID, got[i].Output, want[i].Output)
+				}
+			}
+		})
+	}
+}
+
+// roundTripCollections are a collection with a variable and two folders,
+// one with bearer auth and a variable, and one with a nested folder
+func roundTripCollections() []models.Collection {
+	shop, accounts, orders, refunds := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	return []models.Collection{
+		{
+			ID:        shop,
+			Name:      "Shop",
+			Variables: models.CollectionVariables{Global: map[string]interface{}{"BASE_URL": "https://shop.test"}},
+		},
+		{
+			ID:        accounts,
+			Name:      "Accounts",
+			ParentID:  &shop,
+			SortOrder: 1,
+			Variables: models.CollectionVariables{Global: map[string]interface{}{"ROLE": "admin"}},
+			Auth: models.CollectionAuth{
+				Type:   "bearer",
+				Bearer: &models.CollectionBearerAuth{Token: "${TOKEN}"},
+			},
+		},
+		{ID: orders, Name: "Orders", ParentID: &shop, SortOrder: 2},
+		{ID: refunds, Name: "Refunds", ParentID: &orders},
+	}
+}
+
+// requestFlow is a flow of a collection with a single GET request
+func requestFlow(name string, collectionID uuid.UUID, order int) *models.Flow {
+	return &models.Flow{
+		Name:         name,
+		CollectionID: &collectionID,
+		SortOrder:    order,
+		Definition: models.FlowDefinition{
+			Name: name,
+			Steps: []models.Step{{
+				ID:     "get",
+				Action: "http_request",
+				Config: map[string]interface{}{"method": "GET", "url": "${BASE_URL}/" + strings.ToLower(name)},
+			}},
+		},
+	}
+}
+
+func TestCollectionRoundTripFolders(t *testing.T) {
+	for _, tt := range roundTripFormats {
+		t.Run(string(tt.format), func(t *testing.T) {
+			collections := roundTripCollections()
+			flows := []*models.Flow{
+				requestFlow("Profile", collections[1].ID, 0),
+				requestFlow("Checkout", collections[2].ID, 0),
+				requestFlow("Refund", collections[3].ID, 0),
+			}
+			exported, err := ExportFlows(flows, ExportOptions{
+				Format:      tt.format,
+				IncludeEnv:  true,
+				Collections: collections,
+				Environment: &models.Environment{
+					Name: "staging",
+					Variables: models.EnvironmentVariables{
+						{Key: "USER", Value: "alice", Enabled: true},
+						{Key: "PASSWORD", Value: "hunter2", IsSecret: true, Enabled: true},
+						{Key: "DISABLED", Value: "off"},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			imported, err := tt.parse(exported)
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+
+			if got, want := folderTree(imported, imported.Collection), "Shop[Accounts[Profile] Orders[Checkout Refunds[Refund]]]"; got != want {
+				t.Errorf("folders = %s, want %s", got, want)
+			}
+
+			// Variables of the collection and the environment, without the
+			// values of secrets and without disabled variables
+			vars := make(map[string]string)
+			for key, value := range imported.Collection.Variables {
+				vars[key] = fmt.Sprint(value)
+			}
+			for _, v := range imported.Variables {
+				vars[v.Key] = v.Value
+			}
+			want := map[string]string{"BASE_URL": "https://shop.test", "USER": "alice", "PASSWORD": ""}
+			if !reflect.DeepEqual(vars, want) {
+				t.Errorf("variables = %v, want %v", vars, want)
+			}
+
+			accounts := findFolder(imported.Collection, "Accounts")
+			if accounts == nil {
+				t.Fatal("no Accounts folder")
+			}
+			if got := fmt.Sprint(accounts.Variables["ROLE"]); got != "admin" {
+				t.Errorf("Accounts variables = %v, want ROLE=admin", accounts.Variables)
+			}
+			if accounts.Auth == nil || accounts.Auth.Type != "bearer" || accounts.Auth.Bearer == nil || accounts.Auth.Bearer.Token != "${TOKEN}" {
+				t.Errorf("Accounts auth = %+v, want bearer ${TOKEN}", accounts.Auth)
+			}
+			if orders := findFolder(imported.Collection, "Orders"); orders == nil || orders.Auth != nil {
+				t.Errorf("Orders folder = %+v, want one without auth", orders)
+			}
+		})
+	}
+}
+
+// folderTree formats the folders and flows of an imported folder, like
+// Shop[Accounts[Profile] Orders[Checkout]]
+func folderTree(result *importer.CollectionImportResult, f importer.ImportedFolder) string {
+	var items []string
+	for _, i := range f.Flows {
+		items = append(items, result.Flows[i].Name)
+	}
+	for _, child := range f.Folders {
+		items = append(items, folderTree(result, child))
+	}
+	sort.Strings(items)
+	return f.Name + "[" + strings.Join(items, " ") + "]"
+}
+
+// findFolder finds a folder of an imported collection by name
+func findFolder(f importer.ImportedFolder, name string) *importer.ImportedFolder {
+	if f.Name == name {
+		return &f
+	}
+	for _, child := range f.Folders {
+		if found := findFolder(child, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package gitstatus
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// bitbucketReporter reports through the commit build statuses API
+type bitbucketReporter struct {
+	client   *http.Client
+	apiURL   string
+	token    string
+	username string
+	password string
+}
+
+// Report implements Reporter
+func (r *bitbucketReporter) Report(ctx context.Context, commit Commit, status Status) error {
+	state := "FAILED"
+	switch status.State {
+	case StatePending:
+		state = "INPROGRESS"
+	case StateSuccess:
+		state = "SUCCESSFUL"
+	}
+
+	body := map[string]interface{}{
+		"key":         status.Context,
+		"name":        status.Context,
+		"state":       state,
+		"description": truncate(status.Description, 255),
+		// Bitbucket requires a URL on every build status
+		"url": status.TargetURL,
+	}
+
+	auth := "Bearer " + r.token
+	if r.token == "" {
+		auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(r.username+":"+r.password))
+	}
+
+	endpoint := fmt.Sprintf("%s/repositories/%s/commit/%s/statuses/build", r.apiURL, repoPath(commit.Repository), url.PathEscape(commit.SHA))
+	return DoJSON(ctx, r.client, http.MethodPost, endpoint, body, nil, map[string]string{
+		"Authorization": auth,
+	})
+}
@@ -0,0 +1,70 @@
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// genericReporter posts statuses as signed JSON to a configured callback URL
+type genericReporter struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+type genericStatus struct {
+	Repository  string `json:"repository"`
+	Branch      string `json:"branch,omitempty"`
+	CommitSHA   string `json:"commit_sha"`
+	State       State  `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+// Report implements Reporter
+func (r *genericReporter) Report(ctx context.Context, commit Commit, status Status) error {
+	body, err := json.Marshal(genericStatus{
+		Repository:  commit.Repository,
+		Branch:      commit.Branch,
+		CommitSHA:   commit.SHA,
+		State:       status.State,
+		Context:     status.Context,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Summary:     status.Summary,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-TestMesh-Timestamp", timestamp)
+		req.Header.Set("X-TestMesh-Signature", Signature(r.secret, timestamp, body))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status callback returned %d", resp.StatusCode)
+	}
+	return nil
+}
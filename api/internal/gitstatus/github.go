@@ -0,0 +1,115 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitHubReporter reports through the checks API. The first report for a commit
+// creates a check run and later reports update it.
+type gitHubReporter struct {
+	client *http.Client
+	apiURL string
+	token  string
+
+	mu        sync.Mutex
+	checkRuns map[string]int64 // repository@sha/context -> check run ID
+}
+
+func newGitHubReporter(client *http.Client, apiURL, token string) *gitHubReporter {
+	return &gitHubReporter{
+		client:    client,
+		apiURL:    apiURL,
+		token:     token,
+		checkRuns: make(map[string]int64),
+	}
+}
+
+type gitHubCheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+type gitHubCheckRun struct {
+	Name        string                `json:"name,omitempty"`
+	HeadSHA     string                `json:"head_sha,omitempty"`
+	Status      string                `json:"status"`
+	Conclusion  string                `json:"conclusion,omitempty"`
+	DetailsURL  string                `json:"details_url,omitempty"`
+	StartedAt   *time.Time            `json:"started_at,omitempty"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+	Output      *gitHubCheckRunOutput `json:"output,omitempty"`
+}
+
+// Report implements Reporter
+func (r *gitHubReporter) Report(ctx context.Context, commit Commit, status Status) error {
+	now := time.Now().UTC()
+	check := gitHubCheckRun{
+		Status:     "in_progress",
+		DetailsURL: status.TargetURL,
+		Output: &gitHubCheckRunOutput{
+			Title:   status.Description,
+			Summary: status.Summary,
+		},
+	}
+	if check.Output.Summary == "" {
+		check.Output.Summary = status.Description
+	}
+
+	if status.State == StatePending {
+		check.StartedAt = &now
+	} else {
+		check.Status = "completed"
+		check.CompletedAt = &now
+		check.Conclusion = "failure"
+		if status.State == StateSuccess {
+			check.Conclusion = "success"
+		}
+	}
+
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + r.token,
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	key := commit.Repository + "@" + commit.SHA + "/" + status.Context
+
+	r.mu.Lock()
+	id, exists := r.checkRuns[key]
+	r.mu.Unlock()
+
+	if exists {
+		url := fmt.Sprintf("%s/repos/%s/check-runs/%d", r.apiURL, repoPath(commit.Repository), id)
+		return DoJSON(ctx, r.client, http.MethodPatch, url, check, nil, headers)
+	}
+
+	check.Name = status.Context
+	check.HeadSHA = commit.SHA
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/check-runs", r.apiURL, repoPath(commit.Repository))
+	if err := DoJSON(ctx, r.client, http.MethodPost, url, check, &created, headers); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.checkRuns[key] = created.ID
+	r.mu.Unlock()
+	return nil
+}
+
+// repoPath escapes each segment of an "owner/repo" path
+func repoPath(repository string) string {
+	parts := strings.Split(repository, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
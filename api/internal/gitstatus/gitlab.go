@@ -0,0 +1,44 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// gitLabReporter reports through the commit statuses API
+type gitLabReporter struct {
+	client *http.Client
+	apiURL string
+	token  string
+}
+
+// Report implements Reporter
+func (r *gitLabReporter) Report(ctx context.Context, commit Commit, status Status) error {
+	state := "failed"
+	switch status.State {
+	case StatePending:
+		state = "running"
+	case StateSuccess:
+		state = "success"
+	}
+
+	body := map[string]interface{}{
+		"state":       state,
+		"name":        status.Context,
+		"description": truncate(status.Description, 255),
+	}
+	if status.TargetURL != "" {
+		body["target_url"] = status.TargetURL
+	}
+	if commit.Branch != "" {
+		body["ref"] = commit.Branch
+	}
+
+	// The project is addressed by its URL-encoded full path
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s", r.apiURL, url.PathEscape(commit.Repository), url.PathEscape(commit.SHA))
+	return DoJSON(ctx, r.client, http.MethodPost, endpoint, body, nil, map[string]string{
+		"PRIVATE-TOKEN": r.token,
+	})
+}
@@ -0,0 +1,164 @@
+// Package gitstatus reports test results back to git providers as commit
+// statuses or check runs.
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// State is the provider-neutral state of a commit status
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateFailure State = "failure"
+	StateError   State = "error"
+)
+
+// DefaultContext is the status name used when the integration does not set one
+const DefaultContext = "testmesh"
+
+// Commit identifies the commit a status is reported for
+type Commit struct {
+	Repository string // "owner/repo", or the full project path on GitLab
+	SHA        string
+	Branch     string
+}
+
+// Status is a commit status with a short description, a link and a longer
+// markdown summary for providers that can show one
+type Status struct {
+	State       State
+	Context     string
+	Description string
+	TargetURL   string
+	Summary     string
+}
+
+// Reporter publishes commit statuses to a git provider
+type Reporter interface {
+	Report(ctx context.Context, commit Commit, status Status) error
+}
+
+// NewReporter creates the reporter for a git integration loaded with secrets.
+// It returns nil when the integration does not report statuses.
+func NewReporter(integration *models.SystemIntegration, client *http.Client) (Reporter, error) {
+	if !integration.Config.ReportStatus {
+		return nil, nil
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	apiURL := APIURL(integration)
+	token := integration.Secrets["api_token"]
+
+	switch integration.Provider {
+	case models.IntegrationProviderGitHub:
+		if token == "" {
+			return nil, fmt.Errorf("api_token is required to report GitHub check runs")
+		}
+		return newGitHubReporter(client, apiURL, token), nil
+
+	case models.IntegrationProviderGitLab:
+		if token == "" {
+			return nil, fmt.Errorf("api_token is required to report GitLab commit statuses")
+		}
+		return &gitLabReporter{client: client, apiURL: apiURL, token: token}, nil
+
+	case models.IntegrationProviderBitbucket:
+		username, password := integration.Secrets["username"], integration.Secrets["app_password"]
+		if token == "" && (username == "" || password == "") {
+			return nil, fmt.Errorf("api_token or username and app_password are required to report Bitbucket build statuses")
+		}
+		return &bitbucketReporter{client: client, apiURL: apiURL, token: token, username: username, password: password}, nil
+
+	case models.IntegrationProviderGeneric:
+		if apiURL == "" {
+			return nil, fmt.Errorf("api_url is required to report statuses for generic webhooks")
+		}
+		return &genericReporter{client: client, url: apiURL, secret: integration.Secrets["webhook_secret"]}, nil
+	}
+
+	return nil, fmt.Errorf("status reporting is not supported for provider %s", integration.Provider)
+}
+
+// APIURL returns the API base URL of a git integration, defaulting to the
+// hosted provider's API when the integration does not set one
+func APIURL(integration *models.SystemIntegration) string {
+	if apiURL := strings.TrimRight(integration.Config.APIURL, "/"); apiURL != "" {
+		return apiURL
+	}
+	switch integration.Provider {
+	case models.IntegrationProviderGitHub:
+		return "https://api.github.com"
+	case models.IntegrationProviderGitLab:
+		return "https://gitlab.com/api/v4"
+	case models.IntegrationProviderBitbucket:
+		return "https://api.bitbucket.org/2.0"
+	}
+	return ""
+}
+
+// Signature computes the signature of a generic webhook payload:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DoJSON sends a JSON request and decodes the response into out when it is not nil
+func DoJSON(ctx context.Context, client *http.Client, method, url string, body interface{}, out interface{}, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil && len(respBody) > 0 {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes, as providers limit description length
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
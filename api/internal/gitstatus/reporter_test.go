@@ -0,0 +1,221 @@
+package gitstatus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// recordedRequest is a request received by the fake provider API
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+	Raw    []byte
+}
+
+// fakeProvider records every request and answers with status and body
+func fakeProvider(t *testing.T, status int, body string) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		req := recordedRequest{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header.Clone(), Raw: raw}
+		json.Unmarshal(raw, &req.Body)
+
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func newTestReporter(t *testing.T, provider models.IntegrationProvider, apiURL string, secrets map[string]string) Reporter {
+	t.Helper()
+	reporter, err := NewReporter(&models.SystemIntegration{
+		Type:     models.IntegrationTypeGit,
+		Provider: provider,
+		Config:   models.IntegrationConfig{APIURL: apiURL, ReportStatus: true},
+		Secrets:  secrets,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewReporter: %v", err)
+	}
+	if reporter == nil {
+		t.Fatal("NewReporter returned no reporter")
+	}
+	return reporter
+}
+
+var testCommit = Commit{Repository: "acme/payments", SHA: "0123abcd", Branch: "main"}
+
+func TestNewReporterRequiresCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider models.IntegrationProvider
+		apiURL   string
+		secrets  map[string]string
+	}{
+		{"github without token", models.IntegrationProviderGitHub, "", nil},
+		{"gitlab without token", models.IntegrationProviderGitLab, "", nil},
+		{"bitbucket without credentials", models.IntegrationProviderBitbucket, "", map[string]string{"username": "ci"}},
+		{"generic without callback", models.IntegrationProviderGeneric, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReporter(&models.SystemIntegration{
+				Provider: tt.provider,
+				Config:   models.IntegrationConfig{APIURL: tt.apiURL, ReportStatus: true},
+				Secrets:  tt.secrets,
+			}, nil)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewReporterDisabled(t *testing.T) {
+	reporter, err := NewReporter(&models.SystemIntegration{Provider: models.IntegrationProviderGitHub}, nil)
+	if err != nil || reporter != nil {
+		t.Fatalf("got %v, %v; want no reporter", reporter, err)
+	}
+}
+
+func TestGitHubReporterCreatesThenUpdatesCheckRun(t *testing.T) {
+	server, requests := fakeProvider(t, http.StatusCreated, `{"id": 42}`)
+	reporter := newTestReporter(t, models.IntegrationProviderGitHub, server.URL, map[string]string{"api_token": "gh-token"})
+
+	ctx := context.Background()
+	if err := reporter.Report(ctx, testCommit, Status{State: StatePending, Context: "testmesh", Description: "Running"}); err != nil {
+		t.Fatalf("pending report: %v", err)
+	}
+	if err := reporter.Report(ctx, testCommit, Status{State: StateFailure, Context: "testmesh", Description: "1 failed", Summary: "details"}); err != nil {
+		t.Fatalf("final report: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want 2", len(got))
+	}
+
+	create := got[0]
+	if create.Method != http.MethodPost || create.Path != "/repos/acme/payments/check-runs" {
+		t.Errorf("create: %s %s", create.Method, create.Path)
+	}
+	if auth := create.Header.Get("Authorization"); auth != "Bearer gh-token" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if create.Body["head_sha"] != "0123abcd" || create.Body["name"] != "testmesh" || create.Body["status"] != "in_progress" {
+		t.Errorf("create body = %v", create.Body)
+	}
+
+	update := got[1]
+	if update.Method != http.MethodPatch || update.Path != "/repos/acme/payments/check-runs/42" {
+		t.Errorf("update: %s %s", update.Method, update.Path)
+	}
+	if update.Body["status"] != "completed" || update.Body["conclusion"] != "failure" {
+		t.Errorf("update body = %v", update.Body)
+	}
+}
+
+func TestGitLabReporter(t *testing.T) {
+	server, requests := fakeProvider(t, http.StatusCreated, `{}`)
+	reporter := newTestReporter(t, models.IntegrationProviderGitLab, server.URL, map[string]string{"api_token": "gl-token"})
+
+	commit := Commit{Repository: "group/sub/project", SHA: "beef", Branch: "feature/x"}
+	if err := reporter.Report(context.Background(), commit, Status{State: StateSuccess, Context: "testmesh", Description: "All passed", TargetURL: "http://testmesh/runs/1"}); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	req := got[0]
+	if req.Path != "/projects/group%2Fsub%2Fproject/statuses/beef" {
+		t.Errorf("path = %s", req.Path)
+	}
+	if token := req.Header.Get("PRIVATE-TOKEN"); token != "gl-token" {
+		t.Errorf("PRIVATE-TOKEN = %q", token)
+	}
+	if req.Body["state"] != "success" || req.Body["ref"] != "feature/x" || req.Body["target_url"] != "http://testmesh/runs/1" {
+		t.Errorf("body = %v", req.Body)
+	}
+}
+
+func TestBitbucketReporterBasicAuth(t *testing.T) {
+	server, requests := fakeProvider(t, http.StatusCreated, `{}`)
+	reporter := newTestReporter(t, models.IntegrationProviderBitbucket, server.URL, map[string]string{"username": "ci", "app_password": "secret"})
+
+	if err := reporter.Report(context.Background(), testCommit, Status{State: StatePending, Context: "testmesh", Description: strings.Repeat("x", 300)}); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	req := requests()[0]
+	if req.Path != "/repositories/acme/payments/commit/0123abcd/statuses/build" {
+		t.Errorf("path = %s", req.Path)
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("ci:secret"))
+	if auth := req.Header.Get("Authorization"); auth != want {
+		t.Errorf("Authorization = %q, want %q", auth, want)
+	}
+	if req.Body["state"] != "INPROGRESS" {
+		t.Errorf("state = %v", req.Body["state"])
+	}
+	if description, _ := req.Body["description"].(string); len(description) != 255 {
+		t.Errorf("description is %d bytes, want it truncated to 255", len(description))
+	}
+}
+
+func TestGenericReporterSignsPayload(t *testing.T) {
+	server, requests := fakeProvider(t, http.StatusOK, ``)
+	reporter := newTestReporter(t, models.IntegrationProviderGeneric, server.URL, map[string]string{"webhook_secret": "shh"})
+
+	if err := reporter.Report(context.Background(), testCommit, Status{State: StateFailure, Context: "testmesh", Description: "failed"}); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	req := requests()[0]
+	timestamp := req.Header.Get("X-TestMesh-Timestamp")
+	if timestamp == "" {
+		t.Fatal("missing X-TestMesh-Timestamp")
+	}
+	if got, want := req.Header.Get("X-TestMesh-Signature"), Signature("shh", timestamp, req.Raw); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if req.Body["commit_sha"] != "0123abcd" || req.Body["state"] != "failure" {
+		t.Errorf("body = %v", req.Body)
+	}
+}
+
+func TestReporterReturnsProviderErrors(t *testing.T) {
+	server, _ := fakeProvider(t, http.StatusUnauthorized, `{"message": "Bad credentials"}`)
+	reporter := newTestReporter(t, models.IntegrationProviderGitLab, server.URL, map[string]string{"api_token": "wrong"})
+
+	err := reporter.Report(context.Background(), testCommit, Status{State: StateSuccess, Context: "testmesh"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("err = %v, want the 401 of the provider", err)
+	}
+}
@@ -0,0 +1,256 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	trackPollInterval = 5 * time.Second
+	trackTimeout      = 2 * time.Hour
+)
+
+// RunKind tells the tracker where to look up a triggered run
+type RunKind string
+
+const (
+	RunExecution RunKind = "execution"
+	RunSchedule  RunKind = "schedule_run"
+)
+
+// Run is a test run started for a commit
+type Run struct {
+	Kind       RunKind
+	ID         uuid.UUID
+	ScheduleID uuid.UUID // Set for schedule runs
+	Name       string    // Shown in the status summary
+}
+
+// runOutcome is the state of a tracked run at its last poll
+type runOutcome struct {
+	finished bool
+	passed   bool
+	detail   string
+	url      string
+}
+
+// Tracker reports a pending status for a commit, waits for the triggered runs
+// to finish and reports the combined result
+type Tracker struct {
+	execRepo     *repository.ExecutionRepository
+	scheduleRepo *repository.ScheduleRepository
+	publicURL    string
+	logger       *zap.Logger
+}
+
+// NewTracker creates a tracker. publicURL is the dashboard base URL used for status links.
+func NewTracker(execRepo *repository.ExecutionRepository, scheduleRepo *repository.ScheduleRepository, publicURL string, logger *zap.Logger) *Tracker {
+	return &Tracker{
+		execRepo:     execRepo,
+		scheduleRepo: scheduleRepo,
+		publicURL:    strings.TrimRight(publicURL, "/"),
+		logger:       logger,
+	}
+}
+
+// Track reports on the runs in the background
+func (t *Tracker) Track(reporter Reporter, commit Commit, statusContext string, runs []Run) {
+	if reporter == nil || len(runs) == 0 || commit.SHA == "" {
+		return
+	}
+	if statusContext == "" {
+		statusContext = DefaultContext
+	}
+	go t.track(reporter, commit, statusContext, runs)
+}
+
+func (t *Tracker) track(reporter Reporter, commit Commit, statusContext string, runs []Run) {
+	ctx, cancel := context.WithTimeout(context.Background(), trackTimeout)
+	defer cancel()
+
+	logger := t.logger.With(
+		zap.String("repository", commit.Repository),
+		zap.String("commit_sha", commit.SHA),
+	)
+
+	pending := Status{
+		State:       StatePending,
+		Context:     statusContext,
+		Description: fmt.Sprintf("Running %d test run(s)", len(runs)),
+		TargetURL:   t.runURL(runs[0]),
+	}
+	if err := reporter.Report(ctx, commit, pending); err != nil {
+		logger.Warn("Failed to report pending commit status", zap.Error(err))
+	}
+
+	outcomes := make([]runOutcome, len(runs))
+	ticker := time.NewTicker(trackPollInterval)
+	defer ticker.Stop()
+
+wait:
+	for !t.pollAll(runs, outcomes) {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	// Report with a fresh context so a timeout can still be reported
+	reportCtx, reportCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer reportCancel()
+
+	if err := reporter.Report(reportCtx, commit, t.finalStatus(statusContext, runs, outcomes)); err != nil {
+		logger.Warn("Failed to report commit status", zap.Error(err))
+	}
+}
+
+// pollAll refreshes the unfinished outcomes and reports whether all runs have finished
+func (t *Tracker) pollAll(runs []Run, outcomes []runOutcome) bool {
+	done := true
+	for i, run := range runs {
+		if outcomes[i].finished {
+			continue
+		}
+		outcomes[i] = t.poll(run)
+		if !outcomes[i].finished {
+			done = false
+		}
+	}
+	return done
+}
+
+// poll loads the current state of a run
+func (t *Tracker) poll(run Run) runOutcome {
+	outcome := runOutcome{url: t.runURL(run)}
+
+	switch run.Kind {
+	case RunExecution:
+		execution, err := t.execRepo.GetByID(run.ID)
+		if err != nil {
+			return outcome
+		}
+		// A failed run may have been rerun; its latest attempt decides
+		if execution.Status == models.ExecutionStatusFailed {
+			if retry, err := t.execRepo.GetLatestRetry(execution.ID); err == nil {
+				execution = retry
+			}
+		}
+		switch execution.Status {
+		case models.ExecutionStatusCompleted:
+			outcome.finished, outcome.passed = true, true
+			outcome.detail = fmt.Sprintf("passed %d/%d steps in %s", execution.PassedSteps, execution.TotalSteps, time.Duration(execution.DurationMs)*time.Millisecond)
+			if execution.Attempt > 1 {
+				outcome.detail += fmt.Sprintf(" on attempt %d", execution.Attempt)
+			}
+		case models.ExecutionStatusFailed, models.ExecutionStatusCancelled:
+			outcome.finished = true
+			outcome.passed = execution.Quarantined
+			outcome.detail = string(execution.Status)
+			if execution.Quarantined {
+				outcome.detail = "failed in quarantine"
+			}
+			if execution.Error != "" {
+				outcome.detail += ": " + execution.Error
+			}
+		}
+
+	case RunSchedule:
+		scheduleRun, err := t.scheduleRepo.GetRun(run.ID)
+		if err != nil {
+			return outcome
+		}
+		switch scheduleRun.Status {
+		case "completed":
+			outcome.finished = true
+			outcome.passed = scheduleRun.Result == "success"
+			if outcome.passed {
+				outcome.detail = "passed"
+			} else {
+				outcome.detail = "failed"
+			}
+			if scheduleRun.TotalFlows > 1 {
+				outcome.detail = fmt.Sprintf("%s, %d/%d flows passed", outcome.detail, scheduleRun.PassedFlows, scheduleRun.TotalFlows)
+			}
+			if scheduleRun.Error != "" && !outcome.passed {
+				outcome.detail += ": " + scheduleRun.Error
+			}
+		case "skipped":
+			outcome.finished = true
+			outcome.detail = "skipped"
+			if scheduleRun.Error != "" {
+				outcome.detail += ": " + scheduleRun.Error
+			}
+		}
+	}
+
+	return outcome
+}
+
+// finalStatus combines the run outcomes into one commit status
+func (t *Tracker) finalStatus(statusContext string, runs []Run, outcomes []runOutcome) Status {
+	status := Status{
+		State:     StateSuccess,
+		Context:   statusContext,
+		TargetURL: outcomes[0].url,
+	}
+
+	failed, unfinished := 0, 0
+	linked := false
+	var lines []string
+	for i, o := range outcomes {
+		icon := "✅"
+		detail := o.detail
+		switch {
+		case !o.finished:
+			icon = "⏳"
+			detail = "did not finish in time"
+			unfinished++
+		case !o.passed:
+			icon = "❌"
+			failed++
+		}
+		// Link the first run that did not pass
+		if (!o.finished || !o.passed) && !linked {
+			status.TargetURL = o.url
+			linked = true
+		}
+
+		name := runs[i].Name
+		if o.url != "" {
+			name = fmt.Sprintf("[%s](%s)", name, o.url)
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s — %s", icon, name, detail))
+	}
+
+	switch {
+	case unfinished > 0:
+		status.State = StateError
+		status.Description = fmt.Sprintf("Timed out waiting for %d of %d test run(s)", unfinished, len(runs))
+	case failed > 0:
+		status.State = StateFailure
+		status.Description = fmt.Sprintf("%d of %d test run(s) failed", failed, len(runs))
+	default:
+		status.Description = fmt.Sprintf("All %d test run(s) passed", len(runs))
+	}
+	status.Summary = strings.Join(lines, "\n")
+	return status
+}
+
+// runURL links a run in the dashboard
+func (t *Tracker) runURL(run Run) string {
+	if t.publicURL == "" {
+		return ""
+	}
+	if run.Kind == RunSchedule {
+		return fmt.Sprintf("%s/schedules/%s", t.publicURL, run.ScheduleID)
+	}
+	return fmt.Sprintf("%s/executions/%s", t.publicURL, run.ID)
+}
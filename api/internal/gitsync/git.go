@@ -0,0 +1,158 @@
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// worktree runs git commands in the local clone of a binding
+type worktree struct {
+	dir    string
+	remote string
+	config []string // -c options added to every command, e.g. credentials
+}
+
+// newWorktree prepares a worktree for a repository. Integration tokens are sent
+// as an HTTP header so they are never written to the clone's config.
+func newWorktree(dir, remote string, integration *models.SystemIntegration) *worktree {
+	w := &worktree{dir: dir, remote: remote}
+
+	if integration != nil && strings.HasPrefix(remote, "http") {
+		if token := integration.Secrets["api_token"]; token != "" {
+			user := "x-access-token"
+			switch integration.Provider {
+			case models.IntegrationProviderGitLab:
+				user = "oauth2"
+			case models.IntegrationProviderBitbucket:
+				user = "x-token-auth"
+			}
+			credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + token))
+			w.config = append(w.config, "http.extraHeader=Authorization: Basic "+credentials)
+		}
+	}
+	return w
+}
+
+// run runs a git command in the worktree and returns its trimmed output
+func (w *worktree) run(ctx context.Context, args ...string) (string, error) {
+	return w.runIn(ctx, w.dir, args...)
+}
+
+func (w *worktree) runIn(ctx context.Context, dir string, args ...string) (string, error) {
+	full := make([]string, 0, len(args)+2*len(w.config))
+	for _, c := range w.config {
+		full = append(full, "-c", c)
+	}
+	full = append(full, args...)
+
+	cmd := exec.CommandContext(ctx, "git", full...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()+" "+err.Error()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// fetch clones the repository on first use and fetches the remote branches otherwise
+func (w *worktree) fetch(ctx context.Context) error {
+	if _, err := os.Stat(w.dir + "/.git"); err != nil {
+		if err := os.MkdirAll(w.dir, 0o755); err != nil {
+			return err
+		}
+		if _, err := w.run(ctx, "init", "--quiet"); err != nil {
+			return err
+		}
+		if _, err := w.run(ctx, "remote", "add", "origin", w.remote); err != nil {
+			return err
+		}
+	} else if _, err := w.run(ctx, "remote", "set-url", "origin", w.remote); err != nil {
+		return err
+	}
+
+	_, err := w.run(ctx, "fetch", "--quiet", "--prune", "origin", "+refs/heads/*:refs/remotes/origin/*")
+	return err
+}
+
+// ValidateBranch checks that name is a valid branch name. Names starting
+// with "-" are rejected so that they cannot be read as git options.
+func ValidateBranch(ctx context.Context, name string) error {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	cmd := exec.CommandContext(ctx, "git", "check-ref-format", "--branch", name)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// checkout resets the worktree to a remote branch, discarding local state.
+// It reports false when the remote branch does not exist.
+func (w *worktree) checkout(ctx context.Context, branch string) (bool, error) {
+	if err := ValidateBranch(ctx, branch); err != nil {
+		return false, err
+	}
+	ref := "refs/remotes/origin/" + branch
+	if _, err := w.run(ctx, "rev-parse", "--verify", "--quiet", ref); err != nil {
+		return false, nil
+	}
+	if _, err := w.run(ctx, "checkout", "--quiet", "--force", "-B", branch, ref); err != nil {
+		return true, err
+	}
+	if _, err := w.run(ctx, "clean", "-fdq"); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// blobHash returns the git object hash of a file at a revision, or "" if it does not exist there
+func (w *worktree) blobHash(ctx context.Context, rev, path string) string {
+	out, err := w.run(ctx, "rev-parse", "--verify", "--quiet", rev+":"+path)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// listBlobs returns the object hashes of the files under dir at a revision, keyed by path
+func (w *worktree) listBlobs(ctx context.Context, rev, dir string) (map[string]string, error) {
+	args := []string{"ls-tree", "-r", "-z", rev}
+	if dir != "" {
+		args = append(args, "--", dir)
+	}
+	out, err := w.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make(map[string]string)
+	for _, entry := range strings.Split(out, "\x00") {
+		// <mode> SP <type> SP <hash> TAB <path>
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) == 3 && fields[1] == "blob" {
+			blobs[path] = fields[2]
+		}
+	}
+	return blobs, nil
+}
+
+// head returns the commit checked out in the worktree
+func (w *worktree) head(ctx context.Context) (string, error) {
+	return w.run(ctx, "rev-parse", "HEAD")
+}
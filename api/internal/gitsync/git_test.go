@@ -0,0 +1,177 @@
+package gitsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitIdentity lets tests commit without a global git configuration
+var gitIdentity = []string{"user.name=TestMesh", "user.email=testmesh@example.com", "init.defaultBranch=main"}
+
+// newBareRepo creates a bare repository with a main branch holding
+// flows/login.yaml and a feature branch, and returns its path
+func newBareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	seed := &worktree{dir: filepath.Join(root, "seed"), config: gitIdentity}
+	ctx := context.Background()
+
+	mustRun(t, seed, root, "init", "--quiet", "--bare", remote)
+	mustRun(t, seed, root, "clone", "--quiet", remote, seed.dir)
+	if err := os.MkdirAll(filepath.Join(seed.dir, "flows"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(seed.dir, "flows", "login.yaml"), []byte("flow:\n  name: Login\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"checkout", "--quiet", "-b", "main"},
+		{"add", "--", "flows/login.yaml"},
+		{"commit", "--quiet", "-m", "Add login flow"},
+		{"push", "--quiet", "origin", "HEAD:refs/heads/main"},
+		{"push", "--quiet", "origin", "HEAD:refs/heads/feature/login"},
+	} {
+		if _, err := seed.run(ctx, args...); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return remote
+}
+
+func mustRun(t *testing.T, w *worktree, dir string, args ...string) {
+	t.Helper()
+	if _, err := w.runIn(context.Background(), dir, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	valid := []string{"main", "feature/login", "release-1.2", "testmesh/sync"}
+	for _, name := range valid {
+		if err := ValidateBranch(context.Background(), name); err != nil {
+			t.Errorf("ValidateBranch(%q) = %v, want nil", name, err)
+		}
+	}
+
+	invalid := []string{"", "-B", "--upload-pack=touch /tmp/pwned", "--orphan", "a..b", "feature/", "has space", "lock.lock", "tip~1", "ref^", "x:y", "@{-1}"}
+	for _, name := range invalid {
+		if err := ValidateBranch(context.Background(), name); err == nil {
+			t.Errorf("ValidateBranch(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestWorktreeCheckoutFromBareRepo(t *testing.T) {
+	remote := newBareRepo(t)
+	ctx := context.Background()
+	w := newWorktree(filepath.Join(t.TempDir(), "clone"), remote, nil)
+	w.config = append(w.config, gitIdentity...)
+
+	if err := w.fetch(ctx); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	found, err := w.checkout(ctx, "feature/login")
+	if err != nil || !found {
+		t.Fatalf("checkout feature/login = %v, %v", found, err)
+	}
+	blobs, err := w.listBlobs(ctx, "HEAD", "flows")
+	if err != nil {
+		t.Fatalf("listBlobs: %v", err)
+	}
+	if _, ok := blobs["flows/login.yaml"]; !ok || len(blobs) != 1 {
+		t.Fatalf("blobs = %v, want flows/login.yaml", blobs)
+	}
+	if hash := w.blobHash(ctx, "HEAD", "flows/login.yaml"); hash != blobs["flows/login.yaml"] {
+		t.Errorf("blobHash = %q, want %q", hash, blobs["flows/login.yaml"])
+	}
+
+	// Local changes are discarded by the next checkout
+	path := filepath.Join(w.dir, "flows", "login.yaml")
+	if err := os.WriteFile(path, []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(w.dir, "stray.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := w.checkout(ctx, "main"); err != nil || !found {
+		t.Fatalf("checkout main = %v, %v", found, err)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "name: Login") {
+		t.Errorf("login.yaml = %q, want the committed content", content)
+	}
+	if _, err := os.Stat(filepath.Join(w.dir, "stray.txt")); !os.IsNotExist(err) {
+		t.Errorf("untracked file survived checkout")
+	}
+
+	if found, err := w.checkout(ctx, "missing"); err != nil || found {
+		t.Errorf("checkout missing = %v, %v; want not found", found, err)
+	}
+}
+
+func TestWorktreeCheckoutRejectsOptionBranches(t *testing.T) {
+	remote := newBareRepo(t)
+	ctx := context.Background()
+	w := newWorktree(filepath.Join(t.TempDir(), "clone"), remote, nil)
+	if err := w.fetch(ctx); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	for _, branch := range []string{"--orphan", "-f", "--upload-pack=touch pwned"} {
+		found, err := w.checkout(ctx, branch)
+		if err == nil || found {
+			t.Errorf("checkout(%q) = %v, %v; want an error", branch, found, err)
+		}
+	}
+}
+
+func TestWorktreePushesToBareRepo(t *testing.T) {
+	remote := newBareRepo(t)
+	ctx := context.Background()
+
+	w := newWorktree(filepath.Join(t.TempDir(), "clone"), remote, nil)
+	w.config = append(w.config, gitIdentity...)
+	if err := w.fetch(ctx); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if _, err := w.checkout(ctx, "main"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(w.dir, "flows", "signup.yaml"), []byte("flow:\n  name: Signup\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "--", "flows/signup.yaml"},
+		{"commit", "--quiet", "-m", "Add signup flow"},
+		{"push", "--quiet", "origin", "HEAD:refs/heads/testmesh/sync"},
+	} {
+		if _, err := w.run(ctx, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A second clone sees the pushed branch
+	other := newWorktree(filepath.Join(t.TempDir(), "other"), remote, nil)
+	if err := other.fetch(ctx); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if found, err := other.checkout(ctx, "testmesh/sync"); err != nil || !found {
+		t.Fatalf("checkout testmesh/sync = %v, %v", found, err)
+	}
+	if other.blobHash(ctx, "HEAD", "flows/signup.yaml") == "" {
+		t.Error("pushed file is missing from the branch")
+	}
+}
@@ -0,0 +1,80 @@
+package gitsync
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/georgi-georgiev/testmesh/internal/gitstatus"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// openPullRequest opens a pull request from head into the tracked branch
+// through the binding's git integration and returns its URL
+func (r *syncRun) openPullRequest(head, description string) (string, error) {
+	b := r.binding
+	if r.integration == nil {
+		return "", fmt.Errorf("an integration is required to open pull requests")
+	}
+	if b.Repository == "" {
+		return "", fmt.Errorf("repository is required to open pull requests")
+	}
+	token := r.integration.Secrets["api_token"]
+	if token == "" {
+		return "", fmt.Errorf("the integration has no api_token")
+	}
+
+	apiURL := gitstatus.APIURL(r.integration)
+	title := fmt.Sprintf("Update flows in %s from TestMesh", b.Path)
+	if b.Path == "" {
+		title = "Update flows from TestMesh"
+	}
+
+	switch r.integration.Provider {
+	case models.IntegrationProviderGitHub:
+		var pr struct {
+			HTMLURL string `json:"html_url"`
+		}
+		err := gitstatus.DoJSON(r.ctx, r.client, http.MethodPost,
+			fmt.Sprintf("%s/repos/%s/pulls", apiURL, b.Repository),
+			map[string]interface{}{"title": title, "head": head, "base": b.Branch, "body": description},
+			&pr,
+			map[string]string{
+				"Authorization":        "Bearer " + token,
+				"Accept":               "application/vnd.github+json",
+				"X-GitHub-Api-Version": "2022-11-28",
+			})
+		return pr.HTMLURL, err
+
+	case models.IntegrationProviderGitLab:
+		var mr struct {
+			WebURL string `json:"web_url"`
+		}
+		err := gitstatus.DoJSON(r.ctx, r.client, http.MethodPost,
+			fmt.Sprintf("%s/projects/%s/merge_requests", apiURL, url.PathEscape(b.Repository)),
+			map[string]interface{}{"title": title, "source_branch": head, "target_branch": b.Branch, "description": description},
+			&mr,
+			map[string]string{"PRIVATE-TOKEN": token})
+		return mr.WebURL, err
+
+	case models.IntegrationProviderBitbucket:
+		var pr struct {
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		}
+		branch := func(name string) map[string]interface{} {
+			return map[string]interface{}{"branch": map[string]string{"name": name}}
+		}
+		err := gitstatus.DoJSON(r.ctx, r.client, http.MethodPost,
+			fmt.Sprintf("%s/repositories/%s/pullrequests", apiURL, b.Repository),
+			map[string]interface{}{"title": title, "description": description, "source": branch(head), "destination": branch(b.Branch)},
+			&pr,
+			map[string]string{"Authorization": "Bearer " + token})
+		return pr.Links.HTML.Href, err
+	}
+
+	return "", fmt.Errorf("pull requests are not supported for provider %s", r.integration.Provider)
+}
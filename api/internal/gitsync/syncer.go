@@ -0,0 +1,894 @@
+// Package gitsync keeps collections in sync with flow YAML files in git
+// repositories. Files changed in git are pulled into flows, and flows edited in
+// TestMesh are committed back, optionally on a branch with a pull request.
+// Conflicts are detected from the flow version history: a file changed in git
+// whose flow also has versions newer than the last sync is not overwritten.
+package gitsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/parser"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrSyncInProgress is returned when another sync holds the binding
+var ErrSyncInProgress = errors.New("a sync is already running for this binding")
+
+const (
+	syncPollInterval = 30 * time.Second
+	syncTimeout      = 5 * time.Minute
+	commitAuthor     = "TestMesh"
+	commitEmail      = "testmesh@localhost"
+)
+
+// Keep resolves a conflict in favour of one side
+type Keep string
+
+const (
+	KeepGit      Keep = "git"
+	KeepTestMesh Keep = "testmesh"
+)
+
+// Conflict is a file changed both in git and in TestMesh since the last sync
+type Conflict struct {
+	FilePath string    `json:"file_path"`
+	FlowID   uuid.UUID `json:"flow_id"`
+	Reason   string    `json:"reason"`
+}
+
+// Result summarizes a sync. File lists hold paths relative to the binding path.
+type Result struct {
+	Commit         string     `json:"commit,omitempty"`
+	Created        []string   `json:"created,omitempty"`
+	Updated        []string   `json:"updated,omitempty"`
+	Deleted        []string   `json:"deleted,omitempty"`
+	Pushed         []string   `json:"pushed,omitempty"`
+	Removed        []string   `json:"removed,omitempty"`
+	Conflicts      []Conflict `json:"conflicts,omitempty"`
+	Errors         []string   `json:"errors,omitempty"` // Files that could not be parsed
+	PullRequestURL string     `json:"pull_request_url,omitempty"`
+}
+
+func (r *Result) conflict(file *models.GitSyncFile) {
+	r.Conflicts = append(r.Conflicts, Conflict{FilePath: file.FilePath, FlowID: file.FlowID, Reason: file.Conflict})
+}
+
+// Syncer pulls and pushes bound collections
+type Syncer struct {
+	repo            *repository.GitSyncRepository
+	flowRepo        *repository.FlowRepository
+	versionRepo     *repository.CollaborationRepository
+	integrationRepo *repository.IntegrationRepository
+	workDir         string
+	client          *http.Client
+	logger          *zap.Logger
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewSyncer creates a syncer that keeps its clones under workDir
+func NewSyncer(
+	repo *repository.GitSyncRepository,
+	flowRepo *repository.FlowRepository,
+	versionRepo *repository.CollaborationRepository,
+	integrationRepo *repository.IntegrationRepository,
+	workDir string,
+	logger *zap.Logger,
+) *Syncer {
+	return &Syncer{
+		repo:            repo,
+		flowRepo:        flowRepo,
+		versionRepo:     versionRepo,
+		integrationRepo: integrationRepo,
+		workDir:         workDir,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		logger:          logger,
+	}
+}
+
+// Start syncs bindings with a sync interval in the background
+func (s *Syncer) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	go s.loop(ctx)
+	s.logger.Info("Git sync started")
+}
+
+// Stop stops the background sync and waits for it to exit
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	<-s.done
+	s.logger.Info("Git sync stopped")
+}
+
+func (s *Syncer) loop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncDue()
+		}
+	}
+}
+
+// syncDue syncs the bindings whose interval has elapsed since their last sync
+func (s *Syncer) syncDue() {
+	bindings, err := s.repo.ListEnabled()
+	if err != nil {
+		s.logger.Error("Failed to list git sync bindings", zap.Error(err))
+		return
+	}
+
+	for _, binding := range bindings {
+		if binding.SyncInterval == "" {
+			continue
+		}
+		interval, err := time.ParseDuration(binding.SyncInterval)
+		if err != nil || interval <= 0 {
+			continue
+		}
+		if binding.LastSyncedAt != nil && time.Since(*binding.LastSyncedAt) < interval {
+			continue
+		}
+		go s.syncInBackground(binding.ID, "interval")
+	}
+}
+
+// RepositoryPushed syncs the bindings tracking a branch after a webhook push event
+func (s *Syncer) RepositoryPushed(repository, branch string) {
+	bindings, err := s.repo.FindByRepository(repository, branch)
+	if err != nil {
+		s.logger.Error("Failed to find git sync bindings",
+			zap.String("repository", repository),
+			zap.Error(err))
+		return
+	}
+	for _, binding := range bindings {
+		go s.syncInBackground(binding.ID, "webhook")
+	}
+}
+
+// FlowSaved records a version of a flow edited in TestMesh and, when its
+// collection is bound to git, commits it in the background
+func (s *Syncer) FlowSaved(flow *models.Flow, authorID uuid.UUID) {
+	binding := s.bindingOf(flow)
+	if binding == nil {
+		return
+	}
+
+	content, err := parser.ToYAML(&flow.Definition)
+	if err != nil {
+		s.logger.Warn("Failed to serialize flow", zap.String("flow_id", flow.ID.String()), zap.Error(err))
+		return
+	}
+	if err := s.versionRepo.CreateFlowVersion(&models.FlowVersion{
+		FlowID:   flow.ID,
+		Content:  content,
+		AuthorID: authorID,
+		Message:  "Edited in TestMesh",
+	}); err != nil {
+		s.logger.Warn("Failed to record flow version", zap.String("flow_id", flow.ID.String()), zap.Error(err))
+		return
+	}
+
+	go s.syncInBackground(binding.ID, "edit")
+}
+
+// FlowDeleted removes a deleted flow's file in the background when its collection is bound to git
+func (s *Syncer) FlowDeleted(flow *models.Flow) {
+	if binding := s.bindingOf(flow); binding != nil {
+		go s.syncInBackground(binding.ID, "edit")
+	}
+}
+
+func (s *Syncer) bindingOf(flow *models.Flow) *models.GitSyncBinding {
+	if flow.CollectionID == nil {
+		return nil
+	}
+	binding, err := s.repo.GetByCollection(*flow.CollectionID)
+	if err != nil || !binding.Enabled {
+		return nil
+	}
+	return binding
+}
+
+// syncInBackground syncs a binding, retrying for a while when another sync holds it
+// so that edits made during a sync are still pushed
+func (s *Syncer) syncInBackground(bindingID uuid.UUID, trigger string) {
+	logger := s.logger.With(zap.String("binding_id", bindingID.String()), zap.String("trigger", trigger))
+
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(10 * time.Second)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+		result, err := s.Sync(ctx, bindingID)
+		cancel()
+
+		if errors.Is(err, ErrSyncInProgress) {
+			continue
+		}
+		if err != nil {
+			logger.Error("Git sync failed", zap.Error(err))
+			return
+		}
+		logger.Info("Git sync finished",
+			zap.String("commit", result.Commit),
+			zap.Int("created", len(result.Created)),
+			zap.Int("updated", len(result.Updated)),
+			zap.Int("deleted", len(result.Deleted)),
+			zap.Int("pushed", len(result.Pushed)+len(result.Removed)),
+			zap.Int("conflicts", len(result.Conflicts)))
+		return
+	}
+	logger.Warn("Gave up waiting for a running git sync")
+}
+
+// Sync pulls changes from git into the collection, then commits the flows
+// edited in TestMesh back to git
+func (s *Syncer) Sync(ctx context.Context, bindingID uuid.UUID) (*Result, error) {
+	return s.withBinding(ctx, bindingID, func(b *syncRun) error {
+		if err := b.pull(); err != nil {
+			return err
+		}
+		return b.push()
+	})
+}
+
+// Resolve resolves a conflicting file by keeping the git or the TestMesh side, then syncs
+func (s *Syncer) Resolve(ctx context.Context, bindingID, flowID uuid.UUID, keep Keep) (*Result, error) {
+	if keep != KeepGit && keep != KeepTestMesh {
+		return nil, fmt.Errorf("keep must be %q or %q", KeepGit, KeepTestMesh)
+	}
+
+	return s.withBinding(ctx, bindingID, func(b *syncRun) error {
+		if err := b.resolve(flowID, keep); err != nil {
+			return err
+		}
+		if err := b.pull(); err != nil {
+			return err
+		}
+		return b.push()
+	})
+}
+
+// syncRun is the state of one sync of a binding
+type syncRun struct {
+	*Syncer
+	ctx         context.Context
+	binding     *models.GitSyncBinding
+	integration *models.SystemIntegration
+	worktree    *worktree
+	result      *Result
+}
+
+// withBinding claims a binding, fetches its repository and records the outcome of fn
+func (s *Syncer) withBinding(ctx context.Context, bindingID uuid.UUID, fn func(*syncRun) error) (*Result, error) {
+	claimed, err := s.repo.ClaimSync(bindingID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrSyncInProgress
+	}
+
+	run := &syncRun{Syncer: s, ctx: ctx, result: &Result{}}
+	err = run.prepare(bindingID)
+	if err == nil {
+		err = fn(run)
+	}
+
+	status := models.GitSyncStatusIdle
+	errorMsg := ""
+	if err != nil {
+		status = models.GitSyncStatusError
+		errorMsg = err.Error()
+	} else if run.hasConflicts() {
+		status = models.GitSyncStatusConflict
+	}
+	if finishErr := s.repo.FinishSync(bindingID, status, run.result.Commit, errorMsg, err == nil); finishErr != nil {
+		s.logger.Error("Failed to record git sync", zap.String("binding_id", bindingID.String()), zap.Error(finishErr))
+	}
+
+	if err != nil {
+		return run.result, err
+	}
+	return run.result, nil
+}
+
+func (r *syncRun) prepare(bindingID uuid.UUID) error {
+	binding, err := r.repo.Get(bindingID)
+	if err != nil {
+		return err
+	}
+	r.binding = binding
+
+	if binding.IntegrationID != nil {
+		integration, err := r.integrationRepo.GetWithSecrets(*binding.IntegrationID)
+		if err != nil {
+			return fmt.Errorf("failed to load integration: %w", err)
+		}
+		r.integration = integration
+	}
+
+	r.worktree = newWorktree(filepath.Join(r.workDir, binding.ID.String()), binding.RepositoryURL, r.integration)
+	return r.worktree.fetch(r.ctx)
+}
+
+func (r *syncRun) hasConflicts() bool {
+	files, err := r.repo.ListFiles(r.binding.ID)
+	if err != nil {
+		return len(r.result.Conflicts) > 0
+	}
+	for _, file := range files {
+		if file.Conflict != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// dir is the binding path in the repository, "" for the root
+func (r *syncRun) dir() string {
+	dir := path.Clean("/" + strings.TrimSpace(r.binding.Path))
+	return strings.TrimPrefix(dir, "/")
+}
+
+// repoPath turns a path relative to the binding path into a repository path
+func (r *syncRun) repoPath(file string) string {
+	return path.Join(r.dir(), file)
+}
+
+// baseRef is the fetched branch the binding tracks
+func (r *syncRun) baseRef() string {
+	return "refs/remotes/origin/" + r.binding.Branch
+}
+
+// pull applies the files changed in git since the last sync to the collection
+func (r *syncRun) pull() error {
+	b := r.binding
+	found, err := r.worktree.checkout(r.ctx, b.Branch)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("branch %s not found in %s", b.Branch, b.RepositoryURL)
+	}
+	commit, err := r.worktree.head(r.ctx)
+	if err != nil {
+		return err
+	}
+	r.result.Commit = commit
+
+	blobs, err := r.worktree.listBlobs(r.ctx, "HEAD", r.dir())
+	if err != nil {
+		return err
+	}
+	states, err := r.repo.ListFiles(b.ID)
+	if err != nil {
+		return err
+	}
+	flows, err := r.flowRepo.ListByCollection(b.WorkspaceID, b.CollectionID)
+	if err != nil {
+		return err
+	}
+
+	byPath := make(map[string]*models.GitSyncFile, len(states))
+	linked := make(map[uuid.UUID]bool, len(states))
+	for i := range states {
+		byPath[states[i].FilePath] = &states[i]
+		linked[states[i].FlowID] = true
+	}
+	byName := make(map[string]*models.Flow, len(flows))
+	for i := range flows {
+		if !linked[flows[i].ID] {
+			byName[strings.ToLower(flows[i].Name)] = &flows[i]
+		}
+	}
+
+	paths := make([]string, 0, len(blobs))
+	for p := range blobs {
+		if isFlowFile(p) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	seen := make(map[string]bool, len(paths))
+	for _, repoPath := range paths {
+		file := strings.TrimPrefix(strings.TrimPrefix(repoPath, r.dir()), "/")
+		hash := blobs[repoPath]
+		seen[file] = true
+
+		state := byPath[file]
+		if state != nil && state.ContentHash == hash {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(r.worktree.dir, filepath.FromSlash(repoPath)))
+		if err != nil {
+			return err
+		}
+		def, err := parseFlowFile(content)
+		if err != nil {
+			r.result.Errors = append(r.result.Errors, fmt.Sprintf("%s: %v", file, err))
+			continue
+		}
+
+		if state == nil {
+			state = &models.GitSyncFile{BindingID: b.ID, FilePath: file}
+			flow := byName[strings.ToLower(def.Name)]
+			delete(byName, strings.ToLower(def.Name))
+			if err := r.pullFile(state, flow, def, string(content), hash); err != nil {
+				return err
+			}
+			if flow == nil {
+				r.result.Created = append(r.result.Created, file)
+			} else {
+				r.result.Updated = append(r.result.Updated, file)
+			}
+			continue
+		}
+
+		flow, err := r.flowRepo.GetByIDUnscoped(state.FlowID)
+		if err != nil {
+			// Deleted in TestMesh but changed in git: git brings it back
+			if err := r.pullFile(state, nil, def, string(content), hash); err != nil {
+				return err
+			}
+			r.result.Created = append(r.result.Created, file)
+			continue
+		}
+
+		latest, err := r.repo.LatestFlowVersion(flow.ID)
+		if err != nil {
+			return err
+		}
+		if latest > state.FlowVersion && !sameDefinition(&flow.Definition, def) {
+			state.Conflict = fmt.Sprintf("changed in git at %s and in TestMesh since version %d", shortSHA(commit), state.FlowVersion)
+			if err := r.repo.SaveFile(state); err != nil {
+				return err
+			}
+			r.result.conflict(state)
+			continue
+		}
+
+		if err := r.pullFile(state, flow, def, string(content), hash); err != nil {
+			return err
+		}
+		r.result.Updated = append(r.result.Updated, file)
+	}
+
+	// Files removed from git
+	for _, state := range byPath {
+		if seen[state.FilePath] || state.ContentHash == "" {
+			// Files without a hash were added in TestMesh and are not on the branch yet
+			continue
+		}
+
+		flow, err := r.flowRepo.GetByIDUnscoped(state.FlowID)
+		if err != nil {
+			if err := r.repo.DeleteFile(state.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		latest, err := r.repo.LatestFlowVersion(flow.ID)
+		if err != nil {
+			return err
+		}
+		if latest > state.FlowVersion {
+			state.Conflict = fmt.Sprintf("deleted in git at %s but changed in TestMesh since version %d", shortSHA(commit), state.FlowVersion)
+			if err := r.repo.SaveFile(state); err != nil {
+				return err
+			}
+			r.result.conflict(state)
+			continue
+		}
+
+		if err := r.flowRepo.Delete(flow.ID, b.WorkspaceID); err != nil {
+			return err
+		}
+		if err := r.repo.DeleteFile(state.ID); err != nil {
+			return err
+		}
+		r.result.Deleted = append(r.result.Deleted, state.FilePath)
+	}
+
+	return nil
+}
+
+// pullFile writes a definition from git into a flow, creating the flow when it is nil,
+// and records the new version and file state
+func (r *syncRun) pullFile(state *models.GitSyncFile, flow *models.Flow, def *models.FlowDefinition, content, hash string) error {
+	b := r.binding
+	collectionID := b.CollectionID
+
+	if flow == nil {
+		flow = &models.Flow{CollectionID: &collectionID}
+		applyDefinition(flow, def)
+		if err := r.flowRepo.Create(flow, b.WorkspaceID); err != nil {
+			return fmt.Errorf("failed to create flow for %s: %w", state.FilePath, err)
+		}
+	} else {
+		flow.CollectionID = &collectionID
+		applyDefinition(flow, def)
+		if err := r.flowRepo.Update(flow, b.WorkspaceID); err != nil {
+			return fmt.Errorf("failed to update flow for %s: %w", state.FilePath, err)
+		}
+	}
+
+	version := &models.FlowVersion{
+		FlowID:     flow.ID,
+		Content:    content,
+		AuthorName: commitAuthor,
+		Message:    fmt.Sprintf("Synced from git at %s", shortSHA(r.result.Commit)),
+	}
+	if err := r.versionRepo.CreateFlowVersion(version); err != nil {
+		return err
+	}
+
+	state.FlowID = flow.ID
+	state.ContentHash = hash
+	state.FlowVersion = version.Version
+	state.CommitSHA = r.result.Commit
+	state.Conflict = ""
+	return r.repo.SaveFile(state)
+}
+
+// pendingFile is a file written by push, recorded once the commit is pushed
+type pendingFile struct {
+	state   *models.GitSyncFile
+	hash    string
+	version int
+	removed bool
+}
+
+// push commits the flows changed in TestMesh since the last sync
+func (r *syncRun) push() error {
+	b := r.binding
+	target := b.PushBranch
+	if target == "" {
+		target = b.Branch
+	}
+
+	newBranch := false
+	if target != b.Branch {
+		found, err := r.worktree.checkout(r.ctx, target)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if _, err := r.worktree.run(r.ctx, "checkout", "--quiet", "--force", "-B", target, r.baseRef()); err != nil {
+				return err
+			}
+			newBranch = true
+		}
+	}
+
+	states, err := r.repo.ListFiles(b.ID)
+	if err != nil {
+		return err
+	}
+	flows, err := r.flowRepo.ListByCollection(b.WorkspaceID, b.CollectionID)
+	if err != nil {
+		return err
+	}
+
+	byFlow := make(map[uuid.UUID]*models.GitSyncFile, len(states))
+	used := make(map[string]bool, len(states))
+	for i := range states {
+		byFlow[states[i].FlowID] = &states[i]
+		used[states[i].FilePath] = true
+	}
+
+	var pending []pendingFile
+	inCollection := make(map[uuid.UUID]bool, len(flows))
+	for i := range flows {
+		flow := &flows[i]
+		inCollection[flow.ID] = true
+
+		latest, err := r.repo.LatestFlowVersion(flow.ID)
+		if err != nil {
+			return err
+		}
+		state := byFlow[flow.ID]
+		if state != nil && (state.Conflict != "" || latest <= state.FlowVersion) {
+			continue
+		}
+
+		if state != nil {
+			// The branch must still hold the file as last synced
+			if remote := r.worktree.blobHash(r.ctx, r.baseRef(), r.repoPath(state.FilePath)); remote != state.ContentHash {
+				state.Conflict = fmt.Sprintf("changed in git and in TestMesh since version %d", state.FlowVersion)
+				if err := r.repo.SaveFile(state); err != nil {
+					return err
+				}
+				r.result.conflict(state)
+				continue
+			}
+		} else {
+			state = &models.GitSyncFile{BindingID: b.ID, FlowID: flow.ID, FilePath: newFilePath(flow.Name, used)}
+			used[state.FilePath] = true
+		}
+
+		content, err := parser.ToYAML(&flow.Definition)
+		if err != nil {
+			return err
+		}
+		if latest == 0 {
+			// Flows created in TestMesh have no history yet
+			version := &models.FlowVersion{FlowID: flow.ID, Content: content, AuthorName: commitAuthor, Message: "Added to git"}
+			if err := r.versionRepo.CreateFlowVersion(version); err != nil {
+				return err
+			}
+			latest = version.Version
+		}
+
+		hash, err := r.writeFile(state.FilePath, content)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, pendingFile{state: state, hash: hash, version: latest})
+	}
+
+	// Flows deleted in TestMesh or moved out of the collection
+	for _, state := range byFlow {
+		if inCollection[state.FlowID] || state.Conflict != "" {
+			continue
+		}
+		if state.ContentHash == "" {
+			if err := r.repo.DeleteFile(state.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if remote := r.worktree.blobHash(r.ctx, r.baseRef(), r.repoPath(state.FilePath)); remote != state.ContentHash {
+			// Changed in git, the next pull brings the flow back
+			continue
+		}
+		if _, err := r.worktree.run(r.ctx, "rm", "--quiet", "--ignore-unmatch", "--", r.repoPath(state.FilePath)); err != nil {
+			return err
+		}
+		pending = append(pending, pendingFile{state: state, removed: true})
+	}
+
+	if staged, err := r.worktree.run(r.ctx, "diff", "--cached", "--name-only"); err != nil {
+		return err
+	} else if staged == "" {
+		return nil
+	}
+
+	if _, err := r.worktree.run(r.ctx,
+		"-c", "user.name="+commitAuthor,
+		"-c", "user.email="+commitEmail,
+		"commit", "--quiet", "-m", commitMessage(pending)); err != nil {
+		return err
+	}
+	if _, err := r.worktree.run(r.ctx, "push", "--quiet", "origin", "HEAD:refs/heads/"+target); err != nil {
+		return err
+	}
+	commit, err := r.worktree.head(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	// Files committed to a separate branch reach the tracked branch when it is merged
+	onBranch := target == b.Branch
+	for _, p := range pending {
+		if p.removed {
+			r.result.Removed = append(r.result.Removed, p.state.FilePath)
+			if onBranch {
+				if err := r.repo.DeleteFile(p.state.ID); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		r.result.Pushed = append(r.result.Pushed, p.state.FilePath)
+		p.state.FlowVersion = p.version
+		p.state.CommitSHA = commit
+		if onBranch {
+			p.state.ContentHash = p.hash
+		}
+		if err := r.repo.SaveFile(p.state); err != nil {
+			return err
+		}
+	}
+	if onBranch {
+		r.result.Commit = commit
+	}
+
+	if !onBranch && newBranch && b.CreatePullRequest {
+		url, err := r.openPullRequest(target, commitMessage(pending))
+		if err != nil {
+			return fmt.Errorf("pushed %s but failed to open a pull request: %w", target, err)
+		}
+		r.result.PullRequestURL = url
+		if err := r.repo.SetPullRequestURL(b.ID, url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes and stages a flow file, returning its git object hash
+func (r *syncRun) writeFile(file, content string) (string, error) {
+	repoPath := r.repoPath(file)
+	fullPath := filepath.Join(r.worktree.dir, filepath.FromSlash(repoPath))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	if _, err := r.worktree.run(r.ctx, "add", "--", repoPath); err != nil {
+		return "", err
+	}
+	return r.worktree.run(r.ctx, "hash-object", "--", repoPath)
+}
+
+// resolve clears a conflict so that the next pull or push takes the kept side
+func (r *syncRun) resolve(flowID uuid.UUID, keep Keep) error {
+	states, err := r.repo.ListFiles(r.binding.ID)
+	if err != nil {
+		return err
+	}
+
+	var state *models.GitSyncFile
+	for i := range states {
+		if states[i].FlowID == flowID {
+			state = &states[i]
+			break
+		}
+	}
+	if state == nil || state.Conflict == "" {
+		return fmt.Errorf("flow %s has no conflict in this binding", flowID)
+	}
+
+	remote := r.worktree.blobHash(r.ctx, r.baseRef(), r.repoPath(state.FilePath))
+	state.Conflict = ""
+
+	switch keep {
+	case KeepGit:
+		if remote == "" {
+			// Deleted in git
+			if err := r.flowRepo.Delete(flowID, r.binding.WorkspaceID); err != nil {
+				return err
+			}
+			r.result.Deleted = append(r.result.Deleted, state.FilePath)
+			return r.repo.DeleteFile(state.ID)
+		}
+		latest, err := r.repo.LatestFlowVersion(flowID)
+		if err != nil {
+			return err
+		}
+		// Treat the TestMesh edits as synced so the pull overwrites them
+		state.FlowVersion = latest
+		state.ContentHash = ""
+
+	case KeepTestMesh:
+		// Treat the git change as synced so the push overwrites it
+		state.ContentHash = remote
+	}
+
+	return r.repo.SaveFile(state)
+}
+
+// parseFlowFile parses a flow file in the wrapped (flow:) or unwrapped format
+func parseFlowFile(content []byte) (*models.FlowDefinition, error) {
+	var wrapped map[string]interface{}
+	if err := yaml.Unmarshal(content, &wrapped); err != nil {
+		return nil, err
+	}
+	if flow, ok := wrapped["flow"].(map[string]interface{}); ok {
+		unwrapped, err := yaml.Marshal(flow)
+		if err != nil {
+			return nil, err
+		}
+		content = unwrapped
+	}
+	return parser.ParseYAML(string(content))
+}
+
+func isFlowFile(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func applyDefinition(flow *models.Flow, def *models.FlowDefinition) {
+	flow.Name = def.Name
+	flow.Description = def.Description
+	flow.Suite = def.Suite
+	flow.Tags = def.Tags
+	flow.Definition = *def
+}
+
+// sameDefinition reports whether two definitions are equal, so that the same
+// change made on both sides is not a conflict
+func sameDefinition(a, b *models.FlowDefinition) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newFilePath names the file of a flow added in TestMesh
+func newFilePath(name string, used map[string]bool) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		slug = "flow"
+	}
+	file := slug + ".yaml"
+	for i := 2; used[file]; i++ {
+		file = fmt.Sprintf("%s-%d.yaml", slug, i)
+	}
+	return file
+}
+
+func commitMessage(files []pendingFile) string {
+	var lines []string
+	for _, f := range files {
+		if f.removed {
+			lines = append(lines, "- Remove "+f.state.FilePath)
+		} else {
+			lines = append(lines, "- Update "+f.state.FilePath)
+		}
+	}
+	return fmt.Sprintf("Update %d flow(s) from TestMesh\n\n%s", len(files), strings.Join(lines, "\n"))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
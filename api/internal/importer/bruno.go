@@ -0,0 +1,441 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// bruFile is a parsed .bru file: its blocks by name, like meta, post and
+// body:json
+type bruFile map[string]*bruBlock
+
+// bruBlock is a block of a .bru file. Dictionary blocks have pairs, list
+// blocks items and the other blocks text.
+type bruBlock struct {
+	Pairs [][2]string
+	Items []string
+	Text  string
+}
+
+// get returns the value of a name of a dictionary block
+func (f bruFile) get(block, name string) string {
+	if b, ok := f[block]; ok {
+		for _, pair := range b.Pairs {
+			if pair[0] == name {
+				return pair[1]
+			}
+		}
+	}
+	return ""
+}
+
+// bruTextBlocks are the blocks whose content is text, not pairs
+var bruTextBlocks = regexp.MustCompile(`^(body(:[\w-]+)?|script:[\w-]+|tests|docs)$`)
+
+var (
+	bruBlockStart = regexp.MustCompile(`^([\w:~-]+)\s*([{\[])\s*$`)
+	bruMethods    = []string{"get", "post", "put", "patch", "delete", "options", "head"}
+)
+
+// parseBru parses a .bru file
+func parseBru(content string) (bruFile, error) {
+	file := make(bruFile)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m := bruBlockStart.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected a block, found %q", i+1, line)
+		}
+		name, end := m[1], "}"
+		if m[2] == "[" {
+			end = "]"
+		}
+
+		var body []string
+		for i++; i < len(lines) && strings.TrimRight(lines[i], " \t") != end; i++ {
+			body = append(body, strings.TrimPrefix(lines[i], "  "))
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("block %s is not closed", name)
+		}
+
+		block := &bruBlock{}
+		switch {
+		case m[2] == "[":
+			for _, item := range body {
+				if item = strings.TrimSuffix(strings.TrimSpace(item), ","); item != "" {
+					block.Items = append(block.Items, item)
+				}
+			}
+		case bruTextBlocks.MatchString(name):
+			block.Text = strings.Join(body, "\n")
+		default:
+			for _, item := range body {
+				item = strings.TrimSpace(item)
+				if item == "" || strings.HasPrefix(item, "~") {
+					continue
+				}
+				key, value, _ := strings.Cut(item, ":")
+				block.Pairs = append(block.Pairs, [2]string{strings.TrimSpace(key), strings.TrimSpace(value)})
+			}
+		}
+		file[name] = block
+	}
+	return file, nil
+}
+
+// ParseBruno parses the files of a Bruno collection, by their paths in the
+// collection. Folders of requests become flows; variables set after the
+// response, assertions and tests become outputs and assertions of steps.
+func ParseBruno(files map[string]string) (*CollectionImportResult, error) {
+	// The collection is the folder of bruno.json, which archives may nest
+	base := ""
+	found := false
+	for name := range files {
+		if path.Base(name) == "bruno.json" && (!found || len(name) < len(base)) {
+			base, found = path.Dir(name), true
+		}
+	}
+	if base == "." {
+		base = ""
+	}
+
+	result := &CollectionImportResult{}
+	root := &clientFolder{Name: "Bruno Collection"}
+	if data, ok := files[path.Join(base, "bruno.json")]; ok {
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(data), &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse bruno.json: %w", err)
+		}
+		if manifest.Name != "" {
+			root.Name = manifest.Name
+		}
+	}
+
+	folders := map[string]*clientFolder{"": root}
+	var folderOf func(dir string) *clientFolder
+	folderOf = func(dir string) *clientFolder {
+		if f, ok := folders[dir]; ok {
+			return f
+		}
+		f := &clientFolder{Name: path.Base(dir)}
+		folders[dir] = f
+		parent := folderOf(strings.TrimSuffix(path.Dir(dir), "."))
+		parent.Folders = append(parent.Folders, f)
+		return f
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	environments := 0
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".bru") {
+			continue
+		}
+		rel := name
+		if base != "" {
+			if !strings.HasPrefix(name, base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, base+"/")
+		}
+		file, err := parseBru(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", rel, err)
+		}
+		dir := strings.TrimSuffix(path.Dir(rel), ".")
+		warn := func(format string, args ...interface{}) {
+			result.warn("%s: "+format, append([]interface{}{rel}, args...)...)
+		}
+
+		switch {
+		case rel == "collection.bru":
+			root.Auth = bruAuth(file, warn)
+			root.Variables = bruVariables(file["vars:pre-request"], warn)
+			if docs, ok := file["docs"]; ok {
+				root.Description = docs.Text
+			}
+		case dir == "environments":
+			// The first environment is the variables of the collection
+			environments++
+			if environments == 1 {
+				bruEnvironment(file, result)
+			}
+		case path.Base(rel) == "folder.bru":
+			f := folderOf(dir)
+			if name := file.get("meta", "name"); name != "" {
+				f.Name = name
+			}
+			f.order = bruSeq(file)
+			f.Auth = bruAuth(file, warn)
+			f.Variables = bruVariables(file["vars:pre-request"], warn)
+			if docs, ok := file["docs"]; ok {
+				f.Description = docs.Text
+			}
+		default:
+			if file.get("meta", "type") != "" && file.get("meta", "type") != "http" {
+				warn("%s requests are not imported", file.get("meta", "type"))
+				result.Stats.SkippedRequests++
+				continue
+			}
+			req, ok := bruRequest(file, strings.TrimSuffix(path.Base(rel), ".bru"), warn)
+			if !ok {
+				result.Stats.SkippedRequests++
+				continue
+			}
+			f := folderOf(dir)
+			f.Requests = append(f.Requests, req)
+		}
+	}
+	if environments > 1 {
+		result.warn("only the first of %d environments is imported", environments)
+	}
+
+	buildCollection(root, result)
+	return result, nil
+}
+
+// bruRequest converts a request file. The name of the file is the ID of
+// its step, when it is one.
+func bruRequest(file bruFile, fileName string, warn func(format string, args ...interface{})) (clientRequest, bool) {
+	convert := func(s string) string { return convertMustache(s, warn) }
+
+	method := ""
+	for _, m := range bruMethods {
+		if _, ok := file[m]; ok {
+			method = m
+			break
+		}
+	}
+	if method == "" {
+		warn("no request found")
+		return clientRequest{}, false
+	}
+
+	req := clientRequest{
+		ID:     fileName,
+		Name:   file.get("meta", "name"),
+		Method: strings.ToUpper(method),
+		URL:    convert(file.get(method, "url")),
+		order:  bruSeq(file),
+	}
+	if req.Name == "" {
+		req.Name = fileName
+	}
+	if docs, ok := file["docs"]; ok {
+		req.Description = docs.Text
+	}
+	if headers, ok := file["headers"]; ok {
+		req.Headers = make(map[string]interface{})
+		for _, pair := range headers.Pairs {
+			req.Headers[pair[0]] = convert(pair[1])
+		}
+	}
+
+	switch mode := file.get(method, "body"); mode {
+	case "", "none":
+	case "json", "text", "xml":
+		if body, ok := file["body:"+mode]; ok {
+			req.Body, req.HasBody = convertBody(body.Text, convert), true
+		}
+	case "form-urlencoded", "multipart-form":
+		if body, ok := file["body:"+mode]; ok {
+			params := make(map[string]interface{})
+			for _, pair := range body.Pairs {
+				params[pair[0]] = convert(pair[1])
+			}
+			req.Body, req.HasBody = params, true
+		}
+	default:
+		warn("%s bodies are not imported", mode)
+	}
+
+	switch mode := file.get(method, "auth"); mode {
+	case "", "none", "inherit":
+	default:
+		applyAuth(&req, bruAuthMode(file, mode, warn), warn)
+	}
+
+	// Variables set after the response are outputs
+	if vars, ok := file["vars:post-response"]; ok {
+		for _, pair := range vars.Pairs {
+			keys, ok := responsePath(pair[1], nil)
+			if !ok {
+				warn("variable %s is set from %s, which is not imported", pair[0], pair[1])
+				continue
+			}
+			req.Sets = append(req.Sets, clientSet{Name: pair[0], Path: keys})
+		}
+	}
+	if script, ok := file["script:post-response"]; ok {
+		req.Sets = append(req.Sets, scriptSets(script.Text, warn)...)
+	}
+	if _, ok := file["script:pre-request"]; ok {
+		warn("pre-request scripts are not imported")
+	}
+
+	if asserts, ok := file["assert"]; ok {
+		for _, pair := range asserts.Pairs {
+			assertion, ok := bruAssertion(pair[0], pair[1])
+			if !ok {
+				warn("assertion %s: %s is not imported", pair[0], pair[1])
+				continue
+			}
+			req.Assertions = append(req.Assertions, assertion)
+		}
+	}
+	if tests, ok := file["tests"]; ok {
+		req.Assertions = append(req.Assertions, scriptAssertions(tests.Text, warn)...)
+	}
+	return req, true
+}
+
+// bruOperators are the expressions of the operators of Bruno assertions
+var bruOperators = map[string]string{
+	"eq": "==", "neq": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<=",
+	"contains": "contains", "startsWith": "startsWith", "endsWith": "endsWith", "matches": "matches",
+}
+
+// bruLiteral matches literals of assertions that are expressions as they are
+var bruLiteral = regexp.MustCompile(`^(-?\d+(\.\d+)?|true|false|null|"(?:[^"\\]|\\.)*")$`)
+
+// bruAssertion converts a Bruno assertion, like res.status: eq 200, to an
+// assertion of a step
+func bruAssertion(key, value string) (string, bool) {
+	keys, ok := responsePath(key, nil)
+	if !ok {
+		return "", false
+	}
+	subject := pathString(keys)
+	if keys[0] == "headers" && len(keys) == 2 {
+		subject = fmt.Sprintf("headers[%s]", strconv.Quote(fmt.Sprint(keys[1])))
+	}
+
+	op, operand, _ := strings.Cut(strings.TrimSpace(value), " ")
+	operand = strings.TrimSpace(operand)
+	switch op {
+	case "isDefined", "isNotEmpty":
+		return subject + " != nil", true
+	case "isUndefined", "isNull":
+		return subject + " == nil", true
+	case "isTrue":
+		return subject + " == true", true
+	case "isFalse":
+		return subject + " == false", true
+	}
+	expr, ok := bruOperators[op]
+	if !ok {
+		return "", false
+	}
+	switch {
+	case operand == "null":
+		operand = "nil"
+	case !bruLiteral.MatchString(operand):
+		operand = strconv.Quote(strings.Trim(operand, `'`))
+	}
+	return subject + " " + expr + " " + operand, true
+}
+
+// bruSeq returns the order of a request or folder
+func bruSeq(file bruFile) float64 {
+	seq, _ := strconv.ParseFloat(file.get("meta", "seq"), 64)
+	return seq
+}
+
+func bruVariables(block *bruBlock, warn func(format string, args ...interface{})) map[string]interface{} {
+	if block == nil || len(block.Pairs) == 0 {
+		return nil
+	}
+	vars := make(map[string]interface{}, len(block.Pairs))
+	for _, pair := range block.Pairs {
+		vars[pair[0]] = convertMustache(pair[1], warn)
+	}
+	return vars
+}
+
+// bruEnvironment reads the variables of an environment, with the names of
+// its secrets
+func bruEnvironment(file bruFile, result *CollectionImportResult) {
+	if vars, ok := file["vars"]; ok {
+		for _, pair := range vars.Pairs {
+			result.Variables = appendVariable(result.Variables, models.EnvironmentVariable{
+				Key:     pair[0],
+				Value:   convertMustache(pair[1], result.warn),
+				Enabled: true,
+			})
+		}
+	}
+	if secrets, ok := file["vars:secret"]; ok {
+		for _, name := range secrets.Items {
+			result.Variables = appendVariable(result.Variables, models.EnvironmentVariable{
+				Key:      name,
+				IsSecret: true,
+				Enabled:  true,
+			})
+		}
+	}
+}
+
+// bruAuth converts the auth of collection.bru or folder.bru
+func bruAuth(file bruFile, warn func(format string, args ...interface{})) *models.CollectionAuth {
+	mode := file.get("auth", "mode")
+	if mode == "" || mode == "none" || mode == "inherit" {
+		return nil
+	}
+	return bruAuthMode(file, mode, warn)
+}
+
+// bruAuthMode converts the auth:<mode> block of a file
+func bruAuthMode(file bruFile, mode string, warn func(format string, args ...interface{})) *models.CollectionAuth {
+	block := "auth:" + mode
+	value := func(name string) string { return convertMustache(file.get(block, name), warn) }
+
+	switch mode {
+	case "bearer":
+		return &models.CollectionAuth{Type: "bearer", Bearer: &models.CollectionBearerAuth{Token: value("token")}}
+	case "basic":
+		return &models.CollectionAuth{Type: "basic", Basic: &models.CollectionBasicAuth{
+			Username: value("username"),
+			Password: value("password"),
+		}}
+	case "apikey":
+		in := "header"
+		if value("placement") == "queryparams" {
+			in = "query"
+		}
+		return &models.CollectionAuth{Type: "api_key", APIKey: &models.CollectionAPIKeyAuth{
+			Key:   value("key"),
+			Value: value("value"),
+			In:    in,
+		}}
+	case "oauth2":
+		return &models.CollectionAuth{Type: "oauth2", OAuth2: &models.CollectionOAuth2Auth{
+			GrantType:    value("grant_type"),
+			ClientID:     value("client_id"),
+			ClientSecret: value("client_secret"),
+			AuthURL:      value("authorization_url"),
+			TokenURL:     value("access_token_url"),
+			RedirectURI:  value("callback_url"),
+			Scope:        value("scope"),
+		}}
+	}
+	warn("%s auth is not imported", mode)
+	return nil
+}
@@ -0,0 +1,619 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Collections of API clients, Postman, Insomnia and Bruno, are trees of
+// folders and requests. A folder that holds only requests is imported as a
+// flow with a step per request, in order; a folder with other folders as a
+// collection. Requests outside such folders become single-step flows.
+
+// CollectionImportResult is the result of importing a collection of an API
+// client
+type CollectionImportResult struct {
+	ImportResult
+	Collection ImportedFolder               `json:"collection"`
+	Variables  []models.EnvironmentVariable `json:"variables,omitempty"`
+
+	flowNames map[string]bool
+}
+
+// ImportedFolder is a folder of an imported collection. Flows are indexes
+// of the flows of the result.
+type ImportedFolder struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Auth        *models.CollectionAuth `json:"auth,omitempty"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+	Flows       []int                  `json:"flows,omitempty"`
+	Folders     []ImportedFolder       `json:"folders,omitempty"`
+}
+
+// clientFolder is a folder of a collection, read by the importer of its
+// client
+type clientFolder struct {
+	Name        string
+	Description string
+	Auth        *models.CollectionAuth
+	Variables   map[string]interface{}
+	Requests    []clientRequest
+	Folders     []*clientFolder
+	order       float64
+}
+
+// clientRequest is a request of a collection. Its URL, headers and body
+// already use ${...} references.
+type clientRequest struct {
+	ID          string // ID of the step, when the collection was exported by TestMesh
+	Name        string
+	Description string
+	Method      string
+	URL         string
+	Headers     map[string]interface{}
+	Body        interface{}
+	HasBody     bool
+	Assertions  []string
+	Sets        []clientSet
+	order       float64
+}
+
+// clientSet is a variable a request sets from its response
+type clientSet struct {
+	Name string
+	Path []interface{} // Keys and indexes below the result of the step
+}
+
+// buildCollection converts the tree of a collection to flows and folders
+func buildCollection(root *clientFolder, result *CollectionImportResult) {
+	result.Collection = result.folder(root)
+}
+
+func (r *CollectionImportResult) folder(f *clientFolder) ImportedFolder {
+	imported := ImportedFolder{
+		Name:        f.Name,
+		Description: f.Description,
+		Auth:        f.Auth,
+		Variables:   f.Variables,
+	}
+	sortFolder(f)
+
+	for _, child := range f.Folders {
+		if len(child.Folders) == 0 && len(child.Requests) > 0 {
+			sortFolder(child)
+			// Flows have no auth, so the auth of the folder goes to its requests
+			if child.Auth != nil {
+				for i := range child.Requests {
+					applyAuth(&child.Requests[i], child.Auth, r.warn)
+				}
+			}
+			imported.Flows = append(imported.Flows, r.addFlow(r.flowName(child.Name, f.Name), child.Description, child.Variables, child.Requests))
+			continue
+		}
+		imported.Folders = append(imported.Folders, r.folder(child))
+	}
+	for _, req := range f.Requests {
+		imported.Flows = append(imported.Flows, r.addFlow(r.flowName(req.Name, f.Name), req.Description, nil, []clientRequest{req}))
+	}
+	return imported
+}
+
+func sortFolder(f *clientFolder) {
+	sort.SliceStable(f.Folders, func(i, j int) bool { return f.Folders[i].order < f.Folders[j].order })
+	sort.SliceStable(f.Requests, func(i, j int) bool { return f.Requests[i].order < f.Requests[j].order })
+}
+
+// flowName returns a name for a flow that no other flow of the import has,
+// since flows are saved by name: the name, or the name after the folder's
+func (r *CollectionImportResult) flowName(name, folder string) string {
+	if r.flowNames == nil {
+		r.flowNames = make(map[string]bool)
+	}
+	unique := name
+	if r.flowNames[unique] {
+		unique = folder + " / " + name
+	}
+	for i := 2; r.flowNames[unique]; i++ {
+		unique = fmt.Sprintf("%s / %s %d", folder, name, i)
+	}
+	r.flowNames[unique] = true
+	return unique
+}
+
+func (r *CollectionImportResult) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// addFlow converts requests to the steps of a flow, and returns the index
+// of the flow. Variables the requests set become outputs of their steps,
+// and references to them references to the outputs.
+func (r *CollectionImportResult) addFlow(name, description string, env map[string]interface{}, requests []clientRequest) int {
+	used := make(map[string]bool)
+	steps := make([]models.Step, len(requests))
+	for i, req := range requests {
+		r.Stats.TotalRequests++
+		config := map[string]interface{}{
+			"method": strings.ToUpper(req.Method),
+			"url":    req.URL,
+		}
+		if config["method"] == "" {
+			config["method"] = "GET"
+		}
+		if len(req.Headers) > 0 {
+			config["headers"] = req.Headers
+		}
+		if req.HasBody {
+			config["body"] = req.Body
+		}
+		steps[i] = models.Step{
+			ID:          uniqueStepID(requestStepID(req.ID, req.Name), used),
+			Action:      "http_request",
+			Name:        req.Name,
+			Description: req.Description,
+			Config:      config,
+			Assert:      req.Assertions,
+		}
+	}
+
+	for i, req := range requests {
+		id := steps[i].ID
+		renames := make(map[string]string)
+		for _, set := range req.Sets {
+			rest := strings.TrimPrefix(set.Name, id+".")
+			if rest == "" {
+				continue
+			}
+			if !reflect.DeepEqual(resultPath(rest), set.Path) {
+				if steps[i].Output == nil {
+					steps[i].Output = make(map[string]string)
+				}
+				steps[i].Output[rest] = "$." + pathString(set.Path)
+			}
+			if set.Name != id+"."+rest {
+				renames[set.Name] = id + "." + rest
+			}
+		}
+		if len(renames) == 0 {
+			continue
+		}
+		for j := i + 1; j < len(steps); j++ {
+			steps[j].Config = renameReferences(steps[j].Config, renames).(map[string]interface{})
+		}
+	}
+
+	r.Flows = append(r.Flows, models.FlowDefinition{
+		Name:        name,
+		Description: description,
+		Env:         env,
+		Steps:       steps,
+	})
+	r.Stats.SuccessfulFlows++
+	return len(r.Flows) - 1
+}
+
+// stepIDPattern matches IDs of steps
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// requestStepID returns the ID of the step of a request: the ID it was
+// exported with, or its name in snake case
+func requestStepID(id, name string) string {
+	if stepIDPattern.MatchString(id) {
+		return id
+	}
+	return snakeCase(name)
+}
+
+// resultFields are the fields of the result of an http_request step
+var resultFields = map[string]bool{
+	"status": true, "body": true, "headers": true, "duration_ms": true, "content_type": true,
+}
+
+// resultPath splits a reference below a step into keys and indexes, when it
+// starts with a field of the result, like body.items[0].id
+func resultPath(ref string) []interface{} {
+	var keys []interface{}
+	for _, part := range strings.Split(ref, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			keys = append(keys, name)
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(index)
+			if err != nil {
+				return nil
+			}
+			keys = append(keys, n)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if first, ok := keys[0].(string); !ok || !resultFields[first] {
+		return nil
+	}
+	return keys
+}
+
+// pathString formats keys and indexes as a path, like body.items[0].id
+func pathString(path []interface{}) string {
+	var sb strings.Builder
+	for _, key := range path {
+		if n, ok := key.(int); ok {
+			fmt.Fprintf(&sb, "[%d]", n)
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(".")
+		}
+		fmt.Fprint(&sb, key)
+	}
+	return sb.String()
+}
+
+// templateReference finds ${...} references
+var templateReference = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// renameReferences rewrites references of a config value
+func renameReferences(v interface{}, renames map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return templateReference.ReplaceAllStringFunc(v, func(m string) string {
+			if name, ok := renames[strings.TrimSpace(m[2:len(m)-1])]; ok {
+				return "${" + name + "}"
+			}
+			return m
+		})
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renamed[key] = renameReferences(item, renames)
+		}
+		return renamed
+	case []interface{}:
+		renamed := make([]interface{}, len(v))
+		for i, item := range v {
+			renamed[i] = renameReferences(item, renames)
+		}
+		return renamed
+	}
+	return v
+}
+
+// mustachePattern finds {{name}} variables of Postman and Bruno
+var mustachePattern = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// dynamicVariables are the dynamic variables of API clients that have a
+// built-in variable in TestMesh
+var dynamicVariables = map[string]string{
+	"$guid": "UUID", "$randomUUID": "UUID", "$timestamp": "TIMESTAMP", "$isoTimestamp": "ISO_TIMESTAMP",
+}
+
+// convertMustache converts {{name}} variables to ${name} references
+func convertMustache(s string, warn func(format string, args ...interface{})) string {
+	return mustachePattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.TrimSpace(m[2 : len(m)-2])
+		name = strings.TrimSpace(strings.TrimPrefix(name, "_."))
+		if builtin, ok := dynamicVariables[name]; ok {
+			return "${" + builtin + "}"
+		}
+		if strings.HasPrefix(name, "$") {
+			warn("dynamic variable %s has no equivalent and is kept as is", name)
+			return m
+		}
+		return "${" + name + "}"
+	})
+}
+
+// convertBody converts the text of a body, parsing JSON bodies
+func convertBody(text string, convert func(string) string) interface{} {
+	text = convert(text)
+	var body interface{}
+	if err := json.Unmarshal([]byte(text), &body); err == nil {
+		return body
+	}
+	return text
+}
+
+// applyAuth adds the auth of a request, or of the folder of a flow, to its
+// headers or URL
+func applyAuth(req *clientRequest, auth *models.CollectionAuth, warn func(format string, args ...interface{})) {
+	if auth == nil {
+		return
+	}
+	if req.Headers == nil {
+		req.Headers = make(map[string]interface{})
+	}
+	setHeader := func(name, value string) {
+		for existing := range req.Headers {
+			if strings.EqualFold(existing, name) {
+				return
+			}
+		}
+		req.Headers[name] = value
+	}
+
+	switch {
+	case auth.Type == "bearer" && auth.Bearer != nil:
+		prefix := auth.Bearer.Prefix
+		if prefix == "" {
+			prefix = "Bearer"
+		}
+		setHeader("Authorization", prefix+" "+auth.Bearer.Token)
+	case auth.Type == "basic" && auth.Basic != nil:
+		credentials := auth.Basic.Username + ":" + auth.Basic.Password
+		if strings.Contains(credentials, "${") {
+			warn("request %s: basic auth with variables is not imported, set the Authorization header", req.Name)
+			return
+		}
+		setHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	case auth.Type == "api_key" && auth.APIKey != nil:
+		if auth.APIKey.In == "query" {
+			separator := "?"
+			if strings.Contains(req.URL, "?") {
+				separator = "&"
+			}
+			req.URL += separator + auth.APIKey.Key + "=" + auth.APIKey.Value
+		} else {
+			setHeader(auth.APIKey.Key, auth.APIKey.Value)
+		}
+	case auth.Type == "oauth2" && auth.OAuth2 != nil && auth.OAuth2.AccessToken != "":
+		setHeader("Authorization", "Bearer "+auth.OAuth2.AccessToken)
+	case auth.Type == "oauth2":
+		warn("request %s: OAuth 2.0 is not imported, set the Authorization header", req.Name)
+	}
+}
+
+// testCallPattern finds tests of scripts, like pm.test("status == 200", ...)
+var testCallPattern = regexp.MustCompile(`(?:pm\.test|\btest|\bit)\(\s*("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`)
+
+// scriptAssertions reads the assertions of the tests of a script. A test
+// named by an assertion, like the tests TestMesh exports, is that
+// assertion; other tests are read from common checks of their code.
+func scriptAssertions(script string, warn func(format string, args ...interface{})) []string {
+	var assertions []string
+	matches := testCallPattern.FindAllStringSubmatchIndex(script, -1)
+	for i, m := range matches {
+		name := unquoteJS(script[m[2]:m[3]])
+		if isAssertion(name) {
+			assertions = append(assertions, name)
+			continue
+		}
+		end := len(script)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		found := codeAssertions(script[m[1]:end])
+		if len(found) == 0 {
+			warn("test %q is not imported", name)
+		}
+		assertions = append(assertions, found...)
+	}
+	return assertions
+}
+
+// unquoteJS unquotes a JavaScript string literal
+func unquoteJS(s string) string {
+	if strings.HasPrefix(s, "'") {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s[1 : len(s)-1]
+}
+
+// assertionOperators are the operators of the root of an assertion
+var assertionOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"and": true, "or": true, "&&": true, "||": true, "in": true, "not in": true,
+	"contains": true, "startsWith": true, "endsWith": true, "matches": true,
+}
+
+// isAssertion reports whether the name of a test is an assertion over the
+// result of a step, like status == 200
+func isAssertion(name string) bool {
+	tree, err := parser.Parse(name)
+	if err != nil {
+		return false
+	}
+	switch n := tree.Node.(type) {
+	case *ast.BinaryNode:
+		if !assertionOperators[n.Operator] {
+			return false
+		}
+	case *ast.UnaryNode:
+		if n.Operator != "not" && n.Operator != "!" {
+			return false
+		}
+	case *ast.BuiltinNode:
+	default:
+		return false
+	}
+	fields := &identifierVisitor{}
+	ast.Walk(&tree.Node, fields)
+	return fields.ok && !fields.other
+}
+
+// identifierVisitor checks that the identifiers of an expression are fields
+// of the result
+type identifierVisitor struct {
+	ok    bool
+	other bool
+}
+
+func (v *identifierVisitor) Visit(node *ast.Node) {
+	if id, ok := (*node).(*ast.IdentifierNode); ok {
+		if resultFields[id.Value] {
+			v.ok = true
+		} else {
+			v.other = true
+		}
+	}
+}
+
+// Accessors of the response in scripts of the clients
+const (
+	statusAccessor   = `(?:pm\.response\.code|res\.status|res\.getStatus\(\)|response\.status|result\.status)`
+	durationAccessor = `(?:pm\.response\.responseTime|res\.responseTime|res\.getResponseTime\(\)|response\.responseTime|result\.duration_ms)`
+	bodyAccessor     = `(?:pm\.response\.json\(\)|res\.body|res\.getBody\(\)|jsonData|result\.body)`
+	literalPattern   = `(-?\d+(?:\.\d+)?|true|false|null|"(?:[^"\\]|\\.)*")`
+)
+
+// codeChecks are common checks of tests and their assertions
+var codeChecks = []struct {
+	pattern *regexp.Regexp
+	format  func(m []string) string
+}{
+	{regexp.MustCompile(`pm\.response\.to\.have\.status\((\d+)\)`), func(m []string) string {
+		return "status == " + m[1]
+	}},
+	{regexp.MustCompile(`expect\(` + statusAccessor + `\)\.to\.(?:eql|equal|eq|be\.equal)\((\d+)\)`), func(m []string) string {
+		return "status == " + m[1]
+	}},
+	{regexp.MustCompile(`expect\(` + durationAccessor + `\)\.to\.be\.(?:below|lessThan|lt)\((\d+)\)`), func(m []string) string {
+		return "duration_ms < " + m[1]
+	}},
+	{regexp.MustCompile(`pm\.response\.to\.have\.header\(["']([^"']+)["']\)`), func(m []string) string {
+		return strconv.Quote(m[1]) + " in headers"
+	}},
+	{regexp.MustCompile(`expect\(` + bodyAccessor + `((?:\.[A-Za-z_]\w*|\[\d+\])+)\)\.to\.(?:eql|equal|eq)\(` + literalPattern + `\)`), func(m []string) string {
+		value := m[2]
+		if value == "null" {
+			value = "nil"
+		}
+		return "body" + m[1] + " == " + value
+	}},
+}
+
+// codeAssertions reads assertions from the code of a test
+func codeAssertions(code string) []string {
+	var assertions []string
+	for _, check := range codeChecks {
+		for _, m := range check.pattern.FindAllStringSubmatch(code, -1) {
+			assertions = append(assertions, check.format(m))
+		}
+	}
+	return assertions
+}
+
+// setVariablePattern finds variables set by scripts, like
+// pm.collectionVariables.set("token", pm.response.json().token)
+var setVariablePattern = regexp.MustCompile(`(?m)(?:(?:pm|insomnia)\.(?:collectionVariables|environment|baseEnvironment|globals|variables)\.set|bru\.setVar|bru\.setEnvVar)\(\s*["']([^"']+)["']\s*,\s*(.+?)\s*\);?\s*$`)
+
+// bodyVariablePattern finds variables holding the parsed body, like
+// const jsonData = pm.response.json()
+var bodyVariablePattern = regexp.MustCompile(`(?:var|let|const)\s+(\w+)\s*=\s*(?:(?:pm|insomnia)\.response\.json\(\)|res\.getBody\(\)|res\.body)\s*;?`)
+
+// scriptSets reads the variables a script sets from the response
+func scriptSets(script string, warn func(format string, args ...interface{})) []clientSet {
+	bodyVars := make(map[string]bool)
+	for _, m := range bodyVariablePattern.FindAllStringSubmatch(script, -1) {
+		bodyVars[m[1]] = true
+	}
+
+	var sets []clientSet
+	for _, m := range setVariablePattern.FindAllStringSubmatch(script, -1) {
+		path, ok := responsePath(m[2], bodyVars)
+		if !ok {
+			warn("variable %s is set from %s, which is not imported", m[1], m[2])
+			continue
+		}
+		sets = append(sets, clientSet{Name: m[1], Path: path})
+	}
+	return sets
+}
+
+var (
+	digCallPattern   = regexp.MustCompile(`^dig\(\s*result\s*,\s*(\[.*\])\s*\)$`)
+	accessorPattern  = regexp.MustCompile(`^(\.[A-Za-z_$][\w$]*|\[\d+\]|\["[^"]*"\]|\['[^']*'\])`)
+	headerGetPattern = regexp.MustCompile(`^(?:(?:pm|insomnia)\.response\.headers\.get|res\.getHeader)\(\s*["']([^"']+)["']\s*\)$`)
+)
+
+// responsePath converts an expression of a script over the response to a
+// path below the result of a step, like pm.response.json().token to
+// body.token
+func responsePath(expr string, bodyVars map[string]bool) ([]interface{}, bool) {
+	expr = strings.TrimSpace(expr)
+	if m := digCallPattern.FindStringSubmatch(expr); m != nil {
+		var path []interface{}
+		if err := json.Unmarshal([]byte(m[1]), &path); err != nil {
+			return nil, false
+		}
+		for i, key := range path {
+			if n, ok := key.(float64); ok {
+				path[i] = int(n)
+			}
+		}
+		return path, true
+	}
+	if m := headerGetPattern.FindStringSubmatch(expr); m != nil {
+		return []interface{}{"headers", m[1]}, true
+	}
+
+	roots := []struct {
+		prefix string
+		path   []interface{}
+	}{
+		{"pm.response.json()", []interface{}{"body"}},
+		{"insomnia.response.json()", []interface{}{"body"}},
+		{"res.getBody()", []interface{}{"body"}},
+		{"res.body", []interface{}{"body"}},
+		{"pm.response.code", []interface{}{"status"}},
+		{"insomnia.response.code", []interface{}{"status"}},
+		{"res.getStatus()", []interface{}{"status"}},
+		{"res.status", []interface{}{"status"}},
+		{"pm.response.responseTime", []interface{}{"duration_ms"}},
+		{"insomnia.response.responseTime", []interface{}{"duration_ms"}},
+		{"res.getResponseTime()", []interface{}{"duration_ms"}},
+		{"res.responseTime", []interface{}{"duration_ms"}},
+		{"res.headers", []interface{}{"headers"}},
+	}
+	for name := range bodyVars {
+		roots = append(roots, struct {
+			prefix string
+			path   []interface{}
+		}{name, []interface{}{"body"}})
+	}
+
+	for _, root := range roots {
+		if !strings.HasPrefix(expr, root.prefix) {
+			continue
+		}
+		rest := expr[len(root.prefix):]
+		if rest != "" && rest[0] != '.' && rest[0] != '[' {
+			continue
+		}
+		path := append([]interface{}{}, root.path...)
+		for rest != "" {
+			m := accessorPattern.FindString(rest)
+			if m == "" {
+				return nil, false
+			}
+			rest = rest[len(m):]
+			switch {
+			case m[0] == '.':
+				path = append(path, m[1:])
+			case m[1] == '"' || m[1] == '\'':
+				path = append(path, m[2:len(m)-2])
+			default:
+				n, _ := strconv.Atoi(m[1 : len(m)-1])
+				path = append(path, n)
+			}
+		}
+		return path, true
+	}
+	return nil, false
+}
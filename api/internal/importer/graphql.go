@@ -0,0 +1,461 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// GraphQLGroupBy controls how root fields are grouped into flows
+type GraphQLGroupBy string
+
+const (
+	GraphQLGroupByOperation GraphQLGroupBy = "operation" // One flow per root field
+	GraphQLGroupByType      GraphQLGroupBy = "type"      // One flow per root type (queries, mutations, subscriptions)
+)
+
+// GraphQLOptions configures the GraphQL importer
+type GraphQLOptions struct {
+	GroupBy  GraphQLGroupBy `json:"group_by"`
+	Title    string         `json:"title,omitempty"`     // Defaults to "GraphQL API"
+	Endpoint string         `json:"endpoint,omitempty"`  // Default value of GRAPHQL_URL
+	MaxDepth int            `json:"max_depth,omitempty"` // Selection set depth (default 2)
+}
+
+// GraphQLImportResult extends ImportResult with the environment derived from the schema
+type GraphQLImportResult struct {
+	ImportResult
+	Title     string                       `json:"title"`
+	Variables []models.EnvironmentVariable `json:"variables"`
+}
+
+// graphQLTypeRef is a (possibly wrapped) type reference from introspection
+type graphQLTypeRef struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	OfType *graphQLTypeRef `json:"ofType"`
+}
+
+type graphQLInputValue struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	Type         graphQLTypeRef `json:"type"`
+	DefaultValue *string        `json:"defaultValue"`
+}
+
+type graphQLField struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Args         []graphQLInputValue `json:"args"`
+	Type         graphQLTypeRef      `json:"type"`
+	IsDeprecated bool                `json:"isDeprecated"`
+}
+
+type graphQLType struct {
+	Kind          string              `json:"kind"`
+	Name          string              `json:"name"`
+	Fields        []graphQLField      `json:"fields"`
+	InputFields   []graphQLInputValue `json:"inputFields"`
+	EnumValues    []graphQLEnumValue  `json:"enumValues"`
+	PossibleTypes []graphQLTypeRef    `json:"possibleTypes"`
+}
+
+type graphQLEnumValue struct {
+	Name string `json:"name"`
+}
+
+type graphQLSchema struct {
+	QueryType        *struct{ Name string } `json:"queryType"`
+	MutationType     *struct{ Name string } `json:"mutationType"`
+	SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+	Types            []graphQLType          `json:"types"`
+}
+
+// graphQLSchemaDoc indexes an introspected schema for traversal
+type graphQLSchemaDoc struct {
+	types    map[string]*graphQLType
+	maxDepth int
+}
+
+// graphQLRoot pairs an operation keyword with its root type
+type graphQLRoot struct {
+	Operation string
+	Label     string
+	TypeName  string
+}
+
+// ParseGraphQLIntrospection converts an introspection result (the response of
+// the standard introspection query, or its __schema object) to runnable flows
+// with one graphql step per root field
+func ParseGraphQLIntrospection(content string, opts GraphQLOptions) (*GraphQLImportResult, error) {
+	var envelope struct {
+		Data *struct {
+			Schema *graphQLSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *graphQLSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal([]byte(content), &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection result: %w", err)
+	}
+
+	schema := envelope.Schema
+	if schema == nil && envelope.Data != nil {
+		schema = envelope.Data.Schema
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("introspection result has no __schema")
+	}
+
+	doc := &graphQLSchemaDoc{types: make(map[string]*graphQLType), maxDepth: opts.MaxDepth}
+	if doc.maxDepth <= 0 {
+		doc.maxDepth = 2
+	}
+	for i := range schema.Types {
+		doc.types[schema.Types[i].Name] = &schema.Types[i]
+	}
+
+	result := &GraphQLImportResult{Title: opts.Title}
+	if result.Title == "" {
+		result.Title = "GraphQL API"
+	}
+
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = "http://localhost:4000/graphql"
+	}
+	result.Variables = []models.EnvironmentVariable{{
+		Key:         "GRAPHQL_URL",
+		Value:       endpoint,
+		Description: "GraphQL endpoint",
+		Enabled:     true,
+	}}
+
+	var roots []graphQLRoot
+	if schema.QueryType != nil {
+		roots = append(roots, graphQLRoot{"query", "Queries", schema.QueryType.Name})
+	}
+	if schema.MutationType != nil {
+		roots = append(roots, graphQLRoot{"mutation", "Mutations", schema.MutationType.Name})
+	}
+	if schema.SubscriptionType != nil {
+		roots = append(roots, graphQLRoot{"subscription", "Subscriptions", schema.SubscriptionType.Name})
+	}
+
+	paramVars := make(map[string]models.EnvironmentVariable)
+	for _, root := range roots {
+		rootType := doc.types[root.TypeName]
+		if rootType == nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("root type %q is not defined", root.TypeName))
+			continue
+		}
+
+		var steps []models.Step
+		for _, field := range rootType.Fields {
+			result.Stats.TotalRequests++
+			if field.IsDeprecated {
+				result.Stats.SkippedRequests++
+				continue
+			}
+
+			step, vars, warnings := doc.fieldToStep(root.Operation, field)
+			for _, v := range vars {
+				if _, exists := paramVars[v.Key]; !exists {
+					paramVars[v.Key] = v
+				}
+			}
+			for _, w := range warnings {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: %s", root.Operation, field.Name, w))
+			}
+
+			if opts.GroupBy == GraphQLGroupByType {
+				steps = append(steps, step)
+				continue
+			}
+			result.Flows = append(result.Flows, models.FlowDefinition{
+				Name:        fmt.Sprintf("%s / %s %s", result.Title, root.Operation, field.Name),
+				Description: graphQLFieldDescription(root.Operation, field),
+				Suite:       result.Title,
+				Tags:        []string{"graphql", root.Operation},
+				Steps:       []models.Step{step},
+			})
+		}
+
+		if opts.GroupBy == GraphQLGroupByType && len(steps) > 0 {
+			result.Flows = append(result.Flows, models.FlowDefinition{
+				Name:        fmt.Sprintf("%s / %s", result.Title, root.Label),
+				Description: fmt.Sprintf("Imported from GraphQL introspection - %s fields", root.TypeName),
+				Suite:       result.Title,
+				Tags:        []string{"graphql", root.Operation},
+				Steps:       steps,
+			})
+		}
+	}
+
+	keys := make([]string, 0, len(paramVars))
+	for k := range paramVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result.Variables = appendVariable(result.Variables, paramVars[k])
+	}
+
+	result.Stats.SuccessfulFlows = len(result.Flows)
+	return result, nil
+}
+
+// fieldToStep builds a graphql step calling a single root field
+func (d *graphQLSchemaDoc) fieldToStep(operation string, field graphQLField) (models.Step, []models.EnvironmentVariable, []string) {
+	var vars []models.EnvironmentVariable
+	var warnings []string
+
+	opName := graphQLOperationName(operation, field.Name)
+
+	// Required arguments become operation variables; ID arguments are read from the environment
+	var defs, args []string
+	variables := make(map[string]interface{})
+	for _, arg := range field.Args {
+		if arg.Type.Kind != "NON_NULL" || arg.DefaultValue != nil {
+			continue
+		}
+		defs = append(defs, fmt.Sprintf("$%s: %s", arg.Name, arg.Type.String()))
+		args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+
+		if named := arg.Type.named(); named.Name == "ID" && arg.Type.isScalarValue() {
+			varName := envVarName(field.Name + "_" + arg.Name)
+			vars = append(vars, models.EnvironmentVariable{
+				Key:         varName,
+				Value:       "1",
+				Description: fmt.Sprintf("Argument %q of %s %s", arg.Name, operation, field.Name),
+				Enabled:     true,
+			})
+			variables[arg.Name] = "${" + varName + "}"
+			continue
+		}
+		variables[arg.Name] = d.exampleFor(arg.Type, 0, nil)
+	}
+
+	var query strings.Builder
+	query.WriteString(operation + " " + opName)
+	if len(defs) > 0 {
+		query.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	query.WriteString(" {\n  " + field.Name)
+	if len(args) > 0 {
+		query.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	if selection := d.selectionSet(field.Type, 1, "  "); selection != "" {
+		query.WriteString(" " + selection)
+	} else if !d.isLeaf(field.Type) {
+		query.WriteString(" { __typename }")
+		warnings = append(warnings, "no selectable fields, selecting __typename")
+	}
+	query.WriteString("\n}\n")
+
+	config := map[string]interface{}{
+		"url":            "${GRAPHQL_URL}",
+		"query":          query.String(),
+		"operation_name": opName,
+	}
+	if len(variables) > 0 {
+		config["variables"] = variables
+	}
+
+	assert := []string{"status == 200", fmt.Sprintf("%q in data", field.Name)}
+	if operation == "subscription" {
+		config["count"] = 1
+		config["timeout"] = "30s"
+		assert = []string{"count >= 1"}
+	}
+
+	return models.Step{
+		ID:          snakeCase(operation + "_" + field.Name),
+		Name:        fmt.Sprintf("%s %s", operation, field.Name),
+		Description: field.Description,
+		Action:      "graphql",
+		Config:      config,
+		Assert:      assert,
+	}, vars, warnings
+}
+
+// selectionSet renders the selection for a field type, or "" for leaf types.
+// Fields that need arguments are skipped and nesting stops at maxDepth.
+func (d *graphQLSchemaDoc) selectionSet(ref graphQLTypeRef, depth int, indent string) string {
+	t := d.types[ref.named().Name]
+	if t == nil {
+		return ""
+	}
+
+	inner := indent + "  "
+	var lines []string
+
+	switch t.Kind {
+	case "OBJECT", "INTERFACE":
+		for _, f := range t.Fields {
+			if f.IsDeprecated || hasRequiredArgs(f) {
+				continue
+			}
+			if d.isLeaf(f.Type) {
+				lines = append(lines, inner+f.Name)
+				continue
+			}
+			if depth >= d.maxDepth {
+				continue
+			}
+			if sub := d.selectionSet(f.Type, depth+1, inner); sub != "" {
+				lines = append(lines, inner+f.Name+" "+sub)
+			}
+		}
+		if t.Kind == "INTERFACE" && len(lines) == 0 {
+			lines = append(lines, inner+"__typename")
+		}
+	case "UNION":
+		lines = append(lines, inner+"__typename")
+		if depth < d.maxDepth {
+			for _, p := range t.PossibleTypes {
+				if sub := d.selectionSet(p, depth+1, inner); sub != "" {
+					lines = append(lines, inner+"... on "+p.Name+" "+sub)
+				}
+			}
+		}
+	default:
+		return ""
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return "{\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+}
+
+// exampleFor synthesizes a variable value for an input type
+func (d *graphQLSchemaDoc) exampleFor(ref graphQLTypeRef, depth int, seen []string) interface{} {
+	switch ref.Kind {
+	case "NON_NULL":
+		if ref.OfType != nil {
+			return d.exampleFor(*ref.OfType, depth, seen)
+		}
+		return nil
+	case "LIST":
+		if ref.OfType != nil {
+			return []interface{}{d.exampleFor(*ref.OfType, depth, seen)}
+		}
+		return []interface{}{}
+	}
+
+	switch ref.Name {
+	case "String":
+		return "string"
+	case "ID":
+		return "1"
+	case "Int":
+		return 0
+	case "Float":
+		return 0.0
+	case "Boolean":
+		return true
+	}
+
+	t := d.types[ref.Name]
+	if t == nil {
+		return nil
+	}
+
+	switch t.Kind {
+	case "ENUM":
+		if len(t.EnumValues) > 0 {
+			return t.EnumValues[0].Name
+		}
+		return nil
+	case "INPUT_OBJECT":
+		for _, s := range seen {
+			if s == t.Name {
+				return map[string]interface{}{}
+			}
+		}
+		obj := make(map[string]interface{})
+		if depth >= maxExampleDepth {
+			return obj
+		}
+		for _, f := range t.InputFields {
+			if f.Type.Kind == "NON_NULL" && f.DefaultValue == nil {
+				obj[f.Name] = d.exampleFor(f.Type, depth+1, append(seen, t.Name))
+			}
+		}
+		return obj
+	default:
+		// Custom scalars
+		return "string"
+	}
+}
+
+// isLeaf reports whether a type is a scalar or enum (selected without a sub-selection)
+func (d *graphQLSchemaDoc) isLeaf(ref graphQLTypeRef) bool {
+	named := ref.named()
+	if named.Kind == "SCALAR" || named.Kind == "ENUM" {
+		return true
+	}
+	if t := d.types[named.Name]; t != nil {
+		return t.Kind == "SCALAR" || t.Kind == "ENUM"
+	}
+	return false
+}
+
+// String renders a type reference in SDL notation, e.g. [String!]!
+func (r graphQLTypeRef) String() string {
+	switch r.Kind {
+	case "NON_NULL":
+		if r.OfType != nil {
+			return r.OfType.String() + "!"
+		}
+	case "LIST":
+		if r.OfType != nil {
+			return "[" + r.OfType.String() + "]"
+		}
+	}
+	return r.Name
+}
+
+// named unwraps NON_NULL and LIST wrappers
+func (r graphQLTypeRef) named() graphQLTypeRef {
+	for (r.Kind == "NON_NULL" || r.Kind == "LIST") && r.OfType != nil {
+		r = *r.OfType
+	}
+	return r
+}
+
+// isScalarValue reports whether the type is a single (non-list) value
+func (r graphQLTypeRef) isScalarValue() bool {
+	for r.Kind == "NON_NULL" && r.OfType != nil {
+		r = *r.OfType
+	}
+	return r.Kind != "LIST"
+}
+
+func hasRequiredArgs(f graphQLField) bool {
+	for _, a := range f.Args {
+		if a.Type.Kind == "NON_NULL" && a.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLOperationName builds an operation name (query + user -> QueryUser)
+func graphQLOperationName(operation, field string) string {
+	name := strings.ToUpper(operation[:1]) + operation[1:]
+	if field != "" {
+		name += strings.ToUpper(field[:1]) + field[1:]
+	}
+	return name
+}
+
+func graphQLFieldDescription(operation string, field graphQLField) string {
+	if field.Description != "" {
+		return field.Description
+	}
+	return fmt.Sprintf("Imported from GraphQL introspection - %s %s", operation, field.Name)
+}
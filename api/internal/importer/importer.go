@@ -249,199 +249,3 @@ func curlToFlow(command string) (*models.FlowDefinition, error) {
 		Steps:       []models.Step{step},
 	}, nil
 }
-
-// PostmanCollection represents a Postman collection
-type PostmanCollection struct {
-	Info struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Schema      string `json:"schema"`
-	} `json:"info"`
-	Item     []PostmanItem     `json:"item"`
-	Variable []PostmanVariable `json:"variable,omitempty"`
-}
-
-// PostmanItem represents an item in a Postman collection
-type PostmanItem struct {
-	Name        string           `json:"name"`
-	Description string           `json:"description,omitempty"`
-	Request     *PostmanRequest  `json:"request,omitempty"`
-	Response    []PostmanResponse `json:"response,omitempty"`
-	Item        []PostmanItem    `json:"item,omitempty"` // For folders
-}
-
-// PostmanRequest represents a request in Postman format
-type PostmanRequest struct {
-	Method string          `json:"method"`
-	Header []PostmanHeader `json:"header,omitempty"`
-	Body   *PostmanBody    `json:"body,omitempty"`
-	URL    interface{}     `json:"url"` // Can be string or object
-}
-
-// PostmanHeader represents a header in Postman format
-type PostmanHeader struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-	Type  string `json:"type,omitempty"`
-}
-
-// PostmanBody represents a request body in Postman format
-type PostmanBody struct {
-	Mode       string            `json:"mode"`
-	Raw        string            `json:"raw,omitempty"`
-	URLEncoded []PostmanURLEncoded `json:"urlencoded,omitempty"`
-	FormData   []PostmanFormData `json:"formdata,omitempty"`
-}
-
-// PostmanURLEncoded represents URL-encoded form data
-type PostmanURLEncoded struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-}
-
-// PostmanFormData represents multipart form data
-type PostmanFormData struct {
-	Key  string `json:"key"`
-	Value string `json:"value,omitempty"`
-	Type string `json:"type,omitempty"`
-	Src  string `json:"src,omitempty"`
-}
-
-// PostmanResponse represents a saved response
-type PostmanResponse struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Code   int    `json:"code"`
-	Body   string `json:"body,omitempty"`
-}
-
-// PostmanVariable represents a collection variable
-type PostmanVariable struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-	Type  string `json:"type,omitempty"`
-}
-
-// ParsePostman parses a Postman collection and converts it to flow definitions
-func ParsePostman(content string) (*ImportResult, error) {
-	var collection PostmanCollection
-	if err := json.Unmarshal([]byte(content), &collection); err != nil {
-		return nil, fmt.Errorf("failed to parse Postman collection: %w", err)
-	}
-
-	result := &ImportResult{}
-
-	// Process items recursively
-	processPostmanItems(collection.Item, "", result)
-
-	return result, nil
-}
-
-func processPostmanItems(items []PostmanItem, prefix string, result *ImportResult) {
-	for _, item := range items {
-		// If it's a folder, recurse
-		if len(item.Item) > 0 {
-			folderPrefix := prefix
-			if item.Name != "" {
-				if folderPrefix != "" {
-					folderPrefix += " / "
-				}
-				folderPrefix += item.Name
-			}
-			processPostmanItems(item.Item, folderPrefix, result)
-			continue
-		}
-
-		// It's a request
-		if item.Request == nil {
-			continue
-		}
-
-		result.Stats.TotalRequests++
-
-		flow, err := postmanItemToFlow(item, prefix)
-		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", item.Name, err.Error()))
-			continue
-		}
-
-		result.Flows = append(result.Flows, *flow)
-		result.Stats.SuccessfulFlows++
-	}
-}
-
-func postmanItemToFlow(item PostmanItem, prefix string) (*models.FlowDefinition, error) {
-	req := item.Request
-
-	// Parse URL
-	var requestURL string
-	switch u := req.URL.(type) {
-	case string:
-		requestURL = u
-	case map[string]interface{}:
-		if raw, ok := u["raw"].(string); ok {
-			requestURL = raw
-		}
-	}
-
-	if requestURL == "" {
-		return nil, fmt.Errorf("no URL found")
-	}
-
-	// Build headers
-	headers := make(map[string]interface{})
-	for _, h := range req.Header {
-		headers[h.Key] = h.Value
-	}
-
-	// Build config
-	config := map[string]interface{}{
-		"method": req.Method,
-		"url":    requestURL,
-	}
-
-	if len(headers) > 0 {
-		config["headers"] = headers
-	}
-
-	// Handle body
-	if req.Body != nil {
-		switch req.Body.Mode {
-		case "raw":
-			config["body"] = req.Body.Raw
-		case "urlencoded":
-			params := make(map[string]string)
-			for _, p := range req.Body.URLEncoded {
-				params[p.Key] = p.Value
-			}
-			config["body"] = params
-		case "formdata":
-			params := make(map[string]string)
-			for _, p := range req.Body.FormData {
-				if p.Type != "file" {
-					params[p.Key] = p.Value
-				}
-			}
-			config["body"] = params
-		}
-	}
-
-	// Build step
-	step := models.Step{
-		ID:     "step_1",
-		Name:   item.Name,
-		Action: "http",
-		Config: config,
-	}
-
-	name := item.Name
-	if prefix != "" {
-		name = prefix + " / " + name
-	}
-
-	return &models.FlowDefinition{
-		Name:        name,
-		Description: item.Description,
-		Steps:       []models.Step{step},
-	}, nil
-}
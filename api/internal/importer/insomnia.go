@@ -0,0 +1,395 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// insomniaExport represents an Insomnia export, version 4
+type insomniaExport struct {
+	Type      string             `json:"_type"`
+	Format    int                `json:"__export_format"`
+	Resources []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a resource of an Insomnia export: a workspace,
+// environment, request group, request, unit test suite or unit test
+type insomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       string                 `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	MetaSortKey    float64                `json:"metaSortKey"`
+	Method         string                 `json:"method"`
+	URL            string                 `json:"url"`
+	Headers        []insomniaPair         `json:"headers"`
+	Parameters     []insomniaPair         `json:"parameters"`
+	Body           insomniaBody           `json:"body"`
+	Authentication map[string]interface{} `json:"authentication"`
+	AfterResponse  string                 `json:"afterResponseScript"`
+	Environment    map[string]interface{} `json:"environment"`
+	Data           map[string]interface{} `json:"data"`
+	RequestID      string                 `json:"requestId"`
+	Code           string                 `json:"code"`
+}
+
+type insomniaPair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type insomniaBody struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []insomniaPair `json:"params"`
+}
+
+var (
+	// insomniaTagPattern finds template tags, like {% uuid 'v4' %}
+	insomniaTagPattern = regexp.MustCompile(`\{%\s*(\w+)\s*(.*?)\s*%\}`)
+	// insomniaArgPattern finds the quoted arguments of template tags
+	insomniaArgPattern = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)"`)
+	// insomniaRequestIDPattern matches request IDs of exports of TestMesh,
+	// which keep the IDs of steps, like req_login_3
+	insomniaRequestIDPattern = regexp.MustCompile(`^req_(\w+?)_\d+$`)
+)
+
+// ParseInsomnia parses an Insomnia export. Request groups of requests
+// become flows, response tags references to the results of their steps, and
+// unit tests assertions.
+func ParseInsomnia(content string) (*CollectionImportResult, error) {
+	var export insomniaExport
+	if err := json.Unmarshal([]byte(content), &export); err != nil {
+		return nil, fmt.Errorf("failed to parse Insomnia export: %w", err)
+	}
+	if export.Type != "export" || export.Format != 4 {
+		return nil, fmt.Errorf("unsupported Insomnia export: only export format 4 is supported")
+	}
+
+	result := &CollectionImportResult{}
+	children := make(map[string][]insomniaResource)
+	var workspace *insomniaResource
+	for i, res := range export.Resources {
+		children[res.ParentID] = append(children[res.ParentID], res)
+		if res.Type == "workspace" && workspace == nil {
+			workspace = &export.Resources[i]
+		}
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("Insomnia export has no workspace")
+	}
+
+	// The base environment and its first sub environment are the variables
+	for _, env := range children[workspace.ID] {
+		if env.Type != "environment" {
+			continue
+		}
+		insomniaVariables(env.Data, result)
+		for _, sub := range children[env.ID] {
+			if sub.Type == "environment" {
+				insomniaVariables(sub.Data, result)
+				break
+			}
+		}
+		break
+	}
+
+	// Step IDs of requests, for references of response tags
+	stepIDs := make(map[string]string)
+	for _, res := range export.Resources {
+		if res.Type == "request" {
+			id := ""
+			if m := insomniaRequestIDPattern.FindStringSubmatch(res.ID); m != nil {
+				id = m[1]
+			}
+			stepIDs[res.ID] = requestStepID(id, res.Name)
+		}
+	}
+
+	// Variables the after-response scripts of requests set, for the outputs
+	// of their steps and the response tags reading them
+	sets := make(map[string][]clientSet)
+	for _, res := range export.Resources {
+		if res.Type == "request" && res.AfterResponse != "" {
+			name := res.Name
+			sets[res.ID] = scriptSets(res.AfterResponse, func(format string, args ...interface{}) {
+				result.warn("%s: "+format, append([]interface{}{name}, args...)...)
+			})
+		}
+	}
+
+	// Unit tests of requests
+	tests := make(map[string][]insomniaResource)
+	for _, res := range export.Resources {
+		if res.Type == "unit_test" {
+			tests[res.RequestID] = append(tests[res.RequestID], res)
+		}
+	}
+
+	p := &insomniaParser{result: result, children: children, stepIDs: stepIDs, sets: sets, tests: tests}
+	root := &clientFolder{Name: workspace.Name, Description: workspace.Description}
+	if root.Name == "" {
+		root.Name = "Insomnia Collection"
+	}
+	p.folder(workspace.ID, root)
+	buildCollection(root, result)
+	return result, nil
+}
+
+type insomniaParser struct {
+	result   *CollectionImportResult
+	children map[string][]insomniaResource
+	stepIDs  map[string]string
+	sets     map[string][]clientSet
+	tests    map[string][]insomniaResource
+}
+
+// folder reads the request groups and requests below a parent
+func (p *insomniaParser) folder(parentID string, f *clientFolder) {
+	for _, res := range p.children[parentID] {
+		switch res.Type {
+		case "request_group":
+			child := &clientFolder{
+				Name:        res.Name,
+				Description: res.Description,
+				Auth:        p.auth(res.Authentication, p.result.warn),
+				Variables:   p.variables(res.Environment),
+				order:       res.MetaSortKey,
+			}
+			p.folder(res.ID, child)
+			f.Folders = append(f.Folders, child)
+		case "request":
+			f.Requests = append(f.Requests, p.request(res))
+		case "grpc_request", "websocket_request":
+			p.result.warn("%s: %s resources are not imported", res.Name, res.Type)
+			p.result.Stats.SkippedRequests++
+		}
+	}
+}
+
+// request converts a request
+func (p *insomniaParser) request(res insomniaResource) clientRequest {
+	warn := func(format string, args ...interface{}) {
+		p.result.warn("%s: "+format, append([]interface{}{res.Name}, args...)...)
+	}
+	convert := func(s string) string { return p.template(s, warn) }
+
+	req := clientRequest{
+		ID:          p.stepIDs[res.ID],
+		Name:        res.Name,
+		Description: res.Description,
+		Method:      res.Method,
+		URL:         convert(res.URL),
+		Sets:        p.sets[res.ID],
+		order:       res.MetaSortKey,
+	}
+	var query []string
+	for _, param := range res.Parameters {
+		if !param.Disabled {
+			query = append(query, param.Name+"="+convert(param.Value))
+		}
+	}
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + strings.Join(query, "&")
+	}
+	for _, h := range res.Headers {
+		if h.Disabled {
+			continue
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]interface{})
+		}
+		req.Headers[h.Name] = convert(h.Value)
+	}
+
+	switch {
+	case res.Body.Text != "":
+		req.Body, req.HasBody = convertBody(res.Body.Text, convert), true
+	case len(res.Body.Params) > 0:
+		params := make(map[string]interface{})
+		for _, param := range res.Body.Params {
+			if !param.Disabled {
+				params[param.Name] = convert(param.Value)
+			}
+		}
+		req.Body, req.HasBody = params, true
+	}
+
+	applyAuth(&req, p.auth(res.Authentication, warn), warn)
+
+	for _, test := range p.tests[res.ID] {
+		if isAssertion(test.Name) {
+			req.Assertions = append(req.Assertions, test.Name)
+			continue
+		}
+		found := codeAssertions(test.Code)
+		if len(found) == 0 {
+			warn("unit test %q is not imported", test.Name)
+		}
+		req.Assertions = append(req.Assertions, found...)
+	}
+	return req
+}
+
+// template converts variables and template tags to ${...} references.
+// Response tags become references to the results of the steps of the
+// requests they read.
+func (p *insomniaParser) template(s string, warn func(format string, args ...interface{})) string {
+	s = insomniaTagPattern.ReplaceAllStringFunc(s, func(tag string) string {
+		m := insomniaTagPattern.FindStringSubmatch(tag)
+		var args []string
+		for _, arg := range insomniaArgPattern.FindAllStringSubmatch(m[2], -1) {
+			value := arg[1] + arg[2]
+			if strings.HasPrefix(value, "b64::") {
+				encoded := strings.TrimSuffix(strings.TrimPrefix(value, "b64::"), "::46b")
+				if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+					value = string(decoded)
+				}
+			}
+			args = append(args, value)
+		}
+
+		switch m[1] {
+		case "uuid":
+			return "${UUID}"
+		case "now":
+			if len(args) > 0 && args[0] == "unix" {
+				return "${TIMESTAMP}"
+			}
+			return "${ISO_TIMESTAMP}"
+		case "response":
+			if ref, ok := p.responseReference(args); ok {
+				return ref
+			}
+		}
+		warn("template tag %s is not imported", tag)
+		return tag
+	})
+	return convertMustache(s, warn)
+}
+
+// responseReference converts the arguments of a response tag to a
+// reference to the result of a step, like ${login.body.token}, or to the
+// variable the request sets from the same value, like ${login.token}
+func (p *insomniaParser) responseReference(args []string) (string, bool) {
+	if len(args) < 3 {
+		return "", false
+	}
+	stepID, ok := p.stepIDs[args[1]]
+	if !ok {
+		return "", false
+	}
+
+	var path []interface{}
+	switch args[0] {
+	case "body":
+		filter := strings.TrimPrefix(strings.TrimPrefix(args[2], "$"), ".")
+		path = []interface{}{"body"}
+		if filter != "" {
+			keys := resultPath("body." + filter)
+			if keys == nil {
+				return "", false
+			}
+			path = keys
+		}
+	case "header":
+		path = []interface{}{"headers", args[2]}
+	default:
+		return "", false
+	}
+
+	for _, set := range p.sets[args[1]] {
+		if strings.HasPrefix(set.Name, stepID+".") && reflect.DeepEqual(set.Path, path) {
+			return "${" + set.Name + "}", true
+		}
+	}
+	return "${" + stepID + "." + pathString(path) + "}", true
+}
+
+func (p *insomniaParser) variables(data map[string]interface{}) map[string]interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	vars := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			value = p.template(s, p.result.warn)
+		}
+		vars[key] = value
+	}
+	return vars
+}
+
+func insomniaVariables(data map[string]interface{}, result *CollectionImportResult) {
+	for _, key := range sortedKeys(data) {
+		value := convertMustache(stringValue(data[key]), result.warn)
+		replaced := false
+		for i := range result.Variables {
+			if result.Variables[i].Key == key {
+				result.Variables[i].Value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			result.Variables = append(result.Variables, models.EnvironmentVariable{Key: key, Value: value, Enabled: true})
+		}
+	}
+}
+
+// auth converts the authentication of a request or request group
+func (p *insomniaParser) auth(auth map[string]interface{}, warn func(format string, args ...interface{})) *models.CollectionAuth {
+	if auth == nil || auth["disabled"] == true {
+		return nil
+	}
+	value := func(key string) string { return p.template(stringValue(auth[key]), warn) }
+
+	switch stringValue(auth["type"]) {
+	case "", "none", "inherit":
+		return nil
+	case "bearer":
+		return &models.CollectionAuth{Type: "bearer", Bearer: &models.CollectionBearerAuth{
+			Token:  value("token"),
+			Prefix: value("prefix"),
+		}}
+	case "basic":
+		return &models.CollectionAuth{Type: "basic", Basic: &models.CollectionBasicAuth{
+			Username: value("username"),
+			Password: value("password"),
+		}}
+	case "apikey":
+		in := "header"
+		if stringValue(auth["addTo"]) == "queryParams" {
+			in = "query"
+		}
+		return &models.CollectionAuth{Type: "api_key", APIKey: &models.CollectionAPIKeyAuth{
+			Key:   value("key"),
+			Value: value("value"),
+			In:    in,
+		}}
+	case "oauth2":
+		return &models.CollectionAuth{Type: "oauth2", OAuth2: &models.CollectionOAuth2Auth{
+			GrantType:    value("grantType"),
+			ClientID:     value("clientId"),
+			ClientSecret: value("clientSecret"),
+			AuthURL:      value("authorizationUrl"),
+			TokenURL:     value("accessTokenUrl"),
+			RedirectURI:  value("redirectUrl"),
+			Scope:        value("scope"),
+			AccessToken:  value("accessToken"),
+		}}
+	}
+	warn("%s authentication is not imported", stringValue(auth["type"]))
+	return nil
+}
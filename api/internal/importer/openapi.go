@@ -0,0 +1,1028 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIGroupBy controls how operations are grouped into flows
+type OpenAPIGroupBy string
+
+const (
+	OpenAPIGroupByOperation OpenAPIGroupBy = "operation" // One flow per operation
+	OpenAPIGroupByTag       OpenAPIGroupBy = "tag"       // One flow per tag, one step per operation
+)
+
+// OpenAPIOptions configures the OpenAPI importer
+type OpenAPIOptions struct {
+	GroupBy OpenAPIGroupBy `json:"group_by"`
+	// SchemaRef names the workspace API spec the document is stored as. When set,
+	// responses are checked with schema assertions referencing it instead of
+	// expression assertions on required properties.
+	SchemaRef string `json:"schema_ref,omitempty"`
+	// AttachSpec checks responses against the workspace API spec even when
+	// SchemaRef is empty, naming it after the document title.
+	AttachSpec bool `json:"attach_spec,omitempty"`
+}
+
+// OpenAPIImportResult extends ImportResult with the environment and auth derived from the spec
+type OpenAPIImportResult struct {
+	ImportResult
+	Title     string                       `json:"title"`
+	Version   string                       `json:"version"`
+	SpecType  string                       `json:"spec_type"` // "openapi3" or "swagger2"
+	Variables []models.EnvironmentVariable `json:"variables"`
+	Auth      *models.CollectionAuth       `json:"auth,omitempty"`
+}
+
+// openAPIMethods lists HTTP methods in the order operations are emitted
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// maxExampleDepth bounds schema recursion when synthesizing examples
+const maxExampleDepth = 6
+
+// openAPIDoc wraps a parsed OpenAPI/Swagger document for traversal
+type openAPIDoc struct {
+	root      map[string]interface{}
+	swagger2  bool
+	schemaRef string
+}
+
+// openAPIOperation is a single resolved operation from the spec
+type openAPIOperation struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []map[string]interface{}
+	Op          map[string]interface{}
+}
+
+// ParseOpenAPI parses an OpenAPI 3 or Swagger 2 document (JSON or YAML) and
+// converts its operations to runnable flow definitions
+func ParseOpenAPI(content string, opts OpenAPIOptions) (*OpenAPIImportResult, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	root, ok := normalizeYAML(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: root is not an object")
+	}
+
+	doc := &openAPIDoc{root: root, schemaRef: opts.SchemaRef}
+	result := &OpenAPIImportResult{}
+
+	switch {
+	case stringValue(root["openapi"]) != "":
+		result.SpecType = "openapi3"
+	case stringValue(root["swagger"]) != "":
+		doc.swagger2 = true
+		result.SpecType = "swagger2"
+	default:
+		return nil, fmt.Errorf("document is neither OpenAPI 3 nor Swagger 2")
+	}
+
+	info := mapValue(root["info"])
+	result.Title = stringValue(info["title"])
+	if result.Title == "" {
+		result.Title = "OpenAPI Import"
+	}
+	result.Version = stringValue(info["version"])
+	if opts.AttachSpec && doc.schemaRef == "" {
+		doc.schemaRef = result.Title
+	}
+
+	result.Variables = doc.serverVariables()
+
+	schemes, authVars, authWarnings := doc.securitySchemes()
+	result.Variables = append(result.Variables, authVars...)
+	result.Warnings = append(result.Warnings, authWarnings...)
+	result.Auth = doc.collectionAuth(schemes)
+
+	operations := doc.operations()
+	result.Stats.TotalRequests = len(operations)
+
+	steps := make([]models.Step, 0, len(operations))
+	paramVars := make(map[string]models.EnvironmentVariable)
+	for _, op := range operations {
+		step, vars, warnings := doc.operationToStep(op, schemes)
+		steps = append(steps, step)
+		for _, v := range vars {
+			if _, exists := paramVars[v.Key]; !exists {
+				paramVars[v.Key] = v
+			}
+		}
+		for _, w := range warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: %s", strings.ToUpper(op.Method), op.Path, w))
+		}
+	}
+
+	keys := make([]string, 0, len(paramVars))
+	for k := range paramVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result.Variables = appendVariable(result.Variables, paramVars[k])
+	}
+
+	if opts.GroupBy == OpenAPIGroupByTag {
+		result.Flows = groupStepsByTag(result.Title, operations, steps)
+	} else {
+		for i, op := range operations {
+			result.Flows = append(result.Flows, models.FlowDefinition{
+				Name:        fmt.Sprintf("%s / %s", result.Title, operationLabel(op)),
+				Description: operationDescription(op),
+				Suite:       result.Title,
+				Tags:        append([]string{"openapi"}, op.Tags...),
+				Steps:       []models.Step{steps[i]},
+			})
+		}
+	}
+	result.Stats.SuccessfulFlows = len(result.Flows)
+
+	return result, nil
+}
+
+// groupStepsByTag builds one flow per tag, keeping spec order within each flow
+func groupStepsByTag(title string, operations []openAPIOperation, steps []models.Step) []models.FlowDefinition {
+	var order []string
+	grouped := make(map[string][]models.Step)
+	usedIDs := make(map[string]map[string]bool)
+
+	for i, op := range operations {
+		tag := "default"
+		if len(op.Tags) > 0 {
+			tag = op.Tags[0]
+		}
+		if _, ok := grouped[tag]; !ok {
+			order = append(order, tag)
+			usedIDs[tag] = make(map[string]bool)
+		}
+
+		step := steps[i]
+		step.ID = uniqueStepID(step.ID, usedIDs[tag])
+		grouped[tag] = append(grouped[tag], step)
+	}
+
+	flows := make([]models.FlowDefinition, 0, len(order))
+	for _, tag := range order {
+		flows = append(flows, models.FlowDefinition{
+			Name:        fmt.Sprintf("%s / %s", title, tag),
+			Description: fmt.Sprintf("Imported from OpenAPI - operations tagged %q", tag),
+			Suite:       title,
+			Tags:        []string{"openapi", tag},
+			Steps:       grouped[tag],
+		})
+	}
+	return flows
+}
+
+// serverVariables derives BASE_URL (and alternates) from servers or host/basePath
+func (d *openAPIDoc) serverVariables() []models.EnvironmentVariable {
+	var urls []string
+
+	if d.swagger2 {
+		host := stringValue(d.root["host"])
+		if host == "" {
+			host = "localhost"
+		}
+		scheme := "https"
+		if schemes := sliceValue(d.root["schemes"]); len(schemes) > 0 {
+			scheme = stringValue(schemes[0])
+		}
+		urls = append(urls, scheme+"://"+host+strings.TrimSuffix(stringValue(d.root["basePath"]), "/"))
+	} else {
+		for _, s := range sliceValue(d.root["servers"]) {
+			server := mapValue(s)
+			serverURL := stringValue(server["url"])
+			for name, v := range mapValue(server["variables"]) {
+				serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", stringValue(mapValue(v)["default"]))
+			}
+			urls = append(urls, strings.TrimSuffix(serverURL, "/"))
+		}
+	}
+
+	if len(urls) == 0 {
+		urls = []string{"http://localhost"}
+	}
+
+	vars := []models.EnvironmentVariable{{
+		Key:         "BASE_URL",
+		Value:       urls[0],
+		Description: "API base URL (from OpenAPI servers)",
+		Enabled:     true,
+	}}
+	for i, u := range urls[1:] {
+		vars = append(vars, models.EnvironmentVariable{
+			Key:         fmt.Sprintf("BASE_URL_%d", i+2),
+			Value:       u,
+			Description: "Alternate server - copy into BASE_URL to use it",
+			Enabled:     false,
+		})
+	}
+	return vars
+}
+
+// openAPISecurityScheme is a resolved security scheme with its variable names
+type openAPISecurityScheme struct {
+	Name   string
+	Type   string // "bearer", "basic", "api_key", "oauth2"
+	In     string // for api_key: "header", "query", "cookie"
+	Param  string // for api_key: header/query/cookie name
+	EnvVar string
+	Raw    map[string]interface{}
+}
+
+// securitySchemes resolves securitySchemes (v3) or securityDefinitions (v2)
+func (d *openAPIDoc) securitySchemes() (map[string]*openAPISecurityScheme, []models.EnvironmentVariable, []string) {
+	var defs map[string]interface{}
+	if d.swagger2 {
+		defs = mapValue(d.root["securityDefinitions"])
+	} else {
+		defs = mapValue(mapValue(d.root["components"])["securitySchemes"])
+	}
+
+	schemes := make(map[string]*openAPISecurityScheme)
+	var vars []models.EnvironmentVariable
+	var warnings []string
+
+	for _, name := range sortedKeys(defs) {
+		def := d.resolve(mapValue(defs[name]))
+		envBase := envVarName(name)
+		scheme := &openAPISecurityScheme{Name: name, Raw: def}
+
+		switch strings.ToLower(stringValue(def["type"])) {
+		case "http":
+			if strings.EqualFold(stringValue(def["scheme"]), "basic") {
+				scheme.Type = "basic"
+			} else {
+				scheme.Type = "bearer"
+			}
+		case "basic":
+			scheme.Type = "basic"
+		case "apikey":
+			scheme.Type = "api_key"
+			scheme.In = stringValue(def["in"])
+			scheme.Param = stringValue(def["name"])
+		case "oauth2", "openidconnect":
+			scheme.Type = "oauth2"
+		default:
+			warnings = append(warnings, fmt.Sprintf("security scheme %q of type %q is not supported", name, stringValue(def["type"])))
+			continue
+		}
+
+		if scheme.Type == "basic" {
+			scheme.EnvVar = envBase
+			vars = append(vars,
+				models.EnvironmentVariable{Key: envBase + "_USERNAME", Description: name + " username", Enabled: true},
+				models.EnvironmentVariable{Key: envBase + "_PASSWORD", Description: name + " password", IsSecret: true, Enabled: true},
+			)
+		} else {
+			scheme.EnvVar = envBase
+			vars = append(vars, models.EnvironmentVariable{Key: envBase, Description: name + " credential", IsSecret: true, Enabled: true})
+		}
+		schemes[name] = scheme
+	}
+
+	return schemes, vars, warnings
+}
+
+// collectionAuth maps the primary security scheme to collection-level auth
+func (d *openAPIDoc) collectionAuth(schemes map[string]*openAPISecurityScheme) *models.CollectionAuth {
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	var primary *openAPISecurityScheme
+	for _, name := range securityRequirementNames(d.root["security"]) {
+		if s, ok := schemes[name]; ok {
+			primary = s
+			break
+		}
+	}
+	if primary == nil {
+		names := make([]string, 0, len(schemes))
+		for name := range schemes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		primary = schemes[names[0]]
+	}
+
+	auth := &models.CollectionAuth{Type: primary.Type}
+	switch primary.Type {
+	case "bearer":
+		auth.Bearer = &models.CollectionBearerAuth{Token: "${" + primary.EnvVar + "}", Prefix: "Bearer"}
+	case "basic":
+		auth.Basic = &models.CollectionBasicAuth{
+			Username: "${" + primary.EnvVar + "_USERNAME}",
+			Password: "${" + primary.EnvVar + "_PASSWORD}",
+		}
+	case "api_key":
+		in := primary.In
+		if in != "query" {
+			in = "header"
+		}
+		auth.APIKey = &models.CollectionAPIKeyAuth{Key: primary.Param, Value: "${" + primary.EnvVar + "}", In: in}
+	case "oauth2":
+		auth.OAuth2 = oauth2Settings(primary.Raw, d.swagger2)
+		auth.OAuth2.AccessToken = "${" + primary.EnvVar + "}"
+	}
+	return auth
+}
+
+// oauth2Settings extracts grant type, URLs and scopes from an oauth2 scheme
+func oauth2Settings(def map[string]interface{}, swagger2 bool) *models.CollectionOAuth2Auth {
+	settings := &models.CollectionOAuth2Auth{}
+
+	if swagger2 {
+		settings.GrantType = map[string]string{
+			"application": "client_credentials",
+			"accessCode":  "authorization_code",
+			"password":    "password",
+			"implicit":    "implicit",
+		}[stringValue(def["flow"])]
+		settings.AuthURL = stringValue(def["authorizationUrl"])
+		settings.TokenURL = stringValue(def["tokenUrl"])
+		settings.Scope = strings.Join(sortedKeys(mapValue(def["scopes"])), " ")
+		return settings
+	}
+
+	flows := mapValue(def["flows"])
+	for _, flow := range []struct{ key, grant string }{
+		{"clientCredentials", "client_credentials"},
+		{"authorizationCode", "authorization_code"},
+		{"password", "password"},
+		{"implicit", "implicit"},
+	} {
+		f := mapValue(flows[flow.key])
+		if f == nil {
+			continue
+		}
+		settings.GrantType = flow.grant
+		settings.AuthURL = stringValue(f["authorizationUrl"])
+		settings.TokenURL = stringValue(f["tokenUrl"])
+		settings.Scope = strings.Join(sortedKeys(mapValue(f["scopes"])), " ")
+		break
+	}
+	return settings
+}
+
+// operations returns all operations in deterministic (path, method) order
+func (d *openAPIDoc) operations() []openAPIOperation {
+	paths := mapValue(d.root["paths"])
+	var ops []openAPIOperation
+
+	for _, path := range sortedKeys(paths) {
+		item := d.resolve(mapValue(paths[path]))
+		pathParams := sliceValue(item["parameters"])
+
+		for _, method := range openAPIMethods {
+			opMap := mapValue(item[method])
+			if opMap == nil {
+				continue
+			}
+
+			op := openAPIOperation{
+				Method:      method,
+				Path:        path,
+				OperationID: stringValue(opMap["operationId"]),
+				Summary:     stringValue(opMap["summary"]),
+				Description: stringValue(opMap["description"]),
+				Op:          opMap,
+			}
+			for _, t := range sliceValue(opMap["tags"]) {
+				op.Tags = append(op.Tags, stringValue(t))
+			}
+
+			// Operation-level parameters override path-level ones with the same name+location
+			seen := make(map[string]bool)
+			for _, p := range sliceValue(opMap["parameters"]) {
+				param := d.resolve(mapValue(p))
+				seen[stringValue(param["in"])+":"+stringValue(param["name"])] = true
+				op.Parameters = append(op.Parameters, param)
+			}
+			for _, p := range pathParams {
+				param := d.resolve(mapValue(p))
+				if !seen[stringValue(param["in"])+":"+stringValue(param["name"])] {
+					op.Parameters = append(op.Parameters, param)
+				}
+			}
+
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// pathParamPattern matches {param} placeholders in OpenAPI paths
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// operationToStep converts an operation into an http_request step
+func (d *openAPIDoc) operationToStep(op openAPIOperation, schemes map[string]*openAPISecurityScheme) (models.Step, []models.EnvironmentVariable, []string) {
+	var vars []models.EnvironmentVariable
+	var warnings []string
+
+	headers := make(map[string]interface{})
+	query := url.Values{}
+	var formBody map[string]interface{}
+	formType := "application/x-www-form-urlencoded"
+	var body interface{}
+
+	for _, param := range op.Parameters {
+		name := stringValue(param["name"])
+		required, _ := param["required"].(bool)
+		example := d.parameterExample(param)
+
+		switch stringValue(param["in"]) {
+		case "path":
+			varName := envVarName(name)
+			vars = append(vars, models.EnvironmentVariable{
+				Key:         varName,
+				Value:       fmt.Sprintf("%v", example),
+				Description: fmt.Sprintf("Path parameter %q", name),
+				Enabled:     true,
+			})
+		case "query":
+			if required {
+				query.Set(name, fmt.Sprintf("%v", example))
+			}
+		case "header":
+			if required {
+				headers[name] = fmt.Sprintf("%v", example)
+			}
+		case "body":
+			body = d.exampleFor(mapValue(param["schema"]), nil, 0, nil)
+		case "formData":
+			if formBody == nil {
+				formBody = make(map[string]interface{})
+			}
+			formBody[name] = example
+			if stringValue(param["type"]) == "file" {
+				formType = "multipart/form-data"
+			}
+		}
+	}
+
+	// Path placeholders become environment variable references
+	path := pathParamPattern.ReplaceAllStringFunc(op.Path, func(match string) string {
+		return "${" + envVarName(strings.Trim(match, "{}")) + "}"
+	})
+
+	// OpenAPI 3 request bodies
+	if reqBody := d.resolve(mapValue(op.Op["requestBody"])); reqBody != nil {
+		mediaType, media := pickMediaType(mapValue(reqBody["content"]))
+		if media != nil {
+			body = d.mediaExample(media)
+			headers["Content-Type"] = mediaType
+		}
+	}
+	if body == nil && formBody != nil {
+		// Swagger 2 form parameters are sent as the form the operation consumes
+		body = formBody
+		for _, consumes := range sliceValue(firstNonNil(op.Op["consumes"], d.root["consumes"])) {
+			if stringValue(consumes) == "multipart/form-data" {
+				formType = "multipart/form-data"
+			}
+		}
+		headers["Content-Type"] = formType
+	}
+	if body != nil {
+		if _, ok := headers["Content-Type"]; !ok {
+			headers["Content-Type"] = "application/json"
+		}
+	}
+
+	// Apply security requirements (operation-level overrides global)
+	security := op.Op["security"]
+	if security == nil {
+		security = d.root["security"]
+	}
+	for _, name := range securityRequirementNames(security) {
+		scheme, ok := schemes[name]
+		if !ok {
+			continue
+		}
+		ref := "${" + scheme.EnvVar + "}"
+		switch scheme.Type {
+		case "bearer", "oauth2":
+			headers["Authorization"] = "Bearer " + ref
+		case "api_key":
+			switch scheme.In {
+			case "query":
+				query.Set(scheme.Param, ref)
+			case "cookie":
+				headers["Cookie"] = scheme.Param + "=" + ref
+			default:
+				headers[scheme.Param] = ref
+			}
+		case "basic":
+			warnings = append(warnings, fmt.Sprintf("basic auth %q is set on the collection; add an Authorization header to run the step standalone", name))
+		}
+		break
+	}
+
+	stepURL := "${BASE_URL}" + path
+	if len(query) > 0 {
+		// Keep ${VAR} references readable instead of percent-encoding them
+		stepURL += "?" + strings.NewReplacer("%24%7B", "${", "%7D", "}").Replace(query.Encode())
+	}
+
+	config := map[string]interface{}{
+		"method": strings.ToUpper(op.Method),
+		"url":    stepURL,
+	}
+	if len(headers) > 0 {
+		config["headers"] = headers
+	}
+	if body != nil {
+		config["body"] = body
+	}
+
+	stepID := op.OperationID
+	if stepID == "" {
+		stepID = op.Method + "_" + op.Path
+	}
+
+	step := models.Step{
+		ID:          snakeCase(stepID),
+		Name:        operationLabel(op),
+		Description: op.Description,
+		Action:      "http_request",
+		Config:      config,
+		Assert:      d.responseAssertions(op),
+	}
+	if d.schemaRef != "" {
+		if pointer := d.responseSchemaPointer(op); pointer != "" {
+			step.Schema = models.SchemaAssertions{{Ref: d.schemaRef + "#" + pointer}}
+		}
+	}
+
+	return step, vars, warnings
+}
+
+// responseAssertions builds status and response shape assertions from the spec
+func (d *openAPIDoc) responseAssertions(op openAPIOperation) []string {
+	responses := mapValue(op.Op["responses"])
+	code := expectedStatus(responses)
+	if code == "" {
+		return nil
+	}
+
+	assertions := []string{}
+	if strings.HasSuffix(strings.ToUpper(code), "XX") {
+		class := code[:1]
+		assertions = append(assertions, fmt.Sprintf("status >= %s00 && status < %s00", class, nextDigit(class)))
+	} else {
+		assertions = append(assertions, "status == "+code)
+	}
+
+	response := d.resolve(mapValue(responses[code]))
+	var schema map[string]interface{}
+	if d.swagger2 {
+		schema = mapValue(response["schema"])
+	} else if _, media := pickMediaType(mapValue(response["content"])); media != nil {
+		schema = mapValue(media["schema"])
+	}
+	schema = d.resolve(schema)
+	if schema == nil || d.schemaRef != "" {
+		// With a schema ref the whole body is validated by a schema assertion
+		return assertions
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		for _, prop := range requiredProperties(d, schema) {
+			assertions = append(assertions, fmt.Sprintf("%q in body", prop))
+		}
+	case "array":
+		if minItems, err := strconv.Atoi(stringValue(schema["minItems"])); err == nil && minItems > 0 {
+			assertions = append(assertions, fmt.Sprintf("len(body) >= %d", minItems))
+		}
+	}
+
+	return assertions
+}
+
+// responseSchemaPointer returns the JSON pointer of the expected response's schema
+func (d *openAPIDoc) responseSchemaPointer(op openAPIOperation) string {
+	responses := mapValue(op.Op["responses"])
+	code := expectedStatus(responses)
+	if code == "" {
+		return ""
+	}
+
+	base := "/paths/" + escapeJSONPointer(op.Path) + "/" + op.Method + "/responses/" + escapeJSONPointer(code)
+	response := mapValue(responses[code])
+	if ref := stringValue(response["$ref"]); strings.HasPrefix(ref, "#/") {
+		base = strings.TrimPrefix(ref, "#")
+		response = d.resolve(response)
+	}
+
+	var schema map[string]interface{}
+	if d.swagger2 {
+		schema = mapValue(response["schema"])
+		base += "/schema"
+	} else {
+		mediaType, media := pickMediaType(mapValue(response["content"]))
+		if media == nil || !strings.Contains(mediaType, "json") {
+			return ""
+		}
+		schema = mapValue(media["schema"])
+		base += "/content/" + escapeJSONPointer(mediaType) + "/schema"
+	}
+	if schema == nil {
+		return ""
+	}
+
+	// Point straight at named schemas so assertions stay readable
+	if ref := stringValue(schema["$ref"]); strings.HasPrefix(ref, "#/") {
+		return strings.TrimPrefix(ref, "#")
+	}
+	return base
+}
+
+// escapeJSONPointer escapes a single JSON pointer token
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// expectedStatus picks the lowest 2xx response code, falling back to the lowest declared code
+func expectedStatus(responses map[string]interface{}) string {
+	codes := sortedKeys(responses)
+	for _, c := range codes {
+		if strings.HasPrefix(c, "2") {
+			return c
+		}
+	}
+	for _, c := range codes {
+		if c != "default" {
+			return c
+		}
+	}
+	return ""
+}
+
+// mediaExample returns the example for a media type object (example, examples or schema)
+func (d *openAPIDoc) mediaExample(media map[string]interface{}) interface{} {
+	if ex, ok := media["example"]; ok {
+		return ex
+	}
+	examples := mapValue(media["examples"])
+	for _, name := range sortedKeys(examples) {
+		example := d.resolve(mapValue(examples[name]))
+		if v, ok := example["value"]; ok {
+			return v
+		}
+	}
+	return d.exampleFor(mapValue(media["schema"]), nil, 0, nil)
+}
+
+// parameterExample returns an example value for a parameter
+func (d *openAPIDoc) parameterExample(param map[string]interface{}) interface{} {
+	if ex, ok := param["example"]; ok {
+		return ex
+	}
+	examples := mapValue(param["examples"])
+	for _, name := range sortedKeys(examples) {
+		if v, ok := d.resolve(mapValue(examples[name]))["value"]; ok {
+			return v
+		}
+	}
+	name := stringValue(param["name"])
+	if schema := mapValue(param["schema"]); schema != nil {
+		return d.exampleFor(schema, name, 0, nil)
+	}
+	// Swagger 2 non-body parameters carry the schema inline
+	return d.exampleFor(param, name, 0, nil)
+}
+
+// exampleFor synthesizes an example value from a schema
+func (d *openAPIDoc) exampleFor(schema map[string]interface{}, name interface{}, depth int, refs []string) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if ref := stringValue(schema["$ref"]); ref != "" {
+		for _, r := range refs {
+			if r == ref {
+				return nil // recursive schema
+			}
+		}
+		return d.exampleFor(d.lookup(ref), name, depth, append(refs, ref))
+	}
+
+	if ex, ok := schema["example"]; ok {
+		return ex
+	}
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum := sliceValue(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf := sliceValue(schema["allOf"]); len(allOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, sub := range allOf {
+			if m, ok := d.exampleFor(mapValue(sub), name, depth, refs).(map[string]interface{}); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants := sliceValue(schema[key]); len(variants) > 0 {
+			return d.exampleFor(mapValue(variants[0]), name, depth, refs)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := make(map[string]interface{})
+		props := mapValue(schema["properties"])
+		for _, prop := range sortedKeys(props) {
+			if v := d.exampleFor(mapValue(props[prop]), prop, depth+1, refs); v != nil {
+				obj[prop] = v
+			}
+		}
+		return obj
+	case "array":
+		item := d.exampleFor(mapValue(schema["items"]), name, depth+1, refs)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "integer":
+		if min, ok := schema["minimum"]; ok {
+			return min
+		}
+		return 1
+	case "number":
+		if min, ok := schema["minimum"]; ok {
+			return min
+		}
+		return 1.5
+	case "boolean":
+		return true
+	default:
+		return stringExample(stringValue(schema["format"]), name)
+	}
+}
+
+// stringExample returns a plausible string for a format
+func stringExample(format string, name interface{}) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "12:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000001"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	case "password":
+		return "password"
+	}
+	if n, ok := name.(string); ok && n != "" {
+		return n
+	}
+	return "string"
+}
+
+// requiredProperties returns required property names, following allOf
+func requiredProperties(d *openAPIDoc, schema map[string]interface{}) []string {
+	var props []string
+	for _, r := range sliceValue(schema["required"]) {
+		props = append(props, stringValue(r))
+	}
+	for _, sub := range sliceValue(schema["allOf"]) {
+		props = append(props, requiredProperties(d, d.resolve(mapValue(sub)))...)
+	}
+	return props
+}
+
+// schemaType returns the effective type of a schema
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		// OpenAPI 3.1 allows type arrays such as [string, "null"]
+		for _, v := range t {
+			if s := stringValue(v); s != "null" {
+				return s
+			}
+		}
+	}
+	if schema["properties"] != nil {
+		return "object"
+	}
+	if schema["items"] != nil {
+		return "array"
+	}
+	return ""
+}
+
+// resolve follows a $ref if present
+func (d *openAPIDoc) resolve(obj map[string]interface{}) map[string]interface{} {
+	for i := 0; i < 16 && obj != nil; i++ {
+		ref := stringValue(obj["$ref"])
+		if ref == "" {
+			return obj
+		}
+		obj = d.lookup(ref)
+	}
+	return obj
+}
+
+// lookup resolves a local JSON pointer reference (#/components/schemas/Pet)
+func (d *openAPIDoc) lookup(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var current interface{} = d.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		current = mapValue(current)[part]
+		if current == nil {
+			return nil
+		}
+	}
+	return mapValue(current)
+}
+
+// pickMediaType prefers JSON media types, then falls back to the first declared
+func pickMediaType(content map[string]interface{}) (string, map[string]interface{}) {
+	keys := sortedKeys(content)
+	for _, k := range keys {
+		if strings.Contains(k, "json") {
+			return k, mapValue(content[k])
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0], mapValue(content[keys[0]])
+	}
+	return "", nil
+}
+
+// securityRequirementNames returns scheme names from a security requirement list
+func securityRequirementNames(security interface{}) []string {
+	var names []string
+	for _, req := range sliceValue(security) {
+		names = append(names, sortedKeys(mapValue(req))...)
+	}
+	return names
+}
+
+func operationLabel(op openAPIOperation) string {
+	if op.Summary != "" {
+		return op.Summary
+	}
+	if op.OperationID != "" {
+		return op.OperationID
+	}
+	return strings.ToUpper(op.Method) + " " + op.Path
+}
+
+func operationDescription(op openAPIOperation) string {
+	if op.Description != "" {
+		return op.Description
+	}
+	return fmt.Sprintf("Imported from OpenAPI - %s %s", strings.ToUpper(op.Method), op.Path)
+}
+
+func appendVariable(vars []models.EnvironmentVariable, v models.EnvironmentVariable) []models.EnvironmentVariable {
+	for _, existing := range vars {
+		if existing.Key == v.Key {
+			return vars
+		}
+	}
+	return append(vars, v)
+}
+
+func uniqueStepID(id string, used map[string]bool) string {
+	candidate := id
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", id, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func nextDigit(d string) string {
+	return string(rune(d[0] + 1))
+}
+
+var (
+	camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	nonIdentChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+)
+
+// snakeCase converts operation IDs and paths to step IDs (listPets -> list_pets)
+func snakeCase(s string) string {
+	s = camelBoundary.ReplaceAllString(s, "${1}_${2}")
+	s = nonIdentChars.ReplaceAllString(s, "_")
+	s = strings.Trim(strings.ToLower(s), "_")
+	if s == "" {
+		return "request"
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		s = "op_" + s
+	}
+	return s
+}
+
+// envVarName converts a name to an interpolatable variable name (petId -> PET_ID)
+func envVarName(s string) string {
+	return strings.ToUpper(snakeCase(s))
+}
+
+// normalizeYAML converts map[interface{}]interface{} (e.g. integer status code keys) to map[string]interface{}
+func normalizeYAML(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[fmt.Sprintf("%v", k)] = normalizeYAML(item)
+		}
+		return out
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = normalizeYAML(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeYAML(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func mapValue(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func firstNonNil(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func sliceValue(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+func stringValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,346 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// PostmanCollection represents a Postman collection
+type PostmanCollection struct {
+	Info struct {
+		Name        string      `json:"name"`
+		Description interface{} `json:"description"` // String or {content}
+		Schema      string      `json:"schema"`
+	} `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Auth     PostmanAuth       `json:"auth,omitempty"`
+	Event    []PostmanEvent    `json:"event,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanItem represents an item in a Postman collection
+type PostmanItem struct {
+	ID          string            `json:"id,omitempty"`
+	Name        string            `json:"name"`
+	Description interface{}       `json:"description,omitempty"`
+	Request     *PostmanRequest   `json:"request,omitempty"`
+	Response    []PostmanResponse `json:"response,omitempty"`
+	Item        []PostmanItem     `json:"item,omitempty"` // For folders
+	Auth        PostmanAuth       `json:"auth,omitempty"`
+	Event       []PostmanEvent    `json:"event,omitempty"`
+	Variable    []PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanRequest represents a request in Postman format
+type PostmanRequest struct {
+	Method      string          `json:"method"`
+	Header      []PostmanHeader `json:"header,omitempty"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+	URL         interface{}     `json:"url"` // Can be string or object
+	Auth        PostmanAuth     `json:"auth,omitempty"`
+	Description interface{}     `json:"description,omitempty"`
+}
+
+// PostmanHeader represents a header in Postman format
+type PostmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// PostmanBody represents a request body in Postman format
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []PostmanURLEncoded `json:"urlencoded,omitempty"`
+	FormData   []PostmanFormData   `json:"formdata,omitempty"`
+}
+
+// PostmanURLEncoded represents URL-encoded form data
+type PostmanURLEncoded struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// PostmanFormData represents multipart form data
+type PostmanFormData struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Src   string `json:"src,omitempty"`
+}
+
+// PostmanResponse represents a saved response
+type PostmanResponse struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Code   int    `json:"code"`
+	Body   string `json:"body,omitempty"`
+}
+
+// PostmanVariable represents a collection variable
+type PostmanVariable struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+// PostmanAuth represents the auth of a collection, folder or request, like
+// {"type": "bearer", "bearer": [{"key": "token", "value": "..."}]}
+type PostmanAuth map[string]interface{}
+
+// PostmanEvent represents a script of a collection, folder or request
+type PostmanEvent struct {
+	Listen string `json:"listen"` // prerequest or test
+	Script struct {
+		Type string      `json:"type,omitempty"`
+		Exec interface{} `json:"exec"` // Lines or a string
+	} `json:"script"`
+}
+
+// ParsePostman parses a Postman collection. Folders of requests become
+// flows, and the tests and variables set by their scripts become
+// assertions and outputs of the steps.
+func ParsePostman(content string) (*CollectionImportResult, error) {
+	var collection PostmanCollection
+	if err := json.Unmarshal([]byte(content), &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	result := &CollectionImportResult{}
+	name := collection.Info.Name
+	if name == "" {
+		name = "Postman Collection"
+	}
+	root := &clientFolder{
+		Name:        name,
+		Description: postmanText(collection.Info.Description),
+		Auth:        postmanAuth(collection.Auth, result.warn),
+	}
+	for _, v := range collection.Variable {
+		result.Variables = appendVariable(result.Variables, models.EnvironmentVariable{
+			Key:      v.Key,
+			Value:    convertMustache(stringValue(v.Value), result.warn),
+			IsSecret: v.Type == "secret",
+			Enabled:  true,
+		})
+	}
+	if postmanScript(collection.Event, "prerequest") != "" || postmanScript(collection.Event, "test") != "" {
+		result.warn("%s: scripts of the collection are not imported", name)
+	}
+
+	postmanItems(collection.Item, root, result)
+	buildCollection(root, result)
+	return result, nil
+}
+
+// postmanItems reads the folders and requests of items into a folder
+func postmanItems(items []PostmanItem, f *clientFolder, result *CollectionImportResult) {
+	for i, item := range items {
+		if item.Request == nil {
+			if len(item.Item) == 0 {
+				continue
+			}
+			child := &clientFolder{
+				Name:        item.Name,
+				Description: postmanText(item.Description),
+				Auth:        postmanAuth(item.Auth, result.warn),
+				Variables:   postmanVariables(item.Variable, result.warn),
+				order:       float64(i),
+			}
+			if postmanScript(item.Event, "prerequest") != "" || postmanScript(item.Event, "test") != "" {
+				result.warn("%s: scripts of folders are not imported", item.Name)
+			}
+			postmanItems(item.Item, child, result)
+			f.Folders = append(f.Folders, child)
+			continue
+		}
+
+		req, ok := postmanRequest(item, result)
+		if !ok {
+			result.Stats.SkippedRequests++
+			continue
+		}
+		req.order = float64(i)
+		f.Requests = append(f.Requests, req)
+	}
+}
+
+// postmanRequest converts a request item
+func postmanRequest(item PostmanItem, result *CollectionImportResult) (clientRequest, bool) {
+	warn := func(format string, args ...interface{}) {
+		result.warn("%s: "+format, append([]interface{}{item.Name}, args...)...)
+	}
+	convert := func(s string) string { return convertMustache(s, warn) }
+	r := item.Request
+
+	var requestURL string
+	switch u := r.URL.(type) {
+	case string:
+		requestURL = u
+	case map[string]interface{}:
+		requestURL = stringValue(u["raw"])
+	}
+	if requestURL == "" {
+		warn("no URL found")
+		return clientRequest{}, false
+	}
+
+	req := clientRequest{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: postmanText(r.Description),
+		Method:      r.Method,
+		URL:         convert(requestURL),
+	}
+	if req.Description == "" {
+		req.Description = postmanText(item.Description)
+	}
+	for _, h := range r.Header {
+		if h.Disabled {
+			continue
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]interface{})
+		}
+		req.Headers[h.Key] = convert(h.Value)
+	}
+
+	if r.Body != nil {
+		switch r.Body.Mode {
+		case "raw":
+			if r.Body.Raw != "" {
+				req.Body, req.HasBody = convertBody(r.Body.Raw, convert), true
+			}
+		case "urlencoded":
+			params := make(map[string]interface{})
+			for _, p := range r.Body.URLEncoded {
+				params[p.Key] = convert(p.Value)
+			}
+			req.Body, req.HasBody = params, true
+		case "formdata":
+			params := make(map[string]interface{})
+			for _, p := range r.Body.FormData {
+				if p.Type == "file" {
+					warn("file field %s is not imported", p.Key)
+					continue
+				}
+				params[p.Key] = convert(p.Value)
+			}
+			req.Body, req.HasBody = params, true
+		case "":
+		default:
+			warn("%s bodies are not imported", r.Body.Mode)
+		}
+	}
+
+	applyAuth(&req, postmanAuth(r.Auth, warn), warn)
+
+	if postmanScript(item.Event, "prerequest") != "" {
+		warn("pre-request scripts are not imported")
+	}
+	if script := postmanScript(item.Event, "test"); script != "" {
+		req.Assertions = scriptAssertions(script, warn)
+		req.Sets = scriptSets(script, warn)
+	}
+	return req, true
+}
+
+// postmanScript returns the script of an event
+func postmanScript(events []PostmanEvent, listen string) string {
+	var lines []string
+	for _, event := range events {
+		if event.Listen != listen {
+			continue
+		}
+		switch exec := event.Script.Exec.(type) {
+		case string:
+			lines = append(lines, exec)
+		case []interface{}:
+			for _, line := range exec {
+				lines = append(lines, stringValue(line))
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// postmanText returns a description, which is a string or {content}
+func postmanText(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok {
+		return stringValue(m["content"])
+	}
+	return stringValue(v)
+}
+
+func postmanVariables(vars []PostmanVariable, warn func(format string, args ...interface{})) map[string]interface{} {
+	if len(vars) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(vars))
+	for _, v := range vars {
+		values[v.Key] = convertMustache(stringValue(v.Value), warn)
+	}
+	return values
+}
+
+// postmanAuth converts Postman auth to collection auth
+func postmanAuth(auth PostmanAuth, warn func(format string, args ...interface{})) *models.CollectionAuth {
+	kind := stringValue(auth["type"])
+	// Parameters are a list of {key, value} in v2.1, and an object in v2.0
+	params := make(map[string]string)
+	switch p := auth[kind].(type) {
+	case []interface{}:
+		for _, item := range p {
+			m := mapValue(item)
+			params[stringValue(m["key"])] = convertMustache(stringValue(m["value"]), warn)
+		}
+	case map[string]interface{}:
+		for key, value := range p {
+			params[key] = convertMustache(stringValue(value), warn)
+		}
+	}
+
+	switch kind {
+	case "", "noauth", "inherit":
+		return nil
+	case "bearer":
+		return &models.CollectionAuth{Type: "bearer", Bearer: &models.CollectionBearerAuth{Token: params["token"]}}
+	case "basic":
+		return &models.CollectionAuth{Type: "basic", Basic: &models.CollectionBasicAuth{
+			Username: params["username"],
+			Password: params["password"],
+		}}
+	case "apikey":
+		in := "header"
+		if params["in"] == "query" {
+			in = "query"
+		}
+		return &models.CollectionAuth{Type: "api_key", APIKey: &models.CollectionAPIKeyAuth{
+			Key:   params["key"],
+			Value: params["value"],
+			In:    in,
+		}}
+	case "oauth2":
+		grantType := params["grant_type"]
+		if grantType == "password_credentials" {
+			grantType = "password"
+		}
+		return &models.CollectionAuth{Type: "oauth2", OAuth2: &models.CollectionOAuth2Auth{
+			GrantType:    grantType,
+			ClientID:     params["clientId"],
+			ClientSecret: params["clientSecret"],
+			AuthURL:      params["authUrl"],
+			TokenURL:     params["accessTokenUrl"],
+			RedirectURI:  params["redirect_uri"],
+			Scope:        params["scope"],
+			AccessToken:  params["accessToken"],
+		}}
+	}
+	warn("%s auth is not imported", kind)
+	return nil
+}
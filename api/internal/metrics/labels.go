@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// overflowValue replaces label values beyond the per-label limit
+const overflowValue = "other"
+
+// fixedLabels only take a handful of values and are never capped
+var fixedLabels = map[string]bool{"status": true, "matched": true, "result": true}
+
+// labelPolicy keeps label cardinality bounded. Dropped labels are left out of
+// every series, and each remaining label that is named after a user-defined
+// thing (flow, suite, environment, action, mock server, schedule) keeps at most
+// maxValues distinct values; later values are reported as "other".
+type labelPolicy struct {
+	dropped   map[string]bool
+	maxValues int
+
+	mu     sync.Mutex
+	values map[string]map[string]bool
+}
+
+func newLabelPolicy(drop []string, maxValues int) *labelPolicy {
+	p := &labelPolicy{
+		dropped:   make(map[string]bool),
+		maxValues: maxValues,
+		values:    make(map[string]map[string]bool),
+	}
+	for _, name := range drop {
+		p.dropped[name] = true
+	}
+	return p
+}
+
+// names returns the label names a vector is created with
+func (p *labelPolicy) names(labels ...string) []string {
+	kept := make([]string, 0, len(labels))
+	for _, name := range labels {
+		if !p.dropped[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// apply removes dropped labels and caps the values of the others
+func (p *labelPolicy) apply(labels prometheus.Labels) prometheus.Labels {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(prometheus.Labels, len(labels))
+	for name, value := range labels {
+		if p.dropped[name] {
+			continue
+		}
+		if value == "" {
+			value = "none"
+		}
+		result[name] = p.limit(name, value)
+	}
+	return result
+}
+
+func (p *labelPolicy) limit(name, value string) string {
+	if p.maxValues <= 0 || fixedLabels[name] {
+		return value
+	}
+	seen, ok := p.values[name]
+	if !ok {
+		seen = make(map[string]bool)
+		p.values[name] = seen
+	}
+	if seen[value] {
+		return value
+	}
+	if len(seen) >= p.maxValues {
+		return overflowValue
+	}
+	seen[value] = true
+	return value
+}
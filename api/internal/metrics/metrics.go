@@ -0,0 +1,251 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/shared/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "testmesh"
+
+// Buckets for flow and step durations, from 10ms to 5 minutes
+var durationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Buckets for schedule lag, from 100ms to 1 hour
+var lagBuckets = []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 300, 900, 3600}
+
+// Metrics holds the Prometheus collectors of the API server.
+//
+// All recording methods are safe to call on a nil *Metrics, so instrumented
+// code works the same whether metrics are enabled or not.
+type Metrics struct {
+	registry *prometheus.Registry
+	labels   *labelPolicy
+	path     string
+
+	executionsTotal   *prometheus.CounterVec
+	executionDuration *prometheus.HistogramVec
+	executionReruns   *prometheus.CounterVec
+	executionsRunning prometheus.Gauge
+
+	stepsTotal   *prometheus.CounterVec
+	stepDuration *prometheus.HistogramVec
+	stepRetries  *prometheus.CounterVec
+
+	mockRequests *prometheus.CounterVec
+
+	scheduleRuns   *prometheus.CounterVec
+	scheduleLag    *prometheus.HistogramVec
+	scheduleMissed *prometheus.CounterVec
+}
+
+// New creates the collectors, or returns nil when metrics are disabled
+func New(cfg config.MetricsConfig) *Metrics {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = "/metrics"
+	}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		labels:   newLabelPolicy(cfg.DropLabels, cfg.MaxLabelValues),
+		path:     path,
+	}
+
+	m.executionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "executions_total",
+		Help:      "Finished execution attempts by outcome: passed, failed or quarantined.",
+	}, m.labels.names("flow", "suite", "environment", "status"))
+
+	m.executionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "execution_duration_seconds",
+		Help:      "Duration of execution attempts.",
+		Buckets:   durationBuckets,
+	}, m.labels.names("flow", "suite", "environment"))
+
+	m.executionReruns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "execution_reruns_total",
+		Help:      "Reruns of failed executions.",
+	}, m.labels.names("flow", "suite", "environment"))
+
+	m.executionsRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "executions_running",
+		Help:      "Execution attempts currently running.",
+	})
+
+	m.stepsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "steps_total",
+		Help:      "Finished steps by action type and status.",
+	}, m.labels.names("action", "status"))
+
+	m.stepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Duration of steps by action type, including retries.",
+		Buckets:   durationBuckets,
+	}, m.labels.names("action"))
+
+	m.stepRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "step_retries_total",
+		Help:      "Step retries by action type.",
+	}, m.labels.names("action"))
+
+	m.mockRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mock_requests_total",
+		Help:      "Requests served by mock servers; matched is false when no endpoint matched.",
+	}, m.labels.names("mock_server", "matched"))
+
+	m.scheduleRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schedule_runs_total",
+		Help:      "Finished schedule runs by result: success, failure or skipped.",
+	}, m.labels.names("schedule", "result"))
+
+	m.scheduleLag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "schedule_lag_seconds",
+		Help:      "Delay between the scheduled time of a run and its start, before jitter.",
+		Buckets:   lagBuckets,
+	}, m.labels.names("schedule"))
+
+	m.scheduleMissed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schedule_missed_total",
+		Help:      "Occurrences dropped by the misfire policy of a schedule.",
+	}, m.labels.names("schedule"))
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.executionsTotal,
+		m.executionDuration,
+		m.executionReruns,
+		m.executionsRunning,
+		m.stepsTotal,
+		m.stepDuration,
+		m.stepRetries,
+		m.mockRequests,
+		m.scheduleRuns,
+		m.scheduleLag,
+		m.scheduleMissed,
+	)
+
+	return m
+}
+
+// Path returns the path the metrics are served on
+func (m *Metrics) Path() string {
+	return m.path
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterGauge adds a gauge whose value is read from fn on every scrape
+func (m *Metrics) RegisterGauge(name, help string, fn func() float64) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, fn))
+}
+
+// ExecutionStarted records an execution attempt that started running
+func (m *Metrics) ExecutionStarted() {
+	if m == nil {
+		return
+	}
+	m.executionsRunning.Inc()
+}
+
+// ExecutionFinished records the outcome of an execution attempt. Attempts
+// after the first count as reruns.
+func (m *Metrics) ExecutionFinished(flow, suite, environment, status string, duration time.Duration, attempt int) {
+	if m == nil {
+		return
+	}
+	m.executionsRunning.Dec()
+
+	labels := m.labels.apply(prometheus.Labels{"flow": flow, "suite": suite, "environment": environment})
+	m.executionDuration.With(labels).Observe(duration.Seconds())
+	if attempt > 1 {
+		m.executionReruns.With(labels).Inc()
+	}
+
+	labels["status"] = status
+	m.executionsTotal.With(labels).Inc()
+}
+
+// StepFinished records a finished step and its retries
+func (m *Metrics) StepFinished(action, status string, duration time.Duration, attempts int) {
+	if m == nil {
+		return
+	}
+	labels := m.labels.apply(prometheus.Labels{"action": action})
+	m.stepDuration.With(labels).Observe(duration.Seconds())
+	if attempts > 1 {
+		m.stepRetries.With(labels).Add(float64(attempts - 1))
+	}
+
+	labels["status"] = status
+	m.stepsTotal.With(labels).Inc()
+}
+
+// MockRequest records a request served by a mock server
+func (m *Metrics) MockRequest(mockServer string, matched bool) {
+	if m == nil {
+		return
+	}
+	m.mockRequests.With(m.labels.apply(prometheus.Labels{
+		"mock_server": mockServer,
+		"matched":     strconv.FormatBool(matched),
+	})).Inc()
+}
+
+// ScheduleRunStarted records how late a schedule run started
+func (m *Metrics) ScheduleRunStarted(schedule string, lag time.Duration) {
+	if m == nil {
+		return
+	}
+	if lag < 0 {
+		lag = 0
+	}
+	m.scheduleLag.With(m.labels.apply(prometheus.Labels{"schedule": schedule})).Observe(lag.Seconds())
+}
+
+// ScheduleRunFinished records the result of a schedule run
+func (m *Metrics) ScheduleRunFinished(schedule, result string) {
+	if m == nil {
+		return
+	}
+	m.scheduleRuns.With(m.labels.apply(prometheus.Labels{"schedule": schedule, "result": result})).Inc()
+}
+
+// ScheduleMissed records occurrences of a schedule that did not run
+func (m *Metrics) ScheduleMissed(schedule string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.scheduleMissed.With(m.labels.apply(prometheus.Labels{"schedule": schedule})).Add(float64(count))
+}
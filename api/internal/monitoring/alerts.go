@@ -0,0 +1,223 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"go.uber.org/zap"
+)
+
+// transition returns the next alert state. A breach first makes the alert
+// pending; it fires once the breach lasted pendingFor, or right away when
+// pendingFor is zero. A pending alert whose breach ends goes back to ok
+// without notifying, a firing one is resolved.
+func transition(state models.AlertState, breaching bool, pendingSince time.Time, pendingFor time.Duration, now time.Time) models.AlertState {
+	switch state {
+	case models.AlertStatePending:
+		if !breaching {
+			return models.AlertStateOK
+		}
+		if now.Sub(pendingSince) >= pendingFor {
+			return models.AlertStateFiring
+		}
+		return models.AlertStatePending
+	case models.AlertStateFiring:
+		if !breaching {
+			return models.AlertStateResolved
+		}
+		return models.AlertStateFiring
+	default:
+		if !breaching {
+			return models.AlertStateOK
+		}
+		if pendingFor <= 0 {
+			return models.AlertStateFiring
+		}
+		return models.AlertStatePending
+	}
+}
+
+// breaches lists the breached SLOs of a monitor. A monitor without SLOs is
+// breached while its last check failed.
+func breaches(monitor *models.Monitor, statuses []models.SLOStatus, check *models.MonitorCheck) (names []string, reason string) {
+	if len(monitor.SLOs) == 0 {
+		if check.Result == "failure" {
+			reason = "check failed"
+			if check.Error != "" {
+				reason += ": " + check.Error
+			}
+			return []string{"check"}, reason
+		}
+		return nil, ""
+	}
+
+	var descriptions []string
+	for _, status := range statuses {
+		if status.Met {
+			continue
+		}
+		names = append(names, status.Name)
+		descriptions = append(descriptions, describeBreach(status))
+	}
+	return names, strings.Join(descriptions, "; ")
+}
+
+// evaluateAlert moves the alert of a monitor to its next state after a check.
+// A monitor has at most one open alert, so a breach that continues over many
+// checks is a single alert, notified once when it fires and once when it is
+// resolved, plus every repeat interval while it fires.
+func (m *Manager) evaluateAlert(monitor *models.Monitor, statuses []models.SLOStatus, check *models.MonitorCheck, now time.Time) {
+	names, reason := breaches(monitor, statuses, check)
+
+	alert, err := m.repo.GetOpenAlert(monitor.ID)
+	if err != nil {
+		m.logger.Warn("Failed to load monitor alert", zap.String("monitor_id", monitor.ID.String()), zap.Error(err))
+		return
+	}
+
+	state := monitor.AlertState
+	pendingSince := now
+	if alert != nil {
+		state = alert.State
+		pendingSince = alert.PendingAt
+	} else if state == models.AlertStatePending || state == models.AlertStateFiring {
+		state = models.AlertStateOK
+	}
+
+	pendingFor, _ := time.ParseDuration(monitor.PendingFor)
+	next := transition(state, len(names) > 0, pendingSince, pendingFor, now)
+
+	if next != monitor.AlertState {
+		monitor.AlertState = next
+		monitor.AlertSince = &now
+	}
+
+	switch next {
+	case models.AlertStatePending, models.AlertStateFiring:
+		if alert == nil {
+			alert = &models.MonitorAlert{
+				MonitorID: monitor.ID,
+				State:     models.AlertStatePending,
+				PendingAt: now,
+			}
+		}
+		alert.Reason = reason
+		alert.Breaches = names
+
+		notify := false
+		if next == models.AlertStateFiring {
+			if alert.State != models.AlertStateFiring {
+				alert.State = models.AlertStateFiring
+				alert.FiredAt = &now
+				notify = true
+			} else if repeat, _ := time.ParseDuration(monitor.RepeatInterval); repeat > 0 &&
+				alert.LastNotifiedAt != nil && now.Sub(*alert.LastNotifiedAt) >= repeat {
+				notify = true
+			}
+		}
+		if notify {
+			alert.LastNotifiedAt = &now
+			alert.Notifications++
+		}
+
+		if err := m.saveAlert(alert); err != nil {
+			m.logger.Warn("Failed to save monitor alert", zap.String("monitor_id", monitor.ID.String()), zap.Error(err))
+			return
+		}
+		if notify {
+			m.emit(monitor, alert, "monitor.alert_firing", fmt.Sprintf("Monitor %q is firing: %s", monitor.Name, reason))
+		}
+
+	case models.AlertStateOK, models.AlertStateResolved:
+		if alert == nil {
+			return
+		}
+		fired := alert.State == models.AlertStateFiring
+		alert.State = models.AlertStateResolved
+		alert.ResolvedAt = &now
+		if fired {
+			alert.LastNotifiedAt = &now
+			alert.Notifications++
+		}
+		if err := m.repo.UpdateAlert(alert); err != nil {
+			m.logger.Warn("Failed to resolve monitor alert", zap.String("monitor_id", monitor.ID.String()), zap.Error(err))
+			return
+		}
+		if fired {
+			m.emit(monitor, alert, "monitor.alert_resolved", fmt.Sprintf("Monitor %q is resolved", monitor.Name))
+		}
+	}
+}
+
+func (m *Manager) saveAlert(alert *models.MonitorAlert) error {
+	if alert.CreatedAt.IsZero() {
+		return m.repo.CreateAlert(alert)
+	}
+	return m.repo.UpdateAlert(alert)
+}
+
+// emit records an activity event for an alert and sends the monitor's notification
+func (m *Manager) emit(monitor *models.Monitor, alert *models.MonitorAlert, eventType, description string) {
+	workspaceID := monitor.WorkspaceID
+
+	event := &models.ActivityEvent{
+		ActorName:    "TestMesh",
+		EventType:    eventType,
+		ResourceType: "monitor",
+		ResourceID:   monitor.ID,
+		ResourceName: monitor.Name,
+		Description:  description,
+		Metadata: models.JSONMap{
+			"alert_id": alert.ID.String(),
+			"state":    string(alert.State),
+			"breaches": []string(alert.Breaches),
+		},
+		WorkspaceID: &workspaceID,
+	}
+	if err := m.activityRepo.CreateActivityEvent(event); err != nil {
+		m.logger.Warn("Failed to record monitor activity", zap.Error(err))
+	}
+
+	m.logger.Info("Monitor alert changed",
+		zap.String("event", eventType),
+		zap.String("monitor_id", monitor.ID.String()),
+		zap.String("alert_id", alert.ID.String()))
+
+	if monitor.NotifyWebhookURL != "" {
+		go m.notify(monitor.NotifyWebhookURL, monitor, alert, description)
+	}
+}
+
+// notify posts an alert to a Slack-compatible incoming webhook. The dedup key
+// is the same for every notification of an alert, so receivers that
+// deduplicate, such as incident tools, group them.
+func (m *Manager) notify(url string, monitor *models.Monitor, alert *models.MonitorAlert, text string) {
+	icon := ":rotating_light:"
+	if alert.State == models.AlertStateResolved {
+		icon = ":white_check_mark:"
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"text":       icon + " " + text,
+		"dedup_key":  "testmesh-monitor-" + monitor.ID.String() + "-" + alert.ID.String(),
+		"monitor_id": monitor.ID.String(),
+		"monitor":    monitor.Name,
+		"alert_id":   alert.ID.String(),
+		"state":      string(alert.State),
+		"reason":     alert.Reason,
+		"breaches":   []string(alert.Breaches),
+	})
+	resp, err := m.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		m.logger.Warn("Failed to send monitor notification", zap.Error(err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		m.logger.Warn("Monitor notification was rejected", zap.Int("status", resp.StatusCode))
+	}
+}
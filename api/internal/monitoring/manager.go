@@ -0,0 +1,250 @@
+// Package monitoring runs flows as synthetic checks of production services.
+// A monitor owns a schedule that fires its flow on a short interval; after
+// every check the monitor's SLOs are evaluated over their rolling windows and
+// its alert moves through ok, pending, firing and resolved, notifying on the
+// transitions to firing and resolved.
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/scheduler"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"go.uber.org/zap"
+)
+
+// MinInterval is the shortest interval between checks of a monitor
+const MinInterval = 10 * time.Second
+
+// DefaultLocation is the location of an API server without TESTMESH_LOCATION
+const DefaultLocation = "local"
+
+// statusLocationChecks is how many recent checks the locations of a monitor
+// on the status page are read from
+const statusLocationChecks = 20
+
+// Manager creates monitors, records their checks and evaluates their alerts
+type Manager struct {
+	repo         *repository.MonitorRepository
+	scheduleRepo *repository.ScheduleRepository
+	scheduler    *scheduler.Scheduler
+	activityRepo *repository.CollaborationRepository
+	location     string
+	client       *http.Client
+	logger       *zap.Logger
+}
+
+// NewManager creates a monitor manager. Checks run by this server are
+// recorded at location. Scheduled checks run on the replica holding the
+// scheduler lease, so their location is the location of that replica.
+func NewManager(
+	repo *repository.MonitorRepository,
+	scheduleRepo *repository.ScheduleRepository,
+	sched *scheduler.Scheduler,
+	activityRepo *repository.CollaborationRepository,
+	location string,
+	logger *zap.Logger,
+) *Manager {
+	if location == "" {
+		location = DefaultLocation
+	}
+	return &Manager{
+		repo:         repo,
+		scheduleRepo: scheduleRepo,
+		scheduler:    sched,
+		activityRepo: activityRepo,
+		location:     location,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		logger:       logger,
+	}
+}
+
+// Validate applies defaults to a monitor and validates it
+func (m *Manager) Validate(monitor *models.Monitor) error {
+	interval, err := time.ParseDuration(monitor.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval %q", monitor.Interval)
+	}
+	if interval < MinInterval {
+		return fmt.Errorf("interval must be at least %s", MinInterval)
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"pending_for", monitor.PendingFor},
+		{"repeat_interval", monitor.RepeatInterval},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(field.value); err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q", field.name, field.value)
+		}
+	}
+
+	names := make(map[string]bool, len(monitor.SLOs))
+	for i := range monitor.SLOs {
+		slo := &monitor.SLOs[i]
+		if err := validateSLO(slo); err != nil {
+			return fmt.Errorf("slo %d: %w", i+1, err)
+		}
+		if names[slo.Name] {
+			return fmt.Errorf("duplicate slo name %q", slo.Name)
+		}
+		names[slo.Name] = true
+	}
+	if monitor.SLOs == nil {
+		monitor.SLOs = []models.SLO{}
+	}
+	return nil
+}
+
+// Create validates a monitor and creates it with the schedule that fires its checks
+func (m *Manager) Create(monitor *models.Monitor) error {
+	if err := m.Validate(monitor); err != nil {
+		return err
+	}
+
+	schedule := &models.Schedule{
+		Status:        models.ScheduleStatusActive,
+		Timezone:      "UTC",
+		TargetType:    models.ScheduleTargetFlow,
+		Concurrency:   1,
+		RetryDelay:    "1m",
+		MisfirePolicy: models.MisfireSkip,
+		Tags:          models.StringArray{"monitor"},
+	}
+	applySchedule(schedule, monitor)
+	if err := scheduler.ValidateSchedule(schedule); err != nil {
+		return err
+	}
+	if err := m.scheduler.AddSchedule(schedule); err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	monitor.ScheduleID = schedule.ID
+	monitor.Status = models.MonitorStatusActive
+	monitor.AlertState = models.AlertStateOK
+	if err := m.repo.Create(monitor); err != nil {
+		m.scheduler.RemoveSchedule(schedule.ID)
+		return err
+	}
+
+	m.logger.Info("Monitor created",
+		zap.String("monitor_id", monitor.ID.String()),
+		zap.String("name", monitor.Name),
+		zap.String("interval", monitor.Interval))
+	return nil
+}
+
+// Update validates and saves a monitor and updates its schedule
+func (m *Manager) Update(monitor *models.Monitor) error {
+	if err := m.Validate(monitor); err != nil {
+		return err
+	}
+
+	schedule, err := m.scheduleRepo.Get(monitor.ScheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedule: %w", err)
+	}
+	applySchedule(schedule, monitor)
+	if err := m.scheduler.UpdateSchedule(schedule); err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return m.repo.Update(monitor)
+}
+
+// Delete deletes a monitor with its schedule, checks and alerts
+func (m *Manager) Delete(monitor *models.Monitor) error {
+	if err := m.repo.Delete(monitor.ID); err != nil {
+		return err
+	}
+	return m.scheduler.RemoveSchedule(monitor.ScheduleID)
+}
+
+// Pause stops the checks of a monitor
+func (m *Manager) Pause(monitor *models.Monitor) error {
+	if err := m.scheduler.PauseSchedule(monitor.ScheduleID); err != nil {
+		return err
+	}
+	monitor.Status = models.MonitorStatusPaused
+	return m.repo.SetStatus(monitor.ID, monitor.Status)
+}
+
+// Resume restarts the checks of a paused monitor
+func (m *Manager) Resume(monitor *models.Monitor) error {
+	if err := m.scheduler.ResumeSchedule(monitor.ScheduleID); err != nil {
+		return err
+	}
+	monitor.Status = models.MonitorStatusActive
+	return m.repo.SetStatus(monitor.ID, monitor.Status)
+}
+
+// Check runs a check of a monitor now
+func (m *Manager) Check(monitor *models.Monitor) (*models.ScheduleRun, error) {
+	return m.scheduler.TriggerSchedule(monitor.ScheduleID)
+}
+
+// ObserveRun records a finished schedule run as a check when the schedule
+// belongs to a monitor, and evaluates the monitor's alert. It is the run
+// observer of the scheduler.
+func (m *Manager) ObserveRun(schedule *models.Schedule, run *models.ScheduleRun) {
+	monitor, err := m.repo.GetBySchedule(schedule.ID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	runID := run.ID
+	check := &models.MonitorCheck{
+		MonitorID:     monitor.ID,
+		ScheduleRunID: &runID,
+		Location:      m.location,
+		Result:        run.Result,
+		Error:         run.Error,
+		DurationMs:    run.Duration,
+		CheckedAt:     now,
+	}
+	// The check is as long as its flow; the run also includes reruns and retry delays
+	if len(run.FlowResults) == 1 {
+		check.ExecutionID = run.FlowResults[0].ExecutionID
+		check.DurationMs = run.FlowResults[0].DurationMs
+		if check.Error == "" {
+			check.Error = run.FlowResults[0].Error
+		}
+	}
+	if err := m.repo.CreateCheck(check); err != nil {
+		m.logger.Error("Failed to record monitor check",
+			zap.String("monitor_id", monitor.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	monitor.LastCheckAt = &now
+	monitor.LastCheckResult = check.Result
+
+	statuses := m.Evaluate(monitor, now)
+	m.evaluateAlert(monitor, statuses, check, now)
+
+	if err := m.repo.SetState(monitor); err != nil {
+		m.logger.Error("Failed to record monitor state",
+			zap.String("monitor_id", monitor.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// applySchedule copies the settings of a monitor to the schedule that fires its checks
+func applySchedule(schedule *models.Schedule, monitor *models.Monitor) {
+	workspaceID := monitor.WorkspaceID
+	flowID := monitor.FlowID
+
+	schedule.Name = "Monitor: " + monitor.Name
+	schedule.Description = "Checks of the " + monitor.Name + " monitor"
+	schedule.CronExpr = "@every " + monitor.Interval
+	schedule.WorkspaceID = &workspaceID
+	schedule.FlowID = &flowID
+	schedule.Environment = monitor.Environment
+}
@@ -0,0 +1,164 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWindow     = "24h"
+	defaultPercentile = 95
+)
+
+// validateSLO applies defaults to an SLO and validates it
+func validateSLO(slo *models.SLO) error {
+	if slo.Window == "" {
+		slo.Window = defaultWindow
+	}
+	if _, err := ParseWindow(slo.Window); err != nil {
+		return err
+	}
+
+	switch slo.Type {
+	case models.SLOTypeAvailability:
+		if slo.Objective <= 0 || slo.Objective > 100 {
+			return fmt.Errorf("availability objective must be a percentage above 0 and up to 100")
+		}
+		if slo.Name == "" {
+			slo.Name = "availability " + slo.Window
+		}
+	case models.SLOTypeLatency:
+		if slo.Objective <= 0 {
+			return fmt.Errorf("latency objective must be a positive number of milliseconds")
+		}
+		if slo.Percentile == 0 {
+			slo.Percentile = defaultPercentile
+		}
+		if slo.Percentile <= 0 || slo.Percentile >= 100 {
+			return fmt.Errorf("latency percentile must be above 0 and below 100")
+		}
+		if slo.Name == "" {
+			slo.Name = fmt.Sprintf("latency p%g", slo.Percentile)
+			if slo.StepID != "" {
+				slo.Name += " " + slo.StepID
+			}
+			slo.Name += " " + slo.Window
+		}
+	default:
+		return fmt.Errorf("invalid type %q (expected availability or latency)", slo.Type)
+	}
+	return nil
+}
+
+// ParseWindow parses a rolling window. Besides Go durations such as "1h" or
+// "90m", whole days are written as "7d".
+func ParseWindow(window string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(window)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+	return d, nil
+}
+
+// Evaluate evaluates the SLOs of a monitor over their windows ending at now.
+// An SLO without checks in its window is met.
+func (m *Manager) Evaluate(monitor *models.Monitor, now time.Time) []models.SLOStatus {
+	statuses := make([]models.SLOStatus, 0, len(monitor.SLOs))
+	for _, slo := range monitor.SLOs {
+		status := models.SLOStatus{SLO: slo, Met: true}
+
+		window, err := ParseWindow(slo.Window)
+		if err != nil {
+			statuses = append(statuses, status)
+			continue
+		}
+		since := now.Add(-window)
+
+		switch slo.Type {
+		case models.SLOTypeAvailability:
+			successful, total, err := m.repo.Availability(monitor.ID, since)
+			if err != nil {
+				m.logger.Warn("Failed to evaluate availability SLO",
+					zap.String("monitor_id", monitor.ID.String()), zap.Error(err))
+				break
+			}
+			status.Samples = total
+			if total == 0 {
+				break
+			}
+			actual := 100 * float64(successful) / float64(total)
+			budget := errorBudget(slo.Objective, successful, total)
+			status.Actual = &actual
+			status.ErrorBudget = &budget
+			status.Met = actual >= slo.Objective
+
+		case models.SLOTypeLatency:
+			value, samples, err := m.repo.LatencyPercentile(monitor.ID, slo.StepID, slo.Percentile, since)
+			if err != nil {
+				m.logger.Warn("Failed to evaluate latency SLO",
+					zap.String("monitor_id", monitor.ID.String()), zap.Error(err))
+				break
+			}
+			status.Samples = samples
+			if samples == 0 {
+				break
+			}
+			status.Actual = &value
+			status.Met = value <= slo.Objective
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Uptime returns the percentage of successful checks of a monitor in a
+// window, or nil without checks
+func (m *Manager) Uptime(monitor *models.Monitor, window time.Duration) *float64 {
+	successful, total, err := m.repo.Availability(monitor.ID, time.Now().Add(-window))
+	if err != nil || total == 0 {
+		return nil
+	}
+	uptime := 100 * float64(successful) / float64(total)
+	return &uptime
+}
+
+// errorBudget returns the share of the failures allowed by an availability
+// objective that is left. It is negative once the budget is overspent.
+func errorBudget(objective float64, successful, total int64) float64 {
+	allowed := (100 - objective) / 100 * float64(total)
+	failed := float64(total - successful)
+	if allowed == 0 {
+		if failed == 0 {
+			return 1
+		}
+		return 0
+	}
+	return (allowed - failed) / allowed
+}
+
+// describeBreach describes an SLO that is not met
+func describeBreach(status models.SLOStatus) string {
+	if status.Actual == nil {
+		return status.Name
+	}
+	switch status.Type {
+	case models.SLOTypeAvailability:
+		return fmt.Sprintf("%s: %.2f%% < %.2f%%", status.Name, *status.Actual, status.Objective)
+	default:
+		return fmt.Sprintf("%s: %.0fms > %.0fms", status.Name, *status.Actual, status.Objective)
+	}
+}
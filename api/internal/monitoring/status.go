@@ -0,0 +1,155 @@
+package monitoring
+
+import (
+	"sort"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Status page states of a monitor
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusDown        = "down"
+	StatusPaused      = "paused"
+	StatusOutage      = "outage" // Overall status when a monitor is down
+)
+
+// incidentWindow is how far back the status page lists incidents
+const incidentWindow = 7 * 24 * time.Hour
+
+// uptimeWindows are the windows of the uptime shown on the status page
+var uptimeWindows = []string{"24h", "7d", "30d"}
+
+// StatusPage is the public status of the monitors of a workspace. It leaves
+// out flows, environments, errors and notification settings.
+type StatusPage struct {
+	Name      string          `json:"name"`
+	Status    string          `json:"status"` // "operational", "degraded" or "outage"
+	Monitors  []MonitorStatus `json:"monitors"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// MonitorStatus is the public status of a monitor
+type MonitorStatus struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Status      string              `json:"status"` // "operational", "degraded", "down" or "paused"
+	Locations   []string            `json:"locations"`
+	LastCheckAt *time.Time          `json:"last_check_at,omitempty"`
+	Uptime      map[string]*float64 `json:"uptime"` // Percentage of successful checks per window, null without checks
+	SLOs        []models.SLOStatus  `json:"slos"`
+	Incidents   []Incident          `json:"incidents"`
+}
+
+// Incident is a fired alert shown on the status page
+type Incident struct {
+	Status     string     `json:"status"` // "ongoing" or "resolved"
+	Breaches   []string   `json:"breaches"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// StatusPage builds the status page of the public monitors of a workspace
+func (m *Manager) StatusPage(workspace *models.Workspace) (*StatusPage, error) {
+	monitors, err := m.repo.List(workspace.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	page := &StatusPage{
+		Name:      workspace.Name,
+		Status:    StatusOperational,
+		Monitors:  make([]MonitorStatus, 0, len(monitors)),
+		UpdatedAt: now,
+	}
+
+	for i := range monitors {
+		monitor := &monitors[i]
+		status := MonitorStatus{
+			Name:        monitor.Name,
+			Description: monitor.Description,
+			Status:      publicStatus(monitor),
+			LastCheckAt: monitor.LastCheckAt,
+			Uptime:      make(map[string]*float64, len(uptimeWindows)),
+			SLOs:        m.Evaluate(monitor, now),
+			Incidents:   []Incident{},
+		}
+		if status.Locations, err = m.checkLocations(monitor); err != nil {
+			return nil, err
+		}
+		for _, window := range uptimeWindows {
+			d, _ := ParseWindow(window)
+			status.Uptime[window] = m.Uptime(monitor, d)
+		}
+
+		alerts, err := m.repo.ListAlerts(monitor.ID, now.Add(-incidentWindow), 20)
+		if err != nil {
+			return nil, err
+		}
+		for _, alert := range alerts {
+			incident := Incident{
+				Status:     "ongoing",
+				Breaches:   alert.Breaches,
+				StartedAt:  *alert.FiredAt,
+				ResolvedAt: alert.ResolvedAt,
+			}
+			if alert.ResolvedAt != nil {
+				incident.Status = "resolved"
+			}
+			status.Incidents = append(status.Incidents, incident)
+		}
+
+		switch status.Status {
+		case StatusDown:
+			page.Status = StatusOutage
+		case StatusDegraded:
+			if page.Status == StatusOperational {
+				page.Status = StatusDegraded
+			}
+		}
+		page.Monitors = append(page.Monitors, status)
+	}
+
+	return page, nil
+}
+
+// publicStatus derives the status page state of a monitor from its alert. A
+// firing monitor is down while its checks fail, and degraded when it only
+// misses an SLO such as latency.
+func publicStatus(monitor *models.Monitor) string {
+	if monitor.Status == models.MonitorStatusPaused {
+		return StatusPaused
+	}
+	switch monitor.AlertState {
+	case models.AlertStateFiring:
+		if monitor.LastCheckResult == "failure" {
+			return StatusDown
+		}
+		return StatusDegraded
+	case models.AlertStatePending:
+		return StatusDegraded
+	default:
+		return StatusOperational
+	}
+}
+
+// checkLocations returns the locations the recent checks of a monitor ran at
+func (m *Manager) checkLocations(monitor *models.Monitor) ([]string, error) {
+	checks, err := m.repo.ListChecks(monitor.ID, statusLocationChecks)
+	if err != nil {
+		return nil, err
+	}
+	locations := []string{}
+	seen := make(map[string]bool)
+	for _, check := range checks {
+		if check.Location != "" && !seen[check.Location] {
+			seen[check.Location] = true
+			locations = append(locations, check.Location)
+		}
+	}
+	sort.Strings(locations)
+	return locations, nil
+}
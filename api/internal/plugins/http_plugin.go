@@ -29,6 +29,7 @@ type HTTPPluginRunner struct {
 	client  *http.Client
 	running bool
 	baseURL string
+	actions []PluginActionDef // From the plugin's /info endpoint
 }
 
 // NewHTTPPluginRunner creates a new HTTP plugin runner
@@ -108,6 +109,14 @@ func (r *HTTPPluginRunner) Start(ctx context.Context) error {
 		return fmt.Errorf("plugin failed to become healthy: %w", err)
 	}
 
+	// Action descriptions are optional; plugins without /info still run
+	if err := r.fetchInfo(ctx); err != nil {
+		r.logger.Warn("Failed to read plugin info",
+			zap.String("plugin_id", r.manifest.ID),
+			zap.Error(err),
+		)
+	}
+
 	r.logger.Info("Plugin started successfully",
 		zap.String("plugin_id", r.manifest.ID),
 		zap.Int("port", port),
@@ -116,6 +125,37 @@ func (r *HTTPPluginRunner) Start(ctx context.Context) error {
 	return nil
 }
 
+// fetchInfo reads the actions the plugin describes from its /info endpoint
+func (r *HTTPPluginRunner) fetchInfo(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", r.baseURL+"/info", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("info endpoint returned %d", resp.StatusCode)
+	}
+
+	var info PluginInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to parse info: %w", err)
+	}
+	r.actions = info.Actions
+	return nil
+}
+
+// Actions returns the actions the plugin described when it started
+func (r *HTTPPluginRunner) Actions() []PluginActionDef {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actions
+}
+
 // waitForHealthy polls the health endpoint until the plugin is ready
 func (r *HTTPPluginRunner) waitForHealthy(ctx context.Context) error {
 	healthURL := r.baseURL + "/health"
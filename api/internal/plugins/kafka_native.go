@@ -45,6 +45,63 @@ func (p *KafkaNativePlugin) Execute(ctx context.Context, config map[string]inter
 	}
 }
 
+// Actions describes the Kafka actions
+func (p *KafkaNativePlugin) Actions() []PluginActionDef {
+	connection := func(properties map[string]interface{}) map[string]interface{} {
+		properties["brokers"] = property("string", "Comma-separated broker addresses (default: localhost:9092)")
+		properties["connectionTimeout"] = property("number", "Dial timeout in milliseconds")
+		return properties
+	}
+	return []PluginActionDef{
+		{
+			ID:          "kafka.produce",
+			Name:        "Produce",
+			Description: "Produce one message, or a list of messages, to a topic",
+			Schema: configSchema([]string{"topic"}, connection(map[string]interface{}{
+				"topic":    property("string", "Topic"),
+				"key":      property("string", "Message key"),
+				"value":    property("", "Message value"),
+				"messages": property("array", "Messages with a key and value, instead of key and value"),
+			})),
+		},
+		{
+			ID:          "kafka.consume",
+			Name:        "Consume",
+			Description: "Consume messages from the first partition of a topic",
+			Schema: configSchema([]string{"topic"}, connection(map[string]interface{}{
+				"topic":         property("string", "Topic"),
+				"fromBeginning": property("boolean", "Read the topic from the start"),
+				"maxMessages":   property("number", "Most messages to read (default: 10)"),
+				"timeout":       property("number", "How long to wait in milliseconds (default: 5000)"),
+			})),
+		},
+		{
+			ID:          "kafka.admin.topics",
+			Name:        "List topics",
+			Description: "List the topics of the cluster",
+			Schema:      configSchema(nil, connection(map[string]interface{}{})),
+		},
+		{
+			ID:          "kafka.admin.createTopic",
+			Name:        "Create topic",
+			Description: "Create a topic",
+			Schema: configSchema([]string{"topic"}, connection(map[string]interface{}{
+				"topic":             property("string", "Topic"),
+				"partitions":        property("number", "Partitions (default: 1)"),
+				"replicationFactor": property("number", "Replication factor (default: 1)"),
+			})),
+		},
+		{
+			ID:          "kafka.admin.deleteTopic",
+			Name:        "Delete topic",
+			Description: "Delete a topic",
+			Schema: configSchema([]string{"topic"}, connection(map[string]interface{}{
+				"topic": property("string", "Topic"),
+			})),
+		},
+	}
+}
+
 func (p *KafkaNativePlugin) getBrokers(config map[string]interface{}) []string {
 	brokers := "localhost:9092"
 	if b, ok := config["brokers"].(string); ok {
@@ -55,6 +55,109 @@ func (p *PostgreSQLNativePlugin) Execute(ctx context.Context, config map[string]
 	}
 }
 
+// Actions describes the PostgreSQL actions
+func (p *PostgreSQLNativePlugin) Actions() []PluginActionDef {
+	connection := func(properties map[string]interface{}) map[string]interface{} {
+		properties["connectionString"] = property("string", "Connection string, instead of host, port, database, user and password")
+		properties["host"] = property("string", "Host (default: localhost)")
+		properties["port"] = property("number", "Port (default: 5432)")
+		properties["database"] = property("string", "Database (default: postgres)")
+		properties["user"] = property("string", "User (default: postgres)")
+		properties["password"] = property("string", "Password")
+		properties["sslmode"] = property("string", "SSL mode (default: disable)")
+		return properties
+	}
+	returning := property("array", "Columns to return (default: all)")
+	return []PluginActionDef{
+		{
+			ID:          "postgresql.query",
+			Name:        "Query",
+			Description: "Run a query and return its rows",
+			Schema: configSchema([]string{"query"}, connection(map[string]interface{}{
+				"query":  property("string", "SQL query"),
+				"params": property("array", "Query parameters"),
+			})),
+		},
+		{
+			ID:          "postgresql.insert",
+			Name:        "Insert",
+			Description: "Insert a row",
+			Schema: configSchema([]string{"table", "data"}, connection(map[string]interface{}{
+				"table":     property("string", "Table"),
+				"data":      property("object", "Column values"),
+				"returning": returning,
+			})),
+		},
+		{
+			ID:          "postgresql.update",
+			Name:        "Update",
+			Description: "Update the rows matching a WHERE clause",
+			Schema: configSchema([]string{"table", "data", "where"}, connection(map[string]interface{}{
+				"table":       property("string", "Table"),
+				"data":        property("object", "Column values"),
+				"where":       property("string", "WHERE clause"),
+				"whereParams": property("array", "Parameters of the WHERE clause"),
+				"returning":   returning,
+			})),
+		},
+		{
+			ID:          "postgresql.delete",
+			Name:        "Delete",
+			Description: "Delete the rows matching a WHERE clause",
+			Schema: configSchema([]string{"table", "where"}, connection(map[string]interface{}{
+				"table":     property("string", "Table"),
+				"where":     property("string", "WHERE clause"),
+				"params":    property("array", "Parameters of the WHERE clause"),
+				"returning": returning,
+			})),
+		},
+		{
+			ID:          "postgresql.assert",
+			Name:        "Assert",
+			Description: "Assert on the first row of a query",
+			Schema: configSchema([]string{"query"}, connection(map[string]interface{}{
+				"query":      property("string", "SQL query"),
+				"params":     property("array", "Query parameters"),
+				"assertions": property("array", "Assertions with a field, operator and value"),
+			})),
+		},
+		{
+			ID:          "postgresql.execute",
+			Name:        "Execute",
+			Description: "Execute statements",
+			Schema: configSchema(nil, connection(map[string]interface{}{
+				"statement":  property("string", "Statement"),
+				"statements": property("array", "Statements, instead of statement"),
+			})),
+		},
+		{
+			ID:          "postgresql.transaction",
+			Name:        "Transaction",
+			Description: "Execute statements in a transaction",
+			Schema: configSchema([]string{"statements"}, connection(map[string]interface{}{
+				"statements": property("array", "Statements, or objects with a query and params"),
+			})),
+		},
+		{
+			ID:          "postgresql.tables",
+			Name:        "List tables",
+			Description: "List the tables of a schema",
+			Schema: configSchema(nil, connection(map[string]interface{}{
+				"schema": property("string", "Schema (default: public)"),
+			})),
+		},
+		{
+			ID:          "postgresql.columns",
+			Name:        "List columns",
+			Description: "List the columns of a table",
+			Schema: configSchema([]string{"table"}, connection(map[string]interface{}{
+				"table":  property("string", "Table"),
+				"schema": property("string", "Schema (default: public)"),
+			})),
+		},
+	}
+}
+
 func (p *PostgreSQLNativePlugin) getConnectionString(config map[string]interface{}) string {
 	if connStr, ok := config["connectionString"].(string); ok {
 		return connStr
@@ -85,6 +85,33 @@ type PluginMetrics struct {
 	Custom map[string]interface{} `json:"custom,omitempty"`
 }
 
+// PluginReportRequest is sent to a reporter plugin's /report endpoint
+type PluginReportRequest struct {
+	// Format is the report format being generated (matches the plugin's format)
+	Format string `json:"format"`
+
+	// Run is the test run to render, in the reporting export model
+	Run interface{} `json:"run"`
+
+	// Config contains the plugin configuration from the manifest
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// PluginReportResponse is returned from a reporter plugin's /report endpoint
+type PluginReportResponse struct {
+	// Success indicates whether the report was generated
+	Success bool `json:"success"`
+
+	// Content is the generated report, base64-encoded
+	Content []byte `json:"content"`
+
+	// Error contains error details if Success is false
+	Error *PluginError `json:"error,omitempty"`
+
+	// Logs contains any log messages from the plugin
+	Logs []PluginLog `json:"logs,omitempty"`
+}
+
 // PluginHealthResponse is returned from a plugin's /health endpoint
 type PluginHealthResponse struct {
 	Status  string `json:"status"` // healthy, unhealthy, starting
@@ -109,6 +136,28 @@ type PluginActionDef struct {
 	Schema      map[string]interface{} `json:"schema"` // JSON Schema for config validation
 }
 
+// configSchema builds the JSON Schema of an action config for native plugins
+func configSchema(required []string, properties map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		list := make([]interface{}, len(required))
+		for i, key := range required {
+			list[i] = key
+		}
+		schema["required"] = list
+	}
+	return schema
+}
+
+// property is the schema of a config property; an empty type allows any value
+func property(jsonType, description string) map[string]interface{} {
+	prop := map[string]interface{}{"description": description}
+	if jsonType != "" {
+		prop["type"] = jsonType
+	}
+	return prop
+}
+
 // MarshalJSON for PluginExecuteRequest
 func (r *PluginExecuteRequest) ToJSON() ([]byte, error) {
 	return json.Marshal(r)
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -54,11 +55,18 @@ type ActionPlugin interface {
 	Execute(ctx context.Context, config map[string]interface{}) (map[string]interface{}, error)
 }
 
+// ActionDescriber is implemented by action plugins that describe the actions
+// they provide, with a JSON Schema of each action's config
+type ActionDescriber interface {
+	Actions() []PluginActionDef
+}
+
 // Registry manages plugins
 type Registry struct {
 	mu        sync.RWMutex
 	plugins   map[string]*Plugin
 	actions   map[string]ActionPlugin
+	reporters map[string]ReporterPlugin
 	pluginDir string
 	logger    *zap.Logger
 }
@@ -68,6 +76,7 @@ func NewRegistry(pluginDir string, logger *zap.Logger) *Registry {
 	return &Registry{
 		plugins:   make(map[string]*Plugin),
 		actions:   make(map[string]ActionPlugin),
+		reporters: make(map[string]ReporterPlugin),
 		pluginDir: pluginDir,
 		logger:    logger,
 	}
@@ -163,6 +172,11 @@ func (r *Registry) Load(id string) error {
 			plugin.Error = err.Error()
 			return err
 		}
+	case PluginTypeReporter:
+		if err := r.loadReporterPlugin(plugin); err != nil {
+			plugin.Error = err.Error()
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported plugin type: %s", plugin.Manifest.Type)
 	}
@@ -195,6 +209,28 @@ func (r *Registry) loadActionPlugin(plugin *Plugin) error {
 	return nil
 }
 
+// loadReporterPlugin loads a report generator plugin using HTTP protocol
+func (r *Registry) loadReporterPlugin(plugin *Plugin) error {
+	runner := NewHTTPReporterRunner(plugin.Manifest, plugin.Path, r.logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := runner.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	// Register the report format
+	r.reporters[runner.Format()] = runner
+
+	r.logger.Info("Loaded reporter plugin",
+		zap.String("id", plugin.Manifest.ID),
+		zap.String("format", runner.Format()),
+		zap.String("entry_point", plugin.Manifest.EntryPoint))
+
+	return nil
+}
+
 // Unload unloads a specific plugin
 func (r *Registry) Unload(id string) error {
 	r.mu.Lock()
@@ -214,6 +250,16 @@ func (r *Registry) Unload(id string) error {
 		delete(r.actions, plugin.Manifest.ID)
 	}
 
+	// Remove the report format and stop the process if it's a reporter plugin
+	if plugin.Manifest.Type == PluginTypeReporter {
+		for format, reporter := range r.reporters {
+			if runner, ok := reporter.(*HTTPReporterRunner); ok && runner.manifest.ID == plugin.Manifest.ID {
+				runner.Stop()
+				delete(r.reporters, format)
+			}
+		}
+	}
+
 	plugin.Loaded = false
 	return nil
 }
@@ -298,6 +344,58 @@ func (r *Registry) RegisterAction(name string, plugin ActionPlugin) {
 	r.logger.Info("Registered action plugin", zap.String("name", name))
 }
 
+// ActionDefs returns the actions of every registered action plugin, sorted by
+// ID, and the names of plugins that do not describe their actions. Those
+// accept any action in their namespace, like "name.anything".
+func (r *Registry) ActionDefs() ([]PluginActionDef, []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var defs []PluginActionDef
+	var undescribed []string
+	for name, action := range r.actions {
+		describer, ok := action.(ActionDescriber)
+		if !ok || len(describer.Actions()) == 0 {
+			undescribed = append(undescribed, name)
+			continue
+		}
+		defs = append(defs, describer.Actions()...)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].ID < defs[j].ID })
+	sort.Strings(undescribed)
+	return defs, undescribed
+}
+
+// GetReporter returns the reporter plugin generating a report format
+func (r *Registry) GetReporter(format string) (ReporterPlugin, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reporter, ok := r.reporters[format]
+	return reporter, ok
+}
+
+// ListReporters returns all registered reporter plugins
+func (r *Registry) ListReporters() []ReporterPlugin {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reporters := make([]ReporterPlugin, 0, len(r.reporters))
+	for _, reporter := range r.reporters {
+		reporters = append(reporters, reporter)
+	}
+	return reporters
+}
+
+// RegisterReporter registers a custom reporter plugin
+func (r *Registry) RegisterReporter(plugin ReporterPlugin) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reporters[plugin.Format()] = plugin
+	r.logger.Info("Registered reporter plugin", zap.String("format", plugin.Format()))
+}
+
 // Install installs a plugin from a source (URL or local path)
 func (r *Registry) Install(source string) (*Plugin, error) {
 	r.mu.Lock()
@@ -0,0 +1,112 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// ReporterPlugin interface for report generators. A reporter renders a test
+// run into one file of its format.
+type ReporterPlugin interface {
+	Format() string
+	Extension() string
+	ContentType() string
+	Report(ctx context.Context, run interface{}) ([]byte, error)
+}
+
+// HTTPReporterRunner runs a reporter plugin over the HTTP plugin protocol.
+// The manifest config may set "format" (defaults to the plugin ID),
+// "extension" and "content_type" of the generated file.
+type HTTPReporterRunner struct {
+	*HTTPPluginRunner
+}
+
+// NewHTTPReporterRunner creates a new HTTP reporter runner
+func NewHTTPReporterRunner(manifest *PluginManifest, pluginPath string, logger *zap.Logger) *HTTPReporterRunner {
+	return &HTTPReporterRunner{
+		HTTPPluginRunner: NewHTTPPluginRunner(manifest, pluginPath, logger),
+	}
+}
+
+// Format returns the report format the plugin generates
+func (r *HTTPReporterRunner) Format() string {
+	return r.configString("format", r.manifest.ID)
+}
+
+// Extension returns the file extension of generated reports
+func (r *HTTPReporterRunner) Extension() string {
+	return r.configString("extension", "txt")
+}
+
+// ContentType returns the content type of generated reports
+func (r *HTTPReporterRunner) ContentType() string {
+	return r.configString("content_type", "application/octet-stream")
+}
+
+// Report sends a test run to the plugin's /report endpoint
+func (r *HTTPReporterRunner) Report(ctx context.Context, run interface{}) ([]byte, error) {
+	r.mu.RLock()
+	if !r.running {
+		r.mu.RUnlock()
+		return nil, fmt.Errorf("plugin is not running")
+	}
+	baseURL := r.baseURL
+	r.mu.RUnlock()
+
+	reqBody, err := json.Marshal(&PluginReportRequest{
+		Format: r.Format(),
+		Run:    run,
+		Config: r.manifest.Config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/report", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var pluginResp PluginReportResponse
+	if err := json.Unmarshal(body, &pluginResp); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin response: %w", err)
+	}
+
+	for _, log := range pluginResp.Logs {
+		r.logPluginMessage(log)
+	}
+
+	if !pluginResp.Success {
+		if pluginResp.Error != nil {
+			return nil, pluginResp.Error
+		}
+		return nil, fmt.Errorf("plugin report failed")
+	}
+
+	return pluginResp.Content, nil
+}
+
+func (r *HTTPReporterRunner) configString(key, fallback string) string {
+	if value, ok := r.manifest.Config[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,362 @@
+// Package quarantine keeps flaky flows and steps from failing runs. Quarantined
+// flows and steps still run and their results are recorded, but their failures
+// do not fail schedules, CI runs or the CLI. Flows and steps are quarantined
+// manually or automatically from their flakiness, and are released after a
+// number of consecutive clean runs.
+package quarantine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/reporting"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultFlakinessThreshold = 0.1
+	DefaultReleaseAfter       = 5
+	MaxRetryOnFailure         = 3
+
+	// minRunsForAuto is the number of runs in the flakiness window needed to quarantine automatically
+	minRunsForAuto = 5
+)
+
+// Actor is who changed a quarantine, shown in activity events
+type Actor struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// system is the actor of automatic quarantine changes
+var system = Actor{Name: "TestMesh"}
+
+// ErrAlreadyQuarantined is returned when the flow or step is already quarantined
+var ErrAlreadyQuarantined = errors.New("already quarantined")
+
+// Manager quarantines and releases flows and steps
+type Manager struct {
+	repo          *repository.QuarantineRepository
+	workspaceRepo *repository.WorkspaceRepository
+	flowRepo      *repository.FlowRepository
+	execRepo      *repository.ExecutionRepository
+	activityRepo  *repository.CollaborationRepository
+	client        *http.Client
+	logger        *zap.Logger
+}
+
+// NewManager creates a quarantine manager
+func NewManager(
+	repo *repository.QuarantineRepository,
+	workspaceRepo *repository.WorkspaceRepository,
+	flowRepo *repository.FlowRepository,
+	execRepo *repository.ExecutionRepository,
+	activityRepo *repository.CollaborationRepository,
+	logger *zap.Logger,
+) *Manager {
+	return &Manager{
+		repo:          repo,
+		workspaceRepo: workspaceRepo,
+		flowRepo:      flowRepo,
+		execRepo:      execRepo,
+		activityRepo:  activityRepo,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		logger:        logger,
+	}
+}
+
+// Policy returns the quarantine policy of a workspace with defaults applied
+func (m *Manager) Policy(workspaceID uuid.UUID) models.QuarantinePolicy {
+	var policy models.QuarantinePolicy
+	if workspace, err := m.workspaceRepo.GetByID(workspaceID); err == nil {
+		policy = workspace.Settings.Quarantine
+	}
+	return withDefaults(policy)
+}
+
+func withDefaults(policy models.QuarantinePolicy) models.QuarantinePolicy {
+	if policy.FlakinessThreshold <= 0 {
+		policy.FlakinessThreshold = DefaultFlakinessThreshold
+	}
+	if policy.ReleaseAfter <= 0 {
+		policy.ReleaseAfter = DefaultReleaseAfter
+	}
+	if policy.RetryOnFailure < 0 {
+		policy.RetryOnFailure = 0
+	}
+	if policy.RetryOnFailure > MaxRetryOnFailure {
+		policy.RetryOnFailure = MaxRetryOnFailure
+	}
+	return policy
+}
+
+// Quarantine quarantines a flow, or one of its steps when stepID is set, on behalf of a user
+func (m *Manager) Quarantine(flow *models.Flow, stepID, reason string, actor Actor) (*models.Quarantine, error) {
+	q := &models.Quarantine{
+		WorkspaceID: flow.WorkspaceID,
+		FlowID:      flow.ID,
+		StepID:      stepID,
+		Status:      models.QuarantineStatusActive,
+		Source:      models.QuarantineSourceManual,
+		Reason:      reason,
+	}
+	if actor.ID != uuid.Nil {
+		q.QuarantinedBy = &actor.ID
+	}
+	if err := m.add(flow, q, actor); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (m *Manager) add(flow *models.Flow, q *models.Quarantine, actor Actor) error {
+	if _, err := m.repo.GetActive(q.FlowID, q.StepID); err == nil {
+		return ErrAlreadyQuarantined
+	}
+	if err := m.repo.Create(q); err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("%s was quarantined", target(flow, q))
+	if q.Reason != "" {
+		description += ": " + q.Reason
+	}
+	m.emit("quarantine.added", flow, q, actor, description)
+	return nil
+}
+
+// Release ends a quarantine on behalf of a user
+func (m *Manager) Release(q *models.Quarantine, reason string, actor Actor) error {
+	released, err := m.repo.Release(q.ID, reason)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return nil
+	}
+	q.Status = models.QuarantineStatusReleased
+	q.ReleaseReason = reason
+
+	flow, err := m.flowRepo.GetByIDUnscoped(q.FlowID)
+	if err != nil {
+		return nil
+	}
+	description := fmt.Sprintf("%s was released from quarantine", target(flow, q))
+	if reason != "" {
+		description += ": " + reason
+	}
+	m.emit("quarantine.released", flow, q, actor, description)
+	return nil
+}
+
+// Review marks a failed execution as quarantined when it failed only in
+// quarantined flows or steps
+func (m *Manager) Review(execution *models.Execution) {
+	execution.Quarantined = false
+	if execution.Status != models.ExecutionStatusFailed {
+		return
+	}
+
+	active, err := m.repo.ListActiveForFlow(execution.FlowID)
+	if err != nil || len(active) == 0 {
+		return
+	}
+
+	steps := make(map[string]bool, len(active))
+	for _, q := range active {
+		if q.StepID == "" {
+			execution.Quarantined = true
+			return
+		}
+		steps[q.StepID] = true
+	}
+
+	results, err := m.execRepo.GetSteps(execution.ID)
+	if err != nil {
+		return
+	}
+	failed := 0
+	for _, step := range results {
+		if step.Status != models.StepStatusFailed {
+			continue
+		}
+		if !steps[step.StepID] {
+			return
+		}
+		failed++
+	}
+	// A failure outside of any step, e.g. an invalid flow, is never quarantined
+	execution.Quarantined = failed > 0
+}
+
+// RecordRun counts the clean runs of a flow's quarantines from the first
+// attempt of a run, and releases those that reached the policy's release threshold.
+// A run that passes only after a rerun is not clean.
+func (m *Manager) RecordRun(workspaceID uuid.UUID, firstAttempt *models.Execution) {
+	active, err := m.repo.ListActiveForFlow(firstAttempt.FlowID)
+	if err != nil || len(active) == 0 {
+		return
+	}
+
+	var stepStatus map[string]models.StepStatus
+	policy := m.Policy(workspaceID)
+
+	for i := range active {
+		q := &active[i]
+
+		var clean bool
+		if q.StepID == "" {
+			clean = firstAttempt.Status == models.ExecutionStatusCompleted
+		} else {
+			if stepStatus == nil {
+				stepStatus = make(map[string]models.StepStatus)
+				steps, _ := m.execRepo.GetSteps(firstAttempt.ID)
+				for _, step := range steps {
+					// A failed attempt of a retried step is not clean
+					if stepStatus[step.StepID] != models.StepStatusFailed {
+						stepStatus[step.StepID] = step.Status
+					}
+				}
+			}
+			status, ran := stepStatus[q.StepID]
+			if !ran || (status != models.StepStatusCompleted && status != models.StepStatusFailed) {
+				continue
+			}
+			clean = status == models.StepStatusCompleted
+		}
+
+		cleanRuns := 0
+		if clean {
+			cleanRuns = q.CleanRuns + 1
+		}
+		if cleanRuns == q.CleanRuns {
+			continue
+		}
+
+		if cleanRuns >= policy.ReleaseAfter {
+			if err := m.Release(q, fmt.Sprintf("%d consecutive clean runs", cleanRuns), system); err != nil {
+				m.logger.Warn("Failed to release quarantine", zap.String("quarantine_id", q.ID.String()), zap.Error(err))
+			}
+			continue
+		}
+		if err := m.repo.SetCleanRuns(q.ID, cleanRuns); err != nil {
+			m.logger.Warn("Failed to record quarantine clean runs", zap.String("quarantine_id", q.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// ConsiderFlakiness quarantines a flow, or its steps, whose flakiness reached the
+// workspace threshold when the workspace quarantines automatically. It is the
+// flakiness handler of the reporting aggregator.
+func (m *Manager) ConsiderFlakiness(metric *models.FlakinessMetric, steps []reporting.StepFlakiness) {
+	flow, err := m.flowRepo.GetByIDUnscoped(metric.FlowID)
+	if err != nil {
+		return
+	}
+	policy := m.Policy(flow.WorkspaceID)
+	if !policy.AutoQuarantine {
+		return
+	}
+
+	// A quarantined flow covers its steps
+	if _, err := m.repo.GetActive(flow.ID, ""); err == nil {
+		return
+	}
+
+	if metric.TotalExecs >= minRunsForAuto && metric.FlakinessScore >= policy.FlakinessThreshold {
+		m.autoQuarantine(flow, "", metric.FlakinessScore,
+			fmt.Sprintf("flakiness %.2f over %d runs in %d days (%d transitions)", metric.FlakinessScore, metric.TotalExecs, metric.WindowDays, metric.Transitions))
+		return
+	}
+
+	for _, step := range steps {
+		if step.TotalRuns < minRunsForAuto || step.FlakinessScore < policy.FlakinessThreshold {
+			continue
+		}
+		m.autoQuarantine(flow, step.StepID, step.FlakinessScore,
+			fmt.Sprintf("flakiness %.2f with %d of %d runs failed in %d days", step.FlakinessScore, step.FailedRuns, step.TotalRuns, metric.WindowDays))
+	}
+}
+
+func (m *Manager) autoQuarantine(flow *models.Flow, stepID string, score float64, reason string) {
+	q := &models.Quarantine{
+		WorkspaceID:    flow.WorkspaceID,
+		FlowID:         flow.ID,
+		StepID:         stepID,
+		Status:         models.QuarantineStatusActive,
+		Source:         models.QuarantineSourceAuto,
+		Reason:         reason,
+		FlakinessScore: score,
+	}
+	err := m.add(flow, q, system)
+	if err != nil && !errors.Is(err, ErrAlreadyQuarantined) {
+		m.logger.Warn("Failed to quarantine flaky flow",
+			zap.String("flow_id", flow.ID.String()),
+			zap.String("step_id", stepID),
+			zap.Error(err))
+	}
+}
+
+// emit records an activity event for a quarantine change and sends the workspace notification
+func (m *Manager) emit(eventType string, flow *models.Flow, q *models.Quarantine, actor Actor, description string) {
+	workspaceID := flow.WorkspaceID
+
+	event := &models.ActivityEvent{
+		ActorID:      actor.ID,
+		ActorName:    actor.Name,
+		EventType:    eventType,
+		ResourceType: "flow",
+		ResourceID:   flow.ID,
+		ResourceName: flow.Name,
+		Description:  description,
+		Metadata: models.JSONMap{
+			"quarantine_id":   q.ID.String(),
+			"step_id":         q.StepID,
+			"source":          string(q.Source),
+			"flakiness_score": q.FlakinessScore,
+			"clean_runs":      q.CleanRuns,
+		},
+		WorkspaceID: &workspaceID,
+	}
+	if err := m.activityRepo.CreateActivityEvent(event); err != nil {
+		m.logger.Warn("Failed to record quarantine activity", zap.Error(err))
+	}
+
+	m.logger.Info("Quarantine changed",
+		zap.String("event", eventType),
+		zap.String("flow_id", flow.ID.String()),
+		zap.String("step_id", q.StepID))
+
+	if url := m.Policy(workspaceID).NotifyWebhookURL; url != "" {
+		go m.notify(url, description)
+	}
+}
+
+// notify posts a message to a Slack-compatible incoming webhook
+func (m *Manager) notify(url, text string) {
+	body, _ := json.Marshal(map[string]string{"text": ":test_tube: " + text})
+	resp, err := m.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		m.logger.Warn("Failed to send quarantine notification", zap.Error(err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		m.logger.Warn("Quarantine notification was rejected", zap.Int("status", resp.StatusCode))
+	}
+}
+
+// target names the quarantined flow or step in messages
+func target(flow *models.Flow, q *models.Quarantine) string {
+	if q.StepID == "" {
+		return fmt.Sprintf("Flow %q", flow.Name)
+	}
+	return fmt.Sprintf("Step %q of flow %q", q.StepID, flow.Name)
+}
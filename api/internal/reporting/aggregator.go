@@ -22,8 +22,21 @@ type Aggregator struct {
 	logger       *zap.Logger
 	cron         *cron.Cron
 	flakyThreshold float64 // Threshold for marking a flow as flaky (e.g., 0.1 = 10% flakiness)
+	onFlakiness    FlakinessHandler
 }
 
+// StepFlakiness is the flakiness of one step of a flow over a flakiness window
+type StepFlakiness struct {
+	StepID         string
+	StepName       string
+	TotalRuns      int
+	FailedRuns     int
+	FlakinessScore float64
+}
+
+// FlakinessHandler is called with the flakiness metric of each flow and the flakiness of its steps
+type FlakinessHandler func(metric *models.FlakinessMetric, steps []StepFlakiness)
+
 // NewAggregator creates a new metrics aggregator
 func NewAggregator(
 	db *gorm.DB,
@@ -42,6 +55,11 @@ func NewAggregator(
 	}
 }
 
+// SetFlakinessHandler sets the function called after each flow's flakiness is calculated
+func (a *Aggregator) SetFlakinessHandler(fn FlakinessHandler) {
+	a.onFlakiness = fn
+}
+
 // ScheduleAggregation starts the scheduled aggregation job (runs at 2 AM daily)
 func (a *Aggregator) ScheduleAggregation() error {
 	a.cron = cron.New(cron.WithLocation(time.UTC))
@@ -321,12 +339,13 @@ func (a *Aggregator) calculateFlowFlakiness(flowID uuid.UUID, startDate, endDate
 		return nil // Not enough data to determine flakiness
 	}
 
-	var passed, failed, transitions int
-	var lastStatus string
+	var passed, failed int
+	outcomes := make([]bool, len(executions))
 	errorPatterns := make(map[string]int)
 
 	for i, exec := range executions {
-		if exec.Status == "completed" {
+		outcomes[i] = exec.Status == "completed"
+		if outcomes[i] {
 			passed++
 		} else {
 			failed++
@@ -339,27 +358,10 @@ func (a *Aggregator) calculateFlowFlakiness(flowID uuid.UUID, startDate, endDate
 				errorPatterns[pattern]++
 			}
 		}
-
-		if i > 0 && exec.Status != lastStatus {
-			transitions++
-		}
-		lastStatus = exec.Status
 	}
 
 	total := passed + failed
-
-	// Flakiness score: weighted combination of pass/fail ratio and transitions
-	// Higher score = more flaky
-	// Formula: (transitions / (total-1)) * (1 - abs(pass_rate - 0.5) * 2)
-	// This gives higher scores when there are many transitions and pass rate is near 50%
-	var flakinessScore float64
-	if total > 1 {
-		transitionRate := float64(transitions) / float64(total-1)
-		passRate := float64(passed) / float64(total)
-		// Score is higher when pass rate is close to 50% (inconsistent)
-		consistency := 1.0 - abs(passRate-0.5)*2
-		flakinessScore = transitionRate * consistency
-	}
+	flakinessScore, transitions := scoreFlakiness(outcomes)
 
 	// Get top failure patterns
 	var failurePatterns []string
@@ -392,7 +394,94 @@ func (a *Aggregator) calculateFlowFlakiness(flowID uuid.UUID, startDate, endDate
 		FailurePatterns: failurePatterns,
 	}
 
-	return a.reportRepo.UpsertFlakinessMetric(metric)
+	if err := a.reportRepo.UpsertFlakinessMetric(metric); err != nil {
+		return err
+	}
+
+	if a.onFlakiness != nil {
+		steps, err := a.calculateStepFlakiness(flowID, startDate, endDate)
+		if err != nil {
+			return err
+		}
+		a.onFlakiness(metric, steps)
+	}
+	return nil
+}
+
+// calculateStepFlakiness computes the flakiness of each step of a flow from its step results
+func (a *Aggregator) calculateStepFlakiness(flowID uuid.UUID, startDate, endDate time.Time) ([]StepFlakiness, error) {
+	var rows []struct {
+		StepID   string
+		StepName string
+		Status   string
+	}
+
+	err := a.db.Table("executions.execution_steps AS s").
+		Select("s.step_id, s.step_name, s.status").
+		Joins("JOIN executions.executions e ON e.id = s.execution_id").
+		Where("e.flow_id = ? AND e.created_at >= ? AND e.created_at <= ? AND s.status IN ?",
+			flowID, startDate, endDate, []string{"completed", "failed"}).
+		Order("e.created_at ASC, s.started_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	outcomes := make(map[string][]bool)
+	names := make(map[string]string)
+	for _, row := range rows {
+		if _, ok := outcomes[row.StepID]; !ok {
+			order = append(order, row.StepID)
+		}
+		outcomes[row.StepID] = append(outcomes[row.StepID], row.Status == "completed")
+		names[row.StepID] = row.StepName
+	}
+
+	steps := make([]StepFlakiness, 0, len(order))
+	for _, stepID := range order {
+		results := outcomes[stepID]
+		failed := 0
+		for _, passed := range results {
+			if !passed {
+				failed++
+			}
+		}
+		score, _ := scoreFlakiness(results)
+		steps = append(steps, StepFlakiness{
+			StepID:         stepID,
+			StepName:       names[stepID],
+			TotalRuns:      len(results),
+			FailedRuns:     failed,
+			FlakinessScore: score,
+		})
+	}
+	return steps, nil
+}
+
+// scoreFlakiness scores a chronological series of pass/fail outcomes and counts its transitions.
+// Formula: (transitions / (total-1)) * (1 - abs(pass_rate - 0.5) * 2)
+// This gives higher scores when there are many transitions and pass rate is near 50%
+func scoreFlakiness(outcomes []bool) (float64, int) {
+	passed, transitions := 0, 0
+	for i, ok := range outcomes {
+		if ok {
+			passed++
+		}
+		if i > 0 && ok != outcomes[i-1] {
+			transitions++
+		}
+	}
+
+	total := len(outcomes)
+	if total < 2 {
+		return 0, transitions
+	}
+	transitionRate := float64(transitions) / float64(total-1)
+	passRate := float64(passed) / float64(total)
+	// Score is higher when pass rate is close to 50% (inconsistent)
+	consistency := 1.0 - abs(passRate-0.5)*2
+	return transitionRate * consistency, transitions
 }
 
 // AggregateStepPerformance aggregates step-level performance metrics
@@ -0,0 +1,247 @@
+package reporting
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// allureNamespace seeds result UUIDs, so exporting a run twice gives the same files
+var allureNamespace = uuid.MustParse("6f1c1f3e-6a4b-4c1e-9a55-7b1f0a3f2d10")
+
+// AllureExporter writes Allure 2 result files as a zip archive. Unpacked, the
+// archive is an allure-results directory with one *-result.json per flow and
+// the request and response of every step as attachments.
+type AllureExporter struct{}
+
+func (e *AllureExporter) Format() string      { return "allure" }
+func (e *AllureExporter) Extension() string   { return "zip" }
+func (e *AllureExporter) ContentType() string { return "application/zip" }
+
+// Allure result structures
+type allureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	TestCaseID    string             `json:"testCaseId"`
+	FullName      string             `json:"fullName"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []allureLabel      `json:"labels"`
+	Parameters    []allureParameter  `json:"parameters,omitempty"`
+	Steps         []allureStep       `json:"steps"`
+	Attachments   []allureAttachment `json:"attachments"`
+}
+
+type allureDetails struct {
+	Message string `json:"message,omitempty"`
+	Flaky   bool   `json:"flaky,omitempty"`
+	Muted   bool   `json:"muted,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureStep struct {
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Parameters    []allureParameter  `json:"parameters,omitempty"`
+	Steps         []allureStep       `json:"steps"`
+	Attachments   []allureAttachment `json:"attachments"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// Export renders the run as a zip of Allure result files
+func (e *AllureExporter) Export(ctx context.Context, run *TestRun) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	environments := make(map[string]bool)
+	for _, tc := range run.Cases {
+		key := tc.ExecutionID
+		if key == "" {
+			key = tc.FlowID + "/" + tc.Name
+		}
+		resultID := uuid.NewSHA1(allureNamespace, []byte(key)).String()
+
+		result := allureResult{
+			UUID:        resultID,
+			HistoryID:   allureHash(tc.FlowID, tc.Name),
+			TestCaseID:  allureHash(tc.FlowID),
+			FullName:    tc.Suite + "." + tc.Name,
+			Name:        tc.Name,
+			Description: tc.Description,
+			Status:      allureStatus(tc),
+			Stage:       "finished",
+			Start:       unixMillis(tc.StartedAt),
+			Stop:        unixMillis(tc.FinishedAt),
+			Labels:      allureLabels(run, tc),
+			Steps:       []allureStep{},
+			Attachments: []allureAttachment{},
+		}
+		if tc.Message != "" || tc.Flaky || tc.Quarantined {
+			result.StatusDetails = &allureDetails{Message: tc.Message, Flaky: tc.Flaky, Muted: tc.Quarantined}
+		}
+		if tc.Environment != "" {
+			environments[tc.Environment] = true
+			result.Parameters = append(result.Parameters, allureParameter{Name: "environment", Value: tc.Environment})
+		}
+		if tc.Retries > 0 {
+			result.Parameters = append(result.Parameters, allureParameter{Name: "retries", Value: fmt.Sprint(tc.Retries)})
+		}
+
+		for i, ts := range tc.Steps {
+			step := allureStep{
+				Name:        ts.Name,
+				Status:      ts.Status,
+				Stage:       "finished",
+				Start:       unixMillis(ts.StartedAt),
+				Stop:        unixMillis(ts.FinishedAt),
+				Parameters:  []allureParameter{{Name: "action", Value: ts.Action}},
+				Steps:       []allureStep{},
+				Attachments: []allureAttachment{},
+			}
+			if ts.Message != "" {
+				step.StatusDetails = &allureDetails{Message: ts.Message}
+			}
+			if ts.Attempts > 1 {
+				step.Parameters = append(step.Parameters, allureParameter{Name: "attempts", Value: fmt.Sprint(ts.Attempts)})
+			}
+
+			for j, attachment := range ts.Attachments {
+				content, contentType := attachment.Content, attachment.ContentType
+				if content == nil && attachment.URL != "" {
+					// Artifacts too large to embed are linked
+					content, contentType = []byte(attachment.URL+"\n"), "text/uri-list"
+				}
+				source := fmt.Sprintf("%s-%d-%d-attachment%s", resultID, i, j, attachmentExtension(contentType))
+				if err := writeZipFile(archive, source, content); err != nil {
+					return nil, err
+				}
+				step.Attachments = append(step.Attachments, allureAttachment{
+					Name:   attachment.Name,
+					Source: source,
+					Type:   contentType,
+				})
+			}
+			result.Steps = append(result.Steps, step)
+		}
+
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeZipFile(archive, resultID+"-result.json", content); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(environments) > 0 {
+		names := make([]string, 0, len(environments))
+		for env := range environments {
+			names = append(names, env)
+		}
+		sort.Strings(names)
+		properties := fmt.Sprintf("Run=%s\nEnvironment=%s\n", run.Name, strings.Join(names, ", "))
+		if err := writeZipFile(archive, "environment.properties", []byte(properties)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// allureStatus maps a case to passed, failed, broken or skipped. Failures
+// outside of any step, such as an invalid flow, are broken.
+func allureStatus(tc TestCase) string {
+	if tc.Status != ResultFailed {
+		return tc.Status
+	}
+	for _, step := range tc.Steps {
+		if step.Status == ResultFailed {
+			return "failed"
+		}
+	}
+	return "broken"
+}
+
+func allureLabels(run *TestRun, tc TestCase) []allureLabel {
+	labels := []allureLabel{
+		{Name: "framework", Value: "testmesh"},
+		{Name: "parentSuite", Value: run.Name},
+		{Name: "suite", Value: tc.Suite},
+	}
+	for _, tag := range tc.Tags {
+		labels = append(labels, allureLabel{Name: "tag", Value: tag})
+	}
+	if tc.Quarantined {
+		labels = append(labels, allureLabel{Name: "tag", Value: "quarantined"})
+	}
+	return labels
+}
+
+func allureHash(parts ...string) string {
+	sum := md5.Sum([]byte(strings.Join(parts, "/")))
+	return hex.EncodeToString(sum[:])
+}
+
+func attachmentExtension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return ".json"
+	case "text/html":
+		return ".html"
+	case "application/xml", "text/xml":
+		return ".xml"
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "text/uri-list":
+		return ".uri"
+	default:
+		return ".txt"
+	}
+}
+
+func writeZipFile(archive *zip.Writer, name string, content []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,87 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// embedArtifactLimit is the largest artifact embedded in exports; larger
+// artifacts are only linked
+const embedArtifactLimit = 1 << 20
+
+// SetArtifacts attaches stored artifacts to the steps of exported runs, with
+// download links under baseURL
+func (g *Generator) SetArtifacts(manager *artifacts.Manager, baseURL string) {
+	g.artifacts = manager
+	g.artifactsURL = strings.TrimRight(baseURL, "/")
+}
+
+// attachArtifacts adds the artifacts of the reported attempts to their steps
+func (g *Generator) attachArtifacts(run *TestRun, executions []models.Execution, ids []uuid.UUID) {
+	if g.artifacts == nil || len(run.Cases) == 0 {
+		return
+	}
+
+	stored, err := g.artifacts.ListByExecutions(ids)
+	if err != nil {
+		g.logger.Warn("Failed to load execution artifacts", zap.Error(err))
+		return
+	}
+	if len(stored) == 0 {
+		return
+	}
+
+	workspaces := make(map[uuid.UUID]uuid.UUID, len(executions))
+	for _, execution := range executions {
+		if execution.Flow != nil {
+			workspaces[execution.ID] = execution.Flow.WorkspaceID
+		}
+	}
+
+	ctx := context.Background()
+	for i := range run.Cases {
+		tc := &run.Cases[i]
+		executionID, err := uuid.Parse(tc.ExecutionID)
+		if err != nil {
+			continue
+		}
+		for _, artifact := range stored[executionID] {
+			for j := range tc.Steps {
+				if tc.Steps[j].ID == artifact.StepID {
+					tc.Steps[j].Attachments = append(tc.Steps[j].Attachments, g.artifactAttachment(ctx, artifact, workspaces[executionID]))
+					break
+				}
+			}
+		}
+	}
+}
+
+// artifactAttachment links an artifact and embeds its content when it is small
+// enough
+func (g *Generator) artifactAttachment(ctx context.Context, artifact models.Artifact, flowWorkspace uuid.UUID) TestAttachment {
+	attachment := TestAttachment{
+		Name:        artifact.Name,
+		ContentType: artifact.ContentType,
+	}
+
+	workspaceID := flowWorkspace
+	if artifact.WorkspaceID != nil {
+		workspaceID = *artifact.WorkspaceID
+	}
+	if g.artifactsURL != "" && workspaceID != uuid.Nil {
+		attachment.URL = fmt.Sprintf("%s/api/v1/workspaces/%s/artifacts/%s/download", g.artifactsURL, workspaceID, artifact.ID)
+	}
+
+	content, err := g.artifacts.Read(ctx, &artifact, embedArtifactLimit)
+	if err != nil {
+		g.logger.Warn("Failed to read artifact", zap.String("artifact_id", artifact.ID.String()), zap.Error(err))
+	}
+	attachment.Content = content
+	return attachment
+}
@@ -0,0 +1,712 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/runner/snapshots"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultLatencyThreshold is the number of standard deviations above the
+	// historical mean at which a step counts as a latency regression
+	DefaultLatencyThreshold = 3.0
+	// DefaultHistoryDays is the window of step performance history used as baseline
+	DefaultHistoryDays = 14
+
+	// minHistorySamples is the number of historical runs needed to use the
+	// history as baseline instead of the base run
+	minHistorySamples = 5
+	// Without history a step regresses when it is this much slower than in the base run
+	fallbackSlowdown  = 1.5
+	fallbackMinDiffMs = 100
+
+	// shapeArtifactLimit is the largest offloaded output value loaded to
+	// compare output shapes; larger values are left out of the comparison
+	shapeArtifactLimit = 8 << 20
+)
+
+// Flow and step changes between two runs
+const (
+	ChangeNewlyFailing = "newly_failing"
+	ChangeNewlyPassing = "newly_passing"
+	ChangeStillFailing = "still_failing"
+	ChangeSkipped      = "skipped"
+	ChangeUnchanged    = "unchanged"
+	ChangeAdded        = "added"
+	ChangeRemoved      = "removed"
+)
+
+// CompareOptions tunes regression detection
+type CompareOptions struct {
+	LatencyThreshold float64 // Standard deviations, defaults to DefaultLatencyThreshold
+	HistoryDays      int     // Defaults to DefaultHistoryDays
+}
+
+// Comparison is the difference between a base run and a head run
+type Comparison struct {
+	Title       string            `json:"title"`
+	Base        RunSummary        `json:"base"`
+	Head        RunSummary        `json:"head"`
+	Summary     ComparisonSummary `json:"summary"`
+	Flows       []FlowComparison  `json:"flows"`
+	Options     CompareOptions    `json:"options"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// RunSummary identifies one side of a comparison
+type RunSummary struct {
+	Kind      string     `json:"kind"` // "execution" or "schedule_run"
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Flows     int        `json:"flows"`
+	Passed    int        `json:"passed"`
+	Failed    int        `json:"failed"`
+}
+
+// ComparisonSummary counts what changed between the runs
+type ComparisonSummary struct {
+	NewlyFailing       int  `json:"newly_failing"`
+	NewlyPassing       int  `json:"newly_passing"`
+	StillFailing       int  `json:"still_failing"`
+	Added              int  `json:"added"`
+	Removed            int  `json:"removed"`
+	LatencyRegressions int  `json:"latency_regressions"`
+	ErrorChanges       int  `json:"error_changes"`
+	ShapeChanges       int  `json:"shape_changes"`
+	Regressed          bool `json:"regressed"` // Anything newly failing or slower
+}
+
+// FlowComparison is the difference of one flow between the runs
+type FlowComparison struct {
+	FlowID          string           `json:"flow_id"`
+	FlowName        string           `json:"flow_name"`
+	Change          string           `json:"change"`
+	BaseStatus      string           `json:"base_status,omitempty"`
+	HeadStatus      string           `json:"head_status,omitempty"`
+	BaseExecutionID string           `json:"base_execution_id,omitempty"`
+	HeadExecutionID string           `json:"head_execution_id,omitempty"`
+	BaseDurationMs  int64            `json:"base_duration_ms"`
+	HeadDurationMs  int64            `json:"head_duration_ms"`
+	BaseError       string           `json:"base_error,omitempty"`
+	HeadError       string           `json:"head_error,omitempty"`
+	ErrorChanged    bool             `json:"error_changed"`
+	Steps           []StepComparison `json:"steps,omitempty"` // Only steps that changed
+}
+
+// StepComparison is the difference of one step between the runs
+type StepComparison struct {
+	StepID         string                  `json:"step_id"`
+	StepName       string                  `json:"step_name"`
+	Action         string                  `json:"action"`
+	Change         string                  `json:"change"`
+	BaseStatus     string                  `json:"base_status,omitempty"`
+	HeadStatus     string                  `json:"head_status,omitempty"`
+	BaseDurationMs int64                   `json:"base_duration_ms"`
+	HeadDurationMs int64                   `json:"head_duration_ms"`
+	Latency        *LatencyRegression      `json:"latency_regression,omitempty"`
+	BaseError      string                  `json:"base_error,omitempty"`
+	HeadError      string                  `json:"head_error,omitempty"`
+	ErrorChanged   bool                    `json:"error_changed"`
+	ShapeChanges   []models.SnapshotChange `json:"shape_changes,omitempty"`
+}
+
+// LatencyRegression explains why a step counts as slower
+type LatencyRegression struct {
+	Baseline   string  `json:"baseline"` // "history" or "base_run"
+	MeanMs     float64 `json:"mean_ms"`
+	StdDevMs   float64 `json:"stddev_ms,omitempty"`
+	Samples    int     `json:"samples"`
+	ZScore     float64 `json:"z_score,omitempty"`
+	SlowdownPc float64 `json:"slowdown_pct"`
+}
+
+// Changed reports whether anything about the flow differs between the runs
+func (f FlowComparison) Changed() bool {
+	return f.Change != ChangeUnchanged || f.ErrorChanged || len(f.Steps) > 0
+}
+
+// Details describes the latency, error and response shape changes of a step on one line
+func (s StepComparison) Details() string {
+	var parts []string
+	if l := s.Latency; l != nil {
+		if l.Baseline == "history" {
+			parts = append(parts, fmt.Sprintf("%.0f%% slower than the %d-run mean of %s (z=%.1f)", l.SlowdownPc, l.Samples, formatDuration(int64(l.MeanMs)), l.ZScore))
+		} else {
+			parts = append(parts, fmt.Sprintf("%.0f%% slower than the base run", l.SlowdownPc))
+		}
+	}
+	if s.ErrorChanged {
+		parts = append(parts, fmt.Sprintf("error changed from %q to %q", oneLine(s.BaseError), oneLine(s.HeadError)))
+	} else if s.Change == ChangeNewlyFailing && s.HeadError != "" {
+		parts = append(parts, fmt.Sprintf("error: %q", oneLine(s.HeadError)))
+	}
+	for _, change := range s.ShapeChanges {
+		parts = append(parts, change.Description)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// RenderComparison renders a comparison as "html" or "markdown" with the built-in templates
+func RenderComparison(comparison *Comparison, format string) (string, error) {
+	engine := NewTemplateEngine()
+	var err error
+	switch format {
+	case "html":
+		err = engine.RegisterTemplate("comparison", ComparisonHTMLTemplate)
+	case "markdown", "md":
+		err = engine.RegisterTextTemplate("comparison", ComparisonMarkdownTemplate)
+	default:
+		return "", fmt.Errorf("unsupported comparison format: %s", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return engine.Render("comparison", comparison)
+}
+
+// Comparer compares executions and grouped schedule runs
+type Comparer struct {
+	execRepo   *repository.ExecutionRepository
+	reportRepo *repository.ReportingRepository
+	artifacts  *artifacts.Manager
+	logger     *zap.Logger
+}
+
+// NewComparer creates a run comparer
+func NewComparer(execRepo *repository.ExecutionRepository, reportRepo *repository.ReportingRepository, logger *zap.Logger) *Comparer {
+	return &Comparer{
+		execRepo:   execRepo,
+		reportRepo: reportRepo,
+		logger:     logger,
+	}
+}
+
+// SetArtifacts loads offloaded step output values so that their shapes are compared
+func (c *Comparer) SetArtifacts(manager *artifacts.Manager) {
+	c.artifacts = manager
+}
+
+// runSide is one side of a comparison with its flows by flow ID
+type runSide struct {
+	summary    RunSummary
+	executions map[uuid.UUID]*models.Execution
+	names      map[uuid.UUID]string
+	statuses   map[uuid.UUID]string // "passed", "failed" or "skipped"
+	order      []uuid.UUID
+}
+
+// CompareExecutions compares two executions. The latest rerun of a failed execution stands for it.
+func (c *Comparer) CompareExecutions(base, head *models.Execution, opts CompareOptions) (*Comparison, error) {
+	baseSide := c.executionSide(base)
+	headSide := c.executionSide(head)
+	title := fmt.Sprintf("Execution %s vs %s", shortID(base.ID), shortID(head.ID))
+	return c.compare(title, baseSide, headSide, opts)
+}
+
+// CompareScheduleRuns compares two grouped runs flow by flow
+func (c *Comparer) CompareScheduleRuns(scheduleName string, base, head *models.ScheduleRun, opts CompareOptions) (*Comparison, error) {
+	baseSide, err := c.scheduleRunSide(scheduleName, base)
+	if err != nil {
+		return nil, err
+	}
+	headSide, err := c.scheduleRunSide(scheduleName, head)
+	if err != nil {
+		return nil, err
+	}
+	title := fmt.Sprintf("%s: run %s vs %s", scheduleName, base.ScheduledAt.Format("2006-01-02 15:04"), head.ScheduledAt.Format("2006-01-02 15:04"))
+	return c.compare(title, baseSide, headSide, opts)
+}
+
+func (c *Comparer) executionSide(execution *models.Execution) *runSide {
+	latest := c.latestAttempt(execution)
+	name := latest.FlowID.String()
+	if execution.Flow != nil {
+		name = execution.Flow.Name
+	}
+
+	side := &runSide{
+		summary: RunSummary{
+			Kind:      "execution",
+			ID:        execution.ID.String(),
+			Name:      name,
+			Status:    flowStatus(latest),
+			StartedAt: execution.StartedAt,
+			Flows:     1,
+		},
+		executions: map[uuid.UUID]*models.Execution{latest.FlowID: latest},
+		names:      map[uuid.UUID]string{latest.FlowID: name},
+		statuses:   map[uuid.UUID]string{latest.FlowID: flowStatus(latest)},
+		order:      []uuid.UUID{latest.FlowID},
+	}
+	if side.summary.Status == "passed" {
+		side.summary.Passed = 1
+	} else {
+		side.summary.Failed = 1
+	}
+	return side
+}
+
+func (c *Comparer) scheduleRunSide(scheduleName string, run *models.ScheduleRun) (*runSide, error) {
+	side := &runSide{
+		summary: RunSummary{
+			Kind:      "schedule_run",
+			ID:        run.ID.String(),
+			Name:      fmt.Sprintf("%s @ %s", scheduleName, run.ScheduledAt.Format("2006-01-02 15:04")),
+			Status:    run.Result,
+			StartedAt: run.StartedAt,
+		},
+		executions: make(map[uuid.UUID]*models.Execution),
+		names:      make(map[uuid.UUID]string),
+		statuses:   make(map[uuid.UUID]string),
+	}
+
+	add := func(flowID uuid.UUID, name string, executionID *uuid.UUID) {
+		if _, seen := side.names[flowID]; seen {
+			return
+		}
+		side.names[flowID] = name
+		side.order = append(side.order, flowID)
+		side.statuses[flowID] = "skipped"
+		if executionID == nil {
+			return
+		}
+		execution, err := c.execRepo.GetByID(*executionID)
+		if err != nil {
+			c.logger.Warn("Execution of schedule run not found", zap.String("execution_id", executionID.String()))
+			return
+		}
+		execution = c.latestAttempt(execution)
+		side.executions[flowID] = execution
+		side.statuses[flowID] = flowStatus(execution)
+	}
+
+	for _, fr := range run.FlowResults {
+		add(fr.FlowID, fr.FlowName, fr.ExecutionID)
+	}
+	// Runs of single-flow schedules have no flow results
+	if len(run.FlowResults) == 0 && run.ExecutionID != nil {
+		execution, err := c.execRepo.GetByID(*run.ExecutionID)
+		if err != nil {
+			return nil, fmt.Errorf("execution of schedule run not found: %w", err)
+		}
+		name := execution.FlowID.String()
+		if execution.Flow != nil {
+			name = execution.Flow.Name
+		}
+		add(execution.FlowID, name, &execution.ID)
+	}
+
+	side.summary.Flows = len(side.order)
+	for _, flowID := range side.order {
+		switch side.statuses[flowID] {
+		case "passed":
+			side.summary.Passed++
+		case "failed":
+			side.summary.Failed++
+		}
+	}
+	return side, nil
+}
+
+// latestAttempt follows reruns of a failed execution to its last attempt
+func (c *Comparer) latestAttempt(execution *models.Execution) *models.Execution {
+	if execution.Status != models.ExecutionStatusFailed {
+		return execution
+	}
+	retry, err := c.execRepo.GetLatestRetry(execution.ID)
+	if err != nil {
+		return execution
+	}
+	if retry.Flow == nil {
+		retry.Flow = execution.Flow
+	}
+	return retry
+}
+
+func (c *Comparer) compare(title string, base, head *runSide, opts CompareOptions) (*Comparison, error) {
+	if opts.LatencyThreshold <= 0 {
+		opts.LatencyThreshold = DefaultLatencyThreshold
+	}
+	if opts.HistoryDays <= 0 {
+		opts.HistoryDays = DefaultHistoryDays
+	}
+
+	comparison := &Comparison{
+		Title:       title,
+		Base:        base.summary,
+		Head:        head.summary,
+		Options:     opts,
+		GeneratedAt: time.Now(),
+	}
+
+	// Head flows first in run order, then flows only the base ran
+	order := append([]uuid.UUID{}, head.order...)
+	for _, flowID := range base.order {
+		if _, ok := head.names[flowID]; !ok {
+			order = append(order, flowID)
+		}
+	}
+
+	for _, flowID := range order {
+		name := head.names[flowID]
+		if name == "" {
+			name = base.names[flowID]
+		}
+		fc, err := c.compareFlow(flowID, name, base, head, opts)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Flows = append(comparison.Flows, *fc)
+	}
+
+	summarizeComparison(comparison)
+	return comparison, nil
+}
+
+func (c *Comparer) compareFlow(flowID uuid.UUID, name string, baseSide, headSide *runSide, opts CompareOptions) (*FlowComparison, error) {
+	fc := &FlowComparison{
+		FlowID:     flowID.String(),
+		FlowName:   name,
+		BaseStatus: baseSide.statuses[flowID],
+		HeadStatus: headSide.statuses[flowID],
+	}
+	base, head := baseSide.executions[flowID], headSide.executions[flowID]
+	if base != nil {
+		fc.BaseExecutionID = base.ID.String()
+		fc.BaseDurationMs = base.DurationMs
+		fc.BaseError = base.Error
+	}
+	if head != nil {
+		fc.HeadExecutionID = head.ID.String()
+		fc.HeadDurationMs = head.DurationMs
+		fc.HeadError = head.Error
+	}
+	fc.Change = statusChange(fc.BaseStatus, fc.HeadStatus)
+	fc.ErrorChanged = fc.Change == ChangeStillFailing && fc.BaseError != fc.HeadError
+
+	if base == nil || head == nil {
+		return fc, nil
+	}
+
+	baseSteps, err := c.execRepo.GetSteps(base.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load steps of execution %s: %w", base.ID, err)
+	}
+	headSteps, err := c.execRepo.GetSteps(head.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load steps of execution %s: %w", head.ID, err)
+	}
+
+	baseline := c.stepBaselines(flowID, head, opts.HistoryDays)
+
+	baseByID := lastStepRuns(baseSteps)
+	headByID := lastStepRuns(headSteps)
+	for _, step := range orderedSteps(headSteps, baseSteps) {
+		baseStep, headStep := c.resolveOutputs(baseByID[step], headByID[step])
+		sc := compareStep(baseStep, headStep, baseline[step], opts.LatencyThreshold)
+		if sc != nil {
+			fc.Steps = append(fc.Steps, *sc)
+		}
+	}
+	return fc, nil
+}
+
+// resolveOutputs returns copies of a step's runs whose outputs hold the
+// offloaded values in place of artifact references. Values whose reference
+// cannot be resolved on either side are not comparable and are left out.
+func (c *Comparer) resolveOutputs(base, head *models.ExecutionStep) (*models.ExecutionStep, *models.ExecutionStep) {
+	if base == nil || head == nil {
+		return base, head
+	}
+	baseCopy, headCopy := *base, *head
+	baseOutput, headOutput := base.Output, head.Output
+	if c.artifacts != nil {
+		ctx := context.Background()
+		baseOutput = c.artifacts.ResolveOutput(ctx, baseOutput, shapeArtifactLimit)
+		headOutput = c.artifacts.ResolveOutput(ctx, headOutput, shapeArtifactLimit)
+	}
+	baseCopy.Output = comparableOutput(baseOutput, headOutput)
+	headCopy.Output = comparableOutput(headOutput, baseOutput)
+	return &baseCopy, &headCopy
+}
+
+// comparableOutput returns output without the values that are artifact
+// references in output or other
+func comparableOutput(output, other models.OutputData) models.OutputData {
+	if output == nil {
+		return nil
+	}
+	kept := make(models.OutputData, len(output))
+	for key, value := range output {
+		if artifacts.IsRef(value) || artifacts.IsRef(other[key]) {
+			continue
+		}
+		kept[key] = value
+	}
+	return kept
+}
+
+// compareStep returns the differences of a step between runs, or nil when nothing changed
+func compareStep(base, head *models.ExecutionStep, history *stepBaseline, threshold float64) *StepComparison {
+	ref := head
+	if ref == nil {
+		ref = base
+	}
+	sc := &StepComparison{
+		StepID:   ref.StepID,
+		StepName: ref.StepName,
+		Action:   ref.Action,
+	}
+	if base != nil {
+		sc.BaseStatus = stepStatus(base.Status)
+		sc.BaseDurationMs = base.DurationMs
+		sc.BaseError = base.ErrorMessage
+	}
+	if head != nil {
+		sc.HeadStatus = stepStatus(head.Status)
+		sc.HeadDurationMs = head.DurationMs
+		sc.HeadError = head.ErrorMessage
+	}
+	sc.Change = statusChange(sc.BaseStatus, sc.HeadStatus)
+	sc.ErrorChanged = sc.Change == ChangeStillFailing && sc.BaseError != sc.HeadError
+
+	if base != nil && head != nil {
+		if sc.HeadStatus == "passed" {
+			sc.Latency = latencyRegression(base.DurationMs, head.DurationMs, history, threshold)
+		}
+		if baseShape, headShape := snapshots.Shape(base.Output), snapshots.Shape(head.Output); baseShape != nil && headShape != nil {
+			sc.ShapeChanges = snapshots.DiffShape(baseShape, headShape)
+		}
+	}
+
+	if sc.Change == ChangeUnchanged && sc.Latency == nil && !sc.ErrorChanged && len(sc.ShapeChanges) == 0 {
+		return nil
+	}
+	return sc
+}
+
+// stepBaseline is the historical duration of a step
+type stepBaseline struct {
+	meanMs   float64
+	stdDevMs float64
+	samples  int
+}
+
+// stepBaselines combines the daily step performance of a flow before the head
+// run into a mean and standard deviation per step. Daily rows only keep
+// percentiles, so each day's spread is estimated from its P50 and P95 as for a
+// normal distribution and pooled with the spread of the daily means.
+func (c *Comparer) stepBaselines(flowID uuid.UUID, head *models.Execution, days int) map[string]*stepBaseline {
+	end := head.CreatedAt
+	if head.StartedAt != nil {
+		end = *head.StartedAt
+	}
+	// Daily rows are dated by day; exclude the day of the head run
+	end = end.Truncate(24 * time.Hour).Add(-time.Nanosecond)
+	start := end.AddDate(0, 0, -days)
+
+	rows, err := c.reportRepo.GetStepPerformance(flowID, start, end)
+	if err != nil {
+		c.logger.Warn("Failed to load step performance history", zap.String("flow_id", flowID.String()), zap.Error(err))
+		return nil
+	}
+
+	byStep := make(map[string][]models.StepPerformance)
+	for _, row := range rows {
+		if row.ExecutionCount > 0 {
+			byStep[row.StepID] = append(byStep[row.StepID], row)
+		}
+	}
+
+	baselines := make(map[string]*stepBaseline, len(byStep))
+	for stepID, daily := range byStep {
+		b := &stepBaseline{}
+		var sum float64
+		for _, d := range daily {
+			b.samples += d.ExecutionCount
+			sum += float64(d.AvgDurationMs) * float64(d.ExecutionCount)
+		}
+		b.meanMs = sum / float64(b.samples)
+
+		var variance float64
+		for _, d := range daily {
+			spread := float64(d.P95DurationMs-d.P50DurationMs) / 1.645
+			offset := float64(d.AvgDurationMs) - b.meanMs
+			variance += float64(d.ExecutionCount) * (spread*spread + offset*offset)
+		}
+		b.stdDevMs = math.Sqrt(variance / float64(b.samples))
+		baselines[stepID] = b
+	}
+	return baselines
+}
+
+// latencyRegression decides whether the head duration of a step regressed. With
+// enough history the head must be threshold standard deviations above the
+// historical mean; otherwise it must be clearly slower than the base run.
+// Either way it must be slower than the base run.
+func latencyRegression(baseMs, headMs int64, history *stepBaseline, threshold float64) *LatencyRegression {
+	if headMs <= baseMs {
+		return nil
+	}
+
+	if history != nil && history.samples >= minHistorySamples {
+		// Floor the deviation so that very stable steps do not flag jitter
+		stdDev := math.Max(history.stdDevMs, math.Max(history.meanMs*0.05, 1))
+		z := (float64(headMs) - history.meanMs) / stdDev
+		if z < threshold {
+			return nil
+		}
+		return &LatencyRegression{
+			Baseline:   "history",
+			MeanMs:     round(history.meanMs),
+			StdDevMs:   round(stdDev),
+			Samples:    history.samples,
+			ZScore:     round(z),
+			SlowdownPc: slowdown(history.meanMs, headMs),
+		}
+	}
+
+	if float64(headMs) < float64(baseMs)*fallbackSlowdown || headMs-baseMs < fallbackMinDiffMs {
+		return nil
+	}
+	return &LatencyRegression{
+		Baseline:   "base_run",
+		MeanMs:     float64(baseMs),
+		Samples:    1,
+		SlowdownPc: slowdown(float64(baseMs), headMs),
+	}
+}
+
+func summarizeComparison(comparison *Comparison) {
+	s := &comparison.Summary
+	for _, fc := range comparison.Flows {
+		switch fc.Change {
+		case ChangeNewlyFailing:
+			s.NewlyFailing++
+		case ChangeNewlyPassing:
+			s.NewlyPassing++
+		case ChangeStillFailing:
+			s.StillFailing++
+		case ChangeAdded:
+			s.Added++
+		case ChangeRemoved:
+			s.Removed++
+		}
+		if fc.ErrorChanged {
+			s.ErrorChanges++
+		}
+		for _, sc := range fc.Steps {
+			if sc.Latency != nil {
+				s.LatencyRegressions++
+			}
+			if sc.ErrorChanged {
+				s.ErrorChanges++
+			}
+			s.ShapeChanges += len(sc.ShapeChanges)
+		}
+	}
+	s.Regressed = s.NewlyFailing > 0 || s.LatencyRegressions > 0
+
+	// Most significant changes first, run order otherwise
+	rank := map[string]int{ChangeNewlyFailing: 0, ChangeStillFailing: 1, ChangeNewlyPassing: 2, ChangeSkipped: 3, ChangeAdded: 4, ChangeRemoved: 5, ChangeUnchanged: 6}
+	sort.SliceStable(comparison.Flows, func(i, j int) bool {
+		return rank[comparison.Flows[i].Change] < rank[comparison.Flows[j].Change]
+	})
+}
+
+// lastStepRuns indexes step runs by step ID, keeping the last attempt of retried steps
+func lastStepRuns(steps []models.ExecutionStep) map[string]*models.ExecutionStep {
+	byID := make(map[string]*models.ExecutionStep, len(steps))
+	for i := range steps {
+		step := &steps[i]
+		if existing, ok := byID[step.StepID]; !ok || step.Attempt >= existing.Attempt {
+			byID[step.StepID] = step
+		}
+	}
+	return byID
+}
+
+// orderedSteps lists step IDs in head run order followed by steps only the base ran
+func orderedSteps(head, base []models.ExecutionStep) []string {
+	seen := make(map[string]bool)
+	var order []string
+	for _, list := range [][]models.ExecutionStep{head, base} {
+		for _, step := range list {
+			if !seen[step.StepID] {
+				seen[step.StepID] = true
+				order = append(order, step.StepID)
+			}
+		}
+	}
+	return order
+}
+
+func statusChange(base, head string) string {
+	switch {
+	case base == "":
+		return ChangeAdded
+	case head == "":
+		return ChangeRemoved
+	case head == "skipped" && base != "skipped":
+		return ChangeSkipped
+	case base == "passed" && head == "failed":
+		return ChangeNewlyFailing
+	case base == "failed" && head == "passed":
+		return ChangeNewlyPassing
+	case base == "failed" && head == "failed":
+		return ChangeStillFailing
+	}
+	return ChangeUnchanged
+}
+
+// flowStatus reduces a finished execution to passed or failed
+func flowStatus(execution *models.Execution) string {
+	switch execution.Status {
+	case models.ExecutionStatusCompleted:
+		return "passed"
+	case models.ExecutionStatusFailed, models.ExecutionStatusCancelled:
+		return "failed"
+	}
+	return string(execution.Status)
+}
+
+func stepStatus(status models.StepStatus) string {
+	switch status {
+	case models.StepStatusCompleted:
+		return "passed"
+	case models.StepStatusFailed:
+		return "failed"
+	}
+	return string(status)
+}
+
+func slowdown(baselineMs float64, headMs int64) float64 {
+	if baselineMs <= 0 {
+		return 0
+	}
+	return round((float64(headMs) - baselineMs) / baselineMs * 100)
+}
+
+func oneLine(s string) string {
+	return truncateString(strings.Join(strings.Fields(s), " "), 200)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func shortID(id uuid.UUID) string {
+	return id.String()[:8]
+}
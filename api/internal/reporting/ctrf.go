@@ -0,0 +1,155 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CTRFExporter writes Common Test Report Format JSON, with one test per flow.
+// Quarantined failures are reported with status "other" and raw status
+// "quarantined", so they don't fail the report.
+type CTRFExporter struct{}
+
+func (e *CTRFExporter) Format() string      { return "ctrf" }
+func (e *CTRFExporter) Extension() string   { return "json" }
+func (e *CTRFExporter) ContentType() string { return "application/json" }
+
+// CTRF structures
+type ctrfReport struct {
+	ReportFormat string      `json:"reportFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	Results      ctrfResults `json:"results"`
+}
+
+type ctrfResults struct {
+	Tool        ctrfTool          `json:"tool"`
+	Summary     ctrfSummary       `json:"summary"`
+	Tests       []ctrfTest        `json:"tests"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+type ctrfTool struct {
+	Name string `json:"name"`
+}
+
+type ctrfSummary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"`
+	Stop    int64 `json:"stop"`
+}
+
+type ctrfTest struct {
+	Name        string                 `json:"name"`
+	Status      string                 `json:"status"`
+	RawStatus   string                 `json:"rawStatus,omitempty"`
+	Duration    int64                  `json:"duration"`
+	Start       int64                  `json:"start,omitempty"`
+	Stop        int64                  `json:"stop,omitempty"`
+	Suite       string                 `json:"suite,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Type        string                 `json:"type"`
+	Retries     int                    `json:"retries"`
+	Flaky       bool                   `json:"flaky"`
+	Steps       []ctrfStep             `json:"steps,omitempty"`
+	Attachments []ctrfAttachment       `json:"attachments,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+type ctrfStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type ctrfAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Path        string `json:"path"`
+}
+
+// Export renders the run as CTRF JSON
+func (e *CTRFExporter) Export(ctx context.Context, run *TestRun) ([]byte, error) {
+	report := ctrfReport{
+		ReportFormat: "CTRF",
+		SpecVersion:  "0.0.0",
+		Results: ctrfResults{
+			Tool: ctrfTool{Name: "testmesh"},
+			Summary: ctrfSummary{
+				Start: unixMillis(run.StartedAt),
+				Stop:  unixMillis(run.FinishedAt),
+			},
+			Tests: []ctrfTest{},
+		},
+	}
+
+	environments := make(map[string]bool)
+	for _, tc := range run.Cases {
+		test := ctrfTest{
+			Name:     tc.Name,
+			Status:   tc.Status,
+			Duration: tc.DurationMs,
+			Start:    unixMillis(tc.StartedAt),
+			Stop:     unixMillis(tc.FinishedAt),
+			Suite:    tc.Suite,
+			Message:  tc.Message,
+			Tags:     tc.Tags,
+			Type:     "api",
+			Retries:  tc.Retries,
+			Flaky:    tc.Flaky,
+			Extra: map[string]interface{}{
+				"flow_id": tc.FlowID,
+			},
+		}
+		if tc.ExecutionID != "" {
+			test.Extra["execution_id"] = tc.ExecutionID
+		}
+		if tc.Environment != "" {
+			test.Extra["environment"] = tc.Environment
+			environments[tc.Environment] = true
+		}
+		if tc.Status == ResultFailed && tc.Quarantined {
+			test.Status = "other"
+			test.RawStatus = "quarantined"
+		}
+		for _, ts := range tc.Steps {
+			test.Steps = append(test.Steps, ctrfStep{Name: ts.Name, Status: ts.Status})
+			// Only stored artifacts have a path to link to
+			for _, attachment := range ts.Attachments {
+				if attachment.URL != "" {
+					test.Attachments = append(test.Attachments, ctrfAttachment{
+						Name:        ts.Name + ": " + attachment.Name,
+						ContentType: attachment.ContentType,
+						Path:        attachment.URL,
+					})
+				}
+			}
+		}
+
+		switch test.Status {
+		case ResultPassed:
+			report.Results.Summary.Passed++
+		case ResultFailed:
+			report.Results.Summary.Failed++
+		case ResultSkipped:
+			report.Results.Summary.Skipped++
+		default:
+			report.Results.Summary.Other++
+		}
+		report.Results.Summary.Tests++
+		report.Results.Tests = append(report.Results.Tests, test)
+	}
+
+	// CTRF has a single environment per report
+	if len(environments) == 1 {
+		for env := range environments {
+			report.Results.Environment = map[string]string{"testEnvironment": env}
+		}
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
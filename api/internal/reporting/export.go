@@ -0,0 +1,109 @@
+package reporting
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/georgi-georgiev/testmesh/internal/plugins"
+)
+
+// Exporter renders a test run into one file of a result format
+type Exporter interface {
+	Format() string
+	Extension() string
+	ContentType() string
+	Export(ctx context.Context, run *TestRun) ([]byte, error)
+}
+
+// ReporterSource provides the formats of loaded reporter plugins
+type ReporterSource interface {
+	GetReporter(format string) (plugins.ReporterPlugin, bool)
+	ListReporters() []plugins.ReporterPlugin
+}
+
+// ExportRegistry holds the result formats runs can be exported to
+type ExportRegistry struct {
+	mu        sync.RWMutex
+	exporters map[string]Exporter
+	reporters ReporterSource
+}
+
+// NewExportRegistry creates a registry with the built-in Allure, CTRF, TAP and PDF exporters
+func NewExportRegistry() *ExportRegistry {
+	r := &ExportRegistry{
+		exporters: make(map[string]Exporter),
+	}
+	r.Register(&AllureExporter{})
+	r.Register(&CTRFExporter{})
+	r.Register(&TAPExporter{})
+	r.Register(&PDFExporter{})
+	return r
+}
+
+// Register adds an exporter, replacing any exporter of the same format
+func (r *ExportRegistry) Register(exporter Exporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exporters[exporter.Format()] = exporter
+}
+
+// SetReporterPlugins makes the formats of reporter plugins available. Built-in
+// formats take precedence over plugins of the same format.
+func (r *ExportRegistry) SetReporterPlugins(source ReporterSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reporters = source
+}
+
+// Get returns the exporter of a format
+func (r *ExportRegistry) Get(format string) (Exporter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if exporter, ok := r.exporters[format]; ok {
+		return exporter, true
+	}
+	if r.reporters != nil {
+		if reporter, ok := r.reporters.GetReporter(format); ok {
+			return &pluginExporter{reporter: reporter}, true
+		}
+	}
+	return nil, false
+}
+
+// Formats lists the available formats, sorted
+func (r *ExportRegistry) Formats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool, len(r.exporters))
+	for format := range r.exporters {
+		seen[format] = true
+	}
+	if r.reporters != nil {
+		for _, reporter := range r.reporters.ListReporters() {
+			seen[reporter.Format()] = true
+		}
+	}
+
+	formats := make([]string, 0, len(seen))
+	for format := range seen {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// pluginExporter adapts a reporter plugin to an exporter
+type pluginExporter struct {
+	reporter plugins.ReporterPlugin
+}
+
+func (e *pluginExporter) Format() string      { return e.reporter.Format() }
+func (e *pluginExporter) Extension() string   { return e.reporter.Extension() }
+func (e *pluginExporter) ContentType() string { return e.reporter.ContentType() }
+
+func (e *pluginExporter) Export(ctx context.Context, run *TestRun) ([]byte, error) {
+	return e.reporter.Report(ctx, run)
+}
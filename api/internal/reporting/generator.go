@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/runner/snapshots"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
@@ -26,6 +28,10 @@ type Generator struct {
 	flowRepo   *repository.FlowRepository
 	logger     *zap.Logger
 	outputDir  string
+	exporters  *ExportRegistry
+
+	artifacts    *artifacts.Manager
+	artifactsURL string // Base URL of artifact download links
 }
 
 // NewGenerator creates a new report generator
@@ -46,7 +52,23 @@ func NewGenerator(
 		flowRepo:   flowRepo,
 		logger:     logger,
 		outputDir:  outputDir,
+		exporters:  NewExportRegistry(),
+	}
+}
+
+// Exporters returns the registry of result formats reports can be exported to
+func (g *Generator) Exporters() *ExportRegistry {
+	return g.exporters
+}
+
+// SupportsFormat reports whether reports can be generated in a format
+func (g *Generator) SupportsFormat(format models.ReportFormat) bool {
+	switch format {
+	case models.ReportFormatHTML, models.ReportFormatJSON, models.ReportFormatJUnit:
+		return true
 	}
+	_, ok := g.exporters.Get(string(format))
+	return ok
 }
 
 // ReportData holds aggregated data for report generation
@@ -62,6 +84,7 @@ type ReportData struct {
 	FlowResults []FlowResult            `json:"flow_results"`
 	FlakyTests  []FlakyTest             `json:"flaky_tests"`
 	TopFailures []StepFailure           `json:"top_failures"`
+	Snapshots   []PendingSnapshot       `json:"pending_snapshots,omitempty"`
 }
 
 // ReportSummary contains overall statistics
@@ -134,6 +157,16 @@ type StepFailure struct {
 	CommonError   string `json:"common_error"`
 }
 
+// PendingSnapshot holds a snapshot whose latest output differs from its golden record
+type PendingSnapshot struct {
+	SnapshotID string                  `json:"snapshot_id"`
+	FlowName   string                  `json:"flow_name"`
+	Name       string                  `json:"name"`
+	StepID     string                  `json:"step_id"`
+	Changes    []models.SnapshotChange `json:"changes"`
+	Summary    map[string]int          `json:"summary"`
+}
+
 // GenerateReport creates a report in the specified format
 func (g *Generator) GenerateReport(ctx context.Context, report *models.Report) error {
 	// Update status to generating
@@ -163,7 +196,7 @@ func (g *Generator) GenerateReport(ctx context.Context, report *models.Report) e
 	case models.ReportFormatJUnit:
 		filePath, fileSize, err = g.generateJUnit(data, report.ID.String())
 	default:
-		err = fmt.Errorf("unsupported report format: %s", report.Format)
+		filePath, fileSize, err = g.generateExport(ctx, report)
 	}
 
 	if err != nil {
@@ -273,6 +306,31 @@ func (g *Generator) collectReportData(ctx context.Context, report *models.Report
 		}
 	}
 
+	// Get snapshots awaiting review
+	var flowIDs []uuid.UUID
+	for _, id := range report.Filters.FlowIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			flowIDs = append(flowIDs, parsed)
+		}
+	}
+	pendingSnapshots, err := repository.NewSnapshotRepository(g.db).ListPending(flowIDs)
+	if err == nil {
+		for _, ps := range pendingSnapshots {
+			flowName := ""
+			if ps.Flow != nil {
+				flowName = ps.Flow.Name
+			}
+			data.Snapshots = append(data.Snapshots, PendingSnapshot{
+				SnapshotID: ps.ID.String(),
+				FlowName:   flowName,
+				Name:       ps.Name,
+				StepID:     ps.StepID,
+				Changes:    ps.PendingChanges,
+				Summary:    snapshots.Summary(ps.PendingChanges),
+			})
+		}
+	}
+
 	// Calculate summary
 	var avgDuration int64
 	if totalExecs > 0 {
@@ -475,6 +533,130 @@ func (g *Generator) generateJUnit(data *ReportData, reportID string) (string, in
 	return filePath, info.Size(), nil
 }
 
+// generateExport renders the executions in the report window with the
+// exporter of the report format, one test case per execution
+func (g *Generator) generateExport(ctx context.Context, report *models.Report) (string, int64, error) {
+	exporter, ok := g.exporters.Get(string(report.Format))
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported report format: %s", report.Format)
+	}
+
+	query := g.db.Preload("Flow").
+		Where("created_at >= ? AND created_at <= ? AND status IN ?",
+			report.StartDate, report.EndDate, []string{"completed", "failed"})
+	if len(report.Filters.Suites) > 0 {
+		query = query.Where("flow_id IN (?)", g.db.Table("flows.flows").Select("id").Where("suite IN ?", report.Filters.Suites))
+	}
+	if len(report.Filters.FlowIDs) > 0 {
+		query = query.Where("flow_id IN ?", report.Filters.FlowIDs)
+	}
+	if len(report.Filters.Environments) > 0 {
+		query = query.Where("environment IN ?", report.Filters.Environments)
+	}
+
+	var executions []models.Execution
+	if err := query.Order("created_at ASC").Find(&executions).Error; err != nil {
+		return "", 0, err
+	}
+
+	run, err := g.BuildTestRun(report.Name, executions)
+	if err != nil {
+		return "", 0, err
+	}
+	content, err := exporter.Export(ctx, run)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to export %s report: %w", exporter.Format(), err)
+	}
+
+	filePath := filepath.Join(g.outputDir, fmt.Sprintf("%s.%s", report.ID.String(), exporter.Extension()))
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return "", 0, err
+	}
+
+	info, _ := os.Stat(filePath)
+	return filePath, info.Size(), nil
+}
+
+// BuildTestRun builds the export model of executions, loading their steps
+func (g *Generator) BuildTestRun(name string, executions []models.Execution) (*TestRun, error) {
+	ids := make([]uuid.UUID, len(executions))
+	for i := range executions {
+		ids[i] = executions[i].ID
+	}
+	steps, err := g.execRepo.GetStepsForExecutions(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load execution steps: %w", err)
+	}
+
+	run := NewTestRun(name)
+	for i := range executions {
+		run.AddExecution(&executions[i], steps[executions[i].ID])
+	}
+	g.attachArtifacts(run, executions, ids)
+	return run, nil
+}
+
+// ExportExecution renders a single execution, following its reruns to the last attempt
+func (g *Generator) ExportExecution(ctx context.Context, execution *models.Execution, format string) ([]byte, Exporter, error) {
+	exporter, ok := g.exporters.Get(format)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	executions := []models.Execution{*execution}
+	if execution.Status == models.ExecutionStatusFailed {
+		if latest, err := g.execRepo.GetLatestRetry(execution.ID); err == nil && latest.ID != execution.ID {
+			if latest.Flow == nil {
+				latest.Flow = execution.Flow
+			}
+			executions = append(executions, *latest)
+		}
+	}
+
+	name := execution.ID.String()
+	if execution.Flow != nil {
+		name = execution.Flow.Name
+	}
+	run, err := g.BuildTestRun(name, executions)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := exporter.Export(ctx, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, exporter, nil
+}
+
+// ExportScheduleRun renders a schedule run with one test case per flow.
+// Flows that were skipped are reported as skipped.
+func (g *Generator) ExportScheduleRun(ctx context.Context, scheduleName string, scheduleRun *models.ScheduleRun, format string) ([]byte, Exporter, error) {
+	exporter, ok := g.exporters.Get(format)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	executions, err := g.execRepo.ListByScheduleRun(scheduleRun.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load schedule run executions: %w", err)
+	}
+	run, err := g.BuildTestRun(scheduleName, executions)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, fr := range scheduleRun.FlowResults {
+		if fr.ExecutionID == nil {
+			run.AddSkipped(fr.FlowID.String(), fr.FlowName, fr.Suite, fr.Error)
+		}
+	}
+
+	content, err := exporter.Export(ctx, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, exporter, nil
+}
+
 // GetReportFile retrieves the file content for a report
 func (g *Generator) GetReportFile(reportID uuid.UUID) ([]byte, string, error) {
 	report, err := g.reportRepo.GetReportByID(reportID)
@@ -500,6 +682,11 @@ func (g *Generator) GetReportFile(reportID uuid.UUID) ([]byte, string, error) {
 		contentType = "application/json"
 	case models.ReportFormatJUnit:
 		contentType = "application/xml"
+	default:
+		contentType = "application/octet-stream"
+		if exporter, ok := g.exporters.Get(string(report.Format)); ok {
+			contentType = exporter.ContentType()
+		}
 	}
 
 	return content, contentType, nil
@@ -519,6 +706,7 @@ type JUnitTestSuite struct {
 	Name      string          `xml:"name,attr"`
 	Tests     int             `xml:"tests,attr"`
 	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
 	Time      float64         `xml:"time,attr"`
 	TestCases []JUnitTestCase `xml:"testcase"`
 }
@@ -528,6 +716,11 @@ type JUnitTestCase struct {
 	Classname string        `xml:"classname,attr"`
 	Time      float64       `xml:"time,attr"`
 	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+type JUnitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
 }
 
 type JUnitFailure struct {
@@ -692,6 +885,34 @@ const htmlReportTemplate = `<!DOCTYPE html>
         </table>
         {{end}}
 
+        {{if .Snapshots}}
+        <h2>Snapshots Awaiting Review</h2>
+        {{range .Snapshots}}
+        <h3>{{.FlowName}} / {{.Name}}</h3>
+        <table>
+            <thead>
+                <tr>
+                    <th>Severity</th>
+                    <th>Change</th>
+                    <th>Path</th>
+                    <th>Description</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Changes}}
+                <tr>
+                    <td><span class="badge {{if eq .Severity "critical"}}failed{{else}}flaky{{end}}">{{.Severity}}</span></td>
+                    <td>{{.ChangeType}}</td>
+                    <td><code>{{.Details.Field}}</code></td>
+                    <td>{{.Description}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        <p class="meta">Snapshot ID: {{.SnapshotID}}</p>
+        {{end}}
+        {{end}}
+
         <footer>
             Generated by TestMesh | Report ID: {{.ReportID}}
         </footer>
@@ -2,7 +2,9 @@ package reporting
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
@@ -62,11 +64,21 @@ type ExecutionReport struct {
 
 // StepReport represents a step in the report
 type StepReport struct {
-	Name     string
-	Status   string
-	Duration int64
-	Error    string
-	Details  map[string]interface{}
+	Name      string
+	Status    string
+	Duration  int64
+	Error     string
+	Details   map[string]interface{}
+	Artifacts []ArtifactLink
+}
+
+// ArtifactLink is an artifact of a step. Screenshots with content are embedded
+// in PDF reports; everything else is linked.
+type ArtifactLink struct {
+	Name        string
+	ContentType string
+	URL         string
+	Content     []byte
 }
 
 // Generate generates a PDF report
@@ -91,6 +103,9 @@ func (g *PDFGenerator) Generate(report *ExecutionReport) ([]byte, error) {
 	// Errors section
 	g.renderErrors(pdf, report)
 
+	// Artifacts section
+	g.renderArtifacts(pdf, report)
+
 	// Footer
 	g.renderFooter(pdf)
 
@@ -280,6 +295,79 @@ func (g *PDFGenerator) renderErrors(pdf *gofpdf.Fpdf, report *ExecutionReport) {
 	}
 }
 
+func (g *PDFGenerator) renderArtifacts(pdf *gofpdf.Fpdf, report *ExecutionReport) {
+	var steps []StepReport
+	for _, step := range report.Steps {
+		if len(step.Artifacts) > 0 {
+			steps = append(steps, step)
+		}
+	}
+
+	if len(steps) == 0 {
+		return
+	}
+
+	if pdf.GetY() > 240 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Arial", "B", 14)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Cell(0, 10, "Artifacts")
+	pdf.Ln(12)
+
+	for _, step := range steps {
+		pdf.SetFont("Arial", "B", g.options.FontSize)
+		pdf.Cell(0, 6, step.Name)
+		pdf.Ln(7)
+
+		for i, artifact := range step.Artifacts {
+			imageType := pdfImageType(artifact.ContentType)
+			if imageType != "" && len(artifact.Content) > 0 {
+				name := fmt.Sprintf("%s-%s-%d", report.ID, step.Name, i)
+				info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(artifact.Content))
+				if pdf.Ok() {
+					// Scale to the page width, keeping the aspect ratio
+					width := 120.0
+					height := width * info.Height() / info.Width()
+					if pdf.GetY()+height > 270 {
+						pdf.AddPage()
+					}
+					pdf.ImageOptions(name, pdf.GetX(), pdf.GetY(), width, height, false, gofpdf.ImageOptions{ImageType: imageType}, 0, artifact.URL)
+					pdf.SetY(pdf.GetY() + height + 2)
+				} else {
+					// Undecodable images are linked instead
+					pdf.ClearError()
+				}
+			}
+
+			pdf.SetFont("Arial", "", g.options.FontSize-1)
+			if artifact.URL != "" {
+				pdf.SetTextColor(41, 128, 185)
+				pdf.CellFormat(0, 5, artifact.Name, "", 1, "L", false, 0, artifact.URL)
+				pdf.SetTextColor(0, 0, 0)
+			} else {
+				pdf.CellFormat(0, 5, artifact.Name, "", 1, "L", false, 0, "")
+			}
+		}
+		pdf.Ln(5)
+	}
+}
+
+// pdfImageType returns the gofpdf image type of a content type, or "" for
+// content that cannot be embedded
+func pdfImageType(contentType string) string {
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "image/png":
+		return "PNG"
+	case "image/jpeg":
+		return "JPG"
+	case "image/gif":
+		return "GIF"
+	}
+	return ""
+}
+
 func (g *PDFGenerator) renderFooter(pdf *gofpdf.Fpdf) {
 	pdf.SetY(-20)
 	pdf.SetFont("Arial", "I", 8)
@@ -306,6 +394,7 @@ func (g *PDFGenerator) GenerateMultiple(reports []*ExecutionReport) ([]byte, err
 		g.renderSummary(pdf, report)
 		g.renderStepsTable(pdf, report)
 		g.renderErrors(pdf, report)
+		g.renderArtifacts(pdf, report)
 	}
 
 	var buf bytes.Buffer
@@ -315,3 +404,59 @@ func (g *PDFGenerator) GenerateMultiple(reports []*ExecutionReport) ([]byte, err
 
 	return buf.Bytes(), nil
 }
+
+// PDFExporter renders a run as a PDF with one report per flow. Small
+// screenshots are embedded and other artifacts linked.
+type PDFExporter struct{}
+
+func (e *PDFExporter) Format() string      { return "pdf" }
+func (e *PDFExporter) Extension() string   { return "pdf" }
+func (e *PDFExporter) ContentType() string { return "application/pdf" }
+
+// Export renders the run as a PDF
+func (e *PDFExporter) Export(ctx context.Context, run *TestRun) ([]byte, error) {
+	options := DefaultPDFOptions()
+	options.Title = run.Name
+	return NewPDFGenerator(options).GenerateMultiple(ExecutionReportsFromRun(run))
+}
+
+// ExecutionReportsFromRun converts the cases of a run to execution reports,
+// for PDF generation and sharing
+func ExecutionReportsFromRun(run *TestRun) []*ExecutionReport {
+	reports := make([]*ExecutionReport, 0, len(run.Cases))
+	for _, tc := range run.Cases {
+		report := &ExecutionReport{
+			ID:          tc.ExecutionID,
+			FlowName:    tc.Name,
+			Status:      tc.Status,
+			StartTime:   tc.StartedAt,
+			EndTime:     tc.FinishedAt,
+			Duration:    tc.DurationMs,
+			Environment: tc.Environment,
+			Tags:        tc.Tags,
+			Metadata:    map[string]string{"suite": tc.Suite},
+		}
+		for _, ts := range tc.Steps {
+			step := StepReport{
+				Name:     ts.Name,
+				Status:   ts.Status,
+				Duration: ts.DurationMs,
+				Error:    ts.Message,
+			}
+			// Request and response bodies are left to the structured formats
+			for _, attachment := range ts.Attachments {
+				if attachment.URL != "" {
+					step.Artifacts = append(step.Artifacts, ArtifactLink{
+						Name:        attachment.Name,
+						ContentType: attachment.ContentType,
+						URL:         attachment.URL,
+						Content:     attachment.Content,
+					})
+				}
+			}
+			report.Steps = append(report.Steps, step)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
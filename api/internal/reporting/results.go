@@ -0,0 +1,311 @@
+package reporting
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Result statuses used by the export model
+const (
+	ResultPassed  = "passed"
+	ResultFailed  = "failed"
+	ResultSkipped = "skipped"
+)
+
+// TestRun is the format-independent model exporters render. It is built from
+// executions and their steps, with one test case per flow.
+type TestRun struct {
+	Name       string     `json:"name"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	DurationMs int64      `json:"duration_ms"`
+	Cases      []TestCase `json:"cases"`
+	Summary    RunCounts  `json:"summary"`
+
+	attempts map[string]int // First attempt ID to case index
+}
+
+// RunCounts holds the test case counts of a run
+type RunCounts struct {
+	Total       int `json:"total"`
+	Passed      int `json:"passed"`
+	Failed      int `json:"failed"`
+	Skipped     int `json:"skipped"`
+	Quarantined int `json:"quarantined"`
+	Flaky       int `json:"flaky"`
+}
+
+// TestCase is the result of one flow
+type TestCase struct {
+	ExecutionID string     `json:"execution_id,omitempty"`
+	FlowID      string     `json:"flow_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Suite       string     `json:"suite"`
+	Tags        []string   `json:"tags,omitempty"`
+	Environment string     `json:"environment,omitempty"`
+	Status      string     `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  time.Time  `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+	Retries     int        `json:"retries"`     // Reruns before the reported attempt
+	Flaky       bool       `json:"flaky"`       // Passed only after a rerun
+	Quarantined bool       `json:"quarantined"` // Failed only in quarantined flows or steps
+	Steps       []TestStep `json:"steps"`
+}
+
+// TestStep is the result of one step of a flow
+type TestStep struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Action      string           `json:"action"`
+	Status      string           `json:"status"`
+	Message     string           `json:"message,omitempty"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+	DurationMs  int64            `json:"duration_ms"`
+	Attempts    int              `json:"attempts"`
+	Attachments []TestAttachment `json:"attachments,omitempty"`
+}
+
+// TestAttachment is a file attached to a step, such as a request or response
+// body or a stored artifact. Artifacts too large to embed only have a URL.
+type TestAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+	URL         string `json:"url,omitempty"`
+}
+
+// NewTestRun creates an empty run
+func NewTestRun(name string) *TestRun {
+	return &TestRun{Name: name}
+}
+
+// AddExecution adds the result of an execution. Reruns of a failed execution
+// replace the earlier attempt, so every run of a flow is reported once with
+// its last attempt.
+func (r *TestRun) AddExecution(execution *models.Execution, steps []models.ExecutionStep) {
+	tc := testCaseFromExecution(execution, steps)
+
+	firstAttempt := execution.ID.String()
+	if execution.RetryOf != nil {
+		firstAttempt = execution.RetryOf.String()
+	}
+	if r.attempts == nil {
+		r.attempts = make(map[string]int)
+	}
+
+	if i, ok := r.attempts[firstAttempt]; ok {
+		existing := &r.Cases[i]
+		tc.StartedAt = earliest(existing.StartedAt, tc.StartedAt)
+		if tc.Retries > existing.Retries {
+			*existing = tc
+		} else {
+			existing.StartedAt = tc.StartedAt
+		}
+	} else {
+		r.attempts[firstAttempt] = len(r.Cases)
+		r.Cases = append(r.Cases, tc)
+	}
+	r.refresh()
+}
+
+// AddSkipped adds a flow that did not run
+func (r *TestRun) AddSkipped(flowID, name, suite, reason string) {
+	r.Cases = append(r.Cases, TestCase{
+		FlowID:  flowID,
+		Name:    name,
+		Suite:   suite,
+		Status:  ResultSkipped,
+		Message: reason,
+	})
+	r.refresh()
+}
+
+// refresh recomputes the run timing and counts from its cases
+func (r *TestRun) refresh() {
+	r.Summary = RunCounts{}
+	r.StartedAt, r.FinishedAt = time.Time{}, time.Time{}
+
+	for _, tc := range r.Cases {
+		r.Summary.Total++
+		switch {
+		case tc.Status == ResultPassed:
+			r.Summary.Passed++
+		case tc.Status == ResultSkipped:
+			r.Summary.Skipped++
+		case tc.Quarantined:
+			r.Summary.Quarantined++
+		default:
+			r.Summary.Failed++
+		}
+		if tc.Flaky {
+			r.Summary.Flaky++
+		}
+
+		if !tc.StartedAt.IsZero() {
+			r.StartedAt = earliest(r.StartedAt, tc.StartedAt)
+		}
+		if tc.FinishedAt.After(r.FinishedAt) {
+			r.FinishedAt = tc.FinishedAt
+		}
+	}
+
+	r.DurationMs = 0
+	if !r.StartedAt.IsZero() && r.FinishedAt.After(r.StartedAt) {
+		r.DurationMs = r.FinishedAt.Sub(r.StartedAt).Milliseconds()
+	}
+}
+
+func testCaseFromExecution(execution *models.Execution, steps []models.ExecutionStep) TestCase {
+	tc := TestCase{
+		ExecutionID: execution.ID.String(),
+		FlowID:      execution.FlowID.String(),
+		Environment: execution.Environment,
+		Message:     execution.Error,
+		DurationMs:  execution.DurationMs,
+		Retries:     execution.Attempt - 1,
+		Quarantined: execution.Quarantined,
+		StartedAt:   execution.CreatedAt,
+		FinishedAt:  execution.UpdatedAt,
+	}
+	if tc.Retries < 0 {
+		tc.Retries = 0
+	}
+	if execution.StartedAt != nil {
+		tc.StartedAt = *execution.StartedAt
+	}
+	if execution.FinishedAt != nil {
+		tc.FinishedAt = *execution.FinishedAt
+	}
+
+	definitions := make(map[string]models.Step)
+	if flow := execution.Flow; flow != nil {
+		tc.Name = flow.Name
+		tc.Description = flow.Description
+		tc.Suite = flow.Suite
+		tc.Tags = append(tc.Tags, flow.Tags...)
+		for _, list := range [][]models.Step{flow.Definition.Setup, flow.Definition.Steps, flow.Definition.Teardown} {
+			for _, step := range list {
+				definitions[step.ID] = step
+			}
+		}
+	}
+	if tc.Name == "" {
+		tc.Name = execution.FlowID.String()
+	}
+	if tc.Suite == "" {
+		tc.Suite = "default"
+	}
+
+	switch execution.Status {
+	case models.ExecutionStatusCompleted:
+		tc.Status = ResultPassed
+		tc.Flaky = tc.Retries > 0
+	case models.ExecutionStatusFailed:
+		tc.Status = ResultFailed
+	default:
+		tc.Status = ResultSkipped
+		if tc.Message == "" {
+			tc.Message = "Execution " + string(execution.Status)
+		}
+	}
+
+	for _, step := range steps {
+		tc.Steps = append(tc.Steps, testStepFromExecution(step, definitions[step.StepID]))
+	}
+	return tc
+}
+
+func testStepFromExecution(step models.ExecutionStep, definition models.Step) TestStep {
+	ts := TestStep{
+		ID:         step.StepID,
+		Name:       step.StepName,
+		Action:     step.Action,
+		Message:    step.ErrorMessage,
+		DurationMs: step.DurationMs,
+		Attempts:   step.Attempt,
+		StartedAt:  step.CreatedAt,
+		FinishedAt: step.UpdatedAt,
+	}
+	if ts.Name == "" {
+		ts.Name = step.StepID
+	}
+	if step.StartedAt != nil {
+		ts.StartedAt = *step.StartedAt
+	}
+	if step.FinishedAt != nil {
+		ts.FinishedAt = *step.FinishedAt
+	}
+
+	switch step.Status {
+	case models.StepStatusCompleted:
+		ts.Status = ResultPassed
+	case models.StepStatusFailed:
+		ts.Status = ResultFailed
+	default:
+		ts.Status = ResultSkipped
+	}
+
+	if request := requestAttachment(definition); request != nil {
+		ts.Attachments = append(ts.Attachments, *request)
+	}
+	if response := responseAttachment(step.Output); response != nil {
+		ts.Attachments = append(ts.Attachments, *response)
+	}
+	return ts
+}
+
+// requestAttachment describes the request a step sends, as configured in the
+// flow. Templates in the configuration are not resolved.
+func requestAttachment(definition models.Step) *TestAttachment {
+	config := definition.Config
+	if len(config) == 0 {
+		return nil
+	}
+	request := make(map[string]interface{})
+	for _, key := range []string{"method", "url", "headers", "query", "params", "body", "variables", "topic", "key", "payload"} {
+		if value, ok := config[key]; ok {
+			request[key] = value
+		}
+	}
+	if len(request) == 0 {
+		return nil
+	}
+	return jsonAttachment("Request", request)
+}
+
+// responseAttachment holds the stored output of a step
+func responseAttachment(output models.OutputData) *TestAttachment {
+	if len(output) == 0 {
+		return nil
+	}
+	return jsonAttachment("Response", output)
+}
+
+func jsonAttachment(name string, value interface{}) *TestAttachment {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return &TestAttachment{Name: name, ContentType: "application/json", Content: content}
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// unixMillis returns t in milliseconds since the epoch, or 0 when unset
+func unixMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
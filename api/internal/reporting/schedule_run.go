@@ -0,0 +1,68 @@
+package reporting
+
+import (
+	"encoding/xml"
+	"sort"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// ScheduleRunJUnit renders the flow results of a schedule run as JUnit XML,
+// with one test case per flow grouped into test suites by flow suite
+func ScheduleRunJUnit(scheduleName string, run *models.ScheduleRun) ([]byte, error) {
+	testSuites := JUnitTestSuites{
+		Name:     scheduleName,
+		Tests:    run.TotalFlows,
+		Failures: run.FailedFlows,
+		Time:     float64(run.Duration) / 1000.0,
+	}
+
+	suiteIndex := make(map[string]int)
+	for _, fr := range run.FlowResults {
+		suiteName := fr.Suite
+		if suiteName == "" {
+			suiteName = scheduleName
+		}
+
+		i, ok := suiteIndex[suiteName]
+		if !ok {
+			i = len(testSuites.TestSuites)
+			suiteIndex[suiteName] = i
+			testSuites.TestSuites = append(testSuites.TestSuites, JUnitTestSuite{Name: suiteName})
+		}
+		suite := &testSuites.TestSuites[i]
+
+		testCase := JUnitTestCase{
+			Name:      fr.FlowName,
+			Classname: suiteName,
+			Time:      float64(fr.DurationMs) / 1000.0,
+		}
+
+		switch fr.Status {
+		case "failed":
+			testCase.Failure = &JUnitFailure{
+				Message: fr.Error,
+				Type:    "ExecutionFailure",
+				Content: fr.Error,
+			}
+			suite.Failures++
+		case "skipped", "quarantined":
+			testCase.Skipped = &JUnitSkipped{Message: fr.Error}
+			suite.Skipped++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suite.Time += testCase.Time
+	}
+
+	sort.SliceStable(testSuites.TestSuites, func(a, b int) bool {
+		return testSuites.TestSuites[a].Name < testSuites.TestSuites[b].Name
+	})
+
+	xmlBytes, err := xml.MarshalIndent(testSuites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(xml.Header + string(xmlBytes)), nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -102,6 +103,17 @@ func (s *Sharer) ShareToSlack(report *ExecutionReport, pdfData []byte) error {
 		},
 	}
 
+	if links := artifactMarkdown(report, "<%s|%s>"); links != "" {
+		attachment := message["attachments"].([]map[string]interface{})[0]
+		attachment["blocks"] = append(attachment["blocks"].([]map[string]interface{}), map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": "*Artifacts:*\n" + links,
+			},
+		})
+	}
+
 	if s.config.SlackChannel != "" {
 		message["channel"] = s.config.SlackChannel
 	}
@@ -165,6 +177,13 @@ func (s *Sharer) ShareToTeams(report *ExecutionReport) error {
 			},
 		},
 	}
+	if links := artifactMarkdown(report, "[%[2]s](%[1]s)"); links != "" {
+		card["sections"] = append(card["sections"].([]map[string]interface{}), map[string]interface{}{
+			"title":    "Artifacts",
+			"text":     links,
+			"markdown": true,
+		})
+	}
 
 	body, err := json.Marshal(card)
 	if err != nil {
@@ -303,6 +322,7 @@ func (s *Sharer) buildEmailHTML(report *ExecutionReport) string {
 		<tr><th>#</th><th>Step</th><th>Status</th><th>Duration</th></tr>
 		%s
 	</table>
+	%s
 	<hr>
 	<p style="color: #888; font-size: 12px;">Generated by TestMesh</p>
 </body>
@@ -310,5 +330,49 @@ func (s *Sharer) buildEmailHTML(report *ExecutionReport) string {
 		statusColor, report.FlowName, report.Status, report.Duration,
 		passedCount, failedCount, len(report.Steps),
 		report.ID, report.StartTime.Format(time.RFC3339),
-		stepsHTML.String())
+		stepsHTML.String(), artifactsHTML(report))
+}
+
+// maxSharedArtifacts caps the artifact links included in chat messages
+const maxSharedArtifacts = 10
+
+// artifactMarkdown lists links to the artifacts of a report, one per line,
+// formatting each with the URL and name. Empty when there are none.
+func artifactMarkdown(report *ExecutionReport, format string) string {
+	var lines []string
+	total := 0
+	for _, step := range report.Steps {
+		for _, artifact := range step.Artifacts {
+			if artifact.URL == "" {
+				continue
+			}
+			total++
+			if len(lines) < maxSharedArtifacts {
+				lines = append(lines, "• "+fmt.Sprintf(format, artifact.URL, step.Name+": "+artifact.Name))
+			}
+		}
+	}
+	if total > len(lines) {
+		lines = append(lines, fmt.Sprintf("and %d more", total-len(lines)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// artifactsHTML lists links to the artifacts of a report for emails
+func artifactsHTML(report *ExecutionReport) string {
+	var items strings.Builder
+	for _, step := range report.Steps {
+		for _, artifact := range step.Artifacts {
+			if artifact.URL == "" {
+				continue
+			}
+			items.WriteString(fmt.Sprintf(`
+		<li>%s: <a href="%s">%s</a></li>`,
+				html.EscapeString(step.Name), html.EscapeString(artifact.URL), html.EscapeString(artifact.Name)))
+		}
+	}
+	if items.Len() == 0 {
+		return ""
+	}
+	return "<h2>Artifacts</h2>\n\t<ul>" + items.String() + "\n\t</ul>"
 }
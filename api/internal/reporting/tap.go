@@ -0,0 +1,95 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TAPExporter writes Test Anything Protocol version 14. Every flow is a test
+// point with its steps as a subtest. Quarantined failures are marked TODO, so
+// TAP consumers don't count them as failures.
+type TAPExporter struct{}
+
+func (e *TAPExporter) Format() string      { return "tap" }
+func (e *TAPExporter) Extension() string   { return "tap" }
+func (e *TAPExporter) ContentType() string { return "text/plain; charset=utf-8" }
+
+// Export renders the run as TAP
+func (e *TAPExporter) Export(ctx context.Context, run *TestRun) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("TAP version 14\n")
+	fmt.Fprintf(&b, "1..%d\n", len(run.Cases))
+
+	for i, tc := range run.Cases {
+		if len(tc.Steps) > 0 {
+			fmt.Fprintf(&b, "# Subtest: %s\n", tapEscape(tc.Name))
+			fmt.Fprintf(&b, "    1..%d\n", len(tc.Steps))
+			for j, ts := range tc.Steps {
+				writeTAPPoint(&b, "    ", j+1, ts.Name, ts.Status, "", map[string]interface{}{
+					"action":      ts.Action,
+					"message":     ts.Message,
+					"duration_ms": ts.DurationMs,
+				})
+			}
+		}
+
+		directive := ""
+		switch {
+		case tc.Status == ResultSkipped:
+			directive = "SKIP " + tc.Message
+		case tc.Status == ResultFailed && tc.Quarantined:
+			directive = "TODO quarantined"
+		}
+		writeTAPPoint(&b, "", i+1, tc.Suite+" / "+tc.Name, tc.Status, directive, map[string]interface{}{
+			"message":      tc.Message,
+			"duration_ms":  tc.DurationMs,
+			"execution_id": tc.ExecutionID,
+			"retries":      tc.Retries,
+		})
+	}
+
+	fmt.Fprintf(&b, "# passed %d, failed %d, skipped %d, quarantined %d\n",
+		run.Summary.Passed, run.Summary.Failed, run.Summary.Skipped, run.Summary.Quarantined)
+	return []byte(b.String()), nil
+}
+
+// writeTAPPoint writes a test point, with a YAML diagnostic block for failures
+func writeTAPPoint(b *strings.Builder, indent string, number int, description, status, directive string, diagnostics map[string]interface{}) {
+	result := "ok"
+	if status == ResultFailed {
+		result = "not ok"
+	}
+	if status == ResultSkipped && directive == "" {
+		directive = "SKIP"
+	}
+
+	fmt.Fprintf(b, "%s%s %d - %s", indent, result, number, tapEscape(description))
+	if directive != "" {
+		fmt.Fprintf(b, " # %s", tapEscape(strings.TrimSpace(directive)))
+	}
+	b.WriteString("\n")
+
+	if status != ResultFailed {
+		return
+	}
+	fmt.Fprintf(b, "%s  ---\n", indent)
+	for _, key := range []string{"message", "action", "duration_ms", "execution_id", "retries"} {
+		value, ok := diagnostics[key]
+		if !ok || value == "" {
+			continue
+		}
+		// JSON scalars are valid YAML flow scalars
+		encoded, _ := json.Marshal(value)
+		fmt.Fprintf(b, "%s  %s: %s\n", indent, key, encoded)
+	}
+	fmt.Fprintf(b, "%s  ...\n", indent)
+}
+
+// tapEscape keeps a description on one line and escapes the directive marker
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "#", "\\#")
+	return strings.Join(strings.Fields(s), " ")
+}
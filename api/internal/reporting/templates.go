@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"strings"
+	texttemplate "text/template"
 	"time"
 )
 
 // TemplateEngine handles custom report templates
 type TemplateEngine struct {
-	templates map[string]*template.Template
-	funcMap   template.FuncMap
+	templates     map[string]*template.Template
+	textTemplates map[string]*texttemplate.Template
+	funcMap       template.FuncMap
 }
 
 // NewTemplateEngine creates a new template engine
@@ -23,6 +26,7 @@ func NewTemplateEngine() *TemplateEngine {
 		"percentage":     percentage,
 		"statusClass":    statusClass,
 		"statusIcon":     statusIcon,
+		"changeIcon":     changeIcon,
 		"truncate":       truncateString,
 		"json":           jsonString,
 		"upper":          strings.ToUpper,
@@ -46,8 +50,9 @@ func NewTemplateEngine() *TemplateEngine {
 	}
 
 	return &TemplateEngine{
-		templates: make(map[string]*template.Template),
-		funcMap:   funcMap,
+		templates:     make(map[string]*template.Template),
+		textTemplates: make(map[string]*texttemplate.Template),
+		funcMap:       funcMap,
 	}
 }
 
@@ -61,10 +66,27 @@ func (e *TemplateEngine) RegisterTemplate(name, content string) error {
 	return nil
 }
 
+// RegisterTextTemplate registers a named template that is rendered without HTML
+// escaping, for Markdown and plain text output
+func (e *TemplateEngine) RegisterTextTemplate(name, content string) error {
+	tmpl, err := texttemplate.New(name).Funcs(texttemplate.FuncMap(e.funcMap)).Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	e.textTemplates[name] = tmpl
+	return nil
+}
+
 // Render renders a template with data
 func (e *TemplateEngine) Render(name string, data interface{}) (string, error) {
-	tmpl, ok := e.templates[name]
-	if !ok {
+	var tmpl interface {
+		Execute(w io.Writer, data interface{}) error
+	}
+	if t, ok := e.templates[name]; ok {
+		tmpl = t
+	} else if t, ok := e.textTemplates[name]; ok {
+		tmpl = t
+	} else {
 		return "", fmt.Errorf("template not found: %s", name)
 	}
 
@@ -143,6 +165,21 @@ func statusIcon(status string) string {
 	}
 }
 
+func changeIcon(change string) string {
+	switch change {
+	case ChangeNewlyFailing:
+		return "🔴"
+	case ChangeNewlyPassing:
+		return "🟢"
+	case ChangeStillFailing:
+		return "🟠"
+	case ChangeSkipped, ChangeAdded, ChangeRemoved:
+		return "⚪"
+	default:
+		return "🔵"
+	}
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -306,6 +343,9 @@ const HTMLReportTemplate = `
 				<div class="step-name">{{.Name}}</div>
 				<div class="step-duration">{{formatDuration .Duration}}</div>
 			</div>
+			{{range .Artifacts}}{{if .URL}}
+			<div class="step-artifact"><a href="{{.URL}}">{{.Name}}</a></div>
+			{{end}}{{end}}
 			{{end}}
 		</div>
 
@@ -342,6 +382,15 @@ const MarkdownReportTemplate = `
 | {{add $i 1}} | {{$step.Name}} | {{statusIcon $step.Status}} {{$step.Status}} | {{formatDuration $step.Duration}} |
 {{end}}
 
+{{- $artifacts := false}}{{range .Steps}}{{if .Artifacts}}{{$artifacts = true}}{{end}}{{end}}
+{{if $artifacts}}
+## Artifacts
+
+{{range .Steps}}{{$name := .Name}}{{range .Artifacts}}{{if .URL}}
+- {{$name}}: [{{.Name}}]({{.URL}})
+{{- end}}{{end}}{{end}}
+{{end}}
+
 {{if .Errors}}
 ## Errors
 
@@ -409,10 +458,11 @@ type ExecutionReportData struct {
 
 // StepData holds step data for templates
 type StepData struct {
-	Name     string
-	Status   string
-	Duration int64
-	Error    string
+	Name      string
+	Status    string
+	Duration  int64
+	Error     string
+	Artifacts []ArtifactLink
 }
 
 // ErrorData holds error data for templates
@@ -441,10 +491,11 @@ func BuildExecutionReportData(report *ExecutionReport) *ExecutionReportData {
 
 	for i, step := range report.Steps {
 		data.Steps[i] = StepData{
-			Name:     step.Name,
-			Status:   step.Status,
-			Duration: step.Duration,
-			Error:    step.Error,
+			Name:      step.Name,
+			Status:    step.Status,
+			Duration:  step.Duration,
+			Error:     step.Error,
+			Artifacts: step.Artifacts,
 		}
 
 		if step.Status == "passed" {
@@ -463,3 +514,145 @@ func BuildExecutionReportData(report *ExecutionReport) *ExecutionReportData {
 
 	return data
 }
+
+const ComparisonMarkdownTemplate = `# {{.Title}}
+
+| | Base | Head |
+|---|---|---|
+| Run | {{.Base.Name}} | {{.Head.Name}} |
+| Status | {{.Base.Status}} | {{.Head.Status}} |
+| Flows passed | {{.Base.Passed}}/{{.Base.Flows}} | {{.Head.Passed}}/{{.Head.Flows}} |
+
+## Summary
+
+| Change | Count |
+|--------|-------|
+| Newly failing | {{.Summary.NewlyFailing}} |
+| Newly passing | {{.Summary.NewlyPassing}} |
+| Still failing | {{.Summary.StillFailing}} |
+| Latency regressions | {{.Summary.LatencyRegressions}} |
+| Changed errors | {{.Summary.ErrorChanges}} |
+| Response shape changes | {{.Summary.ShapeChanges}} |
+{{- if or .Summary.Added .Summary.Removed}}
+| Added / removed flows | {{.Summary.Added}} / {{.Summary.Removed}} |
+{{- end}}
+{{range .Flows}}{{if .Changed}}
+### {{changeIcon .Change}} {{.FlowName}}
+
+**{{.Change}}**: {{default "-" .BaseStatus}} → {{default "-" .HeadStatus}} ({{formatDuration .BaseDurationMs}} → {{formatDuration .HeadDurationMs}})
+{{- if .ErrorChanged}}
+
+- Before: ` + "`" + `{{.BaseError}}` + "`" + `
+- After: ` + "`" + `{{.HeadError}}` + "`" + `
+{{- else if and .HeadError (eq .Change "newly_failing")}}
+
+Error: ` + "`" + `{{.HeadError}}` + "`" + `
+{{- end}}
+{{- if .Steps}}
+
+| Step | Change | Duration | Details |
+|------|--------|----------|---------|
+{{- range .Steps}}
+| {{default .StepID .StepName}} | {{.Change}} | {{formatDuration .BaseDurationMs}} → {{formatDuration .HeadDurationMs}} | {{replace .Details "|" "\\|"}} |
+{{- end}}
+{{- end}}
+{{end}}{{end}}
+---
+*Generated by TestMesh on {{formatTime .GeneratedAt}}*
+`
+
+const ComparisonHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+	<title>{{.Title}} - Run Comparison</title>
+	<style>
+		:root {
+			--success: #27ae60;
+			--danger: #e74c3c;
+			--warning: #f39c12;
+			--info: #3498db;
+		}
+		body {
+			font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+			margin: 0;
+			padding: 20px;
+			background: #f5f5f5;
+		}
+		.container { max-width: 1200px; margin: 0 auto; }
+		.header {
+			background: {{if .Summary.Regressed}}var(--danger){{else}}var(--success){{end}};
+			color: white;
+			padding: 30px;
+			border-radius: 8px;
+			margin-bottom: 20px;
+		}
+		.header h1 { margin: 0 0 10px 0; }
+		.summary {
+			display: grid;
+			grid-template-columns: repeat(auto-fit, minmax(160px, 1fr));
+			gap: 20px;
+			margin-bottom: 20px;
+		}
+		.card {
+			background: white;
+			padding: 20px;
+			border-radius: 8px;
+			box-shadow: 0 1px 3px rgba(0,0,0,0.1);
+			margin-bottom: 20px;
+		}
+		.card-title { font-size: 14px; color: #666; margin-bottom: 10px; }
+		.card-value { font-size: 24px; font-weight: bold; }
+		table { width: 100%; border-collapse: collapse; }
+		th, td { text-align: left; padding: 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+		.newly_failing { color: var(--danger); }
+		.newly_passing { color: var(--success); }
+		.still_failing, .skipped { color: var(--warning); }
+		code { background: #f0f0f0; padding: 2px 4px; border-radius: 3px; }
+	</style>
+</head>
+<body>
+	<div class="container">
+		<div class="header">
+			<h1>{{.Title}}</h1>
+			<div>{{.Base.Name}} ({{.Base.Passed}}/{{.Base.Flows}} passed) → {{.Head.Name}} ({{.Head.Passed}}/{{.Head.Flows}} passed)</div>
+		</div>
+
+		<div class="summary">
+			<div class="card"><div class="card-title">Newly failing</div><div class="card-value newly_failing">{{.Summary.NewlyFailing}}</div></div>
+			<div class="card"><div class="card-title">Newly passing</div><div class="card-value newly_passing">{{.Summary.NewlyPassing}}</div></div>
+			<div class="card"><div class="card-title">Still failing</div><div class="card-value still_failing">{{.Summary.StillFailing}}</div></div>
+			<div class="card"><div class="card-title">Latency regressions</div><div class="card-value">{{.Summary.LatencyRegressions}}</div></div>
+			<div class="card"><div class="card-title">Changed errors</div><div class="card-value">{{.Summary.ErrorChanges}}</div></div>
+			<div class="card"><div class="card-title">Shape changes</div><div class="card-value">{{.Summary.ShapeChanges}}</div></div>
+		</div>
+
+		{{range .Flows}}{{if .Changed}}
+		<div class="card">
+			<h3 class="{{.Change}}">{{changeIcon .Change}} {{.FlowName}}</h3>
+			<p><strong>{{.Change}}</strong>: {{default "-" .BaseStatus}} → {{default "-" .HeadStatus}} ({{formatDuration .BaseDurationMs}} → {{formatDuration .HeadDurationMs}})</p>
+			{{if .ErrorChanged}}
+			<p>Before: <code>{{.BaseError}}</code><br>After: <code>{{.HeadError}}</code></p>
+			{{else if and .HeadError (eq .Change "newly_failing")}}
+			<p>Error: <code>{{.HeadError}}</code></p>
+			{{end}}
+			{{if .Steps}}
+			<table>
+				<tr><th>Step</th><th>Change</th><th>Duration</th><th>Details</th></tr>
+				{{range .Steps}}
+				<tr>
+					<td>{{default .StepID .StepName}}</td>
+					<td class="{{.Change}}">{{.Change}}</td>
+					<td>{{formatDuration .BaseDurationMs}} → {{formatDuration .HeadDurationMs}}</td>
+					<td>{{.Details}}</td>
+				</tr>
+				{{end}}
+			</table>
+			{{end}}
+		</div>
+		{{end}}{{end}}
+
+		<p style="color: #999; font-size: 12px;">Generated by TestMesh on {{formatTime .GeneratedAt}}</p>
+	</div>
+</body>
+</html>
+`
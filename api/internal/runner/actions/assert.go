@@ -22,6 +22,18 @@ func NewAssertHandler(logger *zap.Logger) *AssertHandler {
 	}
 }
 
+// ConfigSchema describes the config of assert steps
+func (h *AssertHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "assert",
+		Description: "Evaluate assertions against data",
+		Schema: object([]string{"data", "assertions"}, map[string]interface{}{
+			"data":       anything("Data to assert on"),
+			"assertions": typed("Assertion expressions", "array", "string"),
+		}),
+	}
+}
+
 // Execute runs assertions against provided data
 func (h *AssertHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Get data to assert against
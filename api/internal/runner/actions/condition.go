@@ -28,6 +28,17 @@ func NewConditionHandler(logger *zap.Logger, executor StepExecutor) *ConditionHa
 	}
 }
 
+// ConfigSchema describes the config of condition steps
+func (h *ConditionHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "condition",
+		Description: "Evaluate a condition expression",
+		Schema: object([]string{"condition"}, map[string]interface{}{
+			"condition": str("Expression"),
+		}),
+	}
+}
+
 // Execute evaluates a condition and executes appropriate branch
 func (h *ConditionHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Get condition expression
@@ -27,8 +27,25 @@ func NewContractGenerateHandler(generator *contracts.Generator, execRepo *reposi
 	}
 }
 
+// ConfigSchema describes the config of contract_generate steps
+func (h *ContractGenerateHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "contract_generate",
+		Description: "Generate a consumer contract from the HTTP steps of the execution",
+		Schema: object([]string{"consumer", "provider", "version"}, map[string]interface{}{
+			"consumer":    str("Consumer name"),
+			"provider":    str("Provider name"),
+			"version":     str("Contract version"),
+			"export_json": typed("Include the Pact JSON in the output", "boolean"),
+		}),
+	}
+}
+
 // Execute generates a contract from the current execution
 func (h *ContractGenerateHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
+	if h.generator == nil {
+		return nil, fmt.Errorf("contract repository not initialized")
+	}
 	// Extract configuration
 	consumer, ok := config["consumer"].(string)
 	if !ok {
@@ -127,8 +144,26 @@ func NewContractVerifyHandler(verifier *contracts.Verifier, differ *contracts.Di
 	}
 }
 
+// ConfigSchema describes the config of contract_verify steps
+func (h *ContractVerifyHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "contract_verify",
+		Description: "Verify a provider against a contract",
+		Schema: object([]string{"contract_id", "provider_base_url"}, map[string]interface{}{
+			"contract_id":          str("Contract ID"),
+			"provider_base_url":    str("Base URL of the provider"),
+			"provider_version":     str("Provider version"),
+			"state_setup_url":      str("URL that sets up provider states"),
+			"previous_contract_id": str("Contract to report breaking changes against"),
+		}),
+	}
+}
+
 // Execute verifies a provider against a contract
 func (h *ContractVerifyHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
+	if h.verifier == nil {
+		return nil, fmt.Errorf("contract repository not initialized")
+	}
 	// Extract configuration
 	contractIDStr, ok := config["contract_id"].(string)
 	if !ok {
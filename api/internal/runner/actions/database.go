@@ -24,6 +24,19 @@ func NewDatabaseHandler(logger *zap.Logger) *DatabaseHandler {
 	}
 }
 
+// ConfigSchema describes the config of database_query steps
+func (h *DatabaseHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "database_query",
+		Description: "Run a SQL query",
+		Schema: object([]string{"query", "connection"}, map[string]interface{}{
+			"query":      str("SQL query"),
+			"connection": str("Connection string"),
+			"params":     interpolated(map[string]interface{}{"type": []interface{}{"array", "object"}, "description": "Query parameters"}),
+		}),
+	}
+}
+
 // Execute executes a database query action
 func (h *DatabaseHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Extract configuration
@@ -84,6 +97,33 @@ func (h *DatabaseHandler) Execute(ctx context.Context, config map[string]interfa
 	}
 }
 
+// QueryReadOnly runs a SELECT query with bound parameters in a read-only
+// transaction, which is rolled back, and returns its rows
+func (h *DatabaseHandler) QueryReadOnly(ctx context.Context, dsn, query string, params []interface{}) ([]map[string]interface{}, error) {
+	db, err := h.connect(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	defer sqlDB.Close()
+
+	tx := db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start read-only transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	output, err := h.executeSelect(tx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := output["rows"].([]map[string]interface{})
+	return rows, nil
+}
+
 // connect establishes a database connection
 func (h *DatabaseHandler) connect(dsn string) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
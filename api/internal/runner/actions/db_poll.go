@@ -19,6 +19,23 @@ func NewDBPollHandler(logger *zap.Logger) *DBPollHandler {
 	return &DBPollHandler{logger: logger}
 }
 
+// ConfigSchema describes the config of db_poll steps
+func (h *DBPollHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "db_poll",
+		Description: "Poll a database query until a condition holds",
+		Schema: object([]string{"connection", "query"}, map[string]interface{}{
+			"connection":   str("Connection string"),
+			"query":        str("SQL query"),
+			"params":       typed("Query parameters", "array"),
+			"condition":    anything("Condition on the query result"),
+			"timeout":      str("Give up after this duration"),
+			"interval":     str("Time between attempts"),
+			"max_attempts": typed("Most attempts", "integer"),
+		}),
+	}
+}
+
 // Execute polls a database query until a condition is satisfied or timeout is reached.
 func (h *DBPollHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	cfg := &async.DBPollingConfig{
@@ -21,6 +21,17 @@ func NewDelayHandler(logger *zap.Logger) *DelayHandler {
 	}
 }
 
+// ConfigSchema describes the config of delay steps
+func (h *DelayHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "delay",
+		Description: "Wait for a duration",
+		Schema: object([]string{"duration"}, map[string]interface{}{
+			"duration": str("Duration, e.g. 500ms or 5s"),
+		}),
+	}
+}
+
 // Execute waits for the specified duration
 func (h *DelayHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	durationStr, ok := config["duration"].(string)
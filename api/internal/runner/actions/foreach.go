@@ -22,6 +22,18 @@ func NewForEachHandler(logger *zap.Logger, executor StepExecutor) *ForEachHandle
 	}
 }
 
+// ConfigSchema describes the config of for_each steps
+func (h *ForEachHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "for_each",
+		Description: "Iterate over items",
+		Schema: object([]string{"items"}, map[string]interface{}{
+			"items":     typed("Items to iterate over", "array"),
+			"item_name": str("Variable name of the current item (default: item)"),
+		}),
+	}
+}
+
 // Execute iterates over items and executes nested steps for each
 func (h *ForEachHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Get items to iterate over
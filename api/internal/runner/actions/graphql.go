@@ -0,0 +1,659 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// GraphQL over WebSocket subprotocols
+const (
+	GraphQLTransportWS = "graphql-transport-ws" // graphql-ws library
+	GraphQLLegacyWS    = "graphql-ws"           // subscriptions-transport-ws (legacy)
+)
+
+// GraphQLIntrospectionQuery is the standard introspection query used by the
+// introspection mode and the GraphQL importer
+const GraphQLIntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+// GraphQLHandler handles GraphQL queries, mutations and subscriptions
+type GraphQLHandler struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(logger *zap.Logger) *GraphQLHandler {
+	return &GraphQLHandler{
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// ConfigSchema describes the config of graphql steps
+func (h *GraphQLHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "graphql",
+		Description: "Send a GraphQL query, mutation or subscription",
+		Schema: object([]string{"url"}, map[string]interface{}{
+			"url":               str("GraphQL endpoint"),
+			"query":             str("Query document"),
+			"query_file":        str("File with the query document"),
+			"fragments":         typed("Fragment files", "array", "string"),
+			"variables":         typed("Query variables", "object"),
+			"operation_name":    str("Operation to run"),
+			"headers":           stringMap("Request headers"),
+			"fail_on_errors":    typed("Fail when the response has errors (default: true)", "boolean"),
+			"introspection":     typed("Run an introspection query", "boolean"),
+			"timeout":           str("Timeout"),
+			"ws_url":            str("WebSocket endpoint for subscriptions"),
+			"protocol":          str("Subscription protocol"),
+			"connection_params": typed("Subscription connection parameters", "object"),
+			"count":             typed("Subscription events to receive", "integer"),
+		}),
+	}
+}
+
+// GraphQLConfig represents GraphQL action configuration
+type GraphQLConfig struct {
+	URL              string                 `json:"url"`
+	Query            string                 `json:"query,omitempty"`
+	QueryFile        string                 `json:"query_file,omitempty"`
+	Fragments        []string               `json:"fragments,omitempty"` // Fragment definitions or .graphql/.gql files
+	Variables        map[string]interface{} `json:"variables,omitempty"`
+	OperationName    string                 `json:"operation_name,omitempty"`
+	Headers          map[string]string      `json:"headers,omitempty"`
+	FailOnErrors     *bool                  `json:"fail_on_errors,omitempty"` // Default: true
+	Introspection    bool                   `json:"introspection,omitempty"`
+	Timeout          string                 `json:"timeout,omitempty"`
+	WSURL            string                 `json:"ws_url,omitempty"`   // Subscriptions: defaults to url with ws(s) scheme
+	Protocol         string                 `json:"protocol,omitempty"` // Subscriptions: graphql-transport-ws (default) or graphql-ws
+	ConnectionParams map[string]interface{} `json:"connection_params,omitempty"`
+	Count            int                    `json:"count,omitempty"` // Subscriptions: messages to collect (default 1)
+}
+
+// GraphQLError is a single entry of a GraphQL response's errors array
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []interface{}          `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e GraphQLError) String() string {
+	msg := e.Message
+	if len(e.Path) > 0 {
+		parts := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			parts[i] = fmt.Sprintf("%v", p)
+		}
+		msg += " (path: " + strings.Join(parts, ".") + ")"
+	}
+	if code, ok := e.Extensions["code"]; ok {
+		msg = fmt.Sprintf("[%v] %s", code, msg)
+	}
+	return msg
+}
+
+// Execute runs the GraphQL action (implements Handler interface)
+func (h *GraphQLHandler) Execute(ctx context.Context, rawConfig map[string]interface{}) (models.OutputData, error) {
+	config, err := h.parseConfig(rawConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL config: %w", err)
+	}
+
+	if config.URL == "" && config.WSURL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	document, err := config.document()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 30 * time.Second
+	if config.Timeout != "" {
+		if d, err := time.ParseDuration(config.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opType := GraphQLOperationType(document, config.OperationName)
+	if opType == "subscription" {
+		return h.subscribe(ctx, config, document)
+	}
+	return h.request(ctx, config, document, opType)
+}
+
+// parseConfig converts map to GraphQLConfig
+func (h *GraphQLHandler) parseConfig(rawConfig map[string]interface{}) (*GraphQLConfig, error) {
+	configBytes, err := json.Marshal(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var config GraphQLConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// document builds the final GraphQL document: the operation plus every
+// fragment it references, directly or through other fragments
+func (c *GraphQLConfig) document() (string, error) {
+	if c.Introspection {
+		return GraphQLIntrospectionQuery, nil
+	}
+
+	query := c.Query
+	if c.QueryFile != "" {
+		if query != "" {
+			return "", fmt.Errorf("only one of query and query_file may be set")
+		}
+		data, err := os.ReadFile(c.QueryFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read query file: %w", err)
+		}
+		query = string(data)
+	}
+	if strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("query or query_file is required")
+	}
+
+	if len(c.Fragments) == 0 {
+		return query, nil
+	}
+
+	available := make(map[string]string)
+	for _, f := range c.Fragments {
+		source := f
+		if isGraphQLFile(f) {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return "", fmt.Errorf("failed to read fragment file: %w", err)
+			}
+			source = string(data)
+		}
+		for name, def := range splitFragments(source) {
+			available[name] = def
+		}
+	}
+
+	// Fragments defined in the query itself take precedence
+	defined := splitFragments(query)
+
+	var included []string
+	seen := make(map[string]bool)
+	pending := fragmentSpreads(query)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if seen[name] || defined[name] != "" {
+			continue
+		}
+		seen[name] = true
+		def, ok := available[name]
+		if !ok {
+			continue // Let the server report unknown fragments
+		}
+		included = append(included, def)
+		pending = append(pending, fragmentSpreads(def)...)
+	}
+
+	if len(included) == 0 {
+		return query, nil
+	}
+	return query + "\n\n" + strings.Join(included, "\n\n"), nil
+}
+
+// request sends a query or mutation over HTTP
+func (h *GraphQLHandler) request(ctx context.Context, config *GraphQLConfig, document, opType string) (models.OutputData, error) {
+	payload := graphQLPayload(document, config)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	h.logger.Info("Executing GraphQL request",
+		zap.String("url", config.URL),
+		zap.String("operation_type", opType),
+		zap.String("operation_name", config.OperationName),
+	)
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	duration := time.Since(start)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var parsedBody interface{}
+	if err := json.Unmarshal(respBody, &parsedBody); err != nil {
+		parsedBody = string(respBody)
+	}
+
+	output := models.OutputData{
+		"status":         resp.StatusCode,
+		"headers":        resp.Header,
+		"body":           parsedBody,
+		"data":           nil,
+		"errors":         []interface{}{},
+		"operation_type": opType,
+		"duration_ms":    duration.Milliseconds(),
+	}
+
+	bodyMap, ok := parsedBody.(map[string]interface{})
+	if !ok {
+		return output, fmt.Errorf("GraphQL response is not a JSON object (status %d)", resp.StatusCode)
+	}
+	output["data"] = bodyMap["data"]
+	if errs, ok := bodyMap["errors"].([]interface{}); ok {
+		output["errors"] = errs
+	}
+	if ext, ok := bodyMap["extensions"]; ok {
+		output["extensions"] = ext
+	}
+	if config.Introspection {
+		if data, ok := bodyMap["data"].(map[string]interface{}); ok {
+			output["schema"] = data["__schema"]
+		}
+	}
+
+	h.logger.Info("GraphQL request completed",
+		zap.String("url", config.URL),
+		zap.Int("status", resp.StatusCode),
+		zap.Int64("duration_ms", duration.Milliseconds()),
+	)
+
+	if err := config.checkErrors(output["errors"].([]interface{})); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// subscribe runs a subscription over WebSocket and collects the requested number of events
+func (h *GraphQLHandler) subscribe(ctx context.Context, config *GraphQLConfig, document string) (models.OutputData, error) {
+	wsURL := config.WSURL
+	if wsURL == "" {
+		wsURL = websocketURL(config.URL)
+	}
+	protocol := config.Protocol
+	if protocol == "" {
+		protocol = GraphQLTransportWS
+	}
+	if protocol != GraphQLTransportWS && protocol != GraphQLLegacyWS {
+		return nil, fmt.Errorf("unsupported subscription protocol: %s", protocol)
+	}
+	count := config.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	start := time.Now()
+	conn, _, err := dialWebSocket(ctx, wsURL, config.Headers, []string{protocol})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+	}
+
+	h.logger.Info("GraphQL subscription connected",
+		zap.String("url", wsURL),
+		zap.String("protocol", protocol),
+	)
+
+	if err := conn.WriteJSON(map[string]interface{}{"type": "connection_init", "payload": config.ConnectionParams}); err != nil {
+		return nil, fmt.Errorf("failed to initialize connection: %w", err)
+	}
+
+	startType, nextType, stopType := "subscribe", "next", "complete"
+	if protocol == GraphQLLegacyWS {
+		startType, nextType, stopType = "start", "data", "stop"
+	}
+
+	messages := []interface{}{}
+	errs := []interface{}{}
+	completed := false
+
+	for len(messages) < count && !completed {
+		var msg struct {
+			ID      string          `json:"id"`
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			if len(messages) > 0 {
+				break
+			}
+			return h.subscriptionOutput(messages, errs, start), fmt.Errorf("failed to receive subscription event: %w", err)
+		}
+
+		switch msg.Type {
+		case "connection_ack":
+			sub := map[string]interface{}{"id": "1", "type": startType, "payload": graphQLPayload(document, config)}
+			if err := conn.WriteJSON(sub); err != nil {
+				return nil, fmt.Errorf("failed to subscribe: %w", err)
+			}
+		case "ping":
+			conn.WriteJSON(map[string]interface{}{"type": "pong"})
+		case "ka", "pong":
+			// Keep-alive
+		case "connection_error":
+			return h.subscriptionOutput(messages, errs, start), fmt.Errorf("connection rejected: %s", string(msg.Payload))
+		case nextType:
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				return nil, fmt.Errorf("invalid subscription event: %w", err)
+			}
+			messages = append(messages, payload["data"])
+			if e, ok := payload["errors"].([]interface{}); ok {
+				errs = append(errs, e...)
+			}
+		case "error":
+			var payload interface{}
+			json.Unmarshal(msg.Payload, &payload)
+			switch p := payload.(type) {
+			case []interface{}:
+				errs = append(errs, p...)
+			case map[string]interface{}:
+				if e, ok := p["errors"].([]interface{}); ok {
+					errs = append(errs, e...)
+				} else {
+					errs = append(errs, p)
+				}
+			}
+			completed = true
+		case "complete":
+			completed = true
+		}
+	}
+
+	// Stop the subscription and close the connection politely
+	conn.WriteJSON(map[string]interface{}{"id": "1", "type": stopType})
+	if protocol == GraphQLLegacyWS {
+		conn.WriteJSON(map[string]interface{}{"type": "connection_terminate"})
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	output := h.subscriptionOutput(messages, errs, start)
+	if err := config.checkErrors(errs); err != nil {
+		return output, err
+	}
+	if len(messages) < count {
+		return output, fmt.Errorf("subscription completed after %d of %d event(s)", len(messages), count)
+	}
+	return output, nil
+}
+
+// subscriptionOutput builds the step output for a subscription
+func (h *GraphQLHandler) subscriptionOutput(messages, errs []interface{}, start time.Time) models.OutputData {
+	var data interface{}
+	if len(messages) > 0 {
+		data = messages[0]
+	}
+	return models.OutputData{
+		"data":           data,
+		"messages":       messages,
+		"count":          len(messages),
+		"errors":         errs,
+		"operation_type": "subscription",
+		"duration_ms":    time.Since(start).Milliseconds(),
+	}
+}
+
+// checkErrors turns GraphQL errors into a step failure unless disabled
+func (c *GraphQLConfig) checkErrors(errs []interface{}) error {
+	if len(errs) == 0 || (c.FailOnErrors != nil && !*c.FailOnErrors) {
+		return nil
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, raw := range errs {
+		var gqlErr GraphQLError
+		if b, err := json.Marshal(raw); err == nil && json.Unmarshal(b, &gqlErr) == nil && gqlErr.Message != "" {
+			messages = append(messages, gqlErr.String())
+		} else {
+			messages = append(messages, fmt.Sprintf("%v", raw))
+		}
+	}
+	return fmt.Errorf("GraphQL returned %d error(s): %s", len(errs), strings.Join(messages, "; "))
+}
+
+// Name returns the handler name
+func (h *GraphQLHandler) Name() string {
+	return "graphql"
+}
+
+// graphQLPayload builds the standard request payload
+func graphQLPayload(document string, config *GraphQLConfig) map[string]interface{} {
+	payload := map[string]interface{}{"query": document}
+	if len(config.Variables) > 0 {
+		payload["variables"] = config.Variables
+	}
+	if config.OperationName != "" {
+		payload["operationName"] = config.OperationName
+	} else if config.Introspection {
+		payload["operationName"] = "IntrospectionQuery"
+	}
+	return payload
+}
+
+var (
+	graphQLFragmentDef    = regexp.MustCompile(`\bfragment\s+([_A-Za-z][_0-9A-Za-z]*)\s+on\b`)
+	graphQLFragmentSpread = regexp.MustCompile(`\.\.\.\s*([_A-Za-z][_0-9A-Za-z]*)`)
+	graphQLComment        = regexp.MustCompile(`#[^\n]*`)
+)
+
+// GraphQLOperationType returns "query", "mutation" or "subscription" for the
+// named operation, or for the first operation when name is empty
+func GraphQLOperationType(document, name string) string {
+	src := graphQLComment.ReplaceAllString(document, "")
+	first := ""
+	depth := 0
+	inFragment := false
+
+	for i := 0; i < len(src); i++ {
+		switch ch := src[i]; {
+		case ch == '{':
+			if depth == 0 && inFragment {
+				inFragment = false
+			} else if depth == 0 && first == "" {
+				first = "query" // Shorthand query
+			}
+			depth++
+		case ch == '}':
+			depth--
+		case ch == '"':
+			// Skip string literals (arguments, default values)
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+		case depth == 0 && isNameStart(ch):
+			j := i
+			for j < len(src) && isNameChar(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			i = j - 1
+			if word == "fragment" {
+				inFragment = true
+				continue
+			}
+			if word != "query" && word != "mutation" && word != "subscription" {
+				continue
+			}
+			k := j
+			for k < len(src) && (src[k] == ' ' || src[k] == '\t' || src[k] == '\n' || src[k] == '\r') {
+				k++
+			}
+			l := k
+			for l < len(src) && isNameChar(src[l]) {
+				l++
+			}
+			if name != "" && src[k:l] == name {
+				return word
+			}
+			if first == "" {
+				first = word
+			}
+		}
+	}
+
+	if first == "" {
+		return "query"
+	}
+	return first
+}
+
+// splitFragments returns each fragment definition in a document keyed by name
+func splitFragments(source string) map[string]string {
+	fragments := make(map[string]string)
+	for _, loc := range graphQLFragmentDef.FindAllStringSubmatchIndex(source, -1) {
+		name := source[loc[2]:loc[3]]
+		open := strings.Index(source[loc[1]:], "{")
+		if open < 0 {
+			continue
+		}
+		depth := 0
+		for i := loc[1] + open; i < len(source); i++ {
+			if source[i] == '{' {
+				depth++
+			} else if source[i] == '}' {
+				depth--
+				if depth == 0 {
+					fragments[name] = source[loc[0] : i+1]
+					break
+				}
+			}
+		}
+	}
+	return fragments
+}
+
+// fragmentSpreads returns the names of fragments spread in a document
+func fragmentSpreads(source string) []string {
+	var names []string
+	for _, m := range graphQLFragmentSpread.FindAllStringSubmatch(source, -1) {
+		if m[1] != "on" {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// websocketURL converts an http(s) endpoint to its ws(s) equivalent
+func websocketURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	default:
+		return url
+	}
+}
+
+func isGraphQLFile(s string) bool {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	return !strings.ContainsAny(lower, "{\n") && (strings.HasSuffix(lower, ".graphql") || strings.HasSuffix(lower, ".gql"))
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNameChar(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
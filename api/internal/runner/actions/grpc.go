@@ -31,6 +31,25 @@ func NewGRPCHandler(logger *zap.Logger) *GRPCHandler {
 	}
 }
 
+// ConfigSchema describes the config of grpc steps
+func (h *GRPCHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "grpc",
+		Description: "Call a gRPC method",
+		Schema: object([]string{"address", "service", "method"}, map[string]interface{}{
+			"address":        str("Server address, host:port"),
+			"service":        str("Fully qualified service name"),
+			"method":         str("Method name"),
+			"request":        typed("Request message", "object"),
+			"metadata":       stringMap("Request metadata"),
+			"proto_file":     str("Proto file describing the service"),
+			"timeout":        str("Timeout"),
+			"use_tls":        typed("Connect with TLS", "boolean"),
+			"use_reflection": typed("Describe the service with server reflection", "boolean"),
+		}),
+	}
+}
+
 // GRPCConfig represents gRPC action configuration
 type GRPCConfig struct {
 	Address      string                 `json:"address" yaml:"address"`               // host:port
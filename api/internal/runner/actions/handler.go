@@ -3,10 +3,81 @@ package actions
 import (
 	"context"
 
+	"github.com/georgi-georgiev/testmesh/internal/runner/contracts"
+	"github.com/georgi-georgiev/testmesh/internal/runner/mocks"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"go.uber.org/zap"
 )
 
 // Handler defines the interface for action handlers
 type Handler interface {
 	Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error)
+	// ConfigSchema describes the config the handler reads, for validation
+	// and editor autocompletion
+	ConfigSchema() ActionSchema
+}
+
+// Services are what the handlers of built-in actions are created with.
+// Actions needing a service that is not set fail when they run.
+type Services struct {
+	Logger     *zap.Logger
+	Mocks      *mocks.Manager
+	Contracts  *repository.ContractRepository
+	Executions *repository.ExecutionRepository
+}
+
+// builtins creates the handler of every built-in action. The executor runs
+// steps with them, and the flow validation catalog is built from their
+// config schemas.
+var builtins = map[string]func(s Services) Handler{
+	"http_request":   func(s Services) Handler { return NewHTTPHandler(s.Logger) },
+	"database_query": func(s Services) Handler { return NewDatabaseHandler(s.Logger) },
+	"log":            func(s Services) Handler { return NewLogHandler(s.Logger) },
+	"delay":          func(s Services) Handler { return NewDelayHandler(s.Logger) },
+	"transform":      func(s Services) Handler { return NewTransformHandler(s.Logger) },
+	"assert":         func(s Services) Handler { return NewAssertHandler(s.Logger) },
+	"condition":      func(s Services) Handler { return NewConditionHandler(s.Logger, nil) },
+	"for_each":       func(s Services) Handler { return NewForEachHandler(s.Logger, nil) },
+	"mock_server_start": func(s Services) Handler {
+		return NewMockServerStartHandler(s.Mocks, s.Logger)
+	},
+	"mock_server_stop": func(s Services) Handler {
+		return NewMockServerStopHandler(s.Mocks, s.Logger)
+	},
+	"mock_server_configure": func(s Services) Handler {
+		return NewMockServerConfigureHandler(s.Mocks, s.Logger)
+	},
+	"contract_generate": func(s Services) Handler {
+		var generator *contracts.Generator
+		if s.Contracts != nil {
+			generator = contracts.NewGenerator(s.Contracts, s.Logger)
+		}
+		return NewContractGenerateHandler(generator, s.Executions, s.Logger)
+	},
+	"contract_verify": func(s Services) Handler {
+		var verifier *contracts.Verifier
+		var differ *contracts.Differ
+		if s.Contracts != nil {
+			verifier = contracts.NewVerifier(s.Contracts, s.Logger)
+			differ = contracts.NewDiffer(s.Contracts, s.Logger)
+		}
+		return NewContractVerifyHandler(verifier, differ, s.Logger)
+	},
+	"kafka_consumer": func(s Services) Handler { return NewKafkaConsumerHandler(s.Logger) },
+	"kafka_producer": func(s Services) Handler { return NewKafkaProducerHandler(s.Logger) },
+	"wait_for":       func(s Services) Handler { return NewWaitForHandler(s.Logger) },
+	"db_poll":        func(s Services) Handler { return NewDBPollHandler(s.Logger) },
+	"websocket":      func(s Services) Handler { return NewWebSocketHandler(s.Logger) },
+	"grpc":           func(s Services) Handler { return NewGRPCHandler(s.Logger) },
+	"graphql":        func(s Services) Handler { return NewGraphQLHandler(s.Logger) },
+}
+
+// NewHandler creates the handler of a built-in action
+func NewHandler(action string, s Services) (Handler, bool) {
+	create, ok := builtins[action]
+	if !ok {
+		return nil, false
+	}
+	return create(s), true
 }
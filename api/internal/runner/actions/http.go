@@ -6,7 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
@@ -29,6 +34,20 @@ func NewHTTPHandler(logger *zap.Logger) *HTTPHandler {
 	}
 }
 
+// ConfigSchema describes the config of http_request steps
+func (h *HTTPHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "http_request",
+		Description: "Send an HTTP request",
+		Schema: object([]string{"method", "url"}, map[string]interface{}{
+			"method":  enum("HTTP method", "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"),
+			"url":     str("Request URL"),
+			"headers": stringMap("Request headers"),
+			"body":    anything("Request body; maps and lists are sent as JSON"),
+		}),
+	}
+}
+
 // Execute executes an HTTP request action
 func (h *HTTPHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Extract configuration
@@ -44,12 +63,14 @@ func (h *HTTPHandler) Execute(ctx context.Context, config map[string]interface{}
 
 	// Prepare request body
 	var bodyReader io.Reader
+	var bodyContentType string
 	if body, exists := config["body"]; exists {
-		bodyBytes, err := json.Marshal(body)
+		bodyBytes, contentType, err := encodeBody(body, headerValue(config, "Content-Type"))
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal body: %w", err)
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
+		bodyContentType = contentType
 	}
 
 	// Create HTTP request
@@ -66,7 +87,9 @@ func (h *HTTPHandler) Execute(ctx context.Context, config map[string]interface{}
 	}
 
 	// Set default Content-Type if body exists
-	if bodyReader != nil && req.Header.Get("Content-Type") == "" {
+	if bodyContentType != "" {
+		req.Header.Set("Content-Type", bodyContentType)
+	} else if bodyReader != nil && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
@@ -116,3 +139,58 @@ func (h *HTTPHandler) Execute(ctx context.Context, config map[string]interface{}
 
 	return output, nil
 }
+
+// encodeBody encodes a request body for its content type: an object is sent
+// as form fields for URL-encoded and multipart forms, and as JSON otherwise.
+// Returns the content type to send when encoding sets it, like the boundary
+// of a multipart form.
+func encodeBody(body interface{}, contentType string) ([]byte, string, error) {
+	fields, isObject := body.(map[string]interface{})
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !isObject {
+		data, err := json.Marshal(body)
+		return data, "", err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values := url.Values{}
+		for _, key := range keys {
+			values.Set(key, fmt.Sprintf("%v", fields[key]))
+		}
+		return []byte(values.Encode()), "", nil
+	case "multipart/form-data":
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, key := range keys {
+			if err := writer.WriteField(key, fmt.Sprintf("%v", fields[key])); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+	default:
+		data, err := json.Marshal(body)
+		return data, "", err
+	}
+}
+
+// headerValue returns a header of the request config, matching its name
+// case-insensitively
+func headerValue(config map[string]interface{}, name string) string {
+	headers, _ := config["headers"].(map[string]interface{})
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
@@ -21,6 +21,22 @@ func NewKafkaConsumerHandler(logger *zap.Logger) *KafkaConsumerHandler {
 	return &KafkaConsumerHandler{logger: logger}
 }
 
+// ConfigSchema describes the config of kafka_consumer steps
+func (h *KafkaConsumerHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "kafka_consumer",
+		Description: "Consume messages from a Kafka topic",
+		Schema: object([]string{"brokers", "topic"}, map[string]interface{}{
+			"brokers":        brokers(),
+			"topic":          str("Topic"),
+			"group_id":       str("Consumer group"),
+			"timeout":        str("How long to wait for messages"),
+			"count":          typed("Messages to consume", "integer"),
+			"from_beginning": typed("Read the topic from the start", "boolean"),
+		}),
+	}
+}
+
 // Execute runs the kafka consumer action.
 func (h *KafkaConsumerHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	cfg, err := parseKafkaConsumerConfig(config)
@@ -20,6 +20,21 @@ func NewKafkaProducerHandler(logger *zap.Logger) *KafkaProducerHandler {
 	return &KafkaProducerHandler{logger: logger}
 }
 
+// ConfigSchema describes the config of kafka_producer steps
+func (h *KafkaProducerHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "kafka_producer",
+		Description: "Produce a message to a Kafka topic",
+		Schema: object([]string{"brokers", "topic", "payload"}, map[string]interface{}{
+			"brokers": brokers(),
+			"topic":   str("Topic"),
+			"key":     str("Message key"),
+			"payload": anything("Message payload; maps and lists are sent as JSON"),
+			"headers": stringMap("Message headers"),
+		}),
+	}
+}
+
 // Execute produces a single message to a Kafka topic.
 func (h *KafkaProducerHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	cfg, err := parseKafkaProducerConfig(config)
@@ -20,6 +20,18 @@ func NewLogHandler(logger *zap.Logger) *LogHandler {
 	}
 }
 
+// ConfigSchema describes the config of log steps
+func (h *LogHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "log",
+		Description: "Write a message to the execution log",
+		Schema: object([]string{"message"}, map[string]interface{}{
+			"message": str("Message"),
+			"level":   enum("Log level", "debug", "info", "warn", "error"),
+		}),
+	}
+}
+
 // Execute logs a message
 func (h *LogHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	message, ok := config["message"].(string)
@@ -24,8 +24,25 @@ func NewMockServerStartHandler(manager *mocks.Manager, logger *zap.Logger) *Mock
 	}
 }
 
+// ConfigSchema describes the config of mock_server_start steps
+func (h *MockServerStartHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "mock_server_start",
+		Description: "Start a mock HTTP server",
+		Schema: object([]string{"name", "endpoints"}, map[string]interface{}{
+			"name":      str("Mock server name"),
+			"server_id": str("ID to start the server with"),
+			"port":      typed("Port", "integer"),
+			"endpoints": typed("Endpoints with a path, method and response", "array"),
+		}),
+	}
+}
+
 // Execute starts a mock server
 func (h *MockServerStartHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
+	if h.manager == nil {
+		return nil, fmt.Errorf("mock manager not initialized")
+	}
 	// Extract configuration
 	name, ok := config["name"].(string)
 	if !ok {
@@ -150,6 +167,10 @@ func (h *MockServerStartHandler) parseEndpointConfig(serverID uuid.UUID, config
 		} else if delayMs, ok := respConfig["delay_ms"].(int); ok {
 			responseConfig.DelayMs = delayMs
 		}
+
+		if schema, ok := respConfig["schema"].(map[string]interface{}); ok {
+			responseConfig.Schema = schema
+		}
 	}
 
 	// Parse match configuration
@@ -238,8 +259,22 @@ func NewMockServerStopHandler(manager *mocks.Manager, logger *zap.Logger) *MockS
 	}
 }
 
+// ConfigSchema describes the config of mock_server_stop steps
+func (h *MockServerStopHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "mock_server_stop",
+		Description: "Stop a mock server",
+		Schema: object([]string{"server_id"}, map[string]interface{}{
+			"server_id": str("ID of the mock server"),
+		}),
+	}
+}
+
 // Execute stops a mock server
 func (h *MockServerStopHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
+	if h.manager == nil {
+		return nil, fmt.Errorf("mock manager not initialized")
+	}
 	serverIDStr, ok := config["server_id"].(string)
 	if !ok {
 		return nil, fmt.Errorf("server_id is required")
@@ -278,8 +313,23 @@ func NewMockServerConfigureHandler(manager *mocks.Manager, logger *zap.Logger) *
 	}
 }
 
+// ConfigSchema describes the config of mock_server_configure steps
+func (h *MockServerConfigureHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "mock_server_configure",
+		Description: "Replace the endpoints of a running mock server",
+		Schema: object([]string{"server_id", "endpoints"}, map[string]interface{}{
+			"server_id": str("ID of the mock server"),
+			"endpoints": typed("Endpoints with a path, method and response", "array"),
+		}),
+	}
+}
+
 // Execute configures a running mock server
 func (h *MockServerConfigureHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
+	if h.manager == nil {
+		return nil, fmt.Errorf("mock manager not initialized")
+	}
 	serverIDStr, ok := config["server_id"].(string)
 	if !ok {
 		return nil, fmt.Errorf("server_id is required")
@@ -0,0 +1,125 @@
+package actions
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// ActionSchema describes the config of an action for validation and editor
+// autocompletion
+type ActionSchema struct {
+	Action      string                 `json:"action"`
+	Description string                 `json:"description"`
+	Schema      map[string]interface{} `json:"schema"` // JSON Schema of the step's config
+}
+
+// executorSchemas describe the actions the executor runs itself instead of a
+// handler, since their config holds steps
+var executorSchemas = []ActionSchema{
+	{
+		Action:      "parallel",
+		Description: "Run branches of steps concurrently",
+		Schema: object(nil, map[string]interface{}{
+			"branches":       typed("Branches, each with a name and steps", "array"),
+			"steps":          typed("Steps, one branch each", "array"),
+			"join":           enum("How branches are joined", "all", "any", "fail_fast"),
+			"max_concurrent": typed("Most branches running at once (0: all)", "integer"),
+			"wait_for_all":   typed("Wait for every branch", "boolean"),
+			"fail_fast":      typed("Cancel other branches on the first failure", "boolean"),
+		}),
+	},
+	{
+		Action:      "run_flow",
+		Description: "Run another flow of the workspace",
+		Schema: object([]string{"flow"}, map[string]interface{}{
+			"flow":        str("Flow name or ID"),
+			"input":       typed("Variables passed to the flow", "object"),
+			"inherit_env": typed("Pass the variables of this flow too (default: true)", "boolean"),
+		}),
+	},
+}
+
+// BuiltinSchemas returns the schemas of every built-in action, sorted by action
+func BuiltinSchemas() []ActionSchema {
+	schemas := append([]ActionSchema{}, executorSchemas...)
+	for _, create := range builtins {
+		schemas = append(schemas, create(Services{Logger: zap.NewNop()}).ConfigSchema())
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Action < schemas[j].Action })
+	return schemas
+}
+
+// object is the schema of a config with required keys. Unknown keys are
+// allowed, since handlers ignore them.
+func object(required []string, properties map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		list := make([]interface{}, len(required))
+		for i, key := range required {
+			list[i] = key
+		}
+		schema["required"] = list
+	}
+	return schema
+}
+
+func str(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func anything(description string) map[string]interface{} {
+	return map[string]interface{}{"description": description}
+}
+
+func enum(description string, values ...string) map[string]interface{} {
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return interpolated(map[string]interface{}{"enum": list, "description": description})
+}
+
+func stringMap(description string) map[string]interface{} {
+	return interpolated(map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": []interface{}{"string", "number", "boolean"}},
+		"description":          description,
+	})
+}
+
+// brokers accepts a list of addresses or a comma-separated string
+func brokers() map[string]interface{} {
+	return map[string]interface{}{
+		"description": "Broker addresses",
+		"type":        []interface{}{"array", "string"},
+		"items":       map[string]interface{}{"type": "string"},
+	}
+}
+
+// typed is the schema of a value of a JSON type; for arrays, itemType is the
+// type of the items
+func typed(description, jsonType string, itemType ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": jsonType, "description": description}
+	if len(itemType) > 0 {
+		schema["items"] = map[string]interface{}{"type": itemType[0]}
+	}
+	return interpolated(schema)
+}
+
+// interpolated also accepts a ${...} or {{...}} reference in place of the
+// value, since references are only resolved when the step runs
+func interpolated(schema map[string]interface{}) map[string]interface{} {
+	description := schema["description"]
+	delete(schema, "description")
+	return map[string]interface{}{
+		"description": description,
+		"anyOf": []interface{}{
+			schema,
+			map[string]interface{}{"type": "string", "pattern": `\$\{.+\}|\{\{.+\}\}`},
+		},
+	}
+}
@@ -22,6 +22,18 @@ func NewTransformHandler(logger *zap.Logger) *TransformHandler {
 	}
 }
 
+// ConfigSchema describes the config of transform steps
+func (h *TransformHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "transform",
+		Description: "Extract values from data with JSON paths",
+		Schema: object([]string{"input", "transforms"}, map[string]interface{}{
+			"input":      anything("Data to transform"),
+			"transforms": stringMap("Output names and the JSON paths extracting them"),
+		}),
+	}
+}
+
 // Execute transforms data using JSONPath extraction and manipulation
 func (h *TransformHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	// Get input data
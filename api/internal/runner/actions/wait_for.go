@@ -19,6 +19,29 @@ func NewWaitForHandler(logger *zap.Logger) *WaitForHandler {
 	return &WaitForHandler{logger: logger}
 }
 
+// ConfigSchema describes the config of wait_for steps
+func (h *WaitForHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "wait_for",
+		Description: "Poll an HTTP endpoint or TCP port until it is ready",
+		Schema: object([]string{"type"}, map[string]interface{}{
+			"type":          enum("What to wait for", "http", "tcp"),
+			"timeout":       str("Give up after this duration"),
+			"interval":      str("Time between attempts"),
+			"max_attempts":  typed("Most attempts", "integer"),
+			"url":           str("URL to poll (http)"),
+			"method":        str("HTTP method (http)"),
+			"status_code":   typed("Expected status code (http)", "integer"),
+			"body_contains": str("Expected text in the body (http)"),
+			"json_path":     str("JSON path into the body (http)"),
+			"json_value":    anything("Expected value at json_path (http)"),
+			"headers":       stringMap("Request headers (http)"),
+			"host":          str("Host (tcp)"),
+			"port":          typed("Port (tcp)", "integer"),
+		}),
+	}
+}
+
 // Execute polls until a condition is satisfied or timeout is reached.
 func (h *WaitForHandler) Execute(ctx context.Context, config map[string]interface{}) (models.OutputData, error) {
 	cfg := &async.WaitForConfig{}
@@ -22,6 +22,24 @@ func NewWebSocketHandler(logger *zap.Logger) *WebSocketHandler {
 	return &WebSocketHandler{logger: logger}
 }
 
+// ConfigSchema describes the config of websocket steps
+func (h *WebSocketHandler) ConfigSchema() ActionSchema {
+	return ActionSchema{
+		Action:      "websocket",
+		Description: "Connect to a WebSocket and send or receive messages",
+		Schema: object([]string{"action"}, map[string]interface{}{
+			"url":           str("WebSocket URL (connect)"),
+			"action":        enum("What to do", "connect", "send", "receive", "close"),
+			"headers":       stringMap("Handshake headers"),
+			"message":       anything("Message to send"),
+			"message_type":  enum("Message type", "text", "binary"),
+			"timeout":       str("Timeout"),
+			"expected":      anything("Expected message"),
+			"connection_id": str("Connection of an earlier step"),
+		}),
+	}
+}
+
 // WebSocketConfig represents WebSocket action configuration
 type WebSocketConfig struct {
 	URL             string            `json:"url" yaml:"url"`
@@ -145,18 +163,7 @@ func (h *WebSocketHandler) resultToOutputData(result *WebSocketResult) models.Ou
 
 // connect establishes a WebSocket connection
 func (h *WebSocketHandler) connect(ctx context.Context, config *WebSocketConfig, result *WebSocketResult) error {
-	// Build request headers
-	header := http.Header{}
-	for k, v := range config.Headers {
-		header.Set(k, v)
-	}
-
-	// Connect
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	conn, resp, err := dialer.DialContext(ctx, config.URL, header)
+	conn, resp, err := dialWebSocket(ctx, config.URL, config.Headers, nil)
 	if err != nil {
 		result.Error = err.Error()
 		return fmt.Errorf("failed to connect: %w", err)
@@ -181,6 +188,21 @@ func (h *WebSocketHandler) connect(ctx context.Context, config *WebSocketConfig,
 	return nil
 }
 
+// dialWebSocket opens a WebSocket connection with the given headers and subprotocols
+func dialWebSocket(ctx context.Context, url string, headers map[string]string, subprotocols []string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	for k, v := range headers {
+		header.Set(k, v)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		Subprotocols:     subprotocols,
+	}
+
+	return dialer.DialContext(ctx, url, header)
+}
+
 // send sends a message over an existing connection
 func (h *WebSocketHandler) send(ctx context.Context, config *WebSocketConfig, result *WebSocketResult) error {
 	conn := activeConnections[config.ConnectionID]
@@ -0,0 +1,726 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaDepth guards against self-referencing schemas that never consume the instance
+const maxSchemaDepth = 128
+
+// SchemaViolation describes a single place where an instance does not satisfy its schema
+type SchemaViolation struct {
+	Pointer string `json:"pointer"` // JSON pointer into the validated instance ("" is the root)
+	Keyword string `json:"keyword"` // Schema keyword that failed (type, required, ...)
+	Message string `json:"message"`
+}
+
+// String formats the violation as "<pointer>: <message>"
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// SchemaError is returned when an instance has one or more schema violations
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error lists every violation, one per line
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return fmt.Sprintf("schema validation failed with %d violation(s):\n  - %s", len(e.Violations), strings.Join(lines, "\n  - "))
+}
+
+// SchemaValidator validates values against a JSON Schema.
+// It supports the draft-07 vocabulary used in practice (types, objects, arrays,
+// numeric and string constraints, formats, combinators, local $ref) plus the
+// OpenAPI 3.0 "nullable" extension, so OpenAPI component schemas can be used directly.
+type SchemaValidator struct {
+	root     interface{}
+	schema   interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// NewSchemaValidator creates a validator for a standalone schema document
+func NewSchemaValidator(schema interface{}) (*SchemaValidator, error) {
+	return NewSchemaValidatorAt(schema, "")
+}
+
+// NewSchemaValidatorAt creates a validator for the schema located at pointer
+// inside root (e.g. "#/components/schemas/Pet" inside an OpenAPI document).
+// Local $refs are resolved against root.
+func NewSchemaValidatorAt(root interface{}, pointer string) (*SchemaValidator, error) {
+	root = NormalizeJSON(root)
+
+	schema, err := ResolvePointer(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch schema.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("schema must be an object or boolean, got %T", schema)
+	}
+
+	return &SchemaValidator{
+		root:     root,
+		schema:   schema,
+		patterns: make(map[string]*regexp.Regexp),
+	}, nil
+}
+
+// Validate returns every violation found in instance (empty when valid)
+func (v *SchemaValidator) Validate(instance interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+	v.validate(v.schema, NormalizeJSON(instance), "", 0, &violations)
+	return violations
+}
+
+// Check validates instance and returns a *SchemaError listing all violations, or nil
+func (v *SchemaValidator) Check(instance interface{}) error {
+	violations := v.Validate(instance)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &SchemaError{Violations: violations}
+}
+
+// validate applies schema to instance, appending violations found at pointer
+func (v *SchemaValidator) validate(schema interface{}, instance interface{}, pointer string, depth int, out *[]SchemaViolation) {
+	if depth > maxSchemaDepth {
+		v.add(out, pointer, "$ref", "schema recursion limit exceeded")
+		return
+	}
+
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.add(out, pointer, "false", "no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObjectSchema(s, instance, pointer, depth, out)
+	default:
+		v.add(out, pointer, "schema", fmt.Sprintf("invalid schema of type %T", schema))
+	}
+}
+
+func (v *SchemaValidator) validateObjectSchema(s map[string]interface{}, instance interface{}, pointer string, depth int, out *[]SchemaViolation) {
+	// $ref overrides sibling keywords (draft-07 and OpenAPI 3.0 semantics)
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := ResolvePointer(v.root, ref)
+		if err != nil {
+			v.add(out, pointer, "$ref", err.Error())
+			return
+		}
+		v.validate(target, instance, pointer, depth+1, out)
+		return
+	}
+
+	if instance == nil && s["nullable"] == true {
+		return
+	}
+
+	if t, ok := s["type"]; ok {
+		if !typeMatches(t, instance) {
+			v.add(out, pointer, "type", fmt.Sprintf("expected %s, got %s", describeType(t), jsonTypeOf(instance)))
+			// Remaining keywords would only repeat the type mismatch
+			return
+		}
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.add(out, pointer, "enum", fmt.Sprintf("value %s is not one of %s", compactJSON(instance), compactJSON(enum)))
+		}
+	}
+
+	if constant, ok := s["const"]; ok && !jsonEqual(constant, instance) {
+		v.add(out, pointer, "const", fmt.Sprintf("expected %s, got %s", compactJSON(constant), compactJSON(instance)))
+	}
+
+	switch inst := instance.(type) {
+	case string:
+		v.validateString(s, inst, pointer, out)
+	case float64:
+		v.validateNumber(s, inst, pointer, out)
+	case map[string]interface{}:
+		v.validateObject(s, inst, pointer, depth, out)
+	case []interface{}:
+		v.validateArray(s, inst, pointer, depth, out)
+	}
+
+	v.validateCombinators(s, instance, pointer, depth, out)
+}
+
+func (v *SchemaValidator) validateString(s map[string]interface{}, str string, pointer string, out *[]SchemaViolation) {
+	length := utf8.RuneCountInString(str)
+
+	if min, ok := numberKeyword(s, "minLength"); ok && float64(length) < min {
+		v.add(out, pointer, "minLength", fmt.Sprintf("length %d is shorter than %v", length, min))
+	}
+	if max, ok := numberKeyword(s, "maxLength"); ok && float64(length) > max {
+		v.add(out, pointer, "maxLength", fmt.Sprintf("length %d is longer than %v", length, max))
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.compile(pattern)
+		if err != nil {
+			v.add(out, pointer, "pattern", fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+		} else if !re.MatchString(str) {
+			v.add(out, pointer, "pattern", fmt.Sprintf("%q does not match pattern %q", str, pattern))
+		}
+	}
+
+	if format, ok := s["format"].(string); ok {
+		if !formatMatches(format, str) {
+			v.add(out, pointer, "format", fmt.Sprintf("%q is not a valid %s", str, format))
+		}
+	}
+}
+
+func (v *SchemaValidator) validateNumber(s map[string]interface{}, n float64, pointer string, out *[]SchemaViolation) {
+	if min, ok := numberKeyword(s, "minimum"); ok {
+		// Draft-04 style boolean exclusiveMinimum modifies minimum
+		if s["exclusiveMinimum"] == true {
+			if n <= min {
+				v.add(out, pointer, "exclusiveMinimum", fmt.Sprintf("%v must be greater than %v", n, min))
+			}
+		} else if n < min {
+			v.add(out, pointer, "minimum", fmt.Sprintf("%v is less than minimum %v", n, min))
+		}
+	}
+	if max, ok := numberKeyword(s, "maximum"); ok {
+		if s["exclusiveMaximum"] == true {
+			if n >= max {
+				v.add(out, pointer, "exclusiveMaximum", fmt.Sprintf("%v must be less than %v", n, max))
+			}
+		} else if n > max {
+			v.add(out, pointer, "maximum", fmt.Sprintf("%v is greater than maximum %v", n, max))
+		}
+	}
+	if min, ok := numberKeyword(s, "exclusiveMinimum"); ok && n <= min {
+		v.add(out, pointer, "exclusiveMinimum", fmt.Sprintf("%v must be greater than %v", n, min))
+	}
+	if max, ok := numberKeyword(s, "exclusiveMaximum"); ok && n >= max {
+		v.add(out, pointer, "exclusiveMaximum", fmt.Sprintf("%v must be less than %v", n, max))
+	}
+	if multiple, ok := numberKeyword(s, "multipleOf"); ok && multiple > 0 {
+		quotient := n / multiple
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.add(out, pointer, "multipleOf", fmt.Sprintf("%v is not a multiple of %v", n, multiple))
+		}
+	}
+}
+
+func (v *SchemaValidator) validateObject(s map[string]interface{}, obj map[string]interface{}, pointer string, depth int, out *[]SchemaViolation) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, exists := obj[name]; !exists {
+				v.add(out, pointer, "required", fmt.Sprintf("missing required property %q", name))
+			}
+		}
+	}
+
+	if min, ok := numberKeyword(s, "minProperties"); ok && float64(len(obj)) < min {
+		v.add(out, pointer, "minProperties", fmt.Sprintf("has %d properties, fewer than %v", len(obj), min))
+	}
+	if max, ok := numberKeyword(s, "maxProperties"); ok && float64(len(obj)) > max {
+		v.add(out, pointer, "maxProperties", fmt.Sprintf("has %d properties, more than %v", len(obj), max))
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+
+	for _, key := range sortedObjectKeys(obj) {
+		value := obj[key]
+		childPointer := pointer + "/" + escapePointerToken(key)
+		matched := false
+
+		if propSchema, ok := properties[key]; ok {
+			matched = true
+			v.validate(propSchema, value, childPointer, depth+1, out)
+		}
+
+		for _, pattern := range sortedObjectKeys(patternProperties) {
+			re, err := v.compile(pattern)
+			if err != nil || !re.MatchString(key) {
+				continue
+			}
+			matched = true
+			v.validate(patternProperties[pattern], value, childPointer, depth+1, out)
+		}
+
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok {
+			if !allowed {
+				v.add(out, childPointer, "additionalProperties", fmt.Sprintf("property %q is not allowed", key))
+			}
+			continue
+		}
+		v.validate(additional, value, childPointer, depth+1, out)
+	}
+
+	if names, ok := s["propertyNames"]; ok {
+		for _, key := range sortedObjectKeys(obj) {
+			v.validate(names, key, pointer+"/"+escapePointerToken(key), depth+1, out)
+		}
+	}
+
+	if deps, ok := s["dependencies"].(map[string]interface{}); ok {
+		for _, key := range sortedObjectKeys(deps) {
+			if _, present := obj[key]; !present {
+				continue
+			}
+			switch dep := deps[key].(type) {
+			case []interface{}:
+				for _, d := range dep {
+					if name, ok := d.(string); ok {
+						if _, exists := obj[name]; !exists {
+							v.add(out, pointer, "dependencies", fmt.Sprintf("property %q requires property %q", key, name))
+						}
+					}
+				}
+			default:
+				v.validate(dep, obj, pointer, depth+1, out)
+			}
+		}
+	}
+}
+
+func (v *SchemaValidator) validateArray(s map[string]interface{}, arr []interface{}, pointer string, depth int, out *[]SchemaViolation) {
+	if min, ok := numberKeyword(s, "minItems"); ok && float64(len(arr)) < min {
+		v.add(out, pointer, "minItems", fmt.Sprintf("has %d items, fewer than %v", len(arr), min))
+	}
+	if max, ok := numberKeyword(s, "maxItems"); ok && float64(len(arr)) > max {
+		v.add(out, pointer, "maxItems", fmt.Sprintf("has %d items, more than %v", len(arr), max))
+	}
+
+	if s["uniqueItems"] == true {
+	outer:
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.add(out, pointer, "uniqueItems", fmt.Sprintf("items %d and %d are equal", i, j))
+					break outer
+				}
+			}
+		}
+	}
+
+	switch items := s["items"].(type) {
+	case []interface{}:
+		// Tuple validation
+		for i, item := range arr {
+			childPointer := pointer + "/" + strconv.Itoa(i)
+			if i < len(items) {
+				v.validate(items[i], item, childPointer, depth+1, out)
+				continue
+			}
+			if additional, ok := s["additionalItems"]; ok {
+				if allowed, isBool := additional.(bool); isBool && !allowed {
+					v.add(out, childPointer, "additionalItems", "additional items are not allowed")
+					continue
+				}
+				v.validate(additional, item, childPointer, depth+1, out)
+			}
+		}
+	case nil:
+	default:
+		for i, item := range arr {
+			v.validate(items, item, pointer+"/"+strconv.Itoa(i), depth+1, out)
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		found := false
+		for _, item := range arr {
+			if v.isValid(contains, item, depth+1) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.add(out, pointer, "contains", "no item matches the contains schema")
+		}
+	}
+}
+
+func (v *SchemaValidator) validateCombinators(s map[string]interface{}, instance interface{}, pointer string, depth int, out *[]SchemaViolation) {
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			v.validate(sub, instance, pointer, depth+1, out)
+		}
+	}
+
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.isValid(sub, instance, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.add(out, pointer, "anyOf", fmt.Sprintf("value does not match any of the %d anyOf schemas", len(anyOf)))
+		}
+	}
+
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if v.isValid(sub, instance, depth+1) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.add(out, pointer, "oneOf", fmt.Sprintf("value matches %d of the oneOf schemas, expected exactly 1", matches))
+		}
+	}
+
+	if not, ok := s["not"]; ok && v.isValid(not, instance, depth+1) {
+		v.add(out, pointer, "not", "value must not match the \"not\" schema")
+	}
+
+	if cond, ok := s["if"]; ok {
+		if v.isValid(cond, instance, depth+1) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, instance, pointer, depth+1, out)
+			}
+		} else if otherwise, ok := s["else"]; ok {
+			v.validate(otherwise, instance, pointer, depth+1, out)
+		}
+	}
+}
+
+// isValid reports whether instance satisfies schema without recording violations
+func (v *SchemaValidator) isValid(schema interface{}, instance interface{}, depth int) bool {
+	var violations []SchemaViolation
+	v.validate(schema, instance, "", depth, &violations)
+	return len(violations) == 0
+}
+
+func (v *SchemaValidator) add(out *[]SchemaViolation, pointer, keyword, message string) {
+	*out = append(*out, SchemaViolation{Pointer: pointer, Keyword: keyword, Message: message})
+}
+
+func (v *SchemaValidator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// InferSchema builds a schema describing the shape of value.
+// Objects require every non-null property they contain and arrays take their
+// item schema from the first element, which makes the result a useful
+// baseline for detecting structural drift between runs.
+func InferSchema(value interface{}) map[string]interface{} {
+	switch v := NormalizeJSON(value).(type) {
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		required := make([]interface{}, 0, len(v))
+		for _, key := range sortedObjectKeys(v) {
+			properties[key] = InferSchema(v[key])
+			if v[key] != nil {
+				required = append(required, key)
+			}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array"}
+		if len(v) > 0 {
+			schema["items"] = InferSchema(v[0])
+		}
+		return schema
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case float64:
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		// null or unknown: accept anything
+		return map[string]interface{}{}
+	}
+}
+
+// ResolvePointer resolves a JSON pointer (optionally prefixed with "#") inside doc
+func ResolvePointer(doc interface{}, pointer string) (interface{}, error) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local JSON pointers are supported", pointer)
+	}
+
+	current := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("$ref %q: %q not found", "#"+pointer, token)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("$ref %q: invalid array index %q", "#"+pointer, token)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("$ref %q: cannot descend into %s", "#"+pointer, jsonTypeOf(node))
+		}
+	}
+	return current, nil
+}
+
+// NormalizeJSON converts YAML-decoded and Go-native values into the shapes
+// produced by encoding/json (map[string]interface{}, []interface{}, float64)
+func NormalizeJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, float64:
+		return v
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = NormalizeJSON(item)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[fmt.Sprintf("%v", k)] = NormalizeJSON(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = NormalizeJSON(item)
+		}
+		return out
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	default:
+		// Fall back to a JSON round trip for structs and typed maps/slices
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var out interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return v
+		}
+		return out
+	}
+}
+
+// typeMatches checks the "type" keyword, which may be a string or a list of strings
+func typeMatches(t interface{}, instance interface{}) bool {
+	switch tv := t.(type) {
+	case string:
+		return singleTypeMatches(tv, instance)
+	case []interface{}:
+		for _, item := range tv {
+			if name, ok := item.(string); ok && singleTypeMatches(name, instance) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func singleTypeMatches(name string, instance interface{}) bool {
+	switch name {
+	case "null":
+		return instance == nil
+	case "boolean":
+		_, ok := instance.(bool)
+		return ok
+	case "string":
+		_, ok := instance.(string)
+		return ok
+	case "number":
+		_, ok := instance.(float64)
+		return ok
+	case "integer":
+		n, ok := instance.(float64)
+		return ok && n == math.Trunc(n)
+	case "object":
+		_, ok := instance.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := instance.([]interface{})
+		return ok
+	}
+	return true
+}
+
+func describeType(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			names = append(names, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprintf("%v", t)
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i:[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)(\.(?i:[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?))*$`)
+)
+
+// formatMatches validates the well-known string formats; unknown formats always pass
+func formatMatches(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "time":
+		if _, err := time.Parse("15:04:05Z07:00", value); err == nil {
+			return true
+		}
+		_, err := time.Parse("15:04:05", value)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "uri", "url":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "uri-reference":
+		_, err := url.Parse(value)
+		return err == nil
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && strings.Contains(value, ".")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	case "hostname":
+		return len(value) <= 253 && hostnamePattern.MatchString(value)
+	case "regex":
+		_, err := regexp.Compile(value)
+		return err == nil
+	}
+	return true
+}
+
+func numberKeyword(s map[string]interface{}, key string) (float64, bool) {
+	n, ok := s[key].(float64)
+	return n, ok
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(NormalizeJSON(a), NormalizeJSON(b))
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func sortedObjectKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
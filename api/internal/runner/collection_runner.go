@@ -6,19 +6,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // CollectionRunner executes flows with data-driven testing
 type CollectionRunner struct {
-	executor *Executor
-	logger   *zap.Logger
+	executor        *Executor
+	dataFiles       *repository.DataFileRepository
+	flows           *repository.FlowRepository
+	artifacts       *artifacts.Manager
+	dataSourceHosts []string
+	logger          *zap.Logger
 }
 
 // NewCollectionRunner creates a new collection runner
@@ -29,11 +36,46 @@ func NewCollectionRunner(executor *Executor, logger *zap.Logger) *CollectionRunn
 	}
 }
 
+// SetDataFiles sets the repository "file" data sources read from
+func (r *CollectionRunner) SetDataFiles(repo *repository.DataFileRepository) {
+	r.dataFiles = repo
+}
+
+// SetFlows sets the repository run_flow steps load their flows from
+func (r *CollectionRunner) SetFlows(repo *repository.FlowRepository) {
+	r.flows = repo
+}
+
+// SetArtifacts sets the manager "execution" data sources resolve offloaded
+// step outputs with
+func (r *CollectionRunner) SetArtifacts(manager *artifacts.Manager) {
+	r.artifacts = manager
+}
+
+// SetDataSourceHosts sets the database hosts "sql" data sources may connect
+// to, as host or host:port. Without hosts, any host is allowed.
+func (r *CollectionRunner) SetDataSourceHosts(hosts []string) {
+	r.dataSourceHosts = hosts
+}
+
 // DataSource represents a data source for iterations
 type DataSource struct {
-	Type    string          `json:"type"` // "csv", "json", or "inline"
-	Content string          `json:"content"` // Raw content or file path
-	Data    []DataRow       `json:"data,omitempty"` // Parsed data rows
+	Type    string    `json:"type"`           // "csv", "json", "inline", "file", "sql", "generator" or "execution"
+	Content string    `json:"content"`        // Raw content or file path
+	Data    []DataRow `json:"data,omitempty"` // Parsed data rows
+
+	File        string         `json:"file,omitempty"`         // Name or ID of a data file of the workspace
+	Query       string         `json:"query,omitempty"`        // SELECT query whose rows are the iterations; ${...} references are bound as parameters
+	Connection  string         `json:"connection,omitempty"`   // Database connection string of the query
+	Params      []interface{}  `json:"params,omitempty"`       // Query parameters
+	Generator   *DataGenerator `json:"generator,omitempty"`    // Generator of fake rows
+	ExecutionID string         `json:"execution_id,omitempty"` // Execution whose step output has the rows
+	Step        string         `json:"step,omitempty"`         // Step of the execution
+	Path        string         `json:"path,omitempty"`         // Path of the rows in the step output
+
+	Filter       string `json:"filter,omitempty"`        // Expression rows must match, like fare_type == "single"
+	ExpectColumn string `json:"expect_column,omitempty"` // Column with the expected outcome of a row (default "expected_outcome")
+	LabelColumn  string `json:"label_column,omitempty"`  // Column naming a row in reports (default "name")
 }
 
 // DataRow represents a single row of data for an iteration
@@ -46,37 +88,45 @@ type CollectionRunConfig struct {
 	Iterations      int               `json:"iterations"` // Number of iterations (if no data source)
 	DelayMs         int64             `json:"delay_ms"`   // Delay between iterations
 	StopOnError     bool              `json:"stop_on_error"`
-	Parallel        int               `json:"parallel"`        // Number of parallel executions (1 = sequential)
-	Variables       map[string]string `json:"variables"`       // Global variables for all iterations
+	Parallel        int               `json:"parallel"`         // Number of parallel executions (1 = sequential)
+	Variables       map[string]string `json:"variables"`        // Global variables for all iterations
 	VariableMapping map[string]string `json:"variable_mapping"` // Map data columns to variable names
 	Environment     string            `json:"environment"`
+	WorkspaceID     uuid.UUID         `json:"-"` // Workspace data files and executions are read from
 }
 
 // CollectionRunResult represents the result of a collection run
 type CollectionRunResult struct {
-	ID             uuid.UUID           `json:"id"`
-	Status         string              `json:"status"` // "running", "completed", "failed", "cancelled"
-	TotalIterations int               `json:"total_iterations"`
-	CompletedIterations int           `json:"completed_iterations"`
-	PassedIterations int              `json:"passed_iterations"`
-	FailedIterations int              `json:"failed_iterations"`
-	IterationResults []IterationResult `json:"iteration_results"`
-	StartedAt      time.Time          `json:"started_at"`
-	FinishedAt     *time.Time         `json:"finished_at,omitempty"`
-	DurationMs     int64              `json:"duration_ms"`
-	Error          string             `json:"error,omitempty"`
+	ID                  uuid.UUID         `json:"id"`
+	Status              string            `json:"status"` // "running", "completed", "failed", "cancelled"
+	TotalIterations     int               `json:"total_iterations"`
+	CompletedIterations int               `json:"completed_iterations"`
+	PassedIterations    int               `json:"passed_iterations"`
+	FailedIterations    int               `json:"failed_iterations"`
+	FilteredRows        int               `json:"filtered_rows,omitempty"` // Rows the filter left out
+	Columns             []string          `json:"columns,omitempty"`       // Columns of the data rows
+	DataSeed            int64             `json:"data_seed,omitempty"`     // Seed of a generator, to generate the same rows again
+	IterationResults    []IterationResult `json:"iteration_results"`
+	StartedAt           time.Time         `json:"started_at"`
+	FinishedAt          *time.Time        `json:"finished_at,omitempty"`
+	DurationMs          int64             `json:"duration_ms"`
+	Error               string            `json:"error,omitempty"`
 }
 
 // IterationResult represents the result of a single iteration
 type IterationResult struct {
-	Iteration     int               `json:"iteration"`
-	DataRow       DataRow           `json:"data_row,omitempty"`
-	FlowResults   []FlowRunResult   `json:"flow_results"`
-	Status        string            `json:"status"` // "passed", "failed"
-	StartedAt     time.Time         `json:"started_at"`
-	FinishedAt    time.Time         `json:"finished_at"`
-	DurationMs    int64             `json:"duration_ms"`
-	Error         string            `json:"error,omitempty"`
+	Iteration   int             `json:"iteration"`
+	Row         int             `json:"row,omitempty"`      // Number of the data row in the source, from 1
+	Label       string          `json:"label,omitempty"`    // Name of the data row
+	DataRow     DataRow         `json:"data_row,omitempty"` // Values of the data row
+	Expected    string          `json:"expected,omitempty"` // Expected outcome of the data row: "pass" or "fail"
+	Outcome     string          `json:"outcome,omitempty"`  // Outcome of the flows: "passed" or "failed"
+	FlowResults []FlowRunResult `json:"flow_results"`
+	Status      string          `json:"status"` // "passed" when the outcome is the expected one, "failed" otherwise
+	StartedAt   time.Time       `json:"started_at"`
+	FinishedAt  time.Time       `json:"finished_at"`
+	DurationMs  int64           `json:"duration_ms"`
+	Error       string          `json:"error,omitempty"`
 }
 
 // FlowRunResult represents the result of running a single flow
@@ -148,29 +198,29 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 		StartedAt: time.Now(),
 	}
 
-	// Parse data source if provided
-	var dataRows []DataRow
+	// Load data source if provided
+	var data *DataSet
 	if config.DataSource != nil {
 		var err error
-		switch config.DataSource.Type {
-		case "csv":
-			dataRows, err = ParseCSV(config.DataSource.Content)
-		case "json":
-			dataRows, err = ParseJSON(config.DataSource.Content)
-		case "inline":
-			dataRows = config.DataSource.Data
-		default:
-			return nil, fmt.Errorf("unsupported data source type: %s", config.DataSource.Type)
-		}
+		data, err = r.LoadRows(ctx, config.DataSource, config.WorkspaceID, config.Variables)
 		if err != nil {
 			return nil, err
 		}
+		if len(data.Rows) == 0 {
+			if data.Filtered > 0 {
+				return nil, fmt.Errorf("no data rows match the filter")
+			}
+			return nil, fmt.Errorf("data source has no rows")
+		}
+		result.Columns = data.Columns
+		result.DataSeed = data.Seed
+		result.FilteredRows = data.Filtered
 	}
 
 	// Determine number of iterations
 	iterations := config.Iterations
-	if len(dataRows) > 0 {
-		iterations = len(dataRows)
+	if data != nil {
+		iterations = len(data.Rows)
 	}
 	if iterations == 0 {
 		iterations = 1
@@ -186,20 +236,33 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 	}
 
 	var mu sync.Mutex
+	var wg sync.WaitGroup
 	sem := make(chan struct{}, parallel)
 
+iterationLoop:
 	for i := 0; i < iterations; i++ {
 		select {
 		case <-ctx.Done():
+			mu.Lock()
 			result.Status = "cancelled"
 			result.Error = "cancelled by user"
-			return result, nil
-		default:
+			mu.Unlock()
+			break iterationLoop
+		case sem <- struct{}{}:
 		}
 
-		sem <- struct{}{}
+		// Stop scheduling iterations after a failure
+		mu.Lock()
+		stopped := result.Status != "running"
+		mu.Unlock()
+		if stopped {
+			<-sem
+			break
+		}
 
+		wg.Add(1)
 		go func(iteration int) {
+			defer wg.Done()
 			defer func() { <-sem }()
 
 			iterResult := IterationResult{
@@ -209,9 +272,12 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 
 			// Get data row if available
 			var dataRow DataRow
-			if iteration < len(dataRows) {
-				dataRow = dataRows[iteration]
+			if data != nil {
+				dataRow = data.Rows[iteration]
 				iterResult.DataRow = dataRow
+				iterResult.Row = data.Numbers[iteration]
+				iterResult.Label = rowLabel(dataRow, config.DataSource.LabelColumn)
+				iterResult.Expected, _ = expectedOutcome(dataRow, config.DataSource.ExpectColumn)
 			}
 
 			// Build variables for this iteration
@@ -227,7 +293,7 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 					if mapped, ok := config.VariableMapping[dataKey]; ok {
 						varName = mapped
 					}
-					vars[varName] = fmt.Sprintf("%v", dataValue)
+					vars[varName] = rowValue(dataValue)
 				}
 			}
 
@@ -237,35 +303,30 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 
 			// Run each flow
 			for _, flow := range flows {
-				flowResult := FlowRunResult{
-					FlowID:   flow.ID,
-					FlowName: flow.Name,
-				}
-
-				// TODO: Execute flow with variables
-				// This would integrate with the existing executor
-				// For now, we'll create a placeholder
-				flowResult.Status = "completed"
-				flowResult.DurationMs = 100
-
-				iterResult.FlowResults = append(iterResult.FlowResults, flowResult)
+				iterResult.FlowResults = append(iterResult.FlowResults, r.runFlow(flow, vars, config.Environment))
 			}
 
 			iterResult.FinishedAt = time.Now()
 			iterResult.DurationMs = iterResult.FinishedAt.Sub(iterResult.StartedAt).Milliseconds()
 
 			// Determine iteration status
-			allPassed := true
+			iterResult.Outcome = "passed"
 			for _, fr := range iterResult.FlowResults {
-				if fr.Status != "completed" {
-					allPassed = false
+				if fr.Status != string(models.ExecutionStatusCompleted) {
+					iterResult.Outcome = "failed"
+					iterResult.Error = fmt.Sprintf("%s: %s", fr.FlowName, fr.Error)
 					break
 				}
 			}
-			if allPassed {
-				iterResult.Status = "passed"
-			} else {
-				iterResult.Status = "failed"
+			iterResult.Status = iterResult.Outcome
+			if iterResult.Expected == "fail" {
+				// A negative case passes when its flows fail
+				if iterResult.Outcome == "failed" {
+					iterResult.Status = "passed"
+				} else {
+					iterResult.Status = "failed"
+					iterResult.Error = "expected the flows to fail, but they passed"
+				}
 			}
 
 			// Update result
@@ -277,32 +338,33 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 			} else {
 				result.FailedIterations++
 			}
-			mu.Unlock()
+
+			// Check if we should stop on error
+			if config.StopOnError && iterResult.Status == "failed" && result.Status == "running" {
+				result.Status = "failed"
+				result.Error = "stopped due to iteration failure"
+			}
 
 			// Report progress
 			if progressFn != nil {
 				progressFn(result)
 			}
+			mu.Unlock()
 
 			// Delay between iterations
 			if config.DelayMs > 0 && iteration < iterations-1 {
 				time.Sleep(time.Duration(config.DelayMs) * time.Millisecond)
 			}
-
-			// Check if we should stop on error
-			if config.StopOnError && iterResult.Status == "failed" {
-				mu.Lock()
-				result.Status = "failed"
-				result.Error = "stopped due to iteration failure"
-				mu.Unlock()
-			}
 		}(i)
 	}
 
 	// Wait for all iterations to complete
-	for i := 0; i < parallel; i++ {
-		sem <- struct{}{}
-	}
+	wg.Wait()
+
+	// Report iterations in the order of their rows
+	sort.Slice(result.IterationResults, func(i, j int) bool {
+		return result.IterationResults[i].Iteration < result.IterationResults[j].Iteration
+	})
 
 	// Finalize result
 	finishedAt := time.Now()
@@ -320,7 +382,98 @@ func (r *CollectionRunner) Run(ctx context.Context, config *CollectionRunConfig,
 	return result, nil
 }
 
-// ValidateDataSource validates a data source
+// runFlow executes a flow with the variables of an iteration, recording its
+// execution when the runner has an execution repository
+func (r *CollectionRunner) runFlow(flow *models.Flow, vars map[string]string, environment string) FlowRunResult {
+	flowResult := FlowRunResult{
+		FlowID:   flow.ID,
+		FlowName: flow.Name,
+	}
+
+	execution := &models.Execution{
+		FlowID:      flow.ID,
+		Status:      models.ExecutionStatusPending,
+		Environment: environment,
+	}
+	repo := r.executor.repo
+	if repo != nil {
+		if err := repo.Create(execution); err != nil {
+			flowResult.Status = string(models.ExecutionStatusFailed)
+			flowResult.Error = fmt.Sprintf("failed to create execution: %v", err)
+			return flowResult
+		}
+	} else {
+		execution.ID = uuid.New()
+	}
+	flowResult.ExecutionID = execution.ID
+
+	startedAt := time.Now()
+	execution.Status = models.ExecutionStatusRunning
+	execution.StartedAt = &startedAt
+	if repo != nil {
+		if err := repo.Update(execution); err != nil {
+			r.logger.Warn("Failed to update execution", zap.String("execution_id", execution.ID.String()), zap.Error(err))
+		}
+	}
+
+	err := r.newExecutor(flow.WorkspaceID).Execute(execution, &flow.Definition, vars)
+
+	finishedAt := time.Now()
+	execution.FinishedAt = &finishedAt
+	execution.DurationMs = finishedAt.Sub(startedAt).Milliseconds()
+	if err != nil {
+		execution.Status = models.ExecutionStatusFailed
+		execution.Error = err.Error()
+	} else {
+		execution.Status = models.ExecutionStatusCompleted
+	}
+	if repo != nil {
+		if err := repo.Update(execution); err != nil {
+			r.logger.Warn("Failed to update execution", zap.String("execution_id", execution.ID.String()), zap.Error(err))
+		}
+	}
+
+	flowResult.Status = string(execution.Status)
+	flowResult.DurationMs = execution.DurationMs
+	flowResult.Error = execution.Error
+	return flowResult
+}
+
+// newExecutor creates an executor for one flow execution, since executors
+// hold the state of the execution they run
+func (r *CollectionRunner) newExecutor(workspaceID uuid.UUID) *Executor {
+	base := r.executor
+	executor := NewExecutor(base.repo, base.contractRepo, base.logger, base.wsHub, base.mockManager)
+	executor.pluginRegistry = base.pluginRegistry
+	executor.debugController = base.debugController
+	executor.schemaResolver = base.schemaResolver
+	executor.schemaDir = base.schemaDir
+	executor.snapshotRepo = base.snapshotRepo
+	executor.metrics = base.metrics
+	if r.flows != nil {
+		executor.flowResolver = NewWorkspaceFlowResolver(r.flows, workspaceID)
+	}
+	return executor
+}
+
+// rowValue converts a data row value to a variable; lists and objects are
+// passed as JSON
+func rowValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ValidateDataSource validates a data source that needs no workspace data,
+// which are the csv, json, inline and generator types
 func ValidateDataSource(source *DataSource) error {
 	if source == nil {
 		return nil
@@ -338,15 +491,18 @@ func ValidateDataSource(source *DataSource) error {
 			return fmt.Errorf("inline data source has no data")
 		}
 		return nil
+	case "generator":
+		_, _, err := GenerateRows(source.Generator)
+		return err
 	default:
 		return fmt.Errorf("unsupported data source type: %s", source.Type)
 	}
 }
 
-// GetDataPreview returns a preview of the data source
+// GetDataPreview returns a preview of a data source that needs no workspace
+// data, which are the csv, json, inline and generator types
 func GetDataPreview(source *DataSource, maxRows int) ([]DataRow, []string, error) {
 	var rows []DataRow
-	var columns []string
 
 	switch source.Type {
 	case "csv":
@@ -363,16 +519,17 @@ func GetDataPreview(source *DataSource, maxRows int) ([]DataRow, []string, error
 		}
 	case "inline":
 		rows = source.Data
+	case "generator":
+		var err error
+		rows, _, err = GenerateRows(source.Generator)
+		if err != nil {
+			return nil, nil, err
+		}
 	default:
 		return nil, nil, fmt.Errorf("unsupported data source type: %s", source.Type)
 	}
 
-	// Extract column names from first row
-	if len(rows) > 0 {
-		for key := range rows[0] {
-			columns = append(columns, key)
-		}
-	}
+	columns := dataColumns(rows)
 
 	// Limit rows
 	if maxRows > 0 && len(rows) > maxRows {
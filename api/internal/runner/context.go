@@ -3,13 +3,16 @@ package runner
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Context holds execution context with variables and step outputs
+// Context holds execution context with variables and step outputs.
+// It is safe for concurrent use by the branches of a parallel step.
 type Context struct {
+	mu          sync.RWMutex
 	variables   map[string]string
 	stepOutputs map[string]map[string]interface{}
 }
@@ -36,17 +39,23 @@ func NewContext(variables map[string]string, envVars map[string]interface{}) *Co
 
 // Get retrieves a variable value
 func (c *Context) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	value, ok := c.variables[key]
 	return value, ok
 }
 
 // Set sets a variable value
 func (c *Context) Set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.variables[key] = value
 }
 
 // SetStepOutput stores output from a step
 func (c *Context) SetStepOutput(stepID, key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.stepOutputs[stepID] == nil {
 		c.stepOutputs[stepID] = make(map[string]interface{})
 	}
@@ -55,6 +64,8 @@ func (c *Context) SetStepOutput(stepID, key string, value interface{}) {
 
 // GetStepOutput retrieves output from a step
 func (c *Context) GetStepOutput(stepID, key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if outputs, ok := c.stepOutputs[stepID]; ok {
 		value, exists := outputs[key]
 		return value, exists
@@ -62,9 +73,61 @@ func (c *Context) GetStepOutput(stepID, key string) (interface{}, bool) {
 	return nil, false
 }
 
+// StepOutputs returns a copy of all outputs stored for a step
+func (c *Context) StepOutputs(stepID string) (map[string]interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	outputs, ok := c.stepOutputs[stepID]
+	if !ok {
+		return nil, false
+	}
+	copied := make(map[string]interface{}, len(outputs))
+	for k, v := range outputs {
+		copied[k] = v
+	}
+	return copied, true
+}
+
+// Snapshot returns copies of the variables and step outputs
+func (c *Context) Snapshot() (map[string]string, map[string]map[string]interface{}) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	variables := make(map[string]string, len(c.variables))
+	for k, v := range c.variables {
+		variables[k] = v
+	}
+	outputs := make(map[string]map[string]interface{}, len(c.stepOutputs))
+	for stepID, values := range c.stepOutputs {
+		copied := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			copied[k] = v
+		}
+		outputs[stepID] = copied
+	}
+	return variables, outputs
+}
+
+// Restore sets recorded variables and step outputs
+func (c *Context) Restore(variables map[string]string, stepOutputs map[string]map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range variables {
+		c.variables[k] = v
+	}
+	for stepID, values := range stepOutputs {
+		copied := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			copied[k] = v
+		}
+		c.stepOutputs[stepID] = copied
+	}
+}
+
 // Interpolate replaces variables in a string
 // Supports: ${VAR}, ${RANDOM_ID}, ${TIMESTAMP}, ${step.output.field}
 func (c *Context) Interpolate(input string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	result := input
 
 	// Replace built-in functions
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
@@ -231,6 +232,14 @@ func (v *Verifier) verifyInteraction(interaction *models.Interaction, providerBa
 		}
 	}
 
+	// Verify response body against the interaction's schema
+	if interaction.Response.Schema != nil {
+		if mismatches := v.checkSchema(interaction.Response.Schema, respBody); len(mismatches) > 0 {
+			result.Passed = false
+			result.Mismatches = append(result.Mismatches, mismatches...)
+		}
+	}
+
 	// Store actual request/response for debugging
 	result.ActualRequest = map[string]interface{}{
 		"method":  req.Method,
@@ -246,6 +255,38 @@ func (v *Verifier) verifyInteraction(interaction *models.Interaction, providerBa
 	return result
 }
 
+// checkSchema validates a raw response body against a JSON Schema, one mismatch per violation
+func (v *Verifier) checkSchema(schema map[string]interface{}, body []byte) []models.Mismatch {
+	validator, err := assertions.NewSchemaValidator(schema)
+	if err != nil {
+		return []models.Mismatch{{
+			Type:    "schema",
+			Message: fmt.Sprintf("Invalid response schema: %v", err),
+		}}
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return []models.Mismatch{{
+			Type:    "schema",
+			Actual:  string(body),
+			Message: "Response body is not valid JSON",
+		}}
+	}
+
+	violations := validator.Validate(actual)
+	mismatches := make([]models.Mismatch, 0, len(violations))
+	for _, violation := range violations {
+		mismatches = append(mismatches, models.Mismatch{
+			Type:     "schema",
+			Expected: violation.Keyword,
+			Path:     violation.Pointer,
+			Message:  fmt.Sprintf("Schema violation: %s", violation),
+		})
+	}
+	return mismatches
+}
+
 // compareJSON recursively compares JSON structures
 func (v *Verifier) compareJSON(path string, expected, actual interface{}) []models.Mismatch {
 	mismatches := make([]models.Mismatch, 0)
@@ -0,0 +1,213 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataGenerator configures a data source that generates its rows
+type DataGenerator struct {
+	Count  int                    `json:"count"`          // Number of rows
+	Seed   int64                  `json:"seed,omitempty"` // Same seed, same rows; 0 picks one and reports it
+	Fields map[string]interface{} `json:"fields"`         // Column name to template, list of choices or literal
+}
+
+// fakerPattern matches faker placeholders, like ${FAKER.internet.email}
+var fakerPattern = regexp.MustCompile(`\$\{FAKER\.([a-zA-Z]+\.[a-zA-Z]+)\}`)
+
+// fakerFunc generates a fake value from a seeded source
+type fakerFunc func(r *rand.Rand) interface{}
+
+var (
+	fakeFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isla", "Jack", "Maria", "Noah", "Olivia", "Liam", "Sofia", "Lucas"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Martinez", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Martin", "Lee"}
+	fakeCities     = []string{"London", "Berlin", "Sofia", "Paris", "Madrid", "Rome", "Vienna", "Prague", "Lisbon", "Dublin", "Amsterdam", "Warsaw"}
+	fakeCountries  = []string{"United Kingdom", "Germany", "Bulgaria", "France", "Spain", "Italy", "Austria", "Czechia", "Portugal", "Ireland", "Netherlands", "Poland"}
+	fakeStreets    = []string{"Main St", "High St", "Station Rd", "Church Ln", "Park Ave", "Mill Rd", "Victoria St", "Green Ln"}
+	fakeDomains    = []string{"example.com", "example.org", "example.net", "test.local"}
+	fakeCompanies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Vandelay", "Stark", "Wayne"}
+	fakeSuffixes   = []string{"Corp", "Ltd", "Group", "Labs", "Industries", "Systems"}
+	fakeProducts   = []string{"Keyboard", "Monitor", "Headphones", "Backpack", "Lamp", "Chair", "Notebook", "Bottle"}
+	fakeAdjectives = []string{"Ergonomic", "Compact", "Wireless", "Classic", "Premium", "Portable", "Smart", "Recycled"}
+	fakeCurrencies = []string{"USD", "EUR", "GBP", "BGN", "CHF", "JPY"}
+	fakeWords      = []string{"alpha", "beta", "gamma", "delta", "omega", "lorem", "ipsum", "dolor", "amet", "tempor", "magna", "velit"}
+)
+
+// fakerFuncs are the faker placeholders generators support, by category and
+// name like the faker.js API
+var fakerFuncs = map[string]fakerFunc{
+	"name.firstName": func(r *rand.Rand) interface{} { return pick(r, fakeFirstNames) },
+	"name.lastName":  func(r *rand.Rand) interface{} { return pick(r, fakeLastNames) },
+	"name.fullName": func(r *rand.Rand) interface{} {
+		return pick(r, fakeFirstNames) + " " + pick(r, fakeLastNames)
+	},
+	"internet.email": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(pick(r, fakeFirstNames)), strings.ToLower(pick(r, fakeLastNames)), r.Intn(100), pick(r, fakeDomains))
+	},
+	"internet.userName": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%s%d", strings.ToLower(pick(r, fakeFirstNames)), r.Intn(1000))
+	},
+	"internet.url": func(r *rand.Rand) interface{} {
+		return "https://" + pick(r, fakeWords) + "." + pick(r, fakeDomains)
+	},
+	"internet.ip": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%d.%d.%d.%d", 1+r.Intn(223), r.Intn(256), r.Intn(256), 1+r.Intn(254))
+	},
+	"phone.phoneNumber": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("+1-555-%03d-%04d", r.Intn(1000), r.Intn(10000))
+	},
+	"address.city":    func(r *rand.Rand) interface{} { return pick(r, fakeCities) },
+	"address.country": func(r *rand.Rand) interface{} { return pick(r, fakeCountries) },
+	"address.streetAddress": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%d %s", 1+r.Intn(999), pick(r, fakeStreets))
+	},
+	"address.zipCode": func(r *rand.Rand) interface{} { return fmt.Sprintf("%05d", r.Intn(100000)) },
+	"company.companyName": func(r *rand.Rand) interface{} {
+		return pick(r, fakeCompanies) + " " + pick(r, fakeSuffixes)
+	},
+	"commerce.productName": func(r *rand.Rand) interface{} {
+		return pick(r, fakeAdjectives) + " " + pick(r, fakeProducts)
+	},
+	"commerce.price":       func(r *rand.Rand) interface{} { return float64(100+r.Intn(99900)) / 100 },
+	"finance.amount":       func(r *rand.Rand) interface{} { return float64(r.Intn(1000000)) / 100 },
+	"finance.currencyCode": func(r *rand.Rand) interface{} { return pick(r, fakeCurrencies) },
+	"finance.account":      func(r *rand.Rand) interface{} { return fmt.Sprintf("%08d", r.Intn(100000000)) },
+	"finance.creditCardNumber": func(r *rand.Rand) interface{} {
+		return luhnNumber(r, "4", 16)
+	},
+	"datatype.number":  func(r *rand.Rand) interface{} { return r.Intn(100000) },
+	"datatype.boolean": func(r *rand.Rand) interface{} { return r.Intn(2) == 1 },
+	"datatype.uuid": func(r *rand.Rand) interface{} {
+		var id uuid.UUID
+		r.Read(id[:])
+		// Mark as a version 4 UUID
+		id[6] = (id[6] & 0x0f) | 0x40
+		id[8] = (id[8] & 0x3f) | 0x80
+		return id.String()
+	},
+	"date.past": func(r *rand.Rand) interface{} {
+		return fakeDateBase.AddDate(0, 0, -1-r.Intn(365)).Format("2006-01-02")
+	},
+	"date.future": func(r *rand.Rand) interface{} {
+		return fakeDateBase.AddDate(0, 0, 1+r.Intn(365)).Format("2006-01-02")
+	},
+	"lorem.word": func(r *rand.Rand) interface{} { return pick(r, fakeWords) },
+	"lorem.sentence": func(r *rand.Rand) interface{} {
+		words := make([]string, 4+r.Intn(5))
+		for i := range words {
+			words[i] = pick(r, fakeWords)
+		}
+		sentence := strings.Join(words, " ")
+		return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+	},
+}
+
+// fakeDateBase is the date generated dates are relative to, fixed so that a
+// seed always generates the same rows
+var fakeDateBase = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// GenerateRows generates the rows of a generator. Each field is a string
+// with faker placeholders and ${ROW}, a list to pick a value from, or a
+// literal. A string that is a single placeholder keeps the type of its value.
+// Returns the seed used.
+func GenerateRows(gen *DataGenerator) ([]DataRow, int64, error) {
+	if gen == nil {
+		return nil, 0, fmt.Errorf("generator data source has no generator")
+	}
+	if gen.Count <= 0 {
+		return nil, 0, fmt.Errorf("generator count must be positive")
+	}
+	if len(gen.Fields) == 0 {
+		return nil, 0, fmt.Errorf("generator has no fields")
+	}
+	// Fields are generated in name order, so that a seed always gives the
+	// same values
+	names := sortedFieldNames(gen.Fields)
+	for _, name := range names {
+		if s, ok := gen.Fields[name].(string); ok {
+			for _, m := range fakerPattern.FindAllStringSubmatch(s, -1) {
+				if _, ok := fakerFuncs[m[1]]; !ok {
+					return nil, 0, fmt.Errorf("field %s: unknown faker function %s", name, m[1])
+				}
+			}
+		}
+	}
+
+	seed := gen.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	rows := make([]DataRow, gen.Count)
+	for i := range rows {
+		row := make(DataRow, len(names))
+		for _, name := range names {
+			row[name] = generateValue(r, gen.Fields[name], i+1)
+		}
+		rows[i] = row
+	}
+	return rows, seed, nil
+}
+
+func generateValue(r *rand.Rand, field interface{}, row int) interface{} {
+	switch f := field.(type) {
+	case string:
+		f = strings.ReplaceAll(f, "${ROW}", fmt.Sprint(row))
+		// A single placeholder keeps its type, like numbers and booleans
+		if m := fakerPattern.FindStringSubmatch(f); m != nil && m[0] == f {
+			return fakerFuncs[m[1]](r)
+		}
+		return fakerPattern.ReplaceAllStringFunc(f, func(placeholder string) string {
+			name := fakerPattern.FindStringSubmatch(placeholder)[1]
+			return fmt.Sprint(fakerFuncs[name](r))
+		})
+	case []interface{}:
+		if len(f) == 0 {
+			return nil
+		}
+		return f[r.Intn(len(f))]
+	default:
+		return field
+	}
+}
+
+func sortedFieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+// luhnNumber generates a number with a prefix and a valid Luhn check digit
+func luhnNumber(r *rand.Rand, prefix string, length int) string {
+	digits := []byte(prefix)
+	for len(digits) < length-1 {
+		digits = append(digits, byte('0'+r.Intn(10)))
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Doubled digits are those at even positions from the check digit
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return string(digits) + fmt.Sprint((10-sum%10)%10)
+}
@@ -0,0 +1,332 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/georgi-georgiev/testmesh/internal/runner/actions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultExpectColumn is the column with the expected outcome of a data row
+const DefaultExpectColumn = "expected_outcome"
+
+// DefaultLabelColumn is the column naming a data row in reports
+const DefaultLabelColumn = "name"
+
+// executionRowsArtifactLimit is the largest offloaded step output read as
+// the rows of an "execution" data source
+const executionRowsArtifactLimit = 32 << 20
+
+// DataSet holds the rows of a data source that match its filter
+type DataSet struct {
+	Rows     []DataRow
+	Numbers  []int    // Number of each row in the source, from 1
+	Columns  []string // Columns of the rows, sorted
+	Seed     int64    // Seed of a generator
+	Filtered int      // Rows the filter left out
+}
+
+// LoadRows loads the rows of a data source and applies its filter. Data files
+// and executions are read from the workspace; the query and connection of an
+// SQL source can use the variables of the run, which are bound as parameters
+// of the query.
+func (r *CollectionRunner) LoadRows(ctx context.Context, source *DataSource, workspaceID uuid.UUID, variables map[string]string) (*DataSet, error) {
+	data := &DataSet{}
+
+	var rows []DataRow
+	var err error
+	switch source.Type {
+	case "csv", "json", "inline":
+		rows, _, err = GetDataPreview(source, 0)
+	case "generator":
+		rows, data.Seed, err = GenerateRows(source.Generator)
+	case "file":
+		rows, err = r.loadDataFile(source, workspaceID)
+	case "sql":
+		rows, err = r.loadQueryRows(ctx, source, variables)
+	case "execution":
+		rows, err = r.loadExecutionRows(ctx, source, workspaceID)
+	default:
+		return nil, fmt.Errorf("unsupported data source type: %s", source.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var filter func(DataRow) (bool, error)
+	if strings.TrimSpace(source.Filter) != "" {
+		program, err := expr.Compile(source.Filter, expr.AllowUndefinedVariables(), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		filter = func(row DataRow) (bool, error) {
+			matched, err := expr.Run(program, map[string]interface{}(row))
+			if err != nil {
+				return false, err
+			}
+			return matched.(bool), nil
+		}
+	}
+
+	for i, row := range rows {
+		if filter != nil {
+			matched, err := filter(row)
+			if err != nil {
+				return nil, fmt.Errorf("filter failed on row %d: %w", i+1, err)
+			}
+			if !matched {
+				data.Filtered++
+				continue
+			}
+		}
+		if _, err := expectedOutcome(row, source.ExpectColumn); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		data.Rows = append(data.Rows, row)
+		data.Numbers = append(data.Numbers, i+1)
+	}
+	data.Columns = dataColumns(data.Rows)
+
+	return data, nil
+}
+
+// loadDataFile reads the rows of a data file by ID or name
+func (r *CollectionRunner) loadDataFile(source *DataSource, workspaceID uuid.UUID) ([]DataRow, error) {
+	if source.File == "" {
+		return nil, fmt.Errorf("file data source requires a file")
+	}
+	if r.dataFiles == nil || workspaceID == uuid.Nil {
+		return nil, fmt.Errorf("data files are not available outside a workspace")
+	}
+
+	var file *models.DataFile
+	var err error
+	if id, parseErr := uuid.Parse(source.File); parseErr == nil {
+		file, err = r.dataFiles.GetByID(id, workspaceID)
+	} else {
+		file, err = r.dataFiles.GetByName(source.File, workspaceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data file not found: %s", source.File)
+	}
+
+	return ParseDataFile(file.Format, file.Content)
+}
+
+// ParseDataFile parses the content of a data file of a format
+func ParseDataFile(format, content string) ([]DataRow, error) {
+	switch format {
+	case "csv":
+		return ParseCSV(content)
+	case "json":
+		return ParseJSON(content)
+	default:
+		return nil, fmt.Errorf("unsupported data file format: %s", format)
+	}
+}
+
+// queryReference finds the ${...} references of the query of an SQL source
+var queryReference = regexp.MustCompile(`\$\{[^{}]+\}`)
+
+// loadQueryRows runs the SELECT query of an SQL source in a read-only
+// transaction; each row it returns is an iteration
+func (r *CollectionRunner) loadQueryRows(ctx context.Context, source *DataSource, variables map[string]string) ([]DataRow, error) {
+	if source.Query == "" || source.Connection == "" {
+		return nil, fmt.Errorf("sql data source requires a query and a connection")
+	}
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(source.Query)), "SELECT") {
+		return nil, fmt.Errorf("sql data source query must be a SELECT")
+	}
+
+	interp := NewContext(variables, nil)
+	connection := interp.Interpolate(source.Connection)
+	if err := r.checkDataSourceHost(connection); err != nil {
+		return nil, err
+	}
+	query, params := bindQuery(source.Query, source.Params, interp)
+
+	results, err := actions.NewDatabaseHandler(r.logger).QueryReadOnly(ctx, connection, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]DataRow, len(results))
+	for i, result := range results {
+		rows[i] = DataRow(result)
+	}
+	return rows, nil
+}
+
+// bindQuery replaces the ${...} references of a query with placeholders
+// numbered after its params, so that variables are sent as parameters and
+// never become part of the SQL text. A reference used twice is bound once.
+func bindQuery(query string, params []interface{}, interp *Context) (string, []interface{}) {
+	args := append([]interface{}{}, params...)
+	placeholders := make(map[string]string)
+	query = queryReference.ReplaceAllStringFunc(query, func(ref string) string {
+		if placeholder, ok := placeholders[ref]; ok {
+			return placeholder
+		}
+		args = append(args, interp.Interpolate(ref))
+		placeholder := fmt.Sprintf("$%d", len(args))
+		placeholders[ref] = placeholder
+		return placeholder
+	})
+	return query, args
+}
+
+// checkDataSourceHost rejects connections to hosts outside the data source
+// hosts, when they are set
+func (r *CollectionRunner) checkDataSourceHost(connection string) error {
+	if len(r.dataSourceHosts) == 0 {
+		return nil
+	}
+	config, err := pgconn.ParseConfig(connection)
+	if err != nil {
+		return fmt.Errorf("invalid sql data source connection")
+	}
+
+	hosts := append([]*pgconn.FallbackConfig{{Host: config.Host, Port: config.Port}}, config.Fallbacks...)
+	for _, host := range hosts {
+		allowed := false
+		for _, entry := range r.dataSourceHosts {
+			if entry == host.Host || entry == net.JoinHostPort(host.Host, strconv.Itoa(int(host.Port))) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("sql data source host %s is not allowed", host.Host)
+		}
+	}
+	return nil
+}
+
+// loadExecutionRows reads rows from the output of a step of an earlier
+// execution: a list of objects is a row each, an object is one row
+func (r *CollectionRunner) loadExecutionRows(ctx context.Context, source *DataSource, workspaceID uuid.UUID) ([]DataRow, error) {
+	if source.ExecutionID == "" || source.Step == "" {
+		return nil, fmt.Errorf("execution data source requires an execution_id and a step")
+	}
+	repo := r.executor.repo
+	if repo == nil || workspaceID == uuid.Nil {
+		return nil, fmt.Errorf("executions are not available outside a workspace")
+	}
+
+	executionID, err := uuid.Parse(source.ExecutionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution ID: %s", source.ExecutionID)
+	}
+	execution, err := repo.GetByID(executionID)
+	if err != nil || execution.Flow == nil || execution.Flow.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("execution not found: %s", source.ExecutionID)
+	}
+
+	steps, err := repo.GetSteps(executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load execution steps: %w", err)
+	}
+	// The last attempt of a step holds its final output
+	var output models.OutputData
+	for _, step := range steps {
+		if step.StepID == source.Step {
+			output = step.Output
+		}
+	}
+	if output == nil {
+		return nil, fmt.Errorf("step %s has no output in execution %s", source.Step, source.ExecutionID)
+	}
+	// Large values of the output may be stored as artifacts
+	if r.artifacts != nil {
+		output = r.artifacts.ResolveOutput(ctx, output, executionRowsArtifactLimit)
+	}
+
+	value := extractValue(output, source.Path)
+	switch v := value.(type) {
+	case models.OutputData:
+		return []DataRow{DataRow(v)}, nil
+	case map[string]interface{}:
+		return []DataRow{DataRow(v)}, nil
+	case []map[string]interface{}:
+		rows := make([]DataRow, len(v))
+		for i, item := range v {
+			rows[i] = DataRow(item)
+		}
+		return rows, nil
+	case []interface{}:
+		rows := make([]DataRow, 0, len(v))
+		for i, item := range v {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %d at %s is not an object", i+1, source.Path)
+			}
+			rows = append(rows, DataRow(row))
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("value at %s is not an object or a list of objects", source.Path)
+	}
+}
+
+// expectedOutcome returns whether a row is a positive case, "pass", or a
+// negative case, "fail". Rows without an outcome are positive cases.
+func expectedOutcome(row DataRow, column string) (string, error) {
+	if column == "" {
+		column = DefaultExpectColumn
+	}
+	switch v := row[column].(type) {
+	case nil:
+		return "pass", nil
+	case bool:
+		if v {
+			return "pass", nil
+		}
+		return "fail", nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "pass", "passed", "positive", "success", "true":
+			return "pass", nil
+		case "fail", "failed", "negative", "error", "false":
+			return "fail", nil
+		}
+		return "", fmt.Errorf("unknown %s %q, use pass or fail", column, v)
+	default:
+		return "", fmt.Errorf("unknown %s %v, use pass or fail", column, v)
+	}
+}
+
+// rowLabel returns the name of a row in reports
+func rowLabel(row DataRow, column string) string {
+	if column == "" {
+		column = DefaultLabelColumn
+	}
+	if value, ok := row[column]; ok && value != nil {
+		return rowValue(value)
+	}
+	return ""
+}
+
+// dataColumns returns the columns of rows, sorted
+func dataColumns(rows []DataRow) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
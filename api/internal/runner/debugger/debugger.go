@@ -3,9 +3,11 @@ package debugger
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/expr-lang/expr"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -225,6 +227,10 @@ func (c *Controller) Resume(executionID uuid.UUID) error {
 		return fmt.Errorf("execution is not paused")
 	}
 
+	// Set the state before signalling, so that it doesn't overwrite the pause
+	// of a breakpoint the execution reaches right away
+	session.SetState(StateRunning)
+
 	// Signal resume
 	c.mu.RLock()
 	pauseChan, ok := c.pauseSignals[executionID]
@@ -237,7 +243,6 @@ func (c *Controller) Resume(executionID uuid.UUID) error {
 		}
 	}
 
-	session.SetState(StateRunning)
 	c.emitEvent(executionID, "debug.resumed", nil)
 
 	return nil
@@ -285,6 +290,7 @@ func (c *Controller) OnBeforeStep(ctx context.Context, executionID uuid.UUID, st
 		return true, nil
 	}
 
+	stepping := session.GetState() == StateStepping
 	session.SetCurrentStep(stepID)
 	session.SetState(StateRunning)
 
@@ -293,9 +299,24 @@ func (c *Controller) OnBeforeStep(ctx context.Context, executionID uuid.UUID, st
 	breakpoints := bm.GetForStep(stepID)
 
 	shouldPause := false
+	reason := StopStep
 	for _, bp := range breakpoints {
+		if bp.Condition != "" && bp.Enabled {
+			matched, err := c.evaluateCondition(session, bp.Condition)
+			if err != nil {
+				c.logger.Warn("Failed to evaluate breakpoint condition",
+					zap.String("breakpoint_id", bp.ID),
+					zap.String("condition", bp.Condition),
+					zap.Error(err),
+				)
+			}
+			if !matched {
+				continue
+			}
+		}
 		if bp.Hit() {
 			shouldPause = true
+			reason = StopBreakpoint
 			c.emitEvent(executionID, "debug.breakpoint.hit", map[string]interface{}{
 				"breakpoint_id": bp.ID,
 				"step_id":       stepID,
@@ -312,10 +333,16 @@ func (c *Controller) OnBeforeStep(ctx context.Context, executionID uuid.UUID, st
 	}
 
 	// Check for pause command
+	c.mu.RLock()
+	cmdChan := c.commands[executionID]
+	c.mu.RUnlock()
 	select {
-	case cmd := <-c.commands[executionID]:
+	case cmd := <-cmdChan:
 		switch cmd {
 		case CommandPause:
+			if !shouldPause {
+				reason = StopPause
+			}
 			shouldPause = true
 		case CommandStop:
 			return false, fmt.Errorf("execution stopped by user")
@@ -324,21 +351,32 @@ func (c *Controller) OnBeforeStep(ctx context.Context, executionID uuid.UUID, st
 	}
 
 	// If stepping mode, always pause before step
-	if session.GetState() == StateStepping {
+	if stepping {
 		shouldPause = true
 	}
 
 	if shouldPause {
+		stack := Stack(ctx)
+		if len(stack) == 0 {
+			stack = []Frame{{StepID: stepID, StepName: stepName, Action: action}}
+		}
+		session.SetStopped(stack, reason, config, "")
 		return c.waitForResume(ctx, executionID, stepID, stepName, action, config)
 	}
 
 	return true, nil
 }
 
+// StopOnEntry pauses a session before its first step
+func (c *Controller) StopOnEntry(executionID uuid.UUID) error {
+	return c.SendCommand(executionID, CommandPause)
+}
+
 // waitForResume pauses execution and waits for resume signal
 func (c *Controller) waitForResume(ctx context.Context, executionID uuid.UUID, stepID, stepName, action string, config map[string]interface{}) (bool, error) {
 	session, _ := c.GetSession(executionID)
 	session.SetState(StatePaused)
+	reason, _, _ := session.GetStopped()
 
 	c.emitEvent(executionID, "debug.paused", map[string]interface{}{
 		"step_id":   stepID,
@@ -346,6 +384,7 @@ func (c *Controller) waitForResume(ctx context.Context, executionID uuid.UUID, s
 		"action":    action,
 		"config":    config,
 		"variables": session.GetVariables(),
+		"reason":    reason,
 	})
 
 	c.logger.Info("Execution paused",
@@ -364,12 +403,8 @@ func (c *Controller) waitForResume(ctx context.Context, executionID uuid.UUID, s
 		case <-ctx.Done():
 			return false, ctx.Err()
 		case <-pauseChan:
-			// Check if we should step or continue
-			state := session.GetState()
-			if state == StateStepping {
-				// After stepping, pause again at next step
-				session.SetState(StateRunning)
-			}
+			// When stepping, the state stays StateStepping so that the
+			// execution pauses again before the next step
 			c.emitEvent(executionID, "debug.step", map[string]interface{}{
 				"step_id":   stepID,
 				"step_name": stepName,
@@ -387,8 +422,10 @@ func (c *Controller) waitForResume(ctx context.Context, executionID uuid.UUID, s
 	}
 }
 
-// OnAfterStep is called by the executor after each step completes
-func (c *Controller) OnAfterStep(executionID uuid.UUID, stepID string, output map[string]interface{}, err error, duration time.Duration) {
+// OnAfterStep is called by the executor after each step completes. Failed
+// steps pause on error breakpoints, and on assertion breakpoints when an
+// assertion failed, so that their output can be inspected.
+func (c *Controller) OnAfterStep(ctx context.Context, executionID uuid.UUID, stepID string, output map[string]interface{}, err error, duration time.Duration) {
 	session, ok := c.GetSession(executionID)
 	if !ok {
 		return
@@ -410,23 +447,134 @@ func (c *Controller) OnAfterStep(executionID uuid.UUID, stepID string, output ma
 	}
 	session.AddSnapshot(snapshot)
 
+	c.emitEvent(executionID, "debug.variables", map[string]interface{}{
+		"step_id":      stepID,
+		"step_outputs": session.GetStepOutputs(),
+		"variables":    session.GetVariables(),
+	})
+
 	// Check for error breakpoints
 	if err != nil {
 		bm, _ := c.GetBreakpointManager(executionID)
-		for _, bp := range bm.GetErrorBreakpoints() {
-			bp.Hit()
+		breakpoints := bm.GetErrorBreakpoints()
+		if strings.Contains(err.Error(), "assertion failed") {
+			breakpoints = append(breakpoints, bm.GetAssertionBreakpoints()...)
+		}
+
+		shouldPause := false
+		for _, bp := range breakpoints {
+			if bp.Hit() {
+				shouldPause = true
+			}
 			c.emitEvent(executionID, "debug.error", map[string]interface{}{
 				"step_id": stepID,
 				"error":   err.Error(),
 			})
 		}
+
+		if shouldPause {
+			stack := Stack(ctx)
+			if len(stack) == 0 {
+				stack = []Frame{{StepID: stepID}}
+			}
+			session.SetStopped(stack, StopException, nil, err.Error())
+			top := stack[len(stack)-1]
+			// The step has failed either way; stopping only ends the wait early
+			c.waitForResume(ctx, executionID, stepID, top.StepName, top.Action, nil)
+		}
+	}
+}
+
+// Evaluate evaluates an expr expression against the variables and step outputs
+// of a session. Step outputs are available by step ID, variables by name, and
+// both under "steps" and "vars".
+func (c *Controller) Evaluate(executionID uuid.UUID, expression string) (interface{}, error) {
+	session, ok := c.GetSession(executionID)
+	if !ok {
+		return nil, fmt.Errorf("no debug session found for execution %s", executionID)
 	}
 
+	env := evaluationEnv(session)
+	program, err := expr.Compile(expression, expr.Env(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile expression: %w", err)
+	}
+	return expr.Run(program, env)
+}
+
+// SetVariable changes a variable, or a step output when stepID is set, of a
+// paused session. The change applies to the paused step and every later step.
+func (c *Controller) SetVariable(executionID uuid.UUID, stepID, name string, value interface{}) error {
+	session, ok := c.GetSession(executionID)
+	if !ok {
+		return fmt.Errorf("no debug session found for execution %s", executionID)
+	}
+	if session.GetState() != StatePaused {
+		return fmt.Errorf("execution is not paused")
+	}
+	if name == "" {
+		return fmt.Errorf("variable name is required")
+	}
+
+	session.AddOverride(VariableOverride{StepID: stepID, Name: name, Value: value})
 	c.emitEvent(executionID, "debug.variables", map[string]interface{}{
-		"step_id":      stepID,
-		"step_outputs": session.StepOutputs,
+		"step_outputs": session.GetStepOutputs(),
 		"variables":    session.GetVariables(),
 	})
+	return nil
+}
+
+// TakeOverrides returns the variables changed since the last call, for the
+// executor to apply
+func (c *Controller) TakeOverrides(executionID uuid.UUID) []VariableOverride {
+	session, ok := c.GetSession(executionID)
+	if !ok {
+		return nil
+	}
+	return session.TakeOverrides()
+}
+
+// evaluateCondition evaluates the condition of a breakpoint
+func (c *Controller) evaluateCondition(session *DebugSession, condition string) (bool, error) {
+	env := evaluationEnv(session)
+	program, err := expr.Compile(condition, expr.Env(env), expr.AsBool())
+	if err != nil {
+		return false, err
+	}
+	result, err := expr.Run(program, env)
+	if err != nil {
+		return false, err
+	}
+	matched, _ := result.(bool)
+	return matched, nil
+}
+
+// evaluationEnv builds the environment of expressions. The executor reports
+// step outputs as "$<step_id>" variables.
+func evaluationEnv(session *DebugSession) map[string]interface{} {
+	vars := make(map[string]interface{})
+	steps := make(map[string]interface{})
+	for name, value := range session.GetVariables() {
+		if strings.HasPrefix(name, "$") {
+			steps[name[1:]] = value
+		} else {
+			vars[name] = value
+		}
+	}
+	for stepID, output := range session.GetStepOutputs() {
+		steps[stepID] = output
+	}
+
+	env := make(map[string]interface{}, len(vars)+len(steps)+2)
+	for name, value := range vars {
+		env[name] = value
+	}
+	for stepID, output := range steps {
+		env[stepID] = output
+	}
+	env["vars"] = vars
+	env["steps"] = steps
+	return env
 }
 
 // UpdateVariables updates the session variables from the executor context
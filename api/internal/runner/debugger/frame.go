@@ -0,0 +1,28 @@
+package debugger
+
+import "context"
+
+// Frame is a step on the debug stack. Steps nested in a parallel step are
+// on top of the frame of the parallel step.
+type Frame struct {
+	StepID   string `json:"step_id"`
+	StepName string `json:"step_name,omitempty"`
+	Action   string `json:"action"`
+	Phase    string `json:"phase"` // setup, main or teardown
+}
+
+type frameKey struct{}
+
+// WithFrame returns a context with a frame pushed on its stack
+func WithFrame(ctx context.Context, frame Frame) context.Context {
+	parent := Stack(ctx)
+	stack := make([]Frame, len(parent), len(parent)+1)
+	copy(stack, parent)
+	return context.WithValue(ctx, frameKey{}, append(stack, frame))
+}
+
+// Stack returns the frames of a context, outermost first
+func Stack(ctx context.Context) []Frame {
+	stack, _ := ctx.Value(frameKey{}).([]Frame)
+	return stack
+}
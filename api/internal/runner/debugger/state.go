@@ -31,9 +31,32 @@ type DebugSession struct {
 	StartedAt   time.Time              `json:"started_at"`
 	PausedAt    *time.Time             `json:"paused_at,omitempty"`
 	StepHistory []StepSnapshot         `json:"step_history"`
+	Stack       []Frame                `json:"stack"`
+	StopReason  StopReason             `json:"stop_reason,omitempty"`
+	StepConfig  map[string]interface{} `json:"step_config,omitempty"` // Interpolated config of the paused step
+	LastError   string                 `json:"last_error,omitempty"`  // Error of the step an error breakpoint stopped after
+	overrides   []VariableOverride
 	mu          sync.RWMutex
 }
 
+// StopReason tells why a session paused
+type StopReason string
+
+const (
+	StopBreakpoint StopReason = "breakpoint"
+	StopStep       StopReason = "step"
+	StopPause      StopReason = "pause"
+	StopException  StopReason = "exception"
+)
+
+// VariableOverride is a variable or step output changed while paused. The
+// executor applies overrides before it runs the paused step.
+type VariableOverride struct {
+	StepID string      `json:"step_id,omitempty"` // Set for step outputs, empty for variables
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+}
+
 // StepSnapshot captures the state at a particular step
 type StepSnapshot struct {
 	StepID      string                 `json:"step_id"`
@@ -140,6 +163,75 @@ func (s *DebugSession) GetStepOutput(stepID string) (interface{}, bool) {
 	return output, ok
 }
 
+// SetStopped records where and why the session paused
+func (s *DebugSession) SetStopped(stack []Frame, reason StopReason, config map[string]interface{}, errMessage string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Stack = stack
+	s.StopReason = reason
+	s.StepConfig = config
+	s.LastError = errMessage
+}
+
+// GetStopped returns why the session paused, the config of the paused step and
+// the error of the failed step, if any
+func (s *DebugSession) GetStopped() (StopReason, map[string]interface{}, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.StopReason, s.StepConfig, s.LastError
+}
+
+// GetStack returns the frames of the current step, outermost first
+func (s *DebugSession) GetStack() []Frame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Frame(nil), s.Stack...)
+}
+
+// AddOverride queues a changed variable or step output and shows it in the
+// session right away
+func (s *DebugSession) AddOverride(override VariableOverride) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = append(s.overrides, override)
+	if override.StepID == "" {
+		s.Variables[override.Name] = override.Value
+		return
+	}
+	outputs, _ := s.StepOutputs[override.StepID].(map[string]interface{})
+	if outputs == nil {
+		outputs = make(map[string]interface{})
+	} else {
+		copied := make(map[string]interface{}, len(outputs)+1)
+		for k, v := range outputs {
+			copied[k] = v
+		}
+		outputs = copied
+	}
+	outputs[override.Name] = override.Value
+	s.StepOutputs[override.StepID] = outputs
+}
+
+// TakeOverrides returns and clears the queued overrides
+func (s *DebugSession) TakeOverrides() []VariableOverride {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	overrides := s.overrides
+	s.overrides = nil
+	return overrides
+}
+
+// GetStepOutputs returns a copy of the step outputs
+func (s *DebugSession) GetStepOutputs() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	copy := make(map[string]interface{}, len(s.StepOutputs))
+	for k, v := range s.StepOutputs {
+		copy[k] = v
+	}
+	return copy
+}
+
 // AddSnapshot adds a step snapshot to history
 func (s *DebugSession) AddSnapshot(snapshot StepSnapshot) {
 	s.mu.Lock()
@@ -180,5 +272,7 @@ func (s *DebugSession) ToJSON() map[string]interface{} {
 		"started_at":   s.StartedAt,
 		"paused_at":    s.PausedAt,
 		"step_count":   len(s.StepHistory),
+		"stack":        s.Stack,
+		"stop_reason":  s.StopReason,
 	}
 }
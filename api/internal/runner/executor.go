@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/artifacts"
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
 	"github.com/georgi-georgiev/testmesh/internal/plugins"
 	"github.com/georgi-georgiev/testmesh/internal/runner/actions"
 	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
-	"github.com/georgi-georgiev/testmesh/internal/runner/contracts"
 	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
 	"github.com/georgi-georgiev/testmesh/internal/runner/mocks"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
@@ -27,6 +29,18 @@ type Executor struct {
 	mockManager     *mocks.Manager
 	pluginRegistry  *plugins.Registry
 	debugController *debugger.Controller
+	schemaResolver  SchemaDocumentResolver
+	flowResolver    FlowResolver
+	schemaDir       string // Directory schema files are read from
+	snapshotRepo    *repository.SnapshotRepository
+	updateSnapshots bool
+	metrics         *metrics.Metrics
+	artifacts       *artifacts.Manager
+	workspaceID     *uuid.UUID // Workspace recorded with artifacts
+	contextRepo     *repository.ContextSnapshotRepository
+	contextExclude  map[string]bool // Variables left out of context snapshots
+	contextSequence int
+	statsMu         sync.Mutex // Guards execution step counters updated by parallel branches
 }
 
 // WSHub interface for WebSocket broadcasting
@@ -60,6 +74,20 @@ func (e *Executor) SetDebugController(controller *debugger.Controller) {
 	e.debugController = controller
 }
 
+// SetMetrics sets the collectors that record step outcomes
+func (e *Executor) SetMetrics(m *metrics.Metrics) {
+	e.metrics = m
+}
+
+// SetArtifacts sets the manager that offloads screenshots, attached files and
+// large values of step outputs, recording them for a workspace
+func (e *Executor) SetArtifacts(manager *artifacts.Manager, workspaceID uuid.UUID) {
+	e.artifacts = manager
+	if workspaceID != uuid.Nil {
+		e.workspaceID = &workspaceID
+	}
+}
+
 // GetDebugController returns the debug controller
 func (e *Executor) GetDebugController() *debugger.Controller {
 	return e.debugController
@@ -75,23 +103,23 @@ func (e *Executor) ExecuteWithoutPersistence(ctx context.Context, flow *models.F
 
 	// Execute setup steps
 	if len(definition.Setup) > 0 {
-		if err := e.executeStepsWithoutPersistence(ctx, definition.Setup, execCtx); err != nil {
+		if err := e.executeStepsWithoutPersistence(ctx, definition.Setup, execCtx, "step_"); err != nil {
 			return fmt.Errorf("setup failed: %w", err)
 		}
 	}
 
 	// Execute main steps
-	if err := e.executeStepsWithoutPersistence(ctx, definition.Steps, execCtx); err != nil {
+	if err := e.executeStepsWithoutPersistence(ctx, definition.Steps, execCtx, "step_"); err != nil {
 		// Run teardown even if main steps fail
 		if len(definition.Teardown) > 0 {
-			e.executeStepsWithoutPersistence(ctx, definition.Teardown, execCtx)
+			e.executeStepsWithoutPersistence(ctx, definition.Teardown, execCtx, "step_")
 		}
 		return fmt.Errorf("execution failed: %w", err)
 	}
 
 	// Execute teardown steps
 	if len(definition.Teardown) > 0 {
-		if err := e.executeStepsWithoutPersistence(ctx, definition.Teardown, execCtx); err != nil {
+		if err := e.executeStepsWithoutPersistence(ctx, definition.Teardown, execCtx, "step_"); err != nil {
 			return fmt.Errorf("teardown failed: %w", err)
 		}
 	}
@@ -99,16 +127,34 @@ func (e *Executor) ExecuteWithoutPersistence(ctx context.Context, flow *models.F
 	return nil
 }
 
-// executeStepsWithoutPersistence executes steps without DB writes
-func (e *Executor) executeStepsWithoutPersistence(ctx context.Context, steps []models.Step, execCtx *Context) error {
+// executeStepsWithoutPersistence executes steps without DB writes. Steps
+// without an ID get the ID prefix followed by their position.
+func (e *Executor) executeStepsWithoutPersistence(ctx context.Context, steps []models.Step, execCtx *Context, idPrefix string) error {
 	for i, step := range steps {
 		stepID := step.ID
 		if stepID == "" {
-			stepID = fmt.Sprintf("step_%d", i)
+			stepID = fmt.Sprintf("%s%d", idPrefix, i)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
 		// Execute the step (skip retry for load testing performance)
-		result, err := e.executeStep(ctx, &step, execCtx)
+		var result models.OutputData
+		var err error
+		switch step.Action {
+		case "parallel":
+			result, err = e.executeParallel(ctx, &step, stepID, execCtx, func(ctx context.Context, branch models.ParallelBranch) error {
+				return e.executeStepsWithoutPersistence(ctx, branch.Steps, execCtx, branchStepIDPrefix(stepID, branch.Name))
+			})
+		case "run_flow":
+			result, err = e.executeRunFlow(ctx, &step, stepID, execCtx, func(ctx context.Context, steps []models.Step, subCtx *Context, _, idPrefix string) error {
+				return e.executeStepsWithoutPersistence(ctx, steps, subCtx, idPrefix)
+			})
+		default:
+			result, err = e.executeStep(ctx, &step, execCtx)
+		}
 		if err != nil {
 			return fmt.Errorf("step %s failed: %w", stepID, err)
 		}
@@ -128,46 +174,82 @@ func (e *Executor) executeStepsWithoutPersistence(ctx context.Context, steps []m
 
 // Execute runs a flow definition
 func (e *Executor) Execute(execution *models.Execution, definition *models.FlowDefinition, variables map[string]string) error {
+	return e.ExecuteFrom(execution, definition, variables, nil)
+}
+
+// ExecuteFrom runs a flow definition from a start point. Steps before it are
+// skipped and the recorded context replaces the variables it has in common
+// with the given ones. A nil start point runs the whole flow.
+func (e *Executor) ExecuteFrom(execution *models.Execution, definition *models.FlowDefinition, variables map[string]string, start *StartPoint) error {
 	ctx := context.Background()
 
 	// Create execution context
 	execCtx := NewContext(variables, definition.Env)
 
-	// Count total steps
-	totalSteps := len(definition.Setup) + len(definition.Steps) + len(definition.Teardown)
+	setup, steps, teardown := definition.Setup, definition.Steps, definition.Teardown
+	setupFrom, stepsFrom, teardownFrom := 0, 0, 0
+	if start != nil {
+		execCtx.Restore(start.Variables, start.StepOutputs)
+		switch start.Phase {
+		case "setup":
+			setupFrom = start.StepIndex
+		case "main":
+			setup = nil
+			stepsFrom = start.StepIndex
+		case "teardown":
+			setup, steps = nil, nil
+			teardownFrom = start.StepIndex
+		default:
+			return fmt.Errorf("unknown phase %q", start.Phase)
+		}
+		e.logger.Info("Restarting execution from step",
+			zap.String("execution_id", execution.ID.String()),
+			zap.String("phase", start.Phase),
+			zap.Int("step_index", start.StepIndex),
+		)
+	}
+
+	// Count total steps, including those inside parallel branches
+	totalSteps := countSteps(stepsAfter(setup, setupFrom)) + countSteps(stepsAfter(steps, stepsFrom)) + countSteps(stepsAfter(teardown, teardownFrom))
 	execution.TotalSteps = totalSteps
 
-	// Broadcast execution started
+	// Broadcast execution started. The schedule run lets run streams pick up
+	// executions created after they opened.
 	if e.wsHub != nil {
-		e.wsHub.BroadcastExecutionStarted(execution.ID, map[string]interface{}{
+		data := map[string]interface{}{
 			"flow_name":   definition.Name,
 			"total_steps": totalSteps,
-		})
+			"attempt":     execution.Attempt,
+		}
+		if execution.ScheduleRunID != nil {
+			data["schedule_run_id"] = execution.ScheduleRunID.String()
+		}
+		e.wsHub.BroadcastExecutionStarted(execution.ID, data)
 	}
 
 	// Execute setup steps
-	if len(definition.Setup) > 0 {
-		e.logger.Info("Executing setup steps", zap.Int("count", len(definition.Setup)))
-		if err := e.executeSteps(ctx, execution, definition.Setup, execCtx, "setup"); err != nil {
+	if len(setup) > 0 {
+		e.logger.Info("Executing setup steps", zap.Int("count", len(setup)-setupFrom))
+		if err := e.executeStepsFrom(ctx, execution, setup, setupFrom, execCtx, "setup", "setup_"); err != nil {
 			return fmt.Errorf("setup failed: %w", err)
 		}
 	}
 
 	// Execute main steps
-	e.logger.Info("Executing main steps", zap.Int("count", len(definition.Steps)))
-	if err := e.executeSteps(ctx, execution, definition.Steps, execCtx, "main"); err != nil {
+	e.logger.Info("Executing main steps", zap.Int("count", len(steps)-stepsFrom))
+	if err := e.executeStepsFrom(ctx, execution, steps, stepsFrom, execCtx, "main", "main_"); err != nil {
 		// Run teardown even if main steps fail
-		if len(definition.Teardown) > 0 {
+		if len(teardown) > 0 {
 			e.logger.Info("Executing teardown steps after failure")
-			e.executeSteps(ctx, execution, definition.Teardown, execCtx, "teardown")
+			e.executeStepsFrom(ctx, execution, teardown, teardownFrom, execCtx, "teardown", "teardown_")
 		}
 		return fmt.Errorf("execution failed: %w", err)
 	}
 
 	// Execute teardown steps
-	if len(definition.Teardown) > 0 {
-		e.logger.Info("Executing teardown steps", zap.Int("count", len(definition.Teardown)))
-		if err := e.executeSteps(ctx, execution, definition.Teardown, execCtx, "teardown"); err != nil {
+	if len(teardown) > 0 {
+		e.logger.Info("Executing teardown steps", zap.Int("count", len(teardown)-teardownFrom))
+		if err := e.executeStepsFrom(ctx, execution, teardown, teardownFrom, execCtx, "teardown", "teardown_"); err != nil {
 			return fmt.Errorf("teardown failed: %w", err)
 		}
 	}
@@ -175,12 +257,32 @@ func (e *Executor) Execute(execution *models.Execution, definition *models.FlowD
 	return nil
 }
 
+// stepsAfter returns the steps from an index on
+func stepsAfter(steps []models.Step, from int) []models.Step {
+	if from >= len(steps) {
+		return nil
+	}
+	return steps[from:]
+}
+
 // executeSteps executes a slice of steps
-func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution, steps []models.Step, execCtx *Context, phase string) error {
-	for i, step := range steps {
+func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution, steps []models.Step, execCtx *Context, phase, idPrefix string) error {
+	return e.executeStepsFrom(ctx, execution, steps, 0, execCtx, phase, idPrefix)
+}
+
+// executeStepsFrom executes the steps of a slice from an index on. Steps
+// without an ID get the ID prefix followed by their position in the whole
+// slice.
+func (e *Executor) executeStepsFrom(ctx context.Context, execution *models.Execution, steps []models.Step, from int, execCtx *Context, phase, idPrefix string) error {
+	for i := from; i < len(steps); i++ {
+		step := steps[i]
 		stepID := step.ID
 		if stepID == "" {
-			stepID = fmt.Sprintf("%s_%d", phase, i)
+			stepID = fmt.Sprintf("%s%d", idPrefix, i)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return NewExecutionError(phase, stepID, step.Name, step.Action, "cancelled", err)
 		}
 
 		e.logger.Info("Executing step",
@@ -189,6 +291,10 @@ func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution
 			zap.String("phase", phase),
 		)
 
+		if e.contextRepo != nil && execution.RecordContext {
+			e.recordContext(ctx, execution.ID, execCtx, phase, i, stepID, &step)
+		}
+
 		// Create step record
 		execStep := &models.ExecutionStep{
 			ExecutionID: execution.ID,
@@ -214,20 +320,56 @@ func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution
 			})
 		}
 
-		// Execute the step with retry logic
-		result, err := e.executeStepWithRetry(ctx, &step, execStep, execCtx, execution.ID)
+		// Execute the step with retry logic; parallel steps run their branches
+		// through executeSteps so that inner steps are recorded individually
+		var result models.OutputData
+		var err error
+		stepCtx := debugger.WithFrame(ctx, debugger.Frame{StepID: stepID, StepName: step.Name, Action: step.Action, Phase: phase})
+		switch step.Action {
+		case "parallel":
+			result, err = e.executeParallel(stepCtx, &step, stepID, execCtx, func(ctx context.Context, branch models.ParallelBranch) error {
+				return e.executeSteps(ctx, execution, branch.Steps, execCtx, phase, branchStepIDPrefix(stepID, branch.Name))
+			})
+		case "run_flow":
+			result, err = e.executeRunFlow(stepCtx, &step, stepID, execCtx, func(ctx context.Context, steps []models.Step, subCtx *Context, subPhase, idPrefix string) error {
+				e.addTotalSteps(execution, steps)
+				return e.executeSteps(ctx, execution, steps, subCtx, subPhase, idPrefix)
+			})
+		default:
+			result, err = e.executeStepWithRetry(stepCtx, &step, execStep, execCtx, execution.ID)
+		}
 
 		// Update step record
 		finishedAt := time.Now()
 		execStep.FinishedAt = &finishedAt
 		execStep.DurationMs = finishedAt.Sub(*execStep.StartedAt).Milliseconds()
 
+		if err != nil && cancelledByJoin(ctx) {
+			// The parallel join no longer needs this branch; the step was
+			// stopped, not failed, and is left out of the failed steps
+			execStep.Status = models.StepStatusSkipped
+			execStep.ErrorMessage = "cancelled: " + err.Error()
+			e.repo.UpdateStep(execStep)
+			e.metrics.StepFinished(step.Action, string(execStep.Status), finishedAt.Sub(*execStep.StartedAt), execStep.Attempt)
+
+			if e.wsHub != nil {
+				e.wsHub.BroadcastStepCompleted(execution.ID, map[string]interface{}{
+					"step_id":     stepID,
+					"step_name":   step.Name,
+					"status":      string(execStep.Status),
+					"duration_ms": execStep.DurationMs,
+				})
+			}
+			return NewExecutionError(phase, stepID, step.Name, step.Action, "cancelled", err)
+		}
+
 		if err != nil {
 			execStep.Status = models.StepStatusFailed
 			execStep.ErrorMessage = err.Error()
 			e.repo.UpdateStep(execStep)
 
-			execution.FailedSteps++
+			e.countStep(execution, false)
+			e.metrics.StepFinished(step.Action, string(execStep.Status), finishedAt.Sub(*execStep.StartedAt), execStep.Attempt)
 
 			// Broadcast step failed
 			if e.wsHub != nil {
@@ -253,9 +395,13 @@ func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution
 
 		execStep.Status = models.StepStatusCompleted
 		execStep.Output = result
+		if e.artifacts != nil {
+			execStep.Output = e.artifacts.Offload(ctx, e.workspaceID, execStep, result)
+		}
 		e.repo.UpdateStep(execStep)
 
-		execution.PassedSteps++
+		e.countStep(execution, true)
+		e.metrics.StepFinished(step.Action, string(execStep.Status), finishedAt.Sub(*execStep.StartedAt), execStep.Attempt)
 
 		// Broadcast step completed
 		if e.wsHub != nil {
@@ -281,6 +427,17 @@ func (e *Executor) executeSteps(ctx context.Context, execution *models.Execution
 	return nil
 }
 
+// countStep updates the execution step counters
+func (e *Executor) countStep(execution *models.Execution, passed bool) {
+	e.statsMu.Lock()
+	defer e.statsMu.Unlock()
+	if passed {
+		execution.PassedSteps++
+	} else {
+		execution.FailedSteps++
+	}
+}
+
 // executeStepWithRetry executes a step with retry logic
 func (e *Executor) executeStepWithRetry(ctx context.Context, step *models.Step, execStep *models.ExecutionStep, execCtx *Context, executionID uuid.UUID) (models.OutputData, error) {
 	maxAttempts := 1
@@ -366,39 +523,58 @@ func (e *Executor) executeStepWithDebug(ctx context.Context, step *models.Step,
 	// Interpolate variables in config
 	config := interpolator.InterpolateMap(step.Config)
 
+	// Steps without an ID are known to the debugger by their generated ID
+	stepID := step.ID
+	if stack := debugger.Stack(ctx); len(stack) > 0 {
+		stepID = stack[len(stack)-1].StepID
+	}
+
 	// Debug: Check breakpoints before step execution
 	if e.debugController != nil && executionID != uuid.Nil {
 		// Update debugger with current variables
+		variables, stepOutputs := execCtx.Snapshot()
 		vars := make(map[string]interface{})
-		for k, v := range execCtx.variables {
+		for k, v := range variables {
 			vars[k] = v
 		}
-		for stepID, outputs := range execCtx.stepOutputs {
+		for stepID, outputs := range stepOutputs {
 			vars["$"+stepID] = outputs
 		}
 		e.debugController.UpdateVariables(executionID, vars)
 
 		// Call debug hook before step
-		shouldContinue, err := e.debugController.OnBeforeStep(ctx, executionID, step.ID, step.Name, step.Action, config)
+		shouldContinue, err := e.debugController.OnBeforeStep(ctx, executionID, stepID, step.Name, step.Action, config)
 		if err != nil {
 			return nil, fmt.Errorf("debug error: %w", err)
 		}
 		if !shouldContinue {
 			return nil, fmt.Errorf("execution stopped")
 		}
+
+		// Apply variables changed while paused
+		if overrides := e.debugController.TakeOverrides(executionID); len(overrides) > 0 {
+			for _, override := range overrides {
+				if override.StepID != "" {
+					execCtx.SetStepOutput(override.StepID, override.Name, override.Value)
+				} else {
+					execCtx.Set(override.Name, fmt.Sprint(override.Value))
+				}
+			}
+			config = interpolator.InterpolateMap(step.Config)
+		}
 	}
 
 	// Get action handler
 	handler, err := e.getActionHandler(step.Action)
 	if err != nil {
-		e.notifyDebugAfterStep(executionID, step.ID, nil, err, time.Since(startTime))
+		e.notifyDebugAfterStep(ctx, executionID, stepID, nil, err, time.Since(startTime))
 		return nil, err
 	}
 
 	// Execute action
 	result, err := handler.Execute(ctx, config)
 	if err != nil {
-		e.notifyDebugAfterStep(executionID, step.ID, result, err, time.Since(startTime))
+		e.notifyDebugAfterStep(ctx, executionID, stepID, result, err, time.Since(startTime))
 		return nil, err
 	}
 
@@ -407,102 +583,72 @@ func (e *Executor) executeStepWithDebug(ctx context.Context, step *models.Step,
 		evaluator := assertions.NewEvaluator(result)
 		if err := evaluator.Evaluate(step.Assert); err != nil {
 			assertErr := fmt.Errorf("assertion failed: %w", err)
-			e.notifyDebugAfterStep(executionID, step.ID, result, assertErr, time.Since(startTime))
+			e.notifyDebugAfterStep(ctx, executionID, stepID, result, assertErr, time.Since(startTime))
 			return result, assertErr
 		}
 		e.logger.Info("All assertions passed", zap.Int("count", len(step.Assert)))
 	}
 
+	// Run schema assertions if any
+	if len(step.Schema) > 0 {
+		if err := e.evaluateSchemaAssertions(step, result, executionID); err != nil {
+			schemaErr := fmt.Errorf("schema assertion failed: %w", err)
+			e.notifyDebugAfterStep(ctx, executionID, stepID, result, schemaErr, time.Since(startTime))
+			return result, schemaErr
+		}
+		e.logger.Info("All schema assertions passed", zap.Int("count", len(step.Schema)))
+	}
+
+	// Compare against the golden snapshot if enabled
+	if step.Snapshot != nil {
+		if err := e.evaluateSnapshot(step, result, executionID); err != nil {
+			snapshotErr := fmt.Errorf("snapshot assertion failed: %w", err)
+			e.notifyDebugAfterStep(ctx, executionID, stepID, result, snapshotErr, time.Since(startTime))
+			return result, snapshotErr
+		}
+	}
+
 	// Debug: Notify after successful step
-	e.notifyDebugAfterStep(executionID, step.ID, result, nil, time.Since(startTime))
+	e.notifyDebugAfterStep(ctx, executionID, stepID, result, nil, time.Since(startTime))
 
 	return result, nil
 }
 
 // notifyDebugAfterStep notifies the debugger after step completion
-func (e *Executor) notifyDebugAfterStep(executionID uuid.UUID, stepID string, output models.OutputData, err error, duration time.Duration) {
+func (e *Executor) notifyDebugAfterStep(ctx context.Context, executionID uuid.UUID, stepID string, output models.OutputData, err error, duration time.Duration) {
 	if e.debugController != nil && executionID != uuid.Nil {
-		e.debugController.OnAfterStep(executionID, stepID, output, err, duration)
+		e.debugController.OnAfterStep(ctx, executionID, stepID, output, err, duration)
 	}
 }
 
 // getActionHandler returns the appropriate action handler
 func (e *Executor) getActionHandler(actionType string) (actions.Handler, error) {
-	switch actionType {
-	case "http_request":
-		return actions.NewHTTPHandler(e.logger), nil
-	case "database_query":
-		return actions.NewDatabaseHandler(e.logger), nil
-	case "log":
-		return actions.NewLogHandler(e.logger), nil
-	case "delay":
-		return actions.NewDelayHandler(e.logger), nil
-	case "transform":
-		return actions.NewTransformHandler(e.logger), nil
-	case "assert":
-		return actions.NewAssertHandler(e.logger), nil
-	case "condition":
-		return actions.NewConditionHandler(e.logger, nil), nil
-	case "for_each":
-		return actions.NewForEachHandler(e.logger, nil), nil
-	case "mock_server_start":
-		if e.mockManager == nil {
-			return nil, fmt.Errorf("mock manager not initialized")
-		}
-		return actions.NewMockServerStartHandler(e.mockManager, e.logger), nil
-	case "mock_server_stop":
-		if e.mockManager == nil {
-			return nil, fmt.Errorf("mock manager not initialized")
-		}
-		return actions.NewMockServerStopHandler(e.mockManager, e.logger), nil
-	case "mock_server_configure":
-		if e.mockManager == nil {
-			return nil, fmt.Errorf("mock manager not initialized")
-		}
-		return actions.NewMockServerConfigureHandler(e.mockManager, e.logger), nil
-	case "contract_generate":
-		if e.contractRepo == nil {
-			return nil, fmt.Errorf("contract repository not initialized")
-		}
-		generator := contracts.NewGenerator(e.contractRepo, e.logger)
-		return actions.NewContractGenerateHandler(generator, e.repo, e.logger), nil
-	case "contract_verify":
-		if e.contractRepo == nil {
-			return nil, fmt.Errorf("contract repository not initialized")
-		}
-		verifier := contracts.NewVerifier(e.contractRepo, e.logger)
-		differ := contracts.NewDiffer(e.contractRepo, e.logger)
-		return actions.NewContractVerifyHandler(verifier, differ, e.logger), nil
-	case "kafka_consumer":
-		return actions.NewKafkaConsumerHandler(e.logger), nil
-	case "kafka_producer":
-		return actions.NewKafkaProducerHandler(e.logger), nil
-	case "wait_for":
-		return actions.NewWaitForHandler(e.logger), nil
-	case "db_poll":
-		return actions.NewDBPollHandler(e.logger), nil
-	case "websocket":
-		return actions.NewWebSocketHandler(e.logger), nil
-	case "grpc":
-		return actions.NewGRPCHandler(e.logger), nil
-	default:
-		// Check plugin registry for custom actions
-		if e.pluginRegistry != nil {
-			// First try exact match (e.g., "kafka")
-			if plugin, ok := e.pluginRegistry.GetAction(actionType); ok {
-				return &PluginActionAdapter{plugin: plugin, action: actionType, logger: e.logger}, nil
-			}
+	services := actions.Services{
+		Logger:     e.logger,
+		Mocks:      e.mockManager,
+		Contracts:  e.contractRepo,
+		Executions: e.repo,
+	}
+	if handler, ok := actions.NewHandler(actionType, services); ok {
+		return handler, nil
+	}
 
-			// Then try prefix match for namespaced actions (e.g., "kafka.produce" -> "kafka")
-			if idx := strings.Index(actionType, "."); idx > 0 {
-				pluginName := actionType[:idx]
-				if plugin, ok := e.pluginRegistry.GetAction(pluginName); ok {
-					return &PluginActionAdapter{plugin: plugin, action: actionType, logger: e.logger}, nil
-				}
+	// Check plugin registry for custom actions
+	if e.pluginRegistry != nil {
+		// First try exact match (e.g., "kafka")
+		if plugin, ok := e.pluginRegistry.GetAction(actionType); ok {
+			return &PluginActionAdapter{plugin: plugin, action: actionType, logger: e.logger}, nil
+		}
+
+		// Then try prefix match for namespaced actions (e.g., "kafka.produce" -> "kafka")
+		if idx := strings.Index(actionType, "."); idx > 0 {
+			pluginName := actionType[:idx]
+			if plugin, ok := e.pluginRegistry.GetAction(pluginName); ok {
+				return &PluginActionAdapter{plugin: plugin, action: actionType, logger: e.logger}, nil
 			}
 		}
-		return nil, fmt.Errorf("unknown action type: %s", actionType)
 	}
+	return nil, fmt.Errorf("unknown action type: %s", actionType)
 }
 
 // PluginActionAdapter wraps a plugin to implement the Handler interface
@@ -533,6 +679,19 @@ func (a *PluginActionAdapter) Execute(ctx context.Context, config map[string]int
 	return result, nil
 }
 
+// ConfigSchema describes the config of the plugin action, when the plugin
+// describes its actions
+func (a *PluginActionAdapter) ConfigSchema() actions.ActionSchema {
+	if describer, ok := a.plugin.(plugins.ActionDescriber); ok {
+		for _, def := range describer.Actions() {
+			if def.ID == a.action {
+				return actions.ActionSchema{Action: a.action, Description: def.Description, Schema: def.Schema}
+			}
+		}
+	}
+	return actions.ActionSchema{Action: a.action}
+}
+
 // extractValue extracts a value from result using JSONPath
 func extractValue(result models.OutputData, path string) interface{} {
 	if path == "" || path == "$" {
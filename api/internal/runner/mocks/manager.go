@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
@@ -25,12 +26,14 @@ type Manager struct {
 	logger  *zap.Logger
 	servers map[uuid.UUID]*ServerInstance
 	baseURL string
+	metrics *metrics.Metrics
 	mu      sync.RWMutex
 }
 
 // ServerInstance represents an in-memory mock server (no TCP listener)
 type ServerInstance struct {
 	ID          uuid.UUID
+	Name        string
 	ExecutionID *uuid.UUID
 	BaseURL     string
 	Matcher     *EndpointMatcher
@@ -47,6 +50,18 @@ func NewManager(repo *repository.MockRepository, logger *zap.Logger, baseURL str
 	}
 }
 
+// SetMetrics sets the collectors that record mock server hits
+func (m *Manager) SetMetrics(metrics *metrics.Metrics) {
+	m.metrics = metrics
+}
+
+// ServerCount returns the number of running mock servers
+func (m *Manager) ServerCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.servers)
+}
+
 // RestoreRunningServers re-registers all DB-persisted running servers into the in-memory map.
 // Call this once at startup so servers created in previous process lifetimes keep working.
 func (m *Manager) RestoreRunningServers() {
@@ -74,6 +89,7 @@ func (m *Manager) RestoreRunningServers() {
 
 		instance := &ServerInstance{
 			ID:          s.ID,
+			Name:        s.Name,
 			ExecutionID: s.ExecutionID,
 			BaseURL:     s.BaseURL,
 			Matcher:     NewEndpointMatcher(endpoints, m.logger),
@@ -127,6 +143,7 @@ func (m *Manager) StartServer(ctx context.Context, serverID uuid.UUID, name stri
 	// Store instance
 	instance := &ServerInstance{
 		ID:          serverID,
+		Name:        name,
 		ExecutionID: executionID,
 		BaseURL:     serverBaseURL,
 		Matcher:     matcher,
@@ -214,6 +231,10 @@ func (m *Manager) AddEndpoint(serverID uuid.UUID, endpoint *models.MockEndpoint)
 		return fmt.Errorf("server %s not running", serverID)
 	}
 
+	if err := ValidateResponseConfig(&endpoint.ResponseConfig); err != nil {
+		return err
+	}
+
 	// Create endpoint in database
 	if err := m.repo.CreateEndpoint(endpoint); err != nil {
 		return fmt.Errorf("failed to create endpoint: %w", err)
@@ -385,6 +406,7 @@ func (m *Manager) handleRequest(serverID uuid.UUID, instance *ServerInstance, me
 
 	// Match endpoint
 	endpoint, matched := instance.Matcher.Match(method, path, headers, queryParams, reqBody)
+	m.metrics.MockRequest(instance.Name, matched)
 
 	mockRequest := &models.MockRequest{
 		MockServerID: serverID,
@@ -434,27 +456,50 @@ func (m *Manager) handleRequest(serverID uuid.UUID, instance *ServerInstance, me
 		time.Sleep(time.Duration(response.DelayMs) * time.Millisecond)
 	}
 
+	// Render response body — apply template rendering to all body types
+	var body []byte
+	isJSON := false
+	if response.BodyJSON != nil {
+		body = m.renderBodyJSON(response.BodyJSON, tmplCtx)
+		isJSON = true
+	} else if response.BodyText != "" {
+		body = []byte(m.renderTemplate(response.BodyText, tmplCtx))
+	} else if response.Body != nil {
+		if bodyBytes, err := json.Marshal(response.Body); err == nil {
+			body = []byte(m.renderTemplate(string(bodyBytes), tmplCtx))
+			isJSON = true
+		}
+	}
+
 	// Set response headers
 	for k, v := range response.Headers {
 		w.Header().Set(k, v)
 	}
+	// JSON is only the default; a configured Content-Type is kept
+	if isJSON && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	// Check the served body against the endpoint's schema so drifting mocks are visible
+	if violations := validateServedBody(response, body); len(violations) > 0 {
+		w.Header().Set(SchemaViolationsHeader, fmt.Sprintf("%d", len(violations)))
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.String()
+		}
+		m.logger.Warn("Mock response does not match its schema",
+			zap.String("method", method),
+			zap.String("path", path),
+			zap.Strings("violations", messages),
+		)
+	}
 
 	// Set status code
 	mockRequest.ResponseCode = response.StatusCode
 	w.WriteHeader(response.StatusCode)
 
-	// Write response body — apply template rendering to all body types
-	if response.BodyJSON != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(m.renderBodyJSON(response.BodyJSON, tmplCtx))
-	} else if response.BodyText != "" {
-		w.Write([]byte(m.renderTemplate(response.BodyText, tmplCtx)))
-	} else if response.Body != nil {
-		if bodyBytes, err := json.Marshal(response.Body); err == nil {
-			w.Header().Set("Content-Type", "application/json")
-			rendered := m.renderTemplate(string(bodyBytes), tmplCtx)
-			w.Write([]byte(rendered))
-		}
+	if len(body) > 0 {
+		w.Write(body)
 	}
 
 	// Log request to database (async)
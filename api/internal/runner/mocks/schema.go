@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// SchemaViolationsHeader reports how many schema violations a served mock body had
+const SchemaViolationsHeader = "X-TestMesh-Schema-Violations"
+
+// ValidateResponseConfig checks a mock response body against its schema.
+// Templated bodies are only known per request, so they are checked when served.
+func ValidateResponseConfig(rc *models.ResponseConfig) error {
+	if rc.Schema == nil {
+		return nil
+	}
+
+	validator, err := assertions.NewSchemaValidator(rc.Schema)
+	if err != nil {
+		return fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	if rc.Template {
+		return nil
+	}
+
+	var body interface{}
+	switch {
+	case rc.BodyJSON != nil:
+		body = rc.BodyJSON
+	case rc.Body != nil:
+		body = rc.Body
+	default:
+		return nil
+	}
+
+	if err := validator.Check(body); err != nil {
+		return fmt.Errorf("mock response does not match its schema: %w", err)
+	}
+	return nil
+}
+
+// validateServedBody checks a rendered JSON body against the response schema
+func validateServedBody(rc *models.ResponseConfig, body []byte) []assertions.SchemaViolation {
+	if rc.Schema == nil || len(body) == 0 {
+		return nil
+	}
+
+	validator, err := assertions.NewSchemaValidator(rc.Schema)
+	if err != nil {
+		return []assertions.SchemaViolation{{Keyword: "schema", Message: err.Error()}}
+	}
+
+	var instance interface{}
+	if err := json.Unmarshal(body, &instance); err != nil {
+		return []assertions.SchemaViolation{{Keyword: "type", Message: "body is not valid JSON"}}
+	}
+	return validator.Validate(instance)
+}
@@ -0,0 +1,247 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"go.uber.org/zap"
+)
+
+// Branch statuses reported in the output of a parallel step
+const (
+	branchPassed    = "passed"
+	branchFailed    = "failed"
+	branchCancelled = "cancelled"
+)
+
+// errJoinDone cancels the branches still running once the join strategy has
+// its answer, so that their steps are not reported as failures
+var errJoinDone = errors.New("parallel join completed")
+
+// cancelledByJoin reports whether ctx was cancelled because a parallel join
+// completed rather than because the execution was stopped
+func cancelledByJoin(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errJoinDone)
+}
+
+// branchRunner runs the steps of one parallel branch
+type branchRunner func(ctx context.Context, branch models.ParallelBranch) error
+
+// branchStepIDPrefix returns the prefix of the generated IDs of the steps of
+// a parallel branch without one, which are <parallel_id>.<branch>.<n>
+func branchStepIDPrefix(parallelID, branch string) string {
+	return parallelID + "." + branch + "."
+}
+
+// branchResult is the outcome of one parallel branch
+type branchResult struct {
+	name     string
+	status   string
+	err      error
+	duration time.Duration
+}
+
+// executeParallel runs the branches of a parallel step concurrently and joins
+// them according to the configured strategy. Branches share the execution
+// context, so inner step outputs are available by step ID after the join.
+func (e *Executor) executeParallel(ctx context.Context, step *models.Step, stepID string, execCtx *Context, run branchRunner) (models.OutputData, error) {
+	cfg, err := models.ParseParallelConfig(step.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.Info("Executing parallel branches",
+		zap.String("step_id", stepID),
+		zap.Int("branches", len(cfg.Branches)),
+		zap.String("join", string(cfg.Join)),
+		zap.Int("max_concurrent", cfg.MaxConcurrent),
+	)
+
+	startTime := time.Now()
+	results := runBranches(ctx, cfg, run)
+
+	result := parallelOutput(cfg, results, stepID, execCtx)
+	result["duration_ms"] = time.Since(startTime).Milliseconds()
+
+	if err := joinError(cfg.Join, results); err != nil {
+		return result, err
+	}
+
+	if len(step.Assert) > 0 {
+		evaluator := assertions.NewEvaluator(result)
+		if err := evaluator.Evaluate(step.Assert); err != nil {
+			return result, fmt.Errorf("assertion failed: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// runBranches starts every branch, honouring max_concurrent, and cancels the
+// remaining branches once the join strategy has its answer
+func runBranches(ctx context.Context, cfg *models.ParallelConfig, run branchRunner) []branchResult {
+	joinCtx, cancelCause := context.WithCancelCause(ctx)
+	cancel := func() { cancelCause(errJoinDone) }
+	defer cancel()
+
+	limit := cfg.MaxConcurrent
+	if limit <= 0 || limit > len(cfg.Branches) {
+		limit = len(cfg.Branches)
+	}
+	sem := make(chan struct{}, limit)
+
+	results := make([]branchResult, len(cfg.Branches))
+	var wg sync.WaitGroup
+
+	for i, branch := range cfg.Branches {
+		wg.Add(1)
+		go func(i int, branch models.ParallelBranch) {
+			defer wg.Done()
+			results[i] = branchResult{name: branch.Name}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-joinCtx.Done():
+				results[i].status = branchCancelled
+				results[i].err = joinCtx.Err()
+				return
+			}
+			if joinCtx.Err() != nil {
+				results[i].status = branchCancelled
+				results[i].err = joinCtx.Err()
+				return
+			}
+
+			start := time.Now()
+			err := run(joinCtx, branch)
+			results[i].duration = time.Since(start)
+
+			switch {
+			case err == nil:
+				results[i].status = branchPassed
+				if cfg.Join == models.JoinAny {
+					cancel()
+				}
+			case joinCtx.Err() != nil && ctx.Err() == nil:
+				// Stopped by the join, not by its own failure
+				results[i].status = branchCancelled
+				results[i].err = err
+			default:
+				results[i].status = branchFailed
+				results[i].err = err
+				if cfg.Join == models.JoinFailFast {
+					cancel()
+				}
+			}
+		}(i, branch)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// parallelOutput builds the step output: per-branch status and outputs plus counters
+func parallelOutput(cfg *models.ParallelConfig, results []branchResult, stepID string, execCtx *Context) models.OutputData {
+	branches := make(map[string]interface{}, len(results))
+	counts := map[string]int{}
+	winner := ""
+
+	for i, r := range results {
+		outputs := make(map[string]interface{})
+		prefix := branchStepIDPrefix(stepID, r.name)
+		for j, s := range cfg.Branches[i].Steps {
+			id := s.ID
+			if id == "" {
+				id = fmt.Sprintf("%s%d", prefix, j)
+			}
+			if values, ok := execCtx.StepOutputs(id); ok {
+				outputs[id] = values
+			}
+		}
+
+		branch := map[string]interface{}{
+			"status":      r.status,
+			"duration_ms": r.duration.Milliseconds(),
+			"outputs":     outputs,
+		}
+		if r.err != nil {
+			branch["error"] = r.err.Error()
+		}
+		branches[r.name] = branch
+		counts[r.status]++
+
+		if r.status == branchPassed && winner == "" {
+			winner = r.name
+		}
+	}
+
+	result := models.OutputData{
+		"join":      string(cfg.Join),
+		"branches":  branches,
+		"passed":    counts[branchPassed],
+		"failed":    counts[branchFailed],
+		"cancelled": counts[branchCancelled],
+	}
+	if cfg.Join == models.JoinAny {
+		result["winner"] = winner
+	}
+	return result
+}
+
+// joinError reports whether the parallel step failed under its join strategy
+func joinError(join models.JoinStrategy, results []branchResult) error {
+	var failed []string
+	passed := 0
+	for _, r := range results {
+		switch r.status {
+		case branchPassed:
+			passed++
+		case branchFailed:
+			failed = append(failed, fmt.Sprintf("%s: %v", r.name, r.err))
+		}
+	}
+
+	if join == models.JoinAny {
+		if passed > 0 {
+			return nil
+		}
+		if len(failed) == 0 {
+			return fmt.Errorf("no parallel branch passed")
+		}
+		return fmt.Errorf("no parallel branch passed (%s)", strings.Join(failed, "; "))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d parallel branch(es) failed (%s)", len(failed), strings.Join(failed, "; "))
+	}
+	if passed < len(results) {
+		return fmt.Errorf("parallel branches cancelled")
+	}
+	return nil
+}
+
+// countSteps returns the number of steps including those inside parallel branches
+func countSteps(steps []models.Step) int {
+	total := 0
+	for _, step := range steps {
+		total++
+		if step.Action != "parallel" {
+			continue
+		}
+		cfg, err := models.ParseParallelConfig(step.Config)
+		if err != nil {
+			continue
+		}
+		for _, branch := range cfg.Branches {
+			total += countSteps(branch.Steps)
+		}
+	}
+	return total
+}
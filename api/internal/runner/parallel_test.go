@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"go.uber.org/zap"
+)
+
+// logStep is a step without an ID that logs a message
+func logStep(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"action": "log",
+		"config": map[string]interface{}{"message": message},
+	}
+}
+
+func TestParallelBranchStepIDs(t *testing.T) {
+	executor := NewExecutor(nil, nil, zap.NewNop(), nil, nil)
+	execCtx := NewContext(nil, nil)
+
+	steps := []models.Step{
+		{
+			ID:     "fanout",
+			Action: "parallel",
+			Config: map[string]interface{}{
+				"branches": []interface{}{
+					map[string]interface{}{"name": "left", "steps": []interface{}{logStep("left first"), logStep("left second")}},
+					map[string]interface{}{"name": "right", "steps": []interface{}{logStep("right first")}},
+				},
+			},
+		},
+		// A second parallel step with the same unnamed branches
+		{
+			Action: "parallel",
+			Config: map[string]interface{}{
+				"branches": []interface{}{
+					map[string]interface{}{"steps": []interface{}{logStep("unnamed")}},
+					map[string]interface{}{"steps": []interface{}{logStep("unnamed")}},
+				},
+			},
+		},
+	}
+	if err := executor.executeStepsWithoutPersistence(context.Background(), steps, execCtx, "step_"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	expected := map[string]string{
+		"fanout.left.0":     "left first",
+		"fanout.left.1":     "left second",
+		"fanout.right.0":    "right first",
+		"step_1.branch_0.0": "unnamed",
+		"step_1.branch_1.0": "unnamed",
+	}
+	for id, message := range expected {
+		outputs, ok := execCtx.StepOutputs(id)
+		if !ok {
+			t.Errorf("no outputs for step %s", id)
+			continue
+		}
+		if outputs["message"] != message {
+			t.Errorf("step %s: message = %v, want %q", id, outputs["message"], message)
+		}
+	}
+
+	parallel, ok := execCtx.StepOutputs("fanout")
+	if !ok {
+		t.Fatal("no outputs for the parallel step")
+	}
+	branches, _ := parallel["branches"].(map[string]interface{})
+	left, _ := branches["left"].(map[string]interface{})
+	leftOutputs, _ := left["outputs"].(map[string]interface{})
+	if _, ok := leftOutputs["fanout.left.1"]; !ok || len(leftOutputs) != 2 {
+		t.Errorf("left branch outputs = %v, want fanout.left.0 and fanout.left.1", leftOutputs)
+	}
+}
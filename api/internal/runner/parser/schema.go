@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/actions"
+)
+
+// Catalog lists the actions flows may use, with the schema of their config
+type Catalog struct {
+	actions    map[string]ActionSpec
+	namespaces map[string]bool
+}
+
+// ActionSpec describes an action of a catalog
+type ActionSpec struct {
+	Action      string                 `json:"action"`
+	Description string                 `json:"description,omitempty"`
+	Plugin      string                 `json:"plugin,omitempty"` // Empty for built-in actions
+	Schema      map[string]interface{} `json:"schema,omitempty"` // JSON Schema of the step's config
+}
+
+// NewCatalog returns a catalog of the built-in actions
+func NewCatalog() *Catalog {
+	c := &Catalog{
+		actions:    make(map[string]ActionSpec),
+		namespaces: make(map[string]bool),
+	}
+	for _, schema := range actions.BuiltinSchemas() {
+		c.AddAction(ActionSpec{Action: schema.Action, Description: schema.Description, Schema: schema.Schema})
+	}
+	return c
+}
+
+// AddAction adds an action, such as one a plugin describes
+func (c *Catalog) AddAction(spec ActionSpec) {
+	c.actions[spec.Action] = spec
+}
+
+// AddNamespace accepts every action named after a plugin that does not
+// describe its actions, as "<namespace>" or "<namespace>.<anything>"
+func (c *Catalog) AddNamespace(namespace string) {
+	c.namespaces[namespace] = true
+}
+
+// Lookup returns the spec of an action. Actions of a namespace are known but
+// have no schema.
+func (c *Catalog) Lookup(action string) (ActionSpec, bool) {
+	if spec, ok := c.actions[action]; ok {
+		return spec, true
+	}
+	namespace := action
+	if idx := strings.Index(action, "."); idx > 0 {
+		namespace = action[:idx]
+	}
+	if c.namespaces[namespace] {
+		return ActionSpec{Action: action, Plugin: namespace}, true
+	}
+	return ActionSpec{}, false
+}
+
+// Actions returns every action, sorted by name
+func (c *Catalog) Actions() []ActionSpec {
+	specs := make([]ActionSpec, 0, len(c.actions))
+	for _, spec := range c.actions {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Action < specs[j].Action })
+	return specs
+}
+
+// Namespaces returns the namespaces of plugins that do not describe their actions
+func (c *Catalog) Namespaces() []string {
+	namespaces := make([]string, 0, len(c.namespaces))
+	for namespace := range c.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// suggest returns the known action closest to an unknown one, or ""
+func (c *Catalog) suggest(action string) string {
+	best, bestDistance := "", 4
+	for name := range c.actions {
+		if d := editDistance(action, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// FlowSchema returns a JSON Schema of flow files using the actions of a
+// catalog. Editors use it for completion and inline errors; each step's
+// config is checked against the schema of its action.
+func FlowSchema(c *Catalog) map[string]interface{} {
+	specs := c.Actions()
+
+	names := make([]interface{}, len(specs))
+	conditions := make([]interface{}, 0, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Action
+		if spec.Schema == nil {
+			continue
+		}
+		config := spec.Schema
+		if spec.Action == "parallel" {
+			config = parallelSchema(config)
+		}
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"action": map[string]interface{}{"const": spec.Action}},
+				"required":   []interface{}{"action"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{"config": config},
+			},
+		})
+	}
+
+	action := map[string]interface{}{"description": "Action the step runs", "enum": names}
+	if namespaces := c.Namespaces(); len(namespaces) > 0 {
+		quoted := make([]string, len(namespaces))
+		for i, namespace := range namespaces {
+			quoted[i] = regexp.QuoteMeta(namespace)
+		}
+		action = map[string]interface{}{
+			"description": "Action the step runs",
+			"anyOf": []interface{}{
+				map[string]interface{}{"enum": names},
+				map[string]interface{}{"type": "string", "pattern": `^(` + strings.Join(quoted, "|") + `)(\..+)?$`},
+			},
+		}
+	}
+
+	str := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+	steps := map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/definitions/step"}}
+
+	flow := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name", "steps"},
+		"properties": map[string]interface{}{
+			"name":        str("Flow name"),
+			"description": str("What the flow tests"),
+			"suite":       str("Suite the flow belongs to"),
+			"tags":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"env":         map[string]interface{}{"type": "object", "description": "Variables, referenced as ${NAME}"},
+			"setup":       withDescription(steps, "Steps run before the flow's steps"),
+			"steps":       withDescription(steps, "Steps of the flow"),
+			"teardown":    withDescription(steps, "Steps run after the flow's steps, even when they fail"),
+		},
+	}
+
+	step := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"action"},
+		"properties": map[string]interface{}{
+			"id":          map[string]interface{}{"type": "string", "description": "Step ID; outputs are referenced as ${id.key}", "pattern": `^[a-zA-Z_][a-zA-Z0-9_]*$`},
+			"action":      action,
+			"name":        str("Step name"),
+			"description": str("What the step does"),
+			"config":      map[string]interface{}{"type": "object", "description": "Config of the action"},
+			"assert":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Assertion expressions on the step's output"},
+			"schema":      map[string]interface{}{"type": []interface{}{"object", "array"}, "description": "JSON Schema assertions with a schema, file, ref or from_previous_run"},
+			"snapshot":    map[string]interface{}{"type": []interface{}{"object", "boolean"}, "description": "Compare the output with an approved snapshot"},
+			"output":      map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}, "description": "Output names and the JSON paths extracting them"},
+			"retry": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"max_attempts": map[string]interface{}{"type": "integer"},
+					"delay":        str("Delay between attempts"),
+					"backoff":      map[string]interface{}{"enum": []interface{}{"fixed", "exponential"}, "description": "How the delay grows (default: fixed)"},
+				},
+			},
+			"timeout": str("Step timeout, e.g. 30s"),
+		},
+		"allOf": conditions,
+	}
+
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "TestMesh flow",
+		"description": "A flow file, with the flow at the top level or under a flow key",
+		"anyOf": []interface{}{
+			map[string]interface{}{
+				"type":       "object",
+				"required":   []interface{}{"flow"},
+				"properties": map[string]interface{}{"flow": map[string]interface{}{"$ref": "#/definitions/flow"}},
+			},
+			map[string]interface{}{"$ref": "#/definitions/flow"},
+		},
+		"definitions": map[string]interface{}{
+			"flow":  flow,
+			"step":  step,
+			"steps": steps,
+		},
+	}
+}
+
+// parallelSchema describes the nested steps of a parallel step as steps, so
+// editors complete them too
+func parallelSchema(config map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for key, value := range config["properties"].(map[string]interface{}) {
+		properties[key] = value
+	}
+	properties["steps"] = map[string]interface{}{"$ref": "#/definitions/steps"}
+	properties["branches"] = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"steps"},
+			"properties": map[string]interface{}{
+				"name":  map[string]interface{}{"type": "string"},
+				"steps": map[string]interface{}{"$ref": "#/definitions/steps"},
+			},
+		},
+	}
+
+	schema := make(map[string]interface{})
+	for key, value := range config {
+		schema[key] = value
+	}
+	schema["properties"] = properties
+	return schema
+}
+
+func withDescription(schema map[string]interface{}, description string) map[string]interface{} {
+	out := map[string]interface{}{"description": description}
+	for key, value := range schema {
+		out[key] = value
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,425 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of diagnostics
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a problem found in a flow file. Lines and columns start at 1.
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"` // yaml_syntax, missing_field, unknown_action, ...
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"` // Where in the flow, e.g. steps[2].config.url
+}
+
+// String formats the diagnostic as "<line>:<column>: <severity>: <message>"
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// stepReference matches ${step_id.path}, as the interpolator does
+var stepReference = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\.([\w.]+)\}`)
+
+// yamlErrorLine finds the line in yaml.v3 errors like "yaml: line 4: ..."
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// referenceRoots are the roots of ${root.path} references that are not step IDs
+var referenceRoots = map[string]bool{
+	"env": true, "data": true, "input": true, "item": true, "index": true,
+	"flow": true, "execution": true, "secrets": true, "request": true, "response": true,
+}
+
+// stepFields are the fields the runner reads from a step
+var stepFields = map[string]bool{
+	"id": true, "action": true, "name": true, "description": true, "config": true, "assert": true,
+	"schema": true, "snapshot": true, "output": true, "retry": true, "timeout": true,
+}
+
+// step is a step of the flow being validated, in the order steps run
+type step struct {
+	node   *yaml.Node
+	path   string
+	id     string
+	action *yaml.Node
+	order  int
+}
+
+type validator struct {
+	catalog     *Catalog
+	steps       []*step
+	ids         map[string]*step
+	itemNames   map[string]bool
+	diagnostics []Diagnostic
+}
+
+// Validate checks a flow file and reports every problem it finds with its
+// line and column: YAML syntax, missing fields, unknown actions, duplicate
+// step IDs, configs that do not match their action's schema and references
+// to steps that do not exist. A nil catalog has only the built-in actions.
+func Validate(content []byte, catalog *Catalog) []Diagnostic {
+	if catalog == nil {
+		catalog = NewCatalog()
+	}
+	v := &validator{
+		catalog:   catalog,
+		ids:       make(map[string]*step),
+		itemNames: make(map[string]bool),
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		line := 1
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		message = yamlErrorLine.ReplaceAllString(message, "")
+		return []Diagnostic{{Line: line, Column: 1, Severity: SeverityError, Code: "yaml_syntax", Message: strings.TrimSpace(message)}}
+	}
+	if len(doc.Content) == 0 {
+		return []Diagnostic{{Line: 1, Column: 1, Severity: SeverityError, Code: "missing_field", Message: "flow file is empty"}}
+	}
+
+	flow, prefix := doc.Content[0], ""
+	if wrapped := mappingValue(flow, "flow"); wrapped != nil {
+		flow, prefix = wrapped, "flow."
+	}
+	if flow.Kind != yaml.MappingNode {
+		v.add(flow, SeverityError, "invalid_flow", strings.TrimSuffix(prefix, "."), "flow must be a mapping")
+		return v.diagnostics
+	}
+
+	if name := mappingValue(flow, "name"); name == nil || name.Value == "" {
+		v.add(flow, SeverityError, "missing_field", prefix+"name", "flow name is required")
+	}
+	if steps := mappingValue(flow, "steps"); steps == nil || len(steps.Content) == 0 {
+		v.add(flow, SeverityError, "missing_field", prefix+"steps", "flow must have at least one step")
+	}
+
+	for _, phase := range []string{"setup", "steps", "teardown"} {
+		if node := mappingValue(flow, phase); node != nil {
+			v.collect(node, prefix+phase)
+		}
+	}
+	for _, s := range v.steps {
+		v.checkStep(s)
+	}
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.diagnostics
+}
+
+// collect records the steps of a list, then their nested steps, in the
+// order they run. Step IDs must be unique across the whole flow, since step
+// outputs are stored by ID.
+func (v *validator) collect(list *yaml.Node, path string) {
+	if list.Kind != yaml.SequenceNode {
+		if list.Kind != yaml.ScalarNode || list.Tag != "!!null" {
+			v.add(list, SeverityError, "invalid_flow", path, path+" must be a list of steps")
+		}
+		return
+	}
+
+	for i, node := range list.Content {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		if node.Kind != yaml.MappingNode {
+			v.add(node, SeverityError, "invalid_step", stepPath, "step must be a mapping")
+			continue
+		}
+
+		s := &step{node: node, path: stepPath, action: mappingValue(node, "action"), order: len(v.steps)}
+		v.steps = append(v.steps, s)
+
+		if id := mappingValue(node, "id"); id != nil && id.Value != "" {
+			s.id = id.Value
+			if first, ok := v.ids[s.id]; ok {
+				v.add(id, SeverityError, "duplicate_id", stepPath+".id",
+					fmt.Sprintf("duplicate step ID %q, first used on line %d", s.id, first.node.Line))
+			} else {
+				v.ids[s.id] = s
+			}
+		}
+
+		config := mappingValue(node, "config")
+		if config == nil || config.Kind != yaml.MappingNode {
+			continue
+		}
+		for _, key := range []string{"item_name", "index_name"} {
+			if name := mappingValue(config, key); name != nil && name.Value != "" {
+				v.itemNames[name.Value] = true
+			}
+		}
+		if nested := mappingValue(config, "steps"); nested != nil {
+			v.collect(nested, stepPath+".config.steps")
+		}
+		if branches := mappingValue(config, "branches"); branches != nil && branches.Kind == yaml.SequenceNode {
+			for j, branch := range branches.Content {
+				if nested := mappingValue(branch, "steps"); nested != nil {
+					v.collect(nested, fmt.Sprintf("%s.config.branches[%d].steps", stepPath, j))
+				}
+			}
+		}
+	}
+}
+
+// checkStep checks a step's fields, action, config and references
+func (v *validator) checkStep(s *step) {
+	for i := 0; i < len(s.node.Content); i += 2 {
+		key := s.node.Content[i]
+		if !stepFields[key.Value] {
+			v.add(key, SeverityWarning, "unknown_field", s.path+"."+key.Value,
+				fmt.Sprintf("unknown step field %q is ignored", key.Value))
+		}
+	}
+
+	config := mappingValue(s.node, "config")
+	if s.action == nil || s.action.Value == "" {
+		v.add(s.node, SeverityError, "missing_field", s.path+".action", fmt.Sprintf("%s: action is required", s.label()))
+	} else if spec, ok := v.catalog.Lookup(s.action.Value); !ok {
+		message := fmt.Sprintf("unknown action %q", s.action.Value)
+		if suggestion := v.catalog.suggest(s.action.Value); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		v.add(s.action, SeverityError, "unknown_action", s.path+".action", message)
+	} else if spec.Schema != nil {
+		v.checkConfig(s, config, spec)
+	}
+
+	// Mock servers render their own {{...}} and ${...} templates per request
+	skipConfig := s.action != nil && strings.HasPrefix(s.action.Value, "mock_server_")
+	for i := 0; i < len(s.node.Content); i += 2 {
+		key, value := s.node.Content[i], s.node.Content[i+1]
+		if key.Value == "config" && skipConfig {
+			continue
+		}
+		v.checkReferences(s, value, key.Value == "config")
+	}
+}
+
+// checkConfig validates a step's config against its action's schema
+func (v *validator) checkConfig(s *step, config *yaml.Node, spec ActionSpec) {
+	var instance interface{} = map[string]interface{}{}
+	if config != nil {
+		var raw interface{}
+		if err := config.Decode(&raw); err != nil {
+			v.add(config, SeverityError, "invalid_config", s.path+".config", err.Error())
+			return
+		}
+		if raw != nil {
+			instance = assertions.NormalizeJSON(raw)
+		}
+	}
+
+	schemaValidator, err := assertions.NewSchemaValidator(spec.Schema)
+	if err != nil {
+		return
+	}
+	for _, violation := range schemaValidator.Validate(instance) {
+		node := s.action
+		if config != nil {
+			node = nodeAt(config, violation.Pointer)
+		}
+		path := s.path + ".config" + pointerPath(violation.Pointer)
+		message := violation.Message
+		if violation.Keyword == "anyOf" {
+			message = describeMismatch(spec.Schema, instance, violation)
+		}
+		v.add(node, SeverityError, "invalid_config", path,
+			fmt.Sprintf("%s: %s: %s", s.action.Value, strings.TrimPrefix(path, s.path+"."), message))
+	}
+}
+
+// checkReferences reports ${step.path} references to steps that do not exist
+// or run later. Nested steps of a config are checked as steps of their own.
+func (v *validator) checkReferences(s *step, node *yaml.Node, isConfig bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		for _, match := range stepReference.FindAllStringSubmatchIndex(node.Value, -1) {
+			root := node.Value[match[2]:match[3]]
+			reference := node.Value[match[0]:match[1]]
+			if referenceRoots[root] || v.itemNames[root] || unicode.IsUpper(rune(root[0])) {
+				continue
+			}
+			line, column := scalarPosition(node, match[0])
+			target, ok := v.ids[root]
+			switch {
+			case !ok:
+				v.addAt(line, column, SeverityError, "undefined_reference", s.path,
+					fmt.Sprintf("%s refers to step %q, which does not exist", reference, root))
+			case target.order > s.order:
+				v.addAt(line, column, SeverityWarning, "forward_reference", s.path,
+					fmt.Sprintf("%s refers to step %q, which runs later", reference, root))
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if isConfig && (node.Content[i].Value == "steps" || node.Content[i].Value == "branches") {
+				continue
+			}
+			v.checkReferences(s, node.Content[i+1], false)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			v.checkReferences(s, item, false)
+		}
+	}
+}
+
+func (v *validator) add(node *yaml.Node, severity, code, path, message string) {
+	v.addAt(node.Line, node.Column, severity, code, path, message)
+}
+
+func (v *validator) addAt(line, column int, severity, code, path, message string) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+		Path:     path,
+	})
+}
+
+// label names a step in messages by its ID, or its place in the flow
+func (s *step) label() string {
+	if s.id != "" {
+		return fmt.Sprintf("step %q", s.id)
+	}
+	return s.path
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// nodeAt returns the node a JSON pointer points to, or the deepest node on
+// the way when the rest does not exist
+func nodeAt(node *yaml.Node, pointer string) *yaml.Node {
+	if pointer == "" {
+		return node
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, token)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// pointerPath formats a JSON pointer like the paths of diagnostics
+func pointerPath(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if _, err := strconv.Atoi(token); err == nil {
+			b.WriteString("[" + token + "]")
+		} else {
+			b.WriteString("." + token)
+		}
+	}
+	return b.String()
+}
+
+// describeMismatch explains an anyOf violation of a value that also accepts
+// a ${...} reference with the schema of the value itself
+func describeMismatch(schema map[string]interface{}, instance interface{}, violation assertions.SchemaViolation) string {
+	current := interface{}(schema)
+	for _, token := range strings.Split(strings.TrimPrefix(violation.Pointer, "/"), "/") {
+		s, _ := current.(map[string]interface{})
+		if properties, ok := s["properties"].(map[string]interface{}); ok && properties[token] != nil {
+			current = properties[token]
+		} else if items, ok := s["items"]; ok {
+			current = items
+		} else {
+			return violation.Message
+		}
+	}
+	s, _ := current.(map[string]interface{})
+	anyOf, _ := s["anyOf"].([]interface{})
+	if len(anyOf) == 0 {
+		return violation.Message
+	}
+	value, err := assertions.ResolvePointer(instance, violation.Pointer)
+	if err != nil {
+		return violation.Message
+	}
+	sub, err := assertions.NewSchemaValidator(anyOf[0])
+	if err != nil {
+		return violation.Message
+	}
+	if violations := sub.Validate(value); len(violations) > 0 {
+		return violations[0].Message
+	}
+	return violation.Message
+}
+
+// scalarPosition returns the line and column of an offset into a scalar.
+// Offsets into multi-line scalars point at the scalar.
+func scalarPosition(node *yaml.Node, offset int) (int, int) {
+	switch node.Style {
+	case 0:
+		if !strings.Contains(node.Value, "\n") {
+			return node.Line, node.Column + offset
+		}
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		if !strings.Contains(node.Value, "\n") {
+			return node.Line, node.Column + 1 + offset
+		}
+	}
+	return node.Line, node.Column
+}
@@ -44,21 +44,42 @@ func validateDefinition(def *models.FlowDefinition) error {
 		return fmt.Errorf("flow must have at least one step")
 	}
 
-	// Validate each step
-	for i, step := range def.Steps {
+	return validateSteps(def.Steps, "step ")
+}
+
+// validateSteps validates each step, descending into parallel branches
+func validateSteps(steps []models.Step, prefix string) error {
+	for i, step := range steps {
 		if step.Action == "" {
-			return fmt.Errorf("step %d: action is required", i)
+			return fmt.Errorf("%s%d: action is required", prefix, i)
+		}
+
+		for j, sa := range step.Schema {
+			if sa.Sources() != 1 {
+				return fmt.Errorf("%s%d (%s): schema[%d]: exactly one of schema, file, ref or from_previous_run must be set", prefix, i, step.ID, j)
+			}
 		}
 
 		// Validate action-specific config
 		switch step.Action {
 		case "http_request":
 			if err := validateHTTPConfig(step.Config); err != nil {
-				return fmt.Errorf("step %d (%s): %w", i, step.ID, err)
+				return fmt.Errorf("%s%d (%s): %w", prefix, i, step.ID, err)
 			}
 		case "database_query":
 			if err := validateDatabaseConfig(step.Config); err != nil {
-				return fmt.Errorf("step %d (%s): %w", i, step.ID, err)
+				return fmt.Errorf("%s%d (%s): %w", prefix, i, step.ID, err)
+			}
+		case "parallel":
+			cfg, err := models.ParseParallelConfig(step.Config)
+			if err != nil {
+				return fmt.Errorf("%s%d (%s): %w", prefix, i, step.ID, err)
+			}
+			for _, branch := range cfg.Branches {
+				branchPrefix := fmt.Sprintf("%s%d (%s) branch %s step ", prefix, i, step.ID, branch.Name)
+				if err := validateSteps(branch.Steps, branchPrefix); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/debugger"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StartPoint restarts an execution at a top-level step with the context
+// recorded before that step
+type StartPoint struct {
+	Phase       string // setup, main or teardown
+	StepIndex   int
+	Variables   map[string]string
+	StepOutputs map[string]map[string]interface{}
+}
+
+// redactedValue replaces sensitive values in recorded context, as secrets are
+// masked in environment exports
+const redactedValue = "********"
+
+// RedactedVariables lists the recorded variables whose values were redacted,
+// which a restart cannot run with
+func RedactedVariables(variables map[string]string) []string {
+	var names []string
+	for name, value := range variables {
+		if value == redactedValue {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sensitiveKey matches the names of variables and output fields whose values
+// are credentials, such as tokens and authorization headers
+var sensitiveKey = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|api[_-]?key|authorization|cookie|credential|private[_-]?key)`)
+
+// SetContextRecorder records the variables and step outputs before each step
+// of executions with record_context, leaving out the variables in exclude,
+// such as secrets. Their values and sensitive fields are redacted wherever
+// they appear in step outputs.
+func (e *Executor) SetContextRecorder(repo *repository.ContextSnapshotRepository, exclude []string) {
+	e.contextRepo = repo
+	e.contextExclude = make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		e.contextExclude[name] = true
+	}
+}
+
+// recordContext records the context before a step. Failures are logged: an
+// execution does not fail because its context could not be recorded.
+func (e *Executor) recordContext(ctx context.Context, executionID uuid.UUID, execCtx *Context, phase string, index int, stepID string, step *models.Step) {
+	variables, outputs := execCtx.Snapshot()
+	var secrets []string
+	for name, value := range variables {
+		if e.contextExclude[name] {
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+			delete(variables, name)
+		} else if sensitiveKey.MatchString(name) {
+			variables[name] = redactedValue
+		}
+	}
+	for stepID, values := range outputs {
+		outputs[stepID] = redactValue(values, secrets).(map[string]interface{})
+	}
+
+	// The frame of the step is not pushed yet, so the top frame is the
+	// parallel step of a nested step
+	var parentStepID string
+	if stack := debugger.Stack(ctx); len(stack) > 0 {
+		parentStepID = stack[len(stack)-1].StepID
+	}
+
+	e.statsMu.Lock()
+	e.contextSequence++
+	sequence := e.contextSequence
+	e.statsMu.Unlock()
+
+	snapshot := &models.ContextSnapshot{
+		ExecutionID:  executionID,
+		Sequence:     sequence,
+		Phase:        phase,
+		StepID:       stepID,
+		StepName:     step.Name,
+		Action:       step.Action,
+		StepIndex:    index,
+		ParentStepID: parentStepID,
+		Variables:    variables,
+		StepOutputs:  outputs,
+	}
+	if err := e.contextRepo.Create(snapshot); err != nil {
+		e.logger.Warn("Failed to record execution context",
+			zap.String("execution_id", executionID.String()),
+			zap.String("step_id", stepID),
+			zap.Error(err),
+		)
+	}
+}
+
+// redactValue returns a copy of a recorded value with the fields of sensitive
+// names and the secret values replaced
+func redactValue(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if sensitiveKey.MatchString(key) {
+				redacted[key] = redactedValue
+				continue
+			}
+			redacted[key] = redactValue(child, secrets)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, child := range v {
+			redacted[i] = redactValue(child, secrets)
+		}
+		return redacted
+	case []map[string]interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, child := range v {
+			redacted[i] = redactValue(child, secrets)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			redacted[key] = child
+		}
+		return redactValue(redacted, secrets)
+	case http.Header: // Response headers of HTTP steps
+		redacted := make(map[string]interface{}, len(v))
+		for key, values := range v {
+			list := make([]interface{}, len(values))
+			for i, child := range values {
+				list[i] = child
+			}
+			redacted[key] = list
+		}
+		return redactValue(redacted, secrets)
+	case string:
+		for _, secret := range secrets {
+			v = strings.ReplaceAll(v, secret, redactedValue)
+		}
+		return v
+	}
+	return value
+}
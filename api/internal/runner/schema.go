@@ -0,0 +1,194 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaDocumentResolver loads named documents (such as OpenAPI specs attached
+// to a workspace) that schema assertions reference with "<name>#/pointer"
+type SchemaDocumentResolver interface {
+	ResolveDocument(name string) (interface{}, error)
+}
+
+// SetSchemaResolver sets the resolver used for schema assertion refs
+func (e *Executor) SetSchemaResolver(resolver SchemaDocumentResolver) {
+	e.schemaResolver = resolver
+}
+
+// SetSchemaDir sets the directory schema files are read from. Without one,
+// schema assertions cannot read files.
+func (e *Executor) SetSchemaDir(dir string) {
+	e.schemaDir = dir
+}
+
+// evaluateSchemaAssertions validates step output against every schema assertion
+// and reports all violations of all assertions together
+func (e *Executor) evaluateSchemaAssertions(step *models.Step, result models.OutputData, executionID uuid.UUID) error {
+	var failures []string
+
+	for i, sa := range step.Schema {
+		path := sa.Path
+		if path == "" {
+			path = "body"
+		}
+
+		validator, err := e.schemaValidatorFor(step, sa, path, executionID)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("schema[%d] (%s): %v", i, path, err))
+			continue
+		}
+		if validator == nil {
+			// No baseline yet (first run with from_previous_run)
+			continue
+		}
+
+		root := strings.SplitN(path, ".", 2)[0]
+		if _, exists := result[root]; !exists {
+			failures = append(failures, fmt.Sprintf("schema[%d] (%s): output has no %q", i, path, root))
+			continue
+		}
+
+		// Missing nested values validate as null so the schema reports the type mismatch
+		value := extractDotPath(map[string]interface{}(result), path)
+		if err := validator.Check(value); err != nil {
+			failures = append(failures, fmt.Sprintf("schema[%d] (%s): %v", i, path, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// schemaValidatorFor builds the validator for a single schema assertion
+func (e *Executor) schemaValidatorFor(step *models.Step, sa models.SchemaAssertion, path string, executionID uuid.UUID) (*assertions.SchemaValidator, error) {
+	if sa.Sources() != 1 {
+		return nil, fmt.Errorf("exactly one of schema, file, ref or from_previous_run must be set")
+	}
+
+	switch {
+	case sa.Schema != nil:
+		return assertions.NewSchemaValidator(sa.Schema)
+
+	case sa.File != "":
+		file, pointer := splitSchemaRef(sa.File)
+		doc, err := loadSchemaFile(e.schemaDir, file)
+		if err != nil {
+			return nil, err
+		}
+		return assertions.NewSchemaValidatorAt(doc, pointer)
+
+	case sa.Ref != "":
+		name, pointer := splitSchemaRef(sa.Ref)
+		if name == "" {
+			return nil, fmt.Errorf("ref %q must name a document, e.g. \"Petstore#/components/schemas/Pet\"", sa.Ref)
+		}
+		doc, err := e.resolveSchemaDocument(name)
+		if err != nil {
+			return nil, err
+		}
+		return assertions.NewSchemaValidatorAt(doc, pointer)
+
+	default:
+		return e.previousRunValidator(step, path, executionID)
+	}
+}
+
+// resolveSchemaDocument looks up a named document via the resolver, falling
+// back to a file in the schema directory
+func (e *Executor) resolveSchemaDocument(name string) (interface{}, error) {
+	if e.schemaResolver != nil {
+		doc, err := e.schemaResolver.ResolveDocument(name)
+		if err == nil {
+			return doc, nil
+		}
+		path, pathErr := schemaFilePath(e.schemaDir, name)
+		if pathErr != nil {
+			return nil, fmt.Errorf("document %q: %w", name, err)
+		}
+		if _, statErr := os.Stat(path); statErr != nil {
+			return nil, fmt.Errorf("document %q: %w", name, err)
+		}
+	}
+	return loadSchemaFile(e.schemaDir, name)
+}
+
+// previousRunValidator infers a schema from the last passing run of the same step
+func (e *Executor) previousRunValidator(step *models.Step, path string, executionID uuid.UUID) (*assertions.SchemaValidator, error) {
+	if step.ID == "" {
+		return nil, fmt.Errorf("from_previous_run requires the step to have an id")
+	}
+	if e.repo == nil || executionID == uuid.Nil {
+		return nil, fmt.Errorf("from_previous_run requires a persisted execution")
+	}
+
+	flowID, err := e.flowIDForExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := e.repo.GetLastPassedStep(flowID, step.ID, executionID)
+	if err != nil {
+		e.logger.Info("No previous passing run to capture schema from, skipping",
+			zap.String("step_id", step.ID),
+			zap.String("path", path),
+		)
+		return nil, nil
+	}
+
+	baseline := extractDotPath(map[string]interface{}(previous.Output), path)
+	return assertions.NewSchemaValidator(assertions.InferSchema(baseline))
+}
+
+// loadSchemaFile reads a JSON or YAML schema document from the schema directory
+func loadSchemaFile(dir, name string) (interface{}, error) {
+	path, err := schemaFilePath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so one decoder handles both
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %w", name, err)
+	}
+	return assertions.NormalizeJSON(doc), nil
+}
+
+// schemaFilePath resolves a schema file name against the schema directory,
+// rejecting absolute names and names that leave the directory
+func schemaFilePath(dir, name string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("schema files are not enabled: no schema directory is configured")
+	}
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") {
+		return "", fmt.Errorf("schema file %q must be relative to the schema directory", name)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("schema file %q is outside the schema directory", name)
+	}
+	return filepath.Join(dir, cleaned), nil
+}
+
+// splitSchemaRef splits "doc#/pointer" into its document and pointer parts
+func splitSchemaRef(ref string) (string, string) {
+	if idx := strings.Index(ref, "#"); idx >= 0 {
+		return ref[:idx], ref[idx:]
+	}
+	return ref, ""
+}
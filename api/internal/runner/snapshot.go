@@ -0,0 +1,122 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/snapshots"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SetSnapshotRepository sets the repository used to store snapshot golden records
+func (e *Executor) SetSnapshotRepository(repo *repository.SnapshotRepository) {
+	e.snapshotRepo = repo
+}
+
+// SetUpdateSnapshots makes differing snapshots replace their golden record instead of failing
+func (e *Executor) SetUpdateSnapshots(update bool) {
+	e.updateSnapshots = update
+}
+
+// evaluateSnapshot compares step output with the flow's golden record for the step.
+// The first run records the golden record; later differences are stored as a
+// pending candidate for review and fail the step.
+func (e *Executor) evaluateSnapshot(step *models.Step, result models.OutputData, executionID uuid.UUID) error {
+	if e.snapshotRepo == nil || e.repo == nil || executionID == uuid.Nil {
+		e.logger.Warn("Snapshot assertion skipped: no snapshot storage", zap.String("step_id", step.ID))
+		return nil
+	}
+
+	name := step.Snapshot.Name
+	if name == "" {
+		name = step.ID
+	}
+	if name == "" {
+		return fmt.Errorf("snapshot requires a step id or a snapshot name")
+	}
+
+	flowID, err := e.flowIDForExecution(executionID)
+	if err != nil {
+		return err
+	}
+
+	current, matcherFailures := snapshots.Capture(result, step.Snapshot)
+
+	existing, err := e.snapshotRepo.GetByName(flowID, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		now := time.Now()
+		snapshot := &models.Snapshot{
+			FlowID:     flowID,
+			StepID:     step.ID,
+			Name:       name,
+			Status:     models.SnapshotStatusAccepted,
+			Golden:     current,
+			AcceptedAt: &now,
+		}
+		if err := e.snapshotRepo.Create(snapshot); err != nil {
+			return fmt.Errorf("failed to record snapshot: %w", err)
+		}
+		e.logger.Info("Snapshot recorded", zap.String("snapshot", name), zap.String("step_id", step.ID))
+		if len(matcherFailures) > 0 {
+			return fmt.Errorf("snapshot %q recorded but matchers failed:\n%s", name, snapshots.Render(matcherFailures))
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	changes := append(matcherFailures, snapshots.Diff(existing.Golden, current)...)
+
+	if len(changes) == 0 {
+		if existing.Status == models.SnapshotStatusPending {
+			e.clearPendingSnapshot(existing)
+		}
+		return nil
+	}
+
+	if e.updateSnapshots && len(matcherFailures) == 0 {
+		now := time.Now()
+		existing.Golden = current
+		existing.AcceptedAt = &now
+		e.clearPendingSnapshot(existing)
+		e.logger.Info("Snapshot updated", zap.String("snapshot", name), zap.Int("changes", len(changes)))
+		return nil
+	}
+
+	existing.Status = models.SnapshotStatusPending
+	existing.Pending = current
+	existing.PendingChanges = changes
+	existing.PendingExecutionID = &executionID
+	if err := e.snapshotRepo.Update(existing); err != nil {
+		e.logger.Error("Failed to store pending snapshot", zap.Error(err))
+	}
+
+	return fmt.Errorf("snapshot %q differs from its golden record (%d change(s), review snapshot %s):\n%s",
+		name, len(changes), existing.ID, snapshots.Render(changes))
+}
+
+// clearPendingSnapshot drops a pending candidate and marks the snapshot accepted
+func (e *Executor) clearPendingSnapshot(snapshot *models.Snapshot) {
+	snapshot.Status = models.SnapshotStatusAccepted
+	snapshot.Pending = nil
+	snapshot.PendingChanges = nil
+	snapshot.PendingExecutionID = nil
+	if err := e.snapshotRepo.Update(snapshot); err != nil {
+		e.logger.Error("Failed to update snapshot", zap.Error(err))
+	}
+}
+
+// flowIDForExecution returns the flow an execution belongs to
+func (e *Executor) flowIDForExecution(executionID uuid.UUID) (uuid.UUID, error) {
+	execution, err := e.repo.GetByID(executionID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load execution: %w", err)
+	}
+	return execution.FlowID, nil
+}
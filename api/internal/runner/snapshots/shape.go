@@ -0,0 +1,146 @@
+package snapshots
+
+import (
+	"fmt"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// Shape describes the structure of a step's response body as the JSON type at
+// every path. Array elements are merged under [*], so the shape does not depend
+// on how many items a response returned. Outputs without a body have no shape.
+func Shape(output models.OutputData) map[string]string {
+	body, ok := normalizeOutput(output)["body"]
+	if !ok {
+		return nil
+	}
+	shape := make(map[string]string)
+	collectShape("$.body", body, shape)
+	return shape
+}
+
+func collectShape(path string, value interface{}, shape map[string]string) {
+	kind := kindOf(value)
+	// Keep the first non-null type seen for merged array elements
+	if existing, ok := shape[path]; !ok || existing == "null" {
+		shape[path] = kind
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			collectShape(path+"."+key, child, shape)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectShape(path+"[*]", item, shape)
+		}
+	}
+}
+
+// DiffShape compares the shapes of two responses. Fields under a value that is
+// null, empty or of another type on the other side are not compared, and neither
+// are changes from or to null, since they say nothing about the shape.
+func DiffShape(base, head map[string]string) []models.SnapshotChange {
+	paths := make(map[string]bool, len(base)+len(head))
+	for p := range base {
+		paths[p] = true
+	}
+	for p := range head {
+		paths[p] = true
+	}
+
+	var changes []models.SnapshotChange
+	for _, path := range sortedBoolKeys(paths) {
+		baseKind, inBase := base[path]
+		headKind, inHead := head[path]
+
+		switch {
+		case inBase && !inHead:
+			if !comparable(path, head) || parentMissing(path, head) {
+				continue
+			}
+			changes = append(changes, models.SnapshotChange{
+				ChangeType:  "removed_field",
+				Severity:    models.SeverityCritical,
+				Description: fmt.Sprintf("Field %s was removed", path),
+				Details: models.ChangeDetails{
+					Field:    path,
+					OldValue: baseKind,
+					Impact:   "Consumers expecting this field will fail",
+				},
+			})
+		case !inBase && inHead:
+			if !comparable(path, base) || parentMissing(path, base) {
+				continue
+			}
+			changes = append(changes, models.SnapshotChange{
+				ChangeType:  "added_field",
+				Severity:    models.SeverityMinor,
+				Description: fmt.Sprintf("Field %s was added", path),
+				Details: models.ChangeDetails{
+					Field:    path,
+					NewValue: headKind,
+					Impact:   "Usually backward compatible",
+				},
+			})
+		case baseKind != headKind && baseKind != "null" && headKind != "null":
+			changes = append(changes, models.SnapshotChange{
+				ChangeType:  "changed_type",
+				Severity:    models.SeverityCritical,
+				Description: fmt.Sprintf("Type changed at %s from %s to %s", path, baseKind, headKind),
+				Details: models.ChangeDetails{
+					Field:    path,
+					OldValue: baseKind,
+					NewValue: headKind,
+					Impact:   "Consumers parsing this value will fail",
+				},
+			})
+		}
+	}
+	return changes
+}
+
+// comparable reports whether the other shape has enough information about the
+// ancestors of path to say that the field is missing
+func comparable(path string, other map[string]string) bool {
+	for _, ancestor := range ancestors(path) {
+		kind, ok := other[ancestor]
+		if !ok {
+			continue
+		}
+		// A null or a type change of the parent is all there is to report
+		if kind != "object" && kind != "array" {
+			return false
+		}
+		// An empty array on the other side has no element shape to compare
+		if kind == "array" {
+			if _, hasItems := other[ancestor+"[*]"]; !hasItems {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parentMissing reports whether the direct parent of path is itself missing from
+// the other shape, in which case only the parent is reported
+func parentMissing(path string, other map[string]string) bool {
+	list := ancestors(path)
+	if len(list) == 0 {
+		return false
+	}
+	_, ok := other[list[len(list)-1]]
+	return !ok
+}
+
+// ancestors lists the paths containing path, outermost first
+func ancestors(path string) []string {
+	var list []string
+	for i := 1; i < len(path); i++ {
+		if (path[i] == '.' || path[i] == '[') && path[:i] != "$" {
+			list = append(list, path[:i])
+		}
+	}
+	return list
+}
@@ -0,0 +1,458 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+)
+
+// matcherSchemas maps the built-in matchers to the JSON Schema they check
+var matcherSchemas = map[string]map[string]interface{}{
+	"any":           {},
+	"any string":    {"type": "string"},
+	"any number":    {"type": "number"},
+	"any integer":   {"type": "integer"},
+	"any boolean":   {"type": "boolean"},
+	"any object":    {"type": "object"},
+	"any array":     {"type": "array"},
+	"any uuid":      {"type": "string", "format": "uuid"},
+	"any email":     {"type": "string", "format": "email"},
+	"any url":       {"type": "string", "format": "uri"},
+	"any date":      {"type": "string", "format": "date"},
+	"any timestamp": {"type": "string", "format": "date-time"},
+	"any date-time": {"type": "string", "format": "date-time"},
+}
+
+// Capture normalizes a step output into the document recorded as a snapshot.
+// Only included paths are kept (body by default), header names are lowercased,
+// ignored paths are dropped and values under a matcher are checked and replaced
+// by a stable placeholder. Matcher failures are returned as changes.
+func Capture(output models.OutputData, cfg *models.SnapshotConfig) (map[string]interface{}, []models.SnapshotChange) {
+	source := normalizeOutput(output)
+
+	include := cfg.Include
+	if len(include) == 0 {
+		include = []string{"body"}
+	}
+
+	captured := make(map[string]interface{})
+	for _, path := range include {
+		segments := parsePath(path)
+		if len(segments) == 0 {
+			continue
+		}
+		if value, ok := lookup(source, segments); ok {
+			assign(captured, segments, value)
+		}
+	}
+
+	ignore := make([][]string, 0, len(cfg.Ignore))
+	for _, path := range cfg.Ignore {
+		ignore = append(ignore, parsePath(path))
+	}
+
+	matchers := make([]matcherRule, 0, len(cfg.Matchers))
+	for _, path := range sortedKeys(cfg.Matchers) {
+		matchers = append(matchers, matcherRule{segments: parsePath(path), expr: cfg.Matchers[path]})
+	}
+
+	var changes []models.SnapshotChange
+	result := transform(captured, nil, ignore, matchers, &changes)
+	doc, _ := result.(map[string]interface{})
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	return doc, changes
+}
+
+// Diff compares a golden record with a newly captured document
+func Diff(golden, current map[string]interface{}) []models.SnapshotChange {
+	var changes []models.SnapshotChange
+	diffValues("$", assertions.NormalizeJSON(map[string]interface{}(golden)), assertions.NormalizeJSON(map[string]interface{}(current)), &changes)
+	return changes
+}
+
+// Render formats changes as a readable, line-per-change diff
+func Render(changes []models.SnapshotChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		switch c.ChangeType {
+		case "added_field":
+			lines = append(lines, fmt.Sprintf("+ %s: %s", c.Details.Field, compact(c.Details.NewValue)))
+		case "removed_field":
+			lines = append(lines, fmt.Sprintf("- %s: %s", c.Details.Field, compact(c.Details.OldValue)))
+		default:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", c.Details.Field, compact(c.Details.OldValue), compact(c.Details.NewValue)))
+		}
+	}
+	return "  " + strings.Join(lines, "\n  ")
+}
+
+// Summary counts changes by severity like the contract differ summary
+func Summary(changes []models.SnapshotChange) map[string]int {
+	summary := map[string]int{
+		"critical": 0,
+		"major":    0,
+		"minor":    0,
+		"total":    len(changes),
+	}
+	for _, c := range changes {
+		summary[string(c.Severity)]++
+	}
+	return summary
+}
+
+// matcherRule pairs a parsed path pattern with its matcher expression
+type matcherRule struct {
+	segments []string
+	expr     string
+}
+
+// transform walks the captured document applying ignore and matcher rules
+func transform(value interface{}, path []string, ignore [][]string, matchers []matcherRule, changes *[]models.SnapshotChange) interface{} {
+	for _, rule := range matchers {
+		if matchPath(rule.segments, path) {
+			if err := checkMatcher(rule.expr, value); err != nil {
+				*changes = append(*changes, models.SnapshotChange{
+					ChangeType:  "matcher_mismatch",
+					Severity:    models.SeverityCritical,
+					Description: fmt.Sprintf("Value at %s does not match %q", formatPath(path), rule.expr),
+					Details: models.ChangeDetails{
+						Field:      formatPath(path),
+						OldValue:   rule.expr,
+						NewValue:   value,
+						Impact:     err.Error(),
+						Suggestion: "Fix the response or relax the matcher",
+					},
+				})
+				return value
+			}
+			// Stable placeholder so volatile values never show up as changes
+			return "<<" + rule.expr + ">>"
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			childPath := append(append([]string{}, path...), key)
+			if isIgnored(ignore, childPath) {
+				continue
+			}
+			out[key] = transform(item, childPath, ignore, matchers, changes)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			childPath := append(append([]string{}, path...), strconv.Itoa(i))
+			// Ignored elements are masked rather than removed so the
+			// elements after them keep their index
+			if isIgnored(ignore, childPath) {
+				out = append(out, ignoredPlaceholder)
+				continue
+			}
+			out = append(out, transform(item, childPath, ignore, matchers, changes))
+		}
+		return out
+	}
+	return value
+}
+
+// ignoredPlaceholder replaces ignored array elements
+const ignoredPlaceholder = "<<ignored>>"
+
+// checkMatcher validates value against a matcher such as "any uuid" or "regex:^[A-Z]+$"
+func checkMatcher(expr string, value interface{}) error {
+	var schema map[string]interface{}
+	switch {
+	case strings.HasPrefix(expr, "regex:"):
+		schema = map[string]interface{}{"type": "string", "pattern": strings.TrimSpace(strings.TrimPrefix(expr, "regex:"))}
+	default:
+		known, ok := matcherSchemas[strings.ToLower(strings.TrimSpace(expr))]
+		if !ok {
+			return fmt.Errorf("unknown matcher %q", expr)
+		}
+		schema = known
+	}
+
+	validator, err := assertions.NewSchemaValidator(schema)
+	if err != nil {
+		return err
+	}
+	if violations := validator.Validate(value); len(violations) > 0 {
+		return fmt.Errorf("%s", violations[0].Message)
+	}
+	return nil
+}
+
+// diffValues records differences between golden and current at path
+func diffValues(path string, golden, current interface{}, changes *[]models.SnapshotChange) {
+	if reflect.DeepEqual(golden, current) {
+		return
+	}
+
+	goldenKind, currentKind := kindOf(golden), kindOf(current)
+	if goldenKind != currentKind {
+		*changes = append(*changes, models.SnapshotChange{
+			ChangeType:  "changed_type",
+			Severity:    models.SeverityCritical,
+			Description: fmt.Sprintf("Type changed at %s from %s to %s", path, goldenKind, currentKind),
+			Details: models.ChangeDetails{
+				Field:      path,
+				OldValue:   golden,
+				NewValue:   current,
+				Impact:     "Consumers parsing this value will fail",
+				Suggestion: "Accept the snapshot if the new type is intended",
+			},
+		})
+		return
+	}
+
+	switch g := golden.(type) {
+	case map[string]interface{}:
+		c := current.(map[string]interface{})
+		keys := make(map[string]bool)
+		for k := range g {
+			keys[k] = true
+		}
+		for k := range c {
+			keys[k] = true
+		}
+		for _, key := range sortedBoolKeys(keys) {
+			childPath := path + "." + key
+			gv, inGolden := g[key]
+			cv, inCurrent := c[key]
+			switch {
+			case inGolden && !inCurrent:
+				*changes = append(*changes, models.SnapshotChange{
+					ChangeType:  "removed_field",
+					Severity:    models.SeverityCritical,
+					Description: fmt.Sprintf("Field %s was removed", childPath),
+					Details: models.ChangeDetails{
+						Field:      childPath,
+						OldValue:   gv,
+						Impact:     "Consumers expecting this field will fail",
+						Suggestion: "Restore the field or accept the snapshot",
+					},
+				})
+			case !inGolden && inCurrent:
+				*changes = append(*changes, models.SnapshotChange{
+					ChangeType:  "added_field",
+					Severity:    models.SeverityMinor,
+					Description: fmt.Sprintf("Field %s was added", childPath),
+					Details: models.ChangeDetails{
+						Field:      childPath,
+						NewValue:   cv,
+						Impact:     "Usually backward compatible",
+						Suggestion: "Accept the snapshot, or ignore the path if it is volatile",
+					},
+				})
+			default:
+				diffValues(childPath, gv, cv, changes)
+			}
+		}
+
+	case []interface{}:
+		c := current.([]interface{})
+		if len(g) != len(c) {
+			*changes = append(*changes, models.SnapshotChange{
+				ChangeType:  "changed_length",
+				Severity:    models.SeverityMajor,
+				Description: fmt.Sprintf("Array length at %s changed from %d to %d", path, len(g), len(c)),
+				Details: models.ChangeDetails{
+					Field:      path,
+					OldValue:   len(g),
+					NewValue:   len(c),
+					Impact:     "Result set differs from the golden record",
+					Suggestion: "Check test data, or accept the snapshot",
+				},
+			})
+		}
+		limit := len(g)
+		if len(c) < limit {
+			limit = len(c)
+		}
+		for i := 0; i < limit; i++ {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), g[i], c[i], changes)
+		}
+
+	default:
+		*changes = append(*changes, models.SnapshotChange{
+			ChangeType:  "changed_value",
+			Severity:    models.SeverityMajor,
+			Description: fmt.Sprintf("Value at %s changed", path),
+			Details: models.ChangeDetails{
+				Field:      path,
+				OldValue:   golden,
+				NewValue:   current,
+				Impact:     "Output differs from the golden record",
+				Suggestion: "Accept the snapshot, ignore the path or add a matcher",
+			},
+		})
+	}
+}
+
+// normalizeOutput converts the output into JSON values and lowercases header names
+func normalizeOutput(output models.OutputData) map[string]interface{} {
+	normalized, _ := assertions.NormalizeJSON(map[string]interface{}(output)).(map[string]interface{})
+	if normalized == nil {
+		return map[string]interface{}{}
+	}
+
+	if headers, ok := normalized["headers"].(map[string]interface{}); ok {
+		lowered := make(map[string]interface{}, len(headers))
+		for k, v := range headers {
+			// net/http headers are []string; a single value reads better as a string
+			if list, ok := v.([]interface{}); ok && len(list) == 1 {
+				v = list[0]
+			}
+			lowered[strings.ToLower(k)] = v
+		}
+		normalized["headers"] = lowered
+	}
+	return normalized
+}
+
+// parsePath splits "body.items[*].id" into ["body", "items", "*", "id"]
+func parsePath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) > 0 && segments[0] == "headers" {
+		for i := 1; i < len(segments); i++ {
+			segments[i] = strings.ToLower(segments[i])
+		}
+	}
+	return segments
+}
+
+// matchPath reports whether a concrete path matches a pattern ("*" matches one segment)
+func matchPath(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isIgnored(ignore [][]string, path []string) bool {
+	for _, pattern := range ignore {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup resolves concrete segments inside a document
+func lookup(doc interface{}, segments []string) (interface{}, bool) {
+	current := doc
+	for _, seg := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// assign sets value at segments, creating intermediate objects as needed
+func assign(doc map[string]interface{}, segments []string, value interface{}) {
+	current := doc
+	for _, seg := range segments[:len(segments)-1] {
+		next, ok := current[seg].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[seg] = next
+		}
+		current = next
+	}
+	current[segments[len(segments)-1]] = value
+}
+
+func formatPath(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range path {
+		if _, err := strconv.Atoi(seg); err == nil {
+			b.WriteString("[" + seg + "]")
+			continue
+		}
+		b.WriteString("." + seg)
+	}
+	return b.String()
+}
+
+func kindOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func compact(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedBoolKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
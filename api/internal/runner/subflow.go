@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh/internal/runner/assertions"
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxFlowDepth bounds nested run_flow steps, so that flows running each
+// other fail instead of recursing forever
+const maxFlowDepth = 8
+
+// FlowResolver loads the flows run_flow steps run
+type FlowResolver interface {
+	ResolveFlow(ref string) (*models.Flow, error)
+}
+
+// SetFlowResolver sets where run_flow steps load their flows from
+func (e *Executor) SetFlowResolver(resolver FlowResolver) {
+	e.flowResolver = resolver
+}
+
+// workspaceFlowResolver resolves run_flow references against the flows of a workspace
+type workspaceFlowResolver struct {
+	repo        *repository.FlowRepository
+	workspaceID uuid.UUID
+}
+
+// NewWorkspaceFlowResolver returns a resolver of the flows of a workspace by
+// ID or name. Names match like watch mode matches them: "Create User",
+// "create-user" and "create_user" are the same flow.
+func NewWorkspaceFlowResolver(repo *repository.FlowRepository, workspaceID uuid.UUID) FlowResolver {
+	return &workspaceFlowResolver{repo: repo, workspaceID: workspaceID}
+}
+
+// ResolveFlow implements FlowResolver
+func (r *workspaceFlowResolver) ResolveFlow(ref string) (*models.Flow, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return r.repo.GetByID(id, r.workspaceID)
+	}
+	flow, err := r.repo.GetByName(ref, r.workspaceID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return flow, err
+	}
+
+	flows, err := r.repo.ListAll(r.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flows {
+		if normalizeFlowName(flows[i].Name) == normalizeFlowName(ref) {
+			return &flows[i], nil
+		}
+	}
+	return nil, fmt.Errorf("flow %q not found", ref)
+}
+
+// normalizeFlowName folds case and separators of a flow name
+func normalizeFlowName(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// flowDepthKey is the context key of the number of run_flow steps a step runs in
+type flowDepthKey struct{}
+
+// flowRunner runs the steps of a phase of a sub-flow with the sub-flow's context
+type flowRunner func(ctx context.Context, steps []models.Step, execCtx *Context, phase, idPrefix string) error
+
+// executeRunFlow runs the flow a run_flow step names. The sub-flow gets its
+// own context with the step's input, and the variables of the calling flow
+// unless inherit_env is false. Steps of the sub-flow without an ID get
+// <step_id>.<phase>_<n>.
+func (e *Executor) executeRunFlow(ctx context.Context, step *models.Step, stepID string, execCtx *Context, run flowRunner) (models.OutputData, error) {
+	if e.flowResolver == nil {
+		return nil, fmt.Errorf("run_flow is not available: flows cannot be loaded")
+	}
+	depth, _ := ctx.Value(flowDepthKey{}).(int)
+	if depth >= maxFlowDepth {
+		return nil, fmt.Errorf("run_flow nests more than %d flows", maxFlowDepth)
+	}
+
+	config := NewInterpolator(execCtx).InterpolateMap(step.Config)
+	ref, _ := config["flow"].(string)
+	if ref == "" {
+		return nil, fmt.Errorf("flow is required")
+	}
+	flow, err := e.flowResolver.ResolveFlow(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flow %s: %w", ref, err)
+	}
+	definition := &flow.Definition
+
+	// Input overrides the sub-flow's env, which overrides inherited variables
+	var variables map[string]string
+	if inherit, ok := config["inherit_env"].(bool); !ok || inherit {
+		variables, _ = execCtx.Snapshot()
+	}
+	subCtx := NewContext(variables, definition.Env)
+	if input, ok := config["input"].(map[string]interface{}); ok {
+		for name, value := range input {
+			subCtx.Set(name, rowValue(value))
+		}
+	}
+
+	e.logger.Info("Running sub-flow",
+		zap.String("step_id", stepID),
+		zap.String("flow", definition.Name),
+	)
+
+	ctx = context.WithValue(ctx, flowDepthKey{}, depth+1)
+	prefix := stepID + "."
+	err = run(ctx, definition.Setup, subCtx, "setup", prefix+"setup_")
+	if err == nil {
+		err = run(ctx, definition.Steps, subCtx, "main", prefix+"main_")
+		// Teardown runs even if the main steps failed
+		if teardownErr := run(ctx, definition.Teardown, subCtx, "teardown", prefix+"teardown_"); err == nil {
+			err = teardownErr
+		}
+	}
+
+	_, outputs := subCtx.Snapshot()
+	steps := make(map[string]interface{}, len(outputs))
+	for id, values := range outputs {
+		steps[id] = values
+	}
+	result := models.OutputData{
+		"flow":    definition.Name,
+		"flow_id": flow.ID.String(),
+		"steps":   steps,
+	}
+	if err != nil {
+		return result, fmt.Errorf("flow %s failed: %w", definition.Name, err)
+	}
+
+	if len(step.Assert) > 0 {
+		evaluator := assertions.NewEvaluator(result)
+		if err := evaluator.Evaluate(step.Assert); err != nil {
+			return result, fmt.Errorf("assertion failed: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// addTotalSteps adds the steps of a sub-flow to the total of an execution,
+// since the sub-flow is only loaded when its run_flow step runs
+func (e *Executor) addTotalSteps(execution *models.Execution, steps []models.Step) {
+	e.statsMu.Lock()
+	defer e.statsMu.Unlock()
+	execution.TotalSteps += countSteps(steps)
+}
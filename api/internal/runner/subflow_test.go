@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// flowMap resolves flows by name from a map
+type flowMap map[string]*models.FlowDefinition
+
+func (m flowMap) ResolveFlow(ref string) (*models.Flow, error) {
+	definition, ok := m[ref]
+	if !ok {
+		return nil, fmt.Errorf("flow %q not found", ref)
+	}
+	return &models.Flow{ID: uuid.New(), Name: definition.Name, Definition: *definition}, nil
+}
+
+func TestRunFlow(t *testing.T) {
+	executor := NewExecutor(nil, nil, zap.NewNop(), nil, nil)
+	executor.SetFlowResolver(flowMap{
+		"greet": {
+			Name: "greet",
+			Env:  map[string]interface{}{"GREETING": "hello"},
+			Steps: []models.Step{
+				{ID: "say", Action: "log", Config: map[string]interface{}{"message": "${GREETING} ${NAME} from ${CALLER}"}},
+				{Action: "log", Config: map[string]interface{}{"message": "done"}},
+			},
+		},
+		"loop": {
+			Name:  "loop",
+			Steps: []models.Step{{ID: "again", Action: "run_flow", Config: map[string]interface{}{"flow": "loop"}}},
+		},
+	})
+	execCtx := NewContext(map[string]string{"CALLER": "parent"}, nil)
+
+	steps := []models.Step{{
+		ID:     "call",
+		Action: "run_flow",
+		Config: map[string]interface{}{
+			"flow":  "greet",
+			"input": map[string]interface{}{"NAME": "ada", "GREETING": "hi"},
+		},
+		Output: map[string]string{"message": "steps.say.message"},
+	}}
+	if err := executor.executeStepsWithoutPersistence(context.Background(), steps, execCtx, "step_"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	message, _ := execCtx.GetStepOutput("call", "message")
+	if message != "hi ada from parent" {
+		t.Errorf("message = %v, want %q", message, "hi ada from parent")
+	}
+	outputs, _ := execCtx.StepOutputs("call")
+	subSteps, _ := outputs["steps"].(map[string]interface{})
+	if _, ok := subSteps["call.main_1"]; !ok {
+		t.Errorf("sub-flow step outputs = %v, want call.main_1 for the step without an ID", subSteps)
+	}
+	if _, ok := execCtx.StepOutputs("say"); ok {
+		t.Error("sub-flow step outputs leaked into the calling flow")
+	}
+
+	// Flows running themselves stop at the nesting limit
+	steps = []models.Step{{ID: "loop", Action: "run_flow", Config: map[string]interface{}{"flow": "loop"}}}
+	err := executor.executeStepsWithoutPersistence(context.Background(), steps, execCtx, "step_")
+	if err == nil || !strings.Contains(err.Error(), "nests more than") {
+		t.Errorf("err = %v, want the nesting limit", err)
+	}
+}
@@ -2,9 +2,13 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
 	"github.com/georgi-georgiev/testmesh/internal/storage/repository"
 	"github.com/google/uuid"
@@ -12,38 +16,88 @@ import (
 	"go.uber.org/zap"
 )
 
-// ExecutionFunc is a function that executes a flow and returns the execution ID and result
-type ExecutionFunc func(ctx context.Context, flowID uuid.UUID, env map[string]interface{}) (uuid.UUID, string, error)
+// ExecutionFunc executes a flow as part of a schedule run and returns the execution ID and
+// result: "success", "failure", or "quarantined" for a failure that does not fail the run
+type ExecutionFunc func(ctx context.Context, flowID uuid.UUID, runID uuid.UUID, env map[string]interface{}) (uuid.UUID, string, error)
+
+// RunObserver is called after a schedule run finished executing, with the
+// result, error, duration and flow results of the run filled in
+type RunObserver func(schedule *models.Schedule, run *models.ScheduleRun)
+
+const (
+	leaseName    = "scheduler"
+	leaseTTL     = 15 * time.Second
+	pollInterval = time.Second
+
+	// misfireThreshold is how late an occurrence may fire before it counts as missed
+	misfireThreshold = time.Minute
+	// maxCatchUpRuns caps the runs fired for one schedule by the run_all misfire policy
+	maxCatchUpRuns = 100
+
+	defaultRetryDelay = time.Minute
+
+	// runHeartbeatInterval is how often a replica renews the heartbeat of the runs it runs
+	runHeartbeatInterval = 5 * time.Second
+	// staleRunAfter is how old the heartbeat of a pending or running run may
+	// get before the leader fails it as abandoned
+	staleRunAfter = 4 * leaseTTL
+	// reapInterval is how often the leader looks for abandoned runs
+	reapInterval = 30 * time.Second
+)
+
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 
-// Scheduler manages scheduled test executions
+// Scheduler manages scheduled test executions.
+//
+// Every API replica runs a scheduler, but only the replica holding the
+// scheduler lease in Postgres fires schedules. Each occurrence is also
+// claimed by a compare-and-swap on next_run_at, so an occurrence fires once
+// even while the lease changes hands.
 type Scheduler struct {
-	mu           sync.RWMutex
-	scheduleRepo *repository.ScheduleRepository
-	logger       *zap.Logger
-	cron         *cron.Cron
-	jobs         map[uuid.UUID]cron.EntryID
-	executeFunc  ExecutionFunc
-	running      bool
-	ctx          context.Context
-	cancel       context.CancelFunc
+	mu             sync.RWMutex
+	scheduleRepo   *repository.ScheduleRepository
+	flowRepo       *repository.FlowRepository
+	collectionRepo *repository.CollectionRepository
+	logger         *zap.Logger
+	executeFunc    ExecutionFunc
+	runObserver    RunObserver
+	metrics        *metrics.Metrics
+	holder         string
+	leader         bool
+	lastReap       time.Time // Last search for abandoned runs; only used by the loop
+	running        bool
+	ctx            context.Context
+	cancel         context.CancelFunc
+	done           chan struct{}
 }
 
-// NewScheduler creates a new scheduler
-func NewScheduler(scheduleRepo *repository.ScheduleRepository, logger *zap.Logger) *Scheduler {
-	// Create cron with second-level precision and location support
-	c := cron.New(cron.WithParser(cron.NewParser(
-		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
-	)))
+// SchedulerStatus describes the scheduler of this replica and the current lease
+type SchedulerStatus struct {
+	Holder       string     `json:"holder"`
+	Leader       bool       `json:"leader"`
+	Running      bool       `json:"running"`
+	LeaseHolder  string     `json:"lease_holder,omitempty"`
+	LeaseExpires *time.Time `json:"lease_expires_at,omitempty"`
+}
 
+// NewScheduler creates a new scheduler
+func NewScheduler(scheduleRepo *repository.ScheduleRepository, flowRepo *repository.FlowRepository, collectionRepo *repository.CollectionRepository, logger *zap.Logger) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "testmesh"
+	}
+
 	return &Scheduler{
-		scheduleRepo: scheduleRepo,
-		logger:       logger,
-		cron:         c,
-		jobs:         make(map[uuid.UUID]cron.EntryID),
-		ctx:          ctx,
-		cancel:       cancel,
+		scheduleRepo:   scheduleRepo,
+		flowRepo:       flowRepo,
+		collectionRepo: collectionRepo,
+		logger:         logger,
+		holder:         fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+		ctx:            ctx,
+		cancel:         cancel,
+		done:           make(chan struct{}),
 	}
 }
 
@@ -52,6 +106,23 @@ func (s *Scheduler) SetExecutionFunc(fn ExecutionFunc) {
 	s.executeFunc = fn
 }
 
+// SetRunObserver sets the function called after every finished run
+func (s *Scheduler) SetRunObserver(fn RunObserver) {
+	s.runObserver = fn
+}
+
+// SetMetrics sets the collectors that record schedule runs, lag and misses
+func (s *Scheduler) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// IsLeader reports whether this replica holds the scheduler lease
+func (s *Scheduler) IsLeader() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.leader
+}
+
 // Start starts the scheduler
 func (s *Scheduler) Start() error {
 	s.mu.Lock()
@@ -61,49 +132,76 @@ func (s *Scheduler) Start() error {
 		return nil
 	}
 
-	// Load all active schedules
+	// Schedules created before next_run_at was maintained have nothing to fire on
 	schedules, err := s.scheduleRepo.ListActive()
 	if err != nil {
 		return err
 	}
-
-	// Register each schedule
 	for _, schedule := range schedules {
-		if err := s.registerSchedule(schedule); err != nil {
-			s.logger.Error("Failed to register schedule",
+		if schedule.NextRunAt != nil {
+			continue
+		}
+		nextRun, err := s.calculateNextRun(schedule.CronExpr, schedule.Timezone)
+		if err != nil {
+			s.logger.Error("Invalid schedule",
 				zap.String("schedule_id", schedule.ID.String()),
 				zap.Error(err))
+			continue
 		}
+		s.scheduleRepo.UpdateNextRunTime(schedule.ID, nextRun)
 	}
 
-	// Start cron
-	s.cron.Start()
+	go s.loop()
 	s.running = true
 
-	s.logger.Info("Scheduler started", zap.Int("schedules", len(schedules)))
+	s.logger.Info("Scheduler started",
+		zap.Int("schedules", len(schedules)),
+		zap.String("holder", s.holder))
 	return nil
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler and releases the lease
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if !s.running {
+		s.mu.Unlock()
 		return
 	}
+	s.running = false
+	s.mu.Unlock()
 
 	s.cancel()
-	s.cron.Stop()
-	s.running = false
+	<-s.done
+
+	if err := s.scheduleRepo.ReleaseLease(leaseName, s.holder); err != nil {
+		s.logger.Warn("Failed to release scheduler lease", zap.Error(err))
+	}
 	s.logger.Info("Scheduler stopped")
 }
 
+// Status returns the leadership state of this replica and the current lease
+func (s *Scheduler) Status() (*SchedulerStatus, error) {
+	s.mu.RLock()
+	status := &SchedulerStatus{
+		Holder:  s.holder,
+		Leader:  s.leader,
+		Running: s.running,
+	}
+	s.mu.RUnlock()
+
+	lease, err := s.scheduleRepo.GetLease(leaseName)
+	if err != nil {
+		return nil, err
+	}
+	if lease != nil {
+		status.LeaseHolder = lease.Holder
+		status.LeaseExpires = &lease.ExpiresAt
+	}
+	return status, nil
+}
+
 // AddSchedule adds a new schedule to the scheduler
 func (s *Scheduler) AddSchedule(schedule *models.Schedule) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Calculate next run time
 	nextRun, err := s.calculateNextRun(schedule.CronExpr, schedule.Timezone)
 	if err != nil {
@@ -111,18 +209,11 @@ func (s *Scheduler) AddSchedule(schedule *models.Schedule) error {
 	}
 	schedule.NextRunAt = &nextRun
 
-	// Save to database
+	// Save to database; the leader picks it up once it is due
 	if err := s.scheduleRepo.Create(schedule); err != nil {
 		return err
 	}
 
-	// Register with cron if active
-	if schedule.Status == models.ScheduleStatusActive && s.running {
-		if err := s.registerSchedule(schedule); err != nil {
-			return err
-		}
-	}
-
 	s.logger.Info("Schedule added",
 		zap.String("schedule_id", schedule.ID.String()),
 		zap.String("name", schedule.Name),
@@ -133,9 +224,6 @@ func (s *Scheduler) AddSchedule(schedule *models.Schedule) error {
 
 // UpdateSchedule updates an existing schedule
 func (s *Scheduler) UpdateSchedule(schedule *models.Schedule) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Recalculate next run time if cron expression changed
 	nextRun, err := s.calculateNextRun(schedule.CronExpr, schedule.Timezone)
 	if err != nil {
@@ -143,60 +231,36 @@ func (s *Scheduler) UpdateSchedule(schedule *models.Schedule) error {
 	}
 	schedule.NextRunAt = &nextRun
 
-	// Update in database
-	if err := s.scheduleRepo.Update(schedule); err != nil {
-		return err
-	}
-
-	// Re-register with cron
-	s.unregisterSchedule(schedule.ID)
-	if schedule.Status == models.ScheduleStatusActive && s.running {
-		if err := s.registerSchedule(schedule); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return s.scheduleRepo.Update(schedule)
 }
 
 // RemoveSchedule removes a schedule from the scheduler
 func (s *Scheduler) RemoveSchedule(id uuid.UUID) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.unregisterSchedule(id)
 	return s.scheduleRepo.Delete(id)
 }
 
 // PauseSchedule pauses a schedule
 func (s *Scheduler) PauseSchedule(id uuid.UUID) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.unregisterSchedule(id)
 	return s.scheduleRepo.SetStatus(id, models.ScheduleStatusPaused)
 }
 
-// ResumeSchedule resumes a paused schedule
+// ResumeSchedule resumes a paused schedule. Occurrences that passed while
+// the schedule was paused are not treated as misfires.
 func (s *Scheduler) ResumeSchedule(id uuid.UUID) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	schedule, err := s.scheduleRepo.Get(id)
 	if err != nil {
 		return err
 	}
 
-	if err := s.scheduleRepo.SetStatus(id, models.ScheduleStatusActive); err != nil {
+	nextRun, err := s.calculateNextRun(schedule.CronExpr, schedule.Timezone)
+	if err != nil {
 		return err
 	}
-
-	schedule.Status = models.ScheduleStatusActive
-	if s.running {
-		return s.registerSchedule(schedule)
+	if err := s.scheduleRepo.UpdateNextRunTime(id, nextRun); err != nil {
+		return err
 	}
 
-	return nil
+	return s.scheduleRepo.SetStatus(id, models.ScheduleStatusActive)
 }
 
 // TriggerSchedule manually triggers a schedule execution
@@ -206,52 +270,164 @@ func (s *Scheduler) TriggerSchedule(id uuid.UUID) (*models.ScheduleRun, error) {
 		return nil, err
 	}
 
-	return s.executeSchedule(schedule)
+	run, err := s.createRun(schedule, time.Now())
+	if err != nil || run.Status == "skipped" {
+		return run, err
+	}
+
+	go s.performRun(schedule, run, false)
+	return run, nil
 }
 
-// registerSchedule registers a schedule with cron
-func (s *Scheduler) registerSchedule(schedule *models.Schedule) error {
-	// Parse timezone
-	loc, err := time.LoadLocation(schedule.Timezone)
+// loop renews the lease and fires due schedules while this replica is the leader
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+// tick runs one scheduling pass
+func (s *Scheduler) tick() {
+	leader, err := s.scheduleRepo.AcquireLease(leaseName, s.holder, leaseTTL)
+	if err != nil {
+		s.logger.Error("Failed to acquire scheduler lease", zap.Error(err))
+		leader = false
+	}
+
+	s.mu.Lock()
+	if leader != s.leader {
+		if leader {
+			s.logger.Info("Acquired scheduler lease", zap.String("holder", s.holder))
+		} else {
+			s.logger.Info("Lost scheduler lease", zap.String("holder", s.holder))
+		}
+		s.leader = leader
+	}
+	s.mu.Unlock()
+
+	if !leader {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastReap) >= reapInterval {
+		s.lastReap = now
+		s.reapStaleRuns()
+	}
+
+	schedules, err := s.scheduleRepo.ListDueSchedules(now)
 	if err != nil {
-		loc = time.UTC
+		s.logger.Error("Failed to list due schedules", zap.Error(err))
+		return
 	}
 
-	// Create job function
-	jobFunc := func() {
-		if _, err := s.executeSchedule(schedule); err != nil {
-			s.logger.Error("Schedule execution failed",
+	for _, schedule := range schedules {
+		if err := s.fireDue(schedule, now); err != nil {
+			s.logger.Error("Failed to fire schedule",
 				zap.String("schedule_id", schedule.ID.String()),
 				zap.Error(err))
 		}
 	}
+}
 
-	// Add to cron with timezone
-	entryID, err := s.cron.AddFunc(schedule.CronExpr, cron.FuncJob(jobFunc).Run)
+// fireDue claims the due occurrences of a schedule and runs them according
+// to its misfire policy
+func (s *Scheduler) fireDue(schedule *models.Schedule, now time.Time) error {
+	sched, err := cronParser.Parse(schedule.CronExpr)
 	if err != nil {
 		return err
 	}
 
-	s.jobs[schedule.ID] = entryID
+	// Occurrences are computed in the schedule's timezone, not the one the
+	// database returned the times in
+	loc := scheduleLocation(schedule.Timezone)
+	occurrences := dueOccurrences(sched, schedule.NextRunAt.In(loc), now.In(loc))
+	nextRun := sched.Next(now.In(loc))
 
-	s.logger.Debug("Registered schedule",
-		zap.String("schedule_id", schedule.ID.String()),
-		zap.String("cron", schedule.CronExpr),
-		zap.String("timezone", loc.String()))
+	claimed, err := s.scheduleRepo.ClaimOccurrence(schedule.ID, *schedule.NextRunAt, nextRun)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	runs := applyMisfirePolicy(schedule.MisfirePolicy, occurrences, now)
+	if missed := len(occurrences) - len(runs); missed > 0 {
+		s.logger.Warn("Schedule missed occurrences",
+			zap.String("schedule_id", schedule.ID.String()),
+			zap.String("misfire_policy", string(schedule.MisfirePolicy)),
+			zap.Int("missed", missed),
+			zap.Int("running", len(runs)))
+		s.metrics.ScheduleMissed(schedule.Name, missed)
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	// Catch-up runs execute one after another so they don't trip overlap prevention
+	go func() {
+		for _, scheduledAt := range runs {
+			run, err := s.createRun(schedule, scheduledAt)
+			if err != nil {
+				s.logger.Error("Failed to create schedule run",
+					zap.String("schedule_id", schedule.ID.String()),
+					zap.Error(err))
+				return
+			}
+			if run.Status != "skipped" {
+				s.metrics.ScheduleRunStarted(schedule.Name, time.Since(scheduledAt))
+				s.performRun(schedule, run, true)
+			}
+			if s.ctx.Err() != nil {
+				return
+			}
+		}
+	}()
 
 	return nil
 }
 
-// unregisterSchedule removes a schedule from cron
-func (s *Scheduler) unregisterSchedule(id uuid.UUID) {
-	if entryID, ok := s.jobs[id]; ok {
-		s.cron.Remove(entryID)
-		delete(s.jobs, id)
+// dueOccurrences lists the occurrences from first up to now, capped at maxCatchUpRuns
+func dueOccurrences(sched cron.Schedule, first time.Time, now time.Time) []time.Time {
+	occurrences := []time.Time{first}
+	for t := sched.Next(first); !t.After(now) && len(occurrences) < maxCatchUpRuns; t = sched.Next(t) {
+		occurrences = append(occurrences, t)
 	}
+	return occurrences
 }
 
-// executeSchedule executes a scheduled flow
-func (s *Scheduler) executeSchedule(schedule *models.Schedule) (*models.ScheduleRun, error) {
+// applyMisfirePolicy picks the occurrences to run. Occurrences older than
+// misfireThreshold are misfires; the most recent occurrence stands for all of
+// them unless the policy runs every one.
+func applyMisfirePolicy(policy models.MisfirePolicy, occurrences []time.Time, now time.Time) []time.Time {
+	latest := occurrences[len(occurrences)-1]
+
+	switch policy {
+	case models.MisfireRunAll:
+		return occurrences
+	case models.MisfireSkip:
+		if now.Sub(latest) > misfireThreshold {
+			return nil
+		}
+		return []time.Time{latest}
+	default:
+		return []time.Time{latest}
+	}
+}
+
+// createRun records a run of an occurrence, or a skipped run if the previous one is still going
+func (s *Scheduler) createRun(schedule *models.Schedule, scheduledAt time.Time) (*models.ScheduleRun, error) {
 	// Check for overlapping execution
 	if !schedule.AllowOverlap {
 		runningRun, err := s.scheduleRepo.GetRunningRun(schedule.ID)
@@ -263,104 +439,268 @@ func (s *Scheduler) executeSchedule(schedule *models.Schedule) (*models.Schedule
 			run := &models.ScheduleRun{
 				ScheduleID:  schedule.ID,
 				Status:      "skipped",
-				ScheduledAt: time.Now(),
+				ScheduledAt: scheduledAt,
 			}
 			if err := s.scheduleRepo.CreateRun(run); err != nil {
 				return nil, err
 			}
 			s.scheduleRepo.MarkRunSkipped(run.ID, "Previous execution still running")
+			s.metrics.ScheduleRunFinished(schedule.Name, "skipped")
 			s.logger.Info("Schedule execution skipped due to overlap",
 				zap.String("schedule_id", schedule.ID.String()))
 			return run, nil
 		}
 	}
 
-	// Create run record
+	now := time.Now()
 	run := &models.ScheduleRun{
 		ScheduleID:  schedule.ID,
 		Status:      "pending",
-		ScheduledAt: time.Now(),
+		ScheduledAt: scheduledAt,
+		HeartbeatAt: &now,
 	}
 	if err := s.scheduleRepo.CreateRun(run); err != nil {
 		return nil, err
 	}
+	return run, nil
+}
+
+// performRun executes a run, waiting for jitter first. Flows that fail are
+// retried up to MaxRetries times, RetryDelay apart; flows that passed or
+// failed in quarantine are not run again.
+func (s *Scheduler) performRun(schedule *models.Schedule, run *models.ScheduleRun, jitter bool) {
+	stopHeartbeat := s.heartbeat(run.ID)
+	defer stopHeartbeat()
+
+	if jitter {
+		if delay := jitterDelay(schedule.Jitter); delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-s.ctx.Done():
+				s.scheduleRepo.MarkRunCompleted(run.ID, "failure", "Scheduler stopped before the run started")
+				s.metrics.ScheduleRunFinished(schedule.Name, "failure")
+				return
+			}
+		}
+	}
 
-	// Execute the flow
 	if s.executeFunc == nil {
 		s.scheduleRepo.MarkRunCompleted(run.ID, "failure", "No execution function configured")
-		return run, nil
+		s.metrics.ScheduleRunFinished(schedule.Name, "failure")
+		return
 	}
 
-	// Execute asynchronously
-	go func() {
-		startTime := time.Now()
+	s.scheduleRepo.MarkRunRunning(run.ID)
+	startTime := time.Now()
 
-		execID, result, err := s.executeFunc(s.ctx, schedule.FlowID, schedule.Environment)
+	flows, err := s.ResolveTargets(schedule)
+	if err == nil && len(flows) == 0 {
+		err = fmt.Errorf("schedule target matched no flows")
+	}
+	if err != nil {
+		s.scheduleRepo.MarkRunCompleted(run.ID, "failure", err.Error())
+		s.scheduleRepo.UpdateLastRun(schedule.ID, run.ID, "failure")
+		s.metrics.ScheduleRunFinished(schedule.Name, "failure")
+		s.logger.Error("Schedule execution failed",
+			zap.String("schedule_id", schedule.ID.String()),
+			zap.Error(err))
+		s.observeRun(schedule, run, "failure", err.Error(), time.Since(startTime), nil)
+		return
+	}
 
-		duration := time.Since(startTime).Milliseconds()
+	results := make([]models.ScheduleRunFlow, len(flows))
+	pending := make([]int, len(flows))
+	for i, flow := range flows {
+		results[i] = models.ScheduleRunFlow{FlowID: flow.ID, FlowName: flow.Name, Suite: flow.Suite}
+		pending[i] = i
+	}
 
-		if err != nil {
-			s.scheduleRepo.MarkRunCompleted(run.ID, "failure", err.Error())
-			s.scheduleRepo.UpdateLastRun(schedule.ID, run.ID, "failure")
-			s.logger.Error("Schedule execution failed",
-				zap.String("schedule_id", schedule.ID.String()),
-				zap.Error(err))
-		} else {
-			s.scheduleRepo.MarkRunStarted(run.ID, execID)
-			s.scheduleRepo.MarkRunCompleted(run.ID, result, "")
-			s.scheduleRepo.UpdateLastRun(schedule.ID, run.ID, result)
-			s.logger.Info("Schedule execution completed",
-				zap.String("schedule_id", schedule.ID.String()),
-				zap.String("result", result),
-				zap.Int64("duration_ms", duration))
+	retryDelay := parseRetryDelay(schedule.RetryDelay)
+
+	for attempt := 0; ; attempt++ {
+		s.runFlows(schedule, run.ID, flows, results, pending)
+
+		// Quarantined failures do not fail the run, so they are not retried
+		pending = pending[:0]
+		for i := range results {
+			if results[i].Status != "passed" && results[i].Status != "quarantined" {
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 || attempt >= schedule.MaxRetries || s.ctx.Err() != nil {
+			break
 		}
 
-		// Update next run time
-		nextRun, _ := s.calculateNextRun(schedule.CronExpr, schedule.Timezone)
-		s.scheduleRepo.UpdateNextRunTime(schedule.ID, nextRun)
+		s.scheduleRepo.IncrementRunRetry(run.ID, fmt.Sprintf("%d of %d flow(s) did not pass", len(pending), len(flows)))
+		s.logger.Info("Retrying schedule execution",
+			zap.String("schedule_id", schedule.ID.String()),
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_retries", schedule.MaxRetries),
+			zap.Int("flows", len(pending)),
+			zap.Duration("delay", retryDelay))
+
+		select {
+		case <-time.After(retryDelay):
+		case <-s.ctx.Done():
+		}
+	}
+
+	s.scheduleRepo.UpdateRunResults(run.ID, results)
+	if len(results) == 1 && results[0].ExecutionID != nil {
+		s.scheduleRepo.SetRunExecution(run.ID, *results[0].ExecutionID)
+	}
+
+	result, errorMsg := summarizeResults(results)
+	duration := time.Since(startTime).Milliseconds()
+
+	s.scheduleRepo.MarkRunCompleted(run.ID, result, errorMsg)
+	s.scheduleRepo.UpdateLastRun(schedule.ID, run.ID, result)
+	s.metrics.ScheduleRunFinished(schedule.Name, result)
+	s.logger.Info("Schedule execution completed",
+		zap.String("schedule_id", schedule.ID.String()),
+		zap.String("result", result),
+		zap.Int("flows", len(flows)),
+		zap.Int64("duration_ms", duration))
+	s.observeRun(schedule, run, result, errorMsg, time.Since(startTime), results)
+}
+
+// heartbeat renews the heartbeat of a run until the returned function is
+// called, so that the leader does not take the run for abandoned
+func (s *Scheduler) heartbeat(runID uuid.UUID) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.scheduleRepo.TouchRun(runID); err != nil {
+					s.logger.Warn("Failed to renew schedule run heartbeat",
+						zap.String("run_id", runID.String()),
+						zap.Error(err))
+				}
+			}
+		}
 	}()
+	return func() { close(done) }
+}
 
-	return run, nil
+// reapStaleRuns fails the pending and running runs nobody renews anymore,
+// such as runs of a replica that stopped, so that they do not block
+// overlap prevention forever
+func (s *Scheduler) reapStaleRuns() {
+	runs, err := s.scheduleRepo.AbandonStaleRuns(staleRunAfter, "Run abandoned: the replica running it stopped")
+	if err != nil {
+		s.logger.Error("Failed to reap abandoned schedule runs", zap.Error(err))
+	}
+	for _, run := range runs {
+		s.logger.Warn("Schedule run abandoned",
+			zap.String("schedule_id", run.ScheduleID.String()),
+			zap.String("run_id", run.ID.String()))
+		s.scheduleRepo.UpdateLastRun(run.ScheduleID, run.ID, "failure")
+		if run.Schedule != nil {
+			s.metrics.ScheduleRunFinished(run.Schedule.Name, "failure")
+		}
+	}
 }
 
-// calculateNextRun calculates the next run time for a cron expression
-func (s *Scheduler) calculateNextRun(cronExpr string, timezone string) (time.Time, error) {
+// observeRun passes a finished run to the run observer
+func (s *Scheduler) observeRun(schedule *models.Schedule, run *models.ScheduleRun, result, errorMsg string, duration time.Duration, results []models.ScheduleRunFlow) {
+	if s.runObserver == nil {
+		return
+	}
+	now := time.Now()
+	run.Result = result
+	run.Error = errorMsg
+	run.Duration = duration.Milliseconds()
+	run.FlowResults = results
+	run.CompletedAt = &now
+	s.runObserver(schedule, run)
+}
+
+// jitterDelay returns a random delay below the jitter duration
+func jitterDelay(jitter string) time.Duration {
+	if jitter == "" {
+		return 0
+	}
+	max, err := time.ParseDuration(jitter)
+	if err != nil || max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// parseRetryDelay parses the retry delay of a schedule, defaulting to one minute
+func parseRetryDelay(delay string) time.Duration {
+	d, err := time.ParseDuration(delay)
+	if err != nil || d < 0 {
+		return defaultRetryDelay
+	}
+	return d
+}
+
+// scheduleLocation returns the location of a schedule, falling back to UTC
+func scheduleLocation(timezone string) *time.Location {
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
-		loc = time.UTC
+		return time.UTC
+	}
+	return loc
+}
+
+// ValidateSchedule validates the firing settings of a schedule
+func ValidateSchedule(schedule *models.Schedule) error {
+	switch schedule.MisfirePolicy {
+	case models.MisfireSkip, models.MisfireRunOnce, models.MisfireRunAll:
+	default:
+		return fmt.Errorf("invalid misfire policy %q (expected skip, run_once or run_all)", schedule.MisfirePolicy)
+	}
+	if schedule.Jitter != "" {
+		if d, err := time.ParseDuration(schedule.Jitter); err != nil || d < 0 {
+			return fmt.Errorf("invalid jitter %q", schedule.Jitter)
+		}
 	}
+	if schedule.RetryDelay != "" {
+		if d, err := time.ParseDuration(schedule.RetryDelay); err != nil || d < 0 {
+			return fmt.Errorf("invalid retry delay %q", schedule.RetryDelay)
+		}
+	}
+	if schedule.MaxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative")
+	}
+	if schedule.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+	return validateTarget(schedule)
+}
 
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	sched, err := parser.Parse(cronExpr)
+// calculateNextRun calculates the next run time for a cron expression
+func (s *Scheduler) calculateNextRun(cronExpr string, timezone string) (time.Time, error) {
+	sched, err := cronParser.Parse(cronExpr)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	return sched.Next(time.Now().In(loc)), nil
+	return sched.Next(time.Now().In(scheduleLocation(timezone))), nil
 }
 
 // ValidateCronExpression validates a cron expression
 func ValidateCronExpression(expr string) error {
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	_, err := parser.Parse(expr)
+	_, err := cronParser.Parse(expr)
 	return err
 }
 
 // GetNextRunTimes returns the next N run times for a cron expression
 func GetNextRunTimes(expr string, timezone string, count int) ([]time.Time, error) {
-	loc, err := time.LoadLocation(timezone)
-	if err != nil {
-		loc = time.UTC
-	}
-
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	sched, err := parser.Parse(expr)
+	sched, err := cronParser.Parse(expr)
 	if err != nil {
 		return nil, err
 	}
 
 	times := make([]time.Time, count)
-	t := time.Now().In(loc)
+	t := time.Now().In(scheduleLocation(timezone))
 	for i := 0; i < count; i++ {
 		t = sched.Next(t)
 		times[i] = t
@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/georgi-georgiev/testmesh/internal/tagquery"
+	"github.com/google/uuid"
+)
+
+// ResolveTargets returns the flows a schedule runs, in run order
+func (s *Scheduler) ResolveTargets(schedule *models.Schedule) ([]models.Flow, error) {
+	switch schedule.TargetType {
+	case "", models.ScheduleTargetFlow:
+		if schedule.FlowID == nil {
+			return nil, fmt.Errorf("schedule has no flow")
+		}
+		flow, err := s.workspaceFlow(schedule.WorkspaceID, *schedule.FlowID)
+		if err != nil {
+			return nil, err
+		}
+		return []models.Flow{*flow}, nil
+
+	case models.ScheduleTargetFlows:
+		flows := make([]models.Flow, 0, len(schedule.FlowIDs))
+		seen := make(map[uuid.UUID]bool)
+		for _, raw := range schedule.FlowIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid flow ID %q", raw)
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			flow, err := s.workspaceFlow(schedule.WorkspaceID, id)
+			if err != nil {
+				return nil, err
+			}
+			flows = append(flows, *flow)
+		}
+		return flows, nil
+
+	case models.ScheduleTargetCollection:
+		return s.collectionFlows(*schedule.WorkspaceID, *schedule.CollectionID)
+
+	case models.ScheduleTargetSuite:
+		return s.flowRepo.ListBySuite(*schedule.WorkspaceID, schedule.Suite)
+
+	case models.ScheduleTargetTags:
+		query, err := tagquery.Parse(schedule.TagQuery)
+		if err != nil {
+			return nil, err
+		}
+		all, err := s.flowRepo.ListAll(*schedule.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		var flows []models.Flow
+		for _, flow := range all {
+			if query.Match(flow.Tags) {
+				flows = append(flows, flow)
+			}
+		}
+		return flows, nil
+	}
+
+	return nil, fmt.Errorf("unknown schedule target type %q", schedule.TargetType)
+}
+
+// workspaceFlow loads a flow targeted by ID, refusing flows of another
+// workspace than the schedule's. Schedules from before workspaces have none.
+func (s *Scheduler) workspaceFlow(workspaceID *uuid.UUID, id uuid.UUID) (*models.Flow, error) {
+	flow, err := s.flowRepo.GetByIDUnscoped(id)
+	if err != nil {
+		return nil, fmt.Errorf("flow %s not found: %w", id, err)
+	}
+	if workspaceID != nil && flow.WorkspaceID != *workspaceID {
+		return nil, fmt.Errorf("flow %s is not in the workspace of the schedule", id)
+	}
+	if flow.AdHoc {
+		return nil, fmt.Errorf("flow %s is an ad-hoc flow and cannot be scheduled", id)
+	}
+	return flow, nil
+}
+
+// CheckTargetWorkspace checks that the flows a schedule targets by ID belong
+// to its workspace. A schedule without a workspace takes the workspace of its
+// first flow, so every flow must share it.
+func (s *Scheduler) CheckTargetWorkspace(schedule *models.Schedule) error {
+	var ids []uuid.UUID
+	switch schedule.TargetType {
+	case "", models.ScheduleTargetFlow:
+		if schedule.FlowID != nil {
+			ids = append(ids, *schedule.FlowID)
+		}
+	case models.ScheduleTargetFlows:
+		for _, raw := range schedule.FlowIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("invalid flow ID %q", raw)
+			}
+			ids = append(ids, id)
+		}
+	default:
+		// Other targets list flows of the schedule's workspace only
+		return nil
+	}
+
+	for _, id := range ids {
+		flow, err := s.workspaceFlow(schedule.WorkspaceID, id)
+		if err != nil {
+			return err
+		}
+		if schedule.WorkspaceID == nil {
+			workspaceID := flow.WorkspaceID
+			schedule.WorkspaceID = &workspaceID
+		}
+	}
+	return nil
+}
+
+// collectionFlows returns the flows of a collection followed by those of its
+// nested collections, depth first in sort order
+func (s *Scheduler) collectionFlows(workspaceID, collectionID uuid.UUID) ([]models.Flow, error) {
+	collections, err := s.collectionRepo.ListAll(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]uuid.UUID)
+	found := false
+	for _, c := range collections {
+		if c.ID == collectionID {
+			found = true
+		}
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("collection %s not found", collectionID)
+	}
+
+	var flows []models.Flow
+	var walk func(id uuid.UUID) error
+	walk = func(id uuid.UUID) error {
+		list, err := s.flowRepo.ListByCollection(workspaceID, id)
+		if err != nil {
+			return err
+		}
+		flows = append(flows, list...)
+		for _, child := range children[id] {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(collectionID); err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
+// runFlows executes the flows at the given indexes with the schedule's
+// concurrency, recording the outcome in results. With fail_fast, flows that
+// have not started when one fails are skipped.
+func (s *Scheduler) runFlows(schedule *models.Schedule, runID uuid.UUID, flows []models.Flow, results []models.ScheduleRunFlow, indexes []int) {
+	concurrency := schedule.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, i := range indexes {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			results[i].Status = "skipped"
+			results[i].Error = "Skipped after an earlier flow failed"
+			if s.ctx.Err() != nil {
+				results[i].Error = "Scheduler stopped"
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			startTime := time.Now()
+			execID, result, err := s.executeFunc(ctx, flows[i].ID, runID, schedule.Environment)
+
+			r := &results[i]
+			r.Attempts++
+			r.DurationMs = time.Since(startTime).Milliseconds()
+			if execID != uuid.Nil {
+				r.ExecutionID = &execID
+			}
+
+			if err == nil && result == "quarantined" {
+				r.Status = "quarantined"
+				r.Error = "Failed in quarantine"
+				return
+			}
+			if err == nil && result != "failure" {
+				r.Status = "passed"
+				r.Error = ""
+				return
+			}
+
+			r.Status = "failed"
+			r.Error = "execution failed"
+			if err != nil {
+				r.Error = err.Error()
+			}
+			if schedule.FailFast {
+				cancel()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// summarizeResults returns the run result and an error message listing the flows that did not pass
+func summarizeResults(results []models.ScheduleRunFlow) (string, string) {
+	var failed []string
+	skipped := 0
+	// Flows that failed in quarantine do not fail the run
+	for _, r := range results {
+		switch r.Status {
+		case "failed":
+			failed = append(failed, fmt.Sprintf("%s: %s", r.FlowName, r.Error))
+		case "skipped":
+			skipped++
+		}
+	}
+
+	if len(failed) == 0 && skipped == 0 {
+		return "success", ""
+	}
+
+	msg := fmt.Sprintf("%d of %d flow(s) failed", len(failed), len(results))
+	if skipped > 0 {
+		msg += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if len(failed) > 0 {
+		msg += ": " + strings.Join(failed, "; ")
+	}
+	return "failure", msg
+}
+
+// validateTarget checks that the fields required by the target type are set
+func validateTarget(schedule *models.Schedule) error {
+	switch schedule.TargetType {
+	case "", models.ScheduleTargetFlow:
+		if schedule.FlowID == nil {
+			return fmt.Errorf("flow_id is required for target type flow")
+		}
+		return nil
+	case models.ScheduleTargetFlows:
+		if len(schedule.FlowIDs) == 0 {
+			return fmt.Errorf("flow_ids is required for target type flows")
+		}
+		for _, raw := range schedule.FlowIDs {
+			if _, err := uuid.Parse(raw); err != nil {
+				return fmt.Errorf("invalid flow ID %q", raw)
+			}
+		}
+		return nil
+	}
+
+	if schedule.WorkspaceID == nil {
+		return fmt.Errorf("workspace_id is required for target type %s", schedule.TargetType)
+	}
+
+	switch schedule.TargetType {
+	case models.ScheduleTargetCollection:
+		if schedule.CollectionID == nil {
+			return fmt.Errorf("collection_id is required for target type collection")
+		}
+	case models.ScheduleTargetSuite:
+		if schedule.Suite == "" {
+			return fmt.Errorf("suite is required for target type suite")
+		}
+	case models.ScheduleTargetTags:
+		if _, err := tagquery.Parse(schedule.TagQuery); err != nil {
+			return fmt.Errorf("invalid tag_query: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown target type %q (expected flow, flows, collection, suite or tags)", schedule.TargetType)
+	}
+	return nil
+}
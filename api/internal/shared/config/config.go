@@ -13,13 +13,18 @@ type Config struct {
 	Database    DatabaseConfig
 	Redis       RedisConfig
 	Logger      LoggerConfig
+	Metrics     MetricsConfig
+	Artifacts   ArtifactsConfig
+	Events      EventsConfig
+	Runner      RunnerConfig
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port           int
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	AllowedOrigins []string // Browser origins besides the API's own that may open WebSockets, e.g. the dashboard
 }
 
 // DatabaseConfig holds database configuration
@@ -48,12 +53,58 @@ type LoggerConfig struct {
 	OutputPath string
 }
 
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	Enabled        bool
+	Path           string
+	DropLabels     []string // Labels removed from every series, e.g. flow or mock_server
+	MaxLabelValues int      // Distinct values kept per label before the rest are reported as "other"
+}
+
+// ArtifactsConfig holds execution artifact storage configuration
+type ArtifactsConfig struct {
+	Backend          string        // "filesystem" or "s3"
+	Path             string        // Root directory of the filesystem backend
+	OffloadThreshold int           // Step output values larger than this many bytes are stored as artifacts; 0 keeps them inline
+	RetentionDays    int           // Days artifacts are kept unless a workspace sets its own; 0 keeps them forever
+	CleanupInterval  time.Duration // How often expired artifacts are removed
+	BaseURL          string        // Base URL of the API used in report links; defaults to http://localhost:<port>
+	S3               S3Config
+}
+
+// RunnerConfig holds flow runner configuration
+type RunnerConfig struct {
+	SchemaDir       string   // Directory schema assertion files are read from; empty disables schema files
+	DataSourceHosts []string // Database hosts, as host or host:port, SQL data sources may query; empty allows any host
+}
+
+// EventsConfig holds the event bus that delivers execution and debug events
+// to WebSocket clients
+type EventsConfig struct {
+	Backend    string        // "memory" for a single server, or "postgres" for replicas sharing the database
+	Channel    string        // LISTEN/NOTIFY channel of the postgres backend
+	ReplaySize int           // Events kept per execution for clients that subscribe late; 0 disables replay
+	Retention  time.Duration // How long the events of a finished execution are kept for replay
+}
+
+// S3Config holds the settings of an S3-compatible object store such as MinIO
+type S3Config struct {
+	Endpoint  string // host:port, without scheme
+	Region    string
+	Bucket    string
+	Prefix    string // Prepended to every object key
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	viper.SetDefault("environment", "development")
 	viper.SetDefault("server.port", 5016)
 	viper.SetDefault("server.read_timeout", "15s")
 	viper.SetDefault("server.write_timeout", "15s")
+	viper.SetDefault("server.allowed_origins", []string{"http://localhost:3000"})
 
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -72,6 +123,28 @@ func Load() (*Config, error) {
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.output_path", "stdout")
 
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.drop_labels", []string{})
+	viper.SetDefault("metrics.max_label_values", 200)
+
+	viper.SetDefault("artifacts.backend", "filesystem")
+	viper.SetDefault("artifacts.path", "./data/artifacts")
+	viper.SetDefault("artifacts.offload_threshold", 64*1024)
+	viper.SetDefault("artifacts.retention_days", 30)
+	viper.SetDefault("artifacts.cleanup_interval", "1h")
+	viper.SetDefault("artifacts.s3.endpoint", "localhost:9000")
+	viper.SetDefault("artifacts.s3.region", "us-east-1")
+	viper.SetDefault("artifacts.s3.bucket", "testmesh-artifacts")
+	viper.SetDefault("artifacts.s3.use_ssl", false)
+
+	viper.SetDefault("events.backend", "memory")
+	viper.SetDefault("events.channel", "testmesh_events")
+	viper.SetDefault("events.replay_size", 1000)
+	viper.SetDefault("events.retention", "5m")
+	viper.SetDefault("runner.schema_dir", "./data/schemas")
+	viper.SetDefault("runner.data_source_hosts", []string{})
+
 	// Auto-load environment variables
 	viper.AutomaticEnv()
 
@@ -84,13 +157,16 @@ func Load() (*Config, error) {
 
 	readTimeout, _ := time.ParseDuration(viper.GetString("server.read_timeout"))
 	writeTimeout, _ := time.ParseDuration(viper.GetString("server.write_timeout"))
+	cleanupInterval, _ := time.ParseDuration(viper.GetString("artifacts.cleanup_interval"))
+	eventsRetention, _ := time.ParseDuration(viper.GetString("events.retention"))
 
 	cfg := &Config{
 		Environment: viper.GetString("environment"),
 		Server: ServerConfig{
-			Port:         viper.GetInt("server.port"),
-			ReadTimeout:  readTimeout,
-			WriteTimeout: writeTimeout,
+			Port:           viper.GetInt("server.port"),
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			AllowedOrigins: viper.GetStringSlice("server.allowed_origins"),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("database.host"),
@@ -112,6 +188,39 @@ func Load() (*Config, error) {
 			Level:      viper.GetString("logger.level"),
 			OutputPath: viper.GetString("logger.output_path"),
 		},
+		Metrics: MetricsConfig{
+			Enabled:        viper.GetBool("metrics.enabled"),
+			Path:           viper.GetString("metrics.path"),
+			DropLabels:     viper.GetStringSlice("metrics.drop_labels"),
+			MaxLabelValues: viper.GetInt("metrics.max_label_values"),
+		},
+		Artifacts: ArtifactsConfig{
+			Backend:          viper.GetString("artifacts.backend"),
+			Path:             viper.GetString("artifacts.path"),
+			OffloadThreshold: viper.GetInt("artifacts.offload_threshold"),
+			RetentionDays:    viper.GetInt("artifacts.retention_days"),
+			CleanupInterval:  cleanupInterval,
+			BaseURL:          viper.GetString("artifacts.base_url"),
+			S3: S3Config{
+				Endpoint:  viper.GetString("artifacts.s3.endpoint"),
+				Region:    viper.GetString("artifacts.s3.region"),
+				Bucket:    viper.GetString("artifacts.s3.bucket"),
+				Prefix:    viper.GetString("artifacts.s3.prefix"),
+				AccessKey: viper.GetString("artifacts.s3.access_key"),
+				SecretKey: viper.GetString("artifacts.s3.secret_key"),
+				UseSSL:    viper.GetBool("artifacts.s3.use_ssl"),
+			},
+		},
+		Events: EventsConfig{
+			Backend:    viper.GetString("events.backend"),
+			Channel:    viper.GetString("events.channel"),
+			ReplaySize: viper.GetInt("events.replay_size"),
+			Retention:  eventsRetention,
+		},
+		Runner: RunnerConfig{
+			SchemaDir:       viper.GetString("runner.schema_dir"),
+			DataSourceHosts: viper.GetStringSlice("runner.data_source_hosts"),
+		},
 	}
 
 	return cfg, nil
@@ -10,14 +10,17 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// New creates a new database connection
-func New(cfg config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
+// DSN returns the connection string of a database
+func DSN(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+// New creates a new database connection
+func New(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(DSN(cfg)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
@@ -189,7 +192,11 @@ func AutoMigrate(db *gorm.DB) error {
 		ALTER TABLE flows.flows ADD COLUMN IF NOT EXISTS sort_order INTEGER DEFAULT 0;
 		ALTER TABLE flows.flows ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
 		CREATE INDEX IF NOT EXISTS idx_flows_workspace_id ON flows.flows(workspace_id);
-		CREATE UNIQUE INDEX IF NOT EXISTS idx_flows_workspace_name ON flows.flows(workspace_id, name) WHERE deleted_at IS NULL;
+		ALTER TABLE flows.flows ADD COLUMN IF NOT EXISTS ad_hoc BOOLEAN DEFAULT false;
+		ALTER TABLE flows.flows ADD COLUMN IF NOT EXISTS content_hash TEXT;
+		CREATE INDEX IF NOT EXISTS idx_flows_workspace_content_hash ON flows.flows(workspace_id, content_hash) WHERE ad_hoc;
+		DROP INDEX IF EXISTS flows.idx_flows_workspace_name;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_flows_workspace_shared_name ON flows.flows(workspace_id, name) WHERE deleted_at IS NULL AND NOT ad_hoc;
 	`)
 
 	// Create executions table
@@ -743,6 +750,45 @@ func AutoMigrate(db *gorm.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_schedule_runs_scheduled_at ON schedule_runs(scheduled_at);
 	`)
 
+	// Add misfire policy and jitter to schedules
+	db.Exec(`
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS misfire_policy VARCHAR(20) DEFAULT 'run_once';
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS jitter VARCHAR(20) DEFAULT '';
+	`)
+
+	// Schedule targets (collection, suite, tag query, flow list) and grouped runs
+	db.Exec(`
+		ALTER TABLE schedules ALTER COLUMN flow_id DROP NOT NULL;
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS target_type VARCHAR(20) NOT NULL DEFAULT 'flow';
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS collection_id UUID REFERENCES flows.collections(id) ON DELETE SET NULL;
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS suite VARCHAR(255) DEFAULT '';
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS tag_query TEXT DEFAULT '';
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS flow_ids TEXT[] DEFAULT '{}';
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS concurrency INTEGER DEFAULT 1;
+		ALTER TABLE schedules ADD COLUMN IF NOT EXISTS fail_fast BOOLEAN DEFAULT false;
+		CREATE INDEX IF NOT EXISTS idx_schedules_workspace_id ON schedules(workspace_id);
+
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS total_flows INTEGER DEFAULT 0;
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS passed_flows INTEGER DEFAULT 0;
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS failed_flows INTEGER DEFAULT 0;
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS skipped_flows INTEGER DEFAULT 0;
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS flow_results JSONB DEFAULT '[]';
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS schedule_run_id UUID REFERENCES schedule_runs(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_executions_schedule_run_id ON executions.executions(schedule_run_id);
+	`)
+
+	// Create scheduler_leases table (leader election between API replicas)
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduler_leases (
+			name VARCHAR(100) PRIMARY KEY,
+			holder VARCHAR(255) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+	`)
+
 	// Create request_history table
 	db.Exec(`
 		CREATE TABLE IF NOT EXISTS flows.request_history (
@@ -781,6 +827,250 @@ func AutoMigrate(db *gorm.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_collection_items_flow_id ON flows.collection_items(flow_id);
 	`)
 
+	// Create api_specs table for API descriptions attached to a workspace
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS flows.api_specs (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			spec_type VARCHAR(20),
+			version VARCHAR(100),
+			content TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_api_specs_workspace_id ON flows.api_specs(workspace_id);
+		CREATE INDEX IF NOT EXISTS idx_api_specs_deleted_at ON flows.api_specs(deleted_at);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_api_specs_workspace_name ON flows.api_specs(workspace_id, name) WHERE deleted_at IS NULL;
+	`)
+
+	// Create snapshots table for golden records of step outputs
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS flows.snapshots (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			flow_id UUID NOT NULL REFERENCES flows.flows(id) ON DELETE CASCADE,
+			step_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'accepted',
+			golden JSONB NOT NULL,
+			pending JSONB,
+			pending_changes JSONB,
+			pending_execution_id UUID,
+			accepted_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(flow_id, name)
+		);
+		CREATE INDEX IF NOT EXISTS idx_snapshots_flow_id ON flows.snapshots(flow_id);
+		CREATE INDEX IF NOT EXISTS idx_snapshots_status ON flows.snapshots(status);
+	`)
+
+	// Create git sync tables binding collections to flow files in git repositories
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS flows.git_sync_bindings (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			collection_id UUID NOT NULL UNIQUE REFERENCES flows.collections(id) ON DELETE CASCADE,
+			integration_id UUID REFERENCES system_integrations(id) ON DELETE SET NULL,
+			repository_url TEXT NOT NULL,
+			repository VARCHAR(255),
+			branch VARCHAR(255) NOT NULL DEFAULT 'main',
+			path VARCHAR(500),
+			push_branch VARCHAR(255),
+			create_pull_request BOOLEAN DEFAULT false,
+			sync_interval VARCHAR(50),
+			enabled BOOLEAN DEFAULT true,
+			status VARCHAR(20) NOT NULL DEFAULT 'idle',
+			last_commit VARCHAR(64),
+			last_synced_at TIMESTAMP WITH TIME ZONE,
+			last_error TEXT,
+			pull_request_url TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_git_sync_bindings_workspace_id ON flows.git_sync_bindings(workspace_id);
+		CREATE INDEX IF NOT EXISTS idx_git_sync_bindings_repository ON flows.git_sync_bindings(repository);
+
+		CREATE TABLE IF NOT EXISTS flows.git_sync_files (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			binding_id UUID NOT NULL REFERENCES flows.git_sync_bindings(id) ON DELETE CASCADE,
+			flow_id UUID NOT NULL,
+			file_path VARCHAR(500) NOT NULL,
+			content_hash VARCHAR(64),
+			flow_version INTEGER DEFAULT 0,
+			commit_sha VARCHAR(64),
+			conflict TEXT,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(binding_id, file_path)
+		);
+		CREATE INDEX IF NOT EXISTS idx_git_sync_files_flow_id ON flows.git_sync_files(flow_id);
+	`)
+
+	// Create quarantines table and execution retry columns for flaky test quarantine
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS reporting.quarantines (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			flow_id UUID NOT NULL REFERENCES flows.flows(id) ON DELETE CASCADE,
+			step_id VARCHAR(255) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			source VARCHAR(20) NOT NULL DEFAULT 'manual',
+			reason TEXT,
+			flakiness_score DECIMAL(5,4) DEFAULT 0,
+			clean_runs INTEGER DEFAULT 0,
+			quarantined_by UUID,
+			released_at TIMESTAMP WITH TIME ZONE,
+			release_reason TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_quarantines_workspace_id ON reporting.quarantines(workspace_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantines_active ON reporting.quarantines(flow_id, step_id) WHERE status = 'active';
+
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS attempt INTEGER DEFAULT 1;
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS retry_of UUID REFERENCES executions.executions(id) ON DELETE SET NULL;
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS quarantined BOOLEAN DEFAULT false;
+		CREATE INDEX IF NOT EXISTS idx_executions_retry_of ON executions.executions(retry_of);
+		ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS quarantined_flows INTEGER DEFAULT 0;
+	`)
+
+	// Create synthetic monitoring tables: monitors, their checks and alerts
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS monitors (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT,
+			flow_id UUID NOT NULL REFERENCES flows.flows(id) ON DELETE CASCADE,
+			schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			interval VARCHAR(50) NOT NULL,
+			environment JSONB DEFAULT '{}',
+			slos JSONB DEFAULT '[]',
+			pending_for VARCHAR(50),
+			repeat_interval VARCHAR(50),
+			notify_webhook_url TEXT,
+			public BOOLEAN DEFAULT false,
+			alert_state VARCHAR(20) NOT NULL DEFAULT 'ok',
+			alert_since TIMESTAMP WITH TIME ZONE,
+			last_check_at TIMESTAMP WITH TIME ZONE,
+			last_check_result VARCHAR(20),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_monitors_workspace_id ON monitors(workspace_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_monitors_schedule_id ON monitors(schedule_id);
+
+		CREATE TABLE IF NOT EXISTS monitor_checks (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			monitor_id UUID NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+			schedule_run_id UUID,
+			execution_id UUID,
+			location VARCHAR(100),
+			result VARCHAR(20) NOT NULL,
+			error TEXT,
+			duration_ms BIGINT DEFAULT 0,
+			checked_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_monitor_checks_monitor_checked ON monitor_checks(monitor_id, checked_at);
+		CREATE INDEX IF NOT EXISTS idx_monitor_checks_execution_id ON monitor_checks(execution_id);
+
+		CREATE TABLE IF NOT EXISTS monitor_alerts (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			monitor_id UUID NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+			state VARCHAR(20) NOT NULL,
+			reason TEXT,
+			breaches TEXT[],
+			pending_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			fired_at TIMESTAMP WITH TIME ZONE,
+			resolved_at TIMESTAMP WITH TIME ZONE,
+			last_notified_at TIMESTAMP WITH TIME ZONE,
+			notifications INTEGER DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_monitor_alerts_monitor_id ON monitor_alerts(monitor_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_monitor_alerts_open ON monitor_alerts(monitor_id) WHERE state IN ('pending', 'firing');
+	`)
+
+	// Create execution artifacts table: offloaded step outputs, screenshots and files.
+	// No foreign keys, so that retention can remove the stored files of deleted executions.
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS executions.artifacts (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID,
+			execution_id UUID NOT NULL,
+			execution_step_id UUID,
+			step_id VARCHAR(255),
+			kind VARCHAR(20) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			content_type VARCHAR(255),
+			size BIGINT DEFAULT 0,
+			checksum VARCHAR(64),
+			backend VARCHAR(20) NOT NULL,
+			storage_key TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_artifacts_execution_id ON executions.artifacts(execution_id);
+		CREATE INDEX IF NOT EXISTS idx_artifacts_workspace_created ON executions.artifacts(workspace_id, created_at);
+	`)
+
+	// Create context snapshots table: variables and step outputs recorded
+	// before each step, for restarting executions from a step
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS executions.context_snapshots (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			execution_id UUID NOT NULL REFERENCES executions.executions(id) ON DELETE CASCADE,
+			sequence INTEGER NOT NULL,
+			phase VARCHAR(20) NOT NULL,
+			step_id VARCHAR(255) NOT NULL,
+			step_name VARCHAR(255),
+			action VARCHAR(100),
+			step_index INTEGER DEFAULT 0,
+			parent_step_id VARCHAR(255),
+			variables JSONB,
+			step_outputs JSONB,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_context_snapshots_execution ON executions.context_snapshots(execution_id, sequence);
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS record_context BOOLEAN DEFAULT false;
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS restart_of UUID REFERENCES executions.executions(id) ON DELETE SET NULL;
+		ALTER TABLE executions.executions ADD COLUMN IF NOT EXISTS restart_from VARCHAR(255);
+		CREATE INDEX IF NOT EXISTS idx_executions_restart_of ON executions.executions(restart_of);
+	`)
+
+	// Create event overflow table: payloads of events too large for NOTIFY,
+	// kept briefly for the replicas of the postgres event bus
+	db.Exec(`
+		CREATE UNLOGGED TABLE IF NOT EXISTS executions.event_overflow (
+			id UUID PRIMARY KEY,
+			payload TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_event_overflow_created_at ON executions.event_overflow(created_at);
+	`)
+
+	// Create data files table: CSV and JSON tables of test data that data
+	// sources of collection runs read by name
+	db.Exec(`
+		CREATE TABLE IF NOT EXISTS flows.data_files (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT,
+			format VARCHAR(10) NOT NULL,
+			content TEXT,
+			row_count INTEGER DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_data_files_workspace_id ON flows.data_files(workspace_id);
+		CREATE INDEX IF NOT EXISTS idx_data_files_deleted_at ON flows.data_files(deleted_at);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_data_files_workspace_name ON flows.data_files(workspace_id, LOWER(name)) WHERE deleted_at IS NULL;
+	`)
+
 	// Create workspace_members table
 	db.Exec(`
 		CREATE TABLE IF NOT EXISTS workspace_members (
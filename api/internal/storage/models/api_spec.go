@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APISpec is an API description (OpenAPI 3 / Swagger 2) attached to a workspace.
+// Flows reference its schemas from schema assertions as "<name>#/json/pointer".
+type APISpec struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;index;not null" json:"workspace_id"`
+	Name        string         `gorm:"not null;index" json:"name"`
+	SpecType    string         `gorm:"type:varchar(20)" json:"spec_type"` // openapi3, swagger2, jsonschema
+	Version     string         `json:"version"`
+	Content     string         `gorm:"type:text;not null" json:"content"` // Raw JSON or YAML document
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name with schema
+func (APISpec) TableName() string {
+	return "flows.api_specs"
+}
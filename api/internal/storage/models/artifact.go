@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ArtifactKind describes what an artifact holds
+type ArtifactKind string
+
+const (
+	ArtifactKindOutput     ArtifactKind = "output"     // A step output value offloaded from the database
+	ArtifactKindScreenshot ArtifactKind = "screenshot" // A browser screenshot
+	ArtifactKindFile       ArtifactKind = "file"       // A file attached by an action or plugin, e.g. a HAR trace or log
+)
+
+// ArtifactRefKey marks a step output value that was moved to an artifact. The
+// value is replaced by a reference such as
+// {"$artifact": "<id>", "name": "body", "size": 1048576, "content_type": "application/json"}.
+const ArtifactRefKey = "$artifact"
+
+// Artifact is a file produced by an execution, kept in the artifact store
+// rather than in the database
+type Artifact struct {
+	ID              uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID     *uuid.UUID   `gorm:"type:uuid;index" json:"workspace_id,omitempty"`
+	ExecutionID     uuid.UUID    `gorm:"type:uuid;not null;index" json:"execution_id"`
+	ExecutionStepID *uuid.UUID   `gorm:"type:uuid" json:"execution_step_id,omitempty"`
+	StepID          string       `json:"step_id,omitempty"`
+	Kind            ArtifactKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Name            string       `gorm:"not null" json:"name"`
+	ContentType     string       `json:"content_type"`
+	Size            int64        `json:"size"`
+	Checksum        string       `json:"checksum"`                // SHA-256 of the content, hex encoded
+	Backend         string       `gorm:"not null" json:"backend"` // "filesystem" or "s3"
+	StorageKey      string       `gorm:"not null" json:"-"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+// TableName specifies the table name with schema
+func (Artifact) TableName() string {
+	return "executions.artifacts"
+}
+
+// BeforeCreate generates UUID if not set
+func (a *Artifact) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ArtifactRef returns the reference that replaces an offloaded output value
+func (a *Artifact) ArtifactRef() map[string]interface{} {
+	return map[string]interface{}{
+		ArtifactRefKey: a.ID.String(),
+		"name":         a.Name,
+		"size":         a.Size,
+		"content_type": a.ContentType,
+	}
+}
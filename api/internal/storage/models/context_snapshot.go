@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContextSnapshot is the execution context recorded before a step, for
+// executions run with record_context. Executions can be restarted from the
+// snapshot of a top-level step.
+type ContextSnapshot struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ExecutionID  uuid.UUID         `gorm:"type:uuid;not null;index" json:"execution_id"`
+	Sequence     int               `gorm:"not null" json:"sequence"` // Order in which the steps started
+	Phase        string            `gorm:"type:varchar(20);not null" json:"phase"`
+	StepID       string            `gorm:"not null" json:"step_id"`
+	StepName     string            `json:"step_name,omitempty"`
+	Action       string            `json:"action"`
+	StepIndex    int               `json:"step_index"`               // Position in the phase, or in the branch for nested steps
+	ParentStepID string            `json:"parent_step_id,omitempty"` // Parallel or run_flow step of a nested step
+	Variables    SnapshotVariables `gorm:"type:jsonb" json:"variables"`
+	StepOutputs  SnapshotOutputs   `gorm:"type:jsonb" json:"step_outputs"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// TableName specifies the table name with schema
+func (ContextSnapshot) TableName() string {
+	return "executions.context_snapshots"
+}
+
+// BeforeCreate generates UUID if not set
+func (s *ContextSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SnapshotVariables holds the variables of a context snapshot
+type SnapshotVariables map[string]string
+
+// Scan implements sql.Scanner interface for JSONB
+func (v *SnapshotVariables) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Value implements driver.Valuer interface for JSONB
+func (v SnapshotVariables) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+// SnapshotOutputs holds the step outputs of a context snapshot by step ID
+type SnapshotOutputs map[string]map[string]interface{}
+
+// Scan implements sql.Scanner interface for JSONB
+func (o *SnapshotOutputs) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, o)
+}
+
+// Value implements driver.Valuer interface for JSONB
+func (o SnapshotOutputs) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
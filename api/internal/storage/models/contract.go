@@ -124,6 +124,7 @@ type HTTPResponse struct {
 	Status  int                    `json:"status"`
 	Headers map[string]interface{} `json:"headers,omitempty"`
 	Body    interface{}            `json:"body,omitempty"`
+	Schema  map[string]interface{} `json:"schema,omitempty"` // Optional JSON Schema the provider body must satisfy
 }
 
 // Scan implements sql.Scanner interface for JSONB
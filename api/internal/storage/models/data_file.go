@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataFile is a CSV or JSON table of test data stored in a workspace. Data
+// sources of collection runs read their rows from it by name or ID.
+type DataFile struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;index;not null" json:"workspace_id"`
+	Name        string         `gorm:"not null;index" json:"name"`
+	Description string         `json:"description"`
+	Format      string         `gorm:"type:varchar(10);not null" json:"format"` // "csv" or "json"
+	Content     string         `gorm:"type:text" json:"content,omitempty"`
+	RowCount    int            `json:"row_count"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (DataFile) TableName() string {
+	return "flows.data_files"
+}
@@ -21,20 +21,27 @@ const (
 
 // Execution represents a flow execution record
 type Execution struct {
-	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	FlowID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"flow_id"`
-	Flow        *Flow           `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
-	Status      ExecutionStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
-	Environment string          `gorm:"default:'default'" json:"environment"`
-	StartedAt   *time.Time      `json:"started_at"`
-	FinishedAt  *time.Time      `json:"finished_at"`
-	DurationMs  int64           `json:"duration_ms"`
-	TotalSteps  int             `json:"total_steps"`
-	PassedSteps int             `json:"passed_steps"`
-	FailedSteps int             `json:"failed_steps"`
-	Error       string          `json:"error,omitempty"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FlowID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"flow_id"`
+	Flow          *Flow           `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
+	Status        ExecutionStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Environment   string          `gorm:"default:'default'" json:"environment"`
+	StartedAt     *time.Time      `json:"started_at"`
+	FinishedAt    *time.Time      `json:"finished_at"`
+	DurationMs    int64           `json:"duration_ms"`
+	TotalSteps    int             `json:"total_steps"`
+	PassedSteps   int             `json:"passed_steps"`
+	FailedSteps   int             `json:"failed_steps"`
+	Error         string          `json:"error,omitempty"`
+	ScheduleRunID *uuid.UUID      `gorm:"type:uuid;index" json:"schedule_run_id,omitempty"`
+	Attempt       int             `gorm:"default:1" json:"attempt"`                    // 1 for the first run, higher for reruns on failure
+	RetryOf       *uuid.UUID      `gorm:"type:uuid;index" json:"retry_of,omitempty"`   // First attempt of a rerun
+	Quarantined   bool            `gorm:"default:false" json:"quarantined"`            // Failed only in quarantined flows or steps
+	RecordContext bool            `gorm:"default:false" json:"record_context"`         // Record the context before each step
+	RestartOf     *uuid.UUID      `gorm:"type:uuid;index" json:"restart_of,omitempty"` // Execution this one restarted from a step
+	RestartFrom   string          `json:"restart_from,omitempty"`                      // Step the restart began at
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
 // TableName specifies the table name with schema
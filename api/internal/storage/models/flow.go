@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
@@ -23,6 +24,8 @@ type Flow struct {
 	Environment  string         `gorm:"default:'default'" json:"environment"`
 	CollectionID *uuid.UUID     `gorm:"type:uuid;index" json:"collection_id,omitempty"` // Optional collection membership
 	SortOrder    int            `gorm:"default:0" json:"sort_order"`                    // Order within collection
+	AdHoc        bool           `gorm:"default:false" json:"ad_hoc,omitempty"`          // Sent with an execution; hidden from flow listings
+	ContentHash  string         `json:"-"`                                              // SHA-256 of the YAML of an ad-hoc flow, to reuse it
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -115,6 +118,8 @@ type Step struct {
 	Description string                 `json:"description" yaml:"description"`
 	Config      map[string]interface{} `json:"config" yaml:"config"`
 	Assert      []string               `json:"assert" yaml:"assert"`
+	Schema      SchemaAssertions       `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Snapshot    *SnapshotConfig        `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
 	Output      map[string]string      `json:"output" yaml:"output"`
 	Retry       *RetryConfig           `json:"retry,omitempty" yaml:"retry,omitempty"`
 	Timeout     string                 `json:"timeout,omitempty" yaml:"timeout,omitempty"`
@@ -127,6 +132,169 @@ type RetryConfig struct {
 	Backoff     string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
 }
 
+// JoinStrategy controls when a parallel step completes
+type JoinStrategy string
+
+const (
+	JoinAll      JoinStrategy = "all"       // Wait for every branch; fail if any branch failed
+	JoinAny      JoinStrategy = "any"       // Complete on the first passing branch and cancel the rest
+	JoinFailFast JoinStrategy = "fail_fast" // Wait for every branch, cancelling the rest on the first failure
+)
+
+// ParallelConfig is the config of a `parallel` step
+type ParallelConfig struct {
+	Branches      []ParallelBranch `json:"branches,omitempty" yaml:"branches,omitempty"`
+	Steps         []Step           `json:"steps,omitempty" yaml:"steps,omitempty"` // Shorthand: one branch per step
+	Join          JoinStrategy     `json:"join,omitempty" yaml:"join,omitempty"`
+	MaxConcurrent int              `json:"max_concurrent,omitempty" yaml:"max_concurrent,omitempty"` // 0 = all branches at once
+	WaitForAll    *bool            `json:"wait_for_all,omitempty" yaml:"wait_for_all,omitempty"`     // false = join any
+	FailFast      bool             `json:"fail_fast,omitempty" yaml:"fail_fast,omitempty"`           // true = join fail_fast
+}
+
+// ParallelBranch is a sequence of steps run concurrently with the other branches
+type ParallelBranch struct {
+	Name  string `json:"name" yaml:"name"`
+	Steps []Step `json:"steps" yaml:"steps"`
+}
+
+// ParseParallelConfig decodes and normalizes a parallel step config: shorthand
+// steps become single-step branches, the boolean options become a join strategy,
+// and unnamed branches get positional names
+func ParseParallelConfig(config map[string]interface{}) (*ParallelConfig, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var pc ParallelConfig
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("invalid parallel config: %w", err)
+	}
+
+	for i, step := range pc.Steps {
+		name := step.ID
+		if name == "" {
+			name = fmt.Sprintf("branch_%d", i)
+		}
+		pc.Branches = append(pc.Branches, ParallelBranch{Name: name, Steps: []Step{step}})
+	}
+	pc.Steps = nil
+
+	if len(pc.Branches) == 0 {
+		return nil, fmt.Errorf("parallel requires at least one branch")
+	}
+
+	names := make(map[string]bool)
+	for i := range pc.Branches {
+		branch := &pc.Branches[i]
+		if branch.Name == "" {
+			branch.Name = fmt.Sprintf("branch_%d", i)
+		}
+		if names[branch.Name] {
+			return nil, fmt.Errorf("duplicate parallel branch name %q", branch.Name)
+		}
+		names[branch.Name] = true
+
+		if len(branch.Steps) == 0 {
+			return nil, fmt.Errorf("parallel branch %q has no steps", branch.Name)
+		}
+	}
+
+	if pc.Join == "" {
+		switch {
+		case pc.FailFast:
+			pc.Join = JoinFailFast
+		case pc.WaitForAll != nil && !*pc.WaitForAll:
+			pc.Join = JoinAny
+		default:
+			pc.Join = JoinAll
+		}
+	}
+	switch pc.Join {
+	case JoinAll, JoinAny, JoinFailFast:
+	default:
+		return nil, fmt.Errorf("unknown join strategy %q (expected all, any or fail_fast)", pc.Join)
+	}
+
+	if pc.MaxConcurrent < 0 {
+		return nil, fmt.Errorf("max_concurrent must not be negative")
+	}
+
+	return &pc, nil
+}
+
+// SchemaAssertion validates a step output value against a JSON Schema.
+// Exactly one schema source must be set.
+type SchemaAssertion struct {
+	Path            string                 `json:"path,omitempty" yaml:"path,omitempty"`                           // Output path to validate (default: body)
+	Schema          map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`                       // Inline JSON Schema
+	File            string                 `json:"file,omitempty" yaml:"file,omitempty"`                           // JSON/YAML schema file, optionally with a #/pointer
+	Ref             string                 `json:"ref,omitempty" yaml:"ref,omitempty"`                             // "<api spec name>#/components/schemas/Pet"
+	FromPreviousRun bool                   `json:"from_previous_run,omitempty" yaml:"from_previous_run,omitempty"` // Shape of the last passing run of this step
+}
+
+// Sources returns how many schema sources are configured
+func (sa SchemaAssertion) Sources() int {
+	count := 0
+	if sa.Schema != nil {
+		count++
+	}
+	if sa.File != "" {
+		count++
+	}
+	if sa.Ref != "" {
+		count++
+	}
+	if sa.FromPreviousRun {
+		count++
+	}
+	return count
+}
+
+// SchemaAssertions accepts either a single schema assertion or a list of them
+type SchemaAssertions []SchemaAssertion
+
+// UnmarshalJSON implements json.Unmarshaler
+func (sa *SchemaAssertions) UnmarshalJSON(data []byte) error {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		*sa = nil
+		return nil
+	}
+	if strings.HasPrefix(trimmed, "{") {
+		var single SchemaAssertion
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*sa = SchemaAssertions{single}
+		return nil
+	}
+	var list []SchemaAssertion
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*sa = list
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (sa *SchemaAssertions) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var single SchemaAssertion
+		if err := node.Decode(&single); err != nil {
+			return err
+		}
+		*sa = SchemaAssertions{single}
+		return nil
+	}
+	var list []SchemaAssertion
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*sa = list
+	return nil
+}
+
 // Scan implements sql.Scanner interface for JSONB
 func (fd *FlowDefinition) Scan(value interface{}) error {
 	bytes, ok := value.([]byte)
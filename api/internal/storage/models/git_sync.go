@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GitSyncStatus represents the sync state of a git binding
+type GitSyncStatus string
+
+const (
+	GitSyncStatusIdle     GitSyncStatus = "idle"
+	GitSyncStatusSyncing  GitSyncStatus = "syncing"
+	GitSyncStatusConflict GitSyncStatus = "conflict"
+	GitSyncStatusError    GitSyncStatus = "error"
+)
+
+// GitSyncBinding binds a collection to a directory of flow YAML files in a git
+// repository. Files are pulled into the collection, and flows edited in
+// TestMesh are committed back.
+type GitSyncBinding struct {
+	ID                uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	CollectionID      uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"collection_id"`
+	IntegrationID     *uuid.UUID    `gorm:"type:uuid" json:"integration_id,omitempty"` // Git integration for credentials and pull requests
+	RepositoryURL     string        `gorm:"not null" json:"repository_url"`            // Clone URL
+	Repository        string        `gorm:"index" json:"repository"`                   // "owner/repo" as named by webhooks
+	Branch            string        `gorm:"not null;default:'main'" json:"branch"`
+	Path              string        `json:"path"`        // Directory of flow files, relative to the repository root
+	PushBranch        string        `json:"push_branch"` // Branch edits are committed to; empty commits to Branch
+	CreatePullRequest bool          `gorm:"default:false" json:"create_pull_request"`
+	SyncInterval      string        `json:"sync_interval,omitempty"` // e.g. "5m"; empty syncs on webhook and on demand only
+	Enabled           bool          `gorm:"default:true" json:"enabled"`
+	Status            GitSyncStatus `gorm:"type:varchar(20);not null;default:'idle'" json:"status"`
+	LastCommit        string        `json:"last_commit,omitempty"`
+	LastSyncedAt      *time.Time    `json:"last_synced_at,omitempty"`
+	LastError         string        `json:"last_error,omitempty"`
+	PullRequestURL    string        `json:"pull_request_url,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+
+	// Relations (not always loaded)
+	Collection *Collection   `gorm:"foreignKey:CollectionID" json:"collection,omitempty"`
+	Files      []GitSyncFile `gorm:"foreignKey:BindingID" json:"files,omitempty"`
+}
+
+// TableName specifies the table name with schema
+func (GitSyncBinding) TableName() string {
+	return "flows.git_sync_bindings"
+}
+
+// GitSyncFile records the state of a flow file at its last sync. A file
+// changed in git since ContentHash and a flow with versions after FlowVersion
+// were both changed, which is a conflict.
+type GitSyncFile struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BindingID   uuid.UUID `gorm:"type:uuid;not null;index" json:"binding_id"`
+	FlowID      uuid.UUID `gorm:"type:uuid;not null;index" json:"flow_id"`
+	FilePath    string    `gorm:"not null" json:"file_path"` // Relative to the binding path
+	ContentHash string    `json:"content_hash"`              // Git object hash of the file on the branch, empty if not there yet
+	FlowVersion int       `json:"flow_version"`              // Flow version the file matches
+	CommitSHA   string    `json:"commit_sha,omitempty"`
+	Conflict    string    `json:"conflict,omitempty"` // Why the file is in conflict, empty otherwise
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name with schema
+func (GitSyncFile) TableName() string {
+	return "flows.git_sync_files"
+}
@@ -25,7 +25,10 @@ const (
 	IntegrationProviderLocal     IntegrationProvider = "local"
 
 	// Git Providers
-	IntegrationProviderGitHub IntegrationProvider = "github"
+	IntegrationProviderGitHub    IntegrationProvider = "github"
+	IntegrationProviderGitLab    IntegrationProvider = "gitlab"
+	IntegrationProviderBitbucket IntegrationProvider = "bitbucket"
+	IntegrationProviderGeneric   IntegrationProvider = "generic" // Signed webhook from any CI
 )
 
 // IntegrationStatus represents the status of an integration
@@ -67,6 +70,11 @@ type IntegrationConfig struct {
 
 	// GitHub config
 	SignatureHeader string `json:"signature_header,omitempty"` // "X-Hub-Signature-256"
+
+	// Git provider config
+	APIURL        string `json:"api_url,omitempty"`        // Provider API base URL, or the callback URL for generic
+	ReportStatus  bool   `json:"report_status,omitempty"`  // Report results back as commit statuses / check runs
+	StatusContext string `json:"status_context,omitempty"` // Status name shown on the commit, default "testmesh"
 }
 
 // BeforeCreate generates UUID if not set
@@ -91,6 +91,7 @@ type ResponseConfig struct {
 	DelayMs     int                    `json:"delay_ms,omitempty"`     // Response delay
 	Template    bool                   `json:"template,omitempty"`     // Use templating
 	TemplateVars map[string]interface{} `json:"template_vars,omitempty"` // Template variables
+	Schema      map[string]interface{} `json:"schema,omitempty"`       // JSON Schema the served body must satisfy
 }
 
 // Scan implements sql.Scanner interface for JSONB
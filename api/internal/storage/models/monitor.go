@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MonitorStatus represents whether a monitor is checking
+type MonitorStatus string
+
+const (
+	MonitorStatusActive MonitorStatus = "active"
+	MonitorStatusPaused MonitorStatus = "paused"
+)
+
+// AlertState is the state of a monitor's alert
+type AlertState string
+
+const (
+	AlertStateOK       AlertState = "ok"       // All SLOs are met
+	AlertStatePending  AlertState = "pending"  // An SLO is breached, waiting for pending_for to pass
+	AlertStateFiring   AlertState = "firing"   // An SLO has been breached for pending_for; notified
+	AlertStateResolved AlertState = "resolved" // The SLOs are met again after firing; notified
+)
+
+// SLOType selects what an SLO measures
+type SLOType string
+
+const (
+	SLOTypeAvailability SLOType = "availability" // Percentage of successful checks
+	SLOTypeLatency      SLOType = "latency"      // Latency percentile of the flow or one of its steps, in milliseconds
+)
+
+// SLO is a service level objective of a monitor over a rolling window
+type SLO struct {
+	Name       string  `json:"name"`
+	Type       SLOType `json:"type"`
+	Objective  float64 `json:"objective"`            // Minimum availability in percent, or maximum latency in milliseconds
+	Percentile float64 `json:"percentile,omitempty"` // Latency percentile, defaults to 95
+	StepID     string  `json:"step_id,omitempty"`    // Latency of this step instead of the whole flow
+	Window     string  `json:"window,omitempty"`     // Rolling window, e.g. "1h", "24h", "30d"; defaults to 24h
+}
+
+// Monitor runs a flow on a short interval as a synthetic check and alerts
+// when its SLOs are breached. Checks are fired by the scheduler through a
+// schedule owned by the monitor.
+type Monitor struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Name        string        `gorm:"not null" json:"name"`
+	Description string        `json:"description,omitempty"`
+	FlowID      uuid.UUID     `gorm:"type:uuid;not null;index" json:"flow_id"`
+	Flow        *Flow         `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
+	ScheduleID  uuid.UUID     `gorm:"type:uuid;not null" json:"schedule_id"`
+	Status      MonitorStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+
+	// Checks
+	Interval    string                 `gorm:"not null" json:"interval"` // e.g. "30s", "5m"
+	Environment map[string]interface{} `gorm:"type:jsonb;serializer:json;default:'{}'" json:"environment,omitempty"`
+
+	// SLOs and alerting
+	SLOs             []SLO  `gorm:"column:slos;type:jsonb;serializer:json" json:"slos"`
+	PendingFor       string `json:"pending_for,omitempty"`        // How long an SLO must stay breached before the alert fires
+	RepeatInterval   string `json:"repeat_interval,omitempty"`    // Notify again while firing this often; empty notifies once
+	NotifyWebhookURL string `json:"notify_webhook_url,omitempty"` // Slack-compatible webhook for alert notifications
+	Public           bool   `gorm:"default:false" json:"public"`  // Shown on the workspace status page
+
+	// State
+	AlertState      AlertState `gorm:"type:varchar(20);not null;default:'ok'" json:"alert_state"`
+	AlertSince      *time.Time `json:"alert_since,omitempty"`
+	LastCheckAt     *time.Time `json:"last_check_at,omitempty"`
+	LastCheckResult string     `json:"last_check_result,omitempty"` // "success" or "failure"
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate generates UUID if not set
+func (m *Monitor) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// MonitorCheck is the outcome of one check of a monitor. The flow execution
+// of the check is recorded like any other scheduled execution.
+type MonitorCheck struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MonitorID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"monitor_id"`
+	ScheduleRunID *uuid.UUID `gorm:"type:uuid" json:"schedule_run_id,omitempty"`
+	ExecutionID   *uuid.UUID `gorm:"type:uuid" json:"execution_id,omitempty"`
+	Location      string     `json:"location"`
+	Result        string     `gorm:"not null" json:"result"` // "success" or "failure"
+	Error         string     `json:"error,omitempty"`
+	DurationMs    int64      `json:"duration_ms"`
+	CheckedAt     time.Time  `gorm:"not null;index" json:"checked_at"`
+}
+
+// BeforeCreate generates UUID if not set
+func (mc *MonitorCheck) BeforeCreate(tx *gorm.DB) error {
+	if mc.ID == uuid.Nil {
+		mc.ID = uuid.New()
+	}
+	return nil
+}
+
+// MonitorAlert is one incident of a monitor, from the first breach until the
+// SLOs are met again. A monitor has at most one open (pending or firing) alert.
+type MonitorAlert struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MonitorID      uuid.UUID   `gorm:"type:uuid;not null;index" json:"monitor_id"`
+	State          AlertState  `gorm:"type:varchar(20);not null" json:"state"` // "pending", "firing" or "resolved"
+	Reason         string      `json:"reason"`
+	Breaches       StringArray `gorm:"type:text[]" json:"breaches,omitempty"` // Names of the breached SLOs
+	PendingAt      time.Time   `gorm:"not null" json:"pending_at"`
+	FiredAt        *time.Time  `json:"fired_at,omitempty"`
+	ResolvedAt     *time.Time  `json:"resolved_at,omitempty"`
+	LastNotifiedAt *time.Time  `json:"last_notified_at,omitempty"`
+	Notifications  int         `gorm:"default:0" json:"notifications"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// BeforeCreate generates UUID if not set
+func (ma *MonitorAlert) BeforeCreate(tx *gorm.DB) error {
+	if ma.ID == uuid.Nil {
+		ma.ID = uuid.New()
+	}
+	return nil
+}
+
+// SLOStatus is the evaluation of an SLO over its window
+type SLOStatus struct {
+	SLO
+	Actual  *float64 `json:"actual"` // Nil when the window has no data
+	Samples int64    `json:"samples"`
+	Met     bool     `json:"met"`
+	// ErrorBudget is the share of allowed failures left in the window, for availability SLOs
+	ErrorBudget *float64 `json:"error_budget,omitempty"`
+}
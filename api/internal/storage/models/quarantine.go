@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuarantineStatus represents whether a quarantine is in effect
+type QuarantineStatus string
+
+const (
+	QuarantineStatusActive   QuarantineStatus = "active"
+	QuarantineStatusReleased QuarantineStatus = "released"
+)
+
+// QuarantineSource records who quarantined a flow or step
+type QuarantineSource string
+
+const (
+	QuarantineSourceAuto   QuarantineSource = "auto"
+	QuarantineSourceManual QuarantineSource = "manual"
+)
+
+// Quarantine marks a flow, or a single step of it, as flaky. Quarantined
+// failures still run and are recorded but do not fail schedules, CI runs or
+// the CLI exit code.
+type Quarantine struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	FlowID         uuid.UUID        `gorm:"type:uuid;not null;index" json:"flow_id"`
+	StepID         string           `gorm:"not null;default:''" json:"step_id,omitempty"` // Empty quarantines the whole flow
+	Status         QuarantineStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	Source         QuarantineSource `gorm:"type:varchar(20);not null;default:'manual'" json:"source"`
+	Reason         string           `json:"reason,omitempty"`
+	FlakinessScore float64          `gorm:"type:decimal(5,4)" json:"flakiness_score"`
+	CleanRuns      int              `json:"clean_runs"` // Consecutive clean runs since quarantined
+	QuarantinedBy  *uuid.UUID       `gorm:"type:uuid" json:"quarantined_by,omitempty"`
+	ReleasedAt     *time.Time       `json:"released_at,omitempty"`
+	ReleaseReason  string           `json:"release_reason,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+
+	// Relations (not always loaded)
+	Flow *Flow `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
+}
+
+// TableName specifies the table name with schema
+func (Quarantine) TableName() string {
+	return "reporting.quarantines"
+}
@@ -134,13 +134,18 @@ const (
 	ReportStatusFailed    ReportStatus = "failed"
 )
 
-// ReportFormat represents the format of a report
+// ReportFormat represents the format of a report. Besides these, reports can
+// be generated in the formats of reporter plugins.
 type ReportFormat string
 
 const (
-	ReportFormatHTML  ReportFormat = "html"
-	ReportFormatJSON  ReportFormat = "json"
-	ReportFormatJUnit ReportFormat = "junit"
+	ReportFormatHTML   ReportFormat = "html"
+	ReportFormatJSON   ReportFormat = "json"
+	ReportFormatJUnit  ReportFormat = "junit"
+	ReportFormatAllure ReportFormat = "allure"
+	ReportFormatCTRF   ReportFormat = "ctrf"
+	ReportFormatTAP    ReportFormat = "tap"
+	ReportFormatPDF    ReportFormat = "pdf"
 )
 
 // Report stores generated reports
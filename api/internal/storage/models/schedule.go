@@ -16,17 +16,49 @@ const (
 	ScheduleStatusDisabled ScheduleStatus = "disabled"
 )
 
+// ScheduleTargetType selects which flows a schedule runs
+type ScheduleTargetType string
+
+const (
+	ScheduleTargetFlow       ScheduleTargetType = "flow"       // A single flow (flow_id)
+	ScheduleTargetCollection ScheduleTargetType = "collection" // A collection and its nested collections (collection_id)
+	ScheduleTargetSuite      ScheduleTargetType = "suite"      // Every flow of a suite (suite)
+	ScheduleTargetTags       ScheduleTargetType = "tags"       // Flows matching a tag query (tag_query)
+	ScheduleTargetFlows      ScheduleTargetType = "flows"      // A saved list of flows (flow_ids)
+)
+
+// MisfirePolicy decides what happens to occurrences missed while no scheduler was running
+type MisfirePolicy string
+
+const (
+	MisfireSkip    MisfirePolicy = "skip"     // Drop missed occurrences and wait for the next one
+	MisfireRunOnce MisfirePolicy = "run_once" // Run once for all missed occurrences
+	MisfireRunAll  MisfirePolicy = "run_all"  // Run every missed occurrence
+)
+
 // Schedule represents a scheduled test execution
 type Schedule struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string         `gorm:"not null" json:"name"`
 	Description string         `json:"description,omitempty"`
-	FlowID      uuid.UUID      `gorm:"type:uuid;index" json:"flow_id"`
-	Flow        *Flow          `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
 	CronExpr    string         `gorm:"not null" json:"cron_expr"`
 	Timezone    string         `gorm:"default:'UTC'" json:"timezone"`
 	Status      ScheduleStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
 
+	// Target
+	TargetType   ScheduleTargetType `gorm:"type:varchar(20);not null;default:'flow'" json:"target_type"`
+	WorkspaceID  *uuid.UUID         `gorm:"type:uuid;index" json:"workspace_id,omitempty"`
+	FlowID       *uuid.UUID         `gorm:"type:uuid;index" json:"flow_id,omitempty"`
+	Flow         *Flow              `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
+	CollectionID *uuid.UUID         `gorm:"type:uuid" json:"collection_id,omitempty"`
+	Suite        string             `json:"suite,omitempty"`
+	TagQuery     string             `json:"tag_query,omitempty"` // e.g. "critical AND NOT slow"
+	FlowIDs      StringArray        `gorm:"type:text[]" json:"flow_ids,omitempty"`
+
+	// Group execution
+	Concurrency int  `gorm:"default:1" json:"concurrency"`   // Flows run at once
+	FailFast    bool `gorm:"default:false" json:"fail_fast"` // Skip remaining flows after the first failure
+
 	// Execution settings
 	Environment     map[string]interface{} `gorm:"type:jsonb;serializer:json;default:'{}'" json:"environment,omitempty"`
 	NotifyOnFailure bool        `gorm:"default:false" json:"notify_on_failure"`
@@ -35,6 +67,10 @@ type Schedule struct {
 	MaxRetries      int                    `gorm:"default:0" json:"max_retries"`
 	RetryDelay      string                 `gorm:"default:'1m'" json:"retry_delay"`
 
+	// Firing behaviour
+	MisfirePolicy MisfirePolicy `gorm:"type:varchar(20);default:'run_once'" json:"misfire_policy"`
+	Jitter        string        `json:"jitter,omitempty"` // Random delay up to this duration before each run, e.g. "30s"
+
 	// Overlap prevention
 	AllowOverlap bool `gorm:"default:false" json:"allow_overlap"`
 
@@ -59,7 +95,8 @@ func (s *Schedule) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// ScheduleRun represents a single execution of a schedule
+// ScheduleRun represents a single run of a schedule. A run is the parent of
+// the executions of every targeted flow and aggregates their results.
 type ScheduleRun struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	ScheduleID  uuid.UUID  `gorm:"type:uuid;index;not null" json:"schedule_id"`
@@ -70,15 +107,37 @@ type ScheduleRun struct {
 	Error       string     `json:"error,omitempty"`
 	RetryCount  int        `gorm:"default:0" json:"retry_count"`
 
+	// Aggregated flow results
+	TotalFlows       int               `gorm:"default:0" json:"total_flows"`
+	PassedFlows      int               `gorm:"default:0" json:"passed_flows"`
+	FailedFlows      int               `gorm:"default:0" json:"failed_flows"`
+	SkippedFlows     int               `gorm:"default:0" json:"skipped_flows"`
+	QuarantinedFlows int               `gorm:"default:0" json:"quarantined_flows"` // Failed in quarantine, not counted as failures
+	FlowResults      []ScheduleRunFlow `gorm:"type:jsonb;serializer:json" json:"flow_results,omitempty"`
+	Executions       []Execution       `gorm:"foreignKey:ScheduleRunID" json:"executions,omitempty"`
+
 	// Timing
 	ScheduledAt time.Time  `gorm:"not null" json:"scheduled_at"`
 	StartedAt   *time.Time `json:"started_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"` // Renewed while a replica runs the run
 	Duration    int64      `json:"duration_ms,omitempty"`
 
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
+// ScheduleRunFlow is the outcome of one flow in a schedule run
+type ScheduleRunFlow struct {
+	FlowID      uuid.UUID  `json:"flow_id"`
+	FlowName    string     `json:"flow_name"`
+	Suite       string     `json:"suite,omitempty"`
+	ExecutionID *uuid.UUID `json:"execution_id,omitempty"` // Latest attempt
+	Status      string     `json:"status"`                 // "passed", "failed", "skipped", "quarantined"
+	Attempts    int        `json:"attempts"`
+	DurationMs  int64      `json:"duration_ms"`
+	Error       string     `json:"error,omitempty"`
+}
+
 // BeforeCreate generates UUID if not set
 func (sr *ScheduleRun) BeforeCreate(tx *gorm.DB) error {
 	if sr.ID == uuid.Nil {
@@ -87,6 +146,19 @@ func (sr *ScheduleRun) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// SchedulerLease is a time-limited lock held by one API replica; the holder
+// of the scheduler lease is the only replica that fires schedules
+type SchedulerLease struct {
+	Name      string    `gorm:"primary_key" json:"name"`
+	Holder    string    `gorm:"not null" json:"holder"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
+
+// TableName specifies the table name
+func (SchedulerLease) TableName() string {
+	return "scheduler_leases"
+}
+
 // ScheduleListParams defines parameters for listing schedules
 type ScheduleListParams struct {
 	Status   ScheduleStatus
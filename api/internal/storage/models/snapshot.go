@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// SnapshotStatus represents the review state of a snapshot
+type SnapshotStatus string
+
+const (
+	SnapshotStatusAccepted SnapshotStatus = "accepted" // Golden record matches the last run
+	SnapshotStatusPending  SnapshotStatus = "pending"  // A run produced a different output awaiting review
+)
+
+// SnapshotConfig enables snapshot assertions on a step
+type SnapshotConfig struct {
+	Name     string            `json:"name,omitempty" yaml:"name,omitempty"`         // Snapshot name (default: step id)
+	Include  []string          `json:"include,omitempty" yaml:"include,omitempty"`   // Output paths to record (default: body)
+	Ignore   []string          `json:"ignore,omitempty" yaml:"ignore,omitempty"`     // Paths dropped before comparing, e.g. body.items[*].created_at
+	Matchers map[string]string `json:"matchers,omitempty" yaml:"matchers,omitempty"` // Path -> matcher, e.g. "body.id": "any uuid"
+}
+
+// snapshotConfigFields avoids recursion when decoding SnapshotConfig
+type snapshotConfigFields SnapshotConfig
+
+// UnmarshalJSON implements json.Unmarshaler, accepting `true` as shorthand for defaults
+func (sc *SnapshotConfig) UnmarshalJSON(data []byte) error {
+	if string(data) == "true" {
+		*sc = SnapshotConfig{}
+		return nil
+	}
+	return json.Unmarshal(data, (*snapshotConfigFields)(sc))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting `true` as shorthand for defaults
+func (sc *SnapshotConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf("snapshot: only `true` is accepted as shorthand; remove the key to disable")
+		}
+		*sc = SnapshotConfig{}
+		return nil
+	}
+	return node.Decode((*snapshotConfigFields)(sc))
+}
+
+// Snapshot is the golden record of a step's normalized output, attached to a flow
+type Snapshot struct {
+	ID                 uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FlowID             uuid.UUID       `gorm:"type:uuid;not null;index" json:"flow_id"`
+	Flow               *Flow           `gorm:"foreignKey:FlowID" json:"flow,omitempty"`
+	StepID             string          `gorm:"not null" json:"step_id"`
+	Name               string          `gorm:"not null" json:"name"`
+	Status             SnapshotStatus  `gorm:"type:varchar(20);not null;default:'accepted'" json:"status"`
+	Golden             JSONMap         `gorm:"type:jsonb;not null" json:"golden"`
+	Pending            JSONMap         `gorm:"type:jsonb" json:"pending,omitempty"`
+	PendingChanges     SnapshotChanges `gorm:"type:jsonb" json:"pending_changes,omitempty"`
+	PendingExecutionID *uuid.UUID      `gorm:"type:uuid" json:"pending_execution_id,omitempty"`
+	AcceptedAt         *time.Time      `json:"accepted_at,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name with schema
+func (Snapshot) TableName() string {
+	return "flows.snapshots"
+}
+
+// SnapshotChange is a single difference between a golden record and a new output.
+// It mirrors the BreakingChange layout produced by the contract differ.
+type SnapshotChange struct {
+	ChangeType  string                 `json:"change_type"` // added_field, removed_field, changed_value, changed_type, changed_length, matcher_mismatch
+	Severity    BreakingChangeSeverity `json:"severity"`
+	Description string                 `json:"description"`
+	Details     ChangeDetails          `json:"details"`
+}
+
+// SnapshotChanges is a list of snapshot changes stored as JSONB
+type SnapshotChanges []SnapshotChange
+
+// Scan implements sql.Scanner interface for JSONB
+func (sc *SnapshotChanges) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, sc)
+}
+
+// Value implements driver.Valuer interface for JSONB
+func (sc SnapshotChanges) Value() (driver.Value, error) {
+	if sc == nil {
+		return nil, nil
+	}
+	return json.Marshal(sc)
+}
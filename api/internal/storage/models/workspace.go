@@ -41,6 +41,22 @@ type WorkspaceSettings struct {
 	Variables          map[string]string `json:"variables,omitempty"`
 	AllowPublicSharing bool              `json:"allow_public_sharing,omitempty"`
 	RequireApproval    bool              `json:"require_approval,omitempty"`
+	Quarantine         QuarantinePolicy  `json:"quarantine,omitempty"`
+	Artifacts          ArtifactPolicy    `json:"artifacts,omitempty"`
+}
+
+// QuarantinePolicy configures flaky test quarantine and reruns of failed executions
+type QuarantinePolicy struct {
+	AutoQuarantine     bool    `json:"auto_quarantine,omitempty"`     // Quarantine flows and steps above the flakiness threshold
+	FlakinessThreshold float64 `json:"flakiness_threshold,omitempty"` // Defaults to 0.1
+	ReleaseAfter       int     `json:"release_after,omitempty"`       // Consecutive clean runs before release, defaults to 5
+	RetryOnFailure     int     `json:"retry_on_failure,omitempty"`    // Reruns of a failed execution
+	NotifyWebhookURL   string  `json:"notify_webhook_url,omitempty"`  // Slack-compatible webhook for quarantine changes
+}
+
+// ArtifactPolicy configures how long execution artifacts are kept
+type ArtifactPolicy struct {
+	RetentionDays int `json:"retention_days,omitempty"` // Defaults to the server's artifacts.retention_days
 }
 
 // Scan implements the sql.Scanner interface for WorkspaceSettings
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APISpecRepository handles API spec database operations
+type APISpecRepository struct {
+	db *gorm.DB
+}
+
+// NewAPISpecRepository creates a new API spec repository
+func NewAPISpecRepository(db *gorm.DB) *APISpecRepository {
+	return &APISpecRepository{db: db}
+}
+
+// Upsert creates the spec or replaces the content of the spec with the same name
+func (r *APISpecRepository) Upsert(spec *models.APISpec, workspaceID uuid.UUID) error {
+	spec.WorkspaceID = workspaceID
+
+	existing, err := r.GetByName(spec.Name, workspaceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return r.db.Create(spec).Error
+		}
+		return err
+	}
+
+	spec.ID = existing.ID
+	spec.CreatedAt = existing.CreatedAt
+	return r.db.Save(spec).Error
+}
+
+// GetByID retrieves a spec by ID, verifying workspace ownership
+func (r *APISpecRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.APISpec, error) {
+	var spec models.APISpec
+	if err := r.db.First(&spec, "id = ? AND workspace_id = ?", id, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// GetByName retrieves a spec by name within a workspace (case-insensitive)
+func (r *APISpecRepository) GetByName(name string, workspaceID uuid.UUID) (*models.APISpec, error) {
+	var spec models.APISpec
+	if err := r.db.First(&spec, "LOWER(name) = LOWER(?) AND workspace_id = ?", name, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// List retrieves all specs in a workspace without their content
+func (r *APISpecRepository) List(workspaceID uuid.UUID) ([]models.APISpec, error) {
+	var specs []models.APISpec
+	err := r.db.Select("id", "workspace_id", "name", "spec_type", "version", "created_at", "updated_at").
+		Where("workspace_id = ?", workspaceID).
+		Order("name ASC").
+		Find(&specs).Error
+	return specs, err
+}
+
+// Delete soft-deletes a spec, verifying workspace ownership
+func (r *APISpecRepository) Delete(id uuid.UUID, workspaceID uuid.UUID) error {
+	result := r.db.Where("id = ? AND workspace_id = ?", id, workspaceID).Delete(&models.APISpec{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ArtifactRepository handles execution artifact database operations
+type ArtifactRepository struct {
+	db *gorm.DB
+}
+
+// NewArtifactRepository creates a new artifact repository
+func NewArtifactRepository(db *gorm.DB) *ArtifactRepository {
+	return &ArtifactRepository{db: db}
+}
+
+// Create records a stored artifact
+func (r *ArtifactRepository) Create(artifact *models.Artifact) error {
+	return r.db.Create(artifact).Error
+}
+
+// GetByID retrieves an artifact by ID
+func (r *ArtifactRepository) GetByID(id uuid.UUID) (*models.Artifact, error) {
+	var artifact models.Artifact
+	if err := r.db.First(&artifact, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &artifact, nil
+}
+
+// ListByExecution retrieves the artifacts of an execution in the order they were stored
+func (r *ArtifactRepository) ListByExecution(executionID uuid.UUID) ([]models.Artifact, error) {
+	var artifacts []models.Artifact
+	err := r.db.Where("execution_id = ?", executionID).
+		Order("created_at ASC").
+		Find(&artifacts).Error
+	return artifacts, err
+}
+
+// ListByExecutions retrieves the artifacts of several executions, grouped by execution ID
+func (r *ArtifactRepository) ListByExecutions(executionIDs []uuid.UUID) (map[uuid.UUID][]models.Artifact, error) {
+	result := make(map[uuid.UUID][]models.Artifact)
+	if len(executionIDs) == 0 {
+		return result, nil
+	}
+
+	var artifacts []models.Artifact
+	err := r.db.Where("execution_id IN ?", executionIDs).
+		Order("created_at ASC").
+		Find(&artifacts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, artifact := range artifacts {
+		result[artifact.ExecutionID] = append(result[artifact.ExecutionID], artifact)
+	}
+	return result, nil
+}
+
+// WorkspaceIDs lists the workspaces that have artifacts. Artifacts without a
+// workspace are not included.
+func (r *ArtifactRepository) WorkspaceIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Artifact{}).
+		Where("workspace_id IS NOT NULL").
+		Distinct().
+		Pluck("workspace_id", &ids).Error
+	return ids, err
+}
+
+// ListExpired retrieves up to limit artifacts of a workspace created before a
+// time. A nil workspace selects the artifacts without a workspace.
+func (r *ArtifactRepository) ListExpired(workspaceID *uuid.UUID, before time.Time, limit int) ([]models.Artifact, error) {
+	query := r.db.Where("created_at < ?", before)
+	if workspaceID != nil {
+		query = query.Where("workspace_id = ?", *workspaceID)
+	} else {
+		query = query.Where("workspace_id IS NULL")
+	}
+
+	var artifacts []models.Artifact
+	err := query.Order("created_at ASC").Limit(limit).Find(&artifacts).Error
+	return artifacts, err
+}
+
+// ListOrphaned retrieves up to limit artifacts whose execution was deleted
+func (r *ArtifactRepository) ListOrphaned(limit int) ([]models.Artifact, error) {
+	var artifacts []models.Artifact
+	err := r.db.Where("NOT EXISTS (SELECT 1 FROM executions.executions e WHERE e.id = executions.artifacts.execution_id)").
+		Limit(limit).
+		Find(&artifacts).Error
+	return artifacts, err
+}
+
+// Delete deletes artifact records
+func (r *ArtifactRepository) Delete(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&models.Artifact{}).Error
+}
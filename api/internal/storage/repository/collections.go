@@ -31,6 +31,15 @@ func (r *CollectionRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*mo
 	return &collection, nil
 }
 
+// GetByName retrieves a collection by its exact name within a workspace
+func (r *CollectionRepository) GetByName(name string, workspaceID uuid.UUID) (*models.Collection, error) {
+	var collection models.Collection
+	if err := r.db.First(&collection, "name = ? AND workspace_id = ?", name, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
 // GetByIDWithFlows retrieves a collection with its flows, scoped to workspace
 func (r *CollectionRepository) GetByIDWithFlows(id uuid.UUID, workspaceID uuid.UUID) (*models.Collection, error) {
 	var collection models.Collection
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContextSnapshotRepository handles recorded execution context database operations
+type ContextSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewContextSnapshotRepository creates a new context snapshot repository
+func NewContextSnapshotRepository(db *gorm.DB) *ContextSnapshotRepository {
+	return &ContextSnapshotRepository{db: db}
+}
+
+// Create records a context snapshot
+func (r *ContextSnapshotRepository) Create(snapshot *models.ContextSnapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+// ListByExecution retrieves the snapshots of an execution in the order their steps started
+func (r *ContextSnapshotRepository) ListByExecution(executionID uuid.UUID) ([]models.ContextSnapshot, error) {
+	var snapshots []models.ContextSnapshot
+	err := r.db.Where("execution_id = ?", executionID).
+		Order("sequence ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetByStep retrieves the snapshot taken before the first run of a top-level step
+func (r *ContextSnapshotRepository) GetByStep(executionID uuid.UUID, stepID string) (*models.ContextSnapshot, error) {
+	var snapshot models.ContextSnapshot
+	err := r.db.Where("execution_id = ? AND step_id = ? AND (parent_step_id IS NULL OR parent_step_id = '')", executionID, stepID).
+		Order("sequence ASC").
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataFileRepository handles data file database operations
+type DataFileRepository struct {
+	db *gorm.DB
+}
+
+// NewDataFileRepository creates a new data file repository
+func NewDataFileRepository(db *gorm.DB) *DataFileRepository {
+	return &DataFileRepository{db: db}
+}
+
+// Create creates a new data file in the specified workspace
+func (r *DataFileRepository) Create(file *models.DataFile, workspaceID uuid.UUID) error {
+	file.WorkspaceID = workspaceID
+	return r.db.Create(file).Error
+}
+
+// GetByID retrieves a data file by ID, verifying workspace ownership
+func (r *DataFileRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.DataFile, error) {
+	var file models.DataFile
+	if err := r.db.First(&file, "id = ? AND workspace_id = ?", id, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetByName retrieves a data file by name within a workspace (case-insensitive)
+func (r *DataFileRepository) GetByName(name string, workspaceID uuid.UUID) (*models.DataFile, error) {
+	var file models.DataFile
+	if err := r.db.First(&file, "LOWER(name) = LOWER(?) AND workspace_id = ?", name, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Update updates a data file, verifying workspace ownership
+func (r *DataFileRepository) Update(file *models.DataFile, workspaceID uuid.UUID) error {
+	if _, err := r.GetByID(file.ID, workspaceID); err != nil {
+		return err
+	}
+	// Ensure workspace_id cannot be changed
+	file.WorkspaceID = workspaceID
+	return r.db.Save(file).Error
+}
+
+// Delete soft-deletes a data file, verifying workspace ownership
+func (r *DataFileRepository) Delete(id uuid.UUID, workspaceID uuid.UUID) error {
+	result := r.db.Where("id = ? AND workspace_id = ?", id, workspaceID).Delete(&models.DataFile{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List retrieves the data files of a workspace by name, without their content
+func (r *DataFileRepository) List(workspaceID uuid.UUID, search string) ([]*models.DataFile, error) {
+	var files []*models.DataFile
+	query := r.db.Model(&models.DataFile{}).
+		Omit("content").
+		Where("workspace_id = ?", workspaceID)
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+	if err := query.Order("name ASC").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -63,6 +63,32 @@ func (r *ExecutionRepository) Update(execution *models.Execution) error {
 	return r.db.Save(execution).Error
 }
 
+// GetLatestRetry retrieves the latest rerun of a failed execution
+func (r *ExecutionRepository) GetLatestRetry(executionID uuid.UUID) (*models.Execution, error) {
+	var execution models.Execution
+	err := r.db.Where("retry_of = ?", executionID).Order("attempt DESC").First(&execution).Error
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// GetPrevious retrieves the latest finished execution of a flow created before
+// the given execution, skipping reruns
+func (r *ExecutionRepository) GetPrevious(execution *models.Execution) (*models.Execution, error) {
+	var previous models.Execution
+	err := r.db.Preload("Flow").
+		Where("flow_id = ? AND id != ? AND created_at < ? AND retry_of IS NULL AND status IN ?",
+			execution.FlowID, execution.ID, execution.CreatedAt,
+			[]models.ExecutionStatus{models.ExecutionStatusCompleted, models.ExecutionStatusFailed}).
+		Order("created_at DESC").
+		First(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
 // CreateStep creates a new execution step
 func (r *ExecutionRepository) CreateStep(step *models.ExecutionStep) error {
 	return r.db.Create(step).Error
@@ -77,6 +103,32 @@ func (r *ExecutionRepository) GetSteps(executionID uuid.UUID) ([]models.Executio
 	return steps, nil
 }
 
+// GetStepsForExecutions retrieves the steps of several executions, keyed by execution ID
+func (r *ExecutionRepository) GetStepsForExecutions(executionIDs []uuid.UUID) (map[uuid.UUID][]models.ExecutionStep, error) {
+	result := make(map[uuid.UUID][]models.ExecutionStep, len(executionIDs))
+	if len(executionIDs) == 0 {
+		return result, nil
+	}
+
+	var steps []models.ExecutionStep
+	if err := r.db.Where("execution_id IN ?", executionIDs).Order("created_at ASC").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		result[step.ExecutionID] = append(result[step.ExecutionID], step)
+	}
+	return result, nil
+}
+
+// ListByScheduleRun retrieves the executions of a schedule run, including reruns, oldest first
+func (r *ExecutionRepository) ListByScheduleRun(runID uuid.UUID) ([]models.Execution, error) {
+	var executions []models.Execution
+	if err := r.db.Preload("Flow").Where("schedule_run_id = ?", runID).Order("created_at ASC").Find(&executions).Error; err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
 // UpdateStep updates an execution step
 func (r *ExecutionRepository) UpdateStep(step *models.ExecutionStep) error {
 	return r.db.Save(step).Error
@@ -90,3 +142,19 @@ func (r *ExecutionRepository) GetStepByID(id uuid.UUID) (*models.ExecutionStep,
 	}
 	return &step, nil
 }
+
+// GetLastPassedStep retrieves the most recent completed run of a step in the
+// same flow, excluding the given execution
+func (r *ExecutionRepository) GetLastPassedStep(flowID uuid.UUID, stepID string, excludeExecutionID uuid.UUID) (*models.ExecutionStep, error) {
+	var step models.ExecutionStep
+	err := r.db.
+		Joins("JOIN executions.executions e ON e.id = execution_steps.execution_id").
+		Where("e.flow_id = ? AND execution_steps.step_id = ? AND execution_steps.status = ? AND execution_steps.execution_id != ?",
+			flowID, stepID, models.StepStatusCompleted, excludeExecutionID).
+		Order("execution_steps.finished_at DESC").
+		First(&step).Error
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
@@ -31,12 +31,43 @@ func (r *FlowRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.F
 	return &flow, nil
 }
 
-// List retrieves flows with optional filters, scoped to workspace
+// GetByIDUnscoped retrieves a flow by ID in any workspace, for background
+// jobs such as schedules that are not tied to a request
+func (r *FlowRepository) GetByIDUnscoped(id uuid.UUID) (*models.Flow, error) {
+	var flow models.Flow
+	if err := r.db.First(&flow, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// GetByName retrieves a flow by its exact name within a workspace. Ad-hoc
+// flows are not found by name.
+func (r *FlowRepository) GetByName(name string, workspaceID uuid.UUID) (*models.Flow, error) {
+	var flow models.Flow
+	if err := r.db.First(&flow, "name = ? AND workspace_id = ? AND NOT ad_hoc", name, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// GetAdHocByHash retrieves the ad-hoc flow of a workspace sent with the YAML
+// of a content hash
+func (r *FlowRepository) GetAdHocByHash(contentHash string, workspaceID uuid.UUID) (*models.Flow, error) {
+	var flow models.Flow
+	if err := r.db.First(&flow, "content_hash = ? AND workspace_id = ? AND ad_hoc", contentHash, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// List retrieves flows with optional filters, scoped to workspace. Ad-hoc
+// flows are not listed.
 func (r *FlowRepository) List(workspaceID uuid.UUID, suite string, tags []string, limit, offset int) ([]models.Flow, int64, error) {
 	var flows []models.Flow
 	var total int64
 
-	query := r.db.Model(&models.Flow{}).Where("workspace_id = ?", workspaceID)
+	query := r.db.Model(&models.Flow{}).Where("workspace_id = ? AND NOT ad_hoc", workspaceID)
 
 	// Apply filters
 	if suite != "" {
@@ -86,7 +117,7 @@ func (r *FlowRepository) Delete(id uuid.UUID, workspaceID uuid.UUID) error {
 // Search searches flows by name within a workspace
 func (r *FlowRepository) Search(workspaceID uuid.UUID, query string, limit int) ([]models.Flow, error) {
 	var flows []models.Flow
-	if err := r.db.Where("workspace_id = ? AND name ILIKE ?", workspaceID, "%"+query+"%").Limit(limit).Find(&flows).Error; err != nil {
+	if err := r.db.Where("workspace_id = ? AND NOT ad_hoc AND name ILIKE ?", workspaceID, "%"+query+"%").Limit(limit).Find(&flows).Error; err != nil {
 		return nil, err
 	}
 	return flows, nil
@@ -101,10 +132,28 @@ func (r *FlowRepository) ListByCollection(workspaceID uuid.UUID, collectionID uu
 	return flows, nil
 }
 
+// ListAll retrieves every flow in a workspace ordered by name
+func (r *FlowRepository) ListAll(workspaceID uuid.UUID) ([]models.Flow, error) {
+	var flows []models.Flow
+	if err := r.db.Where("workspace_id = ? AND NOT ad_hoc", workspaceID).Order("name ASC").Find(&flows).Error; err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
+// ListBySuite retrieves all flows of a suite within the workspace ordered by name
+func (r *FlowRepository) ListBySuite(workspaceID uuid.UUID, suite string) ([]models.Flow, error) {
+	var flows []models.Flow
+	if err := r.db.Where("workspace_id = ? AND suite = ? AND NOT ad_hoc", workspaceID, suite).Order("name ASC").Find(&flows).Error; err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
 // CountByWorkspace returns the total number of flows in a workspace
 func (r *FlowRepository) CountByWorkspace(workspaceID uuid.UUID) (int64, error) {
 	var count int64
-	if err := r.db.Model(&models.Flow{}).Where("workspace_id = ?", workspaceID).Count(&count).Error; err != nil {
+	if err := r.db.Model(&models.Flow{}).Where("workspace_id = ? AND NOT ad_hoc", workspaceID).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// staleSyncAfter is how long a sync may hold a binding before another one may take over
+const staleSyncAfter = 10 * time.Minute
+
+// GitSyncRepository handles git sync binding database operations
+type GitSyncRepository struct {
+	db *gorm.DB
+}
+
+// NewGitSyncRepository creates a new git sync repository
+func NewGitSyncRepository(db *gorm.DB) *GitSyncRepository {
+	return &GitSyncRepository{db: db}
+}
+
+// Create creates a new binding
+func (r *GitSyncRepository) Create(binding *models.GitSyncBinding) error {
+	return r.db.Create(binding).Error
+}
+
+// GetByID retrieves a binding by ID, verifying workspace ownership
+func (r *GitSyncRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.GitSyncBinding, error) {
+	var binding models.GitSyncBinding
+	err := r.db.Preload("Collection").
+		Preload("Files", func(db *gorm.DB) *gorm.DB {
+			return db.Order("file_path ASC")
+		}).
+		First(&binding, "id = ? AND workspace_id = ?", id, workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// Get retrieves a binding by ID
+func (r *GitSyncRepository) Get(id uuid.UUID) (*models.GitSyncBinding, error) {
+	var binding models.GitSyncBinding
+	if err := r.db.First(&binding, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// GetByCollection retrieves the binding of a collection
+func (r *GitSyncRepository) GetByCollection(collectionID uuid.UUID) (*models.GitSyncBinding, error) {
+	var binding models.GitSyncBinding
+	if err := r.db.First(&binding, "collection_id = ?", collectionID).Error; err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// List retrieves all bindings in a workspace
+func (r *GitSyncRepository) List(workspaceID uuid.UUID) ([]models.GitSyncBinding, error) {
+	var bindings []models.GitSyncBinding
+	err := r.db.Preload("Collection").
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at ASC").
+		Find(&bindings).Error
+	return bindings, err
+}
+
+// ListEnabled retrieves all enabled bindings across workspaces
+func (r *GitSyncRepository) ListEnabled() ([]models.GitSyncBinding, error) {
+	var bindings []models.GitSyncBinding
+	err := r.db.Where("enabled = ?", true).Find(&bindings).Error
+	return bindings, err
+}
+
+// FindByRepository retrieves the enabled bindings tracking a repository branch
+func (r *GitSyncRepository) FindByRepository(repository, branch string) ([]models.GitSyncBinding, error) {
+	var bindings []models.GitSyncBinding
+	err := r.db.Where("enabled = ? AND repository = ? AND branch = ?", true, repository, branch).
+		Find(&bindings).Error
+	return bindings, err
+}
+
+// Update updates a binding's settings, leaving its sync state untouched
+func (r *GitSyncRepository) Update(binding *models.GitSyncBinding) error {
+	return r.db.Model(binding).
+		Select("integration_id", "repository_url", "repository", "branch", "path", "push_branch", "create_pull_request", "sync_interval", "enabled").
+		Updates(binding).Error
+}
+
+// Delete deletes a binding and its file states
+func (r *GitSyncRepository) Delete(id uuid.UUID, workspaceID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND workspace_id = ?", id, workspaceID).Delete(&models.GitSyncBinding{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("binding_id = ?", id).Delete(&models.GitSyncFile{}).Error
+	})
+}
+
+// ClaimSync marks a binding as syncing unless another sync holds it.
+// A sync that has held it for longer than staleSyncAfter is taken over.
+func (r *GitSyncRepository) ClaimSync(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.GitSyncBinding{}).
+		Where("id = ? AND (status <> ? OR updated_at < ?)", id, models.GitSyncStatusSyncing, time.Now().Add(-staleSyncAfter)).
+		Updates(map[string]interface{}{
+			"status":     models.GitSyncStatusSyncing,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FinishSync records the outcome of a sync and releases the binding
+func (r *GitSyncRepository) FinishSync(id uuid.UUID, status models.GitSyncStatus, commit string, errorMsg string, synced bool) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"last_error": errorMsg,
+		"updated_at": time.Now(),
+	}
+	if commit != "" {
+		updates["last_commit"] = commit
+	}
+	if synced {
+		updates["last_synced_at"] = time.Now()
+	}
+	return r.db.Model(&models.GitSyncBinding{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// SetPullRequestURL records the pull request opened for a binding's edits
+func (r *GitSyncRepository) SetPullRequestURL(id uuid.UUID, url string) error {
+	return r.db.Model(&models.GitSyncBinding{}).Where("id = ?", id).Update("pull_request_url", url).Error
+}
+
+// ListFiles retrieves the file states of a binding
+func (r *GitSyncRepository) ListFiles(bindingID uuid.UUID) ([]models.GitSyncFile, error) {
+	var files []models.GitSyncFile
+	err := r.db.Where("binding_id = ?", bindingID).Order("file_path ASC").Find(&files).Error
+	return files, err
+}
+
+// SaveFile creates or updates a file state
+func (r *GitSyncRepository) SaveFile(file *models.GitSyncFile) error {
+	return r.db.Save(file).Error
+}
+
+// DeleteFile deletes a file state
+func (r *GitSyncRepository) DeleteFile(id uuid.UUID) error {
+	return r.db.Delete(&models.GitSyncFile{}, "id = ?", id).Error
+}
+
+// LatestFlowVersion returns the latest version number of a flow, or 0 if it has none
+func (r *GitSyncRepository) LatestFlowVersion(flowID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.Model(&models.FlowVersion{}).
+		Where("flow_id = ?", flowID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
@@ -277,12 +277,12 @@ func (r *GitTriggerRuleRepository) List(workspaceID uuid.UUID) ([]*models.GitTri
 	return rules, nil
 }
 
-// FindMatchingRules finds all enabled rules matching repository, branch, and event type
-func (r *GitTriggerRuleRepository) FindMatchingRules(repository, branch, eventType string) ([]*models.GitTriggerRule, error) {
+// FindMatchingRules finds all enabled rules of an integration matching repository, branch, and event type
+func (r *GitTriggerRuleRepository) FindMatchingRules(integrationID uuid.UUID, repository, branch, eventType string) ([]*models.GitTriggerRule, error) {
 	var rules []*models.GitTriggerRule
 
-	// Find rules with matching repository and enabled
-	query := r.db.Where("repository = ? AND enabled = ?", repository, true).
+	// Find rules with matching integration and repository and enabled
+	query := r.db.Where("integration_id = ? AND repository = ? AND enabled = ?", integrationID, repository, true).
 		Preload("Integration").
 		Preload("Schedule").
 		Preload("Flow")
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MonitorRepository handles monitor database operations
+type MonitorRepository struct {
+	db *gorm.DB
+}
+
+// NewMonitorRepository creates a new monitor repository
+func NewMonitorRepository(db *gorm.DB) *MonitorRepository {
+	return &MonitorRepository{db: db}
+}
+
+// Create creates a new monitor
+func (r *MonitorRepository) Create(monitor *models.Monitor) error {
+	return r.db.Create(monitor).Error
+}
+
+// GetByID retrieves a monitor by ID, verifying workspace ownership
+func (r *MonitorRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.Monitor, error) {
+	var monitor models.Monitor
+	err := r.db.Preload("Flow").
+		First(&monitor, "id = ? AND workspace_id = ?", id, workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &monitor, nil
+}
+
+// GetBySchedule retrieves the monitor that owns a schedule
+func (r *MonitorRepository) GetBySchedule(scheduleID uuid.UUID) (*models.Monitor, error) {
+	var monitor models.Monitor
+	err := r.db.First(&monitor, "schedule_id = ?", scheduleID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &monitor, nil
+}
+
+// List retrieves the monitors of a workspace by name. publicOnly limits them to those on the status page.
+func (r *MonitorRepository) List(workspaceID uuid.UUID, publicOnly bool) ([]models.Monitor, error) {
+	query := r.db.Preload("Flow").Where("workspace_id = ?", workspaceID)
+	if publicOnly {
+		query = query.Where("public = ?", true)
+	}
+
+	var monitors []models.Monitor
+	err := query.Order("name ASC").Find(&monitors).Error
+	return monitors, err
+}
+
+// Update saves a monitor
+func (r *MonitorRepository) Update(monitor *models.Monitor) error {
+	return r.db.Omit("Flow").Save(monitor).Error
+}
+
+// Delete deletes a monitor with its checks and alerts
+func (r *MonitorRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("monitor_id = ?", id).Delete(&models.MonitorCheck{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("monitor_id = ?", id).Delete(&models.MonitorAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Monitor{}, "id = ?", id).Error
+	})
+}
+
+// SetStatus sets the status of a monitor
+func (r *MonitorRepository) SetStatus(id uuid.UUID, status models.MonitorStatus) error {
+	return r.db.Model(&models.Monitor{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// SetState records the alert state and last check of a monitor
+func (r *MonitorRepository) SetState(monitor *models.Monitor) error {
+	return r.db.Model(&models.Monitor{}).Where("id = ?", monitor.ID).
+		Updates(map[string]interface{}{
+			"alert_state":       monitor.AlertState,
+			"alert_since":       monitor.AlertSince,
+			"last_check_at":     monitor.LastCheckAt,
+			"last_check_result": monitor.LastCheckResult,
+		}).Error
+}
+
+// Check operations
+
+// CreateCheck records a check
+func (r *MonitorRepository) CreateCheck(check *models.MonitorCheck) error {
+	return r.db.Create(check).Error
+}
+
+// ListChecks lists the latest checks of a monitor
+func (r *MonitorRepository) ListChecks(monitorID uuid.UUID, limit int) ([]models.MonitorCheck, error) {
+	var checks []models.MonitorCheck
+	query := r.db.Where("monitor_id = ?", monitorID).Order("checked_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&checks).Error
+	return checks, err
+}
+
+// Availability counts the successful and total checks of a monitor since a time
+func (r *MonitorRepository) Availability(monitorID uuid.UUID, since time.Time) (successful int64, total int64, err error) {
+	var counts struct {
+		Successful int64
+		Total      int64
+	}
+	err = r.db.Model(&models.MonitorCheck{}).
+		Select("COUNT(*) FILTER (WHERE result = 'success') AS successful, COUNT(*) AS total").
+		Where("monitor_id = ? AND checked_at >= ?", monitorID, since).
+		Scan(&counts).Error
+	return counts.Successful, counts.Total, err
+}
+
+// LatencyPercentile returns a latency percentile in milliseconds of a monitor's
+// successful checks since a time, with the number of samples. With a step ID it
+// measures the completed runs of that step in the checks' executions.
+func (r *MonitorRepository) LatencyPercentile(monitorID uuid.UUID, stepID string, percentile float64, since time.Time) (float64, int64, error) {
+	var result struct {
+		Value   float64
+		Samples int64
+	}
+
+	var err error
+	if stepID == "" {
+		err = r.db.Raw(`
+			SELECT COALESCE(percentile_cont(?) WITHIN GROUP (ORDER BY duration_ms), 0) AS value, COUNT(*) AS samples
+			FROM monitor_checks
+			WHERE monitor_id = ? AND checked_at >= ? AND result = 'success'
+		`, percentile/100, monitorID, since).Scan(&result).Error
+	} else {
+		err = r.db.Raw(`
+			SELECT COALESCE(percentile_cont(?) WITHIN GROUP (ORDER BY es.duration_ms), 0) AS value, COUNT(*) AS samples
+			FROM executions.execution_steps es
+			JOIN monitor_checks mc ON mc.execution_id = es.execution_id
+			WHERE mc.monitor_id = ? AND mc.checked_at >= ? AND es.step_id = ? AND es.status = ?
+		`, percentile/100, monitorID, since, stepID, models.StepStatusCompleted).Scan(&result).Error
+	}
+	return result.Value, result.Samples, err
+}
+
+// Alert operations
+
+// CreateAlert records a new alert
+func (r *MonitorRepository) CreateAlert(alert *models.MonitorAlert) error {
+	return r.db.Create(alert).Error
+}
+
+// UpdateAlert saves an alert
+func (r *MonitorRepository) UpdateAlert(alert *models.MonitorAlert) error {
+	return r.db.Save(alert).Error
+}
+
+// GetOpenAlert retrieves the pending or firing alert of a monitor
+func (r *MonitorRepository) GetOpenAlert(monitorID uuid.UUID) (*models.MonitorAlert, error) {
+	var alert models.MonitorAlert
+	err := r.db.Where("monitor_id = ? AND state IN ?", monitorID,
+		[]models.AlertState{models.AlertStatePending, models.AlertStateFiring}).
+		First(&alert).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// ListAlerts lists the alerts of a monitor that fired and were not resolved
+// before a time, newest first. Alerts that never fired are left out.
+func (r *MonitorRepository) ListAlerts(monitorID uuid.UUID, since time.Time, limit int) ([]models.MonitorAlert, error) {
+	var alerts []models.MonitorAlert
+	query := r.db.Where("monitor_id = ? AND fired_at IS NOT NULL AND (resolved_at IS NULL OR resolved_at >= ?)", monitorID, since).
+		Order("pending_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&alerts).Error
+	return alerts, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuarantineRepository handles quarantine database operations
+type QuarantineRepository struct {
+	db *gorm.DB
+}
+
+// NewQuarantineRepository creates a new quarantine repository
+func NewQuarantineRepository(db *gorm.DB) *QuarantineRepository {
+	return &QuarantineRepository{db: db}
+}
+
+// Create creates a new quarantine
+func (r *QuarantineRepository) Create(quarantine *models.Quarantine) error {
+	return r.db.Create(quarantine).Error
+}
+
+// GetByID retrieves a quarantine by ID, verifying workspace ownership
+func (r *QuarantineRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.Quarantine, error) {
+	var quarantine models.Quarantine
+	err := r.db.Preload("Flow").
+		First(&quarantine, "id = ? AND workspace_id = ?", id, workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quarantine, nil
+}
+
+// GetActive retrieves the active quarantine of a flow or step
+func (r *QuarantineRepository) GetActive(flowID uuid.UUID, stepID string) (*models.Quarantine, error) {
+	var quarantine models.Quarantine
+	err := r.db.First(&quarantine, "flow_id = ? AND step_id = ? AND status = ?", flowID, stepID, models.QuarantineStatusActive).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quarantine, nil
+}
+
+// ListActiveForFlow retrieves the active quarantines of a flow and its steps
+func (r *QuarantineRepository) ListActiveForFlow(flowID uuid.UUID) ([]models.Quarantine, error) {
+	var quarantines []models.Quarantine
+	err := r.db.Where("flow_id = ? AND status = ?", flowID, models.QuarantineStatusActive).
+		Find(&quarantines).Error
+	return quarantines, err
+}
+
+// List retrieves the quarantines of a workspace, newest first. An empty status lists all.
+func (r *QuarantineRepository) List(workspaceID uuid.UUID, status models.QuarantineStatus, flowID *uuid.UUID) ([]models.Quarantine, error) {
+	query := r.db.Preload("Flow").Where("workspace_id = ?", workspaceID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if flowID != nil {
+		query = query.Where("flow_id = ?", *flowID)
+	}
+
+	var quarantines []models.Quarantine
+	err := query.Order("created_at DESC").Find(&quarantines).Error
+	return quarantines, err
+}
+
+// SetCleanRuns records the consecutive clean runs of a quarantine
+func (r *QuarantineRepository) SetCleanRuns(id uuid.UUID, cleanRuns int) error {
+	return r.db.Model(&models.Quarantine{}).Where("id = ?", id).Update("clean_runs", cleanRuns).Error
+}
+
+// Release ends an active quarantine. It reports false when the quarantine was not active.
+func (r *QuarantineRepository) Release(id uuid.UUID, reason string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Quarantine{}).
+		Where("id = ? AND status = ?", id, models.QuarantineStatusActive).
+		Updates(map[string]interface{}{
+			"status":         models.QuarantineStatusReleased,
+			"released_at":    now,
+			"release_reason": reason,
+		})
+	return result.RowsAffected == 1, result.Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/georgi-georgiev/testmesh/internal/storage/models"
@@ -123,6 +124,16 @@ func (r *ScheduleRepository) UpdateLastRun(id uuid.UUID, runID uuid.UUID, result
 		}).Error
 }
 
+// ClaimOccurrence advances next_run_at from the expected value to next.
+// It reports false when another replica (or a schedule update) changed
+// next_run_at first, in which case the occurrence must not be fired.
+func (r *ScheduleRepository) ClaimOccurrence(id uuid.UUID, expected time.Time, next time.Time) (bool, error) {
+	result := r.db.Model(&models.Schedule{}).
+		Where("id = ? AND status = ? AND next_run_at = ?", id, models.ScheduleStatusActive, expected).
+		Update("next_run_at", next)
+	return result.RowsAffected == 1, result.Error
+}
+
 // SetStatus updates the status of a schedule
 func (r *ScheduleRepository) SetStatus(id uuid.UUID, status models.ScheduleStatus) error {
 	return r.db.Model(&models.Schedule{}).
@@ -147,6 +158,31 @@ func (r *ScheduleRepository) GetRun(id uuid.UUID) (*models.ScheduleRun, error) {
 	return &run, nil
 }
 
+// GetRunWithExecutions retrieves a run of a schedule together with its executions
+func (r *ScheduleRepository) GetRunWithExecutions(scheduleID, runID uuid.UUID) (*models.ScheduleRun, error) {
+	var run models.ScheduleRun
+	err := r.db.Preload("Executions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).First(&run, "id = ? AND schedule_id = ?", runID, scheduleID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetPreviousRun retrieves the latest completed run of a schedule scheduled before the given run
+func (r *ScheduleRepository) GetPreviousRun(run *models.ScheduleRun) (*models.ScheduleRun, error) {
+	var previous models.ScheduleRun
+	err := r.db.Where("schedule_id = ? AND id != ? AND scheduled_at < ? AND status = ?",
+		run.ScheduleID, run.ID, run.ScheduledAt, "completed").
+		Order("scheduled_at DESC").
+		First(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
 // UpdateRun updates a schedule run
 func (r *ScheduleRepository) UpdateRun(run *models.ScheduleRun) error {
 	return r.db.Save(run).Error
@@ -163,10 +199,10 @@ func (r *ScheduleRepository) ListRuns(scheduleID uuid.UUID, limit int) ([]*model
 	return runs, err
 }
 
-// GetRunningRun returns the currently running execution for a schedule (if any)
+// GetRunningRun returns the pending or running execution for a schedule (if any)
 func (r *ScheduleRepository) GetRunningRun(scheduleID uuid.UUID) (*models.ScheduleRun, error) {
 	var run models.ScheduleRun
-	err := r.db.Where("schedule_id = ? AND status = ?", scheduleID, "running").
+	err := r.db.Where("schedule_id = ? AND status IN ?", scheduleID, []string{"pending", "running"}).
 		First(&run).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, nil
@@ -189,6 +225,59 @@ func (r *ScheduleRepository) MarkRunStarted(runID uuid.UUID, executionID uuid.UU
 		}).Error
 }
 
+// MarkRunRunning marks a run as running before its execution is created
+func (r *ScheduleRepository) MarkRunRunning(runID uuid.UUID) error {
+	now := time.Now()
+	return r.db.Model(&models.ScheduleRun{}).
+		Where("id = ?", runID).
+		Updates(map[string]interface{}{
+			"status":     "running",
+			"started_at": now,
+		}).Error
+}
+
+// SetRunExecution records the execution of the latest attempt of a run
+func (r *ScheduleRepository) SetRunExecution(runID uuid.UUID, executionID uuid.UUID) error {
+	return r.db.Model(&models.ScheduleRun{}).
+		Where("id = ?", runID).
+		Update("execution_id", executionID).Error
+}
+
+// IncrementRunRetry records a failed attempt that will be retried
+func (r *ScheduleRepository) IncrementRunRetry(runID uuid.UUID, errorMsg string) error {
+	return r.db.Model(&models.ScheduleRun{}).
+		Where("id = ?", runID).
+		Updates(map[string]interface{}{
+			"retry_count": gorm.Expr("retry_count + 1"),
+			"error":       errorMsg,
+		}).Error
+}
+
+// UpdateRunResults stores the per-flow results and counts of a run
+func (r *ScheduleRepository) UpdateRunResults(runID uuid.UUID, results []models.ScheduleRunFlow) error {
+	counts := models.ScheduleRun{
+		TotalFlows:  len(results),
+		FlowResults: results,
+	}
+	for _, fr := range results {
+		switch fr.Status {
+		case "passed":
+			counts.PassedFlows++
+		case "failed":
+			counts.FailedFlows++
+		case "skipped":
+			counts.SkippedFlows++
+		case "quarantined":
+			counts.QuarantinedFlows++
+		}
+	}
+
+	// Select so that zero counts are written too
+	return r.db.Model(&models.ScheduleRun{ID: runID}).
+		Select("total_flows", "passed_flows", "failed_flows", "skipped_flows", "quarantined_flows", "flow_results").
+		Updates(&counts).Error
+}
+
 // MarkRunCompleted marks a run as completed
 func (r *ScheduleRepository) MarkRunCompleted(runID uuid.UUID, result string, errorMsg string) error {
 	now := time.Now()
@@ -196,6 +285,7 @@ func (r *ScheduleRepository) MarkRunCompleted(runID uuid.UUID, result string, er
 		"status":       "completed",
 		"result":       result,
 		"completed_at": now,
+		"duration":     gorm.Expr("COALESCE((EXTRACT(EPOCH FROM (?::timestamptz - started_at)) * 1000)::bigint, 0)", now),
 	}
 	if errorMsg != "" {
 		updates["error"] = errorMsg
@@ -217,6 +307,49 @@ func (r *ScheduleRepository) MarkRunSkipped(runID uuid.UUID, reason string) erro
 		}).Error
 }
 
+// TouchRun renews the heartbeat of a pending or running run
+func (r *ScheduleRepository) TouchRun(runID uuid.UUID) error {
+	return r.db.Model(&models.ScheduleRun{}).
+		Where("id = ? AND status IN ?", runID, []string{"pending", "running"}).
+		Update("heartbeat_at", gorm.Expr("NOW()")).Error
+}
+
+// AbandonStaleRuns fails the pending and running runs whose heartbeat is
+// older than staleAfter, such as runs of a replica that stopped. Heartbeats
+// are compared with the database clock. It returns the abandoned runs.
+func (r *ScheduleRepository) AbandonStaleRuns(staleAfter time.Duration, reason string) ([]*models.ScheduleRun, error) {
+	var runs []*models.ScheduleRun
+	err := r.db.Preload("Schedule").
+		Where("status IN ? AND COALESCE(heartbeat_at, started_at, created_at) < NOW() - ?::interval",
+			[]string{"pending", "running"}, fmt.Sprintf("%d milliseconds", staleAfter.Milliseconds())).
+		Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	abandoned := runs[:0]
+	for _, run := range runs {
+		now := time.Now()
+		// The status check keeps a run that finished meanwhile as it is
+		result := r.db.Model(&models.ScheduleRun{}).
+			Where("id = ? AND status IN ?", run.ID, []string{"pending", "running"}).
+			Updates(map[string]interface{}{
+				"status":       "completed",
+				"result":       "failure",
+				"error":        reason,
+				"completed_at": now,
+				"duration":     gorm.Expr("COALESCE((EXTRACT(EPOCH FROM (?::timestamptz - started_at)) * 1000)::bigint, 0)", now),
+			})
+		if result.Error != nil {
+			return abandoned, result.Error
+		}
+		if result.RowsAffected == 1 {
+			abandoned = append(abandoned, run)
+		}
+	}
+	return abandoned, nil
+}
+
 // GetScheduleStats returns statistics for a schedule
 func (r *ScheduleRepository) GetScheduleStats(scheduleID uuid.UUID, days int) (*ScheduleStats, error) {
 	since := time.Now().AddDate(0, 0, -days)
@@ -260,3 +393,38 @@ type ScheduleStats struct {
 	SkippedRuns    int64   `json:"skipped_runs"`
 	AvgDurationMs  float64 `json:"avg_duration_ms"`
 }
+
+// Scheduler lease operations
+
+// AcquireLease takes or renews a lease for holder. It succeeds when the lease
+// is free, expired or already held by holder. Expiry uses the database clock
+// so replicas with skewed clocks agree on it.
+func (r *ScheduleRepository) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	result := r.db.Exec(`
+		INSERT INTO scheduler_leases (name, holder, expires_at)
+		VALUES (?, ?, NOW() + ?::interval)
+		ON CONFLICT (name) DO UPDATE
+			SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+			WHERE scheduler_leases.holder = EXCLUDED.holder OR scheduler_leases.expires_at < NOW()
+	`, name, holder, fmt.Sprintf("%d milliseconds", ttl.Milliseconds()))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseLease gives up a lease if holder still holds it
+func (r *ScheduleRepository) ReleaseLease(name, holder string) error {
+	return r.db.Where("name = ? AND holder = ?", name, holder).
+		Delete(&models.SchedulerLease{}).Error
+}
+
+// GetLease returns the current lease, or nil if nobody holds it
+func (r *ScheduleRepository) GetLease(name string) (*models.SchedulerLease, error) {
+	var lease models.SchedulerLease
+	err := r.db.Where("name = ? AND expires_at > NOW()", name).First(&lease).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
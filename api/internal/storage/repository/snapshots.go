@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"github.com/georgi-georgiev/testmesh/internal/storage/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SnapshotRepository handles snapshot database operations
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotRepository creates a new snapshot repository
+func NewSnapshotRepository(db *gorm.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// Create creates a new snapshot
+func (r *SnapshotRepository) Create(snapshot *models.Snapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+// Update saves a snapshot
+func (r *SnapshotRepository) Update(snapshot *models.Snapshot) error {
+	return r.db.Save(snapshot).Error
+}
+
+// GetByName retrieves the snapshot of a flow by name
+func (r *SnapshotRepository) GetByName(flowID uuid.UUID, name string) (*models.Snapshot, error) {
+	var snapshot models.Snapshot
+	if err := r.db.First(&snapshot, "flow_id = ? AND name = ?", flowID, name).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetByID retrieves a snapshot by ID, verifying its flow belongs to the workspace
+func (r *SnapshotRepository) GetByID(id uuid.UUID, workspaceID uuid.UUID) (*models.Snapshot, error) {
+	var snapshot models.Snapshot
+	err := r.db.Joins("JOIN flows.flows f ON f.id = snapshots.flow_id").
+		Where("snapshots.id = ? AND f.workspace_id = ?", id, workspaceID).
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListByFlow retrieves all snapshots of a flow, optionally filtered by status
+func (r *SnapshotRepository) ListByFlow(flowID uuid.UUID, status models.SnapshotStatus) ([]models.Snapshot, error) {
+	var snapshots []models.Snapshot
+	query := r.db.Where("flow_id = ?", flowID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("name ASC").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// ListPending retrieves snapshots awaiting review, optionally limited to flows
+func (r *SnapshotRepository) ListPending(flowIDs []uuid.UUID) ([]models.Snapshot, error) {
+	var snapshots []models.Snapshot
+	query := r.db.Preload("Flow").Where("status = ?", models.SnapshotStatusPending)
+	if len(flowIDs) > 0 {
+		query = query.Where("flow_id IN ?", flowIDs)
+	}
+	if err := query.Order("updated_at DESC").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// Delete removes a snapshot so the next run records a new golden record
+func (r *SnapshotRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&models.Snapshot{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
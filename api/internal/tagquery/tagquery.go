@@ -0,0 +1,210 @@
+// Package tagquery parses and evaluates boolean tag expressions such as
+// "critical AND NOT slow" or "(smoke OR regression) AND !flaky".
+package tagquery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed tag expression
+type Query interface {
+	// Match reports whether a set of tags satisfies the expression
+	Match(tags []string) bool
+	String() string
+}
+
+type tagTerm string
+
+func (t tagTerm) Match(tags []string) bool {
+	for _, tag := range tags {
+		if strings.EqualFold(tag, string(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t tagTerm) String() string { return string(t) }
+
+type notExpr struct{ expr Query }
+
+func (n notExpr) Match(tags []string) bool { return !n.expr.Match(tags) }
+func (n notExpr) String() string           { return "NOT " + n.expr.String() }
+
+type andExpr struct{ left, right Query }
+
+func (a andExpr) Match(tags []string) bool { return a.left.Match(tags) && a.right.Match(tags) }
+func (a andExpr) String() string           { return "(" + a.left.String() + " AND " + a.right.String() + ")" }
+
+type orExpr struct{ left, right Query }
+
+func (o orExpr) Match(tags []string) bool { return o.left.Match(tags) || o.right.Match(tags) }
+func (o orExpr) String() string           { return "(" + o.left.String() + " OR " + o.right.String() + ")" }
+
+// Parse parses a tag expression. Operators are AND (also "+" and "&&"),
+// OR (also "," and "||") and NOT (also "!"), with the usual precedence
+// NOT > AND > OR, and parentheses for grouping. Tags match case-insensitively.
+func Parse(expr string) (Query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty tag query")
+	}
+
+	p := &parser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in tag query", p.tokens[p.pos])
+	}
+	return q, nil
+}
+
+const (
+	tokAnd    = "AND"
+	tokOr     = "OR"
+	tokNot    = "NOT"
+	tokLParen = "("
+	tokRParen = ")"
+)
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '!':
+			tokens = append(tokens, tokNot)
+			i++
+		case r == '+' || r == ',':
+			if r == '+' {
+				tokens = append(tokens, tokAnd)
+			} else {
+				tokens = append(tokens, tokOr)
+			}
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected %q in tag query", string(r))
+			}
+			if r == '&' {
+				tokens = append(tokens, tokAnd)
+			} else {
+				tokens = append(tokens, tokOr)
+			}
+			i += 2
+		default:
+			start := i
+			for i < len(runes) && isTagRune(runes[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q in tag query", string(r))
+			}
+			word := string(runes[start:i])
+			switch strings.ToUpper(word) {
+			case tokAnd, tokOr, tokNot:
+				tokens = append(tokens, strings.ToUpper(word))
+			default:
+				tokens = append(tokens, word)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' || r == ':' || r == '/'
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (Query, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == tokOr {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Query, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == tokAnd {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Query, error) {
+	if p.peek() == tokNot {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Query, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of tag query")
+	case tokLParen:
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != tokRParen {
+			return nil, fmt.Errorf("missing ) in tag query")
+		}
+		p.pos++
+		return expr, nil
+	case tokRParen, tokAnd, tokOr:
+		return nil, fmt.Errorf("unexpected %q in tag query", tok)
+	}
+	p.pos++
+	return tagTerm(tok), nil
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/georgi-georgiev/testmesh/internal/api"
 	"github.com/georgi-georgiev/testmesh/internal/api/websocket"
+	"github.com/georgi-georgiev/testmesh/internal/events"
+	"github.com/georgi-georgiev/testmesh/internal/metrics"
 	"github.com/georgi-georgiev/testmesh/internal/shared/config"
 	"github.com/georgi-georgiev/testmesh/internal/shared/database"
 	"github.com/georgi-georgiev/testmesh/internal/shared/logger"
@@ -39,12 +41,19 @@ func main() {
 		log.Fatal("Failed to auto-migrate database", zap.Error(err))
 	}
 
-	// Initialize WebSocket hub
-	wsHub := websocket.NewHub(log)
-	go wsHub.Run()
+	// Initialize event bus and WebSocket hub
+	bus, err := events.NewBus(cfg.Events, db, cfg.Database, log)
+	if err != nil {
+		log.Fatal("Failed to initialize event bus", zap.Error(err))
+	}
+	defer bus.Close()
+	wsHub := websocket.NewHub(bus, log)
+
+	// Initialize Prometheus metrics (nil when disabled)
+	m := metrics.New(cfg.Metrics)
 
 	// Initialize API server
-	router := api.NewRouter(db, log, wsHub, cfg.Server.Port)
+	router := api.NewRouter(db, log, wsHub, cfg.Server, m, cfg.Artifacts, cfg.Runner)
 
 	// Create HTTP server
 	srv := &http.Server{
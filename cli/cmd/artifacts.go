@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var artifactsOutputDir string
+
+var artifactsCmd = &cobra.Command{
+	Use:   "artifacts",
+	Short: "List and download execution artifacts",
+	Long: `List and download the artifacts of an execution: screenshots, files
+attached by actions and plugins, and step outputs too large to keep inline.
+
+Examples:
+  testmesh artifacts list <execution-id>
+  testmesh artifacts download <execution-id> --output ./artifacts
+  testmesh artifacts download <execution-id> <artifact-id>`,
+}
+
+var artifactsListCmd = &cobra.Command{
+	Use:   "list <execution-id>",
+	Short: "List the artifacts of an execution",
+	Args:  cobra.ExactArgs(1),
+	RunE:  listArtifacts,
+}
+
+var artifactsDownloadCmd = &cobra.Command{
+	Use:   "download <execution-id> [artifact-id...]",
+	Short: "Download the artifacts of an execution",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  downloadArtifacts,
+}
+
+func init() {
+	rootCmd.AddCommand(artifactsCmd)
+	artifactsCmd.AddCommand(artifactsListCmd)
+	artifactsCmd.AddCommand(artifactsDownloadCmd)
+
+	artifactsDownloadCmd.Flags().StringVarP(&artifactsOutputDir, "output", "o", ".", "Directory to download artifacts to")
+}
+
+type Artifact struct {
+	ID          string    `json:"id"`
+	StepID      string    `json:"step_id"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func listArtifacts(cmd *cobra.Command, args []string) error {
+	artifacts, err := fetchArtifacts(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(artifacts) == 0 {
+		fmt.Println("No artifacts found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-20s %-10s %-30s %10s\n", "ID", "STEP", "KIND", "NAME", "SIZE")
+	fmt.Println(strings.Repeat("-", 112))
+
+	for _, a := range artifacts {
+		fmt.Printf("%-36s  %-20s %-10s %-30s %10s\n",
+			a.ID, truncate(a.StepID, 20), a.Kind, truncate(a.Name, 30), formatSize(a.Size))
+	}
+
+	return nil
+}
+
+func downloadArtifacts(cmd *cobra.Command, args []string) error {
+	artifacts, err := fetchArtifacts(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 1 {
+		wanted := make(map[string]bool, len(args)-1)
+		for _, id := range args[1:] {
+			wanted[id] = true
+		}
+		var selected []Artifact
+		for _, a := range artifacts {
+			if wanted[a.ID] {
+				selected = append(selected, a)
+			}
+		}
+		if len(selected) != len(wanted) {
+			return fmt.Errorf("artifact not found in execution %s", args[0])
+		}
+		artifacts = selected
+	}
+
+	if len(artifacts) == 0 {
+		fmt.Println("No artifacts found")
+		return nil
+	}
+
+	if err := os.MkdirAll(artifactsOutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, a := range artifacts {
+		// Prefix with the step so that artifacts of different steps don't collide
+		name := filepath.Base(a.Name)
+		if a.StepID != "" {
+			name = strings.ReplaceAll(a.StepID, string(filepath.Separator), "_") + "-" + name
+		}
+		path := filepath.Join(artifactsOutputDir, name)
+		if err := downloadArtifact(a.ID, path); err != nil {
+			return err
+		}
+		fmt.Printf("📎 %s (%s)\n", path, formatSize(a.Size))
+	}
+
+	return nil
+}
+
+func downloadArtifact(id, path string) error {
+	resp, err := http.Get(workspaceEndpoint("/artifacts/" + id + "/download"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to download artifact: %w", err)
+	}
+	return nil
+}
+
+func fetchArtifacts(executionID string) ([]Artifact, error) {
+	resp, err := http.Get(workspaceEndpoint("/executions/" + executionID + "/artifacts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Artifacts []Artifact `json:"artifacts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Artifacts, nil
+}
+
+// formatSize formats a size in bytes for humans
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/dap"
+	"github.com/spf13/cobra"
+)
+
+var dapListen string
+
+var dapCmd = &cobra.Command{
+	Use:   "dap",
+	Short: "Run a Debug Adapter Protocol server for debugging flows in editors",
+	Long: `Run a Debug Adapter Protocol (DAP) server, so that editors can debug flows:
+set breakpoints on step lines, step through setup, steps and teardown, inspect
+variables and step outputs, evaluate expressions and change variables.
+
+Flows run on the TestMesh server. On launch, the flow file is uploaded to the
+workspace, replacing the flow with the same name.
+
+By default the server talks DAP over stdin and stdout. With --listen it
+accepts TCP connections instead, one debug session per connection.
+
+Launch arguments:
+  program       Path of the flow file (required)
+  environment   Environment to run in
+  variables     Variables of the execution
+  stopOnEntry   Pause before the first step
+
+Examples:
+  testmesh dap
+  testmesh dap --listen 127.0.0.1:4711`,
+	RunE: runDAP,
+}
+
+func init() {
+	rootCmd.AddCommand(dapCmd)
+	dapCmd.Flags().StringVar(&dapListen, "listen", "", "Accept DAP connections on this TCP address instead of stdio")
+}
+
+func runDAP(cmd *cobra.Command, args []string) error {
+	client := dap.NewClient(apiURL, workspaceID)
+
+	if dapListen == "" {
+		return dap.Serve(os.Stdin, os.Stdout, client)
+	}
+
+	listener, err := net.Listen("tcp", dapListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer listener.Close()
+
+	// stdout is free in TCP mode
+	fmt.Printf("🐞 DAP server listening on %s\n", listener.Addr())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go func() {
+			defer conn.Close()
+			if err := dap.Serve(conn, conn, client); err != nil {
+				fmt.Fprintf(os.Stderr, "DAP session ended: %v\n", err)
+			}
+		}()
+	}
+}
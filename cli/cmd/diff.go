@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffBase             string
+	diffSchedule         string
+	diffFormat           string
+	diffOutput           string
+	diffThreshold        float64
+	diffFailOnRegression bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <execution-id|run-id>",
+	Short: "Compare two test runs",
+	Long: `Compare an execution or a schedule run with an earlier one.
+
+Shows newly failing and newly passing flows, step latency regressions
+against the step's performance history, changed error messages and
+changes to the shape of step responses.
+
+Without --base the previous run of the same flow or schedule is used.
+
+Formats:
+- text (default): Summary for the terminal
+- json: Machine-readable JSON
+- markdown: GitHub-compatible Markdown
+- html: Standalone HTML page
+
+Examples:
+  testmesh diff abc123
+  testmesh diff abc123 --base def456 --format markdown -o diff.md
+  testmesh diff --schedule nightly-id run-id --fail-on-regression`,
+	Args: cobra.ExactArgs(1),
+	RunE: diffRuns,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffBase, "base", "", "Execution or run ID to compare with (default: previous run)")
+	diffCmd.Flags().StringVar(&diffSchedule, "schedule", "", "Compare runs of this schedule instead of executions")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "Output format (text, json, markdown, html)")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "Output file path")
+	diffCmd.Flags().Float64Var(&diffThreshold, "threshold", 0, "Standard deviations above the mean that count as a latency regression (default 3)")
+	diffCmd.Flags().BoolVar(&diffFailOnRegression, "fail-on-regression", false, "Exit with an error when anything newly fails or slows down")
+}
+
+type RunComparison struct {
+	Title string `json:"title"`
+	Base  struct {
+		Name   string `json:"name"`
+		Flows  int    `json:"flows"`
+		Passed int    `json:"passed"`
+	} `json:"base"`
+	Head struct {
+		Name   string `json:"name"`
+		Flows  int    `json:"flows"`
+		Passed int    `json:"passed"`
+	} `json:"head"`
+	Summary struct {
+		NewlyFailing       int  `json:"newly_failing"`
+		NewlyPassing       int  `json:"newly_passing"`
+		StillFailing       int  `json:"still_failing"`
+		LatencyRegressions int  `json:"latency_regressions"`
+		ErrorChanges       int  `json:"error_changes"`
+		ShapeChanges       int  `json:"shape_changes"`
+		Regressed          bool `json:"regressed"`
+	} `json:"summary"`
+	Flows []struct {
+		FlowName   string `json:"flow_name"`
+		Change     string `json:"change"`
+		BaseStatus string `json:"base_status"`
+		HeadStatus string `json:"head_status"`
+		HeadError  string `json:"head_error"`
+		Steps      []struct {
+			StepID     string `json:"step_id"`
+			StepName   string `json:"step_name"`
+			Change     string `json:"change"`
+			BaseMs     int64  `json:"base_duration_ms"`
+			HeadMs     int64  `json:"head_duration_ms"`
+			HeadError  string `json:"head_error"`
+			BaseError  string `json:"base_error"`
+			ErrChanged bool   `json:"error_changed"`
+			Latency    *struct {
+				Baseline   string  `json:"baseline"`
+				MeanMs     float64 `json:"mean_ms"`
+				ZScore     float64 `json:"z_score"`
+				SlowdownPc float64 `json:"slowdown_pct"`
+			} `json:"latency_regression"`
+			ShapeChanges []struct {
+				Description string `json:"description"`
+			} `json:"shape_changes"`
+		} `json:"steps"`
+	} `json:"flows"`
+}
+
+func diffRuns(cmd *cobra.Command, args []string) error {
+	endpoint := workspaceEndpoint("/executions/" + args[0] + "/compare")
+	if diffSchedule != "" {
+		endpoint = fmt.Sprintf("%s/api/v1/schedules/%s/runs/%s/compare", apiURL, diffSchedule, args[0])
+	}
+
+	format := strings.ToLower(diffFormat)
+	query := url.Values{}
+	if diffBase != "" {
+		query.Set("base", diffBase)
+	}
+	if diffThreshold > 0 {
+		query.Set("threshold", fmt.Sprintf("%g", diffThreshold))
+	}
+	switch format {
+	case "text", "json":
+		query.Set("format", "json")
+	case "markdown", "html":
+		query.Set("format", format)
+	default:
+		return fmt.Errorf("unsupported format: %s", diffFormat)
+	}
+
+	resp, err := http.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var comparison RunComparison
+	if query.Get("format") == "json" {
+		if err := json.Unmarshal(body, &comparison); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	output := body
+	if format == "text" {
+		output = []byte(formatComparison(&comparison))
+	}
+
+	if diffOutput != "" {
+		if err := os.WriteFile(diffOutput, output, 0644); err != nil {
+			return fmt.Errorf("failed to write comparison: %w", err)
+		}
+		fmt.Printf("✅ Comparison saved to %s\n", diffOutput)
+	} else {
+		fmt.Println(string(output))
+	}
+
+	if diffFailOnRegression {
+		// Rendered formats carry no summary to check, so fetch it as JSON
+		if query.Get("format") != "json" {
+			query.Set("format", "json")
+			if err := fetchComparison(endpoint+"?"+query.Encode(), &comparison); err != nil {
+				return err
+			}
+		}
+		if comparison.Summary.Regressed {
+			return fmt.Errorf("%d flow(s) newly failing, %d latency regression(s)",
+				comparison.Summary.NewlyFailing, comparison.Summary.LatencyRegressions)
+		}
+	}
+
+	return nil
+}
+
+func fetchComparison(endpoint string, out *RunComparison) error {
+	resp, err := http.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func formatComparison(cmp *RunComparison) string {
+	var b strings.Builder
+	s := cmp.Summary
+
+	fmt.Fprintf(&b, "🔍 %s\n", cmp.Title)
+	fmt.Fprintf(&b, "   Base: %s (%d/%d passed)\n", cmp.Base.Name, cmp.Base.Passed, cmp.Base.Flows)
+	fmt.Fprintf(&b, "   Head: %s (%d/%d passed)\n\n", cmp.Head.Name, cmp.Head.Passed, cmp.Head.Flows)
+
+	icons := map[string]string{
+		"newly_failing": "❌",
+		"newly_passing": "✅",
+		"still_failing": "⚠️ ",
+		"skipped":       "⏭️ ",
+		"added":         "➕",
+		"removed":       "➖",
+	}
+
+	for _, flow := range cmp.Flows {
+		if flow.Change == "unchanged" && len(flow.Steps) == 0 {
+			continue
+		}
+		icon := icons[flow.Change]
+		if icon == "" {
+			icon = "🔄"
+		}
+		fmt.Fprintf(&b, "%s %s (%s)\n", icon, flow.FlowName, strings.ReplaceAll(flow.Change, "_", " "))
+		if flow.Change == "newly_failing" && flow.HeadError != "" {
+			fmt.Fprintf(&b, "   error: %s\n", truncate(flow.HeadError, 120))
+		}
+
+		for _, step := range flow.Steps {
+			name := step.StepName
+			if name == "" {
+				name = step.StepID
+			}
+			fmt.Fprintf(&b, "   • %s: %s\n", name, strings.ReplaceAll(step.Change, "_", " "))
+			if l := step.Latency; l != nil {
+				against := "the base run"
+				if l.Baseline == "history" {
+					against = fmt.Sprintf("history (mean %.0fms, z=%.1f)", l.MeanMs, l.ZScore)
+				}
+				fmt.Fprintf(&b, "     🐢 %dms → %dms, %.0f%% slower than %s\n", step.BaseMs, step.HeadMs, l.SlowdownPc, against)
+			}
+			if step.ErrChanged {
+				fmt.Fprintf(&b, "     error was: %s\n", truncate(step.BaseError, 100))
+				fmt.Fprintf(&b, "     error now: %s\n", truncate(step.HeadError, 100))
+			} else if step.Change == "newly_failing" && step.HeadError != "" {
+				fmt.Fprintf(&b, "     error: %s\n", truncate(step.HeadError, 100))
+			}
+			for _, change := range step.ShapeChanges {
+				fmt.Fprintf(&b, "     shape: %s\n", change.Description)
+			}
+		}
+	}
+
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(&b, "   Newly failing: %d\n", s.NewlyFailing)
+	fmt.Fprintf(&b, "   Newly passing: %d\n", s.NewlyPassing)
+	fmt.Fprintf(&b, "   Still failing: %d\n", s.StillFailing)
+	fmt.Fprintf(&b, "   Latency regressions: %d\n", s.LatencyRegressions)
+	fmt.Fprintf(&b, "   Changed errors: %d\n", s.ErrorChanges)
+	fmt.Fprintf(&b, "   Shape changes: %d\n", s.ShapeChanges)
+	return b.String()
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,8 +17,17 @@ import (
 var (
 	exportFormat string
 	exportOutput string
+	exportEnv    string
 )
 
+// FlowTestCode is a test file generated by the server from a flow
+type FlowTestCode struct {
+	Target   string   `json:"target"`
+	Filename string   `json:"filename"`
+	Code     string   `json:"code"`
+	Warnings []string `json:"warnings"`
+}
+
 var exportCmd = &cobra.Command{
 	Use:   "export <flow.yaml>",
 	Short: "Export flows to different formats",
@@ -26,15 +38,28 @@ Supported export formats:
 - json: JSON format for programmatic access
 - postman: Postman Collection v2.1 format
 - openapi: OpenAPI 3.0 specification
-- curl: Shell script with curl commands`,
+- curl: Shell script with curl commands
+
+Test file formats, generated by the server with setup/teardown, variable
+chaining between steps and translated assertions:
+- go: Go testing with net/http
+- pytest: pytest with requests
+- jest: Jest with fetch
+- vitest: Vitest with fetch
+- k6: k6 load test script
+
+Variables of the environment selected with --env become the defaults of
+the generated file; secrets are left empty. When --output is a directory,
+the file gets its conventional name, like test_login.py.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: exportFlow,
 }
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "yaml", "Export format (yaml, json, postman, openapi, curl)")
-	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file path (default: stdout)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "yaml", "Export format (yaml, json, postman, openapi, curl, go, pytest, jest, vitest, k6)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file path or directory (default: stdout)")
+	exportCmd.Flags().StringVarP(&exportEnv, "env", "e", "", "Environment whose variables test files use (go, pytest, jest, vitest, k6)")
 }
 
 func exportFlow(cmd *cobra.Command, args []string) error {
@@ -74,6 +99,20 @@ func exportFlow(cmd *cobra.Command, args []string) error {
 	case "curl":
 		output, err = exportToCurl(flow)
 		ext = ".sh"
+	case "go", "pytest", "jest", "vitest", "k6":
+		var code *FlowTestCode
+		code, err = generateFlowTest(string(data))
+		if err == nil {
+			output = []byte(code.Code)
+			ext = testFileExtension(code.Filename)
+			for _, warning := range code.Warnings {
+				fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+			}
+			// A directory gets the conventional file name of the framework
+			if info, statErr := os.Stat(exportOutput); exportOutput != "" && statErr == nil && info.IsDir() {
+				exportOutput = filepath.Join(exportOutput, code.Filename)
+			}
+		}
 	default:
 		return fmt.Errorf("unsupported format: %s", exportFormat)
 	}
@@ -99,6 +138,43 @@ func exportFlow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// generateFlowTest asks the server to generate a test file of a flow
+func generateFlowTest(flowYAML string) (*FlowTestCode, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"target":      strings.ToLower(exportFormat),
+		"yaml":        flowYAML,
+		"environment": exportEnv,
+	})
+
+	resp, err := http.Post(workspaceEndpoint("/codegen/flow"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s", string(body))
+	}
+
+	var code FlowTestCode
+	if err := json.NewDecoder(resp.Body).Decode(&code); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &code, nil
+}
+
+// testFileExtension returns the extension of a generated test file,
+// including suffixes like _test.go and .test.js
+func testFileExtension(filename string) string {
+	for _, suffix := range []string{"_test.go", ".test.js", ".k6.js"} {
+		if strings.HasSuffix(filename, suffix) {
+			return suffix
+		}
+	}
+	return filepath.Ext(filename)
+}
+
 func exportToPostman(flow map[string]interface{}) ([]byte, error) {
 	name := "Exported Flow"
 	if n, ok := flow["name"].(string); ok {
@@ -11,9 +11,14 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
-var importOutput string
+var (
+	importOutput  string
+	importGroupBy string
+	importUseAI   bool
+)
 
 var importCmd = &cobra.Command{
 	Use:   "import <file>",
@@ -23,9 +28,28 @@ var importCmd = &cobra.Command{
 Supported formats:
 - OpenAPI/Swagger (*.yaml, *.json)
 - Postman Collection (*.postman_collection.json)
+- Insomnia export, format 4 (*.json)
+- Bruno collection (a directory with bruno.json)
 - HAR files (*.har)
+- GraphQL introspection results (*.json)
+
+The imported flows will be converted to TestMesh format.
+
+OpenAPI documents are converted deterministically (no AI provider needed):
+one flow per operation, or per tag with --group-by tag. Request bodies are
+synthesized from schemas and examples, and an environment file with
+BASE_URL and credential variables is written next to the flows.
+Re-running the import overwrites the same files.
+
+GraphQL introspection results become one graphql flow per root field
+(or per root type with --group-by type), with a GRAPHQL_URL environment
+variable.
 
-The imported flows will be converted to TestMesh format.`,
+Postman, Insomnia and Bruno collections are converted deterministically
+too: each folder of requests becomes a flow with a step per request, and
+folders of folders become directories. Tests become assertions, variables
+set from responses become outputs, and the collection variables are
+written to an environment file.`,
 	Args: cobra.ExactArgs(1),
 	RunE: importFile,
 }
@@ -33,11 +57,25 @@ The imported flows will be converted to TestMesh format.`,
 func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "Output directory for imported flows")
+	importCmd.Flags().StringVar(&importGroupBy, "group-by", "operation", "Grouping: operation, tag (OpenAPI) or type (GraphQL)")
+	importCmd.Flags().BoolVar(&importUseAI, "ai", false, "Use the AI importer instead of the deterministic OpenAPI or Postman importer")
 }
 
 func importFile(cmd *cobra.Command, args []string) error {
 	filePath := args[0]
 
+	// Bruno collections are directories
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		files, err := readBrunoCollection(filePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📥 Importing from bruno format...\n")
+		fmt.Printf("   Directory: %s\n", filePath)
+		fmt.Println()
+		return importCollection("bruno", "Bruno", map[string]interface{}{"files": files})
+	}
+
 	// Read file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -56,11 +94,21 @@ func importFile(cmd *cobra.Command, args []string) error {
 
 	switch format {
 	case "openapi":
+		if !importUseAI {
+			return importParsedFile("openapi", "OpenAPI", data)
+		}
 		endpoint = "/api/v1/ai/import/openapi"
 		reqBody["spec"] = string(data)
+	case "graphql":
+		return importParsedFile("graphql", "GraphQL", data)
 	case "postman":
+		if !importUseAI {
+			return importCollection("postman", "Postman", map[string]interface{}{"content": string(data)})
+		}
 		endpoint = "/api/v1/ai/import/postman"
 		reqBody["collection"] = string(data)
+	case "insomnia":
+		return importCollection("insomnia", "Insomnia", map[string]interface{}{"content": string(data)})
 	case "har":
 		// HAR import would need to be implemented
 		return fmt.Errorf("HAR import not yet implemented")
@@ -122,6 +170,228 @@ func importFile(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// importParsedFile converts an OpenAPI document or GraphQL introspection result through
+// the deterministic importer and writes one YAML file per flow plus an environment file
+func importParsedFile(importType, label string, data []byte) error {
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"type":     importType,
+		"content":  string(data),
+		"group_by": importGroupBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := http.Post(apiURL+"/api/v1/import/parse", "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Title     string                   `json:"title"`
+		Flows     []map[string]interface{} `json:"flows"`
+		Warnings  []string                 `json:"warnings"`
+		Variables []map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	outputDir := importOutput
+	if outputDir == "" {
+		outputDir = "."
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	fmt.Printf("✅ Imported %d flow(s)\n\n", len(result.Flows))
+
+	for _, flow := range result.Flows {
+		name, _ := flow["name"].(string)
+		content, err := yaml.Marshal(map[string]interface{}{"flow": flow})
+		if err != nil {
+			fmt.Printf("   ❌ Failed to encode %s: %v\n", name, err)
+			continue
+		}
+
+		outputPath := filepath.Join(outputDir, sanitizeFilename(name)+".yaml")
+		if err := os.WriteFile(outputPath, content, 0644); err != nil {
+			fmt.Printf("   ❌ Failed to save %s: %v\n", outputPath, err)
+		} else {
+			fmt.Printf("   📄 %s\n", outputPath)
+		}
+	}
+
+	// Environment file compatible with the environments import endpoint
+	envContent, err := json.MarshalIndent(map[string]interface{}{
+		"name":        result.Title,
+		"description": "Imported from " + label,
+		"variables":   result.Variables,
+	}, "", "  ")
+	if err == nil {
+		envPath := filepath.Join(outputDir, sanitizeFilename(result.Title)+".env.json")
+		if err := os.WriteFile(envPath, envContent, 0644); err == nil {
+			fmt.Printf("   🌍 %s\n", envPath)
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Println()
+		fmt.Println("⚠️  Warnings:")
+		for _, w := range result.Warnings {
+			fmt.Printf("   - %s\n", w)
+		}
+	}
+
+	return nil
+}
+
+// importCollection converts a Postman, Insomnia or Bruno collection through the
+// deterministic importer. Flows are written in directories of the folders of
+// the collection, and its variables to an environment file.
+func importCollection(importType, label string, body map[string]interface{}) error {
+	body["type"] = importType
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := http.Post(apiURL+"/api/v1/import/parse", "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Flows      []map[string]interface{} `json:"flows"`
+		Warnings   []string                 `json:"warnings"`
+		Variables  []map[string]interface{} `json:"variables"`
+		Collection importedFolder           `json:"collection"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	outputDir := importOutput
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	fmt.Printf("✅ Imported %d flow(s)\n\n", len(result.Flows))
+
+	var writeFolder func(f importedFolder, dir string)
+	writeFolder = func(f importedFolder, dir string) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Printf("   ❌ Failed to create %s: %v\n", dir, err)
+			return
+		}
+		for _, i := range f.Flows {
+			if i < 0 || i >= len(result.Flows) {
+				continue
+			}
+			flow := result.Flows[i]
+			name, _ := flow["name"].(string)
+			content, err := yaml.Marshal(map[string]interface{}{"flow": flow})
+			if err != nil {
+				fmt.Printf("   ❌ Failed to encode %s: %v\n", name, err)
+				continue
+			}
+			outputPath := filepath.Join(dir, sanitizeFilename(name)+".yaml")
+			if err := os.WriteFile(outputPath, content, 0644); err != nil {
+				fmt.Printf("   ❌ Failed to save %s: %v\n", outputPath, err)
+			} else {
+				fmt.Printf("   📄 %s\n", outputPath)
+			}
+		}
+		for _, child := range f.Folders {
+			writeFolder(child, filepath.Join(dir, sanitizeFilename(child.Name)))
+		}
+	}
+	writeFolder(result.Collection, outputDir)
+
+	if len(result.Variables) > 0 {
+		envContent, err := json.MarshalIndent(map[string]interface{}{
+			"name":        result.Collection.Name,
+			"description": "Imported from " + label,
+			"variables":   result.Variables,
+		}, "", "  ")
+		if err == nil {
+			envPath := filepath.Join(outputDir, sanitizeFilename(result.Collection.Name)+".env.json")
+			if err := os.WriteFile(envPath, envContent, 0644); err == nil {
+				fmt.Printf("   🌍 %s\n", envPath)
+			}
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Println()
+		fmt.Println("⚠️  Warnings:")
+		for _, w := range result.Warnings {
+			fmt.Printf("   - %s\n", w)
+		}
+	}
+
+	return nil
+}
+
+// importedFolder is a folder of an imported collection; flows are indexes
+// into the imported flows
+type importedFolder struct {
+	Name    string           `json:"name"`
+	Flows   []int            `json:"flows"`
+	Folders []importedFolder `json:"folders"`
+}
+
+// readBrunoCollection reads the .bru files and bruno.json of a Bruno
+// collection directory, by slash-separated path
+func readBrunoCollection(dir string) (map[string]string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "bruno.json")); err != nil {
+		return nil, fmt.Errorf("%s is not a Bruno collection: no bruno.json found", dir)
+	}
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".bru" && d.Name() != "bruno.json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Bruno collection: %w", err)
+	}
+	return files, nil
+}
+
 func detectImportFormat(path string, data []byte) string {
 	ext := strings.ToLower(filepath.Ext(path))
 
@@ -143,6 +413,26 @@ func detectImportFormat(path string, data []byte) string {
 				if _, ok := info["_postman_id"]; ok {
 					return "postman"
 				}
+				if schema, _ := info["schema"].(string); strings.Contains(schema, "getpostman.com") {
+					return "postman"
+				}
+			}
+		}
+
+		// Check for Insomnia export
+		if jsonObj["_type"] == "export" {
+			if _, ok := jsonObj["__export_format"]; ok {
+				return "insomnia"
+			}
+		}
+
+		// Check for GraphQL introspection result
+		if _, ok := jsonObj["__schema"]; ok {
+			return "graphql"
+		}
+		if data, ok := jsonObj["data"].(map[string]interface{}); ok {
+			if _, ok := data["__schema"]; ok {
+				return "graphql"
 			}
 		}
 
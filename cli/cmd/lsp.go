@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server for editing flows in editors",
+	Long: `Run a Language Server Protocol (LSP) server for flow files, talking over
+stdin and stdout. Editors start it themselves; see docs/features/LANGUAGE_SERVER.md
+for editor setups.
+
+Features:
+  Completion         Actions, config fields of the step's action, step fields,
+                     step IDs and outputs in ${step.output} references, and
+                     environment and built-in variables
+  Hover              Action docs with their config fields, config fields,
+                     and the steps and variables of references
+  Go to definition   Steps of ${step.output} references, variables, and the
+                     flow files of run_flow steps
+  Diagnostics        Validation on open and save, like testmesh validate
+  Code actions       Extract the selected steps into a sub-flow
+
+Actions of server plugins are completed when the server can be reached.
+Otherwise the built-in actions are completed, and flows are checked locally.
+
+Examples:
+  testmesh lsp
+  testmesh lsp --api-url http://testmesh.internal:5016`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+	// Editors commonly pass --stdio, which is the only transport
+	lspCmd.Flags().Bool("stdio", true, "Talk LSP over stdin and stdout")
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	return lsp.Serve(os.Stdin, os.Stdout, lsp.NewClient(apiURL, workspaceID))
+}
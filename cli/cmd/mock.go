@@ -60,43 +60,59 @@ type MockResponse struct {
 }
 
 type MockConfig struct {
+	Name      string         `yaml:"name,omitempty"`
+	Port      int            `yaml:"port,omitempty"`
 	Endpoints []MockEndpoint `yaml:"endpoints"`
 }
 
-func startMockServer(cmd *cobra.Command, args []string) error {
-	configPath := args[0]
-
-	// Read config
-	data, err := os.ReadFile(configPath)
+// loadMockConfig reads an endpoints file, or a mock definition with the
+// endpoints under mock_server
+func loadMockConfig(path string) (*MockConfig, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
+		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
-	var config MockConfig
+	var config struct {
+		MockConfig `yaml:",inline"`
+		MockServer *MockConfig `yaml:"mock_server"`
+	}
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
-
-	fmt.Printf("🚀 Starting mock server on port %d\n", mockPort)
-	fmt.Printf("   Config: %s\n", configPath)
-	fmt.Printf("   Endpoints: %d\n", len(config.Endpoints))
-	fmt.Println()
-
-	// Print endpoints
-	for _, ep := range config.Endpoints {
-		fmt.Printf("   %s %s\n", ep.Method, ep.Path)
+	if config.MockServer != nil {
+		return config.MockServer, nil
 	}
-	fmt.Println()
-	fmt.Println("Press Ctrl+C to stop")
+	return &config.MockConfig, nil
+}
 
-	// Create router
+// newMockHandler serves the responses of mock endpoints, logging requests
+// when logRequests is set
+func newMockHandler(endpoints []MockEndpoint, logRequests bool) http.Handler {
 	mux := http.NewServeMux()
 
-	// Register endpoints
-	for _, endpoint := range config.Endpoints {
-		ep := endpoint // Capture for closure
-		mux.HandleFunc(ep.Path, func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != ep.Method && ep.Method != "" {
+	// Register each path once; endpoints sharing a path are chosen by method,
+	// the first one winning
+	byPath := make(map[string][]MockEndpoint)
+	var paths []string
+	for _, ep := range endpoints {
+		if _, ok := byPath[ep.Path]; !ok {
+			paths = append(paths, ep.Path)
+		}
+		byPath[ep.Path] = append(byPath[ep.Path], ep)
+	}
+
+	for _, path := range paths {
+		candidates := byPath[path] // Capture for closure
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			var ep *MockEndpoint
+			for i := range candidates {
+				if candidates[i].Method == "" || candidates[i].Method == r.Method {
+					ep = &candidates[i]
+					break
+				}
+			}
+			if ep == nil {
 				w.WriteHeader(http.StatusMethodNotAllowed)
 				return
 			}
@@ -120,14 +136,39 @@ func startMockServer(cmd *cobra.Command, args []string) error {
 				w.Write(body)
 			}
 
-			fmt.Printf("← %s %s → %d\n", r.Method, r.URL.Path, ep.Response.Status)
+			if logRequests {
+				fmt.Printf("← %s %s → %d\n", r.Method, r.URL.Path, ep.Response.Status)
+			}
 		})
 	}
+	return mux
+}
+
+func startMockServer(cmd *cobra.Command, args []string) error {
+	configPath := args[0]
+
+	// Read config
+	config, err := loadMockConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🚀 Starting mock server on port %d\n", mockPort)
+	fmt.Printf("   Config: %s\n", configPath)
+	fmt.Printf("   Endpoints: %d\n", len(config.Endpoints))
+	fmt.Println()
+
+	// Print endpoints
+	for _, ep := range config.Endpoints {
+		fmt.Printf("   %s %s\n", ep.Method, ep.Path)
+	}
+	fmt.Println()
+	fmt.Println("Press Ctrl+C to stop")
 
 	// Start server
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", mockPort),
-		Handler: mux,
+		Handler: newMockHandler(config.Endpoints, true),
 	}
 
 	// Handle shutdown
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorName         string
+	monitorInterval     string
+	monitorLocations    []string
+	monitorAvailability float64
+	monitorLatency      float64
+	monitorPercentile   float64
+	monitorStep         string
+	monitorWindow       string
+	monitorPendingFor   string
+	monitorRepeat       string
+	monitorWebhook      string
+	monitorPublic       bool
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Manage synthetic monitors",
+	Long: `Create and inspect synthetic monitors.
+
+A monitor runs a flow on a short interval, evaluates its SLOs over rolling
+windows and alerts when they are breached.
+
+Examples:
+  testmesh monitor list
+  testmesh monitor create <flow-id> --name checkout --interval 1m --availability 99.9
+  testmesh monitor create <flow-id> --name login --latency 800 --step login --window 1h --pending-for 5m
+  testmesh monitor status <monitor-id>
+  testmesh monitor check <monitor-id>`,
+}
+
+var monitorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List monitors",
+	RunE:  listMonitors,
+}
+
+var monitorCreateCmd = &cobra.Command{
+	Use:   "create <flow-id>",
+	Short: "Create a monitor of a flow",
+	Args:  cobra.ExactArgs(1),
+	RunE:  createMonitor,
+}
+
+var monitorStatusCmd = &cobra.Command{
+	Use:   "status <monitor-id>",
+	Short: "Show the SLOs and alert state of a monitor",
+	Args:  cobra.ExactArgs(1),
+	RunE:  showMonitorStatus,
+}
+
+var monitorPauseCmd = &cobra.Command{
+	Use:   "pause <monitor-id>",
+	Short: "Pause the checks of a monitor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postMonitorAction(args[0], "pause", "⏸️  Monitor paused")
+	},
+}
+
+var monitorResumeCmd = &cobra.Command{
+	Use:   "resume <monitor-id>",
+	Short: "Resume the checks of a paused monitor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postMonitorAction(args[0], "resume", "▶️  Monitor resumed")
+	},
+}
+
+var monitorCheckCmd = &cobra.Command{
+	Use:   "check <monitor-id>",
+	Short: "Run a check of a monitor now",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postMonitorAction(args[0], "check", "🚀 Check started")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+	monitorCmd.AddCommand(monitorListCmd)
+	monitorCmd.AddCommand(monitorCreateCmd)
+	monitorCmd.AddCommand(monitorStatusCmd)
+	monitorCmd.AddCommand(monitorPauseCmd)
+	monitorCmd.AddCommand(monitorResumeCmd)
+	monitorCmd.AddCommand(monitorCheckCmd)
+
+	monitorCreateCmd.Flags().StringVar(&monitorName, "name", "", "Monitor name (required)")
+	monitorCreateCmd.Flags().StringVar(&monitorInterval, "interval", "1m", "Interval between checks (at least 10s)")
+	monitorCreateCmd.Flags().StringSliceVar(&monitorLocations, "location", nil, "Locations to check from")
+	monitorCreateCmd.Flags().Float64Var(&monitorAvailability, "availability", 0, "Availability SLO in percent, e.g. 99.9")
+	monitorCreateCmd.Flags().Float64Var(&monitorLatency, "latency", 0, "Latency SLO in milliseconds")
+	monitorCreateCmd.Flags().Float64Var(&monitorPercentile, "percentile", 95, "Percentile of the latency SLO")
+	monitorCreateCmd.Flags().StringVar(&monitorStep, "step", "", "Measure the latency SLO on this step instead of the whole flow")
+	monitorCreateCmd.Flags().StringVar(&monitorWindow, "window", "24h", "Rolling window of the SLOs, e.g. 1h, 24h, 30d")
+	monitorCreateCmd.Flags().StringVar(&monitorPendingFor, "pending-for", "", "How long an SLO must stay breached before alerting")
+	monitorCreateCmd.Flags().StringVar(&monitorRepeat, "repeat", "", "Notify again this often while an alert fires")
+	monitorCreateCmd.Flags().StringVar(&monitorWebhook, "webhook", "", "Slack-compatible webhook URL for alert notifications")
+	monitorCreateCmd.Flags().BoolVar(&monitorPublic, "public", false, "Show the monitor on the workspace status page")
+	monitorCreateCmd.MarkFlagRequired("name")
+}
+
+type Monitor struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Status          string     `json:"status"`
+	Interval        string     `json:"interval"`
+	AlertState      string     `json:"alert_state"`
+	AlertSince      *time.Time `json:"alert_since"`
+	LastCheckAt     *time.Time `json:"last_check_at"`
+	LastCheckResult string     `json:"last_check_result"`
+	Public          bool       `json:"public"`
+	SLOStatus       []struct {
+		Name        string   `json:"name"`
+		Type        string   `json:"type"`
+		Objective   float64  `json:"objective"`
+		Window      string   `json:"window"`
+		Actual      *float64 `json:"actual"`
+		Samples     int64    `json:"samples"`
+		Met         bool     `json:"met"`
+		ErrorBudget *float64 `json:"error_budget"`
+	} `json:"slo_status"`
+}
+
+func listMonitors(cmd *cobra.Command, args []string) error {
+	fmt.Println("📡 Monitors")
+	fmt.Println()
+
+	resp, err := http.Get(workspaceEndpoint("/monitors"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Monitors []Monitor `json:"monitors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Monitors) == 0 {
+		fmt.Println("No monitors found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-25s %-8s %-8s %-9s %-20s\n", "ID", "NAME", "STATUS", "EVERY", "ALERT", "LAST CHECK")
+	fmt.Println(strings.Repeat("-", 112))
+
+	for _, m := range result.Monitors {
+		lastCheck := "-"
+		if m.LastCheckAt != nil {
+			lastCheck = fmt.Sprintf("%s %s", m.LastCheckAt.Local().Format("01-02 15:04:05"), m.LastCheckResult)
+		}
+		fmt.Printf("%-36s  %-25s %-8s %-8s %-9s %-20s\n",
+			m.ID, truncate(m.Name, 25), m.Status, m.Interval, m.AlertState, lastCheck)
+	}
+
+	return nil
+}
+
+func createMonitor(cmd *cobra.Command, args []string) error {
+	var slos []map[string]interface{}
+	if monitorAvailability > 0 {
+		slos = append(slos, map[string]interface{}{
+			"type":      "availability",
+			"objective": monitorAvailability,
+			"window":    monitorWindow,
+		})
+	}
+	if monitorLatency > 0 {
+		slos = append(slos, map[string]interface{}{
+			"type":       "latency",
+			"objective":  monitorLatency,
+			"percentile": monitorPercentile,
+			"step_id":    monitorStep,
+			"window":     monitorWindow,
+		})
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"name":               monitorName,
+		"flow_id":            args[0],
+		"interval":           monitorInterval,
+		"locations":          monitorLocations,
+		"slos":               slos,
+		"pending_for":        monitorPendingFor,
+		"repeat_interval":    monitorRepeat,
+		"notify_webhook_url": monitorWebhook,
+		"public":             monitorPublic,
+	})
+
+	resp, err := http.Post(workspaceEndpoint("/monitors"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result Monitor
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("📡 Monitor created (ID: %s), checking every %s\n", result.ID, result.Interval)
+	return nil
+}
+
+func showMonitorStatus(cmd *cobra.Command, args []string) error {
+	resp, err := http.Get(workspaceEndpoint("/monitors/" + args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var m Monitor
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("📡 %s\n", m.Name)
+	fmt.Println()
+	fmt.Printf("Status:     %s (every %s)\n", m.Status, m.Interval)
+	alert := m.AlertState
+	if m.AlertSince != nil {
+		alert += " since " + m.AlertSince.Local().Format("2006-01-02 15:04:05")
+	}
+	fmt.Printf("Alert:      %s\n", alert)
+	if m.LastCheckAt != nil {
+		fmt.Printf("Last check: %s (%s)\n", m.LastCheckAt.Local().Format("2006-01-02 15:04:05"), m.LastCheckResult)
+	}
+
+	if len(m.SLOStatus) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%-35s %-10s %-10s %-8s %-8s %s\n", "SLO", "OBJECTIVE", "ACTUAL", "SAMPLES", "BUDGET", "MET")
+	fmt.Println(strings.Repeat("-", 85))
+	for _, s := range m.SLOStatus {
+		unit := "ms"
+		if s.Type == "availability" {
+			unit = "%"
+		}
+		actual := "-"
+		if s.Actual != nil {
+			actual = fmt.Sprintf("%.2f%s", *s.Actual, unit)
+		}
+		budget := "-"
+		if s.ErrorBudget != nil {
+			budget = fmt.Sprintf("%.0f%%", *s.ErrorBudget*100)
+		}
+		met := "✅"
+		if !s.Met {
+			met = "❌"
+		}
+		fmt.Printf("%-35s %-10s %-10s %-8d %-8s %s\n",
+			truncate(s.Name, 35), fmt.Sprintf("%g%s", s.Objective, unit), actual, s.Samples, budget, met)
+	}
+
+	return nil
+}
+
+// postMonitorAction posts to an action endpoint of a monitor
+func postMonitorAction(id, action, message string) error {
+	resp, err := http.Post(workspaceEndpoint("/monitors/"+id+"/"+action), "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	fmt.Println(message)
+	return nil
+}
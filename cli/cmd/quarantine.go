@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	quarantineStatus string
+	quarantineStep   string
+	quarantineReason string
+)
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Manage quarantined flaky flows and steps",
+	Long: `List, add and release quarantines of flaky flows and steps.
+
+Quarantined flows and steps still run, but their failures do not fail
+schedules, CI runs or the exit code of "testmesh run".
+
+Examples:
+  testmesh quarantine list
+  testmesh quarantine add <flow-id> --step login --reason "times out on CI"
+  testmesh quarantine release <quarantine-id>`,
+}
+
+var quarantineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List quarantines",
+	RunE:  listQuarantines,
+}
+
+var quarantineAddCmd = &cobra.Command{
+	Use:   "add <flow-id>",
+	Short: "Quarantine a flow or one of its steps",
+	Args:  cobra.ExactArgs(1),
+	RunE:  addQuarantine,
+}
+
+var quarantineReleaseCmd = &cobra.Command{
+	Use:   "release <quarantine-id>",
+	Short: "Release a quarantine",
+	Args:  cobra.ExactArgs(1),
+	RunE:  releaseQuarantine,
+}
+
+func init() {
+	rootCmd.AddCommand(quarantineCmd)
+	quarantineCmd.AddCommand(quarantineListCmd)
+	quarantineCmd.AddCommand(quarantineAddCmd)
+	quarantineCmd.AddCommand(quarantineReleaseCmd)
+
+	quarantineListCmd.Flags().StringVar(&quarantineStatus, "status", "active", "Filter by status (active, released, all)")
+	quarantineAddCmd.Flags().StringVar(&quarantineStep, "step", "", "Quarantine only this step of the flow")
+	quarantineAddCmd.Flags().StringVar(&quarantineReason, "reason", "", "Why the flow is quarantined")
+	quarantineReleaseCmd.Flags().StringVar(&quarantineReason, "reason", "", "Why the quarantine is released")
+}
+
+type Quarantine struct {
+	ID             string    `json:"id"`
+	FlowID         string    `json:"flow_id"`
+	StepID         string    `json:"step_id"`
+	Status         string    `json:"status"`
+	Source         string    `json:"source"`
+	Reason         string    `json:"reason"`
+	FlakinessScore float64   `json:"flakiness_score"`
+	CleanRuns      int       `json:"clean_runs"`
+	CreatedAt      time.Time `json:"created_at"`
+	Flow           *struct {
+		Name string `json:"name"`
+	} `json:"flow,omitempty"`
+}
+
+// FlowName returns the name of the quarantined flow
+func (q Quarantine) FlowName() string {
+	if q.Flow != nil {
+		return q.Flow.Name
+	}
+	return q.FlowID
+}
+
+func listQuarantines(cmd *cobra.Command, args []string) error {
+	fmt.Println("🧪 Quarantine")
+	fmt.Println()
+
+	quarantines, err := fetchQuarantines(quarantineStatus)
+	if err != nil {
+		return err
+	}
+
+	if len(quarantines) == 0 {
+		fmt.Println("No quarantines found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-25s %-15s %-8s %-6s %-10s\n", "ID", "FLOW", "STEP", "SOURCE", "CLEAN", "STATUS")
+	fmt.Println(strings.Repeat("-", 108))
+
+	for _, q := range quarantines {
+		step := q.StepID
+		if step == "" {
+			step = "(all)"
+		}
+		fmt.Printf("%-36s  %-25s %-15s %-8s %-6d %-10s\n",
+			q.ID, truncate(q.FlowName(), 25), truncate(step, 15), q.Source, q.CleanRuns, q.Status)
+	}
+
+	return nil
+}
+
+func addQuarantine(cmd *cobra.Command, args []string) error {
+	payload, _ := json.Marshal(map[string]string{
+		"flow_id": args[0],
+		"step_id": quarantineStep,
+		"reason":  quarantineReason,
+	})
+
+	resp, err := http.Post(workspaceEndpoint("/quarantine"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result Quarantine
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("🧪 Quarantined (ID: %s)\n", result.ID)
+	return nil
+}
+
+func releaseQuarantine(cmd *cobra.Command, args []string) error {
+	payload, _ := json.Marshal(map[string]string{"reason": quarantineReason})
+
+	req, err := http.NewRequest(http.MethodDelete, workspaceEndpoint("/quarantine/"+args[0]), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	fmt.Println("✅ Quarantine released")
+	return nil
+}
+
+func fetchQuarantines(status string) ([]Quarantine, error) {
+	resp, err := http.Get(workspaceEndpoint("/quarantine?status=" + status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Quarantines []Quarantine `json:"quarantines"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Quarantines, nil
+}
+
+// quarantinedSteps looks up the active quarantines of a flow by name. It reports
+// whether the whole flow is quarantined and which of its steps are.
+func quarantinedSteps(flowName string) (bool, map[string]bool, error) {
+	quarantines, err := fetchQuarantines("active")
+	if err != nil {
+		return false, nil, err
+	}
+
+	steps := make(map[string]bool)
+	for _, q := range quarantines {
+		if q.Flow == nil || q.Flow.Name != flowName {
+			continue
+		}
+		if q.StepID == "" {
+			return true, steps, nil
+		}
+		steps[q.StepID] = true
+	}
+	return false, steps, nil
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,6 +21,7 @@ var (
 	reportOutput   string
 	reportTitle    string
 	reportTemplate string
+	reportSchedule string
 )
 
 var reportCmd = &cobra.Command{
@@ -30,11 +34,19 @@ Supported formats:
 - json: Machine-readable JSON
 - markdown: GitHub-compatible Markdown
 - junit: JUnit XML for CI integration
+- allure: Allure result files, written to a directory (default allure-results)
+- ctrf: Common Test Report Format JSON
+- tap: Test Anything Protocol
+
+Allure, CTRF, TAP and the formats of reporter plugins are rendered by the
+server. With --schedule they cover a whole schedule run.
 
 Examples:
   testmesh report abc123 --format html -o report.html
   testmesh report abc123 --format junit -o results.xml
-  testmesh report latest --format markdown`,
+  testmesh report latest --format markdown
+  testmesh report abc123 --format allure -o allure-results
+  testmesh report --schedule nightly-id run-id --format ctrf -o ctrf-report.json`,
 	Args: cobra.ExactArgs(1),
 	RunE: generateReport,
 }
@@ -49,15 +61,25 @@ func init() {
 	rootCmd.AddCommand(reportCmd)
 	reportCmd.AddCommand(reportListCmd)
 
-	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Report format (html, json, markdown, junit)")
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Report format (html, json, markdown, junit, allure, ctrf, tap)")
 	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output file path")
 	reportCmd.Flags().StringVarP(&reportTitle, "title", "t", "", "Custom report title")
 	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "Custom template file")
+	reportCmd.Flags().StringVar(&reportSchedule, "schedule", "", "Export a run of this schedule instead of an execution")
 }
 
 func generateReport(cmd *cobra.Command, args []string) error {
 	executionID := args[0]
 
+	switch strings.ToLower(reportFormat) {
+	case "html", "json", "markdown", "junit":
+		if reportSchedule != "" {
+			return fmt.Errorf("--schedule is not supported with %s reports", reportFormat)
+		}
+	default:
+		return exportReport(executionID, strings.ToLower(reportFormat))
+	}
+
 	fmt.Printf("📊 Generating %s report...\n", reportFormat)
 	fmt.Printf("   Execution: %s\n", executionID)
 	fmt.Println()
@@ -300,6 +322,90 @@ func generateJUnitReport(exec ExecutionResult) []byte {
 	return []byte(sb.String())
 }
 
+// exportReport downloads a run rendered by the server in a result format
+func exportReport(id, format string) error {
+	endpoint := workspaceEndpoint("/executions/" + id + "/export")
+	if reportSchedule != "" {
+		endpoint = fmt.Sprintf("%s/api/v1/schedules/%s/runs/%s/export", apiURL, reportSchedule, id)
+	}
+
+	fmt.Printf("📊 Exporting %s report...\n", format)
+	fmt.Printf("   Run: %s\n", id)
+	fmt.Println()
+
+	resp, err := http.Get(endpoint + "?format=" + url.QueryEscape(format))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server error: %s", string(content))
+	}
+
+	// Allure results are a directory of files, sent as a zip archive
+	if resp.Header.Get("Content-Type") == "application/zip" && !strings.HasSuffix(reportOutput, ".zip") {
+		dir := reportOutput
+		if dir == "" {
+			dir = format + "-results"
+		}
+		count, err := extractZip(content, dir)
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("✅ %d result files saved to %s\n", count, dir)
+		return nil
+	}
+
+	if reportOutput != "" {
+		if err := os.WriteFile(reportOutput, content, 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("✅ Report saved to %s\n", reportOutput)
+	} else {
+		fmt.Println(string(content))
+	}
+	return nil
+}
+
+// extractZip writes the files of a zip archive into dir
+func extractZip(content []byte, dir string) (int, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, file := range archive.File {
+		// Result files are flat; anything else would escape the directory
+		name := filepath.Base(file.Name)
+		if file.FileInfo().IsDir() || name != file.Name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return 0, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return 0, err
+		}
+		written++
+	}
+	return written, nil
+}
+
 func listExecutions(cmd *cobra.Command, args []string) error {
 	fmt.Println("📋 Recent Executions")
 	fmt.Println()
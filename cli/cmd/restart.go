@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	restartStep    string
+	restartVars    map[string]string
+	restartOutputs map[string]string
+)
+
+var restartCmd = &cobra.Command{
+	Use:   "restart <execution-id>",
+	Short: "Run an execution again from one of its steps",
+	Long: `Run an execution again from one of its top-level steps, with the variables
+and step outputs recorded before that step. Earlier steps are not run again.
+
+Only executions run with context recording have recorded steps. Without
+--step, the recorded steps are listed.
+
+Variables and step outputs can be changed for the new execution. Values of
+step outputs are parsed as JSON and otherwise used as strings.
+
+Examples:
+  testmesh restart <execution-id>
+  testmesh restart <execution-id> --step create_order
+  testmesh restart <execution-id> --step create_order --var user_id=43
+  testmesh restart <execution-id> --step create_order --output login.token=abc`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestart,
+}
+
+func init() {
+	rootCmd.AddCommand(restartCmd)
+	restartCmd.Flags().StringVar(&restartStep, "step", "", "Top-level step to restart from")
+	restartCmd.Flags().StringToStringVar(&restartVars, "var", nil, "Change a variable (name=value)")
+	restartCmd.Flags().StringToStringVar(&restartOutputs, "output", nil, "Change a step output (step.output=value)")
+}
+
+type ContextSnapshot struct {
+	Sequence     int    `json:"sequence"`
+	Phase        string `json:"phase"`
+	StepID       string `json:"step_id"`
+	StepName     string `json:"step_name"`
+	Action       string `json:"action"`
+	ParentStepID string `json:"parent_step_id"`
+}
+
+func runRestart(cmd *cobra.Command, args []string) error {
+	executionID := args[0]
+	if restartStep == "" {
+		return listRecordedSteps(executionID)
+	}
+
+	outputs := make(map[string]map[string]interface{})
+	for key, raw := range restartOutputs {
+		stepID, name, ok := strings.Cut(key, ".")
+		if !ok || stepID == "" || name == "" {
+			return fmt.Errorf("invalid step output %q, expected step.output=value", key)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		if outputs[stepID] == nil {
+			outputs[stepID] = make(map[string]interface{})
+		}
+		outputs[stepID][name] = value
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"step_id":      restartStep,
+		"variables":    restartVars,
+		"step_outputs": outputs,
+	})
+
+	resp, err := http.Post(workspaceEndpoint("/executions/"+executionID+"/restart"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var execution struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&execution); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("🔁 Restarted from %s (execution ID: %s)\n", restartStep, execution.ID)
+	return nil
+}
+
+func listRecordedSteps(executionID string) error {
+	resp, err := http.Get(workspaceEndpoint("/executions/" + executionID + "/context"))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Snapshots []ContextSnapshot `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var steps []ContextSnapshot
+	for _, snapshot := range result.Snapshots {
+		// Only top-level steps can be restarted from
+		if snapshot.ParentStepID == "" {
+			steps = append(steps, snapshot)
+		}
+	}
+	if len(steps) == 0 {
+		fmt.Println("No steps recorded; restarts need an execution run with context recording")
+		return nil
+	}
+
+	fmt.Printf("%-4s %-10s %-25s %-25s %-15s\n", "#", "PHASE", "STEP", "NAME", "ACTION")
+	fmt.Println(strings.Repeat("-", 82))
+	for _, step := range steps {
+		fmt.Printf("%-4d %-10s %-25s %-25s %-15s\n",
+			step.Sequence, step.Phase, truncate(step.StepID, 25), truncate(step.StepName, 25), step.Action)
+	}
+	fmt.Println()
+	fmt.Println("Restart with: testmesh restart " + executionID + " --step <step>")
+	return nil
+}
@@ -8,11 +8,15 @@ import (
 )
 
 var (
-	cfgFile string
-	apiURL  string
-	verbose bool
+	cfgFile     string
+	apiURL      string
+	workspaceID string
+	verbose     bool
 )
 
+// defaultWorkspaceID is the workspace every server creates on first start
+const defaultWorkspaceID = "00000000-0000-0000-0000-000000000001"
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "testmesh",
@@ -36,9 +40,15 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .testmesh.yaml)")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "http://localhost:5016", "TestMesh API URL")
+	rootCmd.PersistentFlags().StringVar(&workspaceID, "workspace", defaultWorkspaceID, "Workspace ID for workspace-scoped commands")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 }
 
+// workspaceEndpoint returns the URL of a workspace-scoped API path
+func workspaceEndpoint(path string) string {
+	return apiURL + "/api/v1/workspaces/" + workspaceID + path
+}
+
 func initConfig() {
 	// Load config from .testmesh.yaml if it exists
 	if cfgFile != "" {
@@ -9,15 +9,33 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var runEnv string
+var (
+	runEnv          string
+	runNoQuarantine bool
+	runRemote       bool
+	runFollow       bool
+	runVars         map[string]string
+)
 
 var runCmd = &cobra.Command{
 	Use:   "run <flow.yaml>",
-	Short: "Execute a flow locally",
+	Short: "Execute a flow locally or on the server",
 	Long: `Execute a test flow defined in a YAML file.
 
 The flow will be executed locally without connecting to a server.
-Use --env to specify the environment (default: development).`,
+Use --env to specify the environment (default: development).
+
+Failures of flows and steps quarantined on the server are reported but do
+not fail the run. Use --no-quarantine to skip the lookup.
+
+With --remote, the flow is uploaded to the server and executed there. Add
+--follow to stream its progress until it finishes; the command then fails
+when the execution fails.
+
+Examples:
+  testmesh run flows/login.yaml
+  testmesh run flows/login.yaml --remote
+  testmesh run flows/login.yaml --remote --follow --var user=alice`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFlow,
 }
@@ -25,6 +43,10 @@ Use --env to specify the environment (default: development).`,
 func init() {
 	rootCmd.AddCommand(runCmd)
 	runCmd.Flags().StringVarP(&runEnv, "env", "e", "development", "Environment name")
+	runCmd.Flags().BoolVar(&runNoQuarantine, "no-quarantine", false, "Fail on quarantined flows and steps")
+	runCmd.Flags().BoolVar(&runRemote, "remote", false, "Execute the flow on the server")
+	runCmd.Flags().BoolVarP(&runFollow, "follow", "f", false, "Stream the progress of a remote execution until it finishes")
+	runCmd.Flags().StringToStringVar(&runVars, "var", nil, "Set a variable of a remote execution (name=value)")
 }
 
 func runFlow(cmd *cobra.Command, args []string) error {
@@ -57,6 +79,13 @@ func runFlow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("flow name is required")
 	}
 
+	if runFollow && !runRemote {
+		return fmt.Errorf("--follow requires --remote")
+	}
+	if runRemote {
+		return runRemoteFlow(flow.Name, data)
+	}
+
 	// Print header
 	fmt.Println()
 	fmt.Printf("🚀 Running flow: %s\n", flow.Name)
@@ -64,20 +93,45 @@ func runFlow(cmd *cobra.Command, args []string) error {
 		fmt.Printf("   %s\n", flow.Description)
 	}
 	fmt.Printf("   Environment: %s\n", runEnv)
+
+	// Quarantine is best effort; without a server every failure counts
+	var flowQuarantined bool
+	quarantined := map[string]bool{}
+	if !runNoQuarantine {
+		var err error
+		flowQuarantined, quarantined, err = quarantinedSteps(flow.Name)
+		if err != nil && verbose {
+			fmt.Printf("   ⚠️  Could not look up quarantined steps: %v\n", err)
+		}
+		if flowQuarantined {
+			fmt.Println("   🧪 Flow is quarantined, failures will not fail the run")
+		}
+	}
 	fmt.Println()
 
 	startTime := time.Now()
 	totalSteps := len(flow.Setup) + len(flow.Steps) + len(flow.Teardown)
 	passedSteps := 0
 	failedSteps := 0
+	quarantinedFailures := 0
+
+	recordFailure := func(step map[string]interface{}, index int, err error) {
+		id, _ := step["id"].(string)
+		if flowQuarantined || quarantined[id] {
+			quarantinedFailures++
+			fmt.Printf("   ⚠️  Step %d failed in quarantine: %v\n", index+1, err)
+			return
+		}
+		failedSteps++
+		fmt.Printf("   ❌ Step %d failed: %v\n", index+1, err)
+	}
 
 	// Execute setup steps
 	if len(flow.Setup) > 0 {
 		fmt.Println("📋 Setup")
 		for i, step := range flow.Setup {
 			if err := executeStep(step, i, "setup"); err != nil {
-				failedSteps++
-				fmt.Printf("   ❌ Step %d failed: %v\n", i+1, err)
+				recordFailure(step, i, err)
 			} else {
 				passedSteps++
 				fmt.Printf("   ✅ Step %d completed\n", i+1)
@@ -90,8 +144,7 @@ func runFlow(cmd *cobra.Command, args []string) error {
 	fmt.Println("🔄 Steps")
 	for i, step := range flow.Steps {
 		if err := executeStep(step, i, "main"); err != nil {
-			failedSteps++
-			fmt.Printf("   ❌ Step %d failed: %v\n", i+1, err)
+			recordFailure(step, i, err)
 		} else {
 			passedSteps++
 			fmt.Printf("   ✅ Step %d completed\n", i+1)
@@ -104,8 +157,7 @@ func runFlow(cmd *cobra.Command, args []string) error {
 		fmt.Println("🧹 Teardown")
 		for i, step := range flow.Teardown {
 			if err := executeStep(step, i, "teardown"); err != nil {
-				failedSteps++
-				fmt.Printf("   ❌ Step %d failed: %v\n", i+1, err)
+				recordFailure(step, i, err)
 			} else {
 				passedSteps++
 				fmt.Printf("   ✅ Step %d completed\n", i+1)
@@ -118,7 +170,9 @@ func runFlow(cmd *cobra.Command, args []string) error {
 
 	// Print summary
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	if failedSteps == 0 {
+	if failedSteps == 0 && quarantinedFailures > 0 {
+		fmt.Printf("⚠️  Flow completed with quarantined failures in %s\n", duration.Round(time.Millisecond))
+	} else if failedSteps == 0 {
 		fmt.Printf("✅ Flow completed successfully in %s\n", duration.Round(time.Millisecond))
 	} else {
 		fmt.Printf("❌ Flow completed with failures in %s\n", duration.Round(time.Millisecond))
@@ -126,6 +180,9 @@ func runFlow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("   Total steps: %d\n", totalSteps)
 	fmt.Printf("   Passed: %d\n", passedSteps)
 	fmt.Printf("   Failed: %d\n", failedSteps)
+	if quarantinedFailures > 0 {
+		fmt.Printf("   Failed in quarantine: %d\n", quarantinedFailures)
+	}
 	fmt.Println()
 
 	if failedSteps > 0 {
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// followRetries is how many times in a row a dropped stream is reconnected
+const followRetries = 5
+
+// streamEvent is an event of an execution stream
+type streamEvent struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// remoteExecution is the execution summary sent when a stream ends
+type remoteExecution struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	DurationMs  int64  `json:"duration_ms"`
+	TotalSteps  int    `json:"total_steps"`
+	PassedSteps int    `json:"passed_steps"`
+	FailedSteps int    `json:"failed_steps"`
+	Error       string `json:"error"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+// runRemoteFlow starts a flow file on the server and, with --follow, streams
+// its progress until it finishes
+func runRemoteFlow(name string, data []byte) error {
+	executionID, err := startRemoteExecution(data, runEnv, runVars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("🚀 Running flow on the server: %s\n", name)
+	fmt.Printf("   Environment: %s\n", runEnv)
+	fmt.Printf("   Execution ID: %s\n", executionID)
+	fmt.Println()
+
+	if !runFollow {
+		return nil
+	}
+	return followExecution(executionID)
+}
+
+// startRemoteExecution starts a flow file on the server as an ad-hoc flow,
+// leaving the workspace flow with its name untouched, and returns the
+// execution ID
+func startRemoteExecution(data []byte, environment string, variables map[string]string) (string, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"yaml":        string(data),
+		"environment": environment,
+		"variables":   variables,
+	})
+	resp, err := http.Post(workspaceEndpoint("/executions"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("server error: %s", string(body))
+	}
+
+	var execution remoteExecution
+	if err := json.NewDecoder(resp.Body).Decode(&execution); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return execution.ID, nil
+}
+
+// followExecution prints the events of an execution as they arrive and
+// returns an error when it fails. Dropped streams are resumed from the last
+// event received.
+func followExecution(executionID string) error {
+	var (
+		lastEventID string
+		phase       string
+		failures    int
+	)
+
+	for {
+		var result *remoteExecution
+		received := false
+		err := readStream(workspaceEndpoint("/executions/"+executionID+"/stream"), lastEventID, func(event streamEvent) error {
+			received = true
+			if event.ID != "" {
+				lastEventID = event.ID
+			}
+			if event.Name == "end" {
+				var end struct {
+					Execution remoteExecution `json:"execution"`
+				}
+				if err := json.Unmarshal(event.Data, &end); err != nil {
+					return fmt.Errorf("failed to parse response: %w", err)
+				}
+				result = &end.Execution
+				return io.EOF
+			}
+			printStreamEvent(event, &phase)
+			return nil
+		})
+		if result != nil {
+			return printRemoteSummary(result)
+		}
+
+		if received {
+			failures = 0
+		}
+		failures++
+		if failures > followRetries {
+			if err == nil {
+				err = fmt.Errorf("stream closed")
+			}
+			return fmt.Errorf("lost the execution stream: %w", err)
+		}
+		if verbose {
+			fmt.Printf("   ⚠️  Stream interrupted, reconnecting: %v\n", err)
+		}
+		time.Sleep(time.Duration(failures) * time.Second)
+	}
+}
+
+// readStream reads Server-Sent Events until the stream ends or handle
+// returns an error
+func readStream(endpoint, lastEventID string, handle func(streamEvent) error) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var event streamEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends an event
+			if event.Name != "" || len(event.Data) > 0 {
+				if err := handle(event); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+			}
+			event = streamEvent{}
+		case strings.HasPrefix(line, ":"):
+			// Heartbeat comment
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				event.ID = value
+			case "event":
+				event.Name = value
+			case "data":
+				if len(event.Data) > 0 {
+					event.Data = append(event.Data, '\n')
+				}
+				event.Data = append(event.Data, value...)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// printStreamEvent prints the progress of a step event, with a header
+// whenever the phase changes
+func printStreamEvent(event streamEvent, phase *string) {
+	var payload struct {
+		Type string                 `json:"type"`
+		Data map[string]interface{} `json:"data"`
+	}
+	if event.Name == "log" {
+		if verbose {
+			var entry struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(event.Data, &entry) == nil {
+				fmt.Printf("      %s\n", entry.Message)
+			}
+		}
+		return
+	}
+	if err := json.Unmarshal(event.Data, &payload); err != nil {
+		return
+	}
+
+	str := func(key string) string {
+		value, _ := payload.Data[key].(string)
+		return value
+	}
+	stepName := func() string {
+		if name := str("step_name"); name != "" {
+			return name
+		}
+		return str("step_id")
+	}
+
+	switch payload.Type {
+	case "step.started":
+		if p := str("phase"); p != "" && p != *phase {
+			if *phase != "" {
+				fmt.Println()
+			}
+			*phase = p
+			switch p {
+			case "setup":
+				fmt.Println("📋 Setup")
+			case "teardown":
+				fmt.Println("🧹 Teardown")
+			default:
+				fmt.Println("🔄 Steps")
+			}
+		}
+	case "step.completed":
+		fmt.Printf("   ✅ %s completed (%vms)\n", stepName(), payload.Data["duration_ms"])
+	case "step.failed":
+		fmt.Printf("   ❌ %s failed: %s\n", stepName(), str("error_message"))
+	case "debug.paused":
+		fmt.Println("   ⏸️  Paused by debugger")
+	}
+}
+
+// printRemoteSummary prints the result of a finished execution and returns an
+// error when it failed
+func printRemoteSummary(execution *remoteExecution) error {
+	duration := (time.Duration(execution.DurationMs) * time.Millisecond).Round(time.Millisecond)
+	failed := execution.Status != "completed" || execution.FailedSteps > 0
+
+	fmt.Println()
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	switch {
+	case !failed:
+		fmt.Printf("✅ Flow completed successfully in %s\n", duration)
+	case execution.Quarantined:
+		fmt.Printf("⚠️  Flow completed with quarantined failures in %s\n", duration)
+	case execution.Status == "cancelled":
+		fmt.Printf("🛑 Flow was cancelled after %s\n", duration)
+	default:
+		fmt.Printf("❌ Flow completed with failures in %s\n", duration)
+	}
+	fmt.Printf("   Total steps: %d\n", execution.TotalSteps)
+	fmt.Printf("   Passed: %d\n", execution.PassedSteps)
+	fmt.Printf("   Failed: %d\n", execution.FailedSteps)
+	if execution.Error != "" {
+		fmt.Printf("   Error: %s\n", execution.Error)
+	}
+	fmt.Println()
+
+	if !failed || execution.Quarantined {
+		return nil
+	}
+	return fmt.Errorf("execution %s %s", execution.ID, execution.Status)
+}
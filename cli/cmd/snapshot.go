@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotStatus string
+	snapshotFlow   string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Review snapshot assertions",
+	Long: `List, inspect, accept, and reject snapshot golden records.
+
+When a step's output differs from its golden record the step fails and the
+new output is kept as a pending candidate until it is accepted or rejected.
+
+Examples:
+  testmesh snapshot list <flow-id> --status pending
+  testmesh snapshot show <snapshot-id>
+  testmesh snapshot accept <snapshot-id>
+  testmesh snapshot accept --flow <flow-id>
+  testmesh snapshot reject <snapshot-id>`,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list <flow-id>",
+	Short: "List snapshots of a flow",
+	Args:  cobra.ExactArgs(1),
+	RunE:  listSnapshots,
+}
+
+var snapshotShowCmd = &cobra.Command{
+	Use:   "show <snapshot-id>",
+	Short: "Show the pending diff of a snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE:  showSnapshot,
+}
+
+var snapshotAcceptCmd = &cobra.Command{
+	Use:   "accept [snapshot-id...]",
+	Short: "Accept pending snapshots as the new golden records",
+	RunE:  acceptSnapshots,
+}
+
+var snapshotRejectCmd = &cobra.Command{
+	Use:   "reject <snapshot-id>",
+	Short: "Discard a pending snapshot and keep the golden record",
+	Args:  cobra.ExactArgs(1),
+	RunE:  rejectSnapshot,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotShowCmd)
+	snapshotCmd.AddCommand(snapshotAcceptCmd)
+	snapshotCmd.AddCommand(snapshotRejectCmd)
+
+	snapshotListCmd.Flags().StringVar(&snapshotStatus, "status", "", "Filter by status (accepted, pending)")
+	snapshotAcceptCmd.Flags().StringVar(&snapshotFlow, "flow", "", "Accept every pending snapshot of this flow")
+}
+
+type Snapshot struct {
+	ID                 string    `json:"id"`
+	FlowID             string    `json:"flow_id"`
+	StepID             string    `json:"step_id"`
+	Name               string    `json:"name"`
+	Status             string    `json:"status"`
+	PendingExecutionID string    `json:"pending_execution_id,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func listSnapshots(cmd *cobra.Command, args []string) error {
+	flowID := args[0]
+
+	fmt.Println("📸 Snapshots")
+	fmt.Println()
+
+	endpoint := workspaceEndpoint("/flows/" + flowID + "/snapshots")
+	if snapshotStatus != "" {
+		endpoint += "?status=" + snapshotStatus
+	}
+
+	resp, err := http.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Snapshots []Snapshot `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Snapshots) == 0 {
+		fmt.Println("No snapshots found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-25s %-10s %-20s\n", "ID", "NAME", "STATUS", "UPDATED")
+	fmt.Println(strings.Repeat("-", 95))
+
+	for _, s := range result.Snapshots {
+		status := s.Status
+		if status == "pending" {
+			status = "⏳ pending"
+		}
+		fmt.Printf("%-36s  %-25s %-10s %-20s\n",
+			s.ID, truncate(s.Name, 25), status, s.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+
+	return nil
+}
+
+func showSnapshot(cmd *cobra.Command, args []string) error {
+	snapshotID := args[0]
+
+	resp, err := http.Get(workspaceEndpoint("/snapshots/" + snapshotID))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	var result struct {
+		Snapshot Snapshot       `json:"snapshot"`
+		Summary  map[string]int `json:"summary"`
+		Diff     string         `json:"diff"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("📸 Snapshot: %s\n", result.Snapshot.Name)
+	fmt.Printf("   ID: %s\n", result.Snapshot.ID)
+	fmt.Printf("   Step: %s\n", result.Snapshot.StepID)
+	fmt.Printf("   Status: %s\n", result.Snapshot.Status)
+	fmt.Println()
+
+	if result.Diff == "" {
+		fmt.Println("✅ No pending changes")
+		return nil
+	}
+
+	fmt.Printf("Changes: %d critical, %d major, %d minor\n",
+		result.Summary["critical"], result.Summary["major"], result.Summary["minor"])
+	fmt.Println()
+	fmt.Println(result.Diff)
+	fmt.Println()
+	fmt.Printf("Accept with: testmesh snapshot accept %s\n", result.Snapshot.ID)
+
+	return nil
+}
+
+func acceptSnapshots(cmd *cobra.Command, args []string) error {
+	if snapshotFlow == "" && len(args) == 0 {
+		return fmt.Errorf("specify snapshot IDs or --flow")
+	}
+
+	if snapshotFlow != "" {
+		fmt.Printf("✅ Accepting pending snapshots of flow %s...\n", snapshotFlow)
+
+		var result struct {
+			Accepted []string `json:"accepted"`
+		}
+		if err := postSnapshotAction(workspaceEndpoint("/flows/"+snapshotFlow+"/snapshots/accept"), &result); err != nil {
+			return err
+		}
+		for _, name := range result.Accepted {
+			fmt.Printf("   ✓ %s\n", name)
+		}
+		fmt.Printf("Accepted %d snapshot(s)\n", len(result.Accepted))
+	}
+
+	for _, id := range args {
+		var result Snapshot
+		if err := postSnapshotAction(workspaceEndpoint("/snapshots/"+id+"/accept"), &result); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Snapshot %s accepted\n", result.Name)
+	}
+
+	return nil
+}
+
+func rejectSnapshot(cmd *cobra.Command, args []string) error {
+	var result Snapshot
+	if err := postSnapshotAction(workspaceEndpoint("/snapshots/"+args[0]+"/reject"), &result); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️  Pending changes of snapshot %s discarded\n", result.Name)
+	return nil
+}
+
+func postSnapshotAction(endpoint string, out interface{}) error {
+	resp, err := http.Post(endpoint, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error: %s", string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -1,166 +1,204 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"strings"
 
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	validateOffline bool
+	validateSchema  bool
+)
+
 var validateCmd = &cobra.Command{
-	Use:   "validate <flow.yaml>",
-	Short: "Validate a flow YAML file",
-	Long: `Validate a test flow definition without executing it.
+	Use:   "validate <flow.yaml>...",
+	Short: "Validate flow YAML files",
+	Long: `Validate test flow definitions without executing them.
 
 Checks for:
 - Valid YAML syntax
 - Required fields (name, steps)
-- Valid action types
-- Step structure`,
-	Args: cobra.ExactArgs(1),
+- Known actions, including the actions of server plugins
+- Step configs matching the schema of their action
+- Duplicate step IDs
+- ${step.output} references to steps that do not exist or run later
+
+Flows are checked by the server, which knows the actions of its plugins.
+When the server cannot be reached, or with --offline, the CLI checks them
+itself; only required config fields of built-in actions are checked then.
+
+Problems are printed as file:line:column: severity: message.
+
+Use --schema to print the JSON Schema of flow files for editor completion.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if validateSchema {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: validateFlow,
 }
 
 func init() {
 	rootCmd.AddCommand(validateCmd)
-}
 
-var validActions = map[string]bool{
-	"http_request":          true,
-	"database_query":        true,
-	"log":                   true,
-	"delay":                 true,
-	"assert":                true,
-	"transform":             true,
-	"condition":             true,
-	"for_each":              true,
-	"mock_server_start":     true,
-	"mock_server_stop":      true,
-	"mock_server_configure": true,
-	"contract_generate":     true,
-	"contract_verify":       true,
-	"websocket":             true,
-	"grpc":                  true,
-	"kafka":                 true,
-	"kafka.produce":         true,
-	"kafka.consume":         true,
+	validateCmd.Flags().BoolVar(&validateOffline, "offline", false, "check locally without the server")
+	validateCmd.Flags().BoolVar(&validateSchema, "schema", false, "print the JSON Schema of flow files")
 }
 
 func validateFlow(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
-
-	// Read flow file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+	if validateSchema {
+		return printFlowSchema()
 	}
 
-	// Parse YAML
-	var flowWrapper struct {
-		Flow struct {
-			Name        string                   `yaml:"name"`
-			Description string                   `yaml:"description"`
-			Suite       string                   `yaml:"suite"`
-			Setup       []map[string]interface{} `yaml:"setup"`
-			Steps       []map[string]interface{} `yaml:"steps"`
-			Teardown    []map[string]interface{} `yaml:"teardown"`
-		} `yaml:"flow"`
+	errorCount, warningCount, failedFiles := 0, 0, 0
+	local := validateOffline
+
+	fmt.Println()
+	for _, filePath := range args {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		var diagnostics []flowcheck.Diagnostic
+		if !local {
+			diagnostics, err = validateRemote(data)
+			if err != nil {
+				fmt.Printf("⚠️  Checking locally, the server could not validate: %v\n\n", err)
+				local = true
+			}
+		}
+		if local {
+			diagnostics = flowcheck.Check(data)
+		}
+
+		fileErrors := 0
+		for _, d := range diagnostics {
+			fmt.Printf("%s:%d:%d: %s: %s\n", filePath, d.Line, d.Column, d.Severity, d.Message)
+			if d.Severity == "error" {
+				fileErrors++
+			} else {
+				warningCount++
+			}
+		}
+		errorCount += fileErrors
+		if fileErrors > 0 {
+			failedFiles++
+		} else if verbose {
+			printFlowSummary(filePath, data)
+		}
 	}
 
-	if err := yaml.Unmarshal(data, &flowWrapper); err != nil {
-		return fmt.Errorf("invalid YAML: %w", err)
+	if errorCount > 0 || warningCount > 0 {
+		fmt.Println()
+	}
+	if errorCount > 0 {
+		fmt.Printf("❌ Validation failed with %d error(s) in %d file(s)\n\n", errorCount, failedFiles)
+		return fmt.Errorf("validation failed")
 	}
 
-	flow := flowWrapper.Flow
-	errors := []string{}
+	if len(args) == 1 {
+		fmt.Print("✅ Flow is valid")
+	} else {
+		fmt.Printf("✅ %d flows are valid", len(args))
+	}
+	if warningCount > 0 {
+		fmt.Printf(" (%d warning(s))", warningCount)
+	}
+	fmt.Println()
+	fmt.Println()
+	return nil
+}
 
-	// Check required fields
-	if flow.Name == "" {
-		errors = append(errors, "flow.name is required")
+// validateRemote validates a flow with the server, which also knows the
+// actions of its plugins
+func validateRemote(data []byte) ([]flowcheck.Diagnostic, error) {
+	payload, err := json.Marshal(map[string]string{"yaml": string(data)})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(flow.Steps) == 0 {
-		errors = append(errors, "flow.steps must have at least one step")
+	resp, err := http.Post(workspaceEndpoint("/flows/validate"), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// Validate steps
-	validateSteps := func(steps []map[string]interface{}, phase string) {
-		for i, step := range steps {
-			// Check for action
-			action, ok := step["action"].(string)
-			if !ok || action == "" {
-				errors = append(errors, fmt.Sprintf("%s step %d: action is required", phase, i+1))
-				continue
-			}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server error: %s", string(body))
+	}
 
-			// Check valid action type (allow plugin actions with dots)
-			isValid := validActions[action]
-			if !isValid && strings.Contains(action, ".") {
-				prefix := action[:strings.Index(action, ".")]
-				isValid = validActions[prefix]
-			}
-			if !isValid {
-				errors = append(errors, fmt.Sprintf("%s step %d: unknown action type '%s'", phase, i+1, action))
-			}
+	var result struct {
+		Valid       bool                   `json:"valid"`
+		Diagnostics []flowcheck.Diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Diagnostics, nil
+}
 
-			// Check for id or name
-			_, hasID := step["id"]
-			_, hasName := step["name"]
-			if !hasID && !hasName {
-				errors = append(errors, fmt.Sprintf("%s step %d: step should have 'id' or 'name'", phase, i+1))
-			}
-		}
+// printFlowSchema prints the server's JSON Schema of flow files
+func printFlowSchema() error {
+	resp, err := http.Get(apiURL + "/api/v1/schemas/flow.json")
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
 	}
+	defer resp.Body.Close()
 
-	validateSteps(flow.Setup, "setup")
-	validateSteps(flow.Steps, "steps")
-	validateSteps(flow.Teardown, "teardown")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server error: %s", string(body))
+	}
 
-	// Print results
-	fmt.Println()
-	if len(errors) > 0 {
-		fmt.Printf("❌ Validation failed with %d error(s):\n\n", len(errors))
-		for _, err := range errors {
-			fmt.Printf("   • %s\n", err)
-		}
-		fmt.Println()
-		return fmt.Errorf("validation failed")
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
 	}
+	fmt.Println(out.String())
+	return nil
+}
 
-	fmt.Println("✅ Flow is valid")
-	fmt.Println()
-	fmt.Printf("   Name: %s\n", flow.Name)
-	if flow.Description != "" {
-		fmt.Printf("   Description: %s\n", flow.Description)
+// printFlowSummary lists the steps of a valid flow
+func printFlowSummary(filePath string, data []byte) {
+	var flow struct {
+		Name     string                   `yaml:"name"`
+		Setup    []map[string]interface{} `yaml:"setup"`
+		Steps    []map[string]interface{} `yaml:"steps"`
+		Teardown []map[string]interface{} `yaml:"teardown"`
 	}
-	if flow.Suite != "" {
-		fmt.Printf("   Suite: %s\n", flow.Suite)
+	var wrapper struct {
+		Flow *yaml.Node `yaml:"flow"`
+	}
+	if err := yaml.Unmarshal(data, &wrapper); err == nil && wrapper.Flow != nil {
+		wrapper.Flow.Decode(&flow)
+	} else {
+		yaml.Unmarshal(data, &flow)
 	}
-	fmt.Printf("   Setup steps: %d\n", len(flow.Setup))
-	fmt.Printf("   Main steps: %d\n", len(flow.Steps))
-	fmt.Printf("   Teardown steps: %d\n", len(flow.Teardown))
-	fmt.Println()
 
-	// List steps
-	if verbose {
-		fmt.Println("   Steps:")
-		for i, step := range flow.Steps {
-			action, _ := step["action"].(string)
-			id, _ := step["id"].(string)
-			name, _ := step["name"].(string)
-			stepLabel := id
-			if stepLabel == "" {
-				stepLabel = name
-			}
-			if stepLabel == "" {
-				stepLabel = fmt.Sprintf("step_%d", i+1)
-			}
-			fmt.Printf("   %d. %s (%s)\n", i+1, stepLabel, action)
+	fmt.Printf("\n   %s: %s (%d setup, %d main, %d teardown steps)\n", filePath, flow.Name, len(flow.Setup), len(flow.Steps), len(flow.Teardown))
+	for i, step := range flow.Steps {
+		action, _ := step["action"].(string)
+		id, _ := step["id"].(string)
+		name, _ := step["name"].(string)
+		stepLabel := id
+		if stepLabel == "" {
+			stepLabel = name
 		}
-		fmt.Println()
+		if stepLabel == "" {
+			stepLabel = fmt.Sprintf("step_%d", i+1)
+		}
+		fmt.Printf("   %d. %s (%s)\n", i+1, stepLabel, action)
 	}
-
-	return nil
+	fmt.Println()
 }
@@ -1,38 +1,84 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/georgi-georgiev/testmesh-cli/internal/dap"
+	"github.com/georgi-georgiev/testmesh-cli/internal/watcher"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
-	watchPattern string
+	watchPattern  string
 	watchDebounce int
+	watchEnv      string
+	watchTags     []string
+	watchVars     map[string]string
+	watchNoMocks  bool
 )
 
 var watchCmd = &cobra.Command{
-	Use:   "watch [directory]",
-	Short: "Watch for changes and run tests automatically",
-	Long: `Watch for file changes in a directory and automatically run tests.
+	Use:   "watch [directory | flow.yaml]",
+	Short: "Watch for changes and run affected flows",
+	Long: `Watch a directory and re-run the flows affected by each change on the server.
 
-Monitors YAML files for changes and re-executes flows when modified.
-Press Ctrl+C to stop watching.`,
+Flows are linked to the sub-flows they run with run_flow, the data files
+they reference, the mock definitions of the mock servers they start and the
+environment file of --env (environments/<env>.yaml). When a file changes,
+only the flows depending on it are run again. Changed sub-flows are uploaded
+before the flows running them.
+
+Mock definitions (files with a mock_server section and a port) are served
+locally for the whole session. A mock server is restarted only when its
+definition changes. Use --no-mocks to leave them to the flows.
+
+While watching, type a command and press Enter:
+  <Enter>      run the selected flows
+  a            run all flows
+  f            re-run failed flows
+  t <tags>     only run flows with one of these tags (t alone clears)
+  o <flow>     focus one flow by name or file (o alone clears)
+  l            list flows and their last result
+  q            quit
+
+Watching a single flow file watches its directory and focuses the flow.
+
+Examples:
+  testmesh watch flows/
+  testmesh watch flows/checkout.yaml
+  testmesh watch . --env staging --tag smoke`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: watchFiles,
 }
 
 func init() {
 	rootCmd.AddCommand(watchCmd)
-	watchCmd.Flags().StringVarP(&watchPattern, "pattern", "p", "*.yaml", "File pattern to watch")
+	watchCmd.Flags().StringVarP(&watchPattern, "pattern", "p", "*.yaml", "Pattern of flow files to run")
 	watchCmd.Flags().IntVarP(&watchDebounce, "debounce", "d", 500, "Debounce time in milliseconds")
+	watchCmd.Flags().StringVarP(&watchEnv, "env", "e", "development", "Environment name")
+	watchCmd.Flags().StringSliceVarP(&watchTags, "tag", "t", nil, "Only run flows with one of these tags")
+	watchCmd.Flags().StringToStringVar(&watchVars, "var", nil, "Set a variable of every execution (name=value)")
+	watchCmd.Flags().BoolVar(&watchNoMocks, "no-mocks", false, "Do not serve mock definitions locally")
+}
+
+// watchSession is the state of a watch: the dependency graph, the filters
+// set from the prompt, the last result of each flow and the mock servers
+type watchSession struct {
+	graph   *watcher.Graph
+	tags    []string
+	focus   string // Path of the focused flow
+	results map[string]bool
+	mocks   map[string]*http.Server // By definition path
 }
 
 func watchFiles(cmd *cobra.Command, args []string) error {
@@ -47,90 +93,383 @@ func watchFiles(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to resolve path: %w", err)
 	}
 
-	// Create watcher
-	watcher, err := fsnotify.NewWatcher()
+	// A single flow is watched with the files around it
+	var focus string
+	if info, err := os.Stat(absDir); err == nil && !info.IsDir() {
+		focus = absDir
+		absDir = filepath.Dir(absDir)
+	}
+
+	config := watcher.DefaultConfig()
+	config.Paths = []string{absDir}
+	config.Pattern = "*" // Data files are watched as well as YAML
+	config.Debounce = time.Duration(watchDebounce) * time.Millisecond
+
+	graph, err := watcher.BuildGraph(absDir, config.IgnorePatterns)
 	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+		return fmt.Errorf("failed to read flows: %w", err)
+	}
+
+	s := &watchSession{
+		graph:   graph,
+		tags:    watchTags,
+		focus:   focus,
+		results: make(map[string]bool),
+		mocks:   make(map[string]*http.Server),
 	}
-	defer watcher.Close()
+	defer s.stopMocks()
 
-	// Add directory to watch
-	if err := addDirRecursive(watcher, absDir); err != nil {
+	changes := make(chan []string, 16)
+	w, err := watcher.New(config)
+	if err != nil {
+		return err
+	}
+	if err := w.Start(func(events []watcher.Event) {
+		paths := make([]string, 0, len(events))
+		for _, event := range events {
+			paths = append(paths, event.Path)
+		}
+		changes <- paths
+	}); err != nil {
 		return fmt.Errorf("failed to add directories to watch: %w", err)
 	}
+	defer w.Stop()
 
 	fmt.Printf("👁️  Watching for changes in %s\n", absDir)
-	fmt.Printf("   Pattern: %s\n", watchPattern)
-	fmt.Println("   Press Ctrl+C to stop")
+	fmt.Printf("   Flows: %d, mock definitions: %d\n", len(graph.Flows), len(graph.Mocks))
+	fmt.Printf("   Environment: %s\n", watchEnv)
+	if len(s.tags) > 0 {
+		fmt.Printf("   Tags: %s\n", strings.Join(s.tags, ", "))
+	}
+	fmt.Println("   Type h for commands, Ctrl+C to stop")
 	fmt.Println()
 
+	if !watchNoMocks {
+		for path := range graph.Mocks {
+			s.startMock(path)
+		}
+	}
+
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Debounce timer
-	var debounceTimer *time.Timer
-	pendingFiles := make(map[string]bool)
+	commands := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			commands <- strings.TrimSpace(scanner.Text())
+		}
+		close(commands)
+	}()
 
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case paths := <-changes:
+			s.changed(paths)
+			s.prompt()
+
+		case line, ok := <-commands:
 			if !ok {
+				// Without input, keep watching until interrupted
+				commands = nil
+				continue
+			}
+			if quit := s.command(line); quit {
+				fmt.Println("👋 Stopping watch...")
 				return nil
 			}
+			s.prompt()
 
-			// Check if file matches pattern
-			if !matchesPattern(event.Name, watchPattern) {
-				continue
-			}
+		case <-sigChan:
+			fmt.Println("\n👋 Stopping watch...")
+			return nil
+		}
+	}
+}
 
-			// Only handle write and create events
-			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
-				continue
-			}
+// changed updates the graph with changed files, restarts changed mock
+// servers and runs the affected flows
+func (s *watchSession) changed(paths []string) {
+	sort.Strings(paths)
+	for _, path := range paths {
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			s.graph.Update(path)
+		}
+	}
 
-			// Add to pending files
-			pendingFiles[event.Name] = true
+	fmt.Println()
+	for _, path := range paths {
+		fmt.Printf("📝 File changed: %s\n", s.relative(path))
+		if _, ok := s.graph.Mocks[path]; ok && !watchNoMocks {
+			s.startMock(path)
+		}
+	}
 
-			// Reset debounce timer
-			if debounceTimer != nil {
-				debounceTimer.Stop()
-			}
+	flows := s.graph.Affected(paths, watchEnv)
+	if len(flows) == 0 {
+		fmt.Println("   No flows affected")
+		return
+	}
+	s.run(flows)
+}
 
-			debounceTimer = time.AfterFunc(time.Duration(watchDebounce)*time.Millisecond, func() {
-				for file := range pendingFiles {
-					runChangedFile(file)
-				}
-				pendingFiles = make(map[string]bool)
-			})
+// command runs a prompt command and reports whether to quit
+func (s *watchSession) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
 
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return nil
+	switch name {
+	case "":
+		s.run(s.graph.All())
+	case "a":
+		s.tags, s.focus = nil, ""
+		s.run(s.graph.All())
+	case "f":
+		var failed []*watcher.Flow
+		for _, flow := range s.graph.All() {
+			if passed, ok := s.results[flow.Path]; ok && !passed {
+				failed = append(failed, flow)
+			}
+		}
+		if len(failed) == 0 {
+			fmt.Println("✅ No failed flows")
+			return false
+		}
+		s.run(failed)
+	case "t":
+		s.tags = nil
+		if arg != "" {
+			s.tags = strings.Split(arg, ",")
+		}
+		s.printFilter()
+	case "o":
+		s.focus = ""
+		if arg != "" {
+			flow := s.find(arg)
+			if flow == nil {
+				fmt.Printf("❌ Flow not found: %s\n", arg)
+				return false
 			}
-			fmt.Printf("❌ Watch error: %v\n", err)
+			s.focus = flow.Path
+		}
+		s.printFilter()
+	case "l":
+		s.list()
+	case "q":
+		return true
+	case "h", "?":
+		fmt.Println("Commands: <Enter> run, a all, f failed, t <tags> filter by tag, o <flow> focus, l list, q quit")
+	default:
+		fmt.Printf("❌ Unknown command: %s (h for help)\n", name)
+	}
+	return false
+}
 
-		case <-sigChan:
-			fmt.Println("\n👋 Stopping watch...")
-			return nil
+// run executes the flows passing the filters, one after another, after
+// uploading the sub-flows they run
+func (s *watchSession) run(flows []*watcher.Flow) {
+	flows = s.filter(flows)
+	if len(flows) == 0 {
+		fmt.Println("   No flows match the filter")
+		return
+	}
+
+	variables := s.variables()
+	fmt.Printf("🔄 Running %d flow(s) at %s\n", len(flows), time.Now().Format("15:04:05"))
+
+	passed, failed := 0, 0
+	for _, flow := range flows {
+		fmt.Println()
+		fmt.Printf("▶️  %s (%s)\n", flow.Name, s.relative(flow.Path))
+
+		err := s.upload(s.graph.Dependencies(flow))
+		if err == nil {
+			err = s.execute(flow, variables)
+		}
+		s.results[flow.Path] = err == nil
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", flow.Name, err)
+			continue
 		}
+		passed++
+	}
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("   Passed: %d\n", passed)
+	fmt.Printf("   Failed: %d\n", failed)
+}
+
+// execute starts a flow on the server and follows it until it finishes
+func (s *watchSession) execute(flow *watcher.Flow, variables map[string]string) error {
+	data, err := os.ReadFile(flow.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read flow file: %w", err)
+	}
+	executionID, err := startRemoteExecution(data, watchEnv, variables)
+	if err != nil {
+		return err
 	}
+	if verbose {
+		fmt.Printf("   Execution ID: %s\n", executionID)
+	}
+	return followExecution(executionID)
 }
 
-func addDirRecursive(watcher *fsnotify.Watcher, dir string) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// upload creates or updates sub-flows on the server, so that run_flow finds
+// their latest version
+func (s *watchSession) upload(flows []*watcher.Flow) error {
+	client := dap.NewClient(apiURL, workspaceID)
+	for _, flow := range flows {
+		data, err := os.ReadFile(flow.Path)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to read sub-flow %s: %w", flow.Name, err)
+		}
+		if _, err := client.UpsertFlow(flow.Name, data); err != nil {
+			return fmt.Errorf("failed to upload sub-flow %s: %w", flow.Name, err)
 		}
-		if info.IsDir() {
-			// Skip hidden directories
-			if strings.HasPrefix(info.Name(), ".") && path != dir {
-				return filepath.SkipDir
+	}
+	return nil
+}
+
+// filter keeps the flows matching the pattern, tags and focus. Sub-flows
+// run through the flows that use them, unless focused.
+func (s *watchSession) filter(flows []*watcher.Flow) []*watcher.Flow {
+	var selected []*watcher.Flow
+	for _, flow := range flows {
+		if s.focus != "" {
+			if flow.Path == s.focus {
+				selected = append(selected, flow)
 			}
-			return watcher.Add(path)
+			continue
+		}
+		if flow.Name == "" || !matchesPattern(flow.Path, watchPattern) || s.graph.IsSubFlow(flow) {
+			continue
 		}
-		return nil
-	})
+		if len(s.tags) > 0 && !hasAnyTag(flow.Tags, s.tags) {
+			continue
+		}
+		selected = append(selected, flow)
+	}
+	return selected
+}
+
+// variables returns the env of the environment file with --var on top
+func (s *watchSession) variables() map[string]string {
+	variables := make(map[string]string)
+	if path := s.graph.Environments[watchEnv]; path != "" {
+		var env struct {
+			Env map[string]interface{} `yaml:"env"`
+		}
+		if data, err := os.ReadFile(path); err == nil && yaml.Unmarshal(data, &env) == nil {
+			for key, value := range env.Env {
+				variables[key] = fmt.Sprint(value)
+			}
+		}
+	}
+	for key, value := range watchVars {
+		variables[key] = value
+	}
+	return variables
+}
+
+// startMock serves a mock definition, replacing the server started for an
+// earlier version of it
+func (s *watchSession) startMock(path string) {
+	if server := s.mocks[path]; server != nil {
+		server.Close()
+		delete(s.mocks, path)
+	}
+
+	mock := s.graph.Mocks[path]
+	if mock == nil || mock.Port == 0 {
+		return
+	}
+	config, err := loadMockConfig(path)
+	if err != nil {
+		fmt.Printf("⚠️  Mock %s: %v\n", s.relative(path), err)
+		return
+	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", mock.Port),
+		Handler: newMockHandler(config.Endpoints, verbose),
+	}
+	s.mocks[path] = server
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("⚠️  Mock %s: %v\n", mock.Name, err)
+		}
+	}()
+	fmt.Printf("🎭 Serving mock %s on port %d (%d endpoints)\n", mock.Name, mock.Port, len(config.Endpoints))
+}
+
+func (s *watchSession) stopMocks() {
+	for _, server := range s.mocks {
+		server.Close()
+	}
+}
+
+func (s *watchSession) list() {
+	flows := s.filter(s.graph.All())
+	if len(flows) == 0 {
+		fmt.Println("No flows match the filter")
+		return
+	}
+	fmt.Printf("%-8s %-35s %s\n", "RESULT", "FLOW", "FILE")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, flow := range flows {
+		result := "-"
+		if passed, ok := s.results[flow.Path]; ok {
+			result = "❌ failed"
+			if passed {
+				result = "✅ passed"
+			}
+		}
+		fmt.Printf("%-8s %-35s %s\n", result, truncate(flow.Name, 35), s.relative(flow.Path))
+	}
+}
+
+func (s *watchSession) printFilter() {
+	switch {
+	case s.focus != "":
+		fmt.Printf("🎯 Focused on %s\n", s.relative(s.focus))
+	case len(s.tags) > 0:
+		fmt.Printf("🏷️  Running flows tagged %s\n", strings.Join(s.tags, ", "))
+	default:
+		fmt.Println("🏷️  Running all affected flows")
+	}
+}
+
+func (s *watchSession) prompt() {
+	fmt.Println()
+	fmt.Println("👁️  Watching for changes... (h for commands)")
+}
+
+// find returns the flow with a name or file
+func (s *watchSession) find(ref string) *watcher.Flow {
+	if flow := s.graph.FlowByName(ref); flow != nil {
+		return flow
+	}
+	if path, err := filepath.Abs(ref); err == nil {
+		if flow := s.graph.Flows[path]; flow != nil {
+			return flow
+		}
+	}
+	for _, flow := range s.graph.All() {
+		if s.relative(flow.Path) == ref || filepath.Base(flow.Path) == ref {
+			return flow
+		}
+	}
+	return nil
+}
+
+func (s *watchSession) relative(path string) string {
+	if rel, err := filepath.Rel(s.graph.Root, path); err == nil {
+		return rel
+	}
+	return path
 }
 
 func matchesPattern(filename, pattern string) bool {
@@ -139,18 +478,13 @@ func matchesPattern(filename, pattern string) bool {
 	return matched
 }
 
-func runChangedFile(file string) {
-	fmt.Println()
-	fmt.Printf("📝 File changed: %s\n", file)
-	fmt.Printf("🔄 Running at %s\n", time.Now().Format("15:04:05"))
-	fmt.Println()
-
-	// Execute the flow
-	args := []string{file}
-	if err := runFlow(nil, args); err != nil {
-		fmt.Printf("\n❌ Execution failed: %v\n", err)
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(tag, strings.TrimSpace(w)) {
+				return true
+			}
+		}
 	}
-
-	fmt.Println()
-	fmt.Println("👁️  Watching for changes...")
+	return false
 }
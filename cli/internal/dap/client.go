@@ -0,0 +1,272 @@
+package dap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// errNoSession is returned for executions without a debug session, which
+// includes executions that have finished
+var errNoSession = errors.New("debug session not found")
+
+// Client talks to the TestMesh API
+type Client struct {
+	APIURL      string
+	WorkspaceID string
+	HTTP        *http.Client
+}
+
+// NewClient creates a client of a server
+func NewClient(apiURL, workspaceID string) *Client {
+	return &Client{
+		APIURL:      apiURL,
+		WorkspaceID: workspaceID,
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// BreakpointRequest is a breakpoint as accepted by the debug API
+type BreakpointRequest struct {
+	StepID    string `json:"step_id,omitempty"`
+	Type      string `json:"type"`
+	Condition string `json:"condition,omitempty"`
+	LogPoint  string `json:"log_point,omitempty"`
+}
+
+// DebugState is the state of a debug session
+type DebugState struct {
+	State       string                 `json:"state"`
+	CurrentStep string                 `json:"current_step"`
+	Variables   map[string]interface{} `json:"variables"`
+	StepOutputs map[string]interface{} `json:"step_outputs"`
+	Stack       []Frame                `json:"stack"`
+	StopReason  string                 `json:"stop_reason"`
+	StepConfig  map[string]interface{} `json:"step_config"`
+	LastError   string                 `json:"last_error"`
+}
+
+// Frame is a step on the stack of a paused execution
+type Frame struct {
+	StepID   string `json:"step_id"`
+	StepName string `json:"step_name"`
+	Action   string `json:"action"`
+	Phase    string `json:"phase"`
+}
+
+// ContextSnapshot is the context recorded before a step
+type ContextSnapshot struct {
+	Sequence     int                               `json:"sequence"`
+	Phase        string                            `json:"phase"`
+	StepID       string                            `json:"step_id"`
+	StepName     string                            `json:"step_name"`
+	Action       string                            `json:"action"`
+	ParentStepID string                            `json:"parent_step_id"`
+	Variables    map[string]string                 `json:"variables"`
+	StepOutputs  map[string]map[string]interface{} `json:"step_outputs"`
+}
+
+// Execution is the status of an execution
+type Execution struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	PassedSteps int    `json:"passed_steps"`
+	FailedSteps int    `json:"failed_steps"`
+}
+
+// UpsertFlow creates the flow of a file, or updates the flow with its name
+func (c *Client) UpsertFlow(name string, yamlContent []byte) (string, error) {
+	var list struct {
+		Flows []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"flows"`
+	}
+	if err := c.do(http.MethodGet, c.workspaceEndpoint("/flows?limit=1000"), nil, &list); err != nil {
+		return "", err
+	}
+
+	body := map[string]string{"yaml": string(yamlContent)}
+	var flow struct {
+		ID string `json:"id"`
+	}
+	for _, f := range list.Flows {
+		if f.Name == name {
+			if err := c.do(http.MethodPut, c.workspaceEndpoint("/flows/"+f.ID), body, &flow); err != nil {
+				return "", err
+			}
+			return flow.ID, nil
+		}
+	}
+	if err := c.do(http.MethodPost, c.workspaceEndpoint("/flows"), body, &flow); err != nil {
+		return "", err
+	}
+	return flow.ID, nil
+}
+
+// StartExecution starts a flow file in a debug session. The file runs as an
+// ad-hoc flow, so the workspace flow with its name is left untouched.
+func (c *Client) StartExecution(yamlContent []byte, environment string, variables map[string]string, breakpoints []BreakpointRequest, stopOnEntry bool) (string, error) {
+	body := map[string]interface{}{
+		"yaml":        string(yamlContent),
+		"environment": environment,
+		"variables":   variables,
+		"debug": map[string]interface{}{
+			"breakpoints":   breakpoints,
+			"stop_on_entry": stopOnEntry,
+		},
+	}
+	var execution Execution
+	if err := c.do(http.MethodPost, c.workspaceEndpoint("/executions"), body, &execution); err != nil {
+		return "", err
+	}
+	return execution.ID, nil
+}
+
+// RestartExecution restarts an execution from a top-level step in a debug
+// session that pauses before the step
+func (c *Client) RestartExecution(executionID, stepID string, breakpoints []BreakpointRequest) (string, error) {
+	body := map[string]interface{}{
+		"step_id": stepID,
+		"debug": map[string]interface{}{
+			"breakpoints":   breakpoints,
+			"stop_on_entry": true,
+		},
+	}
+	var execution Execution
+	if err := c.do(http.MethodPost, c.workspaceEndpoint("/executions/"+executionID+"/restart"), body, &execution); err != nil {
+		return "", err
+	}
+	return execution.ID, nil
+}
+
+// ListContext returns the context recorded before each step of an execution
+func (c *Client) ListContext(executionID string) ([]ContextSnapshot, error) {
+	var result struct {
+		Snapshots []ContextSnapshot `json:"snapshots"`
+	}
+	if err := c.do(http.MethodGet, c.workspaceEndpoint("/executions/"+executionID+"/context"), nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Snapshots, nil
+}
+
+// GetExecution returns the status of an execution
+func (c *Client) GetExecution(id string) (*Execution, error) {
+	var execution Execution
+	if err := c.do(http.MethodGet, c.APIURL+"/api/v1/executions/"+id, nil, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// GetState returns the state of the debug session of an execution
+func (c *Client) GetState(executionID string) (*DebugState, error) {
+	var state DebugState
+	if err := c.do(http.MethodGet, c.debugEndpoint(executionID, "/state"), nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// AddBreakpoint adds a breakpoint to a running session and returns its ID
+func (c *Client) AddBreakpoint(executionID string, bp BreakpointRequest) (string, error) {
+	var result struct {
+		Breakpoint struct {
+			ID string `json:"id"`
+		} `json:"breakpoint"`
+	}
+	if err := c.do(http.MethodPost, c.debugEndpoint(executionID, "/breakpoints"), bp, &result); err != nil {
+		return "", err
+	}
+	return result.Breakpoint.ID, nil
+}
+
+// RemoveBreakpoint removes a breakpoint from a running session
+func (c *Client) RemoveBreakpoint(executionID, breakpointID string) error {
+	return c.do(http.MethodDelete, c.debugEndpoint(executionID, "/breakpoints/"+url.PathEscape(breakpointID)), nil, nil)
+}
+
+// Command sends pause, resume, step-over or stop to a session
+func (c *Client) Command(executionID, command string) error {
+	return c.do(http.MethodPost, c.debugEndpoint(executionID, "/"+command), nil, nil)
+}
+
+// Evaluate evaluates an expression in a session
+func (c *Client) Evaluate(executionID, expression string) (interface{}, error) {
+	var result struct {
+		Result interface{} `json:"result"`
+	}
+	if err := c.do(http.MethodPost, c.debugEndpoint(executionID, "/evaluate"), map[string]string{"expression": expression}, &result); err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// SetVariable changes a variable, or an output of a step, of a paused session
+func (c *Client) SetVariable(executionID, stepID, name string, value interface{}) error {
+	body := map[string]interface{}{"step_id": stepID, "name": name, "value": value}
+	return c.do(http.MethodPut, c.debugEndpoint(executionID, "/variables"), body, nil)
+}
+
+func (c *Client) workspaceEndpoint(path string) string {
+	return c.APIURL + "/api/v1/workspaces/" + c.WorkspaceID + path
+}
+
+func (c *Client) debugEndpoint(executionID, path string) string {
+	return c.APIURL + "/api/v1/debug/sessions/" + executionID + path
+}
+
+// do sends a JSON request and decodes the response into result
+func (c *Client) do(method, endpoint string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			if apiErr.Error == "session not found" {
+				return errNoSession
+			}
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("server error: %s", string(data))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package dap
+
+import (
+	"fmt"
+)
+
+// Directions of travel through the recorded steps
+const (
+	backward = -1
+	forward  = 1
+)
+
+// stoppedEvent is the body of a stopped event
+func stoppedEvent(reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"reason":            reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	}
+}
+
+// history is the context recorded before each step up to the paused step.
+// The cursor points at the snapshot on view; at live, the paused execution
+// is on view.
+type history struct {
+	snapshots []ContextSnapshot
+	live      int
+	cursor    int
+}
+
+// current returns the snapshot on view, or nil at live
+func (h *history) current() *ContextSnapshot {
+	if h == nil || h.cursor >= h.live {
+		return nil
+	}
+	return &h.snapshots[h.cursor]
+}
+
+// stepBack views the step before the one on view. With toBreakpoint, it goes
+// back to the last step with a line breakpoint, or to the first step. It
+// returns the reason of the stop to report.
+func (s *Session) stepBack(toBreakpoint bool) (string, error) {
+	s.mu.Lock()
+	if s.state == nil || !s.stopped {
+		s.mu.Unlock()
+		return "", fmt.Errorf("execution is not paused")
+	}
+	h, state, executionID := s.history, s.state, s.executionID
+	s.mu.Unlock()
+
+	if h == nil {
+		snapshots, err := s.client.ListContext(executionID)
+		if err != nil {
+			return "", err
+		}
+		h, err = newHistory(snapshots, state)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.executionID != executionID || !s.stopped {
+		return "", fmt.Errorf("execution is not paused")
+	}
+	cursor, reason := s.seek(h, backward, toBreakpoint)
+	if cursor < 0 {
+		return "", fmt.Errorf("no earlier step was recorded")
+	}
+	h.cursor = cursor
+	s.history = h
+	s.refs = make(map[int]variableRef)
+	return reason, nil
+}
+
+// travel moves forward through the recorded steps, returning to the paused
+// step past the last one. It reports false when the paused step is on view,
+// so that the execution itself moves on.
+func (s *Session) travel(toBreakpoint bool) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.history
+	if h == nil {
+		return "", false
+	}
+	cursor, reason := s.seek(h, forward, toBreakpoint)
+	if cursor >= h.live {
+		s.history = nil
+	} else {
+		h.cursor = cursor
+	}
+	s.refs = make(map[int]variableRef)
+	return reason, true
+}
+
+// seek returns the next position in a direction and the reason to report
+// for it. With toBreakpoint, it skips steps without line breakpoints, going
+// back no further than the first step. Must be called with the lock held.
+func (s *Session) seek(h *history, direction int, toBreakpoint bool) (int, string) {
+	steps := s.breakpointSteps()
+	cursor := h.cursor + direction
+	for toBreakpoint && cursor >= 0 && cursor < h.live && !steps[h.snapshots[cursor].StepID] {
+		cursor += direction
+	}
+	if cursor < 0 && toBreakpoint && h.cursor > 0 {
+		cursor = 0
+	}
+	if cursor >= 0 && cursor < h.live && steps[h.snapshots[cursor].StepID] {
+		return cursor, "breakpoint"
+	}
+	return cursor, "step"
+}
+
+// breakpointSteps returns the steps with line breakpoints that pause. Their
+// conditions are not evaluated for recorded steps. Must be called with the
+// lock held.
+func (s *Session) breakpointSteps() map[string]bool {
+	steps := make(map[string]bool)
+	if s.source == nil {
+		return steps
+	}
+	for _, bp := range s.lineBreakpoints[absPath(s.source.Path)] {
+		if bp.LogMessage != "" {
+			continue
+		}
+		if step := s.source.StepAt(bp.Line); step != nil {
+			steps[step.ID] = true
+		}
+	}
+	return steps
+}
+
+// viewStack returns the stack on view, outermost first: that of the paused
+// execution, or the steps around the recorded step on view. Must be called
+// with the lock held.
+func (s *Session) viewStack() []Frame {
+	if s.state == nil || !s.stopped {
+		return nil
+	}
+	snapshot := s.history.current()
+	if snapshot == nil {
+		return s.state.Stack
+	}
+
+	stack := []Frame{snapshotFrame(snapshot)}
+	for parent := snapshot.ParentStepID; parent != ""; {
+		found := false
+		for i := s.history.cursor - 1; i >= 0; i-- {
+			if s.history.snapshots[i].StepID == parent {
+				stack = append([]Frame{snapshotFrame(&s.history.snapshots[i])}, stack...)
+				parent = s.history.snapshots[i].ParentStepID
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return stack
+}
+
+// restartFrame runs the execution again from the top-level step of the stack
+// on view, with the context recorded before it. The new execution pauses
+// before the step; the old one is stopped.
+func (s *Session) restartFrame(req *Request) error {
+	s.mu.Lock()
+	stack := s.viewStack()
+	if len(stack) == 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("execution is not paused")
+	}
+	stepID, executionID := stack[0].StepID, s.executionID
+	breakpoints, err := s.breakpointRequests()
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.restarting = true
+	s.mu.Unlock()
+
+	restartID, err := s.client.RestartExecution(executionID, stepID, breakpoints)
+	if err != nil {
+		s.mu.Lock()
+		s.restarting = false
+		s.mu.Unlock()
+		return fmt.Errorf("failed to restart from %s: %w", stepID, err)
+	}
+	// The old execution runs its teardown while the new one is paused
+	s.client.Command(executionID, "stop")
+
+	s.mu.Lock()
+	s.executionID = restartID
+	s.serverIDs = nil
+	s.state = nil
+	s.stopped = false
+	s.stops = 0
+	s.entryReason = "restart"
+	s.history = nil
+	s.refs = make(map[int]variableRef)
+	s.restarting = false
+	s.mu.Unlock()
+
+	if err := s.conn.respond(req, nil); err != nil {
+		return err
+	}
+	return s.conn.event("output", map[string]string{
+		"category": "console",
+		"output":   fmt.Sprintf("Restarted from %s as execution %s\n", stepID, restartID),
+	})
+}
+
+// newHistory positions the recorded steps relative to the paused step. The
+// paused step is the last recorded one, unless it failed: then the execution
+// is paused after it and stepping back views the context before it.
+func newHistory(snapshots []ContextSnapshot, state *DebugState) (*history, error) {
+	if len(state.Stack) == 0 {
+		return nil, fmt.Errorf("execution is not paused at a step")
+	}
+	top := state.Stack[len(state.Stack)-1]
+
+	live := -1
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].StepID == top.StepID && snapshots[i].Phase == top.Phase {
+			live = i
+			break
+		}
+	}
+	if live < 0 {
+		return nil, fmt.Errorf("no context was recorded for this execution")
+	}
+	if state.StopReason == "exception" {
+		live++
+	}
+	if live > len(snapshots) {
+		live = len(snapshots)
+	}
+	return &history{snapshots: snapshots[:live], live: live, cursor: live}, nil
+}
+
+func snapshotFrame(snapshot *ContextSnapshot) Frame {
+	return Frame{
+		StepID:   snapshot.StepID,
+		StepName: snapshot.StepName,
+		Action:   snapshot.Action,
+		Phase:    snapshot.Phase,
+	}
+}
+
+func snapshotVariables(snapshot *ContextSnapshot) map[string]interface{} {
+	variables := make(map[string]interface{}, len(snapshot.Variables))
+	for name, value := range snapshot.Variables {
+		variables[name] = value
+	}
+	return variables
+}
+
+func snapshotOutputs(snapshot *ContextSnapshot) map[string]interface{} {
+	outputs := make(map[string]interface{}, len(snapshot.StepOutputs))
+	for stepID, values := range snapshot.StepOutputs {
+		step := make(map[string]interface{}, len(values))
+		for name, value := range values {
+			step[name] = value
+		}
+		outputs[stepID] = step
+	}
+	return outputs
+}
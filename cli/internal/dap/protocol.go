@@ -0,0 +1,185 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Request is a request of the client
+type Request struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Response answers a request
+type Response struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+// Event is an event sent to the client
+type Event struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+// Capabilities are the features announced in the initialize response
+type Capabilities struct {
+	SupportsConfigurationDoneRequest bool                        `json:"supportsConfigurationDoneRequest"`
+	SupportsConditionalBreakpoints   bool                        `json:"supportsConditionalBreakpoints"`
+	SupportsLogPoints                bool                        `json:"supportsLogPoints"`
+	SupportsEvaluateForHovers        bool                        `json:"supportsEvaluateForHovers"`
+	SupportsSetVariable              bool                        `json:"supportsSetVariable"`
+	SupportsTerminateRequest         bool                        `json:"supportsTerminateRequest"`
+	SupportsStepBack                 bool                        `json:"supportsStepBack"`
+	SupportsRestartFrame             bool                        `json:"supportsRestartFrame"`
+	ExceptionBreakpointFilters       []ExceptionBreakpointFilter `json:"exceptionBreakpointFilters"`
+}
+
+// ExceptionBreakpointFilter is an option of setExceptionBreakpoints
+type ExceptionBreakpointFilter struct {
+	Filter  string `json:"filter"`
+	Label   string `json:"label"`
+	Default bool   `json:"default"`
+}
+
+// Source is a flow file
+type Source struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// SourceBreakpoint is a breakpoint set on a line by the client
+type SourceBreakpoint struct {
+	Line         int    `json:"line"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+	LogMessage   string `json:"logMessage,omitempty"`
+}
+
+// Breakpoint is a breakpoint as placed by the adapter
+type Breakpoint struct {
+	ID       int     `json:"id,omitempty"`
+	Verified bool    `json:"verified"`
+	Message  string  `json:"message,omitempty"`
+	Source   *Source `json:"source,omitempty"`
+	Line     int     `json:"line,omitempty"`
+}
+
+// Thread is a thread of the debuggee; an execution has exactly one
+type Thread struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// StackFrame is a step or phase on the stack
+type StackFrame struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Source *Source `json:"source,omitempty"`
+	Line   int     `json:"line"`
+	Column int     `json:"column"`
+}
+
+// Scope is a group of variables of a frame
+type Scope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+// Variable is a variable, step output or config value
+type Variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// conn reads requests from and writes messages to a client. Writes are
+// serialized, since events are sent from the poller as well.
+type conn struct {
+	reader *bufio.Reader
+	writer io.Writer
+	mu     sync.Mutex
+	seq    int
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{reader: bufio.NewReader(r), writer: w}
+}
+
+// readRequest reads the next Content-Length framed request
+func (c *conn) readRequest() (*Request, error) {
+	headers, err := textproto.NewReader(c.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length <= 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", headers.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return nil, err
+	}
+
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &req, nil
+}
+
+// respond sends a successful response
+func (c *conn) respond(req *Request, body interface{}) error {
+	return c.write(func(seq int) interface{} {
+		return Response{Seq: seq, Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: body}
+	})
+}
+
+// fail sends an error response
+func (c *conn) fail(req *Request, err error) error {
+	return c.write(func(seq int) interface{} {
+		return Response{Seq: seq, Type: "response", RequestSeq: req.Seq, Success: false, Command: req.Command, Message: err.Error()}
+	})
+}
+
+// event sends an event
+func (c *conn) event(name string, body interface{}) error {
+	return c.write(func(seq int) interface{} {
+		return Event{Seq: seq, Type: "event", Event: name, Body: body}
+	})
+}
+
+func (c *conn) write(message func(seq int) interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	data, err := json.Marshal(message(c.seq))
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(data)
+	return err
+}
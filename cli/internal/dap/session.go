@@ -0,0 +1,810 @@
+package dap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// threadID is the only thread: parallel branches of a flow are reported on
+// the stack of the step that paused
+const threadID = 1
+
+// pollInterval is how often the state of a running execution is fetched
+const pollInterval = 200 * time.Millisecond
+
+// launchArguments are the arguments of a launch request
+type launchArguments struct {
+	Program     string            `json:"program"`
+	Environment string            `json:"environment"`
+	Variables   map[string]string `json:"variables"`
+	StopOnEntry bool              `json:"stopOnEntry"`
+}
+
+// variableRef is a value the client can expand by its variables reference
+type variableRef struct {
+	value  interface{}
+	scope  string // "variables", "outputs" or "config"
+	stepID string // Step of a step output map
+	depth  int    // 0 for scopes, 1 for their entries
+}
+
+// Session is a debug session of one client. It runs a single flow file on
+// the server and follows its debug session by polling.
+type Session struct {
+	conn   *conn
+	client *Client
+
+	mu               sync.Mutex
+	launch           *launchArguments
+	source           *SourceMap
+	configured       bool
+	executionID      string
+	lineBreakpoints  map[string][]SourceBreakpoint // By absolute path
+	exceptionFilters []string
+	serverIDs        []string // Breakpoints added to the running session
+	state            *DebugState
+	stopped          bool
+	stops            int
+	entryReason      string // Reason of a pause before the first step
+	history          *history
+	restarting       bool
+	refs             map[int]variableRef
+	done             chan struct{}
+}
+
+// Serve runs a session over a client connection until it disconnects
+func Serve(r io.Reader, w io.Writer, client *Client) error {
+	s := &Session{
+		conn:            newConn(r, w),
+		client:          client,
+		lineBreakpoints: make(map[string][]SourceBreakpoint),
+		refs:            make(map[int]variableRef),
+		done:            make(chan struct{}),
+	}
+	defer s.close()
+
+	for {
+		req, err := s.conn.readRequest()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if req.Type != "request" {
+			continue
+		}
+
+		disconnect := req.Command == "disconnect"
+		if err := s.handle(req); err != nil {
+			s.conn.fail(req, err)
+		}
+		if disconnect {
+			return nil
+		}
+	}
+}
+
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+// handle answers a request
+func (s *Session) handle(req *Request) error {
+	switch req.Command {
+	case "initialize":
+		if err := s.conn.respond(req, Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsConditionalBreakpoints:   true,
+			SupportsLogPoints:                true,
+			SupportsEvaluateForHovers:        true,
+			SupportsSetVariable:              true,
+			SupportsTerminateRequest:         true,
+			SupportsStepBack:                 true,
+			SupportsRestartFrame:             true,
+			ExceptionBreakpointFilters: []ExceptionBreakpointFilter{
+				{Filter: "error", Label: "Failed steps"},
+				{Filter: "assertion", Label: "Failed assertions"},
+			},
+		}); err != nil {
+			return err
+		}
+		return s.conn.event("initialized", nil)
+
+	case "launch":
+		var args launchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return fmt.Errorf("invalid launch arguments: %w", err)
+		}
+		if args.Program == "" {
+			return fmt.Errorf("program is required")
+		}
+		source, err := LoadSourceMap(args.Program)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.launch = &args
+		s.source = source
+		s.mu.Unlock()
+		if err := s.conn.respond(req, nil); err != nil {
+			return err
+		}
+		s.startOrTerminate()
+		return nil
+
+	case "setBreakpoints":
+		return s.setBreakpoints(req)
+
+	case "setExceptionBreakpoints":
+		var args struct {
+			Filters []string `json:"filters"`
+		}
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return fmt.Errorf("invalid arguments: %w", err)
+		}
+		s.mu.Lock()
+		s.exceptionFilters = args.Filters
+		s.mu.Unlock()
+		if err := s.syncBreakpoints(); err != nil {
+			return err
+		}
+		return s.conn.respond(req, nil)
+
+	case "configurationDone":
+		s.mu.Lock()
+		s.configured = true
+		s.mu.Unlock()
+		if err := s.conn.respond(req, nil); err != nil {
+			return err
+		}
+		s.startOrTerminate()
+		return nil
+
+	case "threads":
+		name := "flow"
+		s.mu.Lock()
+		if s.source != nil {
+			name = s.source.Name
+		}
+		s.mu.Unlock()
+		return s.conn.respond(req, map[string]interface{}{
+			"threads": []Thread{{ID: threadID, Name: name}},
+		})
+
+	case "stackTrace":
+		frames := s.stackFrames()
+		return s.conn.respond(req, map[string]interface{}{
+			"stackFrames": frames,
+			"totalFrames": len(frames),
+		})
+
+	case "scopes":
+		return s.scopes(req)
+
+	case "variables":
+		return s.variables(req)
+
+	case "setVariable":
+		return s.setVariable(req)
+
+	case "evaluate":
+		return s.evaluate(req)
+
+	case "continue":
+		if reason, ok := s.travel(true); ok {
+			if err := s.conn.respond(req, map[string]interface{}{"allThreadsContinued": true}); err != nil {
+				return err
+			}
+			return s.conn.event("stopped", stoppedEvent(reason))
+		}
+		if err := s.command("resume"); err != nil {
+			return err
+		}
+		return s.conn.respond(req, map[string]interface{}{"allThreadsContinued": true})
+
+	case "next", "stepIn", "stepOut":
+		if reason, ok := s.travel(false); ok {
+			if err := s.conn.respond(req, nil); err != nil {
+				return err
+			}
+			return s.conn.event("stopped", stoppedEvent(reason))
+		}
+		if err := s.command("step-over"); err != nil {
+			return err
+		}
+		return s.conn.respond(req, nil)
+
+	case "stepBack", "reverseContinue":
+		reason, err := s.stepBack(req.Command == "reverseContinue")
+		if err != nil {
+			return err
+		}
+		if err := s.conn.respond(req, nil); err != nil {
+			return err
+		}
+		return s.conn.event("stopped", stoppedEvent(reason))
+
+	case "restartFrame":
+		return s.restartFrame(req)
+
+	case "pause":
+		executionID := s.execution()
+		if executionID == "" {
+			return fmt.Errorf("flow is not running")
+		}
+		if err := s.client.Command(executionID, "pause"); err != nil {
+			return err
+		}
+		return s.conn.respond(req, nil)
+
+	case "terminate", "disconnect":
+		if executionID := s.execution(); executionID != "" {
+			// The session is gone once the execution has finished
+			s.client.Command(executionID, "stop")
+		}
+		return s.conn.respond(req, nil)
+
+	default:
+		return fmt.Errorf("unsupported request %q", req.Command)
+	}
+}
+
+// startOrTerminate starts the execution, ending the session when the server
+// rejects it
+func (s *Session) startOrTerminate() {
+	if err := s.start(); err != nil {
+		s.conn.event("output", map[string]string{"category": "stderr", "output": err.Error() + "\n"})
+		s.conn.event("terminated", nil)
+	}
+}
+
+// start starts the execution of the flow file once the client has both
+// launched and configured the session
+func (s *Session) start() error {
+	s.mu.Lock()
+	if s.launch == nil || !s.configured || s.executionID != "" {
+		s.mu.Unlock()
+		return nil
+	}
+	launch, source := s.launch, s.source
+	breakpoints, _ := s.breakpointRequests()
+	s.mu.Unlock()
+
+	executionID, err := s.client.StartExecution(source.Data, launch.Environment, launch.Variables, breakpoints, launch.StopOnEntry)
+	if err != nil {
+		return fmt.Errorf("failed to start execution: %w", err)
+	}
+
+	s.mu.Lock()
+	s.executionID = executionID
+	if launch.StopOnEntry {
+		s.entryReason = "entry"
+	}
+	s.mu.Unlock()
+
+	s.conn.event("output", map[string]string{
+		"category": "console",
+		"output":   fmt.Sprintf("Started execution %s of %s\n", executionID, source.Name),
+	})
+	go s.poll()
+	return nil
+}
+
+// setBreakpoints places the breakpoints of a file on the steps of their lines
+func (s *Session) setBreakpoints(req *Request) error {
+	var args struct {
+		Source      Source             `json:"source"`
+		Breakpoints []SourceBreakpoint `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	path := absPath(args.Source.Path)
+	source, err := LoadSourceMap(path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lineBreakpoints[path] = args.Breakpoints
+	s.mu.Unlock()
+
+	result := make([]Breakpoint, len(args.Breakpoints))
+	for i, bp := range args.Breakpoints {
+		result[i] = Breakpoint{ID: i + 1, Source: &args.Source, Line: bp.Line}
+		if step := source.StepAt(bp.Line); step != nil {
+			result[i].Verified = true
+			result[i].Line = step.Line
+		} else {
+			result[i].Message = "No step on this line"
+		}
+	}
+
+	if err := s.syncBreakpoints(); err != nil {
+		return err
+	}
+	return s.conn.respond(req, map[string]interface{}{"breakpoints": result})
+}
+
+// breakpointRequests returns the breakpoints of the launched flow as the
+// server accepts them. Must be called with the lock held.
+func (s *Session) breakpointRequests() ([]BreakpointRequest, error) {
+	var requests []BreakpointRequest
+	if s.source != nil {
+		for _, bp := range s.lineBreakpoints[absPath(s.source.Path)] {
+			step := s.source.StepAt(bp.Line)
+			if step == nil {
+				continue
+			}
+			request := BreakpointRequest{StepID: step.ID, Type: "step", LogPoint: bp.LogMessage}
+			if bp.Condition != "" {
+				request.Type = "conditional"
+				request.Condition = bp.Condition
+			}
+			requests = append(requests, request)
+		}
+	}
+	for _, filter := range s.exceptionFilters {
+		switch filter {
+		case "error", "assertion":
+			requests = append(requests, BreakpointRequest{Type: filter})
+		default:
+			return nil, fmt.Errorf("unknown exception filter %q", filter)
+		}
+	}
+	return requests, nil
+}
+
+// syncBreakpoints replaces the breakpoints of a running execution
+func (s *Session) syncBreakpoints() error {
+	s.mu.Lock()
+	requests, err := s.breakpointRequests()
+	executionID, previous := s.executionID, s.serverIDs
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if executionID == "" {
+		// Sent with the execution once it starts
+		return nil
+	}
+
+	for _, id := range previous {
+		if err := s.client.RemoveBreakpoint(executionID, id); err != nil && !errors.Is(err, errNoSession) {
+			return err
+		}
+	}
+	ids := make([]string, 0, len(requests))
+	for _, request := range requests {
+		id, err := s.client.AddBreakpoint(executionID, request)
+		if err != nil {
+			if errors.Is(err, errNoSession) {
+				break
+			}
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	s.mu.Lock()
+	s.serverIDs = ids
+	s.mu.Unlock()
+	return nil
+}
+
+// command sends a command that resumes a paused execution
+func (s *Session) command(command string) error {
+	executionID := s.execution()
+	if executionID == "" {
+		return fmt.Errorf("flow is not running")
+	}
+	if err := s.client.Command(executionID, command); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.stopped = false
+	s.history = nil
+	s.refs = make(map[int]variableRef)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Session) execution() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.executionID
+}
+
+// poll follows the execution, reporting stops and its end to the client. The
+// execution is read on every tick, since restarting a frame replaces it.
+func (s *Session) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		executionID, restarting := s.executionID, s.restarting
+		s.mu.Unlock()
+		if restarting {
+			continue
+		}
+
+		state, err := s.client.GetState(executionID)
+		if errors.Is(err, errNoSession) {
+			if s.finished(executionID) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		if state.State != "paused" || s.stopped || executionID != s.executionID {
+			s.mu.Unlock()
+			continue
+		}
+		s.stopped = true
+		s.stops++
+		s.state = state
+		s.refs = make(map[int]variableRef)
+		reason := stopReason(state.StopReason)
+		if s.stops == 1 && s.entryReason != "" && state.StopReason == "pause" {
+			reason = s.entryReason
+		}
+		s.mu.Unlock()
+
+		body := stoppedEvent(reason)
+		if state.LastError != "" {
+			body["description"] = "Step failed"
+			body["text"] = state.LastError
+		}
+		s.conn.event("stopped", body)
+	}
+}
+
+// finished reports the end of an execution once the server has finished it
+func (s *Session) finished(executionID string) bool {
+	execution, err := s.client.GetExecution(executionID)
+	if err != nil {
+		return false
+	}
+	if s.execution() != executionID {
+		// Replaced by a restart
+		return false
+	}
+
+	exitCode := 0
+	switch execution.Status {
+	case "completed":
+	case "failed", "cancelled":
+		exitCode = 1
+	default:
+		return false
+	}
+
+	output := fmt.Sprintf("Execution %s: %d passed, %d failed\n", execution.Status, execution.PassedSteps, execution.FailedSteps)
+	if execution.Error != "" {
+		output += execution.Error + "\n"
+	}
+	s.conn.event("output", map[string]string{"category": "console", "output": output})
+	s.conn.event("exited", map[string]int{"exitCode": exitCode})
+	s.conn.event("terminated", nil)
+	return true
+}
+
+// stackFrames returns the stack of the paused execution, innermost first. The
+// outermost frame is the phase, on the line of its key.
+func (s *Session) stackFrames() []StackFrame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stack := s.viewStack()
+	if len(stack) == 0 {
+		return []StackFrame{}
+	}
+
+	source := &Source{Name: filepath.Base(s.source.Path), Path: absPath(s.source.Path)}
+	frames := make([]StackFrame, 0, len(stack)+1)
+	for i := len(stack) - 1; i >= 0; i-- {
+		frame := stack[i]
+		name := frame.StepID
+		if frame.StepName != "" {
+			name = fmt.Sprintf("%s (%s)", frame.StepName, frame.StepID)
+		}
+		line := s.source.Phases[frame.Phase]
+		if step := s.source.Step(frame.StepID); step != nil {
+			line = step.Line
+		}
+		frames = append(frames, StackFrame{ID: i + 1, Name: name, Source: source, Line: line, Column: 1})
+	}
+
+	phase := stack[0].Phase
+	frames = append(frames, StackFrame{
+		ID:     len(stack) + 1,
+		Name:   phase,
+		Source: source,
+		Line:   s.source.Phases[phase],
+		Column: 1,
+	})
+	return frames
+}
+
+// scopes returns the variables, step outputs and, for the paused step, its
+// interpolated config
+func (s *Session) scopes(req *Request) error {
+	var args struct {
+		FrameID int `json:"frameId"`
+	}
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.state == nil || !s.stopped {
+		s.mu.Unlock()
+		return fmt.Errorf("execution is not paused")
+	}
+	if snapshot := s.history.current(); snapshot != nil {
+		scopes := []Scope{
+			{Name: "Variables", VariablesReference: s.addRef(variableRef{value: snapshotVariables(snapshot), scope: "history"})},
+			{Name: "Step Outputs", VariablesReference: s.addRef(variableRef{value: snapshotOutputs(snapshot), scope: "history"})},
+		}
+		s.mu.Unlock()
+		return s.conn.respond(req, map[string]interface{}{"scopes": scopes})
+	}
+	variables := make(map[string]interface{})
+	for name, value := range s.state.Variables {
+		// Step outputs are also reported as $<step_id> variables
+		if !strings.HasPrefix(name, "$") {
+			variables[name] = value
+		}
+	}
+	scopes := []Scope{
+		{Name: "Variables", VariablesReference: s.addRef(variableRef{value: variables, scope: "variables"})},
+		{Name: "Step Outputs", VariablesReference: s.addRef(variableRef{value: s.state.StepOutputs, scope: "outputs"})},
+	}
+	if args.FrameID == len(s.state.Stack) && s.state.StepConfig != nil {
+		scopes = append(scopes, Scope{Name: "Step Config", VariablesReference: s.addRef(variableRef{value: s.state.StepConfig, scope: "config"})})
+	}
+	s.mu.Unlock()
+
+	return s.conn.respond(req, map[string]interface{}{"scopes": scopes})
+}
+
+// variables expands a variables reference
+func (s *Session) variables(req *Request) error {
+	var args struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	ref, ok := s.refs[args.VariablesReference]
+	if !ok {
+		s.mu.Unlock()
+		return s.conn.respond(req, map[string]interface{}{"variables": []Variable{}})
+	}
+	variables := s.children(ref)
+	s.mu.Unlock()
+
+	return s.conn.respond(req, map[string]interface{}{"variables": variables})
+}
+
+// children returns the entries of a map or list. Must be called with the lock
+// held.
+func (s *Session) children(ref variableRef) []Variable {
+	var variables []Variable
+	add := func(name string, value interface{}) {
+		child := variableRef{value: value, scope: ref.scope, stepID: ref.stepID, depth: ref.depth + 1}
+		if ref.scope == "outputs" && ref.depth == 0 {
+			// Entries of the scope are steps; their entries are outputs
+			child.stepID = name
+		}
+		variable := Variable{Name: name, Value: formatValue(value), Type: valueType(value)}
+		if expandable(value) {
+			variable.VariablesReference = s.addRef(child)
+		}
+		variables = append(variables, variable)
+	}
+
+	switch value := ref.value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(name, value[name])
+		}
+	case []interface{}:
+		for i, item := range value {
+			add(fmt.Sprintf("[%d]", i), item)
+		}
+	}
+	if variables == nil {
+		variables = []Variable{}
+	}
+	return variables
+}
+
+// setVariable changes a variable or a step output of the paused execution.
+// Values are parsed as JSON, falling back to a string.
+func (s *Session) setVariable(req *Request) error {
+	var args struct {
+		VariablesReference int    `json:"variablesReference"`
+		Name               string `json:"name"`
+		Value              string `json:"value"`
+	}
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	ref, ok := s.refs[args.VariablesReference]
+	executionID := s.executionID
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("variable not found")
+	}
+
+	var stepID string
+	switch {
+	case ref.scope == "variables" && ref.depth == 0:
+	case ref.scope == "outputs" && ref.depth == 1:
+		stepID = ref.stepID
+	case ref.scope == "history":
+		return fmt.Errorf("recorded steps cannot be changed; restart the frame to run again from this step")
+	default:
+		return fmt.Errorf("only variables and step outputs can be changed")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(args.Value), &value); err != nil {
+		value = args.Value
+	}
+	if err := s.client.SetVariable(executionID, stepID, args.Name, value); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if m, ok := ref.value.(map[string]interface{}); ok {
+		m[args.Name] = value
+	}
+	s.mu.Unlock()
+
+	return s.conn.respond(req, map[string]interface{}{
+		"value": formatValue(value),
+		"type":  valueType(value),
+	})
+}
+
+// evaluate evaluates an expr expression, as in assertions and conditions
+func (s *Session) evaluate(req *Request) error {
+	var args struct {
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	executionID, inHistory := s.executionID, s.history.current() != nil
+	s.mu.Unlock()
+	if executionID == "" {
+		return fmt.Errorf("flow is not running")
+	}
+	if inHistory {
+		return fmt.Errorf("expressions are evaluated at the paused step; step forward to return to it")
+	}
+	result, err := s.client.Evaluate(executionID, args.Expression)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"result":             formatValue(result),
+		"type":               valueType(result),
+		"variablesReference": 0,
+	}
+	if expandable(result) {
+		s.mu.Lock()
+		body["variablesReference"] = s.addRef(variableRef{value: result, scope: "result"})
+		s.mu.Unlock()
+	}
+	return s.conn.respond(req, body)
+}
+
+// addRef registers a value for expansion. Must be called with the lock held.
+func (s *Session) addRef(ref variableRef) int {
+	id := len(s.refs) + 1
+	s.refs[id] = ref
+	return id
+}
+
+// stopReason maps the stop reasons of the server to those of DAP
+func stopReason(reason string) string {
+	switch reason {
+	case "breakpoint", "step", "pause", "exception":
+		return reason
+	default:
+		return "pause"
+	}
+}
+
+func expandable(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("{%d}", len(v))
+	case []interface{}:
+		return fmt.Sprintf("[%d]", len(v))
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return ""
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
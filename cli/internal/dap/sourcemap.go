@@ -0,0 +1,202 @@
+package dap
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// phases are the step lists of a flow in execution order, with the names the
+// server reports them under
+var phases = []struct {
+	Key  string
+	Name string
+}{
+	{"setup", "setup"},
+	{"steps", "main"},
+	{"teardown", "teardown"},
+}
+
+// StepLocation is the place of a step in a flow file
+type StepLocation struct {
+	ID      string
+	Name    string
+	Action  string
+	Phase   string
+	Line    int // Line of the first key of the step
+	EndLine int // Last line of the step, including nested steps
+	Parent  *StepLocation
+}
+
+// SourceMap maps the steps of a flow file to their lines. Step IDs follow
+// the server: steps without an id are named <phase>_<index>, and steps of
+// parallel branches <branch>_<index>.
+type SourceMap struct {
+	Path   string
+	Name   string
+	Data   []byte
+	Steps  []*StepLocation
+	Phases map[string]int // Line of each phase key
+}
+
+// LoadSourceMap reads and maps a flow file
+func LoadSourceMap(path string) (*SourceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flow file: %w", err)
+	}
+	m, err := ParseSourceMap(data)
+	if err != nil {
+		return nil, err
+	}
+	m.Path = path
+	return m, nil
+}
+
+// ParseSourceMap maps the steps of a flow document
+func ParseSourceMap(data []byte) (*SourceMap, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse flow: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("flow must be a YAML mapping")
+	}
+
+	// Flows are either wrapped in flow: or at the top level
+	root := doc.Content[0]
+	if flow := mappingValue(root, "flow"); flow != nil && flow.Kind == yaml.MappingNode {
+		root = flow
+	}
+
+	m := &SourceMap{Data: data, Phases: make(map[string]int)}
+	if name := mappingValue(root, "name"); name != nil {
+		m.Name = name.Value
+	}
+	if m.Name == "" {
+		return nil, fmt.Errorf("flow name is required")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		for _, phase := range phases {
+			if key.Value != phase.Key || value.Kind != yaml.SequenceNode {
+				continue
+			}
+			m.Phases[phase.Name] = key.Line
+			for j, step := range value.Content {
+				m.addStep(step, phase.Name, fmt.Sprintf("%s_%d", phase.Name, j), nil)
+			}
+		}
+	}
+	return m, nil
+}
+
+// addStep maps a step and the steps of its parallel branches
+func (m *SourceMap) addStep(node *yaml.Node, phase, defaultID string, parent *StepLocation) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	loc := &StepLocation{
+		ID:      defaultID,
+		Phase:   phase,
+		Line:    node.Line,
+		EndLine: lastLine(node),
+		Parent:  parent,
+	}
+	if v := mappingValue(node, "id"); v != nil && v.Value != "" {
+		loc.ID = v.Value
+	}
+	if v := mappingValue(node, "name"); v != nil {
+		loc.Name = v.Value
+	}
+	if v := mappingValue(node, "action"); v != nil {
+		loc.Action = v.Value
+	}
+	m.Steps = append(m.Steps, loc)
+
+	if loc.Action != "parallel" {
+		return
+	}
+	config := mappingValue(node, "config")
+	if config == nil {
+		return
+	}
+
+	// Mirrors the branch names of models.ParseParallelConfig and the step IDs
+	// the executor generates inside branches
+	if branches := mappingValue(config, "branches"); branches != nil && branches.Kind == yaml.SequenceNode {
+		for i, branch := range branches.Content {
+			name := fmt.Sprintf("branch_%d", i)
+			if v := mappingValue(branch, "name"); v != nil && v.Value != "" {
+				name = v.Value
+			}
+			steps := mappingValue(branch, "steps")
+			if steps == nil || steps.Kind != yaml.SequenceNode {
+				continue
+			}
+			for j, step := range steps.Content {
+				m.addStep(step, phase, fmt.Sprintf("%s.%s.%d", loc.ID, name, j), loc)
+			}
+		}
+	}
+	if steps := mappingValue(config, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+		for i, step := range steps.Content {
+			name := fmt.Sprintf("branch_%d", i)
+			if v := mappingValue(step, "id"); v != nil && v.Value != "" {
+				name = v.Value
+			}
+			m.addStep(step, phase, fmt.Sprintf("%s.%s.0", loc.ID, name), loc)
+		}
+	}
+}
+
+// StepAt returns the innermost step on a line
+func (m *SourceMap) StepAt(line int) *StepLocation {
+	var found *StepLocation
+	for _, step := range m.Steps {
+		if line < step.Line || line > step.EndLine {
+			continue
+		}
+		if found == nil || step.Line >= found.Line {
+			found = step
+		}
+	}
+	return found
+}
+
+// Step returns the step with an ID
+func (m *SourceMap) Step(id string) *StepLocation {
+	for _, step := range m.Steps {
+		if step.ID == id {
+			return step
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value of a key of a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lastLine returns the last line a node spans
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > line {
+			line = l
+		}
+	}
+	return line
+}
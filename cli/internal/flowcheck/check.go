@@ -0,0 +1,255 @@
+// Package flowcheck checks flow files without the server
+package flowcheck
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem found in a flow file, as the server reports it
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"`
+}
+
+// Action is a built-in action
+type Action struct {
+	Description string
+	Required    []string // Config fields the action requires
+}
+
+// BuiltinActions lists the actions the runner has without plugins
+var BuiltinActions = map[string]Action{
+	"http_request":          {"Send an HTTP request", []string{"method", "url"}},
+	"database_query":        {"Run a SQL query", []string{"query", "connection"}},
+	"log":                   {"Write a message to the execution log", []string{"message"}},
+	"delay":                 {"Wait for a duration", []string{"duration"}},
+	"assert":                {"Evaluate assertions against data", []string{"data", "assertions"}},
+	"transform":             {"Extract values from data with JSON paths", []string{"input", "transforms"}},
+	"condition":             {"Evaluate a condition expression", []string{"condition"}},
+	"for_each":              {"Iterate over items", []string{"items"}},
+	"parallel":              {"Run branches of steps concurrently", nil},
+	"mock_server_start":     {"Start a mock HTTP server", []string{"name", "endpoints"}},
+	"mock_server_stop":      {"Stop a mock server", []string{"server_id"}},
+	"mock_server_configure": {"Replace the endpoints of a running mock server", []string{"server_id", "endpoints"}},
+	"contract_generate":     {"Generate a consumer contract from the HTTP steps of the execution", []string{"consumer", "provider", "version"}},
+	"contract_verify":       {"Verify a provider against a contract", []string{"contract_id", "provider_base_url"}},
+	"kafka_consumer":        {"Consume messages from a Kafka topic", []string{"brokers", "topic"}},
+	"kafka_producer":        {"Produce a message to a Kafka topic", []string{"brokers", "topic", "payload"}},
+	"wait_for":              {"Poll an HTTP endpoint or TCP port until it is ready", []string{"type"}},
+	"db_poll":               {"Poll a database query until a condition holds", []string{"connection", "query"}},
+	"websocket":             {"Connect to a WebSocket and send or receive messages", []string{"action"}},
+	"grpc":                  {"Call a gRPC method", []string{"address", "service", "method"}},
+	"graphql":               {"Send a GraphQL query, mutation or subscription", []string{"url"}},
+	"run_flow":              {"Run another flow of the workspace", []string{"flow"}},
+}
+
+// StepFields are the fields the runner reads from a step
+var StepFields = map[string]bool{
+	"id": true, "action": true, "name": true, "description": true, "config": true, "assert": true,
+	"schema": true, "snapshot": true, "output": true, "retry": true, "timeout": true,
+}
+
+// ReferenceRoots are the roots of ${root.path} references that are not step IDs
+var ReferenceRoots = map[string]bool{
+	"env": true, "data": true, "input": true, "item": true, "index": true,
+	"flow": true, "execution": true, "secrets": true, "request": true, "response": true,
+}
+
+// StepReference matches ${step.path} references
+var StepReference = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\.([\w.]+)\}`)
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// step is a step of a checked flow, in the order steps run
+type step struct {
+	node  *yaml.Node
+	id    string
+	order int
+}
+
+// Check checks a flow without the server, reporting problems with their
+// line and column like the server does. Configs are only checked for the
+// required fields of built-in actions, and namespaced actions like
+// kafka.produce are accepted, since only the server knows its plugins.
+func Check(data []byte) []Diagnostic {
+	var diagnostics []Diagnostic
+	add := func(node *yaml.Node, severity, code, message string) {
+		diagnostics = append(diagnostics, Diagnostic{Line: node.Line, Column: node.Column, Severity: severity, Code: code, Message: message})
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line := 1
+		if m := regexp.MustCompile(`line (\d+):`).FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return []Diagnostic{{Line: line, Column: 1, Severity: "error", Code: "yaml_syntax", Message: strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	if len(doc.Content) == 0 {
+		return []Diagnostic{{Line: 1, Column: 1, Severity: "error", Code: "missing_field", Message: "flow file is empty"}}
+	}
+
+	flow := doc.Content[0]
+	if wrapped := Value(flow, "flow"); wrapped != nil {
+		flow = wrapped
+	}
+	if flow.Kind != yaml.MappingNode {
+		add(flow, "error", "invalid_flow", "flow must be a mapping")
+		return diagnostics
+	}
+	if name := Value(flow, "name"); name == nil || name.Value == "" {
+		add(flow, "error", "missing_field", "flow name is required")
+	}
+	if steps := Value(flow, "steps"); steps == nil || len(steps.Content) == 0 {
+		add(flow, "error", "missing_field", "flow must have at least one step")
+	}
+
+	// Steps in the order they run, with nested steps after their parent
+	var steps []*step
+	ids := make(map[string]*step)
+	itemNames := make(map[string]bool)
+	var collect func(list *yaml.Node)
+	collect = func(list *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, node := range list.Content {
+			if node.Kind != yaml.MappingNode {
+				add(node, "error", "invalid_step", "step must be a mapping")
+				continue
+			}
+			s := &step{node: node, order: len(steps)}
+			steps = append(steps, s)
+			if id := Value(node, "id"); id != nil && id.Value != "" {
+				s.id = id.Value
+				if first, ok := ids[s.id]; ok {
+					add(id, "error", "duplicate_id", fmt.Sprintf("duplicate step ID %q, first used on line %d", s.id, first.node.Line))
+				} else {
+					ids[s.id] = s
+				}
+			}
+			config := Value(node, "config")
+			for _, key := range []string{"item_name", "index_name"} {
+				if name := Value(config, key); name != nil && name.Value != "" {
+					itemNames[name.Value] = true
+				}
+			}
+			collect(Value(config, "steps"))
+			if branches := Value(config, "branches"); branches != nil {
+				for _, branch := range branches.Content {
+					collect(Value(branch, "steps"))
+				}
+			}
+		}
+	}
+	for _, phase := range []string{"setup", "steps", "teardown"} {
+		collect(Value(flow, phase))
+	}
+
+	var checkReferences func(s *step, node *yaml.Node, isConfig bool)
+	checkReferences = func(s *step, node *yaml.Node, isConfig bool) {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			for _, match := range StepReference.FindAllStringSubmatch(node.Value, -1) {
+				root := match[1]
+				if ReferenceRoots[root] || itemNames[root] || unicode.IsUpper(rune(root[0])) {
+					continue
+				}
+				if target, ok := ids[root]; !ok {
+					add(node, "error", "undefined_reference", fmt.Sprintf("%s refers to step %q, which does not exist", match[0], root))
+				} else if target.order > s.order {
+					add(node, "warning", "forward_reference", fmt.Sprintf("%s refers to step %q, which runs later", match[0], root))
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				// Nested steps are checked as steps of their own
+				if isConfig && (node.Content[i].Value == "steps" || node.Content[i].Value == "branches") {
+					continue
+				}
+				checkReferences(s, node.Content[i+1], false)
+			}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				checkReferences(s, item, false)
+			}
+		}
+	}
+
+	for _, s := range steps {
+		for i := 0; i+1 < len(s.node.Content); i += 2 {
+			if key := s.node.Content[i]; !StepFields[key.Value] {
+				add(key, "warning", "unknown_field", fmt.Sprintf("unknown step field %q is ignored", key.Value))
+			}
+		}
+
+		action := Value(s.node, "action")
+		config := Value(s.node, "config")
+		if action == nil || action.Value == "" {
+			add(s.node, "error", "missing_field", "action is required")
+		} else if required, ok := BuiltinActions[action.Value]; ok {
+			for _, field := range required.Required {
+				if Value(config, field) == nil {
+					target := action
+					if config != nil {
+						target = config
+					}
+					add(target, "error", "invalid_config", fmt.Sprintf("%s: config: missing required property %q", action.Value, field))
+				}
+			}
+		} else if !strings.Contains(action.Value, ".") {
+			// Namespaced actions come from plugins, which only the server knows
+			add(action, "error", "unknown_action", fmt.Sprintf("unknown action %q", action.Value))
+		}
+
+		// Mock servers render their own templates per request
+		skipConfig := action != nil && strings.HasPrefix(action.Value, "mock_server_")
+		for i := 0; i+1 < len(s.node.Content); i += 2 {
+			key := s.node.Content[i].Value
+			if key == "config" && skipConfig {
+				continue
+			}
+			checkReferences(s, s.node.Content[i+1], key == "config")
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+	return diagnostics
+}
+
+// yamlValue returns the value of a key of a mapping node, or nil
+func Value(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
+)
+
+// Action is an action flows may use, with the fields of its config
+type Action struct {
+	Name        string
+	Description string
+	Plugin      string // Empty for built-in actions
+	Fields      []Field
+}
+
+// Field is a config field of an action
+type Field struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+// Catalog lists the actions of the server, or the built-in actions when the
+// server cannot be reached
+type Catalog struct {
+	actions    map[string]*Action
+	namespaces []string
+	offline    bool
+}
+
+// builtinCatalog lists the built-in actions with their required fields
+func builtinCatalog() *Catalog {
+	c := &Catalog{actions: make(map[string]*Action), offline: true}
+	for name, action := range flowcheck.BuiltinActions {
+		a := &Action{Name: name, Description: action.Description}
+		for _, field := range action.Required {
+			a.Fields = append(a.Fields, Field{Name: field, Required: true})
+		}
+		c.actions[name] = a
+	}
+	return c
+}
+
+// newCatalog reads the actions listed by the server
+func newCatalog(specs []ActionSpec, namespaces []string) *Catalog {
+	c := &Catalog{actions: make(map[string]*Action), namespaces: namespaces}
+	for _, spec := range specs {
+		c.actions[spec.Action] = &Action{
+			Name:        spec.Action,
+			Description: spec.Description,
+			Plugin:      spec.Plugin,
+			Fields:      schemaFields(spec.Schema),
+		}
+	}
+	return c
+}
+
+// Lookup returns an action, or nil
+func (c *Catalog) Lookup(name string) *Action {
+	return c.actions[name]
+}
+
+// Actions returns every action, sorted by name
+func (c *Catalog) Actions() []*Action {
+	actions := make([]*Action, 0, len(c.actions))
+	for _, action := range c.actions {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+	return actions
+}
+
+// Field returns a config field of the action, or nil
+func (a *Action) Field(name string) *Field {
+	for i := range a.Fields {
+		if a.Fields[i].Name == name {
+			return &a.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Doc documents the action and its config fields in Markdown
+func (a *Action) Doc() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**", a.Name)
+	if a.Plugin != "" {
+		fmt.Fprintf(&b, " _(plugin %s)_", a.Plugin)
+	}
+	if a.Description != "" {
+		fmt.Fprintf(&b, "\n\n%s", a.Description)
+	}
+	if len(a.Fields) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n\n| Config | Type | |\n|---|---|---|\n")
+	for _, f := range a.Fields {
+		name := "`" + f.Name + "`"
+		if f.Required {
+			name += " *(required)*"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", name, f.Type, f.Summary())
+	}
+	return b.String()
+}
+
+// Summary is the description of the field and its allowed values
+func (f *Field) Summary() string {
+	summary := f.Description
+	if len(f.Enum) > 0 {
+		if summary != "" {
+			summary += ": "
+		}
+		summary += strings.Join(f.Enum, ", ")
+	}
+	return summary
+}
+
+// schemaFields lists the properties of a config schema, required ones first
+func schemaFields(schema map[string]interface{}) []Field {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	fields := make([]Field, 0, len(properties))
+	for name, value := range properties {
+		property, _ := value.(map[string]interface{})
+		field := Field{Name: name, Required: required[name]}
+		field.Description, _ = property["description"].(string)
+
+		// Values that may be references are an anyOf of the schema and a
+		// string; the first branch is the actual type
+		if anyOf, ok := property["anyOf"].([]interface{}); ok && len(anyOf) > 0 {
+			if first, ok := anyOf[0].(map[string]interface{}); ok {
+				property = first
+				if field.Description == "" {
+					field.Description, _ = property["description"].(string)
+				}
+			}
+		}
+		field.Type = schemaType(property)
+		if values, ok := property["enum"].([]interface{}); ok {
+			for _, v := range values {
+				field.Enum = append(field.Enum, fmt.Sprint(v))
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Required != fields[j].Required {
+			return fields[i].Required
+		}
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+// schemaType describes the type of a property, like "string" or
+// "array or string"
+func schemaType(property map[string]interface{}) string {
+	switch t := property["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		types := make([]string, len(t))
+		for i, v := range t {
+			types[i] = fmt.Sprint(v)
+		}
+		return strings.Join(types, " or ")
+	}
+	if _, ok := property["enum"]; ok {
+		return "string"
+	}
+	if anyOf, ok := property["anyOf"].([]interface{}); ok {
+		var types []string
+		for _, branch := range anyOf {
+			if m, ok := branch.(map[string]interface{}); ok {
+				types = append(types, schemaType(m))
+			}
+		}
+		return strings.Join(types, " or ")
+	}
+	return "any"
+}
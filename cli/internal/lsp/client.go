@@ -0,0 +1,105 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
+)
+
+// Client talks to the TestMesh API
+type Client struct {
+	APIURL      string
+	WorkspaceID string
+	HTTP        *http.Client
+}
+
+// NewClient creates a client of a server. Requests time out quickly, so
+// that editing does not stall while the server is down.
+func NewClient(apiURL, workspaceID string) *Client {
+	return &Client{
+		APIURL:      apiURL,
+		WorkspaceID: workspaceID,
+		HTTP:        &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// ActionSpec is an action as the server lists it
+type ActionSpec struct {
+	Action      string                 `json:"action"`
+	Description string                 `json:"description"`
+	Plugin      string                 `json:"plugin"`
+	Schema      map[string]interface{} `json:"schema"` // JSON Schema of the step's config
+}
+
+// Actions lists the built-in and plugin actions of the server, and the
+// namespaces of plugins that do not describe their actions
+func (c *Client) Actions() ([]ActionSpec, []string, error) {
+	var result struct {
+		Actions    []ActionSpec `json:"actions"`
+		Namespaces []string     `json:"namespaces"`
+	}
+	if err := c.do(http.MethodGet, c.APIURL+"/api/v1/schemas/actions", nil, &result); err != nil {
+		return nil, nil, err
+	}
+	return result.Actions, result.Namespaces, nil
+}
+
+// Validate validates a flow file with the server
+func (c *Client) Validate(data []byte) ([]flowcheck.Diagnostic, error) {
+	var result struct {
+		Diagnostics []flowcheck.Diagnostic `json:"diagnostics"`
+	}
+	body := map[string]string{"yaml": string(data)}
+	if err := c.do(http.MethodPost, c.APIURL+"/api/v1/workspaces/"+c.WorkspaceID+"/flows/validate", body, &result); err != nil {
+		return nil, err
+	}
+	return result.Diagnostics, nil
+}
+
+// do sends a JSON request and decodes the response into result
+func (c *Client) do(method, endpoint string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("server error: %s", string(data))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
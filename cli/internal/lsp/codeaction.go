@@ -0,0 +1,188 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// codeActions offers refactorings of a selection
+func (s *Server) codeActions(doc *document, r Range) []CodeAction {
+	actions := []CodeAction{}
+	if action := s.extractSubFlow(doc, r); action != nil {
+		actions = append(actions, *action)
+	}
+	return actions
+}
+
+// extractSubFlow moves the selected steps into a new flow file next to the
+// document, and runs it with a run_flow step in their place. The selection
+// must start on a step of a step list; it is widened to whole steps.
+func (s *Server) extractSubFlow(doc *document, r Range) *CodeAction {
+	first := r.Start.Line
+	for first < len(doc.lines) && parseLine(doc.lines[first]).blank {
+		first++
+	}
+	last := r.End.Line
+	if r.End.Character == 0 && last > first {
+		last--
+	}
+	if first >= len(doc.lines) || last < first {
+		return nil
+	}
+
+	start := parseLine(doc.lines[first])
+	if !start.item || !doc.inStepList(first, start.indent) {
+		return nil
+	}
+	dash := strings.Index(doc.lines[first], "-")
+
+	// The selection may only hold steps of the same list
+	end := first
+	for i := first; i < len(doc.lines); i++ {
+		l := parseLine(doc.lines[i])
+		if l.blank {
+			continue
+		}
+		if i > last && (l.item && l.indent == start.indent || l.indent < start.indent) {
+			break
+		}
+		if l.indent < start.indent {
+			return nil
+		}
+		end = i
+	}
+
+	var steps []string
+	for _, text := range doc.lines[first : end+1] {
+		if len(text) >= dash && strings.TrimSpace(text[:dash]) == "" {
+			text = text[dash:]
+		}
+		steps = append(steps, text)
+	}
+
+	name := s.subFlowName(doc, first)
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	path := uniquePath(filepath.Join(filepath.Dir(doc.path), slug+".yaml"))
+	if unique := strings.TrimSuffix(filepath.Base(path), ".yaml"); unique != slug {
+		// The runner finds the flow by name, so the name is numbered like the file
+		name += " " + strings.TrimPrefix(unique, slug+"-")
+		slug = unique
+	}
+
+	var flow strings.Builder
+	fmt.Fprintf(&flow, "flow:\n  name: %q\n", name)
+	if env := doc.flowEnv(); len(env) > 0 {
+		flow.WriteString("  env:\n")
+		for _, text := range env {
+			flow.WriteString("    " + text + "\n")
+		}
+	}
+	flow.WriteString("  steps:\n")
+	for _, text := range steps {
+		if strings.TrimSpace(text) == "" {
+			flow.WriteString("\n")
+			continue
+		}
+		flow.WriteString("    " + text + "\n")
+	}
+
+	pad := strings.Repeat(" ", dash)
+	inner := strings.Repeat(" ", start.indent)
+	step := fmt.Sprintf("%s- id: %s\n%saction: run_flow\n%sconfig:\n%s  flow: %s\n",
+		pad, strings.ReplaceAll(slug, "-", "_"), inner, inner, inner, slug)
+
+	uri := pathToURI(path)
+	create := createFile{Kind: "create", URI: uri}
+	var fill, replace textDocumentEdit
+	fill.TextDocument.URI = uri
+	fill.Edits = []TextEdit{{NewText: flow.String()}}
+	version := doc.version
+	replace.TextDocument.URI = doc.uri
+	replace.TextDocument.Version = &version
+	replace.Edits = []TextEdit{{
+		Range:   Range{Start: Position{Line: first}, End: Position{Line: end + 1}},
+		NewText: step,
+	}}
+
+	count := 0
+	for i := first; i <= end; i++ {
+		if l := parseLine(doc.lines[i]); l.item && l.indent == start.indent {
+			count++
+		}
+	}
+	title := "Extract step into sub-flow"
+	if count > 1 {
+		title = fmt.Sprintf("Extract %d steps into sub-flow", count)
+	}
+	return &CodeAction{
+		Title: title,
+		Kind:  "refactor.extract",
+		Edit:  &WorkspaceEdit{DocumentChanges: []interface{}{create, fill, replace}},
+	}
+}
+
+// subFlowName names an extracted flow after the flow and its first step
+func (s *Server) subFlowName(doc *document, line int) string {
+	l := parseLine(doc.lines[line])
+	keys := doc.keys(line, l.indent)
+	label := keys["id"]
+	if label == "" {
+		label = keys["name"]
+	}
+	if label == "" {
+		label = "steps"
+	}
+	if doc.outline.name == "" {
+		return label
+	}
+	return doc.outline.name + " " + label
+}
+
+// flowEnv returns the lines of the flow's env, without their indent
+func (d *document) flowEnv() []string {
+	for i, text := range d.lines {
+		l := parseLine(text)
+		if l.key != "env" || l.item {
+			continue
+		}
+		if parent := d.parent(i, l.indent); parent >= 0 && parseLine(d.lines[parent]).key != "flow" {
+			continue
+		}
+
+		var lines []string
+		indent := -1
+		for _, text := range d.lines[i+1:] {
+			next := parseLine(text)
+			if next.blank {
+				continue
+			}
+			if next.indent <= l.indent {
+				break
+			}
+			if indent < 0 {
+				indent = next.indent
+			}
+			lines = append(lines, text[min(indent, len(text)-len(strings.TrimLeft(text, " "))):])
+		}
+		return lines
+	}
+	return nil
+}
+
+// uniquePath returns a path that does not exist yet, numbering the file name
+// when needed
+func uniquePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
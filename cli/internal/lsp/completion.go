@@ -0,0 +1,291 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
+)
+
+// builtinVariables are the variables the runner generates, referenced as
+// ${NAME}
+var builtinVariables = map[string]string{
+	"RANDOM_ID":     "Random ID",
+	"UUID":          "Random UUID",
+	"TIMESTAMP":     "Unix time in seconds",
+	"ISO_TIMESTAMP": "Current time in RFC 3339",
+	"DATE":          "Current date, 2006-01-02",
+	"TIME":          "Current time, 15:04:05",
+	"DATETIME":      "Current date and time, 2006-01-02 15:04:05",
+	"YEAR":          "Current year",
+	"MONTH":         "Current month",
+	"DAY":           "Current day of the month",
+	"HOUR":          "Current hour",
+	"MINUTE":        "Current minute",
+	"SECOND":        "Current second",
+}
+
+// flowFields are the keys of a flow
+var flowFields = map[string]string{
+	"name":        "Flow name",
+	"description": "What the flow tests",
+	"suite":       "Suite the flow belongs to",
+	"tags":        "Tags to select flows by",
+	"env":         "Variables, referenced as ${NAME}",
+	"setup":       "Steps run before the flow's steps",
+	"steps":       "Steps of the flow",
+	"teardown":    "Steps run after the flow's steps, even when they fail",
+}
+
+// stepFieldDocs describe the keys of a step
+var stepFieldDocs = map[string]string{
+	"id":          "Step ID; outputs are referenced as ${id.key}",
+	"action":      "Action the step runs",
+	"name":        "Step name",
+	"description": "What the step does",
+	"config":      "Config of the action",
+	"assert":      "Assertion expressions on the step's output",
+	"schema":      "JSON Schema assertions on the step's output",
+	"snapshot":    "Compare the output with an approved snapshot",
+	"output":      "Output names and the JSON paths extracting them",
+	"retry":       "Retry the step with max_attempts, delay and backoff",
+	"timeout":     "Step timeout, e.g. 30s",
+}
+
+// openReference matches a ${ or {{ reference being typed at the end of a line
+var openReference = regexp.MustCompile(`(\$\{|\{\{)\s*([A-Za-z0-9_.]*)$`)
+
+// complete proposes completions at a position
+func (s *Server) complete(doc *document, pos Position) []CompletionItem {
+	text := doc.line(pos.Line)
+	prefix := text[:byteOffset(text, pos.Character)]
+
+	if m := openReference.FindStringSubmatch(prefix); m != nil {
+		return s.completeReference(doc, m[2])
+	}
+
+	l := parseLine(prefix)
+	if l.key != "" {
+		return s.completeValue(doc, pos.Line, l)
+	}
+	if isKeyPrefix(prefix) {
+		return s.completeKey(doc, pos.Line, l.indent)
+	}
+	return []CompletionItem{}
+}
+
+// isKeyPrefix reports whether a line is a key being typed
+func isKeyPrefix(prefix string) bool {
+	rest := strings.TrimLeft(prefix, " ")
+	for rest == "-" || strings.HasPrefix(rest, "- ") {
+		rest = strings.TrimLeft(strings.TrimPrefix(rest, "-"), " ")
+	}
+	for _, r := range rest {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// completeKey proposes the keys of the mapping a key is typed in: config
+// fields in configs, step fields in steps and flow fields in flows
+func (s *Server) completeKey(doc *document, line, indent int) []CompletionItem {
+	parent := doc.parent(line, indent)
+	existing := doc.keys(line, indent)
+
+	var items []CompletionItem
+	add := func(name, detail, documentation string, required bool) {
+		if _, ok := existing[name]; ok {
+			return
+		}
+		item := CompletionItem{Label: name, Kind: kindField, Detail: detail, InsertText: name + ": ", SortText: "1" + name}
+		if required {
+			item.SortText = "0" + name
+		}
+		if documentation != "" {
+			item.Documentation = markdown(documentation)
+		}
+		items = append(items, item)
+	}
+
+	parentLine := parseLine(doc.line(parent))
+	switch {
+	case parent >= 0 && parentLine.key == "config":
+		action := s.actions().Lookup(doc.stepAction(parent))
+		if action == nil {
+			return []CompletionItem{}
+		}
+		for _, f := range action.Fields {
+			detail := f.Type
+			if f.Required {
+				detail = strings.TrimSpace(detail + " (required)")
+			}
+			add(f.Name, detail, f.Summary(), f.Required)
+		}
+
+	case parent >= 0 && stepLists[parentLine.key] && doc.isItem(line, indent):
+		for name := range flowcheck.StepFields {
+			add(name, stepFieldDocs[name], "", name == "action")
+		}
+
+	case parent < 0 && !hasTopLevelFlow(doc), parent >= 0 && parentLine.key == "flow":
+		for name, detail := range flowFields {
+			add(name, detail, "", name == "name" || name == "steps")
+		}
+	}
+
+	if items == nil {
+		return []CompletionItem{}
+	}
+	return items
+}
+
+// hasTopLevelFlow reports whether the flow is wrapped in a flow key
+func hasTopLevelFlow(doc *document) bool {
+	for _, text := range doc.lines {
+		if l := parseLine(text); l.key == "flow" && l.indent == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// completeValue proposes values of a key: actions, sub-flows and the
+// allowed values of config fields
+func (s *Server) completeValue(doc *document, line int, l yamlLine) []CompletionItem {
+	var items []CompletionItem
+	switch l.key {
+	case "action":
+		catalog := s.actions()
+		for _, action := range catalog.Actions() {
+			item := CompletionItem{Label: action.Name, Kind: kindFunction, Detail: action.Description, Documentation: markdown(action.Doc())}
+			if action.Plugin != "" {
+				item.SortText = "1" + action.Name
+			} else {
+				item.SortText = "0" + action.Name
+			}
+			items = append(items, item)
+		}
+		for _, namespace := range catalog.namespaces {
+			items = append(items, CompletionItem{Label: namespace, Kind: kindModule, Detail: "Plugin " + namespace, SortText: "2" + namespace})
+		}
+
+	case "flow":
+		if !doc.isSubFlow(line, l) || s.graph == nil {
+			break
+		}
+		for _, flow := range s.graph.All() {
+			if flow.Path == doc.path || flow.Name == "" {
+				continue
+			}
+			rel, err := filepath.Rel(s.root, flow.Path)
+			if err != nil {
+				rel = flow.Path
+			}
+			items = append(items, CompletionItem{Label: flow.Name, Kind: kindFile, Detail: rel})
+		}
+
+	case "backoff":
+		for _, value := range []string{"fixed", "exponential"} {
+			items = append(items, CompletionItem{Label: value, Kind: kindValue})
+		}
+	}
+
+	// Allowed values of config fields
+	if parent := doc.parent(line, l.indent); len(items) == 0 && parent >= 0 && parseLine(doc.line(parent)).key == "config" {
+		if action := s.actions().Lookup(doc.stepAction(parent)); action != nil {
+			if f := action.Field(l.key); f != nil {
+				for _, value := range f.Enum {
+					items = append(items, CompletionItem{Label: value, Kind: kindValue, Detail: f.Description})
+				}
+			}
+		}
+	}
+
+	if items == nil {
+		return []CompletionItem{}
+	}
+	return items
+}
+
+// completeReference proposes what a ${...} reference may refer to: step IDs
+// and variables, or the outputs of a step after its ID
+func (s *Server) completeReference(doc *document, typed string) []CompletionItem {
+	items := []CompletionItem{}
+
+	if root, _, ok := strings.Cut(typed, "."); ok {
+		step := doc.outline.step(root)
+		if step == nil {
+			return items
+		}
+		for _, output := range step.Outputs {
+			items = append(items, CompletionItem{Label: output, Kind: kindField, Detail: "Output of " + step.Label()})
+		}
+		return items
+	}
+
+	for i, step := range doc.outline.steps {
+		if step.ID == "" {
+			continue
+		}
+		detail := step.Action
+		if step.Name != "" {
+			detail = step.Name + " (" + step.Action + ")"
+		}
+		items = append(items, CompletionItem{
+			Label:         step.ID,
+			Kind:          kindModule,
+			Detail:        detail,
+			Documentation: markdown(stepDoc(step)),
+			SortText:      fmt.Sprintf("0%04d", i),
+		})
+	}
+
+	variables := make(map[string]string)
+	for name := range builtinVariables {
+		variables[name] = "Built-in: " + builtinVariables[name]
+	}
+	for name, path := range s.environmentVariables() {
+		variables[name] = "Environment " + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for name := range doc.outline.env {
+		variables[name] = "Flow variable"
+	}
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, CompletionItem{Label: name, Kind: kindVariable, Detail: variables[name], SortText: "1" + name})
+	}
+	return items
+}
+
+// stepDoc documents a step in Markdown
+func stepDoc(step *stepInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Step `%s`**", step.Label())
+	if step.Action != "" {
+		fmt.Fprintf(&b, " — `%s`", step.Action)
+	}
+	if step.Name != "" && step.Name != step.ID {
+		fmt.Fprintf(&b, "\n\n%s", step.Name)
+	}
+	if len(step.Outputs) > 0 {
+		b.WriteString("\n\nOutputs: ")
+		for i, output := range step.Outputs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "`%s`", output)
+		}
+	}
+	fmt.Fprintf(&b, "\n\nDefined on line %d", step.Line+1)
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package lsp
+
+// definition returns where the step or variable of a reference, or the
+// sub-flow of a run_flow step, is defined
+func (s *Server) definition(doc *document, pos Position) []Location {
+	text := doc.line(pos.Line)
+	offset := byteOffset(text, pos.Character)
+
+	if expr, _, _, ok := referenceAt(text, offset); ok {
+		root := referenceRoot(expr)
+		if step := doc.outline.step(root); step != nil {
+			idText := doc.line(step.IDLine)
+			start := runeColumn(idText, step.IDCol+1)
+			return []Location{{URI: doc.uri, Range: *lineRange(idText, step.IDLine, start, start+len(root))}}
+		}
+		if line, ok := doc.outline.env[root]; ok {
+			return []Location{{URI: doc.uri, Range: Range{Start: Position{Line: line}, End: Position{Line: line}}}}
+		}
+		if path, ok := s.environmentVariables()[root]; ok {
+			line := keyLine(path, root)
+			return []Location{{URI: pathToURI(path), Range: Range{Start: Position{Line: line}, End: Position{Line: line}}}}
+		}
+		return []Location{}
+	}
+
+	l := parseLine(text)
+	if offset < l.valueCol || !doc.isSubFlow(pos.Line, l) || s.graph == nil {
+		return []Location{}
+	}
+	flow := s.graph.FlowByName(unquote(l.value))
+	if flow == nil {
+		return []Location{}
+	}
+	line := keyLine(flow.Path, "name")
+	return []Location{{URI: pathToURI(flow.Path), Range: Range{Start: Position{Line: line}, End: Position{Line: line}}}}
+}
@@ -0,0 +1,369 @@
+package lsp
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
+	"gopkg.in/yaml.v3"
+)
+
+// stepLists are the keys holding steps: the phases of a flow and the nested
+// steps of parallel and for_each steps
+var stepLists = map[string]bool{"setup": true, "steps": true, "teardown": true}
+
+// document is an open flow file
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    string
+	lines   []string
+	outline *outline // Of the last version that parsed
+}
+
+func newDocument(uri, path string, version int, text string) *document {
+	d := &document{uri: uri, path: path}
+	d.update(version, text)
+	return d
+}
+
+// update replaces the text. The outline is kept while the text does not
+// parse, which it often does not while typing.
+func (d *document) update(version int, text string) {
+	d.version = version
+	d.text = text
+	d.lines = strings.Split(text, "\n")
+	for i, line := range d.lines {
+		d.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	if o := parseOutline([]byte(text)); o != nil {
+		d.outline = o
+	} else if d.outline == nil {
+		d.outline = &outline{env: make(map[string]int)}
+	}
+}
+
+func (d *document) line(i int) string {
+	if i < 0 || i >= len(d.lines) {
+		return ""
+	}
+	return d.lines[i]
+}
+
+// outline lists the steps and variables of a flow
+type outline struct {
+	name  string
+	steps []*stepInfo
+	env   map[string]int // Flow variables and the zero-based lines of their keys
+}
+
+// stepInfo is a step of a flow, in the order steps run
+type stepInfo struct {
+	ID      string
+	Name    string
+	Action  string
+	Line    int // Zero-based line of the step's first key
+	IDLine  int // Zero-based line and column of the ID value
+	IDCol   int
+	Outputs []string
+}
+
+// Label names the step by its ID or name
+func (s *stepInfo) Label() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Name
+}
+
+// step returns the step with an ID, or nil
+func (o *outline) step(id string) *stepInfo {
+	for _, s := range o.steps {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// parseOutline maps the steps of a flow file, or returns nil when it does
+// not parse
+func parseOutline(data []byte) *outline {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	flow := doc.Content[0]
+	if wrapped := flowcheck.Value(flow, "flow"); wrapped != nil {
+		flow = wrapped
+	}
+	if flow.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	o := &outline{env: make(map[string]int)}
+	if name := flowcheck.Value(flow, "name"); name != nil {
+		o.name = name.Value
+	}
+	if env := flowcheck.Value(flow, "env"); env != nil && env.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(env.Content); i += 2 {
+			o.env[env.Content[i].Value] = env.Content[i].Line - 1
+		}
+	}
+
+	var collect func(list *yaml.Node)
+	collect = func(list *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, node := range list.Content {
+			if node.Kind != yaml.MappingNode {
+				continue
+			}
+			s := &stepInfo{Line: node.Line - 1}
+			if id := flowcheck.Value(node, "id"); id != nil {
+				s.ID, s.IDLine, s.IDCol = id.Value, id.Line-1, id.Column-1
+			}
+			if name := flowcheck.Value(node, "name"); name != nil {
+				s.Name = name.Value
+			}
+			if action := flowcheck.Value(node, "action"); action != nil {
+				s.Action = action.Value
+			}
+			if output := flowcheck.Value(node, "output"); output != nil && output.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(output.Content); i += 2 {
+					s.Outputs = append(s.Outputs, output.Content[i].Value)
+				}
+			}
+			o.steps = append(o.steps, s)
+
+			config := flowcheck.Value(node, "config")
+			collect(flowcheck.Value(config, "steps"))
+			if branches := flowcheck.Value(config, "branches"); branches != nil {
+				for _, branch := range branches.Content {
+					collect(flowcheck.Value(branch, "steps"))
+				}
+			}
+		}
+	}
+	for _, phase := range []string{"setup", "steps", "teardown"} {
+		collect(flowcheck.Value(flow, phase))
+	}
+	return o
+}
+
+// yamlLine is a line of a flow file read on its own, so that documents are
+// understood while they are being edited and do not parse
+type yamlLine struct {
+	blank    bool   // Empty or a comment
+	indent   int    // Column of the content after any "- " markers
+	item     bool   // Starts a sequence item
+	key      string // Empty when the line is not a key
+	value    string // Text after "key:", without a trailing comment
+	valueCol int    // Byte column of the value
+}
+
+var keyPattern = regexp.MustCompile(`^([A-Za-z0-9_.\-]+|"[^"]*"|'[^']*')[ \t]*:([ \t]|$)`)
+
+func parseLine(text string) yamlLine {
+	trimmed := strings.TrimLeft(text, " ")
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return yamlLine{blank: true, indent: len(text)}
+	}
+
+	l := yamlLine{indent: len(text) - len(trimmed)}
+	for trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+		l.item = true
+		rest := strings.TrimLeft(strings.TrimPrefix(trimmed, "-"), " ")
+		l.indent += len(trimmed) - len(rest)
+		trimmed = rest
+	}
+
+	if m := keyPattern.FindStringSubmatchIndex(trimmed); m != nil {
+		l.key = strings.Trim(trimmed[m[2]:m[3]], `"'`)
+		value := trimmed[m[3]:]
+		value = value[strings.Index(value, ":")+1:]
+		rest := strings.TrimLeft(value, " \t")
+		l.valueCol = l.indent + len(trimmed) - len(rest)
+		if idx := strings.Index(rest, " #"); idx >= 0 {
+			rest = rest[:idx]
+		}
+		l.value = strings.TrimSpace(rest)
+	}
+	return l
+}
+
+// parent returns the line of the key holding the content at an indent above
+// a line, or -1 at the top level
+func (d *document) parent(line, indent int) int {
+	for i := line - 1; i >= 0; i-- {
+		l := parseLine(d.lines[i])
+		if !l.blank && l.indent < indent {
+			return i
+		}
+	}
+	return -1
+}
+
+// block returns the lines of the mapping at an indent that holds a line. In a
+// sequence, the mapping is the item holding the line.
+func (d *document) block(line, indent int) (start, end int) {
+	start = line
+	for i := line; i >= 0; i-- {
+		l := parseLine(d.lines[i])
+		if l.blank {
+			continue
+		}
+		if l.indent < indent {
+			break
+		}
+		start = i
+		if l.indent == indent && l.item {
+			break
+		}
+	}
+	end = line
+	for i := line + 1; i < len(d.lines); i++ {
+		l := parseLine(d.lines[i])
+		if l.blank {
+			continue
+		}
+		if l.indent < indent || (l.indent == indent && l.item) {
+			break
+		}
+		end = i
+	}
+	return start, end
+}
+
+// isItem reports whether the mapping at an indent holding a line is a
+// sequence item
+func (d *document) isItem(line, indent int) bool {
+	start, _ := d.block(line, indent)
+	l := parseLine(d.lines[start])
+	return l.item && l.indent == indent
+}
+
+// keys returns the keys of the mapping at an indent that holds a line, with
+// their values
+func (d *document) keys(line, indent int) map[string]string {
+	keys := make(map[string]string)
+	start, end := d.block(line, indent)
+	for i := start; i <= end; i++ {
+		if l := parseLine(d.lines[i]); l.key != "" && l.indent == indent {
+			keys[l.key] = unquote(l.value)
+		}
+	}
+	return keys
+}
+
+// stepAction returns the action of the step whose config holds a line, given
+// the line of the config key
+func (d *document) stepAction(configLine int) string {
+	l := parseLine(d.lines[configLine])
+	return d.keys(configLine, l.indent)["action"]
+}
+
+// isSubFlow reports whether a key line is the flow of a run_flow step
+func (d *document) isSubFlow(line int, l yamlLine) bool {
+	if l.key != "flow" {
+		return false
+	}
+	parent := d.parent(line, l.indent)
+	return parent >= 0 && parseLine(d.lines[parent]).key == "config" && d.stepAction(parent) == "run_flow"
+}
+
+// inStepList reports whether the content at an indent on a line belongs to a
+// step: an item of a setup, steps or teardown list
+func (d *document) inStepList(line, indent int) bool {
+	if !d.isItem(line, indent) {
+		return false
+	}
+	p := d.parent(line, indent)
+	return p >= 0 && stepLists[parseLine(d.lines[p]).key]
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// byteOffset converts a UTF-16 character offset of a line to a byte offset
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// characterOffset converts a byte offset of a line to a UTF-16 character
+// offset
+func characterOffset(line string, offset int) int {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	units := 0
+	for _, r := range line[:offset] {
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return units
+}
+
+// runeColumn converts a one-based column in runes, as YAML reports them, to
+// a byte offset
+func runeColumn(line string, column int) int {
+	offset := 0
+	for n := 1; n < column && offset < len(line); n++ {
+		_, size := utf8.DecodeRuneInString(line[offset:])
+		offset += size
+	}
+	return offset
+}
+
+var referencePattern = regexp.MustCompile(`\$\{([^}]*)\}|\{\{([^}]*)\}\}`)
+
+// referenceAt returns the ${...} or {{...}} reference around a byte offset of
+// a line, with its byte span
+func referenceAt(line string, offset int) (expr string, start, end int, ok bool) {
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(line, -1) {
+		if offset < m[0] || offset > m[1] {
+			continue
+		}
+		if m[2] >= 0 {
+			return strings.TrimSpace(line[m[2]:m[3]]), m[0], m[1], true
+		}
+		return strings.TrimSpace(line[m[4]:m[5]]), m[0], m[1], true
+	}
+	return "", 0, 0, false
+}
+
+// referenceRoot returns the step ID or variable a reference starts with
+func referenceRoot(expr string) string {
+	root, _, _ := strings.Cut(expr, ".")
+	return root
+}
+
+// keyLine returns the zero-based line of the first key with a name in a
+// file, or 0
+func keyLine(path, key string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for i, text := range strings.Split(string(data), "\n") {
+		if parseLine(text).key == key {
+			return i
+		}
+	}
+	return 0
+}
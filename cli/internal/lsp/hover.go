@@ -0,0 +1,98 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// hover documents the action, config field, reference or sub-flow under the
+// cursor
+func (s *Server) hover(doc *document, pos Position) *Hover {
+	text := doc.line(pos.Line)
+	offset := byteOffset(text, pos.Character)
+
+	if expr, start, end, ok := referenceAt(text, offset); ok {
+		contents := s.referenceDoc(doc, referenceRoot(expr))
+		if contents == "" {
+			return nil
+		}
+		return &Hover{Contents: markdown(contents), Range: lineRange(text, pos.Line, start, end)}
+	}
+
+	l := parseLine(text)
+	if l.key == "" {
+		return nil
+	}
+	onValue := offset >= l.valueCol && l.value != ""
+	parent := doc.parent(pos.Line, l.indent)
+	inConfig := parent >= 0 && parseLine(doc.line(parent)).key == "config"
+
+	switch {
+	case l.key == "action" && onValue:
+		if action := s.actions().Lookup(l.value); action != nil {
+			return &Hover{Contents: markdown(action.Doc()), Range: lineRange(text, pos.Line, l.valueCol, l.valueCol+len(l.value))}
+		}
+
+	case onValue && doc.isSubFlow(pos.Line, l):
+		if s.graph == nil {
+			return nil
+		}
+		if flow := s.graph.FlowByName(unquote(l.value)); flow != nil {
+			rel, err := filepath.Rel(s.root, flow.Path)
+			if err != nil {
+				rel = flow.Path
+			}
+			contents := fmt.Sprintf("**Flow %s**\n\n`%s`", flow.Name, rel)
+			if len(flow.Tags) > 0 {
+				contents += "\n\nTags: " + strings.Join(flow.Tags, ", ")
+			}
+			return &Hover{Contents: markdown(contents)}
+		}
+
+	case inConfig && !onValue:
+		action := s.actions().Lookup(doc.stepAction(parent))
+		if action == nil {
+			return nil
+		}
+		if f := action.Field(l.key); f != nil {
+			contents := fmt.Sprintf("**%s** `%s`", action.Name, f.Name)
+			if f.Type != "" {
+				contents += " — " + f.Type
+			}
+			if f.Required {
+				contents += ", required"
+			}
+			if summary := f.Summary(); summary != "" {
+				contents += "\n\n" + summary
+			}
+			return &Hover{Contents: markdown(contents)}
+		}
+	}
+	return nil
+}
+
+// referenceDoc documents the step or variable a reference starts with
+func (s *Server) referenceDoc(doc *document, root string) string {
+	if step := doc.outline.step(root); step != nil {
+		return stepDoc(step)
+	}
+	if line, ok := doc.outline.env[root]; ok {
+		return fmt.Sprintf("**%s**\n\nFlow variable, defined on line %d", root, line+1)
+	}
+	if description, ok := builtinVariables[root]; ok {
+		return fmt.Sprintf("**%s**\n\n%s", root, description)
+	}
+	if path, ok := s.environmentVariables()[root]; ok {
+		return fmt.Sprintf("**%s**\n\nDefined in environment `%s`", root, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	}
+	return ""
+}
+
+// lineRange converts a byte span of a line to a range
+func lineRange(text string, line, start, end int) *Range {
+	return &Range{
+		Start: Position{Line: line, Character: characterOffset(text, start)},
+		End:   Position{Line: line, Character: characterOffset(text, end)},
+	}
+}
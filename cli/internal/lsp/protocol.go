@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeRequestFailed  = -32803
+)
+
+// Message is a JSON-RPC request or notification of the client. Notifications
+// have no ID.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response answers a request
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+}
+
+// errorResponse answers a request that failed
+type errorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   responseError   `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// notification is sent to the client without expecting an answer
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// Position is a zero-based line and UTF-16 character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document, with an exclusive end
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range of a file
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic is a problem shown in the editor
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"` // 1 error, 2 warning
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// TextEdit replaces a range of a document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit changes documents and creates files. The changes are listed
+// in documentChanges, in the order they are applied.
+type WorkspaceEdit struct {
+	DocumentChanges []interface{} `json:"documentChanges"`
+}
+
+// createFile creates a file as part of a workspace edit
+type createFile struct {
+	Kind    string `json:"kind"`
+	URI     string `json:"uri"`
+	Options struct {
+		IgnoreIfExists bool `json:"ignoreIfExists"`
+	} `json:"options"`
+}
+
+// textDocumentEdit edits an open or created document
+type textDocumentEdit struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version *int   `json:"version"`
+	} `json:"textDocument"`
+	Edits []TextEdit `json:"edits"`
+}
+
+// CompletionItem is a completion proposal
+type CompletionItem struct {
+	Label         string         `json:"label"`
+	Kind          int            `json:"kind,omitempty"`
+	Detail        string         `json:"detail,omitempty"`
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+	InsertText    string         `json:"insertText,omitempty"`
+	SortText      string         `json:"sortText,omitempty"`
+}
+
+// Completion item kinds
+const (
+	kindFunction = 3
+	kindField    = 5
+	kindVariable = 6
+	kindModule   = 9
+	kindValue    = 12
+	kindFile     = 17
+)
+
+// MarkupContent is Markdown shown in hovers and completion details
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+func markdown(text string) *MarkupContent {
+	return &MarkupContent{Kind: "markdown", Value: text}
+}
+
+// Hover is the documentation of the symbol under the cursor
+type Hover struct {
+	Contents *MarkupContent `json:"contents"`
+	Range    *Range         `json:"range,omitempty"`
+}
+
+// CodeAction is a refactoring offered for a selection
+type CodeAction struct {
+	Title string         `json:"title"`
+	Kind  string         `json:"kind"`
+	Edit  *WorkspaceEdit `json:"edit"`
+}
+
+// textDocumentPositionParams are the params of completion, hover and
+// definition requests
+type textDocumentPositionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position Position `json:"position"`
+}
+
+// conn reads messages from and writes messages to a client. Writes are
+// serialized, since diagnostics are published while requests are answered.
+type conn struct {
+	reader *bufio.Reader
+	writer io.Writer
+	mu     sync.Mutex
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{reader: bufio.NewReader(r), writer: w}
+}
+
+// readMessage reads the next Content-Length framed message
+func (c *conn) readMessage() (*Message, error) {
+	headers, err := textproto.NewReader(c.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length <= 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", headers.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return nil, err
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &msg, nil
+}
+
+// reply answers a request
+func (c *conn) reply(id json.RawMessage, result interface{}) error {
+	return c.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// fail answers a request with an error
+func (c *conn) fail(id json.RawMessage, code int, err error) error {
+	return c.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: err.Error()}})
+}
+
+// notify sends a notification
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *conn) write(message interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(data)
+	return err
+}
@@ -0,0 +1,422 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georgi-georgiev/testmesh-cli/internal/flowcheck"
+	"github.com/georgi-georgiev/testmesh-cli/internal/watcher"
+	"gopkg.in/yaml.v3"
+)
+
+// catalogRetry is how long the server is left alone after it could not list
+// its actions
+const catalogRetry = 30 * time.Second
+
+// Server is a language server of one client. Flow files are validated by the
+// TestMesh server when it can be reached, and checked locally otherwise.
+type Server struct {
+	conn   *conn
+	client *Client
+
+	mu          sync.Mutex
+	docs        map[string]*document // By URI
+	root        string
+	graph       *watcher.Graph
+	catalog     *Catalog
+	catalogTime time.Time
+	shutdown    bool
+}
+
+// Serve runs a server over a client connection until the client exits
+func Serve(r io.Reader, w io.Writer, client *Client) error {
+	s := &Server{
+		conn:   newConn(r, w),
+		client: client,
+		docs:   make(map[string]*document),
+	}
+
+	for {
+		msg, err := s.conn.readMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			// Notifications are not answered
+			continue
+		}
+		if err != nil {
+			code := codeRequestFailed
+			var rpcErr *rpcError
+			if errors.As(err, &rpcErr) {
+				code = rpcErr.code
+			}
+			s.conn.fail(msg.ID, code, err)
+			continue
+		}
+		s.conn.reply(msg.ID, result)
+	}
+}
+
+// rpcError is an error with a JSON-RPC error code
+type rpcError struct {
+	code int
+	err  error
+}
+
+func (e *rpcError) Error() string { return e.err.Error() }
+
+// handle answers a request or handles a notification
+func (s *Server) handle(msg *Message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			RootURI          string `json:"rootUri"`
+			RootPath         string `json:"rootPath"`
+			WorkspaceFolders []struct {
+				URI string `json:"uri"`
+			} `json:"workspaceFolders"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err}
+		}
+		root := params.RootPath
+		if len(params.WorkspaceFolders) > 0 {
+			root = uriToPath(params.WorkspaceFolders[0].URI)
+		} else if params.RootURI != "" {
+			root = uriToPath(params.RootURI)
+		}
+		if root == "" {
+			root, _ = os.Getwd()
+		}
+		s.mu.Lock()
+		s.root = root
+		s.mu.Unlock()
+
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // Full text
+					"save":      map[string]interface{}{"includeText": false},
+				},
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"{", ".", ":", " "},
+				},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"codeActionProvider": map[string]interface{}{
+					"codeActionKinds": []string{"refactor.extract"},
+				},
+			},
+			"serverInfo": map[string]string{"name": "testmesh"},
+		}, nil
+
+	case "initialized":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.loadGraph()
+		s.loadCatalog()
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+				Text    string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		doc := newDocument(params.TextDocument.URI, uriToPath(params.TextDocument.URI), params.TextDocument.Version, params.TextDocument.Text)
+		s.mu.Lock()
+		s.docs[doc.uri] = doc
+		s.mu.Unlock()
+		s.publishDiagnostics(doc.uri)
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// Changes are full texts, so the last one is the document
+		if doc := s.docs[params.TextDocument.URI]; doc != nil && len(params.ContentChanges) > 0 {
+			doc.update(params.TextDocument.Version, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didSave":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if s.graph != nil {
+			s.graph.Update(uriToPath(params.TextDocument.URI))
+		}
+		s.mu.Unlock()
+		s.publishDiagnostics(params.TextDocument.URI)
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.mu.Unlock()
+		// Problems of closed files are not shown
+		return nil, s.conn.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []Diagnostic{},
+		})
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return []CompletionItem{}, nil
+		}
+		return s.complete(doc, params.Position), nil
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		return s.hover(doc, params.Position), nil
+
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		return s.definition(doc, params.Position), nil
+
+	case "textDocument/codeAction":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+			Range Range `json:"range"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return []CodeAction{}, nil
+		}
+		return s.codeActions(doc, params.Range), nil
+	}
+
+	if msg.ID != nil {
+		return nil, &rpcError{codeMethodNotFound, fmt.Errorf("method %q is not supported", msg.Method)}
+	}
+	return nil, nil
+}
+
+// publishDiagnostics validates a document and sends its problems to the
+// client
+func (s *Server) publishDiagnostics(uri string) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	var text string
+	var lines []string
+	if doc != nil {
+		text, lines = doc.text, doc.lines
+	}
+	s.mu.Unlock()
+	if doc == nil {
+		return
+	}
+
+	found, err := s.client.Validate([]byte(text))
+	if err != nil {
+		found = flowcheck.Check([]byte(text))
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(found))
+	for _, d := range found {
+		severity := 1
+		if d.Severity == "warning" {
+			severity = 2
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    tokenRange(lines, d.Line-1, d.Column),
+			Severity: severity,
+			Code:     d.Code,
+			Source:   "testmesh",
+			Message:  d.Message,
+		})
+	}
+	s.conn.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// tokenRange returns the range of the token starting at a one-based rune
+// column of a line
+func tokenRange(lines []string, line, column int) Range {
+	if line < 0 || line >= len(lines) {
+		return Range{Start: Position{Line: max(line, 0)}, End: Position{Line: max(line, 0)}}
+	}
+	text := lines[line]
+	start := runeColumn(text, column)
+	end := start
+	for end < len(text) && text[end] != ' ' && text[end] != '\t' {
+		end++
+	}
+	return Range{
+		Start: Position{Line: line, Character: characterOffset(text, start)},
+		End:   Position{Line: line, Character: characterOffset(text, end)},
+	}
+}
+
+// loadGraph reads the flows, mocks and environments of the workspace
+func (s *Server) loadGraph() {
+	if s.root == "" {
+		return
+	}
+	graph, err := watcher.BuildGraph(s.root, watcher.DefaultConfig().IgnorePatterns)
+	if err != nil {
+		s.conn.notify("window/logMessage", map[string]interface{}{"type": 2, "message": "failed to read workspace: " + err.Error()})
+		return
+	}
+	s.graph = graph
+}
+
+// actions returns the catalog of actions, asking the server again when it
+// could not be reached a while ago
+func (s *Server) actions() *Catalog {
+	if s.catalog == nil || (s.catalog.offline && time.Since(s.catalogTime) > catalogRetry) {
+		s.loadCatalog()
+	}
+	return s.catalog
+}
+
+func (s *Server) loadCatalog() {
+	s.catalogTime = time.Now()
+	specs, namespaces, err := s.client.Actions()
+	if err != nil {
+		if s.catalog == nil {
+			s.conn.notify("window/logMessage", map[string]interface{}{
+				"type":    2,
+				"message": "TestMesh server not reachable, completing built-in actions only: " + err.Error(),
+			})
+		}
+		s.catalog = builtinCatalog()
+		return
+	}
+	s.catalog = newCatalog(specs, namespaces)
+}
+
+// environmentVariables returns the variables of the workspace's environment
+// files, with the file each is defined in
+func (s *Server) environmentVariables() map[string]string {
+	variables := make(map[string]string)
+	if s.graph == nil {
+		return variables
+	}
+	for _, path := range s.graph.Environments {
+		var env struct {
+			Env map[string]interface{} `yaml:"env"`
+		}
+		if data, err := os.ReadFile(path); err == nil && yaml.Unmarshal(data, &env) == nil {
+			for key := range env.Env {
+				if _, ok := variables[key]; !ok {
+					variables[key] = path
+				}
+			}
+		}
+	}
+	return variables
+}
+
+// uriToPath converts a file URI to a path
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	// Windows paths are written as file:///C:/...
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI converts a path to a file URI
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,357 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flow is a flow file and the files it depends on
+type Flow struct {
+	Path     string
+	Name     string
+	Tags     []string
+	SubFlows []string // Names of flows run with run_flow
+	Files    []string // Data and mock files referenced by path
+	Mocks    []string // Names of mock servers started by the flow
+}
+
+// Mock is a mock server definition file
+type Mock struct {
+	Path string
+	Name string
+	Port int
+}
+
+// Graph links flows to the sub-flows, data files, mock definitions and
+// environment files they depend on
+type Graph struct {
+	Root         string
+	Flows        map[string]*Flow  // By path
+	Mocks        map[string]*Mock  // By path
+	Environments map[string]string // Environment file paths by name
+
+	byName map[string]*Flow // By normalized name and file name
+}
+
+// BuildGraph reads every YAML file under root
+func BuildGraph(root string, ignore []string) (*Graph, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{
+		Root:         root,
+		Flows:        make(map[string]*Flow),
+		Mocks:        make(map[string]*Mock),
+		Environments: make(map[string]string),
+		byName:       make(map[string]*Flow),
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			for _, pattern := range ignore {
+				if matched, _ := filepath.Match(pattern, name); matched {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if ext := filepath.Ext(name); ext == ".yaml" || ext == ".yml" {
+			g.Update(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Update reads a file again after it changed. Files that no longer exist
+// are removed from the graph.
+func (g *Graph) Update(path string) {
+	g.remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var doc struct {
+		Flow       map[string]interface{} `yaml:"flow"`
+		MockServer struct {
+			Name string `yaml:"name"`
+			Port int    `yaml:"port"`
+		} `yaml:"mock_server"`
+		Name string                 `yaml:"name"`
+		Env  map[string]interface{} `yaml:"env"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return
+	}
+
+	switch {
+	case doc.Flow != nil:
+		flow := &Flow{Path: path}
+		flow.Name, _ = doc.Flow["name"].(string)
+		if tags, ok := doc.Flow["tags"].([]interface{}); ok {
+			for _, tag := range tags {
+				if s, ok := tag.(string); ok {
+					flow.Tags = append(flow.Tags, s)
+				}
+			}
+		}
+		for _, phase := range []string{"setup", "steps", "teardown"} {
+			g.collect(flow, doc.Flow[phase])
+		}
+		flow.Files = dedupe(flow.Files)
+		g.Flows[path] = flow
+		// run_flow refers to flows by name, often written as a slug
+		g.byName[normalizeName(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))] = flow
+		if flow.Name != "" {
+			g.byName[normalizeName(flow.Name)] = flow
+		}
+
+	case doc.MockServer.Name != "" || doc.MockServer.Port != 0:
+		g.Mocks[path] = &Mock{Path: path, Name: doc.MockServer.Name, Port: doc.MockServer.Port}
+
+	case doc.Env != nil && filepath.Base(filepath.Dir(path)) == "environments":
+		name := doc.Name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		g.Environments[name] = path
+	}
+}
+
+func (g *Graph) remove(path string) {
+	if flow, ok := g.Flows[path]; ok {
+		for name, f := range g.byName {
+			if f == flow {
+				delete(g.byName, name)
+			}
+		}
+		delete(g.Flows, path)
+	}
+	delete(g.Mocks, path)
+	for name, p := range g.Environments {
+		if p == path {
+			delete(g.Environments, name)
+		}
+	}
+}
+
+// collect walks steps, including nested ones, for sub-flows, mock servers
+// and referenced files
+func (g *Graph) collect(flow *Flow, node interface{}) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			g.collect(flow, item)
+		}
+	case map[string]interface{}:
+		action, _ := v["action"].(string)
+		if config, ok := v["config"].(map[string]interface{}); ok {
+			switch action {
+			case "run_flow":
+				if name, ok := config["flow"].(string); ok && name != "" {
+					flow.SubFlows = append(flow.SubFlows, name)
+				}
+			case "mock_server_start":
+				if name, ok := config["name"].(string); ok && name != "" {
+					flow.Mocks = append(flow.Mocks, name)
+				}
+			}
+		}
+		for key, value := range v {
+			if s, ok := value.(string); ok && isFileKey(key) {
+				flow.Files = append(flow.Files, g.resolve(flow.Path, s)...)
+				continue
+			}
+			g.collect(flow, value)
+		}
+	}
+}
+
+// isFileKey reports whether a config key names a file, such as input_file,
+// file or items_from_glob
+func isFileKey(key string) bool {
+	return key == "file" || strings.HasSuffix(key, "_file") || strings.HasSuffix(key, "_glob")
+}
+
+// resolve returns the existing files a reference matches, relative to the
+// flow's directory or the root. References to variables are skipped.
+func (g *Graph) resolve(flowPath, ref string) []string {
+	if ref == "" || strings.Contains(ref, "${") {
+		return nil
+	}
+	candidates := []string{ref}
+	if !filepath.IsAbs(ref) {
+		candidates = []string{filepath.Join(filepath.Dir(flowPath), ref), filepath.Join(g.Root, ref)}
+	}
+	for _, candidate := range candidates {
+		if matches, _ := filepath.Glob(candidate); len(matches) > 0 {
+			return matches
+		}
+	}
+	// Keep the reference so the file is picked up once it is created
+	return []string{candidates[0]}
+}
+
+// FlowByName returns the flow with a name or file name, or nil
+func (g *Graph) FlowByName(name string) *Flow {
+	return g.byName[normalizeName(name)]
+}
+
+// All returns every flow, sorted by path
+func (g *Graph) All() []*Flow {
+	return g.sorted(func(*Flow) bool { return true })
+}
+
+// IsSubFlow reports whether another flow runs the flow with run_flow
+func (g *Graph) IsSubFlow(flow *Flow) bool {
+	for _, other := range g.Flows {
+		for _, name := range other.SubFlows {
+			if g.FlowByName(name) == flow {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Affected returns the flows that changed or depend on a changed file,
+// directly or through sub-flows, sorted by path. Changes to the active
+// environment file affect every flow.
+func (g *Graph) Affected(changed []string, environment string) []*Flow {
+	changedSet := make(map[string]bool)
+	for _, path := range changed {
+		changedSet[path] = true
+		if path == g.Environments[environment] && path != "" {
+			return g.sorted(func(*Flow) bool { return true })
+		}
+	}
+
+	changedMocks := make(map[string]bool)
+	for path, mock := range g.Mocks {
+		if changedSet[path] {
+			changedMocks[mockName(mock.Name)] = true
+		}
+	}
+
+	// Flows whose own dependencies changed
+	affected := make(map[*Flow]bool)
+	for path, flow := range g.Flows {
+		if changedSet[path] {
+			affected[flow] = true
+			continue
+		}
+		for _, file := range flow.Files {
+			if changedSet[file] {
+				affected[flow] = true
+			}
+		}
+		for _, name := range flow.Mocks {
+			if changedMocks[mockName(name)] {
+				affected[flow] = true
+			}
+		}
+	}
+
+	// Then flows running affected sub-flows, until nothing changes
+	for grew := true; grew; {
+		grew = false
+		for _, flow := range g.Flows {
+			if affected[flow] {
+				continue
+			}
+			for _, name := range flow.SubFlows {
+				if sub := g.FlowByName(name); sub != nil && affected[sub] {
+					affected[flow] = true
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	return g.sorted(func(flow *Flow) bool { return affected[flow] })
+}
+
+// Dependencies returns the sub-flows a flow runs, directly or through other
+// sub-flows
+func (g *Graph) Dependencies(flow *Flow) []*Flow {
+	seen := map[*Flow]bool{flow: true}
+	var deps []*Flow
+	var visit func(*Flow)
+	visit = func(f *Flow) {
+		for _, name := range f.SubFlows {
+			if sub := g.FlowByName(name); sub != nil && !seen[sub] {
+				seen[sub] = true
+				visit(sub)
+				deps = append(deps, sub)
+			}
+		}
+	}
+	visit(flow)
+	return deps
+}
+
+// MockFor returns the mock definition a flow's mock server name refers to
+func (g *Graph) MockFor(name string) *Mock {
+	for _, mock := range g.Mocks {
+		if mockName(mock.Name) == mockName(name) {
+			return mock
+		}
+	}
+	return nil
+}
+
+func (g *Graph) sorted(include func(*Flow) bool) []*Flow {
+	var flows []*Flow
+	for _, flow := range g.Flows {
+		if include(flow) {
+			flows = append(flows, flow)
+		}
+	}
+	sort.Slice(flows, func(i, j int) bool { return flows[i].Path < flows[j].Path })
+	return flows
+}
+
+// normalizeName compares names like "Fare Test Template V2" and
+// "fare-test-template-v2"
+func normalizeName(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// mockName compares mock names like "Payment Gateway Mock" and
+// "payment-gateway"
+func mockName(name string) string {
+	return normalizeName(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), " mock"))
+}
+
+func dedupe(paths []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
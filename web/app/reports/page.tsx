@@ -88,7 +88,8 @@ export default function ReportsPage() {
   };
 
   const handleDownload = (report: Report) => {
-    const extension = report.format === 'junit' ? 'xml' : report.format;
+    const extensions: Record<string, string> = { junit: 'xml', allure: 'zip', ctrf: 'json' };
+    const extension = extensions[report.format] || report.format;
     downloadReport.mutate({
       id: report.id,
       filename: `${report.name}.${extension}`,
@@ -141,6 +142,9 @@ export default function ReportsPage() {
       html: 'bg-blue-100 text-blue-800 dark:bg-blue-900 dark:text-blue-200',
       json: 'bg-green-100 text-green-800 dark:bg-green-900 dark:text-green-200',
       junit: 'bg-purple-100 text-purple-800 dark:bg-purple-900 dark:text-purple-200',
+      allure: 'bg-orange-100 text-orange-800 dark:bg-orange-900 dark:text-orange-200',
+      ctrf: 'bg-teal-100 text-teal-800 dark:bg-teal-900 dark:text-teal-200',
+      tap: 'bg-gray-100 text-gray-800 dark:bg-gray-900 dark:text-gray-200',
     };
     return (
       <Badge variant="outline" className={colors[format] || ''}>
@@ -201,6 +205,9 @@ export default function ReportsPage() {
                     <SelectItem value="html">HTML (with charts)</SelectItem>
                     <SelectItem value="json">JSON (raw data)</SelectItem>
                     <SelectItem value="junit">JUnit XML (CI/CD)</SelectItem>
+                    <SelectItem value="allure">Allure results (zip)</SelectItem>
+                    <SelectItem value="ctrf">CTRF JSON</SelectItem>
+                    <SelectItem value="tap">TAP</SelectItem>
                   </SelectContent>
                 </Select>
               </div>
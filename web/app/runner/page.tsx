@@ -1,6 +1,6 @@
 'use client';
 
-import { useState, useCallback } from 'react';
+import { useState } from 'react';
 import {
   Play,
   Upload,
@@ -14,13 +14,16 @@ import {
   Settings,
   Table as TableIcon,
   Zap,
+  Database,
+  Sparkles,
+  History,
 } from 'lucide-react';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
+import { Textarea } from '@/components/ui/textarea';
 import { Label } from '@/components/ui/label';
 import { Badge } from '@/components/ui/badge';
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
-import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
 import { Progress } from '@/components/ui/progress';
 import {
   Select,
@@ -39,26 +42,58 @@ import {
 } from '@/components/ui/table';
 import { cn } from '@/lib/utils';
 import { useFlows } from '@/lib/hooks/useFlows';
-import { useRunCollection, useParseDataFile } from '@/lib/hooks/useRunner';
+import { useRunCollection, useParseDataFile, useDataFiles } from '@/lib/hooks/useRunner';
 import type {
   DataRow,
+  DataSource,
   CollectionRunConfig,
   CollectionRunResult,
   IterationResult,
 } from '@/lib/api/runner';
 
+type DataType = 'none' | 'csv' | 'json' | 'file' | 'sql' | 'generator' | 'execution';
+
+const DATA_TYPES: { value: DataType; label: string }[] = [
+  { value: 'none', label: 'No Data' },
+  { value: 'csv', label: 'CSV upload' },
+  { value: 'json', label: 'JSON upload' },
+  { value: 'file', label: 'Data file' },
+  { value: 'sql', label: 'SQL query' },
+  { value: 'generator', label: 'Generator' },
+  { value: 'execution', label: 'Execution output' },
+];
+
+const formatValue = (value: unknown) =>
+  value !== null && typeof value === 'object' ? JSON.stringify(value) : String(value ?? '');
+
 export default function RunnerPage() {
   // Flow selection
   const [selectedFlowIds, setSelectedFlowIds] = useState<string[]>([]);
   const { data: flowsData, isLoading: isLoadingFlows } = useFlows();
 
   // Data source
-  const [dataType, setDataType] = useState<'csv' | 'json' | 'none'>('none');
+  const [dataType, setDataType] = useState<DataType>('none');
   const [dataContent, setDataContent] = useState('');
+  const [dataFile, setDataFile] = useState('');
+  const [sqlQuery, setSqlQuery] = useState('');
+  const [sqlConnection, setSqlConnection] = useState('');
+  const [generatorCount, setGeneratorCount] = useState(10);
+  const [generatorSeed, setGeneratorSeed] = useState('');
+  const [generatorFields, setGeneratorFields] = useState(
+    '{\n  "email": "${FAKER.internet.email}",\n  "amount": "${FAKER.finance.amount}"\n}'
+  );
+  const [executionId, setExecutionId] = useState('');
+  const [executionStep, setExecutionStep] = useState('');
+  const [executionPath, setExecutionPath] = useState('');
+  const [rowFilter, setRowFilter] = useState('');
+  const [expectColumn, setExpectColumn] = useState('');
+  const [dataError, setDataError] = useState<string | null>(null);
+  const { data: dataFilesData } = useDataFiles();
   const [parsedData, setParsedData] = useState<{
     columns: string[];
     preview: DataRow[];
     totalRows: number;
+    filteredRows?: number;
   } | null>(null);
 
   // Variable mapping
@@ -79,40 +114,98 @@ export default function RunnerPage() {
   const runCollection = useRunCollection();
 
   // Handle file upload
-  const handleFileUpload = useCallback(
-    async (e: React.ChangeEvent<HTMLInputElement>) => {
-      const file = e.target.files?.[0];
-      if (!file) return;
-
-      const content = await file.text();
-      setDataContent(content);
-
-      // Detect type from extension
-      const type = file.name.endsWith('.json') ? 'json' : 'csv';
-      setDataType(type);
-
-      // Parse the file
-      try {
-        const result = await parseDataFile.mutateAsync({ type, content });
-        setParsedData({
-          columns: result.columns,
-          preview: result.preview,
-          totalRows: result.total_rows,
-        });
-
-        // Initialize variable mapping
-        const mapping: Record<string, string> = {};
-        result.columns.forEach((col) => {
-          mapping[col] = col;
-        });
-        setVariableMapping(mapping);
-        setIterations(result.total_rows);
-      } catch (error) {
-        console.error('Failed to parse file:', error);
+  const handleFileUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    if (!file) return;
+
+    const content = await file.text();
+    setDataContent(content);
+
+    // Detect type from extension
+    const type = file.name.endsWith('.json') ? 'json' : 'csv';
+    setDataType(type);
+
+    // Parse the file
+    await loadRows({ type, content });
+  };
+
+  // Build the data source of the run from the selected type
+  const buildDataSource = (): DataSource | undefined => {
+    const common = {
+      filter: rowFilter || undefined,
+      expect_column: expectColumn || undefined,
+    };
+    switch (dataType) {
+      case 'csv':
+      case 'json':
+        return dataContent ? { type: dataType, content: dataContent, ...common } : undefined;
+      case 'file':
+        return dataFile ? { type: 'file', file: dataFile, ...common } : undefined;
+      case 'sql':
+        return { type: 'sql', query: sqlQuery, connection: sqlConnection, ...common };
+      case 'generator': {
+        let fields: Record<string, unknown> = {};
+        try {
+          fields = JSON.parse(generatorFields);
+        } catch {
+          setDataError('Generator fields must be a JSON object');
+          return undefined;
+        }
+        return {
+          type: 'generator',
+          generator: {
+            count: generatorCount,
+            seed: generatorSeed ? parseInt(generatorSeed) : undefined,
+            fields,
+          },
+          ...common,
+        };
       }
-    },
-    [parseDataFile]
-  );
+      case 'execution':
+        return {
+          type: 'execution',
+          execution_id: executionId,
+          step: executionStep,
+          path: executionPath || undefined,
+          ...common,
+        };
+      default:
+        return undefined;
+    }
+  };
+
+  // Load the rows of a data source and show a preview
+  const loadRows = async (source?: DataSource) => {
+    const request = source
+      ? { ...source, filter: rowFilter || undefined, expect_column: expectColumn || undefined }
+      : buildDataSource();
+    if (!request) return;
+
+    setDataError(null);
+    try {
+      const result = await parseDataFile.mutateAsync({ ...request, variables: globalVariables });
+      setParsedData({
+        columns: result.columns,
+        preview: result.preview,
+        totalRows: result.total_rows,
+        filteredRows: result.filtered_rows,
+      });
+      if (request.type === 'generator' && result.data_seed) {
+        setGeneratorSeed(String(result.data_seed));
+      }
+
+      // Initialize variable mapping
+      const mapping: Record<string, string> = {};
+      result.columns.forEach((col) => {
+        mapping[col] = col;
+      });
+      setVariableMapping(mapping);
+      setIterations(result.total_rows);
+    } catch (error: any) {
+      setParsedData(null);
+      setDataError(error?.response?.data?.error || 'Failed to load data');
+    }
+  };
 
   // Handle run
   const handleRun = async () => {
@@ -126,11 +219,9 @@ export default function RunnerPage() {
       variable_mapping: variableMapping,
     };
 
-    if (dataType !== 'none' && dataContent) {
-      config.data_source = {
-        type: dataType,
-        content: dataContent,
-      };
+    const dataSource = buildDataSource();
+    if (dataSource) {
+      config.data_source = dataSource;
     }
 
     try {
@@ -157,7 +248,7 @@ export default function RunnerPage() {
             Collection Runner
           </h1>
           <p className="text-muted-foreground">
-            Run flows with data-driven testing using CSV or JSON files
+            Run flows once per row of a data file, query, generator or earlier execution
           </p>
         </div>
         <Button
@@ -231,64 +322,227 @@ export default function RunnerPage() {
             <CardHeader>
               <CardTitle className="text-base">Data Source</CardTitle>
               <CardDescription>
-                Upload a CSV or JSON file for data-driven testing
+                Each row is an iteration; its columns are variables of the flows
               </CardDescription>
             </CardHeader>
             <CardContent className="space-y-4">
-              <Tabs value={dataType} onValueChange={(v: any) => setDataType(v)}>
-                <TabsList className="grid w-full grid-cols-3">
-                  <TabsTrigger value="none">No Data</TabsTrigger>
-                  <TabsTrigger value="csv">CSV</TabsTrigger>
-                  <TabsTrigger value="json">JSON</TabsTrigger>
-                </TabsList>
-
-                <TabsContent value="none" className="space-y-3">
+              <Select
+                value={dataType}
+                onValueChange={(v) => {
+                  setDataType(v as DataType);
+                  setParsedData(null);
+                  setDataError(null);
+                }}
+              >
+                <SelectTrigger>
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  {DATA_TYPES.map((t) => (
+                    <SelectItem key={t.value} value={t.value}>
+                      {t.label}
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+
+              {dataType === 'none' && (
+                <div>
+                  <Label className="text-sm">Iterations</Label>
+                  <Input
+                    type="number"
+                    min={1}
+                    value={iterations}
+                    onChange={(e) => setIterations(parseInt(e.target.value) || 1)}
+                    className="w-24 mt-1"
+                  />
+                </div>
+              )}
+
+              {(dataType === 'csv' || dataType === 'json') && (
+                <div className="border-2 border-dashed rounded-lg p-6 text-center">
+                  {dataType === 'csv' ? (
+                    <FileSpreadsheet className="w-8 h-8 mx-auto mb-2 text-muted-foreground" />
+                  ) : (
+                    <FileJson className="w-8 h-8 mx-auto mb-2 text-muted-foreground" />
+                  )}
+                  <label className="cursor-pointer">
+                    <span className="text-sm text-primary hover:underline">
+                      Upload {dataType.toUpperCase()} file
+                    </span>
+                    <input
+                      type="file"
+                      accept={dataType === 'csv' ? '.csv' : '.json'}
+                      onChange={handleFileUpload}
+                      className="hidden"
+                    />
+                  </label>
+                </div>
+              )}
+
+              {dataType === 'file' && (
+                <div>
+                  <Label className="text-sm">Data file</Label>
+                  <Select value={dataFile} onValueChange={setDataFile}>
+                    <SelectTrigger className="mt-1">
+                      <SelectValue placeholder="Select a data file" />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {dataFilesData?.data_files.map((f) => (
+                        <SelectItem key={f.id} value={f.id}>
+                          {f.name} ({f.row_count} rows)
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                </div>
+              )}
+
+              {dataType === 'sql' && (
+                <div className="space-y-3">
                   <div>
-                    <Label className="text-sm">Iterations</Label>
+                    <Label className="text-sm flex items-center gap-1">
+                      <Database className="w-3 h-3" />
+                      Connection
+                    </Label>
                     <Input
-                      type="number"
-                      min={1}
-                      value={iterations}
-                      onChange={(e) => setIterations(parseInt(e.target.value) || 1)}
-                      className="w-24 mt-1"
+                      value={sqlConnection}
+                      onChange={(e) => setSqlConnection(e.target.value)}
+                      placeholder="${DATABASE_URL}"
+                      className="mt-1 font-mono text-sm"
+                    />
+                  </div>
+                  <div>
+                    <Label className="text-sm">Query</Label>
+                    <Textarea
+                      value={sqlQuery}
+                      onChange={(e) => setSqlQuery(e.target.value)}
+                      placeholder="SELECT card_id, fare, expected_outcome FROM fare_cases"
+                      className="mt-1 font-mono text-sm"
+                      rows={3}
                     />
                   </div>
-                </TabsContent>
+                </div>
+              )}
 
-                <TabsContent value="csv" className="space-y-3">
-                  <div className="border-2 border-dashed rounded-lg p-6 text-center">
-                    <FileSpreadsheet className="w-8 h-8 mx-auto mb-2 text-muted-foreground" />
-                    <label className="cursor-pointer">
-                      <span className="text-sm text-primary hover:underline">
-                        Upload CSV file
-                      </span>
-                      <input
-                        type="file"
-                        accept=".csv"
-                        onChange={handleFileUpload}
-                        className="hidden"
+              {dataType === 'generator' && (
+                <div className="space-y-3">
+                  <div className="grid grid-cols-2 gap-4">
+                    <div>
+                      <Label className="text-sm flex items-center gap-1">
+                        <Sparkles className="w-3 h-3" />
+                        Rows
+                      </Label>
+                      <Input
+                        type="number"
+                        min={1}
+                        value={generatorCount}
+                        onChange={(e) => setGeneratorCount(parseInt(e.target.value) || 1)}
+                        className="mt-1"
                       />
-                    </label>
+                    </div>
+                    <div>
+                      <Label className="text-sm">Seed</Label>
+                      <Input
+                        value={generatorSeed}
+                        onChange={(e) => setGeneratorSeed(e.target.value)}
+                        placeholder="Random"
+                        className="mt-1 font-mono text-sm"
+                      />
+                    </div>
+                  </div>
+                  <div>
+                    <Label className="text-sm">Fields</Label>
+                    <Textarea
+                      value={generatorFields}
+                      onChange={(e) => setGeneratorFields(e.target.value)}
+                      className="mt-1 font-mono text-sm"
+                      rows={4}
+                    />
                   </div>
-                </TabsContent>
+                </div>
+              )}
 
-                <TabsContent value="json" className="space-y-3">
-                  <div className="border-2 border-dashed rounded-lg p-6 text-center">
-                    <FileJson className="w-8 h-8 mx-auto mb-2 text-muted-foreground" />
-                    <label className="cursor-pointer">
-                      <span className="text-sm text-primary hover:underline">
-                        Upload JSON file
-                      </span>
-                      <input
-                        type="file"
-                        accept=".json"
-                        onChange={handleFileUpload}
-                        className="hidden"
+              {dataType === 'execution' && (
+                <div className="space-y-3">
+                  <div>
+                    <Label className="text-sm flex items-center gap-1">
+                      <History className="w-3 h-3" />
+                      Execution ID
+                    </Label>
+                    <Input
+                      value={executionId}
+                      onChange={(e) => setExecutionId(e.target.value)}
+                      className="mt-1 font-mono text-sm"
+                    />
+                  </div>
+                  <div className="grid grid-cols-2 gap-4">
+                    <div>
+                      <Label className="text-sm">Step</Label>
+                      <Input
+                        value={executionStep}
+                        onChange={(e) => setExecutionStep(e.target.value)}
+                        placeholder="list_cards"
+                        className="mt-1 font-mono text-sm"
                       />
-                    </label>
+                    </div>
+                    <div>
+                      <Label className="text-sm">Path</Label>
+                      <Input
+                        value={executionPath}
+                        onChange={(e) => setExecutionPath(e.target.value)}
+                        placeholder="body.items"
+                        className="mt-1 font-mono text-sm"
+                      />
+                    </div>
                   </div>
-                </TabsContent>
-              </Tabs>
+                </div>
+              )}
+
+              {dataType !== 'none' && (
+                <div className="grid grid-cols-2 gap-4">
+                  <div>
+                    <Label className="text-sm">Filter</Label>
+                    <Input
+                      value={rowFilter}
+                      onChange={(e) => setRowFilter(e.target.value)}
+                      placeholder='fare_type == "single"'
+                      className="mt-1 font-mono text-sm"
+                    />
+                  </div>
+                  <div>
+                    <Label className="text-sm">Expected outcome column</Label>
+                    <Input
+                      value={expectColumn}
+                      onChange={(e) => setExpectColumn(e.target.value)}
+                      placeholder="expected_outcome"
+                      className="mt-1 font-mono text-sm"
+                    />
+                  </div>
+                </div>
+              )}
+
+              {dataType !== 'none' && (
+                <Button
+                  variant="outline"
+                  size="sm"
+                  onClick={() =>
+                    dataType === 'csv' || dataType === 'json'
+                      ? dataContent && loadRows({ type: dataType, content: dataContent })
+                      : loadRows()
+                  }
+                  disabled={parseDataFile.isPending}
+                >
+                  {parseDataFile.isPending ? (
+                    <Loader2 className="w-4 h-4 mr-2 animate-spin" />
+                  ) : (
+                    <TableIcon className="w-4 h-4 mr-2" />
+                  )}
+                  Load rows
+                </Button>
+              )}
+
+              {dataError && <div className="text-sm text-red-600">{dataError}</div>}
 
               {/* Data preview */}
               {parsedData && (
@@ -297,6 +551,11 @@ export default function RunnerPage() {
                     <span className="text-sm font-medium">
                       Data Preview ({parsedData.totalRows} rows)
                     </span>
+                    {!!parsedData.filteredRows && (
+                      <span className="text-xs text-muted-foreground">
+                        {parsedData.filteredRows} filtered out
+                      </span>
+                    )}
                   </div>
                   <div className="border rounded-md overflow-auto max-h-48">
                     <Table>
@@ -314,7 +573,7 @@ export default function RunnerPage() {
                           <TableRow key={i}>
                             {parsedData.columns.map((col) => (
                               <TableCell key={col} className="text-xs">
-                                {String(row[col] ?? '')}
+                                {formatValue(row[col])}
                               </TableCell>
                             ))}
                           </TableRow>
@@ -446,6 +705,14 @@ export default function RunnerPage() {
                     </span>
                     <span>{runResult.duration_ms}ms</span>
                   </div>
+                  {(!!runResult.filtered_rows || !!runResult.data_seed) && (
+                    <div className="flex gap-4 text-xs text-muted-foreground">
+                      {!!runResult.filtered_rows && (
+                        <span>{runResult.filtered_rows} rows filtered out</span>
+                      )}
+                      {!!runResult.data_seed && <span>Seed {runResult.data_seed}</span>}
+                    </div>
+                  )}
                 </div>
 
                 {/* Iteration results */}
@@ -467,14 +734,48 @@ export default function RunnerPage() {
                           ) : (
                             <XCircle className="w-4 h-4 text-red-600" />
                           )}
-                          <span>Iteration {iter.iteration}</span>
+                          <span>
+                            {iter.row ? `Row ${iter.row}` : `Iteration ${iter.iteration}`}
+                            {iter.label && (
+                              <span className="ml-1 font-medium">{iter.label}</span>
+                            )}
+                          </span>
+                          {iter.expected === 'fail' && (
+                            <Badge variant="outline" className="text-xs">
+                              negative
+                            </Badge>
+                          )}
+                          {iter.outcome && iter.expected && (
+                            <span className="text-xs text-muted-foreground">
+                              expected {iter.expected}, {iter.outcome}
+                            </span>
+                          )}
                         </div>
                         <span className="text-xs text-muted-foreground">
                           {iter.duration_ms}ms
                         </span>
                       </div>
+                      {iter.data_row && (
+                        <div className="mt-1 flex flex-wrap gap-1">
+                          {(runResult.columns || Object.keys(iter.data_row)).map((col) => (
+                            <span
+                              key={col}
+                              className="text-xs font-mono bg-muted px-1.5 py-0.5 rounded"
+                            >
+                              {col}={formatValue(iter.data_row?.[col])}
+                            </span>
+                          ))}
+                        </div>
+                      )}
                       {iter.error && (
-                        <div className="mt-1 text-xs text-red-600">{iter.error}</div>
+                        <div
+                          className={cn(
+                            'mt-1 text-xs',
+                            iter.status === 'passed' ? 'text-muted-foreground' : 'text-red-600'
+                          )}
+                        >
+                          {iter.error}
+                        </div>
                       )}
                     </div>
                   ))}
@@ -63,7 +63,7 @@ export default function ScheduleEditPage({
     if (schedule && !initialized) {
       setName(schedule.name);
       setDescription(schedule.description || '');
-      setFlowId(schedule.flow_id);
+      setFlowId(schedule.flow_id || '');
       setCronExpr(schedule.cron_expr);
       setTimezone(schedule.timezone);
       setNotifyOnFailure(schedule.notify_on_failure);
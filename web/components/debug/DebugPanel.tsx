@@ -15,6 +15,7 @@ import {
   Variable,
 } from 'lucide-react';
 import { cn } from '@/lib/utils';
+import { getActiveWorkspaceId } from '@/lib/hooks/useWorkspaces';
 import { Button } from '@/components/ui/button';
 import { Badge } from '@/components/ui/badge';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
@@ -192,9 +193,10 @@ export default function DebugPanel({
 
   // WebSocket connection for real-time updates
   useEffect(() => {
-    if (!isConnected) return;
+    const workspaceId = getActiveWorkspaceId();
+    if (!isConnected || !workspaceId) return;
 
-    const ws = new WebSocket(`ws://${window.location.host}/ws/executions/${executionId}`);
+    const ws = new WebSocket(`ws://${window.location.host}/api/v1/workspaces/${workspaceId}/executions/${executionId}/events`);
 
     ws.onmessage = (event) => {
       const data = JSON.parse(event.data);
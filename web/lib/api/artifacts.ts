@@ -0,0 +1,51 @@
+import { apiClient } from './client';
+
+export type ArtifactKind = 'output' | 'screenshot' | 'file';
+
+export interface Artifact {
+  id: string;
+  workspace_id?: string;
+  execution_id: string;
+  execution_step_id?: string;
+  step_id: string;
+  kind: ArtifactKind;
+  name: string;
+  content_type: string;
+  size: number;
+  checksum: string;
+  backend: string;
+  created_at: string;
+}
+
+// Reference left in a step output in place of an offloaded value
+export interface ArtifactRef {
+  $artifact: string;
+  name: string;
+  size: number;
+  content_type: string;
+}
+
+// Check whether a step output value was offloaded to an artifact
+export function isArtifactRef(value: unknown): value is ArtifactRef {
+  return typeof value === 'object' && value !== null && typeof (value as ArtifactRef).$artifact === 'string';
+}
+
+// List the artifacts of an execution
+export async function listExecutionArtifacts(executionId: string): Promise<{ artifacts: Artifact[]; total: number }> {
+  const response = await apiClient.get(`/api/v1/executions/${executionId}/artifacts`);
+  return response.data;
+}
+
+// Get an artifact
+export async function getArtifact(id: string): Promise<Artifact> {
+  const response = await apiClient.get(`/api/v1/artifacts/${id}`);
+  return response.data;
+}
+
+// Download the content of an artifact
+export async function downloadArtifact(id: string): Promise<Blob> {
+  const response = await apiClient.get(`/api/v1/artifacts/${id}/download`, {
+    responseType: 'blob',
+  });
+  return response.data;
+}
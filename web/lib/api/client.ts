@@ -11,6 +11,8 @@ import type {
   ListExecutionsResponse,
   GetStepsResponse,
   GetLogsResponse,
+  ListContextResponse,
+  RestartExecutionRequest,
   HealthResponse,
   ExecutionStatus,
 } from './types';
@@ -19,7 +21,7 @@ import type {
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:5016';
 
 // Paths that should be workspace-scoped
-const WORKSPACE_SCOPED_PATHS = ['/flows', '/collections', '/environments', '/executions'];
+const WORKSPACE_SCOPED_PATHS = ['/flows', '/collections', '/environments', '/executions', '/git-sync', '/quarantine', '/monitors', '/artifacts', '/runner', '/data-files'];
 
 // Check if a path should be workspace-scoped
 const isWorkspaceScopedPath = (url: string): boolean => {
@@ -200,6 +202,16 @@ export const executionApi = {
     );
     return response.data;
   },
+
+  getContext: async (id: string): Promise<ListContextResponse> => {
+    const response = await apiClient.get<ListContextResponse>(`/api/v1/executions/${id}/context`);
+    return response.data;
+  },
+
+  restart: async (id: string, data: RestartExecutionRequest): Promise<Execution> => {
+    const response = await apiClient.post<Execution>(`/api/v1/executions/${id}/restart`, data);
+    return response.data;
+  },
 };
 
 // Export a default API object with all endpoints
@@ -0,0 +1,107 @@
+import { apiClient } from './client';
+import type { BreakingChangeSeverity, ChangeDetails } from './types';
+
+export type RunChange =
+  | 'newly_failing'
+  | 'newly_passing'
+  | 'still_failing'
+  | 'skipped'
+  | 'unchanged'
+  | 'added'
+  | 'removed';
+
+export interface ShapeChange {
+  change_type: 'added_field' | 'removed_field' | 'changed_type';
+  severity: BreakingChangeSeverity;
+  description: string;
+  details: ChangeDetails;
+}
+
+export interface RunSummary {
+  kind: 'execution' | 'schedule_run';
+  id: string;
+  name: string;
+  status: string;
+  started_at?: string;
+  flows: number;
+  passed: number;
+  failed: number;
+}
+
+export interface LatencyRegression {
+  baseline: 'history' | 'base_run';
+  mean_ms: number;
+  stddev_ms?: number;
+  samples: number;
+  z_score?: number;
+  slowdown_pct: number;
+}
+
+export interface StepComparison {
+  step_id: string;
+  step_name: string;
+  action: string;
+  change: RunChange;
+  base_status?: string;
+  head_status?: string;
+  base_duration_ms: number;
+  head_duration_ms: number;
+  latency_regression?: LatencyRegression;
+  base_error?: string;
+  head_error?: string;
+  error_changed: boolean;
+  shape_changes?: ShapeChange[];
+}
+
+export interface FlowComparison {
+  flow_id: string;
+  flow_name: string;
+  change: RunChange;
+  base_status?: string;
+  head_status?: string;
+  base_execution_id?: string;
+  head_execution_id?: string;
+  base_duration_ms: number;
+  head_duration_ms: number;
+  base_error?: string;
+  head_error?: string;
+  error_changed: boolean;
+  steps?: StepComparison[];
+}
+
+export interface RunComparison {
+  title: string;
+  base: RunSummary;
+  head: RunSummary;
+  summary: {
+    newly_failing: number;
+    newly_passing: number;
+    still_failing: number;
+    added: number;
+    removed: number;
+    latency_regressions: number;
+    error_changes: number;
+    shape_changes: number;
+    regressed: boolean;
+  };
+  flows: FlowComparison[];
+  generated_at: string;
+}
+
+export interface CompareParams {
+  base?: string;
+  threshold?: number;
+  history_days?: number;
+}
+
+// Compare an execution with another one, by default the previous execution of the flow
+export async function compareExecutions(id: string, params?: CompareParams): Promise<RunComparison> {
+  const response = await apiClient.get(`/api/v1/executions/${id}/compare`, { params });
+  return response.data;
+}
+
+// Compare a schedule run with another one, by default the previous completed run
+export async function compareScheduleRuns(id: string, runId: string, params?: CompareParams): Promise<RunComparison> {
+  const response = await apiClient.get(`/api/v1/schedules/${id}/runs/${runId}/compare`, { params });
+  return response.data;
+}
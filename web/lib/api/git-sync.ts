@@ -0,0 +1,116 @@
+import { apiClient } from './client';
+import type { Collection } from './types';
+
+export type GitSyncStatus = 'idle' | 'syncing' | 'conflict' | 'error';
+
+export interface GitSyncFile {
+  id: string;
+  binding_id: string;
+  flow_id: string;
+  file_path: string;
+  content_hash: string;
+  flow_version: number;
+  commit_sha?: string;
+  conflict?: string;
+  updated_at: string;
+}
+
+export interface GitSyncBinding {
+  id: string;
+  workspace_id: string;
+  collection_id: string;
+  integration_id?: string;
+  repository_url: string;
+  repository: string;
+  branch: string;
+  path: string;
+  push_branch: string;
+  create_pull_request: boolean;
+  sync_interval?: string;
+  enabled: boolean;
+  status: GitSyncStatus;
+  last_commit?: string;
+  last_synced_at?: string;
+  last_error?: string;
+  pull_request_url?: string;
+  created_at: string;
+  updated_at: string;
+  collection?: Collection;
+  files?: GitSyncFile[];
+}
+
+export interface GitSyncBindingRequest {
+  collection_id: string;
+  integration_id?: string;
+  repository_url: string;
+  repository?: string;
+  branch?: string;
+  path?: string;
+  push_branch?: string;
+  create_pull_request?: boolean;
+  sync_interval?: string;
+  enabled?: boolean;
+}
+
+export interface GitSyncConflict {
+  file_path: string;
+  flow_id: string;
+  reason: string;
+}
+
+export interface GitSyncResult {
+  commit?: string;
+  created?: string[];
+  updated?: string[];
+  deleted?: string[];
+  pushed?: string[];
+  removed?: string[];
+  conflicts?: GitSyncConflict[];
+  errors?: string[];
+  pull_request_url?: string;
+}
+
+// List git sync bindings
+export async function listGitSyncBindings(): Promise<{ bindings: GitSyncBinding[]; total: number }> {
+  const response = await apiClient.get('/api/v1/git-sync');
+  return response.data;
+}
+
+// Get a binding with its file states
+export async function getGitSyncBinding(id: string): Promise<GitSyncBinding> {
+  const response = await apiClient.get(`/api/v1/git-sync/${id}`);
+  return response.data;
+}
+
+// Bind a collection to a git repository
+export async function createGitSyncBinding(data: GitSyncBindingRequest): Promise<GitSyncBinding> {
+  const response = await apiClient.post('/api/v1/git-sync', data);
+  return response.data;
+}
+
+// Update a binding
+export async function updateGitSyncBinding(id: string, data: GitSyncBindingRequest): Promise<GitSyncBinding> {
+  const response = await apiClient.put(`/api/v1/git-sync/${id}`, data);
+  return response.data;
+}
+
+// Delete a binding
+export async function deleteGitSyncBinding(id: string): Promise<void> {
+  await apiClient.delete(`/api/v1/git-sync/${id}`);
+}
+
+// Pull and push now
+export async function syncGitSyncBinding(id: string): Promise<GitSyncResult> {
+  const response = await apiClient.post(`/api/v1/git-sync/${id}/sync`);
+  return response.data;
+}
+
+// Resolve a conflicting file by keeping one side
+export async function resolveGitSyncConflict(
+  id: string,
+  flowId: string,
+  keep: 'git' | 'testmesh'
+): Promise<GitSyncResult> {
+  const response = await apiClient.post(`/api/v1/git-sync/${id}/resolve`, { flow_id: flowId, keep });
+  return response.data;
+}
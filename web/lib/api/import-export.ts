@@ -2,7 +2,10 @@ import { apiClient } from './client';
 import type { FlowDefinition } from './types';
 
 // Import types
-export type ImportType = 'har' | 'curl' | 'postman';
+export type ImportType = 'har' | 'curl' | 'postman' | 'insomnia' | 'bruno';
+
+// API client collection formats
+export type CollectionFormat = 'postman' | 'insomnia' | 'bruno';
 
 export interface ImportStats {
   total_requests: number;
@@ -17,6 +20,46 @@ export interface ImportResult {
   stats: ImportStats;
 }
 
+// A folder of an imported collection; flows are indexes into the imported flows
+export interface ImportedFolder {
+  name: string;
+  description?: string;
+  auth?: Record<string, unknown>;
+  variables?: Record<string, unknown>;
+  flows?: number[];
+  folders?: ImportedFolder[];
+}
+
+export interface CollectionImportResult extends ImportResult {
+  collection: ImportedFolder;
+  variables?: { key: string; value: string; is_secret?: boolean; enabled: boolean }[];
+}
+
+export interface ImportCollectionRequest {
+  format: CollectionFormat;
+  content?: string;
+  files?: Record<string, string>; // Bruno: the files of the collection by path
+  collection_id?: string;
+  environment_name?: string;
+  preview?: boolean;
+}
+
+export interface ImportCollectionResponse {
+  collection_id: string;
+  environment_id: string | null;
+  created: string[];
+  updated: string[];
+  errors: string[];
+  warnings?: string[];
+  stats: {
+    total: number;
+    created: number;
+    updated: number;
+    failed: number;
+    requests: number;
+  };
+}
+
 export interface ImportFlowsRequest {
   flows: FlowDefinition[];
   suite?: string;
@@ -35,13 +78,19 @@ export interface ImportFlowsResponse {
 }
 
 // Export types
-export type ExportFormat = 'postman' | 'openapi' | 'har' | 'testmesh';
+export type ExportFormat = 'postman' | 'openapi' | 'har' | 'testmesh' | 'bruno' | 'insomnia' | 'k6';
 
 export interface ExportRequest {
   flow_ids: string[];
   format: ExportFormat;
   include_tests?: boolean;
   include_env?: boolean;
+  environment?: string; // ID or name; defaults to the default environment
+}
+
+export interface ExportedFile {
+  path: string;
+  content: string;
 }
 
 export interface ExportResult {
@@ -49,6 +98,8 @@ export interface ExportResult {
   content: string;
   filename: string;
   mime_type: string;
+  files?: ExportedFile[]; // Formats of several files, like Bruno; downloaded as a zip
+  warnings?: string[];
 }
 
 // Parse import content (preview without saving)
@@ -61,6 +112,26 @@ export async function parseImport(type: ImportType, content: string): Promise<Im
   return response.data;
 }
 
+// Parse a Bruno collection, given its files by path (preview without saving)
+export async function parseBruno(files: Record<string, string>): Promise<CollectionImportResult> {
+  const response = await apiClient.post('/api/v1/import/parse', {
+    type: 'bruno',
+    files,
+    preview: true,
+  });
+  return response.data;
+}
+
+// Import a collection of an API client into a workspace: folders become
+// collections, folders of requests flows and its variables an environment
+export async function importCollection(
+  workspaceId: string,
+  data: ImportCollectionRequest
+): Promise<ImportCollectionResponse> {
+  const response = await apiClient.post(`/api/v1/workspaces/${workspaceId}/import/collection`, data);
+  return response.data;
+}
+
 // Import flows (save to database)
 export async function importFlows(data: ImportFlowsRequest): Promise<ImportFlowsResponse> {
   const response = await apiClient.post('/api/v1/import', data);
@@ -82,7 +153,7 @@ export function getExportDownloadUrl(flowIds: string[], format: ExportFormat): s
 }
 
 // Helper to download content as file
-export function downloadAsFile(content: string, filename: string, mimeType: string) {
+export function downloadAsFile(content: string | Blob, filename: string, mimeType: string) {
   const blob = new Blob([content], { type: mimeType });
   const url = URL.createObjectURL(blob);
   const a = document.createElement('a');
@@ -111,6 +182,11 @@ export async function parsePostman(content: string): Promise<ImportResult> {
   return parseImport('postman', content);
 }
 
+// Parse Insomnia export
+export async function parseInsomnia(content: string): Promise<ImportResult> {
+  return parseImport('insomnia', content);
+}
+
 // Export to Postman
 export async function exportToPostman(flowIds: string[]): Promise<ExportResult> {
   return exportFlows({ flow_ids: flowIds, format: 'postman' });
@@ -131,8 +207,29 @@ export async function exportToTestMesh(flowIds: string[]): Promise<ExportResult>
   return exportFlows({ flow_ids: flowIds, format: 'testmesh' });
 }
 
+// Export to Bruno
+export async function exportToBruno(flowIds: string[]): Promise<ExportResult> {
+  return exportFlows({ flow_ids: flowIds, format: 'bruno' });
+}
+
+// Export to Insomnia
+export async function exportToInsomnia(flowIds: string[]): Promise<ExportResult> {
+  return exportFlows({ flow_ids: flowIds, format: 'insomnia' });
+}
+
+// Export to k6
+export async function exportToK6(flowIds: string[]): Promise<ExportResult> {
+  return exportFlows({ flow_ids: flowIds, format: 'k6' });
+}
+
 // Export and download
 export async function exportAndDownload(flowIds: string[], format: ExportFormat) {
   const result = await exportFlows({ flow_ids: flowIds, format });
+  if (result.files?.length) {
+    // Several files are zipped by the download endpoint
+    const response = await apiClient.get(getExportDownloadUrl(flowIds, format), { responseType: 'blob' });
+    downloadAsFile(response.data, result.filename, result.mime_type);
+    return;
+  }
   downloadAsFile(result.content, result.filename, result.mime_type);
 }
@@ -17,7 +17,14 @@ export interface SystemIntegration {
 }
 
 export type IntegrationType = 'ai_provider' | 'git';
-export type IntegrationProvider = 'openai' | 'anthropic' | 'local' | 'github';
+export type IntegrationProvider =
+  | 'openai'
+  | 'anthropic'
+  | 'local'
+  | 'github'
+  | 'gitlab'
+  | 'bitbucket'
+  | 'generic';
 export type IntegrationStatus = 'active' | 'disabled' | 'error';
 
 export interface IntegrationConfig {
@@ -26,6 +33,9 @@ export interface IntegrationConfig {
   temperature?: number;
   max_tokens?: number;
   signature_header?: string;
+  api_url?: string;
+  report_status?: boolean;
+  status_context?: string;
 }
 
 export interface CreateIntegrationRequest {
@@ -0,0 +1,178 @@
+import { apiClient } from './client';
+import type { Flow } from './types';
+import type { ScheduleRun } from './schedules';
+
+export type MonitorStatus = 'active' | 'paused';
+export type AlertState = 'ok' | 'pending' | 'firing' | 'resolved';
+export type SLOType = 'availability' | 'latency';
+
+export interface SLO {
+  name?: string;
+  type: SLOType;
+  objective: number;
+  percentile?: number;
+  step_id?: string;
+  window?: string;
+}
+
+export interface SLOStatus extends SLO {
+  name: string;
+  actual: number | null;
+  samples: number;
+  met: boolean;
+  error_budget?: number;
+}
+
+export interface Monitor {
+  id: string;
+  workspace_id: string;
+  name: string;
+  description?: string;
+  flow_id: string;
+  schedule_id: string;
+  status: MonitorStatus;
+  interval: string;
+  environment?: Record<string, any>;
+  slos: SLO[];
+  pending_for?: string;
+  repeat_interval?: string;
+  notify_webhook_url?: string;
+  public: boolean;
+  alert_state: AlertState;
+  alert_since?: string;
+  last_check_at?: string;
+  last_check_result?: 'success' | 'failure';
+  created_at: string;
+  updated_at: string;
+  flow?: Flow;
+  slo_status?: SLOStatus[];
+}
+
+export interface MonitorCheck {
+  id: string;
+  monitor_id: string;
+  schedule_run_id?: string;
+  execution_id?: string;
+  location: string;
+  result: 'success' | 'failure';
+  error?: string;
+  duration_ms: number;
+  checked_at: string;
+}
+
+export interface MonitorAlert {
+  id: string;
+  monitor_id: string;
+  state: AlertState;
+  reason: string;
+  breaches?: string[];
+  pending_at: string;
+  fired_at?: string;
+  resolved_at?: string;
+  last_notified_at?: string;
+  notifications: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface MonitorRequest {
+  name: string;
+  description?: string;
+  flow_id: string;
+  interval: string;
+  environment?: Record<string, any>;
+  slos?: SLO[];
+  pending_for?: string;
+  repeat_interval?: string;
+  notify_webhook_url?: string;
+  public?: boolean;
+}
+
+export interface StatusPage {
+  name: string;
+  status: 'operational' | 'degraded' | 'outage';
+  updated_at: string;
+  monitors: {
+    name: string;
+    description?: string;
+    status: 'operational' | 'degraded' | 'down' | 'paused';
+    locations: string[];
+    last_check_at?: string;
+    uptime: Record<string, number | null>;
+    slos: SLOStatus[];
+    incidents: {
+      status: 'ongoing' | 'resolved';
+      breaches: string[];
+      started_at: string;
+      resolved_at?: string;
+    }[];
+  }[];
+}
+
+// List the monitors of the workspace
+export async function listMonitors(): Promise<{ monitors: Monitor[]; total: number }> {
+  const response = await apiClient.get('/api/v1/monitors');
+  return response.data;
+}
+
+// Get a monitor with the current evaluation of its SLOs
+export async function getMonitor(id: string): Promise<Monitor> {
+  const response = await apiClient.get(`/api/v1/monitors/${id}`);
+  return response.data;
+}
+
+// Create a monitor
+export async function createMonitor(data: MonitorRequest): Promise<Monitor> {
+  const response = await apiClient.post('/api/v1/monitors', data);
+  return response.data;
+}
+
+// Update a monitor
+export async function updateMonitor(id: string, data: MonitorRequest): Promise<Monitor> {
+  const response = await apiClient.put(`/api/v1/monitors/${id}`, data);
+  return response.data;
+}
+
+// Delete a monitor with its checks and alerts
+export async function deleteMonitor(id: string): Promise<void> {
+  await apiClient.delete(`/api/v1/monitors/${id}`);
+}
+
+// Pause the checks of a monitor
+export async function pauseMonitor(id: string): Promise<Monitor> {
+  const response = await apiClient.post(`/api/v1/monitors/${id}/pause`);
+  return response.data;
+}
+
+// Resume the checks of a monitor
+export async function resumeMonitor(id: string): Promise<Monitor> {
+  const response = await apiClient.post(`/api/v1/monitors/${id}/resume`);
+  return response.data;
+}
+
+// Run a check of a monitor now
+export async function checkMonitor(id: string): Promise<ScheduleRun> {
+  const response = await apiClient.post(`/api/v1/monitors/${id}/check`);
+  return response.data;
+}
+
+// List the latest checks of a monitor
+export async function listMonitorChecks(id: string, limit = 50): Promise<{ checks: MonitorCheck[]; total: number }> {
+  const response = await apiClient.get(`/api/v1/monitors/${id}/checks`, { params: { limit } });
+  return response.data;
+}
+
+// List the alerts of a monitor
+export async function listMonitorAlerts(
+  id: string,
+  limit = 50
+): Promise<{ alerts: MonitorAlert[]; total: number; alert_state: AlertState }> {
+  const response = await apiClient.get(`/api/v1/monitors/${id}/alerts`, { params: { limit } });
+  return response.data;
+}
+
+// Get the public status page of a workspace
+export async function getStatusPage(slug: string): Promise<StatusPage> {
+  const response = await apiClient.get(`/api/v1/status/${slug}`);
+  return response.data;
+}
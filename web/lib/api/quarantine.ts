@@ -0,0 +1,58 @@
+import { apiClient } from './client';
+import type { Flow } from './types';
+
+export type QuarantineStatus = 'active' | 'released';
+export type QuarantineSource = 'auto' | 'manual';
+
+export interface Quarantine {
+  id: string;
+  workspace_id: string;
+  flow_id: string;
+  step_id?: string;
+  status: QuarantineStatus;
+  source: QuarantineSource;
+  reason?: string;
+  flakiness_score: number;
+  clean_runs: number;
+  quarantined_by?: string;
+  released_at?: string;
+  release_reason?: string;
+  created_at: string;
+  updated_at: string;
+  flow?: Flow;
+}
+
+export interface QuarantinePolicy {
+  auto_quarantine?: boolean;
+  flakiness_threshold?: number;
+  release_after?: number;
+  retry_on_failure?: number;
+  notify_webhook_url?: string;
+}
+
+// List quarantines, active ones by default
+export async function listQuarantines(params?: {
+  status?: QuarantineStatus | 'all';
+  flow_id?: string;
+}): Promise<{ quarantines: Quarantine[]; total: number; policy: QuarantinePolicy }> {
+  const response = await apiClient.get('/api/v1/quarantine', { params });
+  return response.data;
+}
+
+// Get a quarantine
+export async function getQuarantine(id: string): Promise<Quarantine> {
+  const response = await apiClient.get(`/api/v1/quarantine/${id}`);
+  return response.data;
+}
+
+// Quarantine a flow, or one of its steps
+export async function quarantineFlow(data: { flow_id: string; step_id?: string; reason?: string }): Promise<Quarantine> {
+  const response = await apiClient.post('/api/v1/quarantine', data);
+  return response.data;
+}
+
+// Release a quarantine
+export async function releaseQuarantine(id: string, reason?: string): Promise<Quarantine> {
+  const response = await apiClient.delete(`/api/v1/quarantine/${id}`, { data: { reason } });
+  return response.data;
+}
@@ -54,6 +54,29 @@ export const reportsApi = {
   delete: async (id: string): Promise<void> => {
     await apiClient.delete(`/api/v1/reports/${id}`);
   },
+
+  formats: async (): Promise<ReportFormat[]> => {
+    const response = await apiClient.get<{ formats: ReportFormat[] }>('/api/v1/reports/formats');
+    return response.data.formats;
+  },
+
+  // Export one execution in a result format such as allure, ctrf or tap
+  exportExecution: async (workspaceId: string, id: string, format: ReportFormat): Promise<Blob> => {
+    const response = await apiClient.get(`/api/v1/workspaces/${workspaceId}/executions/${id}/export`, {
+      params: { format },
+      responseType: 'blob',
+    });
+    return response.data;
+  },
+
+  // Export a schedule run in a result format, one test per flow
+  exportScheduleRun: async (scheduleId: string, runId: string, format: ReportFormat): Promise<Blob> => {
+    const response = await apiClient.get(`/api/v1/schedules/${scheduleId}/runs/${runId}/export`, {
+      params: { format },
+      responseType: 'blob',
+    });
+    return response.data;
+  },
 };
 
 // Analytics API
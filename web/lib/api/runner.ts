@@ -1,10 +1,37 @@
 import { apiClient } from './client';
 
 // Runner types
+export type DataSourceType = 'csv' | 'json' | 'inline' | 'file' | 'sql' | 'generator' | 'execution';
+
 export interface DataSource {
-  type: 'csv' | 'json' | 'inline';
+  type: DataSourceType;
   content?: string;
   data?: DataRow[];
+  // file: name or ID of a data file of the workspace
+  file?: string;
+  // sql: SELECT query whose rows are the iterations
+  query?: string;
+  connection?: string;
+  params?: unknown[];
+  // generator: seeded fake rows
+  generator?: DataGenerator;
+  // execution: rows from a step output of an earlier execution
+  execution_id?: string;
+  step?: string;
+  path?: string;
+  // Expression rows must match, like fare_type == "single"
+  filter?: string;
+  // Column with the expected outcome of a row, "pass" or "fail" (default "expected_outcome")
+  expect_column?: string;
+  // Column naming a row in reports (default "name")
+  label_column?: string;
+}
+
+export interface DataGenerator {
+  count: number;
+  seed?: number;
+  // Column name to a template like "${FAKER.internet.email}", a list of choices or a literal
+  fields: Record<string, unknown>;
 }
 
 export type DataRow = Record<string, any>;
@@ -28,6 +55,9 @@ export interface CollectionRunResult {
   completed_iterations: number;
   passed_iterations: number;
   failed_iterations: number;
+  filtered_rows?: number;
+  columns?: string[];
+  data_seed?: number;
   iteration_results: IterationResult[];
   started_at: string;
   finished_at?: string;
@@ -37,7 +67,11 @@ export interface CollectionRunResult {
 
 export interface IterationResult {
   iteration: number;
+  row?: number;
+  label?: string;
   data_row?: DataRow;
+  expected?: 'pass' | 'fail';
+  outcome?: 'passed' | 'failed';
   flow_results: FlowRunResult[];
   status: 'passed' | 'failed';
   started_at: string;
@@ -55,15 +89,46 @@ export interface FlowRunResult {
   error?: string;
 }
 
+export interface ParseDataRequest extends DataSource {
+  environment?: string;
+  variables?: Record<string, string>;
+}
+
 export interface ParseDataResponse {
   columns: string[];
   preview: DataRow[];
   total_rows: number;
+  filtered_rows?: number;
+  data_seed?: number;
+}
+
+export interface DataFile {
+  id: string;
+  name: string;
+  description: string;
+  format: 'csv' | 'json';
+  content?: string;
+  row_count: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateDataFileRequest {
+  name: string;
+  description?: string;
+  format?: 'csv' | 'json';
+  content: string;
 }
 
 // Run a collection with data
 export async function runCollection(config: CollectionRunConfig): Promise<CollectionRunResult> {
-  const response = await apiClient.post('/runner/run', config);
+  const response = await apiClient.post('/api/v1/runner/run', config);
+  return response.data;
+}
+
+// Load the rows of a data source, with a preview of the first ones
+export async function parseDataSource(request: ParseDataRequest): Promise<ParseDataResponse> {
+  const response = await apiClient.post('/api/v1/runner/parse-data', request);
   return response.data;
 }
 
@@ -72,6 +137,40 @@ export async function parseDataFile(
   type: 'csv' | 'json',
   content: string
 ): Promise<ParseDataResponse> {
-  const response = await apiClient.post('/runner/parse-data', { type, content });
-  return response.data;
+  return parseDataSource({ type, content });
 }
+
+// Data files of the workspace
+export const dataFilesApi = {
+  list: async (search?: string) => {
+    const response = await apiClient.get<{ data_files: DataFile[]; total: number }>(
+      '/api/v1/data-files',
+      { params: search ? { search } : undefined }
+    );
+    return response.data;
+  },
+
+  get: async (id: string) => {
+    const response = await apiClient.get<DataFile>(`/api/v1/data-files/${id}`);
+    return response.data;
+  },
+
+  create: async (data: CreateDataFileRequest) => {
+    const response = await apiClient.post<DataFile>('/api/v1/data-files', data);
+    return response.data;
+  },
+
+  update: async (id: string, data: Partial<CreateDataFileRequest>) => {
+    const response = await apiClient.put<DataFile>(`/api/v1/data-files/${id}`, data);
+    return response.data;
+  },
+
+  delete: async (id: string) => {
+    await apiClient.delete(`/api/v1/data-files/${id}`);
+  },
+
+  preview: async (id: string) => {
+    const response = await apiClient.get<ParseDataResponse>(`/api/v1/data-files/${id}/preview`);
+    return response.data;
+  },
+};
@@ -1,15 +1,24 @@
 import { apiClient } from './client';
+import type { Execution } from './types';
 
 // Types
 export interface Schedule {
   id: string;
   name: string;
   description?: string;
-  flow_id: string;
+  target_type: ScheduleTargetType;
+  workspace_id?: string;
+  flow_id?: string;
   flow?: {
     id: string;
     name: string;
   };
+  collection_id?: string;
+  suite?: string;
+  tag_query?: string;
+  flow_ids?: string[];
+  concurrency: number;
+  fail_fast: boolean;
   cron_expr: string;
   timezone: string;
   status: ScheduleStatus;
@@ -19,6 +28,8 @@ export interface Schedule {
   notify_emails?: string[];
   max_retries: number;
   retry_delay: string;
+  misfire_policy: MisfirePolicy;
+  jitter?: string;
   allow_overlap: boolean;
   next_run_at?: string;
   last_run_at?: string;
@@ -32,6 +43,21 @@ export interface Schedule {
 
 export type ScheduleStatus = 'active' | 'paused' | 'disabled';
 
+export type MisfirePolicy = 'skip' | 'run_once' | 'run_all';
+
+export type ScheduleTargetType = 'flow' | 'flows' | 'collection' | 'suite' | 'tags';
+
+export interface ScheduleRunFlow {
+  flow_id: string;
+  flow_name: string;
+  suite?: string;
+  execution_id?: string;
+  status: 'passed' | 'failed' | 'skipped' | 'quarantined';
+  attempts: number;
+  duration_ms: number;
+  error?: string;
+}
+
 export interface ScheduleRun {
   id: string;
   schedule_id: string;
@@ -44,6 +70,13 @@ export interface ScheduleRun {
   started_at?: string;
   completed_at?: string;
   duration_ms?: number;
+  total_flows: number;
+  passed_flows: number;
+  failed_flows: number;
+  skipped_flows: number;
+  quarantined_flows: number;
+  flow_results?: ScheduleRunFlow[];
+  executions?: Execution[];
   created_at: string;
 }
 
@@ -58,7 +91,15 @@ export interface ScheduleStats {
 export interface CreateScheduleRequest {
   name: string;
   description?: string;
-  flow_id: string;
+  target_type?: ScheduleTargetType;
+  workspace_id?: string;
+  flow_id?: string;
+  collection_id?: string;
+  suite?: string;
+  tag_query?: string;
+  flow_ids?: string[];
+  concurrency?: number;
+  fail_fast?: boolean;
   cron_expr: string;
   timezone?: string;
   environment?: Record<string, any>;
@@ -67,6 +108,8 @@ export interface CreateScheduleRequest {
   notify_emails?: string[];
   max_retries?: number;
   retry_delay?: string;
+  misfire_policy?: MisfirePolicy;
+  jitter?: string;
   allow_overlap?: boolean;
   tags?: string[];
 }
@@ -154,6 +197,23 @@ export async function getScheduleRuns(id: string, limit?: number): Promise<{
   return response.data;
 }
 
+export async function getScheduleRun(id: string, runId: string): Promise<ScheduleRun> {
+  const response = await apiClient.get(`/api/v1/schedules/${id}/runs/${runId}`);
+  return response.data;
+}
+
+export function getScheduleRunJUnitUrl(id: string, runId: string): string {
+  return `${apiClient.defaults.baseURL ?? ''}/api/v1/schedules/${id}/runs/${runId}/junit`;
+}
+
+export async function getScheduleTargets(id: string): Promise<{
+  flows: { id: string; name: string; suite?: string; tags?: string[] }[];
+  total: number;
+}> {
+  const response = await apiClient.get(`/api/v1/schedules/${id}/targets`);
+  return response.data;
+}
+
 export async function getScheduleStats(id: string, days?: number): Promise<ScheduleStats> {
   const response = await apiClient.get(`/api/v1/schedules/${id}/stats`, {
     params: { days },
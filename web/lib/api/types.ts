@@ -58,6 +58,12 @@ export interface Execution {
   passed_steps: number;
   failed_steps: number;
   error?: string;
+  attempt?: number;
+  retry_of?: string;
+  quarantined?: boolean;
+  record_context?: boolean;
+  restart_of?: string;
+  restart_from?: string;
   created_at: string;
   updated_at: string;
 }
@@ -292,6 +298,33 @@ export interface CreateExecutionRequest {
   flow_id: string;
   environment?: string;
   variables?: Record<string, string>;
+  record_context?: boolean;
+}
+
+export interface ContextSnapshot {
+  id: string;
+  execution_id: string;
+  sequence: number;
+  phase: 'setup' | 'main' | 'teardown';
+  step_id: string;
+  step_name?: string;
+  action: string;
+  step_index: number;
+  parent_step_id?: string;
+  variables: Record<string, string>;
+  step_outputs: Record<string, Record<string, any>>;
+  created_at: string;
+}
+
+export interface ListContextResponse {
+  snapshots: ContextSnapshot[];
+  total: number;
+}
+
+export interface RestartExecutionRequest {
+  step_id: string;
+  variables?: Record<string, string>;
+  step_outputs?: Record<string, Record<string, any>>;
 }
 
 export interface ListExecutionsResponse {
@@ -379,7 +412,8 @@ export interface DetectBreakingChangesResponse {
 // Reporting & Analytics Types
 
 export type ReportStatus = 'pending' | 'generating' | 'completed' | 'failed';
-export type ReportFormat = 'html' | 'json' | 'junit';
+// Reporter plugins add their own formats
+export type ReportFormat = 'html' | 'json' | 'junit' | 'allure' | 'ctrf' | 'tap' | 'pdf' | (string & {});
 
 export interface ReportFilters {
   suites?: string[];
@@ -1,5 +1,11 @@
-import { useMutation } from '@tanstack/react-query';
-import { runCollection, parseDataFile, type CollectionRunConfig } from '@/lib/api/runner';
+import { useMutation, useQuery } from '@tanstack/react-query';
+import {
+  runCollection,
+  parseDataSource,
+  dataFilesApi,
+  type CollectionRunConfig,
+  type ParseDataRequest,
+} from '@/lib/api/runner';
 
 // Run collection mutation
 export function useRunCollection() {
@@ -8,10 +14,17 @@ export function useRunCollection() {
   });
 }
 
-// Parse data file mutation
+// Parse data source mutation
 export function useParseDataFile() {
   return useMutation({
-    mutationFn: ({ type, content }: { type: 'csv' | 'json'; content: string }) =>
-      parseDataFile(type, content),
+    mutationFn: (request: ParseDataRequest) => parseDataSource(request),
+  });
+}
+
+// Data files of the workspace
+export function useDataFiles() {
+  return useQuery({
+    queryKey: ['data-files'],
+    queryFn: () => dataFilesApi.list(),
   });
 }
@@ -1,4 +1,5 @@
 import { useEffect, useRef, useState } from 'react';
+import { getActiveWorkspaceId } from '@/lib/hooks/useWorkspaces';
 
 export type WebSocketEventType =
   | 'execution.started'
@@ -9,9 +10,12 @@ export type WebSocketEventType =
   | 'step.failed';
 
 export interface WebSocketEvent {
+  seq: number; // Order of the event within its execution
   type: WebSocketEventType;
+  workspace_id: string;
   execution_id: string;
   data: Record<string, any>;
+  timestamp: string;
 }
 
 interface UseWebSocketOptions {
@@ -42,6 +46,8 @@ export function useWebSocket({
   const [lastMessage, setLastMessage] = useState<WebSocketEvent | null>(null);
   const wsRef = useRef<WebSocket | null>(null);
   const reconnectTimeoutRef = useRef<NodeJS.Timeout | undefined>(undefined);
+  // Last event received, so that reconnects resume after it
+  const lastSeqRef = useRef(0);
 
   const connect = () => {
     if (wsRef.current?.readyState === WebSocket.OPEN) {
@@ -51,7 +57,11 @@ export function useWebSocket({
     // Determine WebSocket URL based on current location
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
     const host = process.env.NEXT_PUBLIC_API_URL?.replace(/^https?:\/\//, '') || 'localhost:5016';
-    const wsUrl = `${protocol}//${host}/ws/executions/${executionId}`;
+    const workspaceId = getActiveWorkspaceId();
+    if (!workspaceId) {
+      return;
+    }
+    const wsUrl = `${protocol}//${host}/api/v1/workspaces/${workspaceId}/executions/${executionId}/events?since=${lastSeqRef.current}`;
 
     try {
       const ws = new WebSocket(wsUrl);
@@ -65,6 +75,10 @@ export function useWebSocket({
       ws.onmessage = (event) => {
         try {
           const data = JSON.parse(event.data) as WebSocketEvent;
+          if (data.seq && data.seq <= lastSeqRef.current) {
+            return;
+          }
+          lastSeqRef.current = data.seq || lastSeqRef.current;
           setLastMessage(data);
           onMessage?.(data);
         } catch (error) {
@@ -113,6 +127,7 @@ export function useWebSocket({
   };
 
   useEffect(() => {
+    lastSeqRef.current = 0;
     if (autoConnect) {
       connect();
     }